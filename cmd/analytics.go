@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/alerts"
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// analyticsOptions selects the cross-exchange analytics run on the books
+type analyticsOptions struct {
+	Arbitrage  *analytics.ArbitrageConfig   // Spreads between venue pairs, nil disables detection
+	Flow       *analytics.FlowConfig        // CVD and taker volumes from the trades, nil disables tracking
+	Funding    *analytics.FundingConfig     // Funding and open interest of perpetual venues, nil disables tracking
+	Basis      *analytics.BasisConfig       // Spot-perp basis per venue, annualized with the funding; nil disables it
+	Resiliency *analytics.ResiliencyConfig  // Recovery of the depth near mid after large depletions, nil disables it
+	Spoofing   *analytics.SpoofingConfig    // Levels placed and pulled without trading, nil disables detection
+	Share      *analytics.MarketShareConfig // Share of each venue in the liquidity and best quotes, nil disables it
+	Divergence *analytics.DivergenceConfig  // Mids straying from the median mid of the venues, nil disables detection
+	Volatility *analytics.VolatilityConfig  // Realized volatility of the mids over trailing windows, nil disables it
+	Fees       *analytics.Fees              // Fee schedules the spreads after fees are reported with, nil disables them
+	Index      *analytics.IndexConfig       // Weighted index price across the venues, nil disables it
+	Alerts     *alerts.Config               // Alert rules and their notifiers, nil disables alerting
+	Capture    *alerts.CaptureConfig        // Full books kept around every fired alert, nil disables capturing
+}
+
+// startAnalytics starts the selected analytics and adds them to the outputs books register with
+func startAnalytics(work workers, options analyticsOptions, symbol string, out *outputs) error {
+	if options.Arbitrage != nil {
+		out.arbitrage = analytics.NewArbitrage(*options.Arbitrage, symbol)
+		out.arbitrage.OnEvent(logArbitrage)
+		work.start(out.arbitrage.Start)
+		logger("arbitrage").Info("Detecting arbitrage net of fees", "sizes", options.Arbitrage.Sizes, "threshold", rateText(options.Arbitrage.Threshold))
+	}
+	if options.Divergence != nil {
+		out.divergence = analytics.NewDivergence(*options.Divergence, symbol)
+		out.divergence.OnEvent(logDivergence)
+		work.start(out.divergence.Start)
+		logger("divergence").Info("Detecting mids away from the median mid", "threshold", rateText(options.Divergence.Threshold), "duration", options.Divergence.Duration)
+	}
+	if options.Alerts != nil {
+		engine, err := alerts.New(*options.Alerts, symbol)
+		if err != nil {
+			return fmt.Errorf("alerts setup failed: %w", err)
+		}
+		out.alerts = engine
+		out.alerts.OnAlert(logAlert)
+		if options.Capture != nil {
+			if out.capture, err = alerts.NewCapture(*options.Capture); err != nil {
+				return fmt.Errorf("alert capture setup failed: %w", err)
+			}
+			out.alerts.OnAlert(out.capture.Trigger)
+			work.start(out.capture.Start)
+			logger("alerts").Info("Writing the books around every alert", "before", options.Capture.Before, "dir", options.Capture.Dir)
+		}
+		work.start(out.alerts.Start)
+		logger("alerts").Info("Evaluating alert rules", "rules", len(engine.Rules()), "notifiers", engine.Notifiers())
+	}
+	if options.Flow != nil {
+		out.flow = analytics.NewFlow(*options.Flow, symbol)
+		out.flow.OnLargeTrade(logLargeTrade)
+		logger("flow").Info("Tracking taker flow", "window", options.Flow.Window)
+		if options.Flow.LargeTrade.IsPositive() {
+			logger("flow").Info("Reporting large trades", "min_notional", options.Flow.LargeTrade)
+		}
+	}
+	if options.Funding != nil {
+		out.funding = analytics.NewFunding(*options.Funding)
+	}
+	if options.Basis != nil {
+		out.basis = analytics.NewBasis(*options.Basis, out.funding)
+	}
+	if options.Spoofing != nil {
+		out.spoofing = analytics.NewSpoofing(*options.Spoofing, symbol)
+		out.spoofing.OnEvent(logSpoofing)
+		work.start(out.spoofing.Start)
+		logger("spoofing").Info("Detecting spoofing and layering", "max_age", options.Spoofing.MaxAge)
+	}
+	if options.Resiliency != nil {
+		out.resiliency = analytics.NewResiliency(*options.Resiliency)
+		work.start(out.resiliency.Start)
+		logger("resiliency").Info("Scoring the resiliency of the depth near mid", "window", options.Resiliency.Window)
+	}
+	if options.Volatility != nil {
+		out.volatility = analytics.NewVolatility(*options.Volatility)
+		work.start(out.volatility.Start)
+		logger("volatility").Info("Tracking the realized volatility of the mids", "windows", options.Volatility.Windows)
+	}
+	if options.Fees != nil {
+		out.fees = options.Fees
+		logger("fees").Info("Reporting spreads after fees, unless set per exchange", "maker", rateText(options.Fees.Default.Maker), "taker", rateText(options.Fees.Default.Taker))
+	}
+	if options.Index != nil {
+		config := *options.Index
+		config.Flow = out.flow
+		out.index = analytics.NewIndex(config)
+		logger("index").Info("Computing the index price", "weighting", out.index.Weighting(), "max_deviation", rateText(config.MaxDeviation))
+	}
+	if options.Share != nil {
+		out.share = analytics.NewMarketShare(*options.Share)
+		work.start(out.share.Start)
+		logger("market_share").Info("Tracking the market share of every venue in the liquidity and best quotes")
+	}
+	return nil
+}
+
+// logLargeTrade logs a trade whose notional reached the large trade threshold
+func logLargeTrade(e analytics.LargeTrade) {
+	logging.For(e.Exchange, e.Symbol).Info("Large taker trade", logging.KeyComponent, "flow", "event", "large_trade",
+		"side", e.Trade.Side, "quantity", e.Trade.Quantity, "price", e.Trade.Price, "notional", e.Notional.StringFixed(2))
+}
+
+// logSpoofing logs a spoofing or layering pattern
+func logSpoofing(e analytics.SpoofingEvent) {
+	side := "bid"
+	if e.Side == types.Sell {
+		side = "ask"
+	}
+	logging.For(e.Exchange, e.Symbol).Warn("Spoofing pattern", logging.KeyComponent, "spoofing", "event", string(e.Kind),
+		"side", side, "pulls", e.Pulls, "size", e.Size, "price", e.Price, "lifetime", e.Lifetime.Round(time.Millisecond))
+}
+
+// logArbitrage logs an arbitrage opportunity opening or closing
+func logArbitrage(e analytics.ArbitrageEvent) {
+	if e.Open {
+		logger("arbitrage").Info("Arbitrage opened", "event", "arbitrage_opened", logging.KeySymbol, e.Symbol, "size", e.Size,
+			"buy", e.Buy, "buy_vwap", e.BuyVWAP.StringFixed(2), "sell", e.Sell, "sell_vwap", e.SellVWAP.StringFixed(2),
+			"net", rateText(e.Net), "profit", e.Profit.StringFixed(2))
+		return
+	}
+	logger("arbitrage").Info("Arbitrage closed", "event", "arbitrage_closed", logging.KeySymbol, e.Symbol, "size", e.Size,
+		"buy", e.Buy, "sell", e.Sell, "duration", e.Time.Sub(e.Since), "peak", rateText(e.Peak))
+}
+
+// logAlert logs an alert firing or resolving
+func logAlert(a alerts.Alert) {
+	event, level := "alert_fired", slog.LevelWarn
+	if a.Resolved {
+		event, level = "alert_resolved", slog.LevelInfo
+	}
+	logging.For(a.Exchange, a.Symbol).Log(context.Background(), level, a.Text(), logging.KeyComponent, "alerts", "event", event,
+		"rule", a.Rule, "value", a.Value, "threshold", a.Threshold)
+}
+
+// logDivergence logs a venue's mid opening or closing a divergence from the median mid
+func logDivergence(e analytics.DivergenceEvent) {
+	if e.Open {
+		cause := "dislocated"
+		if e.Stale {
+			cause = "stale feed"
+		}
+		logging.For(e.Exchange, e.Symbol).Warn("Mid diverged from the median", logging.KeyComponent, "divergence", "event", "divergence_opened",
+			"mid", e.Mid, "median", e.Median, "deviation", rateText(e.Deviation), "duration", e.Time.Sub(e.Since), "cause", cause)
+		return
+	}
+	logging.For(e.Exchange, e.Symbol).Info("Mid back near the median", logging.KeyComponent, "divergence", "event", "divergence_closed",
+		"duration", e.Time.Sub(e.Since), "peak", rateText(e.Peak))
+}
+
+// rateText formats a fraction in basis points, e.g. 12.50bp
+func rateText(rate decimal.Decimal) string {
+	return rate.Mul(decimal.NewFromInt(10000)).StringFixed(2) + "bp"
+}
+
+// parseRate parses a non-negative rate in percent or basis points, as ParseBand does, and
+// returns it as a fraction
+func parseRate(item string) (decimal.Decimal, error) {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(item)), "bp"), "%")
+	if value, err := decimal.NewFromString(trimmed); err == nil && value.IsZero() {
+		return decimal.Zero, nil
+	}
+	return types.ParseBand(item)
+}
+
+// parseFee parses a fee like parseRate, allowing a leading minus for a rebate
+func parseFee(item string) (decimal.Decimal, error) {
+	if rest, rebate := strings.CutPrefix(strings.TrimSpace(item), "-"); rebate {
+		fee, err := parseRate(rest)
+		return fee.Neg(), err
+	}
+	return parseRate(item)
+}
+
+// parseFees parses comma-separated exchange=maker/taker pairs, e.g. binance=2bp/4bp,okx=-0.5bp/5bp;
+// an exchange with a single fee pays it as taker and maker as maker
+func parseFees(list string, maker decimal.Decimal) (map[string]analytics.FeeSchedule, error) {
+	fees := make(map[string]analytics.FeeSchedule)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fee %q, want exchange=maker/taker", item)
+		}
+		schedule := analytics.FeeSchedule{Maker: maker}
+		var err error
+		if makerFee, takerFee, both := strings.Cut(value, "/"); both {
+			if schedule.Maker, err = parseFee(makerFee); err != nil {
+				return nil, fmt.Errorf("invalid maker fee of %s: %w", name, err)
+			}
+			value = takerFee
+		}
+		if schedule.Taker, err = parseFee(value); err != nil {
+			return nil, fmt.Errorf("invalid taker fee of %s: %w", name, err)
+		}
+		fees[strings.ToLower(strings.TrimSpace(name))] = schedule
+	}
+	return fees, nil
+}
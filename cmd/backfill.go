@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/config"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/replay"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/storage"
+)
+
+// backfill rebuilds the books of recorded book files and stores a snapshot of them every
+// -db-interval of recorded time, stamped with that time
+func backfill(args []string) error {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: orderbook backfill [flags] FILE...")
+		fmt.Fprintln(flags.Output(), "FILE is a book recording under <record-dir>/<exchange>/; the files of one exchange are read in name order")
+		flags.PrintDefaults()
+	}
+	symbol := flags.String("symbol", "BTCUSDT", "Symbol stored with the snapshots")
+	interval := flags.Duration("db-interval", 20*time.Second, "Recorded time between stored snapshots")
+	driver := flags.String("db-driver", os.Getenv("DB_DRIVER"), "Storage driver: supabase (default), postgres, clickhouse, sqlite, parquet, csv, influx or grpc")
+	dsn := flags.String("db-dsn", os.Getenv("DB_DSN"), "Connection string of the storage driver")
+	path := flags.String("db-path", os.Getenv("DB_PATH"), "SQLite database file (default book.db), Parquet archive directory (default parquet) or CSV directory (default csv)")
+	cbbo := flags.Bool("db-cbbo", os.Getenv("DB_CBBO") != "", "Also store the consolidated best bid and offer across exchanges as exchange CONSOLIDATED")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("invalid -db-interval: %v", *interval)
+	}
+
+	// The recorder keeps the book files of each exchange in a directory named after it
+	files := make(map[string][]string)
+	for _, file := range flags.Args() {
+		name := filepath.Base(filepath.Dir(file))
+		files[name] = append(files[name], file)
+	}
+
+	client, err := storage.New(config.StorageConfig{Driver: *driver, DSN: *dsn, Path: *path, Consolidated: *cbbo})
+	if err != nil {
+		return fmt.Errorf("storage setup failed: %w", err)
+	}
+	defer client.Close()
+
+	dataCollector := collector.NewCollector(client, *symbol, *interval)
+	dataCollector.SetConsolidated(*cbbo)
+	dataCollector.SetQuiet(true)
+
+	var sources []*recordedBook
+	for name, paths := range files {
+		slices.Sort(paths)
+		source := &recordedBook{name: name, paths: paths, book: orderbook.New(), collector: dataCollector}
+		source.book.SetLogger(logging.For(name, *symbol))
+		if err := source.advance(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if source.next != nil {
+			sources = append(sources, source)
+		}
+	}
+
+	// Records of every exchange are applied in recorded order, collecting at each interval
+	// boundary they pass so the consolidated row sees the books as they were together
+	var tick, from time.Time
+	collections := 0
+	for len(sources) > 0 {
+		earliest := slices.MinFunc(sources, func(a, b *recordedBook) int { return a.next.Time.Compare(b.next.Time) })
+		at := earliest.next.Time
+		if tick.IsZero() {
+			from = at
+			tick = at.Truncate(*interval).Add(*interval)
+		}
+		for !at.Before(tick) {
+			dataCollector.CollectAt(tick)
+			collections++
+			tick = tick.Add(*interval)
+		}
+
+		if err := earliest.apply(); err != nil {
+			return fmt.Errorf("failed to apply %s: %w", earliest.name, err)
+		}
+		if err := earliest.advance(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", earliest.name, err)
+		}
+		if earliest.next == nil {
+			sources = slices.DeleteFunc(sources, func(s *recordedBook) bool { return s == earliest })
+		}
+	}
+
+	stats := dataCollector.GetStats()
+	logger("backfill").Info("Backfilled", "event", "backfilled", logging.KeySymbol, *symbol, "collections", collections,
+		"from", from.Format(time.RFC3339), "to", tick.Add(-*interval).Format(time.RFC3339), "stored", stats["stored"], "insert_errors", stats["insert_errors"])
+	return nil
+}
+
+// recordedBook is the book of one exchange rebuilt from its recordings
+type recordedBook struct {
+	name      string
+	paths     []string // Recordings not opened yet, oldest first
+	reader    *replay.Reader
+	book      *orderbook.OrderBook
+	collector *collector.Collector
+	next      *replay.Record // Next record to apply, nil once every recording is read
+	collected bool           // Registered with the collector, from the first snapshot on
+}
+
+// advance reads the next record, moving on to the next recording at the end of one
+func (r *recordedBook) advance() error {
+	for {
+		if r.reader == nil {
+			if len(r.paths) == 0 {
+				r.next = nil
+				return nil
+			}
+			reader, err := replay.OpenReader(r.paths[0], "")
+			if err != nil {
+				return err
+			}
+			r.reader, r.paths = reader, r.paths[1:]
+		}
+		rec, err := r.reader.Next()
+		if err == io.EOF {
+			r.reader.Close()
+			r.reader = nil
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		r.next = rec
+		return nil
+	}
+}
+
+// apply applies the next record to the book, which is collected from its first snapshot on
+func (r *recordedBook) apply() error {
+	switch r.next.Type {
+	case replay.RecordSnapshot:
+		if err := r.book.LoadSnapshot(r.next.Snapshot); err != nil {
+			return err
+		}
+		r.book.ProcessBufferedEvents()
+		if !r.collected {
+			// The recorded levels are all the feed delivered, so bands beyond them stay NULL
+			caps := exchange.Capabilities{MaxDepth: max(len(r.next.Snapshot.Bids), len(r.next.Snapshot.Asks))}
+			r.collector.RegisterOrderbook(r.name, r.book, caps)
+			r.collected = true
+		}
+	case replay.RecordUpdate:
+		r.book.HandleDepthUpdate(r.next.Update)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/imajinl/crypto-orderbook/internal/factory"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// usage lists the commands; each prints its own flags with -h
+const usage = `Usage: orderbook [command] [flags]
+
+Commands:
+  run             Monitor the books of every exchange live (default)
+  record          Like run, archiving every feed under -record-dir (default recordings) without storing snapshots
+  replay FILE     Like run, playing back a recorded book file instead of connecting to the exchanges
+  backfill FILE   Store the snapshots a live run would have stored from recorded book files
+  export          Write stored snapshots as JSON lines
+  list-exchanges  List the exchanges that can be monitored
+
+Run "orderbook <command> -h" for the flags of a command.
+`
+
+func main() {
+	command, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "run", "record", "replay":
+		err = run(command, args)
+	case "backfill":
+		err = backfill(args)
+	case "export":
+		err = export(args)
+	case "list-exchanges":
+		listExchanges(args)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		logger("main").Error("Exiting", "error", err)
+		os.Exit(1)
+	}
+}
+
+// listExchanges prints every registered exchange, marking those monitored by default
+func listExchanges(args []string) {
+	flags := flag.NewFlagSet("list-exchanges", flag.ExitOnError)
+	flags.Parse(args)
+
+	monitored := factory.ListMonitored()
+	for _, name := range factory.ListRegistered() {
+		if slices.Contains(monitored, name) {
+			fmt.Printf("%-14s default\n", name)
+			continue
+		}
+		fmt.Println(name)
+	}
+}
+
+// logger returns the logger of a part of the command, e.g. "config" or "admin"
+func logger(component string) *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, component)
+}
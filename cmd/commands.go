@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/imajinl/crypto-orderbook/internal/config"
+)
+
+// readCommands applies the commands typed on input, one per line, until it ends:
+//
+//	symbol ETHUSDT  monitor another symbol, reconnecting every exchange
+//	add okx         connect to another exchange
+//	remove okx      disconnect from an exchange
+func (c *control) readCommands(input io.Reader) {
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if err := c.command(fields); err != nil {
+			logger("command").Warn("Ignoring command", "event", "command_failed", "command", strings.Join(fields, " "), "error", err)
+		}
+	}
+}
+
+// command applies one command split into its words
+func (c *control) command(fields []string) error {
+	if len(fields) != 2 {
+		return fmt.Errorf("unknown command %q (want symbol <SYMBOL>, add <exchange> or remove <exchange>)", strings.Join(fields, " "))
+	}
+	switch strings.ToLower(fields[0]) {
+	case "symbol":
+		return c.SwitchSymbol(fields[1])
+	case "add":
+		return c.AddExchange(strings.ToLower(fields[1]))
+	case "remove":
+		return c.RemoveExchange(strings.ToLower(fields[1]))
+	default:
+		return fmt.Errorf("unknown command %q (want symbol, add or remove)", fields[0])
+	}
+}
+
+// reloadOnHangup applies the config file again on every SIGHUP, e.g. after changing its
+// symbol on a system without file notifications
+func (c *control) reloadOnHangup(path string) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	for range hangups {
+		if path == "" {
+			logger("config").Warn("Ignoring SIGHUP without -config", "event", "reload_skipped")
+			continue
+		}
+		file, err := config.LoadFile(path)
+		if err != nil {
+			logger("config").Error("Ignoring SIGHUP", "event", "reload_failed", "path", path, "error", err)
+			continue
+		}
+		logger("config").Info("Reloading on SIGHUP", "event", "reload", "path", path)
+		c.reload(file)
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/config"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/factory"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/server"
+
+	"github.com/shopspring/decimal"
+)
+
+// logIntervalName names the interval of the logged stats among the adjustable intervals
+const logIntervalName = "log"
+
+// control holds what the admin API and the config file change at runtime: the monitored
+// exchanges and symbol, whether snapshots are stored, the intervals of the collectors and
+// the logged stats, and the liquidity bands of the books
+type control struct {
+	mu          sync.Mutex
+	symbol      string
+	selected    []exchange.ExchangeName                 // Exchanges to monitor, in the order they start
+	running     map[exchange.ExchangeName]chan struct{} // Stop channels of the exchanges connecting or connected
+	start       func(exchange.ExchangeName, <-chan struct{})
+	storage     *collector.Collector            // Stores snapshots, nil without a database
+	collectors  map[string]*collector.Collector // Collectors whose interval can change, by name
+	logInterval time.Duration
+	logReset    chan struct{} // Signals the stats logger that logInterval changed
+	symbols     chan string   // Symbols requested through SwitchSymbol, newest only
+	bands       []decimal.Decimal
+	books       map[string]*orderbook.OrderBook // Live books, which band changes apply to
+}
+
+func newControl(symbol string, exchanges []exchange.ExchangeName, logInterval time.Duration, bands []decimal.Decimal) *control {
+	return &control{
+		symbol:      symbol,
+		selected:    exchanges,
+		running:     make(map[exchange.ExchangeName]chan struct{}),
+		collectors:  make(map[string]*collector.Collector),
+		logInterval: logInterval,
+		logReset:    make(chan struct{}, 1),
+		symbols:     make(chan string, 1),
+		bands:       bands,
+		books:       make(map[string]*orderbook.OrderBook),
+	}
+}
+
+// RegisterOrderbook tracks a live book for band changes
+func (c *control) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.books[name] = ob
+}
+
+// UnregisterOrderbook stops tracking a book
+func (c *control) UnregisterOrderbook(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.books, name)
+}
+
+// liquidityBands returns the bands new books report
+func (c *control) liquidityBands() []decimal.Decimal {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bands
+}
+
+// setBands changes the bands of new books and of every live one
+func (c *control) setBands(bands []decimal.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bands = bands
+	for _, ob := range c.books {
+		ob.SetLiquidityBands(bands)
+	}
+}
+
+// reload applies a changed config file through the same paths as the admin API; settings
+// the file leaves out are kept
+func (c *control) reload(file config.File) {
+	if len(file.Exchanges) > 0 {
+		c.mu.Lock()
+		selected := slices.Clone(c.selected)
+		c.mu.Unlock()
+		for _, name := range selected {
+			if !slices.Contains(file.Exchanges, name) {
+				c.RemoveExchange(string(name))
+			}
+		}
+		for _, name := range file.Exchanges {
+			if !slices.Contains(selected, name) {
+				c.AddExchange(string(name))
+			}
+		}
+	}
+
+	changed := make(map[string]time.Duration)
+	state := c.AdminState()
+	for name, interval := range file.Intervals {
+		if state.Intervals[name] != interval.String() {
+			changed[name] = interval
+		}
+	}
+	if len(changed) > 0 {
+		if err := c.SetIntervals(changed); err != nil {
+			logger("config").Error("Ignoring intervals", "event", "config_ignored", "error", err)
+		}
+	}
+
+	if file.Bands != nil && !slices.EqualFunc(file.Bands, c.liquidityBands(), decimal.Decimal.Equal) {
+		c.setBands(file.Bands)
+		logger("config").Info("Liquidity bands changed", "event", "bands_changed", "bands", bandsText(file.Bands))
+	}
+
+	if file.Symbol != "" {
+		c.SwitchSymbol(file.Symbol)
+	}
+}
+
+// addCollector makes the interval of a collector adjustable under name
+func (c *control) addCollector(name string, col *collector.Collector) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.collectors[name] = col
+}
+
+// namedCollectors returns the collectors whose interval can change, by name
+func (c *control) namedCollectors() map[string]*collector.Collector {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.collectors)
+}
+
+// setStorage selects the collector SetCollectorEnabled turns on and off
+func (c *control) setStorage(col *collector.Collector) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.storage = col
+}
+
+// setSymbol records the symbol the exchanges run on
+func (c *control) setSymbol(symbol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.symbol = symbol
+}
+
+// attach starts every selected exchange with start, which keeps serving exchanges added
+// until detach; stop is closed when an exchange is removed
+func (c *control) attach(start func(name exchange.ExchangeName, stop <-chan struct{})) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.start = start
+	for _, name := range c.selected {
+		c.launch(name)
+	}
+}
+
+// detach stops starting exchanges, before the running ones shut down for a symbol switch
+func (c *control) detach() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.start = nil
+}
+
+// launch starts an exchange unless it is running; c.mu must be held
+func (c *control) launch(name exchange.ExchangeName) {
+	if _, ok := c.running[name]; ok || c.start == nil {
+		return
+	}
+	stop := make(chan struct{})
+	c.running[name] = stop
+	c.start(name, stop)
+}
+
+// stopped forgets an exchange whose connection ended, so adding it again reconnects
+func (c *control) stopped(name exchange.ExchangeName, stop <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running[name] == stop {
+		delete(c.running, name)
+	}
+}
+
+// logEvery returns the interval between logged stats
+func (c *control) logEvery() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.logInterval
+}
+
+// AdminState implements server.Controller
+func (c *control) AdminState() server.AdminState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := server.AdminState{
+		Symbol:    c.symbol,
+		Exchanges: make([]string, 0, len(c.selected)),
+		Running:   make([]string, 0, len(c.running)),
+		Intervals: map[string]string{logIntervalName: c.logInterval.String()},
+	}
+	for _, name := range c.selected {
+		state.Exchanges = append(state.Exchanges, string(name))
+	}
+	for name := range c.running {
+		state.Running = append(state.Running, string(name))
+	}
+	sort.Strings(state.Running)
+	for _, name := range factory.ListRegistered() {
+		state.Available = append(state.Available, string(name))
+	}
+	if c.storage != nil {
+		enabled := c.storage.Enabled()
+		state.CollectorEnabled = &enabled
+	}
+	for name, col := range c.collectors {
+		state.Intervals[name] = col.Interval().String()
+	}
+	return state
+}
+
+// SetCollectorEnabled implements server.Controller
+func (c *control) SetCollectorEnabled(enabled bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.storage == nil {
+		return fmt.Errorf("no database configured: %w", server.ErrUnavailable)
+	}
+	c.storage.SetEnabled(enabled)
+	return nil
+}
+
+// AddExchange implements server.Controller; adding an exchange whose connection failed
+// reconnects it
+func (c *control) AddExchange(name string) error {
+	if !factory.ValidateExchangeName(name) {
+		return fmt.Errorf("unknown exchange %s: %w", name, server.ErrNotFound)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	exchangeName := exchange.ExchangeName(name)
+	if !slices.Contains(c.selected, exchangeName) {
+		c.selected = append(c.selected, exchangeName)
+		logger("admin").Info("Adding exchange", "event", "exchange_added", logging.KeyExchange, name)
+	}
+	c.launch(exchangeName)
+	return nil
+}
+
+// RemoveExchange implements server.Controller
+func (c *control) RemoveExchange(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	exchangeName := exchange.ExchangeName(name)
+	i := slices.Index(c.selected, exchangeName)
+	if i < 0 {
+		return fmt.Errorf("%s is not monitored: %w", name, server.ErrNotFound)
+	}
+	c.selected = slices.Delete(c.selected, i, i+1)
+	if stop, ok := c.running[exchangeName]; ok {
+		close(stop)
+		delete(c.running, exchangeName)
+	}
+	logger("admin").Info("Removing exchange", "event", "exchange_removed", logging.KeyExchange, name)
+	return nil
+}
+
+// SwitchSymbol implements server.Controller; the exchanges reconnect in the background
+func (c *control) SwitchSymbol(symbol string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if symbol == c.symbol {
+		return nil
+	}
+	c.symbol = symbol
+	for {
+		select {
+		case c.symbols <- symbol:
+			return nil
+		default:
+		}
+		select {
+		case <-c.symbols:
+		default:
+		}
+	}
+}
+
+// SetIntervals implements server.Controller
+func (c *control) SetIntervals(intervals map[string]time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range intervals {
+		if _, ok := c.collectors[name]; !ok && name != logIntervalName {
+			names := []string{logIntervalName}
+			for known := range c.collectors {
+				names = append(names, known)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown interval %s (want %s)", name, strings.Join(names, ", "))
+		}
+	}
+	for name, interval := range intervals {
+		if name == logIntervalName {
+			c.logInterval = interval
+			select {
+			case c.logReset <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		c.collectors[name].SetInterval(interval)
+	}
+	logger("admin").Info("Intervals changed", "event", "intervals_changed", "intervals", intervals)
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"github.com/imajinl/crypto-orderbook/internal/bus"
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// subscribe connects every enabled output to the events the connections publish
+func (out outputs) subscribe() {
+	events := out.events
+	// The books register with everything that reads them, and with the control for band changes
+	events.AttachBooks(out.control)
+	for _, c := range out.collectors {
+		events.AttachBooks(c)
+	}
+	if out.server != nil {
+		events.AttachBooks(out.server)
+		events.Books.Subscribe(func(event bus.BookEvent) {
+			if event.Removed {
+				out.metrics.UnregisterExchange(event.Exchange)
+				return
+			}
+			out.server.RegisterFeed(event.Exchange, event.Feed)
+			out.metrics.RegisterExchange(event.Exchange, event.Book, event.Feed)
+		})
+	}
+	if out.heatmap != nil {
+		events.AttachBooks(out.heatmap)
+	}
+	if out.arbitrage != nil {
+		events.AttachBooks(out.arbitrage)
+	}
+	if out.divergence != nil {
+		events.AttachBooks(out.divergence)
+	}
+	if out.alerts != nil {
+		events.AttachBooks(out.alerts)
+	}
+	if out.capture != nil {
+		events.AttachBooks(out.capture)
+	}
+	if out.basis != nil {
+		events.AttachBooks(out.basis)
+	}
+	if out.resiliency != nil {
+		events.AttachBooks(out.resiliency)
+	}
+	if out.share != nil {
+		events.AttachBooks(out.share)
+	}
+	if out.volatility != nil {
+		events.AttachBooks(out.volatility)
+	}
+	if out.index != nil {
+		events.AttachBooks(out.index)
+	}
+	if out.spoofing != nil {
+		events.AttachBooks(out.spoofing)
+	}
+	if out.flow != nil || out.funding != nil {
+		events.Books.Subscribe(func(event bus.BookEvent) {
+			if !event.Removed {
+				return
+			}
+			if out.flow != nil {
+				out.flow.UnregisterExchange(event.Exchange)
+			}
+			if out.funding != nil {
+				out.funding.UnregisterExchange(event.Exchange)
+			}
+		})
+	}
+	if out.tui != nil {
+		events.AttachBooks(out.tui)
+	}
+
+	// Depth updates are archived and streamed as they arrive
+	if out.recorder != nil {
+		events.Depth.Subscribe(func(event bus.DepthEvent) {
+			out.recorder.RecordUpdate(exchange.ExchangeName(event.Exchange), event.Update)
+		})
+	}
+	if out.publisher != nil {
+		events.Depth.Subscribe(func(event bus.DepthEvent) {
+			end := event.Trace.Stage(telemetry.StagePublish, attribute.String("sink", "publisher"))
+			err := out.publisher.PublishDepth(event.Symbol, event.Update)
+			if err != nil {
+				logging.For(event.Exchange, event.Symbol).Error("Failed to publish update", "event", "publish_failed",
+					"final_update_id", event.Update.FinalUpdateID, "error", err)
+			}
+			end(err)
+		})
+	}
+	if out.relay != nil {
+		events.Depth.Subscribe(func(event bus.DepthEvent) {
+			// Failures are counted by the relay and reported on shutdown
+			end := event.Trace.Stage(telemetry.StagePublish, attribute.String("sink", "relay"))
+			out.relay.PublishDepth(event.Symbol, event.Update)
+			end(nil)
+		})
+	}
+	if out.server != nil {
+		events.Depth.Subscribe(func(event bus.DepthEvent) {
+			end := event.Trace.Stage(telemetry.StagePublish, attribute.String("sink", "server"))
+			out.server.PublishDepth(event.Symbol, event.Update)
+			end(nil)
+		})
+	}
+
+	// Trades feed the taker flow and spoofing detection, and are stored with -db-trades
+	if out.flow != nil {
+		events.Trades.Subscribe(func(event bus.TradeEvent) {
+			out.flow.Record(event.Exchange, event.Trade)
+		})
+	}
+	if out.spoofing != nil {
+		events.Trades.Subscribe(func(event bus.TradeEvent) {
+			out.spoofing.Record(event.Exchange, event.Trade)
+		})
+	}
+	if out.trades != nil {
+		events.Trades.Subscribe(func(event bus.TradeEvent) {
+			stored, err := collector.NewTrade(event.Symbol, event.Trade)
+			if err != nil {
+				logging.For(event.Exchange, event.Symbol).Warn("Failed to convert trade", "event", "trade_invalid",
+					"trade_id", event.Trade.TradeID, "error", err)
+				return
+			}
+			out.trades.Record(stored)
+		})
+	}
+	if out.funding != nil {
+		events.Funding.Subscribe(func(event bus.FundingEvent) {
+			out.funding.Record(event.Exchange, event.Funding)
+		})
+	}
+}
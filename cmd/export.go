@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/config"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/storage"
+)
+
+// exportChunk bounds the snapshots read from the database at once
+const exportChunk = time.Hour
+
+// snapshotReader is a storage driver whose snapshots can be read back
+type snapshotReader interface {
+	SnapshotsBetween(table string, from, to time.Time) ([]*database.OrderbookSnapshotAPI, error)
+}
+
+// export writes the stored snapshots of a time range as JSON lines
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	driver := flags.String("db-driver", os.Getenv("DB_DRIVER"), "Storage driver to read: sqlite, postgres or supabase (default)")
+	dsn := flags.String("db-dsn", os.Getenv("DB_DSN"), "Connection string of the storage driver")
+	path := flags.String("db-path", os.Getenv("DB_PATH"), "SQLite database file (default book.db)")
+	table := flags.String("table", "orderbook_snapshots", "Table to read, e.g. a retention rollup such as orderbook_snapshots_1m")
+	fromText := flags.String("from", "1h", "Start of the range: RFC 3339 time or a duration before now, e.g. 24h")
+	toText := flags.String("to", "", "End of the range: RFC 3339 time or a duration before now (default now)")
+	exchangeName := flags.String("exchange", "", "Only export the snapshots of this exchange")
+	symbol := flags.String("symbol", "", "Only export the snapshots of this symbol")
+	outPath := flags.String("out", "-", "File to write, - for stdout")
+	flags.Parse(args)
+
+	now := time.Now()
+	from, err := parseInstant(*fromText, now)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	to := now
+	if *toText != "" {
+		if to, err = parseInstant(*toText, now); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("invalid range: -from %v is not before -to %v", from, to)
+	}
+
+	client, err := storage.New(config.StorageConfig{Driver: *driver, DSN: *dsn, Path: *path})
+	if err != nil {
+		return fmt.Errorf("storage setup failed: %w", err)
+	}
+	defer client.Close()
+	reader, ok := client.(snapshotReader)
+	if !ok {
+		return fmt.Errorf("the %s driver cannot read snapshots back (want sqlite, postgres or supabase)", driverName(*driver))
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "-" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *outPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	buffered := bufio.NewWriter(out)
+	encoder := json.NewEncoder(buffered)
+
+	written := 0
+	// Bounds are whole seconds for SQLite; the last second is included whole
+	last := to.Truncate(time.Second).Add(time.Second)
+	for start := from.Truncate(time.Second); start.Before(last); start = start.Add(exportChunk) {
+		end := start.Add(exportChunk)
+		if end.After(last) {
+			end = last
+		}
+		snapshots, err := reader.SnapshotsBetween(*table, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshots: %w", err)
+		}
+		for _, s := range snapshots {
+			if *exchangeName != "" && !strings.EqualFold(s.Exchange, *exchangeName) || *symbol != "" && s.Symbol != *symbol {
+				continue
+			}
+			if err := encoder.Encode(s); err != nil {
+				return fmt.Errorf("failed to write snapshot: %w", err)
+			}
+			written++
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write snapshots: %w", err)
+	}
+	logger("export").Info("Exported snapshots", "event", "exported", "snapshots", written, "table", *table,
+		"from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339))
+	return nil
+}
+
+// parseInstant reads a time as RFC 3339 or as a duration before now
+func parseInstant(text string, now time.Time) (time.Time, error) {
+	if ago, err := time.ParseDuration(text); err == nil {
+		return now.Add(-ago), nil
+	}
+	t, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want an RFC 3339 time or a duration like 24h, got %q", text)
+	}
+	return t, nil
+}
@@ -4,180 +4,1275 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
-	"orderbook/internal/collector"
-	"orderbook/internal/config"
-	"orderbook/internal/database"
-	"orderbook/internal/exchange"
-	"orderbook/internal/factory"
-	"orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/alerts"
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/bus"
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/config"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/factory"
+	"github.com/imajinl/crypto-orderbook/internal/heatmap"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/metrics"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/output"
+	"github.com/imajinl/crypto-orderbook/internal/publisher"
+	"github.com/imajinl/crypto-orderbook/internal/recorder"
+	"github.com/imajinl/crypto-orderbook/internal/relay"
+	"github.com/imajinl/crypto-orderbook/internal/retention"
+	"github.com/imajinl/crypto-orderbook/internal/server"
+	"github.com/imajinl/crypto-orderbook/internal/storage"
+	"github.com/imajinl/crypto-orderbook/internal/symbols"
+	"github.com/imajinl/crypto-orderbook/internal/telemetry"
+	"github.com/imajinl/crypto-orderbook/internal/tui"
+	"github.com/imajinl/crypto-orderbook/internal/types"
 
 	"github.com/shopspring/decimal"
+	"golang.org/x/sync/errgroup"
 )
 
-func main() {
+// run monitors the books live with the flags in args; command is run, record or replay,
+// which differ only in the defaults of their flags. An error means a flag was invalid, setup
+// failed or shutdown did not finish
+func run(command string, args []string) error {
+	settings, err := parseRun(command, args)
+	if err != nil {
+		return err
+	}
+	logging.Setup(settings.Logging)
+
+	if settings.Telemetry != nil {
+		shutdown, err := telemetry.Setup(context.Background(), *settings.Telemetry)
+		if err != nil {
+			return fmt.Errorf("OpenTelemetry setup failed: %w", err)
+		}
+		logger("main").Info("Exporting OpenTelemetry spans and metrics", "endpoint", settings.Telemetry.Endpoint, "sample_ratio", settings.Telemetry.SampleRatio)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				logger("main").Error("Failed to flush OpenTelemetry data", "event", "close_failed", "error", err)
+			}
+		}()
+	}
+
+	if settings.SymbolOverrides != "" {
+		if err := symbols.LoadOverrides(settings.SymbolOverrides); err != nil {
+			return fmt.Errorf("failed to load symbol overrides: %w", err)
+		}
+		logger("main").Info("Loaded symbol overrides", "path", settings.SymbolOverrides)
+	}
+
+	// Ctrl-C or SIGTERM cancels the context everything runs on; once it is cancelled a second
+	// one kills the process without waiting for the shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	for _, ignored := range settings.Ignored {
+		logger("main").Warn(ignored, "event", "config_ignored")
+	}
+	logOptions(settings.Monitor)
+	return runMultiExchange(ctx, settings.Monitor)
+}
+
+// runSettings is what the flags of run, record and replay select
+type runSettings struct {
+	Logging         logging.Config
+	Telemetry       *telemetry.Config // OTLP export of spans and metrics, nil disables it
+	SymbolOverrides string            // File pinning native symbols, empty for none
+	Ignored         []string          // Flags without effect, warned about once logging is set up
+	Monitor         monitorOptions
+}
+
+// parseRun parses the flags of command, with the config file they name applied over them
+func parseRun(command string, args []string) (runSettings, error) {
+	fixedPointDefault, _ := strconv.ParseBool(os.Getenv("FIXED_POINT"))
+
+	// Recording archives the feeds instead of storing snapshots, and a replay plays back
+	// the file given first instead of connecting to the exchanges
+	exchangesDefault, recordDirDefault, replayFileDefault := os.Getenv("EXCHANGES"), os.Getenv("RECORD_DIR"), os.Getenv("REPLAY_FILE")
+	switch command {
+	case "record":
+		if recordDirDefault == "" {
+			recordDirDefault = "recordings"
+		}
+	case "replay":
+		exchangesDefault = string(exchange.File)
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			replayFileDefault, args = args[0], args[1:]
+		}
+	}
+
 	// Parse command line flags
-	var symbol = flag.String("symbol", "BTCUSDT", "Trading symbol to monitor")
-	var logInterval = flag.Duration("log-interval", 10*time.Second, "Interval for logging orderbook stats")
-	var dbEnabled = flag.Bool("db-enabled", true, "Enable database storage")
-	var dbInterval = flag.Duration("db-interval", 20*time.Second, "Interval for database storage")
-	flag.Parse()
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	var symbol = flags.String("symbol", "BTCUSDT", "Trading symbol to monitor")
+	var configPath = flags.String("config", os.Getenv("CONFIG_FILE"), "JSON file of the symbol, exchanges, intervals and liquidity bands, overriding their flags and applied again whenever it changes (empty disables)")
+	var exchangeList = flags.String("exchanges", exchangesDefault, "Exchanges to connect to, e.g. binance,okx,kraken (empty connects to the default set)")
+	var logInterval = flags.Duration("log-interval", 10*time.Second, "Interval for logging orderbook stats")
+	var logLevel = flags.String("log-level", os.Getenv("LOG_LEVEL"), "Least severe log level written: debug, info (default), warn or error")
+	var logFormat = flags.String("log-format", os.Getenv("LOG_FORMAT"), "Log format: console (default) for people or json for log shippers such as Loki or ELK")
+	var otelEndpoint = flags.String("otel-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "Export OpenTelemetry spans and metrics of the message path over OTLP/gRPC to this URL, e.g. http://localhost:4317 (empty disables)")
+	var otelSample = flags.Float64("otel-sample", 0.01, "Fraction of depth updates and collector ticks traced with -otel-endpoint; stage durations are measured for all")
+	var outputName = flags.String("output", os.Getenv("OUTPUT"), "Format of the stats logged every -log-interval: text (default), table, json or csv")
+	var useTUI = flags.Bool("tui", os.Getenv("TUI") != "", "Show the books in a full-screen terminal UI instead of logging stats every -log-interval")
+	var tuiLevels = flags.Int("tui-levels", 15, "Levels per side in the ladder view of the terminal UI")
+	var shutdownTimeout = flags.Duration("shutdown-timeout", 10*time.Second, "Longest shutdown may take to flush pending writes and close connections before the process exits anyway")
+	var dbEnabled = flags.Bool("db-enabled", command == "run", "Enable database storage")
+	var dbInterval = flags.Duration("db-interval", 20*time.Second, "Interval for database storage")
+	var dbDriver = flags.String("db-driver", os.Getenv("DB_DRIVER"), "Storage driver: supabase (default), postgres, clickhouse, sqlite, parquet, csv, influx, grpc or none")
+	var dbDSN = flags.String("db-dsn", os.Getenv("DB_DSN"), "Connection string of the storage driver: Supabase URL, postgres://..., clickhouse://... or grpc[s]://host:port")
+	var dbAsync = flags.Bool("db-async", os.Getenv("DB_ASYNC") != "", "Queue Supabase inserts and send them in the background instead of inside the collector tick")
+	var dbAsyncQueue = flags.Int("db-async-queue", 1000, "Pending Supabase inserts held in memory before new ones are dropped")
+	var dbAsyncWindow = flags.Duration("db-async-window", time.Second, "Longest a queued Supabase row waits for a batch to fill")
+	var dbAsyncConcurrency = flags.Int("db-async-concurrency", 2, "Supabase insert requests in flight at once")
+	var dbDedup = flags.Bool("db-dedup", os.Getenv("DB_DEDUP") != "", "Skip storing snapshots of books that have not changed since their last write")
+	var dbDedupPrice = flags.String("db-dedup-price", os.Getenv("DB_DEDUP_PRICE"), "Price move that counts as a change for -db-dedup, in percent or basis points (e.g. 1bp; default any move)")
+	var dbDedupQuantity = flags.String("db-dedup-quantity", os.Getenv("DB_DEDUP_QUANTITY"), "Liquidity change that counts as a change for -db-dedup, in percent or basis points (e.g. 1; default any change)")
+	var dbLatest = flags.Bool("db-latest", os.Getenv("DB_LATEST") != "", "Also keep the newest snapshot of every book in orderbook_latest (sqlite, postgres, clickhouse, supabase)")
+	var dbCBBO = flags.Bool("db-cbbo", os.Getenv("DB_CBBO") != "", "Also store the consolidated best bid and offer across exchanges as exchange CONSOLIDATED")
+	var dbTouch = flags.Bool("db-touch", os.Getenv("DB_TOUCH") != "", "Store the mean, minimum and time-weighted size at the best bid and ask of every book since its previous snapshot")
+	var dbTrades = flags.Bool("db-trades", os.Getenv("DB_TRADES") != "", "Also store the public trades of every exchange (sqlite, postgres, clickhouse, supabase, parquet)")
+	var dbDedupMaxAge = flags.Duration("db-dedup-max-age", 0, "Store unchanged books at least this often with -db-dedup (0 never)")
+	var clickhouseDSN = flags.String("clickhouse-dsn", os.Getenv("CLICKHOUSE_DSN"), "ClickHouse DSN, used by the clickhouse driver when -db-dsn is empty")
+	var dbPath = flags.String("db-path", os.Getenv("DB_PATH"), "SQLite database file (default book.db), Parquet archive directory (default parquet) or CSV directory (default csv)")
+	var parquetRotateSize = flags.String("parquet-rotate-size", os.Getenv("PARQUET_ROTATE_MB"), "Megabytes written to a Parquet file before it is rotated (default 128)")
+	var parquetRotateInterval = flags.Duration("parquet-rotate-interval", time.Hour, "Longest a Parquet file stays open before it is rotated")
+	var parquetUpload = flags.String("parquet-upload", os.Getenv("PARQUET_UPLOAD"), "Command run for each finished Parquet file, with {path} and {key}, e.g. 'aws s3 cp {path} s3://bucket/{key}'")
+	var csvColumns = flags.String("csv-columns", os.Getenv("CSV_COLUMNS"), "Comma-separated CSV columns, e.g. timestamp,exchange,mid_price,imbalance_5 (default the snapshot columns)")
+	var csvPeriod = flags.Duration("csv-period", 24*time.Hour, "Time covered by one CSV file per exchange and symbol, 24h or a divisor such as 1h")
+	var csvRotateSize = flags.String("csv-rotate-size", os.Getenv("CSV_ROTATE_MB"), "Megabytes written to a CSV file before the next part is started (default no limit)")
+	var influxURL = flags.String("influx-url", os.Getenv("INFLUX_URL"), "InfluxDB v2 URL for the influx driver, e.g. http://localhost:8086 (token from INFLUX_TOKEN)")
+	var influxOrg = flags.String("influx-org", os.Getenv("INFLUX_ORG"), "InfluxDB organization")
+	var influxBucket = flags.String("influx-bucket", os.Getenv("INFLUX_BUCKET"), "InfluxDB bucket")
+	var retentionSpec = flags.String("retention", os.Getenv("RETENTION"), "Delete and downsample old snapshots, e.g. raw=7d,1m=365d keeps raw snapshots 7 days and 1m averages a year")
+	var retentionInterval = flags.Duration("retention-interval", time.Hour, "Interval between retention runs")
+	var dbDepth = flags.String("db-depth", os.Getenv("DB_DEPTH_LEVELS"), "Levels per side stored with each snapshot where the backend supports it (0 none)")
+	var dbDepthBucket = flags.String("db-depth-bucket", os.Getenv("DB_DEPTH_BUCKET"), "Merge stored levels into price buckets of this width, in percent or basis points of mid (e.g. 5bp)")
+	var publishDriver = flags.String("publish", os.Getenv("PUBLISH_DRIVER"), "Publish depth updates and stats snapshots to a broker: kafka or nats (empty disables)")
+	var kafkaBrokers = flags.String("kafka-brokers", os.Getenv("KAFKA_BROKERS"), "Comma-separated Kafka bootstrap brokers, e.g. localhost:9092")
+	var natsURL = flags.String("nats-url", os.Getenv("NATS_URL"), "NATS server URL (default nats://127.0.0.1:4222)")
+	var natsStream = flags.String("nats-stream", os.Getenv("NATS_STREAM"), "JetStream stream persisting published messages (empty publishes on core NATS only)")
+	var depthTopic = flags.String("publish-depth-topic", os.Getenv("PUBLISH_DEPTH_TOPIC"), "Topic or subject prefix for normalized depth updates (default orderbook.depth)")
+	var statsTopic = flags.String("publish-stats-topic", os.Getenv("PUBLISH_STATS_TOPIC"), "Topic or subject prefix for stats snapshots (default orderbook.stats)")
+	var publishInterval = flags.Duration("publish-interval", 5*time.Second, "Interval between published stats snapshots")
+	var redisURL = flags.String("redis-url", os.Getenv("REDIS_URL"), "Keep the latest stats and levels of every book in Redis, e.g. redis://localhost:6379/0")
+	var redisInterval = flags.Duration("redis-interval", time.Second, "Interval between Redis cache updates")
+	var redisDepth = flags.String("redis-depth", os.Getenv("REDIS_DEPTH_LEVELS"), "Levels per side cached in Redis (default 20)")
+	var redisPublish = flags.Bool("redis-publish", os.Getenv("REDIS_PUBLISH") != "", "Also publish each cached value on a Redis channel named like its key")
+	var httpAddr = flags.String("http-addr", os.Getenv("HTTP_ADDR"), "Serve the live books and stats as JSON, a WebSocket stream and Prometheus metrics over HTTP on this address, e.g. :8080 (empty disables)")
+	var wsBackpressure = flags.String("ws-backpressure", os.Getenv("WS_BACKPRESSURE"), "What WebSocket clients of -http-addr that fall behind lose: drop-oldest (default) or conflate")
+	var wsQueue = flags.Int("ws-queue", 1000, "Messages held per WebSocket client before -ws-backpressure applies")
+	var wsStatsInterval = flags.Duration("ws-stats-interval", 5*time.Second, "Interval between stats snapshots sent to WebSocket clients")
+	var heatmapWindow = flags.Duration("heatmap-window", 0, "Keep this much bucketed depth of every book for /api/v1/heatmap on -http-addr, e.g. 15m (0 disables)")
+	var heatmapInterval = flags.Duration("heatmap-interval", time.Second, "Interval between heatmap columns")
+	var heatmapTick = flags.String("heatmap-tick", os.Getenv("HEATMAP_TICK"), "Heatmap bucket width in quote units (default picked from mid and -heatmap-range)")
+	var heatmapRange = flags.String("heatmap-range", os.Getenv("HEATMAP_RANGE"), "Depth kept in the heatmap either side of mid, in percent or basis points (default 1)")
+	var adminToken = flags.String("admin-token", os.Getenv("ADMIN_TOKEN"), "Serve the admin API under /api/v1/admin on -http-addr to requests bearing this token (empty disables)")
+	var flowWindow = flags.Duration("flow-window", 0, "Track the cumulative volume delta of the public trades, with taker volumes and buy ratio over this trailing window, e.g. 5m (0 disables)")
+	var largeTrade = flags.String("large-trade", os.Getenv("LARGE_TRADE"), "Log trades of at least this quote notional as large and count them in the flow, e.g. 100000 (needs -flow-window)")
+	var resiliencyWindow = flags.Duration("resiliency-window", 15*time.Minute, "Score how quickly the depth within 0.5% of mid recovers after large depletions over this trailing window (0 disables)")
+	var volatilityWindows = flags.String("volatility-windows", os.Getenv("VOLATILITY_WINDOWS"), "Track the realized volatility of every mid and of the CBBO mid over these trailing windows, e.g. 1m,5m,1h (empty disables)")
+	var indexWeighting = flags.String("index", os.Getenv("INDEX"), "Compute an index price across the venues weighted equal, by liquidity within 0.5% of mid or by taker volume (needs -flow-window), stored with the consolidated row and served under /api/v1/index (empty disables)")
+	var indexDeviation = flags.String("index-max-deviation", "1%", "Leave venues whose mid is further than this from the median mid out of the -index price, in percent or basis points")
+	var indexVenues = flags.Int("index-min-venues", 1, "Venues the -index price needs after trimming outliers and stale books")
+	var marketShare = flags.Bool("market-share", false, "Track each venue's share of the liquidity within each band and of the time at the consolidated best bid and ask, stored with the snapshots and served under /api/v1/market-share")
+	var spoofing = flags.Bool("spoofing", false, "Detect levels placed and pulled without trading, repeatedly at one price (spoofing) or several at once (layering), on venues with a trades feed")
+	var spoofSize = flags.String("spoof-size", os.Getenv("SPOOF_SIZE"), "Size in base units a pulled level must reach to count for -spoofing (default 3 times the mean level size)")
+	var spoofAge = flags.Duration("spoof-max-age", 5*time.Second, "Oldest a pulled level may be to count for -spoofing")
+	var spoofRepeats = flags.Int("spoof-repeats", 3, "Pulls at one price within a minute that flag spoofing")
+	var spoofLayers = flags.Int("spoof-layers", 3, "Levels of one side pulled together that flag layering")
+	var oiWindow = flags.Duration("oi-window", time.Hour, "Trailing window of the open interest change of perpetual markets")
+	var basisPairs = flags.String("basis-pairs", os.Getenv("BASIS_PAIRS"), "Spot and perpetual exchanges to compute the basis between, as spot=perp pairs, e.g. binance=binancef (default the venues with both books, none disables)")
+	var arbSizes = flags.String("arb-sizes", os.Getenv("ARB_SIZES"), "Order sizes in base units to detect arbitrage between exchanges for, e.g. 0.1,1 (empty disables)")
+	var makerFee = flags.String("maker-fee", "2bp", "Maker fee of exchanges missing from -fees, in percent or basis points; negative for a rebate")
+	var takerFee = flags.String("taker-fee", "10bp", "Taker fee of exchanges missing from -fees, in percent or basis points")
+	var feeList = flags.String("fees", os.Getenv("FEES"), "Maker and taker fee per exchange, e.g. binance=2bp/4bp,okx=-0.5bp/5bp; a single fee sets the taker fee")
+	var effectiveSpread = flags.Bool("effective-spread", false, "Report the spread of every book after the maker and taker fees of its venue, stored with the snapshots")
+	var arbThreshold = flags.String("arb-threshold", os.Getenv("ARB_THRESHOLD"), "Net spread after fees an arbitrage must exceed to be reported, in percent or basis points (default any profit)")
+	var arbInterval = flags.Duration("arb-interval", time.Second, "Interval between arbitrage evaluations")
+	var alertsFile = flags.String("alerts", os.Getenv("ALERTS_FILE"), "JSON file of alert rules (spread, liquidity drop, stale feed, arbitrage) and the Telegram, Discord, Slack or webhook notifiers they go to (empty disables)")
+	var alertsCapture = flags.String("alerts-capture", os.Getenv("ALERTS_CAPTURE_DIR"), "Directory the full books of every exchange around each fired alert are written to with -alerts (empty disables)")
+	var alertsCaptureWindow = flags.Duration("alerts-capture-window", 10*time.Second, "Time captured before and after each alert with -alerts-capture")
+	var divergence = flags.String("divergence", os.Getenv("DIVERGENCE"), "Report venues whose mid strays from the median mid of the venues by more than this, in percent or basis points, e.g. 20bp (empty disables)")
+	var divergenceFor = flags.Duration("divergence-for", 10*time.Second, "How long a mid must stay beyond -divergence before it is reported")
+	var grpcAddr = flags.String("grpc-addr", os.Getenv("GRPC_ADDR"), "Serve book queries and update and stats streams over gRPC (OrderbookService) on this address, e.g. :9090 (empty disables)")
+	var recordDir = flags.String("record-dir", recordDirDefault, "Archive raw feed messages and replayable books of every exchange under this directory (empty disables)")
+	var replayFile = flags.String("replay-file", replayFileDefault, "Book recording the file exchange plays back, e.g. recordings/binance/book-20250101T000000Z.ndjson.gz")
+	var recordCompression = flags.String("record-compression", os.Getenv("RECORD_COMPRESSION"), "Compression of recordings: gzip (default) or zstd")
+	var recordRotate = flags.Duration("record-rotate-interval", time.Hour, "Longest a recording file stays open before it is rotated")
+	var staleAfter = flags.Duration("stale-after", 30*time.Second, "Flag books on the console that have not updated for this long")
+	var rollingWindow = flags.Duration("rolling-window", 0, "Trailing window for rolling spread and mid volatility, e.g. 5m (0 disables)")
+	var ofiWindows = flags.String("ofi-windows", os.Getenv("OFI_WINDOWS"), "Compute the order flow imbalance at the top of every book over these trailing windows, e.g. 10s,1m,5m (empty disables)")
+	var staleResync = flags.Duration("stale-resync", 0, "Resync books that have not updated for this long (0 never)")
+	var fixedPoint = flags.Bool("fixed-point", fixedPointDefault, "Store prices and sizes as scaled int64, with decimals from -symbol-overrides (default 8)")
+	var symbolOverrides = flags.String("symbol-overrides", os.Getenv("SYMBOL_OVERRIDES_FILE"), "JSON file pinning native symbols per exchange")
+	var gapPolicyName = flags.String("gap-policy", os.Getenv("GAP_POLICY"), "Reaction to sequence gaps: buffer, log, drop or resync (override per exchange with <NAME>_GAP_POLICY)")
+	var crossPolicyName = flags.String("cross-policy", os.Getenv("CROSS_POLICY"), "Reaction to crossed or locked books: log, trim or resync")
+	var bandList = flags.String("bands", os.Getenv("LIQUIDITY_BANDS"), "Liquidity bands around mid, in percent or basis points (e.g. 0.1,1,5 or 10bp,25bp)")
+	var depthList = flags.String("imbalance-depths", os.Getenv("IMBALANCE_DEPTHS"), "Top-N level counts for imbalance and weighted mid (e.g. 1,5,10)")
+	var maxDepth = flags.String("max-depth", os.Getenv("MAX_DEPTH"), "Levels kept per side, dropping the rest (0 keeps all)")
+	var maxBand = flags.String("max-band", os.Getenv("MAX_BAND"), "Drop levels further than this from mid, in percent or basis points (e.g. 5 or 500bp)")
+	var curveList = flags.String("depth-curve", os.Getenv("DEPTH_CURVE"), "Offsets from mid at which the cumulative depth curve is stored (e.g. 10bp,25bp,50bp,1)")
+	var impactList = flags.String("impact-sizes", os.Getenv("IMPACT_SIZES"), "Order sizes in base units whose market impact is stored with snapshots (e.g. 1,10,50)")
+	var notionalList = flags.String("slippage-notionals", os.Getenv("SLIPPAGE_NOTIONALS"), "Order notionals in quote currency whose slippage is stored with snapshots (e.g. 10000,100000,1000000,10000000)")
+	flags.Parse(args)
+	if command == "replay" && *replayFile == "" {
+		fmt.Fprintln(os.Stderr, "Usage: orderbook replay FILE [flags]")
+		os.Exit(2)
+	}
+
+	settings := runSettings{SymbolOverrides: *symbolOverrides}
+	level, err := logging.ParseLevel(*logLevel)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -log-level: %w", err)
+	}
+	format, err := logging.ParseFormat(*logFormat)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -log-format: %w", err)
+	}
+	settings.Logging = logging.Config{Level: level, Format: format}
+	if *otelEndpoint != "" {
+		settings.Telemetry = &telemetry.Config{Endpoint: *otelEndpoint, SampleRatio: *otelSample}
+	}
+
+	// The config file overrides the flags of the settings it has
+	var file config.File
+	if *configPath != "" {
+		if file, err = config.LoadFile(*configPath); err != nil {
+			return runSettings{}, fmt.Errorf("invalid -config: %w", err)
+		}
+		if file.Symbol != "" {
+			*symbol = file.Symbol
+		}
+		intervals := map[string]*time.Duration{logIntervalName: logInterval, "db": dbInterval, "publish": publishInterval, "redis": redisInterval, "ws": wsStatsInterval}
+		for name, interval := range file.Intervals {
+			if intervals[name] == nil {
+				return runSettings{}, fmt.Errorf("invalid -config: unknown interval %s (want log, db, publish, redis or ws)", name)
+			}
+			*intervals[name] = interval
+		}
+	}
+
+	gapPolicy, err := types.ParseGapPolicy(*gapPolicyName)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -gap-policy: %w", err)
+	}
+
+	crossPolicy, err := types.ParseCrossPolicy(*crossPolicyName)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -cross-policy: %w", err)
+	}
+
+	bands, err := types.ParseLiquidityBands(*bandList)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -bands: %w", err)
+	}
+	if file.Bands != nil {
+		bands = file.Bands
+	}
+
+	depths, err := parseDepths(*depthList)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -imbalance-depths: %w", err)
+	}
+
+	levels := 0
+	if *maxDepth != "" {
+		if levels, err = strconv.Atoi(*maxDepth); err != nil || levels < 0 {
+			return runSettings{}, fmt.Errorf("invalid -max-depth: %q", *maxDepth)
+		}
+	}
+
+	band := decimal.Zero
+	if *maxBand != "" {
+		if band, err = types.ParseBand(*maxBand); err != nil {
+			return runSettings{}, fmt.Errorf("invalid -max-band: %w", err)
+		}
+	}
 
-	// Set up signal handling
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
+	exchanges, err := factory.ParseExchanges(*exchangeList)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -exchanges: %w", err)
+	}
+	if len(file.Exchanges) > 0 {
+		exchanges = file.Exchanges
+	}
+
+	ofi, err := parseWindows(*ofiWindows)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -ofi-windows %q: want durations like 10s,1m,5m", *ofiWindows)
+	}
+
+	// Orderbook settings shared by every exchange
+	app := config.Default().App
+	app.GapPolicy = gapPolicy
+	app.CrossPolicy = crossPolicy
+	app.LiquidityBands = bands
+	app.ImbalanceDepths = depths
+	app.MaxDepth = levels
+	app.MaxBand = band
+	app.StaleAfter = *staleAfter
+	app.StaleResync = *staleResync
+	app.RollingWindow = *rollingWindow
+	app.OFIWindows = ofi
+	app.FixedPoint = *fixedPoint
+	app.Exchanges = exchanges
+	app.ConfigFile = *configPath
+	app.ShutdownTimeout = *shutdownTimeout
+	app.ReplayFile = *replayFile
+
+	impactSizes, err := parseSizes(*impactList)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -impact-sizes: %w", err)
+	}
+	slippageNotionals, err := parseSizes(*notionalList)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -slippage-notionals: %w", err)
+	}
 
-	log.Printf("Starting multi-exchange orderbook monitor for %s", *symbol)
-	log.Printf("Log interval: %v", *logInterval)
-	if *dbEnabled {
-		log.Printf("Database storage enabled with interval: %v", *dbInterval)
+	var curveOffsets []decimal.Decimal
+	if *curveList != "" {
+		if curveOffsets, err = types.ParseLiquidityBands(*curveList); err != nil {
+			return runSettings{}, fmt.Errorf("invalid -depth-curve: %w", err)
+		}
 	}
 
-	runMultiExchange(*symbol, *logInterval, *dbEnabled, *dbInterval, interrupt)
+	store := config.StorageConfig{Driver: *dbDriver, DSN: *dbDSN, Path: *dbPath, Latest: *dbLatest, Consolidated: *dbCBBO, Touch: *dbTouch, Trades: *dbTrades,
+		RotateInterval: *parquetRotateInterval, Upload: *parquetUpload,
+		Influx: database.InfluxConfig{URL: *influxURL, Token: os.Getenv("INFLUX_TOKEN"), Org: *influxOrg, Bucket: *influxBucket},
+		CSV:    database.CSVConfig{Period: *csvPeriod}}
+	if store.DSN == "" && store.Driver == "clickhouse" {
+		store.DSN = *clickhouseDSN
+	}
+	if *dbAsync {
+		store.Async = &database.SupabaseAsyncConfig{QueueSize: *dbAsyncQueue, BatchWindow: *dbAsyncWindow, Concurrency: *dbAsyncConcurrency}
+	}
+	if *dbDedup {
+		store.Dedup = &collector.Dedup{MaxAge: *dbDedupMaxAge}
+		for _, threshold := range []struct {
+			name, value string
+			target      *float64
+		}{{"-db-dedup-price", *dbDedupPrice, &store.Dedup.Price}, {"-db-dedup-quantity", *dbDedupQuantity, &store.Dedup.Quantity}} {
+			if threshold.value == "" {
+				continue
+			}
+			fraction, err := types.ParseBand(threshold.value)
+			if err != nil {
+				return runSettings{}, fmt.Errorf("invalid %s: %w", threshold.name, err)
+			}
+			*threshold.target = fraction.InexactFloat64()
+		}
+	}
+	if *parquetRotateSize != "" {
+		megabytes, err := strconv.ParseInt(*parquetRotateSize, 10, 64)
+		if err != nil || megabytes <= 0 {
+			return runSettings{}, fmt.Errorf("invalid -parquet-rotate-size: %q", *parquetRotateSize)
+		}
+		store.RotateSize = megabytes << 20
+	}
+	if *csvColumns != "" {
+		for _, column := range strings.Split(*csvColumns, ",") {
+			store.CSV.Columns = append(store.CSV.Columns, strings.TrimSpace(column))
+		}
+	}
+	if *csvRotateSize != "" {
+		megabytes, err := strconv.ParseInt(*csvRotateSize, 10, 64)
+		if err != nil || megabytes <= 0 {
+			return runSettings{}, fmt.Errorf("invalid -csv-rotate-size: %q", *csvRotateSize)
+		}
+		store.CSV.RotateSize = megabytes << 20
+	}
+	if *retentionSpec != "" {
+		policy, err := retention.ParsePolicy(*retentionSpec)
+		if err != nil {
+			return runSettings{}, fmt.Errorf("invalid -retention: %w", err)
+		}
+		store.Retention, store.RetentionInterval = &policy, *retentionInterval
+	}
+	if *dbDepth != "" {
+		if store.DepthLevels, err = strconv.Atoi(*dbDepth); err != nil || store.DepthLevels < 0 {
+			return runSettings{}, fmt.Errorf("invalid -db-depth: %q", *dbDepth)
+		}
+	}
+	if *dbDepthBucket != "" {
+		if store.DepthBucket, err = types.ParseBand(*dbDepthBucket); err != nil {
+			return runSettings{}, fmt.Errorf("invalid -db-depth-bucket: %w", err)
+		}
+	}
+
+	if *dbEnabled && store.Driver == storage.None {
+		*dbEnabled = false
+	}
+
+	publishing := publisherOptions{Driver: *publishDriver, Interval: *publishInterval, URL: *natsURL, Stream: *natsStream,
+		Topics: publisher.Topics{Depth: *depthTopic, Stats: *statsTopic}}
+	if *kafkaBrokers != "" {
+		publishing.Brokers = strings.Split(*kafkaBrokers, ",")
+	}
+
+	cache := cacheOptions{URL: *redisURL, Interval: *redisInterval, DepthLevels: 20, Publish: *redisPublish}
+	if *redisDepth != "" {
+		if cache.DepthLevels, err = strconv.Atoi(*redisDepth); err != nil || cache.DepthLevels < 0 {
+			return runSettings{}, fmt.Errorf("invalid -redis-depth: %q", *redisDepth)
+		}
+	}
+
+	recording := recorder.Config{Dir: *recordDir, RotateInterval: *recordRotate}
+	if recording.Compression, err = recorder.ParseCompression(*recordCompression); err != nil {
+		return runSettings{}, fmt.Errorf("invalid -record-compression: %w", err)
+	}
+
+	serving := server.Config{Addr: *httpAddr, GRPCAddr: *grpcAddr, ClientQueue: *wsQueue, StatsInterval: *wsStatsInterval, AdminToken: *adminToken}
+	if serving.AdminToken != "" && serving.Addr == "" {
+		settings.Ignored = append(settings.Ignored, "-admin-token has no effect without -http-addr")
+	}
+
+	var depthMap *heatmap.Config
+	if *heatmapWindow > 0 {
+		depthMap = &heatmap.Config{Window: *heatmapWindow, Interval: *heatmapInterval}
+		if *heatmapTick != "" {
+			if depthMap.Tick, err = decimal.NewFromString(*heatmapTick); err != nil || !depthMap.Tick.IsPositive() {
+				return runSettings{}, fmt.Errorf("invalid -heatmap-tick: %q", *heatmapTick)
+			}
+		}
+		if *heatmapRange != "" {
+			if depthMap.Range, err = types.ParseBand(*heatmapRange); err != nil {
+				return runSettings{}, fmt.Errorf("invalid -heatmap-range: %w", err)
+			}
+		}
+		if serving.Addr == "" {
+			settings.Ignored = append(settings.Ignored, "-heatmap-window has no effect without -http-addr")
+		}
+	}
+	if serving.Backpressure, err = server.ParseBackpressure(*wsBackpressure); err != nil {
+		return runSettings{}, fmt.Errorf("invalid -ws-backpressure: %w", err)
+	}
+
+	var fees analytics.Fees
+	if fees.Default.Maker, err = parseFee(*makerFee); err != nil {
+		return runSettings{}, fmt.Errorf("invalid -maker-fee: %w", err)
+	}
+	if fees.Default.Taker, err = parseFee(*takerFee); err != nil {
+		return runSettings{}, fmt.Errorf("invalid -taker-fee: %w", err)
+	}
+	if fees.Venues, err = parseFees(*feeList, fees.Default.Maker); err != nil {
+		return runSettings{}, fmt.Errorf("invalid -fees: %w", err)
+	}
+
+	var analysis analyticsOptions
+	if *effectiveSpread {
+		analysis.Fees = &fees
+	}
+	if *arbSizes != "" {
+		arbitrage := &analytics.ArbitrageConfig{Fees: fees, Interval: *arbInterval}
+		if arbitrage.Sizes, err = parseSizes(*arbSizes); err != nil {
+			return runSettings{}, fmt.Errorf("invalid -arb-sizes: %w", err)
+		}
+		if *arbThreshold != "" {
+			if arbitrage.Threshold, err = parseRate(*arbThreshold); err != nil {
+				return runSettings{}, fmt.Errorf("invalid -arb-threshold: %w", err)
+			}
+		}
+		analysis.Arbitrage = arbitrage
+	}
+	if *divergence != "" {
+		config := &analytics.DivergenceConfig{Duration: *divergenceFor}
+		if config.Threshold, err = parseRate(*divergence); err != nil || !config.Threshold.IsPositive() {
+			return runSettings{}, fmt.Errorf("invalid -divergence %q: want a positive rate", *divergence)
+		}
+		analysis.Divergence = config
+	}
+	if *flowWindow > 0 {
+		flow := &analytics.FlowConfig{Window: *flowWindow}
+		if *largeTrade != "" {
+			if flow.LargeTrade, err = decimal.NewFromString(*largeTrade); err != nil || flow.LargeTrade.IsNegative() {
+				return runSettings{}, fmt.Errorf("invalid -large-trade %q: want a non-negative notional", *largeTrade)
+			}
+		}
+		analysis.Flow = flow
+	} else if *largeTrade != "" {
+		settings.Ignored = append(settings.Ignored, "-large-trade has no effect without -flow-window")
+	}
+	analysis.Funding = &analytics.FundingConfig{Window: *oiWindow}
+	if *spoofing {
+		spoof := &analytics.SpoofingConfig{MaxAge: *spoofAge, Repeats: *spoofRepeats, Layers: *spoofLayers}
+		if *spoofSize != "" {
+			if spoof.MinSize, err = decimal.NewFromString(*spoofSize); err != nil || !spoof.MinSize.IsPositive() {
+				return runSettings{}, fmt.Errorf("invalid -spoof-size %q: want a positive size", *spoofSize)
+			}
+		}
+		analysis.Spoofing = spoof
+	}
+	if *resiliencyWindow > 0 {
+		analysis.Resiliency = &analytics.ResiliencyConfig{Window: *resiliencyWindow}
+	}
+	if *marketShare {
+		analysis.Share = &analytics.MarketShareConfig{}
+	}
+	if *volatilityWindows != "" {
+		volatility := &analytics.VolatilityConfig{}
+		if volatility.Windows, err = parseWindows(*volatilityWindows); err != nil || len(volatility.Windows) == 0 {
+			return runSettings{}, fmt.Errorf("invalid -volatility-windows %q: want durations like 1m,5m,1h", *volatilityWindows)
+		}
+		analysis.Volatility = volatility
+	}
+	if *alertsFile != "" {
+		rules, err := alerts.LoadConfig(*alertsFile)
+		if err != nil {
+			return runSettings{}, fmt.Errorf("invalid -alerts: %w", err)
+		}
+		analysis.Alerts = &rules
+	}
+	if *alertsCapture != "" {
+		if analysis.Alerts == nil {
+			return runSettings{}, fmt.Errorf("invalid -alerts-capture: it needs -alerts")
+		}
+		if *alertsCaptureWindow <= 0 {
+			return runSettings{}, fmt.Errorf("invalid -alerts-capture-window %v: want a positive duration", *alertsCaptureWindow)
+		}
+		analysis.Capture = &alerts.CaptureConfig{Dir: *alertsCapture, Before: *alertsCaptureWindow, After: *alertsCaptureWindow}
+	}
+	if *indexWeighting != "" {
+		index := &analytics.IndexConfig{MinVenues: *indexVenues}
+		if index.Weighting, err = analytics.ParseIndexWeighting(*indexWeighting); err != nil {
+			return runSettings{}, fmt.Errorf("invalid -index: %w", err)
+		}
+		if index.Weighting == analytics.WeightVolume && analysis.Flow == nil {
+			return runSettings{}, fmt.Errorf("invalid -index: volume weighting needs -flow-window")
+		}
+		if index.MaxDeviation, err = parseRate(*indexDeviation); err != nil || !index.MaxDeviation.IsPositive() {
+			return runSettings{}, fmt.Errorf("invalid -index-max-deviation %q: want a positive rate", *indexDeviation)
+		}
+		analysis.Index = index
+	}
+	if *basisPairs != "none" {
+		basis := &analytics.BasisConfig{}
+		if basis.Pairs, err = analytics.ParseBasisPairs(*basisPairs); err != nil {
+			return runSettings{}, fmt.Errorf("invalid -basis-pairs: %w", err)
+		}
+		analysis.Basis = basis
+	}
+
+	outputFormat, err := output.ParseFormat(*outputName)
+	if err != nil {
+		return runSettings{}, fmt.Errorf("invalid -output: %w", err)
+	}
+	console := output.New(outputFormat, os.Stdout, app.StaleAfter)
+
+	var terminal *tui.Config
+	if *useTUI {
+		terminal = &tui.Config{Levels: *tuiLevels, StaleAfter: app.StaleAfter}
+	}
+
+	settings.Monitor = monitorOptions{
+		Symbol:            *symbol,
+		LogInterval:       *logInterval,
+		DBEnabled:         *dbEnabled,
+		DBInterval:        *dbInterval,
+		Store:             store,
+		Publishing:        publishing,
+		Cache:             cache,
+		Recording:         recording,
+		Serving:           serving,
+		DepthMap:          depthMap,
+		Analysis:          analysis,
+		Terminal:          terminal,
+		Console:           console,
+		ImpactSizes:       impactSizes,
+		SlippageNotionals: slippageNotionals,
+		CurveOffsets:      curveOffsets,
+		App:               app,
+	}
+	return settings, nil
+}
+
+// logOptions logs what the monitor is set up to do
+func logOptions(options monitorOptions) {
+	logger("main").Info("Starting multi-exchange orderbook monitor", logging.KeySymbol, options.Symbol)
+	if options.Terminal == nil {
+		logger("main").Info("Logging stats", "interval", options.LogInterval)
+	}
+	store := options.Store
+	if options.DBEnabled {
+		logger("main").Info("Database storage enabled", "interval", options.DBInterval)
+		if len(options.ImpactSizes) > 0 {
+			logger("main").Info("Storing market impact", "sizes", options.ImpactSizes)
+		}
+		if len(options.SlippageNotionals) > 0 {
+			logger("main").Info("Storing slippage", "notionals", options.SlippageNotionals)
+		}
+		if len(options.CurveOffsets) > 0 {
+			logger("main").Info("Storing depth curve", "offsets", bandsText(options.CurveOffsets))
+		}
+		if store.DepthLevels > 0 && store.DepthBucket.IsPositive() {
+			logger("main").Info("Merging stored levels into buckets", "bucket", types.BandLabel(store.DepthBucket))
+		}
+		if store.Dedup != nil {
+			logger("main").Info("Skipping unchanged snapshots", "price_bp", store.Dedup.Price*10000, "liquidity_bp", store.Dedup.Quantity*10000, "max_age", store.Dedup.MaxAge)
+		}
+	}
+
+	app := options.App
+	logger("main").Info("Book policies", "gap_policy", app.GapPolicy, "cross_policy", app.CrossPolicy, "bands", bandsText(app.LiquidityBands))
+	if app.MaxDepth > 0 {
+		logger("main").Info("Keeping a limited depth", "levels", app.MaxDepth)
+	}
+	if app.FixedPoint {
+		logger("main").Info("Using fixed-point books")
+	}
+	if app.StaleResync > 0 {
+		logger("main").Info("Resyncing stale books", "after", app.StaleResync)
+	}
+	if app.MaxBand.IsPositive() {
+		logger("main").Info("Dropping levels far from mid", "max_band", types.BandLabel(app.MaxBand))
+	}
+}
+
+// outputs receive the books of every exchange besides the console
+type outputs struct {
+	events     *bus.Bus                 // Carries the updates, trades and books of every connection to the outputs below
+	collectors []*collector.Collector   // Storage, publishing and cache collectors books register with
+	publisher  *publisher.Publisher     // Receives every depth update, nil when publishing is off
+	recorder   *recorder.Recorder       // Archives snapshots and updates, nil when recording is off
+	relay      *relay.Client            // Streams every depth update, nil unless storing with the grpc driver
+	trades     *collector.TradeRecorder // Stores the public trades of every exchange, nil unless enabled
+	server     *server.Server           // Serves the books over HTTP, nil when the API is off
+	metrics    *metrics.Metrics         // Served by the API at /metrics, nil when the API is off
+	heatmap    *heatmap.Heatmap         // Served by the API at /api/v1/heatmap, nil unless enabled
+	arbitrage  *analytics.Arbitrage     // Reports arbitrage between exchanges, nil unless enabled
+	divergence *analytics.Divergence    // Reports mids straying from the median mid, nil unless enabled
+	flow       *analytics.Flow          // Taker flow from the trades of every exchange, nil unless enabled
+	funding    *analytics.Funding       // Funding and open interest of the perpetual exchanges
+	basis      *analytics.Basis         // Basis of perpetuals over the spot book of their venue, nil when disabled
+	resiliency *analytics.Resiliency    // Replenishment of every book after large depletions, nil when disabled
+	spoofing   *analytics.Spoofing      // Flags spoofing and layering on books with trades, nil unless enabled
+	share      *analytics.MarketShare   // Share of every book in the liquidity and best quotes, nil unless enabled
+	volatility *analytics.Volatility    // Realized volatility of every mid and the CBBO mid, nil unless enabled
+	fees       *analytics.Fees          // Fee schedules of the fee-adjusted spreads, nil unless enabled
+	index      *analytics.Index         // Index price across the books, nil unless enabled
+	alerts     *alerts.Engine           // Sends the alerts of the configured rules, nil unless enabled
+	capture    *alerts.Capture          // Writes the books around every fired alert, nil unless enabled
+	tui        *tui.UI                  // Shows the books in place of the logged stats, nil in plain log mode
+	console    output.Formatter         // Renders the logged stats
+	control    *control                 // Monitored exchanges and intervals, changed through the admin API
+}
+
+// setSymbol points every output at a new symbol before its books register
+func (out outputs) setSymbol(symbol string) {
+	for _, c := range out.collectors {
+		c.SetSymbol(symbol)
+	}
+	if out.server != nil {
+		out.server.SetSymbol(symbol)
+		out.metrics.SetSymbol(symbol)
+	}
+	if out.heatmap != nil {
+		out.heatmap.SetSymbol(symbol)
+	}
+	if out.arbitrage != nil {
+		out.arbitrage.SetSymbol(symbol)
+	}
+	if out.divergence != nil {
+		out.divergence.SetSymbol(symbol)
+	}
+	if out.alerts != nil {
+		out.alerts.SetSymbol(symbol)
+	}
+	if out.capture != nil {
+		out.capture.Reset()
+	}
+	if out.flow != nil {
+		out.flow.SetSymbol(symbol)
+	}
+	if out.spoofing != nil {
+		out.spoofing.SetSymbol(symbol)
+	}
+	if out.funding != nil {
+		out.funding.Reset()
+	}
+	if out.resiliency != nil {
+		out.resiliency.Reset()
+	}
+	if out.share != nil {
+		out.share.Reset()
+	}
+	if out.volatility != nil {
+		out.volatility.Reset()
+	}
+	if out.tui != nil {
+		out.tui.SetSymbol(symbol)
+	}
+	out.control.setSymbol(symbol)
 }
 
 type orderbookWithName struct {
 	name string
 	ob   *orderbook.OrderBook
+	caps exchange.Capabilities
 }
 
-const (
-	colorReset   = "\033[0m"
-	colorYellow  = "\033[33m"
-	colorGreen   = "\033[32m"
-	colorRed     = "\033[31m"
-	colorMagenta = "\033[35m"
-	colorBold    = "\033[1m"
-)
-
-func getExchangeNames() []exchange.ExchangeName {
-	return []exchange.ExchangeName{
-		exchange.Binancef,
-		exchange.Binance,
-		exchange.Bybitf,
-		exchange.Bybit,
-		exchange.Kraken,
-		exchange.OKX,
-		exchange.Coinbase,
-		exchange.Asterdexf,
-		exchange.BingX,
-		exchange.Hyperliquidf,
+func getExchangeNames(app config.AppConfig) []exchange.ExchangeName {
+	if len(app.Exchanges) > 0 {
+		return app.Exchanges
 	}
+	return factory.ListMonitored()
+}
+
+// workers run the background loops of the outputs until the context is cancelled, so shutdown
+// can wait for the last collection to be written before closing the clients they write to
+type workers struct {
+	ctx   context.Context
+	group *errgroup.Group
+}
+
+// start runs loop in the background with the context of the workers
+func (w workers) start(loop func(context.Context)) {
+	w.group.Go(func() error {
+		loop(w.ctx)
+		return nil
+	})
 }
 
-func runMultiExchange(initialSymbol string, logInterval time.Duration, dbEnabled bool, dbInterval time.Duration, interrupt chan os.Signal) {
-	ctx := context.Background()
+// monitorOptions is everything run parsed from its flags that runMultiExchange monitors with
+type monitorOptions struct {
+	Symbol            string               // Symbol monitored first
+	LogInterval       time.Duration        // Interval of the logged stats
+	DBEnabled         bool                 // Store snapshots with Store
+	DBInterval        time.Duration        // Interval between stored snapshots
+	Store             config.StorageConfig // Storage driver and what it stores
+	Publishing        publisherOptions     // Broker depth updates and stats are published to, no driver disables it
+	Cache             cacheOptions         // Redis latest-book cache, no URL disables it
+	Recording         recorder.Config      // Feed archive, no directory disables it
+	Serving           server.Config        // HTTP and gRPC API, no addresses disable it
+	DepthMap          *heatmap.Config      // Depth heatmaps served by the HTTP API, nil disables them
+	Analysis          analyticsOptions     // Cross-exchange analytics run on the books
+	Terminal          *tui.Config          // Terminal UI shown instead of the logged stats, nil disables it
+	Console           output.Formatter     // Renders the logged stats
+	ImpactSizes       []decimal.Decimal    // Order sizes whose market impact is stored
+	SlippageNotionals []decimal.Decimal    // Order notionals whose slippage is stored
+	CurveOffsets      []decimal.Decimal    // Offsets from mid of the stored depth curve
+	App               config.AppConfig     // Exchanges, book policies and shutdown timeout
+}
+
+// runMultiExchange monitors the books until ctx is cancelled or the terminal UI closes; an
+// error means setup or shutdown failed
+func runMultiExchange(ctx context.Context, options monitorOptions) error {
+	// Everything stops with ctx: on a signal, or when the terminal UI is closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	group, groupCtx := errgroup.WithContext(ctx)
+	work := workers{ctx: groupCtx, group: group}
 	orderbooksMap := make(map[string]*orderbook.OrderBook)
 	var obMutex sync.Mutex
-	currentSymbol := initialSymbol
+	currentSymbol := options.Symbol
 
 	// Initialize database client and collector if enabled
-	var dbClient database.SupabaseAPIClient
+	var dbClient collector.DatabaseClient
 	var dataCollector *collector.Collector
-	if dbEnabled {
-		// Get Supabase configuration from environment
-		baseURL, apiKey := getSupabaseConfig()
-
-		// Create API client
-		dbClient = *database.NewSupabaseAPIClient(baseURL, apiKey)
+	var tradeRecorder *collector.TradeRecorder
+	if options.DBEnabled {
+		client, err := storage.New(options.Store)
+		if err != nil {
+			return fmt.Errorf("storage setup failed: %w", err)
+		}
+		dbClient = client
+		logger("main").Info("Storing snapshots", "driver", driverName(options.Store.Driver))
+		if options.Store.Async != nil && driverName(options.Store.Driver) == "supabase" {
+			logger("main").Info("Supabase inserts run in the background", "queue", options.Store.Async.QueueSize, "writers", options.Store.Async.Concurrency)
+		}
+		if options.Store.DepthLevels > 0 {
+			if _, ok := dbClient.(collector.DepthWriter); ok {
+				logger("main").Info("Storing the top levels with each snapshot", "levels", options.Store.DepthLevels)
+			} else {
+				logger("main").Warn("Ignoring -db-depth, the driver does not store levels", "event", "config_ignored", "driver", driverName(options.Store.Driver))
+			}
+		}
+		if options.Store.Consolidated {
+			logger("main").Info("Storing the consolidated best bid and offer", logging.KeyExchange, analytics.ConsolidatedExchange)
+		}
+		if options.Store.Touch {
+			logger("main").Info("Storing the size at the best bid and ask over every interval")
+		}
+		if options.Store.Latest {
+			if _, ok := dbClient.(collector.LatestWriter); ok {
+				logger("main").Info("Keeping the newest snapshot of every book", "table", "orderbook_latest")
+			} else {
+				logger("main").Warn("Ignoring -db-latest, the driver has no latest table", "event", "config_ignored", "driver", driverName(options.Store.Driver))
+			}
+		}
+		if options.Store.Trades {
+			if writer, ok := dbClient.(collector.TradeWriter); ok {
+				tradeRecorder = collector.NewTradeRecorder(writer, 0, 0)
+				work.start(tradeRecorder.Start)
+				logger("main").Info("Storing the public trades of every exchange")
+			} else {
+				logger("main").Warn("Ignoring -db-trades, the driver has no trades table", "event", "config_ignored", "driver", driverName(options.Store.Driver))
+			}
+		}
 
-		// Test API connection
-		if err := dbClient.TestConnection(); err != nil {
-			log.Fatalf("Supabase API connection test failed: %v", err)
+		if options.Store.Retention != nil {
+			job, err := retention.NewJob(dbClient, *options.Store.Retention, options.Store.RetentionInterval)
+			if err != nil {
+				return fmt.Errorf("retention setup failed: %w", err)
+			}
+			logger("main").Info("Applying retention", "retention", options.Store.Retention, "interval", options.Store.RetentionInterval)
+			work.start(job.Start)
 		}
-		log.Println("Supabase API connection established successfully")
 
 		// Create data collector
-		dataCollector = collector.NewCollector(&dbClient, currentSymbol, dbInterval)
+		dataCollector = collector.NewCollector(dbClient, currentSymbol, options.DBInterval)
+		dataCollector.SetImpactSizes(options.ImpactSizes)
+		dataCollector.SetSlippageNotionals(options.SlippageNotionals)
+		dataCollector.SetCurveOffsets(options.CurveOffsets)
+		dataCollector.SetDepthLevels(options.Store.DepthLevels)
+		dataCollector.SetDepthBucket(options.Store.DepthBucket)
+		dataCollector.SetDedup(options.Store.Dedup)
+		dataCollector.SetLatest(options.Store.Latest)
+		dataCollector.SetConsolidated(options.Store.Consolidated)
+		dataCollector.SetTouch(options.Store.Touch)
 
 		// Start data collection in background
-		go dataCollector.Start(ctx)
+		work.start(dataCollector.Start)
+	}
+
+	out := outputs{events: bus.New(), console: options.Console, trades: tradeRecorder, control: newControl(currentSymbol, getExchangeNames(options.App), options.LogInterval, options.App.LiquidityBands)}
+	if dataCollector != nil {
+		out.collectors = append(out.collectors, dataCollector)
+		out.control.setStorage(dataCollector)
+		out.control.addCollector("db", dataCollector)
+	}
+	if client, ok := dbClient.(*relay.Client); ok {
+		out.relay = client
+		logger("main").Info("Streaming depth updates with the snapshots", "target", options.Store.DSN)
+	}
+
+	// Publish depth updates as they arrive and stats snapshots on their own interval
+	if options.Publishing.Driver != "" {
+		pub, err := newPublisher(options.Publishing)
+		if err != nil {
+			return err
+		}
+		out.publisher = pub
+		statsCollector := collector.NewCollector(out.publisher, currentSymbol, options.Publishing.Interval)
+		statsCollector.SetImpactSizes(options.ImpactSizes)
+		statsCollector.SetSlippageNotionals(options.SlippageNotionals)
+		statsCollector.SetCurveOffsets(options.CurveOffsets)
+		statsCollector.SetQuiet(true)
+		out.collectors = append(out.collectors, statsCollector)
+		out.control.addCollector("publish", statsCollector)
+		work.start(statsCollector.Start)
+	}
+
+	// Keep the latest book of every exchange in Redis
+	var cacheClient collector.DatabaseClient
+	if options.Cache.URL != "" {
+		client, err := newCache(options.Cache)
+		if err != nil {
+			return err
+		}
+		cacheClient = client
+		cacheCollector := collector.NewCollector(cacheClient, currentSymbol, options.Cache.Interval)
+		cacheCollector.SetImpactSizes(options.ImpactSizes)
+		cacheCollector.SetSlippageNotionals(options.SlippageNotionals)
+		cacheCollector.SetCurveOffsets(options.CurveOffsets)
+		cacheCollector.SetDepthLevels(options.Cache.DepthLevels)
+		cacheCollector.SetQuiet(true)
+		out.collectors = append(out.collectors, cacheCollector)
+		out.control.addCollector("redis", cacheCollector)
+		work.start(cacheCollector.Start)
+	}
+
+	// Archive every raw message and the normalized books for replay
+	if options.Recording.Dir != "" {
+		rec, err := recorder.New(options.Recording)
+		if err != nil {
+			return fmt.Errorf("recorder setup failed: %w", err)
+		}
+		exchange.SetMessageTap(rec)
+		out.recorder = rec
+		logger("main").Info("Recording feeds", "dir", options.Recording.Dir, "compression", options.Recording.Compression)
+	}
+
+	if err := startAnalytics(work, options.Analysis, currentSymbol, &out); err != nil {
+		return err
+	}
+
+	// Serve the live books to local clients
+	if options.Serving.Addr != "" || options.Serving.GRPCAddr != "" {
+		out.metrics = metrics.New(currentSymbol)
+		options.Serving.Metrics = out.metrics.Handler()
+		options.Serving.Admin = out.control
+		options.Serving.Flow = out.flow
+		options.Serving.Spoofing = out.spoofing
+		options.Serving.MarketShare = out.share
+		options.Serving.Volatility = out.volatility
+		options.Serving.Fees = out.fees
+		options.Serving.Index = out.index
+		if options.DepthMap != nil && options.Serving.Addr != "" {
+			out.heatmap = heatmap.New(*options.DepthMap, currentSymbol)
+			options.Serving.Heatmap = out.heatmap
+			work.start(out.heatmap.Start)
+		}
+		out.server = server.New(options.Serving, currentSymbol)
+		if err := out.server.Start(); err != nil {
+			return fmt.Errorf("API server setup failed: %w", err)
+		}
+		if options.Serving.GRPCAddr != "" {
+			logger("main").Info("Serving the gRPC API", "addr", out.server.GRPCAddr())
+		}
+	}
+	if options.Serving.Addr != "" {
+		logger("main").Info("Serving the HTTP API", "addr", out.server.Addr(), "backpressure", options.Serving.Backpressure)
+		if options.Serving.AdminToken != "" {
+			logger("main").Info("Serving the admin API", "path", "/api/v1/admin")
+		}
+		if out.heatmap != nil {
+			logger("main").Info("Serving depth heatmaps", "path", "/api/v1/heatmap", "window", options.DepthMap.Window)
+		}
+		if out.flow != nil {
+			logger("main").Info("Serving taker flow", "path", "/api/v1/flow")
+		}
+		if out.spoofing != nil {
+			logger("main").Info("Serving spoofing and layering", "path", "/api/v1/spoofing")
+		}
+		if out.share != nil {
+			logger("main").Info("Serving market share", "path", "/api/v1/market-share")
+		}
+		if out.index != nil {
+			logger("main").Info("Serving the index price", "path", "/api/v1/index")
+		}
+
+		// Stats snapshots reach WebSocket clients through a collector, like published ones
+		hubCollector := collector.NewCollector(out.server.Hub(), currentSymbol, options.Serving.StatsInterval)
+		hubCollector.SetImpactSizes(options.ImpactSizes)
+		hubCollector.SetSlippageNotionals(options.SlippageNotionals)
+		hubCollector.SetCurveOffsets(options.CurveOffsets)
+		hubCollector.SetQuiet(true)
+		out.collectors = append(out.collectors, hubCollector)
+		out.control.addCollector("ws", hubCollector)
+		work.start(hubCollector.Start)
+
+		for name, c := range out.control.namedCollectors() {
+			out.server.AddCollector(name, c)
+		}
+	}
+
+	// Stored, published and streamed snapshots carry the taker flow, funding, basis, resiliency, market share, volatility and fee-adjusted spread of their exchange,
+	// and the consolidated row the index price
+	for _, c := range out.collectors {
+		if out.flow != nil {
+			c.SetFlow(out.flow)
+		}
+		if out.funding != nil {
+			c.SetFunding(out.funding)
+		}
+		if out.basis != nil {
+			c.SetBasis(out.basis)
+		}
+		if out.resiliency != nil {
+			c.SetResiliency(out.resiliency)
+		}
+		if out.share != nil {
+			c.SetMarketShare(out.share)
+		}
+		if out.volatility != nil {
+			c.SetVolatility(out.volatility)
+		}
+		if out.fees != nil {
+			c.SetFees(out.fees)
+		}
+		if out.index != nil {
+			c.SetIndex(out.index)
+		}
+	}
+
+	// Take over the terminal last, so setup failures still reach it
+	var quit <-chan struct{}
+	var symbolChanges <-chan string
+	if options.Terminal != nil {
+		out.tui = tui.New(*options.Terminal, currentSymbol)
+		quit, symbolChanges = out.tui.Done(), out.tui.Symbols()
+		logging.SetOutput(out.tui.LogWriter())
+		go func() {
+			if err := out.tui.Run(); err != nil {
+				logging.SetOutput(os.Stderr)
+				logger("main").Error("Terminal UI failed", "error", err)
+			}
+		}()
+	}
+
+	// Everything enabled subscribes before the first connection publishes
+	out.subscribe()
+
+	// Changes to the config file apply like admin API calls
+	if options.App.ConfigFile != "" {
+		if err := config.WatchFile(ctx, options.App.ConfigFile, out.control.reload); err != nil {
+			return fmt.Errorf("config watch setup failed: %w", err)
+		}
+		logger("main").Info("Applying changes to the config file while running", "path", options.App.ConfigFile)
+	}
+	go out.control.reloadOnHangup(options.App.ConfigFile)
+
+	// Without the terminal UI, stdin takes commands such as "symbol ETHUSDT"
+	if out.tui == nil {
+		go out.control.readCommands(os.Stdin)
 	}
 
 	// Main loop to handle symbol changes
 	for {
-		log.Printf("Starting exchanges for symbol: %s", currentSymbol)
+		logger("main").Info("Starting exchanges", logging.KeySymbol, currentSymbol)
 
-		// Start all exchanges with current symbol
-		done := make(chan struct{})
+		// Start all exchanges with current symbol; they stop with symbolCtx
+		symbolCtx, stopSymbol := context.WithCancel(ctx)
 		exchangesDone := make(chan struct{})
 
 		go func() {
-			startExchangesForSymbol(ctx, currentSymbol, orderbooksMap, &obMutex, logInterval, dataCollector, done, interrupt)
+			startExchangesForSymbol(symbolCtx, currentSymbol, orderbooksMap, &obMutex, options.App, out)
 			close(exchangesDone)
 		}()
 
-		// Wait for interrupt
-		<-interrupt
-		log.Println("Interrupt received, shutting down...")
-		close(done)
-		<-exchangesDone
-		log.Println("All exchanges closed. Goodbye!")
-		return
+		// Wait for interrupt, or a new symbol from the terminal UI or the admin API
+		var symbol string
+		select {
+		case <-ctx.Done():
+			logger("main").Info("Interrupt received, shutting down", "event", "shutdown")
+		case <-quit:
+			logger("main").Info("Terminal UI closed, shutting down", "event", "shutdown")
+		case symbol = <-symbolChanges:
+		case symbol = <-out.control.symbols:
+		}
+		stopSymbol()
+		if symbol != "" {
+			logger("main").Info("Switching symbol", "event", "symbol_switched", "from", currentSymbol, "to", symbol)
+			<-exchangesDone
+			currentSymbol = symbol
+			out.setSymbol(currentSymbol)
+			continue
+		}
+		cancel()
+		if out.tui != nil {
+			out.tui.Close()
+			logging.SetOutput(os.Stderr)
+		}
+
+		// Close the connections and let the background loops finish, then flush and close what
+		// they wrote to; a shutdown stuck on a slow backend gives up after the timeout
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			<-exchangesDone
+			if err := group.Wait(); err != nil {
+				logger("main").Error("Background task failed", "error", err)
+			}
+			out.close(dbClient, cacheClient, dataCollector, options.Store)
+		}()
+		select {
+		case <-closed:
+			logger("main").Info("All exchanges closed. Goodbye!")
+			return nil
+		case <-time.After(options.App.ShutdownTimeout):
+			return fmt.Errorf("shutdown did not finish within %v", options.App.ShutdownTimeout)
+		}
+	}
+}
+
+// close flushes and closes every output once nothing writes to them anymore
+func (out outputs) close(dbClient, cacheClient collector.DatabaseClient, dataCollector *collector.Collector, store config.StorageConfig) {
+	if out.trades != nil {
+		out.trades.Flush()
+		logger("main").Info("Trades", "stored", out.trades.Written(), "dropped", out.trades.Dropped())
+	}
+	if dbClient != nil {
+		if err := dbClient.Close(); err != nil {
+			logger("main").Error("Failed to close database client", "event", "close_failed", "error", err)
+		}
+		if client, ok := dbClient.(*database.SupabaseAPIClient); ok && store.Async != nil {
+			stats := client.AsyncStats()
+			logger("main").Info("Supabase background inserts", "written", stats.Written, "dropped", stats.Dropped, "failed", stats.Failed)
+		}
+		if store.Dedup != nil {
+			logger("main").Info("Skipped unchanged snapshots", "snapshots", dataCollector.Skipped())
+		}
+		if out.relay != nil {
+			stats := out.relay.Stats()
+			logger("main").Info("gRPC relay", "sent", stats.Sent, "dropped", stats.Dropped, "failed", stats.Failed)
+		}
+	}
+	if out.publisher != nil {
+		if err := out.publisher.Close(); err != nil {
+			logger("main").Error("Failed to close publisher", "event", "close_failed", "error", err)
+		}
+	}
+	if cacheClient != nil {
+		if err := cacheClient.Close(); err != nil {
+			logger("main").Error("Failed to close Redis client", "event", "close_failed", "error", err)
+		}
+	}
+	if out.server != nil {
+		if err := out.server.Close(); err != nil {
+			logger("main").Error("Failed to close HTTP API", "event", "close_failed", "error", err)
+		}
+		if dropped := out.server.Hub().Dropped(); dropped > 0 {
+			logger("main").Warn("WebSocket clients fell behind", "dropped", dropped)
+		}
+	}
+	if out.capture != nil {
+		out.capture.Close()
+		if dropped, failed := out.capture.Dropped(), out.capture.Failed(); dropped > 0 || failed > 0 {
+			logger("main").Warn("Alert captures", "written", out.capture.Written(), "skipped", dropped, "failed", failed)
+		}
+	}
+	if out.recorder != nil {
+		exchange.SetMessageTap(nil)
+		out.recorder.Close()
+		if dropped := out.recorder.Dropped(); dropped > 0 {
+			logger("main").Warn("Recorder dropped records", "dropped", dropped)
+		}
 	}
 }
 
-func startExchangesForSymbol(ctx context.Context, symbol string, orderbooksMap map[string]*orderbook.OrderBook, obMutex *sync.Mutex, logInterval time.Duration, dataCollector *collector.Collector, done chan struct{}, interrupt chan os.Signal) {
-	cfg := config.NewMultiExchange(buildExchangeConfigs(symbol))
+func startExchangesForSymbol(ctx context.Context, symbol string, orderbooksMap map[string]*orderbook.OrderBook, obMutex *sync.Mutex, app config.AppConfig, out outputs) {
+	cfg := config.Default()
+	cfg.App = app
 
 	var wg sync.WaitGroup
-	orderbooks := make([]*orderbookWithName, 0, len(cfg.Exchanges))
+	var orderbooks []*orderbookWithName
 
-	// Create an orderbook for each exchange
-	for _, exConfig := range cfg.Exchanges {
+	// Create an orderbook for each selected exchange, and for those the admin API adds while
+	// the symbol is monitored; stop is closed when the admin API removes the exchange
+	start := func(name exchange.ExchangeName, stop <-chan struct{}) {
+		exCfg := buildExchangeConfig(name, symbol, app)
 		wg.Add(1)
-		go func(exCfg config.ExchangeConfig) {
+		go func() {
 			defer wg.Done()
+			defer out.control.stopped(name, stop)
 
-			log.Printf("[%s] Starting connection...", exCfg.Name)
+			logger := logging.For(string(exCfg.Name), symbol)
+			logger.Info("Starting connection", "event", "connecting")
+			report := func(state bus.ConnectionState, err error) {
+				out.events.Connections.Publish(bus.ConnectionEvent{Exchange: string(exCfg.Name), Symbol: symbol, State: state, Err: err})
+			}
 
 			// Create exchange-specific orderbook
 			ob := orderbook.New()
+			ob.SetLogger(logger)
+			ob.SetGapPolicy(cfg.GapPolicyFor(exCfg))
+			ob.SetCrossPolicy(cfg.App.CrossPolicy)
+			ob.SetLiquidityBands(out.control.liquidityBands())
+			ob.SetImbalanceDepths(cfg.App.ImbalanceDepths)
+			ob.SetMaxDepth(cfg.App.MaxDepth)
+			ob.SetMaxBand(cfg.App.MaxBand)
+			ob.SetStaleAfter(cfg.App.StaleResync)
+			ob.EnableRolling(cfg.App.RollingWindow)
+			ob.EnableOFI(cfg.App.OFIWindows)
+			if cfg.App.FixedPoint {
+				scale := types.DefaultFixedScale
+				if listing, err := symbols.Resolve(exCfg.Name, symbol); err == nil {
+					scale = listing.FixedScale()
+				}
+				ob.SetFixedPoint(scale)
+			}
 
 			// Create exchange instance
 			ex, err := factory.NewExchange(factory.ExchangeConfig{
-				Name:   exCfg.Name,
-				Symbol: exCfg.Symbol,
+				Name:           exCfg.Name,
+				Symbol:         exCfg.Symbol,
+				InstrumentType: exCfg.InstrumentType,
+				Contract:       exCfg.Contract,
+				Path:           exCfg.Path,
+				Venues:         exCfg.Venues,
+				TickSize:       exCfg.TickSize,
+				L3:             exCfg.L3,
+				Depth:          exCfg.Depth,
+				UpdateSpeed:    exCfg.UpdateSpeed,
+				APIKey:         exCfg.APIKey,
+				APISecret:      exCfg.APISecret,
+				Passphrase:     exCfg.Passphrase,
 			})
 			if err != nil {
-				log.Printf("[%s] Failed to create exchange: %v", exCfg.Name, err)
+				logger.Error("Failed to create exchange", "event", "setup_failed", "error", err)
+				report(bus.Failed, err)
 				return
 			}
 
 			// Connect
 			if err := ex.Connect(ctx); err != nil {
-				log.Printf("[%s] Failed to connect: %v", exCfg.Name, err)
+				logger.Error("Failed to connect", "event", "connect_failed", "error", err)
+				report(bus.Failed, err)
 				return
 			}
 			defer ex.Close()
 
+			caps := ex.Capabilities()
+			logger.Info("Connected", "event", "connected", "native_symbol", caps.NativeSymbol,
+				"depth", depthText(caps.MaxDepth), "updates", intervalText(caps.UpdateInterval), "checksum", caps.Checksum)
+			if reporter, ok := ex.(exchange.ConnectionReporter); ok && reporter.ConnectionCount() > 1 {
+				logger.Info("Subscriptions sharded", "event", "sharded", "connections", reporter.ConnectionCount())
+			}
+			report(bus.Connected, nil)
+
+			// Publish the trades of venues that stream them, and the funding of perpetual venues
+			out.events.DrainTrades(string(exCfg.Name), symbol, ex)
+
+			// Order-level feeds publish individual orders instead of levels
+			ob.SetL3(caps.L3)
+
+			// Verify venue checksums when the feed publishes them
+			if checksum := orderbook.ChecksumFor(exCfg.Name); checksum != nil && caps.Checksum {
+				ob.SetChecksum(checksum)
+			}
+
 			// Get snapshot
 			snapshot, err := ex.GetSnapshot(ctx)
 			if err != nil {
-				log.Printf("[%s] Failed to get snapshot: %v", exCfg.Name, err)
+				logger.Error("Failed to get snapshot", "event", "snapshot_failed", "error", err)
+				report(bus.Failed, err)
 				return
 			}
 
+			if out.recorder != nil {
+				out.recorder.RecordSnapshot(exCfg.Name, snapshot)
+			}
+
 			if err := ob.LoadSnapshot(snapshot); err != nil {
-				log.Printf("[%s] Failed to load snapshot: %v", exCfg.Name, err)
+				logger.Error("Failed to load snapshot", "event", "snapshot_failed", "last_update_id", snapshot.LastUpdateID, "error", err)
+				report(bus.Failed, err)
 				return
 			}
 
-			// Process updates in background
-			updatesDone := make(chan struct{})
-			go func() {
-				defer close(updatesDone)
-				for update := range ex.Updates() {
-					ob.HandleDepthUpdate(update)
+			// The book applies its updates first, then everything subscribed to the bus sees them
+			updatesDone := out.events.DrainDepth(string(exCfg.Name), symbol, ex, func(event bus.DepthEvent) {
+				endApply := event.Trace.Stage(telemetry.StageApply)
+				ob.HandleDepthUpdate(event.Update)
+				endApply(nil)
+				event.Trace.Applied(event.Update.EventTime)
+			})
+
+			// Reinitialization check; streamed snapshots need a resubscribe to refresh
+			getSnapshot := func() (*exchange.Snapshot, error) {
+				if resyncer, ok := ex.(exchange.Resyncer); ok {
+					if err := resyncer.Resync(); err != nil {
+						return nil, fmt.Errorf("resync failed: %w", err)
+					}
 				}
-			}()
+				snapshot, err := ex.GetSnapshot(ctx)
+				if err == nil && out.recorder != nil {
+					out.recorder.RecordSnapshot(exCfg.Name, snapshot)
+				}
+				return snapshot, err
+			}
 
-			// Reinitialization check
 			go func() {
 				ticker := time.NewTicker(cfg.App.ReinitCheckInterval)
 				defer ticker.Stop()
@@ -185,166 +1280,348 @@ func startExchangesForSymbol(ctx context.Context, symbol string, orderbooksMap m
 				for {
 					select {
 					case <-ticker.C:
-						ob.CheckAndReinitialize(func() (*exchange.Snapshot, error) {
-							return ex.GetSnapshot(ctx)
-						})
+						ob.CheckAndReinitialize(getSnapshot)
+					case <-ob.ResyncRequests():
+						ob.CheckAndReinitialize(getSnapshot)
 					case <-updatesDone:
 						return
-					case <-done:
-						return
-					case <-interrupt:
+					case <-ctx.Done():
 						return
 					}
 				}
 			}()
 
 			ob.ProcessBufferedEvents()
-			log.Printf("[%s] Orderbook initialized", exCfg.Name)
+			logger.Info("Orderbook ready", "event", "ready", "last_update_id", ob.Snapshot().LastUpdateID)
 
 			// Add orderbook to shared collections
 			obMutex.Lock()
 			orderbooks = append(orderbooks, &orderbookWithName{
 				name: string(exCfg.Name),
 				ob:   ob,
+				caps: caps,
 			})
 			orderbooksMap[string(exCfg.Name)] = ob
 			obMutex.Unlock()
 
-			// Register orderbook with data collector if enabled
-			if dataCollector != nil {
-				dataCollector.RegisterOrderbook(string(exCfg.Name), ob)
-			}
+			// Announce the book to everything that reads it
+			out.events.Books.Publish(bus.BookEvent{Exchange: string(exCfg.Name), Symbol: symbol, Book: ob, Caps: caps, Feed: ex})
 
 			// Wait for shutdown
 			select {
 			case <-updatesDone:
-				log.Printf("[%s] Connection closed", exCfg.Name)
-			case <-done:
-				log.Printf("[%s] Shutting down...", exCfg.Name)
-			case <-interrupt:
-				log.Printf("[%s] Shutting down...", exCfg.Name)
+				logger.Warn("Connection closed", "event", "disconnected")
+				report(bus.Disconnected, nil)
+			case <-stop:
+				logger.Info("Removed", "event", "removed")
+				report(bus.Stopped, nil)
+			case <-ctx.Done():
+				logger.Info("Shutting down", "event", "shutdown")
+				report(bus.Stopped, nil)
 			}
 
-			// Unregister from data collector if enabled
-			if dataCollector != nil {
-				dataCollector.UnregisterOrderbook(string(exCfg.Name))
-			}
+			// Unregister from everything that read it
+			out.events.Books.Publish(bus.BookEvent{Exchange: string(exCfg.Name), Symbol: symbol, Book: ob, Caps: caps, Feed: ex, Removed: true})
 
-			// Remove from map on shutdown
+			// Remove from the map and the logged stats on shutdown
 			obMutex.Lock()
 			delete(orderbooksMap, string(exCfg.Name))
+			orderbooks = slices.DeleteFunc(orderbooks, func(obn *orderbookWithName) bool { return obn.ob == ob })
 			obMutex.Unlock()
-		}(exConfig)
+		}()
 	}
+	out.control.attach(start)
 
-	// Centralized logging ticker
+	// Centralized logging ticker; the terminal UI shows the books itself
 	go func() {
-		ticker := time.NewTicker(logInterval)
+		if out.tui != nil {
+			return
+		}
+		ticker := time.NewTicker(out.control.logEvery())
 		defer ticker.Stop()
 
 		for {
 			select {
+			case <-out.control.logReset:
+				ticker.Reset(out.control.logEvery())
 			case <-ticker.C:
 				obMutex.Lock()
-				printCombinedStats(orderbooks)
+				books := make([]output.Book, len(orderbooks))
+				var shares map[string]analytics.MarketShareStats
+				if out.share != nil {
+					shares = out.share.All()
+				}
+				for i, obn := range orderbooks {
+					books[i] = output.Book{Exchange: obn.name, Symbol: symbol, Book: obn.ob, Caps: obn.caps}
+					if out.flow != nil {
+						if flow, ok := out.flow.Stats(obn.name); ok {
+							books[i].Flow = &flow
+						}
+					}
+					if out.funding != nil {
+						if funding, ok := out.funding.Stats(obn.name); ok {
+							books[i].Funding = &funding
+						}
+					}
+					if out.basis != nil {
+						if basis, ok := out.basis.Stats(obn.name); ok {
+							books[i].Basis = &basis
+						}
+					}
+					if out.resiliency != nil {
+						if resiliency, ok := out.resiliency.Stats(obn.name); ok {
+							books[i].Resiliency = &resiliency
+						}
+					}
+					if out.spoofing != nil {
+						if spoofing, ok := out.spoofing.Stats(obn.name); ok {
+							books[i].Spoofing = &spoofing
+						}
+					}
+					if share, ok := shares[obn.name]; ok {
+						books[i].Share = &share
+					}
+					if out.volatility != nil {
+						if volatility, ok := out.volatility.Stats(obn.name); ok {
+							books[i].Volatility = &volatility
+						}
+					}
+					if out.fees != nil {
+						schedule := out.fees.For(obn.name)
+						books[i].Fees = &schedule
+					}
+				}
 				obMutex.Unlock()
-			case <-done:
-				return
-			case <-interrupt:
+				if err := out.console.Format(books); err != nil {
+					logger("main").Error("Failed to write stats", "error", err)
+				}
+			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
+	// Exchanges added from now on start with the next symbol
+	<-ctx.Done()
+	out.control.detach()
 	wg.Wait()
 }
 
-func buildExchangeConfigs(symbol string) []config.ExchangeConfig {
-	names := getExchangeNames()
-	configs := make([]config.ExchangeConfig, len(names))
-	for i, name := range names {
-		configs[i] = config.ExchangeConfig{
-			Name:   name,
-			Symbol: symbol,
+// buildExchangeConfig configures an exchange from the <NAME>_* environment variables, with
+// the recording of app for the file exchange
+func buildExchangeConfig(name exchange.ExchangeName, symbol string, app config.AppConfig) config.ExchangeConfig {
+	prefix := strings.ToUpper(string(name))
+
+	// Unset leaves the policy empty so the -gap-policy default applies
+	var gapPolicy types.GapPolicy
+	if value := os.Getenv(prefix + "_GAP_POLICY"); value != "" {
+		policy, err := types.ParseGapPolicy(value)
+		if err != nil {
+			logging.For(string(name), symbol).Warn("Ignoring "+prefix+"_GAP_POLICY", "event", "config_ignored", "error", err)
+		}
+		gapPolicy = policy
+	}
+
+	l3, _ := strconv.ParseBool(os.Getenv(prefix + "_L3"))
+
+	// The adapter logs and replaces a depth or speed its venue does not offer
+	var depth int
+	if value := os.Getenv(prefix + "_DEPTH"); value != "" {
+		if levels, err := strconv.Atoi(value); err == nil && levels > 0 {
+			depth = levels
+		} else {
+			logging.For(string(name), symbol).Warn("Ignoring "+prefix+"_DEPTH, want a positive level count", "event", "config_ignored", "value", value)
 		}
 	}
-	return configs
+	var speed time.Duration
+	if value := os.Getenv(prefix + "_UPDATE_SPEED"); value != "" {
+		if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+			speed = interval
+		} else {
+			logging.For(string(name), symbol).Warn("Ignoring "+prefix+"_UPDATE_SPEED, want a duration such as 100ms", "event", "config_ignored", "value", value)
+		}
+	}
+
+	exCfg := config.ExchangeConfig{
+		Name:        name,
+		Symbol:      symbol,
+		GapPolicy:   gapPolicy,
+		L3:          l3,
+		Depth:       depth,
+		UpdateSpeed: speed,
+		APIKey:      os.Getenv(prefix + "_API_KEY"),
+		APISecret:   os.Getenv(prefix + "_API_SECRET"),
+		Passphrase:  os.Getenv(prefix + "_API_PASSPHRASE"),
+	}
+	if name == exchange.File {
+		exCfg.Path = app.ReplayFile
+	}
+	return exCfg
 }
 
-func printCombinedStats(orderbooks []*orderbookWithName) {
-	if len(orderbooks) == 0 {
-		return
+// parseDepths parses a comma-separated list of positive level counts; empty selects the defaults
+func parseDepths(list string) ([]int, error) {
+	var depths []int
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		depth, err := strconv.Atoi(item)
+		if err != nil || depth <= 0 {
+			return nil, fmt.Errorf("invalid depth %q", item)
+		}
+		depths = append(depths, depth)
 	}
+	if len(depths) == 0 {
+		return types.DefaultImbalanceDepths, nil
+	}
+	return depths, nil
+}
 
-	fmt.Println()
+// parseSizes parses a comma-separated list of positive order sizes
+func parseSizes(list string) ([]decimal.Decimal, error) {
+	var sizes []decimal.Decimal
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		size, err := decimal.NewFromString(item)
+		if err != nil || !size.IsPositive() {
+			return nil, fmt.Errorf("invalid size %q", item)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
 
-	for i, obn := range orderbooks {
-		if !obn.ob.IsInitialized() {
+// parseWindows parses a comma-separated list of positive durations, e.g. 1m,5m,1h
+func parseWindows(list string) ([]time.Duration, error) {
+	var windows []time.Duration
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
 			continue
 		}
+		window, err := time.ParseDuration(item)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid window %q", item)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
 
-		stats := obn.ob.GetStats()
-		midPrice := stats.BestBid.Add(stats.BestAsk).Div(decimal.NewFromInt(2))
+// bandsText lists liquidity bands for logging
+func bandsText(bands []decimal.Decimal) string {
+	labels := make([]string, len(bands))
+	for i, band := range bands {
+		labels[i] = types.BandLabel(band)
+	}
+	return strings.Join(labels, ", ")
+}
 
-		// print exchange name
-		fmt.Printf("%s%s%s", colorBold, obn.name, colorReset)
-		// Print exchange header
-		fmt.Printf("  Mid: %s%10s%s │ Spread: %s%8s%s | BB: %s%10s%s │ BA: %s%10s%s\n",
-			colorYellow, midPrice.StringFixed(2), colorReset,
-			colorMagenta, stats.Spread.StringFixed(4), colorReset,
-			colorGreen, stats.BestBid.StringFixed(2), colorReset,
-			colorRed, stats.BestAsk.StringFixed(2), colorReset)
+// depthText describes a feed depth
+func depthText(maxDepth int) string {
+	if maxDepth == 0 {
+		return "full book"
+	}
+	return fmt.Sprintf("%d levels", maxDepth)
+}
 
-		// Print depth metrics
-		fmt.Printf("  DEPTH 0.5%% Bids: %s%9s%s │ Asks: %s%9s%s │ Δ: %s%10s%s\n",
-			colorGreen, stats.BidLiquidity05Pct.StringFixed(2), colorReset,
-			colorRed, stats.AskLiquidity05Pct.StringFixed(2), colorReset,
-			getDeltaColor(stats.DeltaLiquidity05Pct), stats.DeltaLiquidity05Pct.StringFixed(2), colorReset)
+// intervalText describes a feed update interval
+func intervalText(interval time.Duration) string {
+	if interval == 0 {
+		return "real-time"
+	}
+	return interval.String()
+}
 
-		fmt.Printf("  DEPTH 2%%:  Bids: %s%9s%s │ Asks: %s%9s%s │ Δ: %s%10s%s\n",
-			colorGreen, stats.BidLiquidity2Pct.StringFixed(2), colorReset,
-			colorRed, stats.AskLiquidity2Pct.StringFixed(2), colorReset,
-			getDeltaColor(stats.DeltaLiquidity2Pct), stats.DeltaLiquidity2Pct.StringFixed(2), colorReset)
+// driverName names a storage driver, supabase when empty
+func driverName(driver string) string {
+	if driver == "" {
+		return "supabase"
+	}
+	return driver
+}
 
-		fmt.Printf("  DEPTH 10%%  Bids: %s%9s%s │ Asks: %s%9s%s │ Δ: %s%10s%s\n",
-			colorGreen, stats.BidLiquidity10Pct.StringFixed(2), colorReset,
-			colorRed, stats.AskLiquidity10Pct.StringFixed(2), colorReset,
-			getDeltaColor(stats.DeltaLiquidity10Pct), stats.DeltaLiquidity10Pct.StringFixed(2), colorReset)
+// publisherOptions selects the broker depth updates and stats snapshots are published to
+type publisherOptions struct {
+	Driver   string           // kafka or nats, empty disables publishing
+	Brokers  []string         // Kafka bootstrap brokers
+	URL      string           // NATS server URL
+	Stream   string           // JetStream stream, empty for core NATS
+	Topics   publisher.Topics // Destinations of depth updates and stats
+	Interval time.Duration    // Interval between stats snapshots
+}
 
-		fmt.Printf("  TOTAL QTY: Bids: %s%9s%s │ Asks: %s%9s%s\n",
-			colorGreen, stats.TotalBidsQty.StringFixed(2), colorReset,
-			colorRed, stats.TotalAsksQty.StringFixed(2), colorReset)
+// newPublisher connects to the selected broker and verifies the connection
+func newPublisher(publishing publisherOptions) (*publisher.Publisher, error) {
+	switch publishing.Driver {
+	case "kafka":
+		sink, err := publisher.NewKafkaSink(publisher.KafkaConfig{Brokers: publishing.Brokers})
+		if err != nil {
+			return nil, fmt.Errorf("Kafka setup failed (set KAFKA_BROKERS or -kafka-brokers): %w", err)
+		}
+		pub := publisher.New(sink, publishing.Topics)
+		if err := pub.TestConnection(); err != nil {
+			pub.Close()
+			return nil, fmt.Errorf("Kafka connection test failed: %w", err)
+		}
+		logger("main").Info("Publishing to Kafka", "brokers", strings.Join(publishing.Brokers, ","), "interval", publishing.Interval)
+		return pub, nil
 
-		// Print separator between exchanges (but not after the last one)
-		if i < len(orderbooks)-1 {
-			fmt.Println()
+	case "nats":
+		topics := publishing.Topics.WithDefaults()
+		sink, err := publisher.NewNATSSink(publisher.NATSConfig{
+			URL:      publishing.URL,
+			Stream:   publishing.Stream,
+			Subjects: []string{topics.Depth + ".>", topics.Stats + ".>"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("NATS setup failed: %w", err)
 		}
-	}
-}
+		pub := publisher.New(sink, topics)
+		if err := pub.TestConnection(); err != nil {
+			pub.Close()
+			return nil, fmt.Errorf("NATS connection test failed: %w", err)
+		}
+		if publishing.Stream != "" {
+			logger("main").Info("Publishing to NATS JetStream", "stream", publishing.Stream, "interval", publishing.Interval)
+		} else {
+			logger("main").Info("Publishing to NATS", "interval", publishing.Interval)
+		}
+		return pub, nil
 
-func getDeltaColor(delta decimal.Decimal) string {
-	if delta.GreaterThan(decimal.Zero) {
-		return colorGreen
-	} else if delta.LessThan(decimal.Zero) {
-		return colorRed
+	default:
+		return nil, fmt.Errorf("unknown -publish %q: expected kafka or nats", publishing.Driver)
 	}
-	return colorYellow
 }
 
-// getSupabaseConfig gets Supabase configuration from environment variables
-func getSupabaseConfig() (string, string) {
-	// Get Supabase URL and API key from environment
-	baseURL := os.Getenv("SUPABASE_URL")
-	apiKey := os.Getenv("SUPABASE_ANON_KEY")
+// cacheOptions configures the Redis latest-book cache
+type cacheOptions struct {
+	URL         string        // Redis URL, empty disables the cache
+	Interval    time.Duration // Interval between cache updates
+	DepthLevels int           // Levels per side cached with each book
+	Publish     bool          // Also publish cached values on channels
+}
 
-	// Set defaults if not provided
-	if baseURL == "" {
-		baseURL = "https://qlcmrsbvdmyflllavyzc.supabase.co"
+// newCache connects to Redis and verifies the connection
+func newCache(cache cacheOptions) (collector.DatabaseClient, error) {
+	// Keys outlive a few missed updates but expire once a book stops updating
+	ttl := time.Minute
+	if 3*cache.Interval > ttl {
+		ttl = 3 * cache.Interval
 	}
-	if apiKey == "" {
-		log.Fatal("SUPABASE_ANON_KEY environment variable is required. Please set it with your Supabase anonymous key.")
+	client, err := database.NewRedisClient(database.RedisConfig{URL: cache.URL, TTL: ttl, Publish: cache.Publish})
+	if err != nil {
+		return nil, fmt.Errorf("Redis setup failed: %w", err)
 	}
-
-	log.Printf("Supabase API: %s", baseURL)
-	return baseURL, apiKey
+	if err := client.TestConnection(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("Redis connection test failed: %w", err)
+	}
+	logger("main").Info("Caching the latest books in Redis", "levels", cache.DepthLevels, "interval", cache.Interval)
+	return client, nil
 }
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/config"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/output"
+	"github.com/imajinl/crypto-orderbook/internal/storage"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// stuckClient is a storage client whose Close waits for release, like a backend that stops
+// answering during the final flush
+type stuckClient struct {
+	release chan struct{}
+}
+
+func (c *stuckClient) InsertOrderbookSnapshot(*database.OrderbookSnapshotAPI) error { return nil }
+func (c *stuckClient) InsertOrderbookSnapshotsBatch([]*database.OrderbookSnapshotAPI) error {
+	return nil
+}
+func (c *stuckClient) TestConnection() error { return nil }
+
+func (c *stuckClient) Close() error {
+	<-c.release
+	return nil
+}
+
+var (
+	registerStuck sync.Once
+	stuckRelease  chan struct{} // Release of the client the stuck driver creates next
+)
+
+// shutdownStuck starts the monitor with the stuck driver on a cancelled context, returning
+// the time shutdown took and its error
+func shutdownStuck(t *testing.T, timeout time.Duration) (time.Duration, error) {
+	t.Helper()
+	registerStuck.Do(func() {
+		storage.Register("test-stuck", func(config.StorageConfig) (collector.DatabaseClient, error) {
+			return &stuckClient{release: stuckRelease}, nil
+		})
+	})
+
+	app := config.Default().App
+	app.Exchanges = []exchange.ExchangeName{exchange.File}
+	app.ReplayFile = filepath.Join(t.TempDir(), "missing.jsonl")
+	app.ShutdownTimeout = timeout
+	options := monitorOptions{
+		Symbol:      "BTCUSDT",
+		LogInterval: time.Hour,
+		DBEnabled:   true,
+		DBInterval:  time.Hour,
+		Store:       config.StorageConfig{Driver: "test-stuck"},
+		Console:     output.New(output.Text, &strings.Builder{}, 0),
+		App:         app,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	err := runMultiExchange(ctx, options)
+	return time.Since(start), err
+}
+
+func TestRunMultiExchangeShutdownTimeout(t *testing.T) {
+	stuckRelease = make(chan struct{})
+	defer close(stuckRelease)
+
+	took, err := shutdownStuck(t, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "shutdown did not finish within 50ms") {
+		t.Fatalf("expected the shutdown to time out, got %v", err)
+	}
+	if took > 5*time.Second {
+		t.Errorf("expected the monitor to give up after the timeout, took %v", took)
+	}
+}
+
+func TestRunMultiExchangeShutdown(t *testing.T) {
+	stuckRelease = make(chan struct{})
+	close(stuckRelease)
+
+	if _, err := shutdownStuck(t, 5*time.Second); err != nil {
+		t.Fatalf("expected a clean shutdown, got %v", err)
+	}
+}
+
+// parseRunClean parses the flags of command without the environment supplying defaults
+func parseRunClean(t *testing.T, command string, args ...string) runSettings {
+	t.Helper()
+	for _, name := range []string{"EXCHANGES", "RECORD_DIR", "REPLAY_FILE", "CONFIG_FILE", "DB_DRIVER"} {
+		t.Setenv(name, "")
+	}
+	settings, err := parseRun(command, args)
+	if err != nil {
+		t.Fatalf("parseRun(%s, %v) error: %v", command, args, err)
+	}
+	return settings
+}
+
+func TestParseRunCommandDefaults(t *testing.T) {
+	run := parseRunClean(t, "run").Monitor
+	if !run.DBEnabled || run.Recording.Dir != "" || len(run.App.Exchanges) != 0 {
+		t.Errorf("run: expected storage on, no recording and the default exchanges, got %v %q %v", run.DBEnabled, run.Recording.Dir, run.App.Exchanges)
+	}
+
+	record := parseRunClean(t, "record").Monitor
+	if record.DBEnabled || record.Recording.Dir != "recordings" {
+		t.Errorf("record: expected storage off and recordings under recordings, got %v %q", record.DBEnabled, record.Recording.Dir)
+	}
+	if record = parseRunClean(t, "record", "-record-dir", "archive", "-db-enabled").Monitor; !record.DBEnabled || record.Recording.Dir != "archive" {
+		t.Errorf("record: expected the flags over the defaults, got %v %q", record.DBEnabled, record.Recording.Dir)
+	}
+
+	replay := parseRunClean(t, "replay", "book.ndjson.gz").Monitor
+	if replay.DBEnabled || !slices.Equal(replay.App.Exchanges, []exchange.ExchangeName{exchange.File}) {
+		t.Errorf("replay: expected storage off and only the file exchange, got %v %v", replay.DBEnabled, replay.App.Exchanges)
+	}
+}
+
+func TestParseRunReplayFile(t *testing.T) {
+	for _, test := range []struct {
+		command string
+		args    []string
+		want    string
+	}{
+		{"replay", []string{"book.ndjson.gz", "-symbol", "ETHUSDT"}, "book.ndjson.gz"},
+		{"replay", []string{"-replay-file", "flag.ndjson.gz"}, "flag.ndjson.gz"},
+		{"run", []string{"-exchanges", "file,binance", "-replay-file", "run.ndjson.gz"}, "run.ndjson.gz"},
+	} {
+		options := parseRunClean(t, test.command, test.args...).Monitor
+		if options.App.ReplayFile != test.want {
+			t.Errorf("%s %v: replay file %q, want %q", test.command, test.args, options.App.ReplayFile, test.want)
+		}
+		// Only the file exchange plays the recording back
+		if path := buildExchangeConfig(exchange.File, options.Symbol, options.App).Path; path != test.want {
+			t.Errorf("%s %v: file exchange path %q, want %q", test.command, test.args, path, test.want)
+		}
+		if path := buildExchangeConfig(exchange.Binance, options.Symbol, options.App).Path; path != "" {
+			t.Errorf("%s %v: unexpected path %q for binance", test.command, test.args, path)
+		}
+	}
+	if options := parseRunClean(t, "replay", "book.ndjson.gz", "-symbol", "ETHUSDT").Monitor; options.Symbol != "ETHUSDT" {
+		t.Errorf("expected the flags after FILE parsed, got symbol %s", options.Symbol)
+	}
+}
+
+func TestParseRunConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"symbol": "ETHUSDT", "exchanges": ["okx", "kraken"], "intervals": {"log": "3s", "db": "1m"}, "bands": "0.1,2"}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file overrides the flags of the settings it has and leaves the rest
+	options := parseRunClean(t, "run", "-config", path, "-symbol", "BTCUSDT", "-exchanges", "binance", "-log-interval", "1s", "-publish-interval", "7s").Monitor
+	if options.Symbol != "ETHUSDT" || !slices.Equal(options.App.Exchanges, []exchange.ExchangeName{exchange.OKX, exchange.Kraken}) {
+		t.Errorf("expected the symbol and exchanges of the file, got %s %v", options.Symbol, options.App.Exchanges)
+	}
+	if options.LogInterval != 3*time.Second || options.DBInterval != time.Minute || options.Publishing.Interval != 7*time.Second {
+		t.Errorf("unexpected intervals log %v, db %v, publish %v", options.LogInterval, options.DBInterval, options.Publishing.Interval)
+	}
+	want, _ := types.ParseLiquidityBands("0.1,2")
+	if !slices.EqualFunc(options.App.LiquidityBands, want, decimal.Decimal.Equal) {
+		t.Errorf("expected the bands of the file, got %v", options.App.LiquidityBands)
+	}
+	if options.App.ConfigFile != path {
+		t.Errorf("expected the file watched while running, got %q", options.App.ConfigFile)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"intervals": {"trades": "1s"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseRun("run", []string{"-config", path}); err == nil || !strings.Contains(err.Error(), "unknown interval trades") {
+		t.Errorf("expected an unknown interval rejected, got %v", err)
+	}
+}
+
+func TestParseRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-log-level", "loud"},
+		{"-bands", "wide"},
+		{"-max-depth", "-1"},
+		{"-exchanges", "nasdaq"},
+		{"-alerts-capture", "captures"},
+		{"-index", "volume"},
+		{"-config", filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		if _, err := parseRun("run", args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+
+	settings := parseRunClean(t, "run", "-admin-token", "secret", "-large-trade", "100000")
+	if len(settings.Ignored) != 2 {
+		t.Errorf("expected -admin-token and -large-trade reported as ignored, got %v", settings.Ignored)
+	}
+}
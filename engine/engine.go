@@ -0,0 +1,281 @@
+// Package engine is the public API of the multi-exchange book engine, for Go programs that
+// embed it instead of running the binary
+//
+// A Feed connects to one exchange and keeps its order book current, resyncing on gaps;
+// an Engine runs feeds for several exchanges on one symbol:
+//
+//	e := engine.New("BTCUSDT", engine.Options{})
+//	if err := e.Start(ctx, engine.Binance, engine.OKX); err != nil {
+//		log.Print(err) // The exchanges that connected keep running
+//	}
+//	defer e.Close()
+//	snapshot := e.Book(engine.Binance).Snapshot()
+//
+// The types are aliases of the ones the binary uses, so their methods are documented there;
+// this package is the supported way to reach them from outside the module
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/factory"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// Books and their read-only copies
+type (
+	OrderBook    = orderbook.OrderBook    // Live book of one exchange, safe for concurrent use
+	BookSnapshot = orderbook.BookSnapshot // Consistent copy of a book and its stats
+	Stats        = types.Stats            // Spread, mid, liquidity and imbalance of a book
+	Level        = types.PriceLevel       // Price and quantity of a level of a book
+	Side         = types.Side             // Buy or sell, the side of the book an order takes
+	Execution    = types.Execution        // Result of walking a book for a size or notional
+	GapPolicy    = types.GapPolicy        // Reaction of a book to a sequence gap
+	CBBO         = analytics.CBBO         // Consolidated best bid and offer across books
+)
+
+// Exchange adapters and the canonical messages they produce
+type (
+	Exchange       = exchange.Exchange      // Adapter streaming the book of one venue
+	ExchangeName   = exchange.ExchangeName  // Registered name of an adapter, e.g. binance
+	Capabilities   = exchange.Capabilities  // What a feed offers: depth, update interval, checksums
+	Snapshot       = exchange.Snapshot      // Full book as sent by a venue
+	DepthUpdate    = exchange.DepthUpdate   // Change to a book as sent by a venue
+	PriceLevel     = exchange.PriceLevel    // Level of a snapshot or update, as text
+	Trade          = exchange.Trade         // Public trade
+	HealthStatus   = exchange.HealthStatus  // Connection health reported by an adapter
+	ExchangeConfig = factory.ExchangeConfig // What an adapter is created from
+	Constructor    = factory.Constructor    // Creates an adapter from its config
+)
+
+// Exchanges that ship with the engine, see Exchanges and DefaultExchanges for those
+// registered and monitored by default
+const (
+	Binancef     = exchange.Binancef
+	Binance      = exchange.Binance
+	BinanceUS    = exchange.BinanceUS
+	BinanceCoinf = exchange.BinanceCoinf
+	Bybitf       = exchange.Bybitf
+	Bybit        = exchange.Bybit
+	Kraken       = exchange.Kraken
+	Hyperliquidf = exchange.Hyperliquidf
+	OKX          = exchange.OKX
+	Coinbase     = exchange.Coinbase
+	Asterdexf    = exchange.Asterdexf
+	BingX        = exchange.BingX
+	BingXf       = exchange.BingXf
+	Deribitf     = exchange.Deribitf
+	Gate         = exchange.Gate
+	Gatef        = exchange.Gatef
+	Dydxf        = exchange.Dydxf
+	Bitget       = exchange.Bitget
+	Bitgetf      = exchange.Bitgetf
+	HTX          = exchange.HTX
+	CryptoCom    = exchange.CryptoCom
+	Bitstamp     = exchange.Bitstamp
+	Gemini       = exchange.Gemini
+	Phemex       = exchange.Phemex
+	Phemexf      = exchange.Phemexf
+	WooXf        = exchange.WooXf
+	Aevof        = exchange.Aevof
+	Paradexf     = exchange.Paradexf
+	Hyperliquid  = exchange.Hyperliquid
+	CoinbaseIntx = exchange.CoinbaseIntx
+	Backpack     = exchange.Backpack
+	UniswapV3    = exchange.UniswapV3
+	Lighterf     = exchange.Lighterf
+	Poloniex     = exchange.Poloniex
+	BitMart      = exchange.BitMart
+	File         = exchange.File
+	Aggregated   = exchange.Aggregated
+)
+
+// Gap policies, see OrderBook.SetGapPolicy
+const (
+	GapBuffer = types.GapBuffer
+	GapLog    = types.GapLog
+	GapDrop   = types.GapDrop
+	GapResync = types.GapResync
+)
+
+// NewOrderBook creates an empty book, to feed with LoadSnapshot and HandleDepthUpdate
+func NewOrderBook() *OrderBook {
+	return orderbook.New()
+}
+
+// NewExchange creates the adapter registered under name for symbol, not yet connected
+func NewExchange(name ExchangeName, symbol string) (Exchange, error) {
+	return factory.NewExchange(factory.ExchangeConfig{Name: name, Symbol: symbol})
+}
+
+// Register adds an adapter under name, so Connect and Engine.Start can use it; it panics if
+// the name is taken
+func Register(name ExchangeName, constructor Constructor) {
+	factory.Register(name, constructor)
+}
+
+// Exchanges returns every registered exchange
+func Exchanges() []ExchangeName {
+	return factory.ListRegistered()
+}
+
+// DefaultExchanges returns the exchanges the binary monitors unless told otherwise
+func DefaultExchanges() []ExchangeName {
+	return factory.ListMonitored()
+}
+
+// ParseExchanges parses a comma-separated list of registered exchange names
+func ParseExchanges(list string) ([]ExchangeName, error) {
+	return factory.ParseExchanges(list)
+}
+
+// ComputeCBBO returns the best bid and offer across snapshots keyed by exchange, false
+// when no book has both sides
+func ComputeCBBO(books map[string]*BookSnapshot) (CBBO, bool) {
+	return analytics.ComputeCBBO(books)
+}
+
+// Options tune the books of feeds; zero values keep the defaults
+type Options struct {
+	LiquidityBands []decimal.Decimal // Bands reported around mid as fractions, default 0.5%, 2% and 10%
+	MaxDepth       int               // Levels kept per side, 0 keeps all
+	GapPolicy      GapPolicy         // Reaction to sequence gaps, default GapBuffer
+	ResyncCheck    time.Duration     // How often books are checked for a needed resync, default 5s
+	Logger         *slog.Logger      // Receives the events of the books, default slog.Default()
+}
+
+// Engine runs a feed per exchange on one symbol
+type Engine struct {
+	symbol  string
+	options Options
+	mu      sync.RWMutex
+	feeds   map[ExchangeName]*Feed
+}
+
+// New creates an engine for symbol without feeds
+func New(symbol string, options Options) *Engine {
+	return &Engine{symbol: symbol, options: options, feeds: make(map[ExchangeName]*Feed)}
+}
+
+// Symbol returns the symbol of the engine
+func (e *Engine) Symbol() string {
+	return e.symbol
+}
+
+// Start connects to the exchanges at once, the default ones when none are given, and
+// returns once every book is loaded or its connection failed; the errors of the failed
+// ones are joined, the others keep running
+func (e *Engine) Start(ctx context.Context, names ...ExchangeName) error {
+	if len(names) == 0 {
+		names = DefaultExchanges()
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(names))
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = e.Add(ctx, name)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Add connects to one more exchange; adding one that runs does nothing
+func (e *Engine) Add(ctx context.Context, name ExchangeName) error {
+	e.mu.RLock()
+	_, running := e.feeds[name]
+	e.mu.RUnlock()
+	if running {
+		return nil
+	}
+
+	ex, err := NewExchange(name, e.symbol)
+	if err != nil {
+		return err
+	}
+	return e.Attach(ctx, ex)
+}
+
+// Attach runs a feed on an adapter created by the caller, e.g. one not registered
+func (e *Engine) Attach(ctx context.Context, ex Exchange) error {
+	feed, err := Attach(ctx, ex, e.options)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if previous, ok := e.feeds[feed.Name()]; ok {
+		previous.Close()
+	}
+	e.feeds[feed.Name()] = feed
+	return nil
+}
+
+// Remove closes the feed of an exchange
+func (e *Engine) Remove(name ExchangeName) error {
+	e.mu.Lock()
+	feed, ok := e.feeds[name]
+	delete(e.feeds, name)
+	e.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%s is not running", name)
+	}
+	return feed.Close()
+}
+
+// Feed returns the feed of an exchange, nil when it is not running
+func (e *Engine) Feed(name ExchangeName) *Feed {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.feeds[name]
+}
+
+// Book returns the book of an exchange, nil when it is not running
+func (e *Engine) Book(name ExchangeName) *OrderBook {
+	if feed := e.Feed(name); feed != nil {
+		return feed.Book()
+	}
+	return nil
+}
+
+// Snapshots returns a copy of every book, keyed by exchange
+func (e *Engine) Snapshots() map[string]*BookSnapshot {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	snapshots := make(map[string]*BookSnapshot, len(e.feeds))
+	for name, feed := range e.feeds {
+		snapshots[string(name)] = feed.Book().Snapshot()
+	}
+	return snapshots
+}
+
+// CBBO returns the best bid and offer across the books, false when none has both sides
+func (e *Engine) CBBO() (CBBO, bool) {
+	return ComputeCBBO(e.Snapshots())
+}
+
+// Close closes every feed
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	feeds := e.feeds
+	e.feeds = make(map[ExchangeName]*Feed)
+	e.mu.Unlock()
+
+	var errs []error
+	for _, feed := range feeds {
+		errs = append(errs, feed.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeExchange serves a fixed snapshot and whatever updates the test pushes
+type fakeExchange struct {
+	name     ExchangeName
+	snapshot *Snapshot
+	updates  chan *DepthUpdate
+}
+
+func newFakeExchange(name ExchangeName, bid, ask string) *fakeExchange {
+	return &fakeExchange{
+		name: name,
+		snapshot: &Snapshot{
+			LastUpdateID: 10,
+			Bids:         []PriceLevel{{Price: bid, Quantity: "1"}},
+			Asks:         []PriceLevel{{Price: ask, Quantity: "1"}},
+		},
+		updates: make(chan *DepthUpdate, 10),
+	}
+}
+
+func (f *fakeExchange) GetName() ExchangeName             { return f.name }
+func (f *fakeExchange) GetSymbol() string                 { return "BTCUSDT" }
+func (f *fakeExchange) Connect(ctx context.Context) error { return nil }
+func (f *fakeExchange) Close() error                      { close(f.updates); return nil }
+func (f *fakeExchange) Updates() <-chan *DepthUpdate      { return f.updates }
+func (f *fakeExchange) Trades() <-chan *Trade             { return nil }
+func (f *fakeExchange) IsConnected() bool                 { return true }
+func (f *fakeExchange) Capabilities() Capabilities        { return Capabilities{} }
+func (f *fakeExchange) Health() HealthStatus              { return HealthStatus{} }
+func (f *fakeExchange) GetSnapshot(ctx context.Context) (*Snapshot, error) {
+	return f.snapshot, nil
+}
+
+func TestFeed(t *testing.T) {
+	ex := newFakeExchange(Binance, "100", "101")
+	feed, err := Attach(context.Background(), ex, Options{})
+	if err != nil {
+		t.Fatalf("Attach() error: %v", err)
+	}
+	if bid, ok := feed.Book().BestBid(); !ok || bid.Price.String() != "100" {
+		t.Fatalf("expected the snapshot loaded, got bid %v", bid)
+	}
+
+	ex.updates <- &DepthUpdate{FirstUpdateID: 11, FinalUpdateID: 11, PrevUpdateID: 10, Bids: []PriceLevel{{Price: "100.5", Quantity: "2"}}}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if bid, _ := feed.Book().BestBid(); bid.Price.String() == "100.5" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the update")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := feed.Close(); err != nil {
+		t.Errorf("Close() error: %v", err)
+	}
+	select {
+	case <-feed.Done():
+	default:
+		t.Error("expected the feed stopped after Close")
+	}
+}
+
+func TestEngine(t *testing.T) {
+	e := New("BTCUSDT", Options{})
+	defer e.Close()
+	ctx := context.Background()
+	if err := e.Attach(ctx, newFakeExchange(Binance, "100", "101")); err != nil {
+		t.Fatalf("Attach() error: %v", err)
+	}
+	if err := e.Attach(ctx, newFakeExchange(OKX, "100.2", "100.8")); err != nil {
+		t.Fatalf("Attach() error: %v", err)
+	}
+
+	cbbo, ok := e.CBBO()
+	if !ok || cbbo.BestBid.String() != "100.2" || cbbo.AskVenues[0] != string(OKX) || cbbo.Venues != 2 {
+		t.Errorf("expected okx at both best prices, got %+v", cbbo)
+	}
+	if len(e.Snapshots()) != 2 || e.Book(Binance) == nil || e.Book(Kraken) != nil {
+		t.Errorf("expected the binance and okx books only, got %v", e.Snapshots())
+	}
+
+	if err := e.Remove(Binance); err != nil || e.Book(Binance) != nil {
+		t.Errorf("expected binance removed, got %v", err)
+	}
+	if err := e.Start(ctx, "mtgox"); err == nil {
+		t.Error("expected an error for an unregistered exchange")
+	}
+}
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+)
+
+// Feed keeps the book of one exchange current: it applies the updates of the adapter and
+// reloads the snapshot when the book falls out of sync
+type Feed struct {
+	ex      Exchange
+	book    *OrderBook
+	stop    chan struct{}
+	done    chan struct{}
+	closing sync.Once
+	err     error
+}
+
+// Connect creates the adapter registered under name and attaches a feed to it
+func Connect(ctx context.Context, name ExchangeName, symbol string, options Options) (*Feed, error) {
+	ex, err := NewExchange(name, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return Attach(ctx, ex, options)
+}
+
+// Attach connects an adapter, loads its snapshot and keeps applying its updates until the
+// feed is closed or the adapter closes its update channel
+func Attach(ctx context.Context, ex Exchange, options Options) (*Feed, error) {
+	logger := options.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger = logger.With("exchange", string(ex.GetName()), "symbol", ex.GetSymbol())
+	if options.ResyncCheck <= 0 {
+		options.ResyncCheck = 5 * time.Second
+	}
+
+	if err := ex.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", ex.GetName(), err)
+	}
+
+	ob := orderbook.New()
+	ob.SetLogger(logger)
+	ob.SetLiquidityBands(options.LiquidityBands)
+	ob.SetMaxDepth(options.MaxDepth)
+	if options.GapPolicy != "" {
+		ob.SetGapPolicy(options.GapPolicy)
+	}
+	caps := ex.Capabilities()
+	ob.SetL3(caps.L3)
+	if checksum := orderbook.ChecksumFor(ex.GetName()); checksum != nil && caps.Checksum {
+		ob.SetChecksum(checksum)
+	}
+
+	snapshot, err := ex.GetSnapshot(ctx)
+	if err == nil {
+		err = ob.LoadSnapshot(snapshot)
+	}
+	if err != nil {
+		ex.Close()
+		return nil, fmt.Errorf("failed to load the %s book: %w", ex.GetName(), err)
+	}
+
+	f := &Feed{ex: ex, book: ob, stop: make(chan struct{}), done: make(chan struct{})}
+	updatesDone := make(chan struct{})
+	go func() {
+		defer close(updatesDone)
+		for update := range ex.Updates() {
+			ob.HandleDepthUpdate(update)
+		}
+	}()
+	go f.resync(ctx, options.ResyncCheck, updatesDone)
+	ob.ProcessBufferedEvents()
+	return f, nil
+}
+
+// resync reinitializes the book from a fresh snapshot when it needs one, until the
+// updates end or the feed is closed
+func (f *Feed) resync(ctx context.Context, every time.Duration, updatesDone <-chan struct{}) {
+	defer close(f.done)
+	getSnapshot := func() (*exchange.Snapshot, error) {
+		if resyncer, ok := f.ex.(exchange.Resyncer); ok {
+			if err := resyncer.Resync(); err != nil {
+				return nil, fmt.Errorf("resync failed: %w", err)
+			}
+		}
+		return f.ex.GetSnapshot(ctx)
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.book.CheckAndReinitialize(getSnapshot)
+		case <-f.book.ResyncRequests():
+			f.book.CheckAndReinitialize(getSnapshot)
+		case <-updatesDone:
+			return
+		case <-f.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Name returns the exchange of the feed
+func (f *Feed) Name() ExchangeName {
+	return f.ex.GetName()
+}
+
+// Book returns the live book of the feed
+func (f *Feed) Book() *OrderBook {
+	return f.book
+}
+
+// Exchange returns the adapter of the feed, e.g. for its Health or Trades
+func (f *Feed) Exchange() Exchange {
+	return f.ex
+}
+
+// Done is closed once the feed stops: it was closed, its context ended or the adapter
+// closed its updates
+func (f *Feed) Done() <-chan struct{} {
+	return f.done
+}
+
+// Close closes the adapter and waits for the feed to stop
+func (f *Feed) Close() error {
+	f.closing.Do(func() {
+		close(f.stop)
+		f.err = f.ex.Close()
+	})
+	<-f.done
+	return f.err
+}
@@ -1,8 +1,8 @@
 package aggregation
 
 import (
+	"github.com/imajinl/crypto-orderbook/internal/types"
 	"github.com/shopspring/decimal"
-	"orderbook/internal/types"
 )
 
 // Aggregator handles price aggregation based on tick levels
@@ -3,8 +3,8 @@ package aggregation
 import (
 	"testing"
 
+	"github.com/imajinl/crypto-orderbook/internal/types"
 	"github.com/shopspring/decimal"
-	"orderbook/internal/types"
 )
 
 func TestNew(t *testing.T) {
@@ -0,0 +1,411 @@
+// Package alerts evaluates user-defined rules against the live books and sends the alerts
+// they raise to chat and webhook notifiers, once per condition rather than on every check
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// notifyTimeout bounds a single notifier call, so a hung endpoint cannot pile up senders
+const notifyTimeout = 10 * time.Second
+
+// Alert is a rule firing for one book, or an arbitrage pair, or clearing again
+type Alert struct {
+	Rule      string    `json:"rule"`
+	Type      RuleType  `json:"type"`
+	Exchange  string    `json:"exchange"` // buy>sell venues for arbitrage
+	Symbol    string    `json:"symbol"`
+	Value     float64   `json:"value"`     // Basis points for spread and arbitrage, percent for liquidity_drop, seconds for stale
+	Threshold float64   `json:"threshold"` // In the unit of Value
+	Resolved  bool      `json:"resolved"`  // The condition cleared
+	Since     time.Time `json:"since"`     // When the condition started to hold
+	Time      time.Time `json:"time"`
+}
+
+// Text formats an alert for chat notifiers and the log, e.g.
+// [ALERT] spread: binance BTCUSDT spread 25.00bp above 20.00bp for 5s
+func (a Alert) Text() string {
+	state := "ALERT"
+	if a.Resolved {
+		state = "RESOLVED"
+	}
+	var measure string
+	switch a.Type {
+	case RuleSpread:
+		measure = fmt.Sprintf("spread %.2fbp, threshold %.2fbp", a.Value, a.Threshold)
+	case RuleArbitrage:
+		measure = fmt.Sprintf("bid over ask by %.2fbp, threshold %.2fbp", a.Value, a.Threshold)
+	case RuleLiquidityDrop:
+		measure = fmt.Sprintf("liquidity down %.1f%% from its high, threshold %.1f%%", a.Value, a.Threshold)
+	case RuleStale:
+		measure = fmt.Sprintf("no update for %.0fs, threshold %.0fs", a.Value, a.Threshold)
+	}
+	return fmt.Sprintf("[%s] %s: %s %s %s for %s", state, a.Rule, a.Exchange, a.Symbol, measure, a.Time.Sub(a.Since).Round(time.Second))
+}
+
+// logger returns the logger of the alerts engine
+func logger() *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, "alerts")
+}
+
+// condition is a rule holding for one key in one evaluation
+type condition struct {
+	exchange  string
+	value     float64
+	threshold float64
+}
+
+// state tracks a condition across evaluations
+type state struct {
+	since time.Time
+	sent  time.Time // When the alert was last sent, zero before it fired
+	last  condition
+}
+
+// sample is the banded notional of a book at one evaluation
+type sample struct {
+	at       time.Time
+	notional decimal.Decimal
+}
+
+// Engine evaluates the rules against the registered books every interval and sends the
+// alerts to the notifiers and handlers. Books register and unregister like they do with a
+// collector
+type Engine struct {
+	config    Config
+	notifiers []Notifier
+	mu        sync.Mutex
+	symbol    string
+	books     map[string]*orderbook.OrderBook
+	states    map[string]*state   // By rule and key
+	history   map[string][]sample // Banded notional by rule and exchange, for liquidity drops
+	sent      [][]time.Time       // Send times within the last minute, by notifier
+	handlers  []func(Alert)
+	dropped   atomic.Int64 // Alerts a notifier did not get because of the rate limit
+	failed    atomic.Int64 // Notifier calls that returned an error
+}
+
+// New creates an engine for the rules of a validated config; call Start to evaluate them
+func New(config Config, symbol string) (*Engine, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if config.Interval <= 0 {
+		config.Interval = Duration(time.Second)
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = Duration(5 * time.Minute)
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 20
+	}
+	e := &Engine{
+		config:  config,
+		symbol:  symbol,
+		books:   make(map[string]*orderbook.OrderBook),
+		states:  make(map[string]*state),
+		history: make(map[string][]sample),
+	}
+	for _, n := range config.Notifiers {
+		notifier, err := NewNotifier(n)
+		if err != nil {
+			return nil, err
+		}
+		e.notifiers = append(e.notifiers, notifier)
+	}
+	e.sent = make([][]time.Time, len(e.notifiers))
+	return e, nil
+}
+
+// Rules returns the rules evaluated
+func (e *Engine) Rules() []Rule {
+	return e.config.Rules
+}
+
+// Notifiers returns the names of the notifiers alerts are sent to
+func (e *Engine) Notifiers() []string {
+	names := make([]string, len(e.notifiers))
+	for i, n := range e.notifiers {
+		names[i] = n.Name()
+	}
+	return names
+}
+
+// OnAlert adds a handler called with every alert fired or resolved, outside the engine's lock
+func (e *Engine) OnAlert(handler func(Alert)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers = append(e.handlers, handler)
+}
+
+// RegisterOrderbook adds the book of an exchange to the books watched
+func (e *Engine) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.books[name] = ob
+}
+
+// UnregisterOrderbook removes the book of an exchange; its alerts resolve on the next evaluation
+func (e *Engine) UnregisterOrderbook(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.books, name)
+	for key := range e.history {
+		if _, exchange := splitKey(key); exchange == name {
+			delete(e.history, key)
+		}
+	}
+}
+
+// SetSymbol changes the symbol reported with alerts, for books registered after a symbol
+// switch; conditions of the previous symbol are dropped without resolving
+func (e *Engine) SetSymbol(symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.symbol = symbol
+	clear(e.states)
+	clear(e.history)
+}
+
+// Dropped returns the number of alerts held back from a notifier by the rate limit
+func (e *Engine) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Failed returns the number of notifier calls that failed
+func (e *Engine) Failed() int64 {
+	return e.failed.Load()
+}
+
+// Start evaluates the rules every interval until ctx is done
+func (e *Engine) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(e.config.Interval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, alert := range e.evaluate(now) {
+				e.dispatch(ctx, alert)
+			}
+		}
+	}
+}
+
+// evaluate checks every rule against the books and returns the alerts that fire, repeat
+// after the cooldown, or resolve
+func (e *Engine) evaluate(now time.Time) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	books := make(map[string]*orderbook.BookSnapshot, len(e.books))
+	for name, ob := range e.books {
+		books[name] = ob.Snapshot()
+	}
+
+	var alerts []Alert
+	holding := make(map[string]bool)
+	for i := range e.config.Rules {
+		rule := &e.config.Rules[i]
+		for _, c := range e.check(rule, books, now) {
+			key := rule.Name + "\x00" + c.exchange
+			holding[key] = true
+			s, ok := e.states[key]
+			if !ok {
+				s = &state{since: now}
+				e.states[key] = s
+			}
+			s.last = c
+			if now.Sub(s.since) < time.Duration(rule.For) {
+				continue
+			}
+			if s.sent.IsZero() || now.Sub(s.sent) >= time.Duration(e.config.Cooldown) {
+				s.sent = now
+				alerts = append(alerts, e.alert(rule, s, now, false))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(e.states))
+	for key := range e.states {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if holding[key] {
+			continue
+		}
+		s := e.states[key]
+		delete(e.states, key)
+		if s.sent.IsZero() {
+			continue // Cleared before it fired
+		}
+		name, _ := splitKey(key)
+		for i := range e.config.Rules {
+			if rule := &e.config.Rules[i]; rule.Name == name {
+				alerts = append(alerts, e.alert(rule, s, now, true))
+			}
+		}
+	}
+	return alerts
+}
+
+// alert builds the alert of a rule from the last state of its condition
+func (e *Engine) alert(rule *Rule, s *state, now time.Time, resolved bool) Alert {
+	return Alert{
+		Rule:      rule.Name,
+		Type:      rule.Type,
+		Exchange:  s.last.exchange,
+		Symbol:    e.symbol,
+		Value:     s.last.value,
+		Threshold: s.last.threshold,
+		Resolved:  resolved,
+		Since:     s.since,
+		Time:      now,
+	}
+}
+
+// check returns the conditions of a rule that hold (must be called with mutex locked)
+func (e *Engine) check(rule *Rule, books map[string]*orderbook.BookSnapshot, now time.Time) []condition {
+	var holding []condition
+	threshold := rule.threshold.InexactFloat64()
+	switch rule.Type {
+	case RuleStale:
+		for name, book := range books {
+			if !rule.watches(name) || !book.Initialized || book.Stats.LastUpdateTime.IsZero() {
+				continue
+			}
+			if age := now.Sub(book.Stats.LastUpdateTime); age >= time.Duration(rule.After) {
+				holding = append(holding, condition{exchange: name, value: age.Seconds(), threshold: time.Duration(rule.After).Seconds()})
+			}
+		}
+
+	case RuleSpread:
+		for name, book := range books {
+			if !rule.watches(name) || !live(book) {
+				continue
+			}
+			spread := book.Asks[0].Price.Sub(book.Bids[0].Price).Div(book.MidPrice())
+			if spread.GreaterThan(rule.threshold) {
+				holding = append(holding, condition{exchange: name, value: spread.InexactFloat64() * 10000, threshold: threshold * 10000})
+			}
+		}
+
+	case RuleLiquidityDrop:
+		for name, book := range books {
+			if !rule.watches(name) || !live(book) {
+				continue
+			}
+			depth := book.DepthAtOffsets([]decimal.Decimal{rule.band})
+			if len(depth) == 0 {
+				continue
+			}
+			notional := depth[0].BidNotional.Add(depth[0].AskNotional)
+			key := rule.Name + "\x00" + name
+			history := append(e.history[key], sample{at: now, notional: notional})
+			cutoff := now.Add(-time.Duration(rule.Window))
+			for len(history) > 0 && history[0].at.Before(cutoff) {
+				history = history[1:]
+			}
+			e.history[key] = history
+
+			high := notional
+			for _, s := range history {
+				high = decimal.Max(high, s.notional)
+			}
+			if !high.IsPositive() {
+				continue
+			}
+			if drop := decimal.NewFromInt(1).Sub(notional.Div(high)); drop.GreaterThanOrEqual(rule.threshold) {
+				holding = append(holding, condition{exchange: name, value: drop.InexactFloat64() * 100, threshold: threshold * 100})
+			}
+		}
+
+	case RuleArbitrage:
+		watched := make(map[string]*orderbook.BookSnapshot, len(books))
+		for name, book := range books {
+			if rule.watches(name) {
+				watched[name] = book
+			}
+		}
+		cbbo, ok := analytics.ComputeCBBO(watched)
+		if !ok || cbbo.Spread.IsPositive() || cbbo.Spread.IsZero() {
+			break
+		}
+		mid := cbbo.BestBid.Add(cbbo.BestAsk).Div(decimal.NewFromInt(2))
+		if gap := cbbo.Spread.Neg().Div(mid); gap.GreaterThan(rule.threshold) {
+			pair := cbbo.AskVenues[0] + ">" + cbbo.BidVenues[0]
+			holding = append(holding, condition{exchange: pair, value: gap.InexactFloat64() * 10000, threshold: threshold * 10000})
+		}
+	}
+	return holding
+}
+
+// live reports whether a book has an uncrossed top
+func live(book *orderbook.BookSnapshot) bool {
+	return book.Initialized && len(book.Bids) > 0 && len(book.Asks) > 0 && book.Asks[0].Price.GreaterThan(book.Bids[0].Price)
+}
+
+// splitKey returns the rule name and exchange of a state or history key
+func splitKey(key string) (rule, exchange string) {
+	rule, exchange, _ = strings.Cut(key, "\x00")
+	return rule, exchange
+}
+
+// dispatch hands an alert to the handlers, then to every notifier within its rate limit
+func (e *Engine) dispatch(ctx context.Context, alert Alert) {
+	e.mu.Lock()
+	handlers := append([]func(Alert){}, e.handlers...)
+	e.mu.Unlock()
+	for _, handler := range handlers {
+		handler(alert)
+	}
+
+	for i, notifier := range e.notifiers {
+		if !e.allow(i, alert.Time) {
+			e.dropped.Add(1)
+			logger().Warn("Alert rate limited", "notifier", notifier.Name(), "rule", alert.Rule, logging.KeyExchange, alert.Exchange)
+			continue
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+			defer cancel()
+			if err := notifier.Notify(ctx, alert); err != nil {
+				e.failed.Add(1)
+				logger().Error("Failed to send alert", "notifier", notifier.Name(), "rule", alert.Rule, "error", err)
+			}
+		}()
+	}
+}
+
+// allow reports whether a notifier may take one more alert this minute, and counts it
+func (e *Engine) allow(notifier int, now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	sent := e.sent[notifier]
+	cutoff := now.Add(-time.Minute)
+	for len(sent) > 0 && !sent[0].After(cutoff) {
+		sent = sent[1:]
+	}
+	if len(sent) >= e.config.RateLimit {
+		e.sent[notifier] = sent
+		return false
+	}
+	e.sent[notifier] = append(sent, now)
+	return true
+}
@@ -0,0 +1,290 @@
+package alerts
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+)
+
+func liveBook(t *testing.T, bid, ask, qty string) *orderbook.OrderBook {
+	t.Helper()
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		Bids: []exchange.PriceLevel{{Price: bid, Quantity: qty}},
+		Asks: []exchange.PriceLevel{{Price: ask, Quantity: qty}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	return ob
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("ALERT_TOKEN", "secret")
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	write := func(body string) {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"rules": [{"type": "spread", "threshold": "20bp", "for": "5s"}, {"type": "stale", "after": "30s", "exchanges": ["OKX"]}],
+		"notifiers": [{"type": "telegram", "token": "${ALERT_TOKEN}", "chat_id": "1"}]}`)
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if rule := config.Rules[0]; rule.Name != "spread" || rule.threshold.String() != "0.002" || time.Duration(rule.For) != 5*time.Second {
+		t.Errorf("unexpected spread rule %+v", rule)
+	}
+	if rule := config.Rules[1]; !rule.watches("okx") || rule.watches("bybit") {
+		t.Errorf("expected the stale rule to watch okx only, got %v", rule.Exchanges)
+	}
+	if config.Notifiers[0].Token != "secret" {
+		t.Errorf("expected the token expanded from the environment, got %q", config.Notifiers[0].Token)
+	}
+
+	for _, body := range []string{
+		`{"rules": []}`,
+		`{"rules": [{"type": "median"}]}`,
+		`{"rules": [{"type": "spread"}]}`,
+		`{"rules": [{"type": "stale"}]}`,
+		`{"rules": [{"type": "liquidity_drop", "threshold": "100%"}]}`,
+		`{"rules": [{"type": "spread", "threshold": "1%"}, {"type": "spread", "threshold": "2%"}]}`,
+		`{"rules": [{"type": "spread", "threshold": "1%", "for": 5}]}`,
+		`{"rules": [{"type": "spread", "threshold": "1%"}], "notifiers": [{"type": "slack"}]}`,
+		`{"rules": [{"type": "spread", "threshold": "1%"}], "unknown": true}`,
+	} {
+		write(body)
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("expected an error for %s", body)
+		}
+	}
+}
+
+func TestEngine(t *testing.T) {
+	e, err := New(Config{
+		Cooldown: Duration(time.Minute),
+		Rules: []Rule{
+			{Type: RuleSpread, Threshold: "50bp", For: Duration(2 * time.Second)},
+			{Type: RuleStale, After: Duration(time.Minute), Exchanges: []string{"okx"}},
+			{Type: RuleArbitrage, Threshold: "10bp"},
+		},
+	}, "BTCUSDT")
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// okx is 2% wide, and bybit bids above its ask
+	e.RegisterOrderbook("okx", liveBook(t, "99", "101", "1"), exchange.Capabilities{})
+	e.RegisterOrderbook("bybit", liveBook(t, "101.5", "101.6", "1"), exchange.Capabilities{})
+	start := time.Now()
+
+	alerts := e.evaluate(start)
+	if len(alerts) != 1 || alerts[0].Type != RuleArbitrage || alerts[0].Exchange != "okx>bybit" || alerts[0].Value < 49 {
+		t.Fatalf("expected only the arbitrage to fire at once, got %+v", alerts)
+	}
+	if !strings.HasPrefix(alerts[0].Text(), "[ALERT] arbitrage: okx>bybit BTCUSDT") {
+		t.Errorf("unexpected text %q", alerts[0].Text())
+	}
+
+	// The spread fires once it held for 2s, and not again within the cooldown
+	alerts = e.evaluate(start.Add(2 * time.Second))
+	if len(alerts) != 1 || alerts[0].Rule != "spread" || alerts[0].Exchange != "okx" || alerts[0].Value < 199 || alerts[0].Threshold != 50 {
+		t.Fatalf("expected the okx spread to fire, got %+v", alerts)
+	}
+	if alerts = e.evaluate(start.Add(30 * time.Second)); len(alerts) != 0 {
+		t.Errorf("expected no repeats within the cooldown, got %+v", alerts)
+	}
+
+	// Past the cooldown both repeat, and a minute without updates makes okx stale
+	alerts = e.evaluate(start.Add(2 * time.Minute))
+	if len(alerts) != 3 || alerts[0].Type != RuleStale && alerts[1].Type != RuleStale && alerts[2].Type != RuleStale {
+		t.Errorf("expected the spread and arbitrage repeated and okx stale, got %+v", alerts)
+	}
+
+	// Removing okx resolves everything it was part of
+	e.UnregisterOrderbook("okx")
+	alerts = e.evaluate(start.Add(3 * time.Minute))
+	if len(alerts) != 3 {
+		t.Fatalf("expected three resolved alerts, got %+v", alerts)
+	}
+	for _, alert := range alerts {
+		if !alert.Resolved || !strings.HasPrefix(alert.Text(), "[RESOLVED]") {
+			t.Errorf("expected %s resolved, got %+v", alert.Rule, alert)
+		}
+	}
+}
+
+func TestLiquidityDrop(t *testing.T) {
+	e, err := New(Config{Rules: []Rule{{Type: RuleLiquidityDrop, Threshold: "50%", Window: Duration(time.Minute)}}}, "BTCUSDT")
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	ob := liveBook(t, "99.9", "100.1", "10")
+	e.RegisterOrderbook("okx", ob, exchange.Capabilities{})
+	start := time.Now()
+	if alerts := e.evaluate(start); len(alerts) != 0 {
+		t.Fatalf("expected no drop from the first sample, got %+v", alerts)
+	}
+
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		Bids: []exchange.PriceLevel{{Price: "99.9", Quantity: "3"}},
+		Asks: []exchange.PriceLevel{{Price: "100.1", Quantity: "3"}},
+	})
+	alerts := e.evaluate(start.Add(time.Second))
+	if len(alerts) != 1 || alerts[0].Value < 69.9 || alerts[0].Value > 70.1 {
+		t.Fatalf("expected a 70%% drop, got %+v", alerts)
+	}
+
+	// Once the high leaves the window the thinner book is the new normal
+	if alerts = e.evaluate(start.Add(2 * time.Minute)); len(alerts) != 1 || !alerts[0].Resolved {
+		t.Errorf("expected the drop resolved after the window, got %+v", alerts)
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	var mu sync.Mutex
+	bodies := make(map[string]map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode error: %v", err)
+		}
+		mu.Lock()
+		bodies[r.URL.Path] = body
+		mu.Unlock()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		if r.URL.Path == "/hook" && r.Header.Get("Authorization") != "Bearer x" {
+			t.Errorf("expected the configured header, got %q", r.Header.Get("Authorization"))
+		}
+	}))
+	defer server.Close()
+
+	alert := Alert{Rule: "spread", Type: RuleSpread, Exchange: "okx", Symbol: "BTCUSDT", Value: 25, Threshold: 20, Time: time.Now()}
+	for _, config := range []NotifierConfig{
+		{Type: "slack", URL: server.URL + "/slack"},
+		{Type: "discord", URL: server.URL + "/discord"},
+		{Type: "webhook", URL: server.URL + "/hook", Headers: map[string]string{"Authorization": "Bearer x"}},
+	} {
+		notifier, err := NewNotifier(config)
+		if err != nil {
+			t.Fatalf("NewNotifier(%s) error: %v", config.Type, err)
+		}
+		if err := notifier.Notify(context.Background(), alert); err != nil {
+			t.Errorf("%s Notify() error: %v", config.Type, err)
+		}
+	}
+	telegram := &telegramNotifier{client: http.DefaultClient, api: server.URL, token: "T", chatID: "42"}
+	if err := telegram.Notify(context.Background(), alert); err != nil {
+		t.Errorf("telegram Notify() error: %v", err)
+	}
+	failing, _ := NewNotifier(NotifierConfig{Type: "webhook", URL: server.URL + "/fail"})
+	if err := failing.Notify(context.Background(), alert); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+
+	if text := bodies["/slack"]["text"]; text != alert.Text() {
+		t.Errorf("unexpected slack body %v", bodies["/slack"])
+	}
+	if content := bodies["/discord"]["content"]; content != alert.Text() {
+		t.Errorf("unexpected discord body %v", bodies["/discord"])
+	}
+	if bodies["/hook"]["rule"] != "spread" || bodies["/hook"]["value"] != 25.0 {
+		t.Errorf("unexpected webhook body %v", bodies["/hook"])
+	}
+	if body := bodies["/botT/sendMessage"]; body["chat_id"] != "42" || body["text"] != alert.Text() {
+		t.Errorf("unexpected telegram body %v", body)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	e, err := New(Config{RateLimit: 2, Rules: []Rule{{Type: RuleSpread, Threshold: "1%"}}}, "BTCUSDT")
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	e.notifiers = []Notifier{&webhookNotifier{}}
+	e.sent = make([][]time.Time, 1)
+	start := time.Now()
+	for i := range 3 {
+		if allowed := e.allow(0, start.Add(time.Duration(i)*time.Second)); allowed != (i < 2) {
+			t.Errorf("alert %d allowed %v", i, allowed)
+		}
+	}
+	if !e.allow(0, start.Add(time.Minute+time.Second)) {
+		t.Error("expected the limit to free up after a minute")
+	}
+}
+
+func TestCapture(t *testing.T) {
+	capture, err := NewCapture(CaptureConfig{Dir: t.TempDir(), Before: 2 * time.Second, After: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("NewCapture() error: %v", err)
+	}
+	ob := liveBook(t, "99", "101", "1")
+	capture.RegisterOrderbook("okx", ob, exchange.Capabilities{})
+	widen := func(ask string) {
+		ob.HandleDepthUpdate(&exchange.DepthUpdate{Asks: []exchange.PriceLevel{{Price: ask, Quantity: "1"}}})
+	}
+
+	// The first sample falls out of the history before the alert reaches back to it
+	start := time.Now()
+	capture.sample(start.Add(-5 * time.Second))
+	widen("102")
+	capture.sample(start)
+	capture.sample(start.Add(500 * time.Millisecond))
+	capture.Trigger(Alert{Rule: "spread", Exchange: "okx", Symbol: "BTC/USDT", Time: start.Add(time.Second), Resolved: true})
+	capture.Trigger(Alert{Rule: "spread", Exchange: "okx", Symbol: "BTC/USDT", Time: start.Add(time.Second)})
+
+	widen("103")
+	capture.sample(start.Add(2 * time.Second))
+	if capture.Written() != 0 {
+		t.Fatal("expected nothing written before the capture window ends")
+	}
+	capture.sample(start.Add(3 * time.Second))
+	capture.Close()
+	if capture.Written() != 1 {
+		t.Fatalf("expected one capture written, got %d written and %d failed", capture.Written(), capture.Failed())
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(capture.Config().Dir, "*-BTC_USDT-spread-okx.json.gz"))
+	if len(paths) != 1 {
+		t.Fatalf("expected one capture file, got %v", paths)
+	}
+	file, err := os.Open(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	stream, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var anomaly Anomaly
+	if err := json.NewDecoder(stream).Decode(&anomaly); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(anomaly.Frames) != 2 || anomaly.Alert.Rule != "spread" {
+		t.Fatalf("expected the books before and after the alert, got %+v", anomaly)
+	}
+	if ask := anomaly.Frames[0].Asks[1]; ask[0] != "102" {
+		t.Errorf("expected the full book before the alert, got asks %v", anomaly.Frames[0].Asks)
+	}
+	if ask := anomaly.Frames[1].Asks[2]; ask[0] != "103" {
+		t.Errorf("expected the full book after the alert, got asks %v", anomaly.Frames[1].Asks)
+	}
+}
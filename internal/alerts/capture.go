@@ -0,0 +1,308 @@
+package alerts
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+)
+
+// maxCaptures bounds the captures in progress; alerts beyond it are not captured
+const maxCaptures = 16
+
+// CaptureConfig selects what is kept of the books around every alert that fires
+type CaptureConfig struct {
+	Dir      string        // Where the captures are written, default anomalies
+	Before   time.Duration // Kept from before the alert, default 10s
+	After    time.Duration // Captured after the alert, default 10s
+	Interval time.Duration // Between samples of the books, default 250ms
+}
+
+// Frame is the full book of one exchange at one sample
+type Frame struct {
+	Exchange     string      `json:"exchange"`
+	Time         time.Time   `json:"time"`
+	LastUpdateID int64       `json:"last_update_id"`
+	Bids         [][2]string `json:"bids"` // Price and quantity, best first
+	Asks         [][2]string `json:"asks"`
+}
+
+// Anomaly is one capture file: the alert and the books of every exchange around it
+type Anomaly struct {
+	Alert  Alert     `json:"alert"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Frames []Frame   `json:"frames"` // In time order; a book appears only when it changed
+}
+
+// taken is a book sampled at one time
+type taken struct {
+	exchange string
+	at       time.Time
+	book     *orderbook.BookSnapshot
+}
+
+// capture is an alert still collecting the samples after it
+type capture struct {
+	alert  Alert
+	from   time.Time
+	until  time.Time
+	frames []taken
+}
+
+// Capture keeps the full books of every exchange over the last seconds and, when an alert
+// fires, writes them with those of the following seconds to
+//
+//	<dir>/<time>-<symbol>-<rule>-<exchange>.json.gz
+//
+// so the book context of an alert outlives the periodic aggregates. Samples share the
+// immutable book snapshots, and a book is only sampled again once it changed
+type Capture struct {
+	config   CaptureConfig
+	mu       sync.Mutex
+	books    map[string]*orderbook.OrderBook
+	versions map[string]uint64 // Version of the last sample of every book
+	history  []taken           // Samples of the last Before, oldest first
+	pending  []*capture
+	writes   sync.WaitGroup
+	written  atomic.Int64
+	failed   atomic.Int64
+	dropped  atomic.Int64
+}
+
+// NewCapture creates the capture directory
+func NewCapture(config CaptureConfig) (*Capture, error) {
+	if config.Dir == "" {
+		config.Dir = "anomalies"
+	}
+	if config.Before <= 0 {
+		config.Before = 10 * time.Second
+	}
+	if config.After <= 0 {
+		config.After = 10 * time.Second
+	}
+	if config.Interval <= 0 {
+		config.Interval = 250 * time.Millisecond
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+	return &Capture{
+		config:   config,
+		books:    make(map[string]*orderbook.OrderBook),
+		versions: make(map[string]uint64),
+	}, nil
+}
+
+// Config returns the config of the capture with its defaults filled in
+func (c *Capture) Config() CaptureConfig {
+	return c.config
+}
+
+// RegisterOrderbook adds the book of an exchange to the books sampled
+func (c *Capture) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.books[name] = ob
+}
+
+// UnregisterOrderbook stops sampling the book of an exchange; its samples so far are kept
+func (c *Capture) UnregisterOrderbook(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.books, name)
+	delete(c.versions, name)
+}
+
+// Reset drops the samples so far, for a symbol switch; captures in progress keep theirs
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = nil
+	clear(c.versions)
+}
+
+// Trigger starts capturing around an alert; resolved alerts are ignored. It can be passed
+// to Engine.OnAlert
+func (c *Capture) Trigger(alert Alert) {
+	if alert.Resolved {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) >= maxCaptures {
+		c.dropped.Add(1)
+		return
+	}
+
+	p := &capture{alert: alert, from: alert.Time.Add(-c.config.Before), until: alert.Time.Add(c.config.After)}
+	for _, sample := range c.history {
+		if !sample.at.Before(p.from) {
+			p.frames = append(p.frames, sample)
+		}
+	}
+	c.pending = append(c.pending, p)
+}
+
+// Written returns the number of captures written
+func (c *Capture) Written() int64 {
+	return c.written.Load()
+}
+
+// Failed returns the number of captures that could not be written
+func (c *Capture) Failed() int64 {
+	return c.failed.Load()
+}
+
+// Dropped returns the number of alerts not captured because too many captures were in progress
+func (c *Capture) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// Start samples the books every interval until ctx is done
+func (c *Capture) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.sample(now)
+		}
+	}
+}
+
+// Close writes the captures in progress with the samples they have so far and waits for
+// every write to finish
+func (c *Capture) Close() {
+	c.mu.Lock()
+	for _, p := range c.pending {
+		c.write(p)
+	}
+	c.pending = nil
+	c.mu.Unlock()
+	c.writes.Wait()
+}
+
+// sample adds the books that changed since their last sample to the history and the
+// captures in progress, and writes the captures whose time is up
+func (c *Capture) sample(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, ob := range c.books {
+		book := ob.Snapshot()
+		if !book.Initialized || c.versions[name] == book.Version {
+			continue
+		}
+		c.versions[name] = book.Version
+		sample := taken{exchange: name, at: now, book: book}
+		c.history = append(c.history, sample)
+		for _, p := range c.pending {
+			p.frames = append(p.frames, sample)
+		}
+	}
+
+	// Drop the samples no new alert can reach back to
+	cutoff := now.Add(-c.config.Before)
+	old := 0
+	for old < len(c.history) && c.history[old].at.Before(cutoff) {
+		old++
+	}
+	if old > 0 {
+		c.history = append([]taken(nil), c.history[old:]...)
+	}
+
+	remaining := c.pending[:0]
+	for _, p := range c.pending {
+		if now.Before(p.until) {
+			remaining = append(remaining, p)
+			continue
+		}
+		c.write(p)
+	}
+	clear(c.pending[len(remaining):])
+	c.pending = remaining
+}
+
+// write encodes a capture in the background; called with mu held
+func (c *Capture) write(p *capture) {
+	c.writes.Add(1)
+	go func() {
+		defer c.writes.Done()
+		path, err := c.save(p)
+		if err != nil {
+			c.failed.Add(1)
+			logger().Error("Failed to write capture", "event", "capture_failed", "rule", p.alert.Rule, "error", err)
+			return
+		}
+		c.written.Add(1)
+		logger().Info("Captured the books around an alert", "event", "captured", "rule", p.alert.Rule,
+			"exchange", p.alert.Exchange, "frames", len(p.frames), "file", path)
+	}()
+}
+
+// save writes a capture to its file and returns the path
+func (c *Capture) save(p *capture) (string, error) {
+	anomaly := Anomaly{Alert: p.alert, From: p.from, To: p.until, Frames: make([]Frame, len(p.frames))}
+	for i, sample := range p.frames {
+		anomaly.Frames[i] = Frame{
+			Exchange:     sample.exchange,
+			Time:         sample.at,
+			LastUpdateID: sample.book.LastUpdateID,
+			Bids:         levelPairs(sample.book.Bids),
+			Asks:         levelPairs(sample.book.Asks),
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s-%s-%s.json.gz", p.alert.Time.UTC().Format("20060102T150405.000Z"),
+		fileSafe(p.alert.Symbol), fileSafe(p.alert.Rule), fileSafe(p.alert.Exchange))
+	path := filepath.Join(c.config.Dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create capture: %w", err)
+	}
+	defer file.Close()
+
+	stream := gzip.NewWriter(file)
+	if err := json.NewEncoder(stream).Encode(anomaly); err != nil {
+		return "", fmt.Errorf("failed to encode capture: %w", err)
+	}
+	if err := stream.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress capture: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to close capture: %w", err)
+	}
+	return path, nil
+}
+
+// levelPairs converts levels to price and quantity pairs
+func levelPairs(levels []types.PriceLevel) [][2]string {
+	pairs := make([][2]string, len(levels))
+	for i, level := range levels {
+		pairs[i] = [2]string{level.Price.String(), level.Quantity.String()}
+	}
+	return pairs
+}
+
+// fileSafe replaces the characters of a rule or symbol that do not belong in a file name
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,182 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// RuleType selects what a rule watches
+type RuleType string
+
+const (
+	RuleSpread        RuleType = "spread"         // Spread of a book above Threshold of its mid
+	RuleLiquidityDrop RuleType = "liquidity_drop" // Notional within Band of mid down by Threshold from its high over Window
+	RuleStale         RuleType = "stale"          // No update to a book for After
+	RuleArbitrage     RuleType = "arbitrage"      // Best bid of one venue above the best ask of another by Threshold of mid
+)
+
+// Duration is a time.Duration written as a string in the alerts file, e.g. "30s"
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string like "1m30s"
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid duration %s, want a string like \"30s\"", data)
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("invalid duration %q", text)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Rule is one condition evaluated against the books
+type Rule struct {
+	Name      string   `json:"name,omitempty"`      // Shown in the alerts, default the type
+	Type      RuleType `json:"type"`                // spread, liquidity_drop, stale or arbitrage
+	Threshold string   `json:"threshold,omitempty"` // In percent or basis points, e.g. 20bp or 50%; not used by stale
+	Band      string   `json:"band,omitempty"`      // liquidity_drop only: band around mid, default 2%
+	Window    Duration `json:"window,omitempty"`    // liquidity_drop only: trailing window of the high, default 1m
+	After     Duration `json:"after,omitempty"`     // stale only: time without updates
+	For       Duration `json:"for,omitempty"`       // How long the condition must hold before the alert fires, default at once
+	Exchanges []string `json:"exchanges,omitempty"` // Books the rule watches, default every book
+
+	threshold decimal.Decimal
+	band      decimal.Decimal
+}
+
+// Config is the alerts file: the rules, where their alerts go, and how often
+type Config struct {
+	Interval  Duration         `json:"interval,omitempty"`   // Between evaluations, default 1s
+	Cooldown  Duration         `json:"cooldown,omitempty"`   // Before an alert that still holds is sent again, default 5m
+	RateLimit int              `json:"rate_limit,omitempty"` // Most alerts sent per minute to each notifier, default 20; the rest are dropped
+	Rules     []Rule           `json:"rules"`
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"` // None only logs the alerts
+}
+
+// LoadConfig reads and validates an alerts file, e.g.
+//
+//	{"cooldown": "10m",
+//	 "rules": [{"type": "spread", "threshold": "20bp", "for": "5s"},
+//	           {"type": "liquidity_drop", "band": "2%", "threshold": "50%", "window": "1m"},
+//	           {"type": "stale", "after": "30s", "exchanges": ["binance", "okx"]},
+//	           {"type": "arbitrage", "threshold": "10bp"}],
+//	 "notifiers": [{"type": "telegram", "token": "${TELEGRAM_TOKEN}", "chat_id": "12345"},
+//	               {"type": "slack", "url": "https://hooks.slack.com/services/..."}]}
+//
+// Environment variables in notifier URLs, tokens and headers are expanded, so secrets can
+// stay out of the file
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read alerts: %w", err)
+	}
+
+	var config Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("failed to parse alerts: %w", err)
+	}
+	for i := range config.Notifiers {
+		n := &config.Notifiers[i]
+		n.URL, n.Token, n.ChatID = os.ExpandEnv(n.URL), os.ExpandEnv(n.Token), os.ExpandEnv(n.ChatID)
+		for key, value := range n.Headers {
+			n.Headers[key] = os.ExpandEnv(value)
+		}
+	}
+	if err := config.validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// validate parses the thresholds of every rule and checks the notifiers
+func (c *Config) validate() error {
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("no alert rules")
+	}
+	names := make(map[string]bool, len(c.Rules))
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if err := rule.parse(); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i+1, err)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	for i, n := range c.Notifiers {
+		if _, err := NewNotifier(n); err != nil {
+			return fmt.Errorf("invalid notifier %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// parse fills in the defaults of a rule and parses its rates
+func (r *Rule) parse() error {
+	if r.Name == "" {
+		r.Name = string(r.Type)
+	}
+	for i, name := range r.Exchanges {
+		r.Exchanges[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	switch r.Type {
+	case RuleStale:
+		if r.After <= 0 {
+			return fmt.Errorf("stale rule %q needs after", r.Name)
+		}
+		return nil
+	case RuleSpread, RuleArbitrage:
+	case RuleLiquidityDrop:
+		band := r.Band
+		if band == "" {
+			band = "2%"
+		}
+		var err error
+		if r.band, err = types.ParseBand(band); err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		if r.Window <= 0 {
+			r.Window = Duration(time.Minute)
+		}
+	default:
+		return fmt.Errorf("unknown rule type %q (want spread, liquidity_drop, stale or arbitrage)", r.Type)
+	}
+
+	threshold, err := types.ParseBand(r.Threshold)
+	if err != nil {
+		return fmt.Errorf("rule %q needs a threshold in percent or basis points: %w", r.Name, err)
+	}
+	if r.Type == RuleLiquidityDrop && !threshold.LessThan(decimal.NewFromInt(1)) {
+		return fmt.Errorf("rule %q: a drop must be below 100%%", r.Name)
+	}
+	r.threshold = threshold
+	return nil
+}
+
+// watches reports whether the rule applies to the book of an exchange
+func (r *Rule) watches(name string) bool {
+	if len(r.Exchanges) == 0 {
+		return true
+	}
+	for _, exchange := range r.Exchanges {
+		if exchange == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// telegramAPI is the Bot API base URL messages are sent through
+const telegramAPI = "https://api.telegram.org"
+
+// Notifier delivers alerts to one destination
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NotifierConfig configures one destination of the alerts
+type NotifierConfig struct {
+	Type    string            `json:"type"`              // telegram, discord, slack or webhook
+	URL     string            `json:"url,omitempty"`     // discord, slack and webhook: URL posted to
+	Token   string            `json:"token,omitempty"`   // telegram only: bot token
+	ChatID  string            `json:"chat_id,omitempty"` // telegram only: chat the bot posts in
+	Headers map[string]string `json:"headers,omitempty"` // webhook only: sent with every request, e.g. Authorization
+}
+
+// NewNotifier creates the notifier of a config
+func NewNotifier(config NotifierConfig) (Notifier, error) {
+	client := &http.Client{Timeout: notifyTimeout}
+	switch strings.ToLower(config.Type) {
+	case "telegram":
+		if config.Token == "" || config.ChatID == "" {
+			return nil, fmt.Errorf("telegram needs token and chat_id")
+		}
+		return &telegramNotifier{client: client, api: telegramAPI, token: config.Token, chatID: config.ChatID}, nil
+	case "discord", "slack", "webhook":
+		if config.URL == "" {
+			return nil, fmt.Errorf("%s needs url", config.Type)
+		}
+		return &webhookNotifier{client: client, kind: strings.ToLower(config.Type), url: config.URL, headers: config.Headers}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q (want telegram, discord, slack or webhook)", config.Type)
+	}
+}
+
+// webhookNotifier posts alerts as JSON: the alert itself to a generic webhook, or its text
+// in the message format of a Discord or Slack incoming webhook
+type webhookNotifier struct {
+	client  *http.Client
+	kind    string // discord, slack or webhook
+	url     string
+	headers map[string]string
+}
+
+func (n *webhookNotifier) Name() string {
+	return n.kind
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	var body any = alert
+	switch n.kind {
+	case "discord":
+		body = map[string]string{"content": alert.Text()}
+	case "slack":
+		body = map[string]string{"text": alert.Text()}
+	}
+	return postJSON(ctx, n.client, n.url, body, n.headers)
+}
+
+// telegramNotifier sends the text of alerts through a Telegram bot
+type telegramNotifier struct {
+	client *http.Client
+	api    string
+	token  string
+	chatID string
+}
+
+func (n *telegramNotifier) Name() string {
+	return "telegram"
+}
+
+func (n *telegramNotifier) Notify(ctx context.Context, alert Alert) error {
+	url := n.api + "/bot" + n.token + "/sendMessage"
+	return postJSON(ctx, n.client, url, map[string]string{"chat_id": n.chatID, "text": alert.Text()}, nil)
+}
+
+// postJSON posts body as JSON and fails on any status other than 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, body any, headers map[string]string) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("alert rejected with status %d after %v: %s", resp.StatusCode, time.Since(start).Round(time.Millisecond), strings.TrimSpace(string(message)))
+	}
+	return nil
+}
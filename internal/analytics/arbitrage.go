@@ -0,0 +1,222 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// ArbitrageConfig configures an arbitrage detector
+type ArbitrageConfig struct {
+	Sizes     []decimal.Decimal // Order sizes in base units the spreads are computed for
+	Fees      Fees              // Both legs pay the taker fee of their exchange
+	Threshold decimal.Decimal   // Net spread an opportunity must exceed to open, as a fraction of the buy notional
+	Interval  time.Duration     // Time between evaluations, default 1s
+}
+
+// ArbitrageSpread is the result of buying Size at the asks of one exchange and selling it
+// at the bids of another, both as market orders
+type ArbitrageSpread struct {
+	Buy      string          // Exchange bought on
+	Sell     string          // Exchange sold on
+	Size     decimal.Decimal // Base units
+	BuyVWAP  decimal.Decimal
+	SellVWAP decimal.Decimal
+	Gross    decimal.Decimal // (SellVWAP - BuyVWAP) / BuyVWAP
+	Net      decimal.Decimal // Profit as a fraction of the buy notional
+	Profit   decimal.Decimal // Quote currency left after the fees of both legs
+}
+
+// ArbitrageEvent reports an opportunity opening, or closing once its net spread no longer
+// exceeds the threshold or one of its books is gone
+type ArbitrageEvent struct {
+	ArbitrageSpread // As of the event; the last spread seen when a book is gone
+	Symbol          string
+	Open            bool
+	Since           time.Time       // When the opportunity opened
+	Time            time.Time       // When the event happened
+	Peak            decimal.Decimal // Highest net spread while open
+}
+
+// arbitrageKey identifies an opportunity
+type arbitrageKey struct {
+	buy, sell, size string
+}
+
+// Arbitrage computes the executable spreads between every pair of registered books and
+// reports opportunities to its handlers. Books register and unregister like they do with
+// a collector
+type Arbitrage struct {
+	config   ArbitrageConfig
+	mu       sync.Mutex
+	symbol   string
+	books    map[string]*orderbook.OrderBook
+	spreads  []ArbitrageSpread
+	open     map[arbitrageKey]*ArbitrageEvent
+	handlers []func(ArbitrageEvent)
+}
+
+// NewArbitrage creates a detector for the books of symbol; call Start to evaluate them
+func NewArbitrage(config ArbitrageConfig, symbol string) *Arbitrage {
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	return &Arbitrage{
+		config: config,
+		symbol: symbol,
+		books:  make(map[string]*orderbook.OrderBook),
+		open:   make(map[arbitrageKey]*ArbitrageEvent),
+	}
+}
+
+// OnEvent adds a handler called with every opportunity opening or closing, outside the
+// detector's lock
+func (a *Arbitrage) OnEvent(handler func(ArbitrageEvent)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.handlers = append(a.handlers, handler)
+}
+
+// RegisterOrderbook adds the book of an exchange to the pairs evaluated
+func (a *Arbitrage) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.books[name] = ob
+}
+
+// UnregisterOrderbook removes the book of an exchange; its open opportunities close on
+// the next evaluation
+func (a *Arbitrage) UnregisterOrderbook(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.books, name)
+}
+
+// SetSymbol changes the symbol reported with events, for books registered after a symbol
+// switch; open opportunities of the previous symbol are dropped without an event
+func (a *Arbitrage) SetSymbol(symbol string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.symbol = symbol
+	a.spreads = nil
+	clear(a.open)
+}
+
+// Spreads returns the spreads of the latest evaluation, highest net spread first
+func (a *Arbitrage) Spreads() []ArbitrageSpread {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]ArbitrageSpread(nil), a.spreads...)
+}
+
+// Start evaluates the books every interval until ctx is done
+func (a *Arbitrage) Start(ctx context.Context) {
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.evaluate(now)
+		}
+	}
+}
+
+// fee returns the taker fee of an exchange
+func (a *Arbitrage) fee(name string) decimal.Decimal {
+	return a.config.Fees.For(name).Taker
+}
+
+// evaluate walks every initialized book once per size and side, pairs the results and
+// opens and closes opportunities
+func (a *Arbitrage) evaluate(now time.Time) {
+	a.mu.Lock()
+	names := make([]string, 0, len(a.books))
+	buys := make(map[string][]types.Execution, len(a.books))
+	sells := make(map[string][]types.Execution, len(a.books))
+	for name, ob := range a.books {
+		if !ob.IsInitialized() {
+			continue
+		}
+		names = append(names, name)
+		for _, size := range a.config.Sizes {
+			buys[name] = append(buys[name], ob.PriceForSize(types.Buy, size))
+			sells[name] = append(sells[name], ob.PriceForSize(types.Sell, size))
+		}
+	}
+	sort.Strings(names)
+
+	var spreads []ArbitrageSpread
+	var events []ArbitrageEvent
+	seen := make(map[arbitrageKey]bool)
+	one := decimal.NewFromInt(1)
+	for _, buy := range names {
+		for _, sell := range names {
+			if buy == sell {
+				continue
+			}
+			for i, size := range a.config.Sizes {
+				bought, sold := buys[buy][i], sells[sell][i]
+				if !bought.Complete || !sold.Complete {
+					continue
+				}
+				cost := bought.VWAP.Mul(one.Add(a.fee(buy)))
+				proceeds := sold.VWAP.Mul(one.Sub(a.fee(sell)))
+				spread := ArbitrageSpread{
+					Buy:      buy,
+					Sell:     sell,
+					Size:     size,
+					BuyVWAP:  bought.VWAP,
+					SellVWAP: sold.VWAP,
+					Gross:    sold.VWAP.Sub(bought.VWAP).Div(bought.VWAP),
+					Net:      proceeds.Sub(cost).Div(bought.VWAP),
+					Profit:   proceeds.Sub(cost).Mul(size),
+				}
+				spreads = append(spreads, spread)
+
+				key := arbitrageKey{buy: buy, sell: sell, size: size.String()}
+				seen[key] = true
+				event, open := a.open[key]
+				switch {
+				case spread.Net.GreaterThan(a.config.Threshold) && !open:
+					event = &ArbitrageEvent{ArbitrageSpread: spread, Symbol: a.symbol, Open: true, Since: now, Time: now, Peak: spread.Net}
+					a.open[key] = event
+					events = append(events, *event)
+				case spread.Net.GreaterThan(a.config.Threshold):
+					event.ArbitrageSpread = spread
+					event.Peak = decimal.Max(event.Peak, spread.Net)
+				case open:
+					event.ArbitrageSpread, event.Open, event.Time = spread, false, now
+					delete(a.open, key)
+					events = append(events, *event)
+				}
+			}
+		}
+	}
+	for key, event := range a.open {
+		if !seen[key] {
+			event.Open, event.Time = false, now
+			delete(a.open, key)
+			events = append(events, *event)
+		}
+	}
+
+	sort.SliceStable(spreads, func(i, j int) bool { return spreads[i].Net.GreaterThan(spreads[j].Net) })
+	a.spreads = spreads
+	handlers := a.handlers
+	a.mu.Unlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
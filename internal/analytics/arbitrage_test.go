@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+func liveBook(t *testing.T, bids, asks []exchange.PriceLevel) *orderbook.OrderBook {
+	t.Helper()
+	ob := orderbook.New()
+	if err := ob.LoadSnapshot(&exchange.Snapshot{Bids: bids, Asks: asks}); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	return ob
+}
+
+func TestArbitrage(t *testing.T) {
+	a := NewArbitrage(ArbitrageConfig{
+		Sizes:     []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(3)},
+		Fees:      Fees{Venues: map[string]FeeSchedule{"okx": {Taker: decimal.NewFromFloat(0.001)}}},
+		Threshold: decimal.NewFromFloat(0.001),
+	}, "BTCUSDT")
+	var events []ArbitrageEvent
+	a.OnEvent(func(e ArbitrageEvent) { events = append(events, e) })
+
+	// Buying 1 on okx at 100 and selling on bybit at 100.5 nets 40bp after okx's 10bp fee;
+	// 3 walks both books to a loss
+	a.RegisterOrderbook("okx", liveBook(t,
+		[]exchange.PriceLevel{{Price: "99.5", Quantity: "5"}},
+		[]exchange.PriceLevel{{Price: "100", Quantity: "1"}, {Price: "101", Quantity: "5"}}), exchange.Capabilities{})
+	a.RegisterOrderbook("bybit", liveBook(t,
+		[]exchange.PriceLevel{{Price: "100.5", Quantity: "1"}, {Price: "99", Quantity: "5"}},
+		[]exchange.PriceLevel{{Price: "101", Quantity: "5"}}), exchange.Capabilities{})
+	a.RegisterOrderbook("gate", orderbook.New(), exchange.Capabilities{})
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a.evaluate(start)
+	spreads := a.Spreads()
+	if len(spreads) != 4 {
+		t.Fatalf("got %d spreads, want 2 pairs × 2 sizes", len(spreads))
+	}
+	best := spreads[0]
+	if best.Buy != "okx" || best.Sell != "bybit" || !best.Size.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("unexpected best spread %+v", best)
+	}
+	if !best.Gross.Equal(decimal.RequireFromString("0.005")) || !best.Net.Equal(decimal.RequireFromString("0.004")) || !best.Profit.Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("unexpected gross %s, net %s, profit %s", best.Gross, best.Net, best.Profit)
+	}
+	if len(events) != 1 || !events[0].Open || events[0].Buy != "okx" || events[0].Symbol != "BTCUSDT" {
+		t.Fatalf("expected one opening event, got %+v", events)
+	}
+
+	// Still open: no new event
+	a.evaluate(start.Add(time.Second))
+	if len(events) != 1 {
+		t.Fatalf("expected no event while the opportunity stays open, got %d", len(events))
+	}
+
+	// The opportunity closes once a book goes away
+	a.UnregisterOrderbook("bybit")
+	a.evaluate(start.Add(2 * time.Second))
+	if len(events) != 2 || events[1].Open || !events[1].Since.Equal(start) || !events[1].Peak.Equal(best.Net) {
+		t.Fatalf("expected a closing event, got %+v", events)
+	}
+	if len(a.Spreads()) != 0 {
+		t.Errorf("expected no spreads with a single book, got %+v", a.Spreads())
+	}
+}
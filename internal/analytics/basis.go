@@ -0,0 +1,176 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// BasisPair pairs the spot and perpetual books of a venue
+type BasisPair struct {
+	Spot string // Registered name of the spot exchange, e.g. binance
+	Perp string // Registered name of the perpetual exchange, e.g. binancef
+}
+
+// DefaultBasisPairs are the venues connected with both a spot and a perpetual book
+var DefaultBasisPairs = []BasisPair{
+	{Spot: string(exchange.Binance), Perp: string(exchange.Binancef)},
+	{Spot: string(exchange.Bybit), Perp: string(exchange.Bybitf)},
+	{Spot: string(exchange.Gate), Perp: string(exchange.Gatef)},
+	{Spot: string(exchange.Bitget), Perp: string(exchange.Bitgetf)},
+	{Spot: string(exchange.BingX), Perp: string(exchange.BingXf)},
+	{Spot: string(exchange.Phemex), Perp: string(exchange.Phemexf)},
+	{Spot: string(exchange.Hyperliquid), Perp: string(exchange.Hyperliquidf)},
+}
+
+// ParseBasisPairs parses comma-separated spot=perp pairs, e.g. binance=binancef,bybit=bybitf
+func ParseBasisPairs(list string) ([]BasisPair, error) {
+	var pairs []BasisPair
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		spot, perp, ok := strings.Cut(item, "=")
+		spot, perp = strings.ToLower(strings.TrimSpace(spot)), strings.ToLower(strings.TrimSpace(perp))
+		if !ok || spot == "" || perp == "" || spot == perp {
+			return nil, fmt.Errorf("invalid basis pair %q: want spot=perp", item)
+		}
+		pairs = append(pairs, BasisPair{Spot: spot, Perp: perp})
+	}
+	return pairs, nil
+}
+
+// BasisConfig configures spot-perp basis tracking
+type BasisConfig struct {
+	Pairs []BasisPair // Default DefaultBasisPairs
+}
+
+// BasisStats is the live basis between the spot and perpetual books of a venue
+type BasisStats struct {
+	BasisPair
+	SpotMid           decimal.Decimal
+	PerpMid           decimal.Decimal
+	Basis             decimal.Decimal // PerpMid / SpotMid - 1
+	FundingRate       decimal.Decimal // Of the perpetual's current interval, zero without funding
+	AnnualizedFunding decimal.Decimal // FundingRate over a year of funding intervals
+	AnnualizedBasis   decimal.Decimal // Basis earned every funding interval, over a year; zero without funding
+	Funded            bool            // Whether the perpetual had funding to annualize with
+}
+
+// BasisBps returns the basis in basis points
+func (s BasisStats) BasisBps() decimal.Decimal {
+	return s.Basis.Mul(decimal.NewFromInt(10000))
+}
+
+// Basis computes the basis between the spot and perpetual books of each configured venue
+// from their mids, annualized with the funding interval of the perpetual. Books register
+// and unregister like they do with a collector
+type Basis struct {
+	pairs   []BasisPair
+	funding *Funding // Nil leaves the basis unannualized
+	mu      sync.Mutex
+	books   map[string]*orderbook.OrderBook
+}
+
+// NewBasis creates a basis tracker; funding may be nil
+func NewBasis(config BasisConfig, funding *Funding) *Basis {
+	if len(config.Pairs) == 0 {
+		config.Pairs = DefaultBasisPairs
+	}
+	return &Basis{
+		pairs:   config.Pairs,
+		funding: funding,
+		books:   make(map[string]*orderbook.OrderBook),
+	}
+}
+
+// RegisterOrderbook adds the book of an exchange; only books of a configured pair are kept
+func (b *Basis) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	for _, pair := range b.pairs {
+		if name == pair.Spot || name == pair.Perp {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.books[name] = ob
+			return
+		}
+	}
+}
+
+// UnregisterOrderbook removes the book of an exchange
+func (b *Basis) UnregisterOrderbook(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.books, name)
+}
+
+// Stats returns the basis of the pair whose perpetual is registered as perp; ok is false
+// unless both books are initialized and not crossed
+func (b *Basis) Stats(perp string) (BasisStats, bool) {
+	for _, pair := range b.pairs {
+		if pair.Perp == perp {
+			return b.stats(pair)
+		}
+	}
+	return BasisStats{}, false
+}
+
+// All returns the basis of every pair with both books live, by perpetual
+func (b *Basis) All() map[string]BasisStats {
+	all := make(map[string]BasisStats)
+	for _, pair := range b.pairs {
+		if stats, ok := b.stats(pair); ok {
+			all[pair.Perp] = stats
+		}
+	}
+	return all
+}
+
+func (b *Basis) stats(pair BasisPair) (BasisStats, bool) {
+	b.mu.Lock()
+	spot, perp := b.books[pair.Spot], b.books[pair.Perp]
+	b.mu.Unlock()
+	if spot == nil || perp == nil {
+		return BasisStats{}, false
+	}
+
+	spotMid, ok := bookMid(spot)
+	if !ok {
+		return BasisStats{}, false
+	}
+	perpMid, ok := bookMid(perp)
+	if !ok {
+		return BasisStats{}, false
+	}
+
+	stats := BasisStats{BasisPair: pair, SpotMid: spotMid, PerpMid: perpMid}
+	stats.Basis = perpMid.Div(spotMid).Sub(decimal.NewFromInt(1))
+	if b.funding != nil {
+		if funding, ok := b.funding.Stats(pair.Perp); ok {
+			periods := periodsPerYear(funding.Interval)
+			stats.FundingRate = funding.Rate
+			stats.AnnualizedFunding = funding.Rate.Mul(periods)
+			stats.AnnualizedBasis = stats.Basis.Mul(periods)
+			stats.Funded = true
+		}
+	}
+	return stats, true
+}
+
+// bookMid returns the mid of an initialized book with both sides that is not crossed
+func bookMid(ob *orderbook.OrderBook) (decimal.Decimal, bool) {
+	if !ob.IsInitialized() {
+		return decimal.Zero, false
+	}
+	bid, hasBid := ob.BestBid()
+	ask, hasAsk := ob.BestAsk()
+	if !hasBid || !hasAsk || bid.Price.GreaterThan(ask.Price) {
+		return decimal.Zero, false
+	}
+	return bid.Price.Add(ask.Price).Div(decimal.NewFromInt(2)), true
+}
@@ -0,0 +1,60 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestBasis(t *testing.T) {
+	funding := NewFunding(FundingConfig{})
+	b := NewBasis(BasisConfig{}, funding)
+	spot := liveBook(t, []exchange.PriceLevel{{Price: "99", Quantity: "1"}}, []exchange.PriceLevel{{Price: "101", Quantity: "1"}})
+	perp := liveBook(t, []exchange.PriceLevel{{Price: "100", Quantity: "1"}}, []exchange.PriceLevel{{Price: "102", Quantity: "1"}})
+	b.RegisterOrderbook("binance", spot, exchange.Capabilities{})
+	b.RegisterOrderbook("binancef", perp, exchange.Capabilities{})
+	b.RegisterOrderbook("okx", spot, exchange.Capabilities{})
+
+	stats, ok := b.Stats("binancef")
+	if !ok {
+		t.Fatal("expected the basis of binancef")
+	}
+	if stats.Spot != "binance" || !stats.BasisBps().Equal(decimal.NewFromInt(100)) || stats.Funded {
+		t.Errorf("unexpected basis %+v", stats)
+	}
+
+	// Funding annualizes the basis over its 8h intervals
+	funding.Record("binancef", &exchange.Funding{Exchange: exchange.Binancef, Rate: "0.0001", NextFunding: time.Now().Add(time.Hour)})
+	stats, _ = b.Stats("binancef")
+	if !stats.Funded || !stats.AnnualizedBasis.Equal(decimal.RequireFromString("10.95")) || !stats.AnnualizedFunding.Equal(decimal.RequireFromString("0.1095")) {
+		t.Errorf("unexpected annualized basis %+v", stats)
+	}
+
+	if len(b.All()) != 1 {
+		t.Errorf("expected one pair, got %v", b.All())
+	}
+	b.RegisterOrderbook("bybitf", orderbook.New(), exchange.Capabilities{})
+	if _, ok := b.Stats("bybitf"); ok {
+		t.Error("expected no basis without a spot book")
+	}
+	b.UnregisterOrderbook("binance")
+	if _, ok := b.Stats("binancef"); ok {
+		t.Error("expected no basis after unregistering the spot book")
+	}
+}
+
+func TestParseBasisPairs(t *testing.T) {
+	pairs, err := ParseBasisPairs("Binance=binancef, okx=okxswap")
+	if err != nil || len(pairs) != 2 || pairs[0] != (BasisPair{Spot: "binance", Perp: "binancef"}) {
+		t.Errorf("ParseBasisPairs() = %v, %v", pairs, err)
+	}
+	for _, list := range []string{"binance", "binance=", "okx=okx"} {
+		if _, err := ParseBasisPairs(list); err == nil {
+			t.Errorf("ParseBasisPairs(%q) expected an error", list)
+		}
+	}
+}
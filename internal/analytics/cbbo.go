@@ -0,0 +1,86 @@
+// Package analytics derives measures that span the books of several exchanges
+package analytics
+
+import (
+	"sort"
+
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// ConsolidatedExchange is the exchange name consolidated rows are stored under
+const ConsolidatedExchange = "CONSOLIDATED"
+
+// CBBO is the consolidated best bid and offer: the highest bid and lowest ask across venues
+type CBBO struct {
+	BestBid     decimal.Decimal
+	BestAsk     decimal.Decimal
+	BidQuantity decimal.Decimal // Resting at BestBid, summed over BidVenues
+	AskQuantity decimal.Decimal // Resting at BestAsk, summed over AskVenues
+	BidVenues   []string        // Exchanges quoting BestBid, sorted
+	AskVenues   []string        // Exchanges quoting BestAsk, sorted
+	Spread      decimal.Decimal // BestAsk - BestBid; zero or negative when one venue bids at or above another's ask
+	Venues      int             // Books that contributed
+}
+
+// ComputeCBBO consolidates the top of the books by exchange name. Books that are not
+// initialized, miss a side or are crossed themselves are left out; ok is false when none is left
+func ComputeCBBO(books map[string]*orderbook.BookSnapshot) (cbbo CBBO, ok bool) {
+	names := make([]string, 0, len(books))
+	for name := range books {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		book := books[name]
+		if !book.Initialized || len(book.Bids) == 0 || len(book.Asks) == 0 {
+			continue
+		}
+		bid, ask := book.Bids[0], book.Asks[0]
+		if !ask.Price.GreaterThan(bid.Price) {
+			continue
+		}
+
+		switch {
+		case cbbo.Venues == 0 || bid.Price.GreaterThan(cbbo.BestBid):
+			cbbo.BestBid, cbbo.BidQuantity, cbbo.BidVenues = bid.Price, bid.Quantity, []string{name}
+		case bid.Price.Equal(cbbo.BestBid):
+			cbbo.BidQuantity = cbbo.BidQuantity.Add(bid.Quantity)
+			cbbo.BidVenues = append(cbbo.BidVenues, name)
+		}
+		switch {
+		case cbbo.Venues == 0 || ask.Price.LessThan(cbbo.BestAsk):
+			cbbo.BestAsk, cbbo.AskQuantity, cbbo.AskVenues = ask.Price, ask.Quantity, []string{name}
+		case ask.Price.Equal(cbbo.BestAsk):
+			cbbo.AskQuantity = cbbo.AskQuantity.Add(ask.Quantity)
+			cbbo.AskVenues = append(cbbo.AskVenues, name)
+		}
+		cbbo.Venues++
+	}
+	if cbbo.Venues == 0 {
+		return CBBO{}, false
+	}
+	cbbo.Spread = cbbo.BestAsk.Sub(cbbo.BestBid)
+	return cbbo, true
+}
+
+// Mid returns the midpoint of the consolidated bid and ask
+func (c CBBO) Mid() decimal.Decimal {
+	return c.BestBid.Add(c.BestAsk).Div(decimal.NewFromInt(2))
+}
+
+// Crossed reports whether a venue bids at or above the ask of another
+func (c CBBO) Crossed() bool {
+	return !c.Spread.IsPositive()
+}
+
+// SpreadBps returns the spread in basis points of mid
+func (c CBBO) SpreadBps() decimal.Decimal {
+	mid := c.Mid()
+	if mid.IsZero() {
+		return decimal.Zero
+	}
+	return c.Spread.Div(mid).Mul(decimal.NewFromInt(10000))
+}
@@ -0,0 +1,57 @@
+package analytics
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+func top(bid, bidQty, ask, askQty string) *orderbook.BookSnapshot {
+	return &orderbook.BookSnapshot{
+		Initialized: true,
+		Bids:        []types.PriceLevel{{Price: decimal.RequireFromString(bid), Quantity: decimal.RequireFromString(bidQty)}},
+		Asks:        []types.PriceLevel{{Price: decimal.RequireFromString(ask), Quantity: decimal.RequireFromString(askQty)}},
+	}
+}
+
+func TestComputeCBBO(t *testing.T) {
+	cbbo, ok := ComputeCBBO(map[string]*orderbook.BookSnapshot{
+		"okx":     top("100", "1", "100.5", "2"),
+		"bybit":   top("100", "3", "100.2", "1"),
+		"binance": top("99.9", "5", "100.2", "4"),
+		"kraken":  top("101", "1", "100", "1"), // Crossed itself
+		"gate":    {},                          // Not initialized
+	})
+	if !ok {
+		t.Fatal("expected a CBBO")
+	}
+	if !cbbo.BestBid.Equal(decimal.NewFromInt(100)) || !cbbo.BidQuantity.Equal(decimal.NewFromInt(4)) || !slices.Equal(cbbo.BidVenues, []string{"bybit", "okx"}) {
+		t.Errorf("unexpected bid %s x %s at %v", cbbo.BestBid, cbbo.BidQuantity, cbbo.BidVenues)
+	}
+	if !cbbo.BestAsk.Equal(decimal.RequireFromString("100.2")) || !cbbo.AskQuantity.Equal(decimal.NewFromInt(5)) || !slices.Equal(cbbo.AskVenues, []string{"binance", "bybit"}) {
+		t.Errorf("unexpected ask %s x %s at %v", cbbo.BestAsk, cbbo.AskQuantity, cbbo.AskVenues)
+	}
+	if cbbo.Venues != 3 || !cbbo.Spread.Equal(decimal.RequireFromString("0.2")) || cbbo.Crossed() {
+		t.Errorf("unexpected CBBO %+v", cbbo)
+	}
+	if bps := cbbo.SpreadBps().Round(2); !bps.Equal(decimal.RequireFromString("19.98")) {
+		t.Errorf("SpreadBps() = %s", bps)
+	}
+
+	// A venue bidding above another's ask crosses the consolidated book
+	cbbo, _ = ComputeCBBO(map[string]*orderbook.BookSnapshot{
+		"okx":   top("100.3", "1", "100.4", "1"),
+		"bybit": top("100", "1", "100.2", "1"),
+	})
+	if !cbbo.Crossed() || !cbbo.Spread.Equal(decimal.RequireFromString("-0.1")) {
+		t.Errorf("expected a crossed CBBO, got spread %s", cbbo.Spread)
+	}
+
+	if _, ok := ComputeCBBO(map[string]*orderbook.BookSnapshot{"gate": {}}); ok {
+		t.Error("expected no CBBO without live books")
+	}
+}
@@ -0,0 +1,221 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// DivergenceConfig configures a divergence detector
+type DivergenceConfig struct {
+	Threshold decimal.Decimal // Deviation of a mid from the median mid of the books that counts as divergent, as a fraction; default 0.001 (10bp)
+	Duration  time.Duration   // How long a book must stay divergent before a divergence opens, default 10s
+	MinVenues int             // Books with a mid the median needs, default 3
+	Interval  time.Duration   // Time between evaluations, default 1s
+}
+
+// DivergenceEvent reports the mid of a book opening a divergence from the median mid of
+// the books, or closing once it is back within the threshold or the book is gone
+type DivergenceEvent struct {
+	Exchange  string
+	Symbol    string
+	Open      bool
+	Mid       decimal.Decimal // As of the event; the last seen when the book is gone
+	Median    decimal.Decimal
+	Deviation decimal.Decimal // Mid / Median - 1
+	Peak      decimal.Decimal // Largest deviation from the median while divergent, either way
+	Stale     bool            // The book had not changed for Duration when the divergence opened, pointing at a stalled feed rather than a dislocation
+	Since     time.Time       // When the mid first went beyond the threshold
+	Time      time.Time       // When the event happened
+}
+
+// DeviationBps returns the deviation in basis points
+func (e DivergenceEvent) DeviationBps() decimal.Decimal {
+	return e.Deviation.Mul(decimal.NewFromInt(10000))
+}
+
+// Divergence compares the mid of every registered book to the median mid of the books and
+// reports the books that stay too far from it to its handlers. Books register and
+// unregister like they do with a collector
+type Divergence struct {
+	config   DivergenceConfig
+	mu       sync.Mutex
+	symbol   string
+	books    map[string]*orderbook.OrderBook
+	beyond   map[string]*DivergenceEvent // Books beyond the threshold, opened or not yet
+	handlers []func(DivergenceEvent)
+}
+
+// NewDivergence creates a detector for the books of symbol; call Start to evaluate them
+func NewDivergence(config DivergenceConfig, symbol string) *Divergence {
+	if !config.Threshold.IsPositive() {
+		config.Threshold = decimal.NewFromFloat(0.001)
+	}
+	if config.Duration <= 0 {
+		config.Duration = 10 * time.Second
+	}
+	if config.MinVenues <= 0 {
+		config.MinVenues = 3
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	return &Divergence{
+		config: config,
+		symbol: symbol,
+		books:  make(map[string]*orderbook.OrderBook),
+		beyond: make(map[string]*DivergenceEvent),
+	}
+}
+
+// OnEvent adds a handler called with every divergence opening or closing, outside the
+// detector's lock
+func (d *Divergence) OnEvent(handler func(DivergenceEvent)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, handler)
+}
+
+// RegisterOrderbook adds the book of an exchange to the books compared
+func (d *Divergence) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.books[name] = ob
+}
+
+// UnregisterOrderbook removes the book of an exchange; its divergence closes on the next
+// evaluation
+func (d *Divergence) UnregisterOrderbook(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.books, name)
+}
+
+// SetSymbol changes the symbol reported with events, for books registered after a symbol
+// switch; divergences of the previous symbol are dropped without an event
+func (d *Divergence) SetSymbol(symbol string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.symbol = symbol
+	clear(d.beyond)
+}
+
+// Divergent returns the open divergences, sorted by exchange
+func (d *Divergence) Divergent() []DivergenceEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var open []DivergenceEvent
+	for _, event := range d.beyond {
+		if event.Open {
+			open = append(open, *event)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].Exchange < open[j].Exchange })
+	return open
+}
+
+// Start evaluates the books every interval until ctx is done
+func (d *Divergence) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			d.evaluate(now)
+		}
+	}
+}
+
+// evaluate compares the mid of every initialized, uncrossed book to their median and
+// opens and closes divergences; with fewer books than MinVenues every divergence closes
+func (d *Divergence) evaluate(now time.Time) {
+	d.mu.Lock()
+	mids := make(map[string]decimal.Decimal, len(d.books))
+	updated := make(map[string]time.Time, len(d.books))
+	for name, ob := range d.books {
+		book := ob.Snapshot()
+		if !book.Initialized || len(book.Bids) == 0 || len(book.Asks) == 0 || !book.Asks[0].Price.GreaterThan(book.Bids[0].Price) {
+			continue
+		}
+		mids[name] = book.MidPrice()
+		updated[name] = book.Stats.LastUpdateTime
+	}
+
+	var events []DivergenceEvent
+	median, ok := medianMid(mids, d.config.MinVenues)
+	if !ok {
+		mids = nil // Too few books for a median, every divergence closes below
+	}
+	for name, mid := range mids {
+		deviation := mid.Div(median).Sub(decimal.NewFromInt(1))
+		event, tracked := d.beyond[name]
+		if deviation.Abs().LessThanOrEqual(d.config.Threshold) {
+			if tracked {
+				delete(d.beyond, name)
+				if event.Open {
+					event.Mid, event.Median, event.Deviation, event.Open, event.Time = mid, median, deviation, false, now
+					events = append(events, *event)
+				}
+			}
+			continue
+		}
+
+		if !tracked {
+			event = &DivergenceEvent{Exchange: name, Symbol: d.symbol, Since: now, Peak: decimal.Zero}
+			d.beyond[name] = event
+		}
+		event.Mid, event.Median, event.Deviation = mid, median, deviation
+		if deviation.Abs().GreaterThan(event.Peak.Abs()) {
+			event.Peak = deviation
+		}
+		if !event.Open && now.Sub(event.Since) >= d.config.Duration {
+			event.Open, event.Time = true, now
+			event.Stale = now.Sub(updated[name]) >= d.config.Duration
+			events = append(events, *event)
+		}
+	}
+	for name, event := range d.beyond {
+		if _, live := mids[name]; live {
+			continue
+		}
+		delete(d.beyond, name)
+		if event.Open {
+			event.Open, event.Time = false, now
+			events = append(events, *event)
+		}
+	}
+	handlers := d.handlers
+	d.mu.Unlock()
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Exchange < events[j].Exchange })
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+// medianMid returns the median of the mids; ok is false with fewer than least of them
+func medianMid(mids map[string]decimal.Decimal, least int) (median decimal.Decimal, ok bool) {
+	if len(mids) == 0 || len(mids) < least {
+		return decimal.Zero, false
+	}
+	sorted := make([]decimal.Decimal, 0, len(mids))
+	for _, mid := range mids {
+		sorted = append(sorted, mid)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle], true
+	}
+	return sorted[middle-1].Add(sorted[middle]).Div(decimal.NewFromInt(2)), true
+}
@@ -0,0 +1,71 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDivergence(t *testing.T) {
+	d := NewDivergence(DivergenceConfig{Threshold: decimal.NewFromFloat(0.001), Duration: 10 * time.Second}, "BTCUSDT")
+	var events []DivergenceEvent
+	d.OnEvent(func(e DivergenceEvent) { events = append(events, e) })
+
+	quote := func(bid, ask string) *exchange.Snapshot {
+		return &exchange.Snapshot{Bids: []exchange.PriceLevel{{Price: bid, Quantity: "1"}}, Asks: []exchange.PriceLevel{{Price: ask, Quantity: "1"}}}
+	}
+	book := func(bid, ask string) *orderbook.OrderBook {
+		snapshot := quote(bid, ask)
+		return liveBook(t, snapshot.Bids, snapshot.Asks)
+	}
+	d.RegisterOrderbook("binance", book("99", "101"), exchange.Capabilities{})
+	d.RegisterOrderbook("bybit", book("99.5", "100.5"), exchange.Capabilities{})
+	okx := book("100", "102")
+	d.RegisterOrderbook("okx", okx, exchange.Capabilities{})
+
+	// Before the books last changed, so a divergence is a dislocation rather than a stale feed
+	start := time.Now().Add(-time.Minute)
+	d.evaluate(start)
+	d.evaluate(start.Add(5 * time.Second))
+	if len(events) != 0 {
+		t.Fatalf("expected no event before the duration, got %+v", events)
+	}
+	d.evaluate(start.Add(10 * time.Second))
+	if len(events) != 1 || !events[0].Open || events[0].Exchange != "okx" || events[0].Stale || !events[0].DeviationBps().Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("expected okx to open a divergence of 100bp, got %+v", events)
+	}
+	if open := d.Divergent(); len(open) != 1 || !open[0].Since.Equal(start) {
+		t.Errorf("expected the open divergence of okx, got %+v", open)
+	}
+
+	// Back within the threshold
+	if err := okx.LoadSnapshot(quote("99", "101")); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	okx.ProcessBufferedEvents()
+	d.evaluate(start.Add(11 * time.Second))
+	if len(events) != 2 || events[1].Open || !events[1].Peak.Equal(decimal.NewFromFloat(0.01)) {
+		t.Fatalf("expected okx to close its divergence, got %+v", events)
+	}
+
+	// A book left behind long enough is flagged stale
+	d.RegisterOrderbook("kraken", book("101", "103"), exchange.Capabilities{})
+	later := time.Now().Add(time.Minute)
+	d.evaluate(later)
+	d.evaluate(later.Add(10 * time.Second))
+	if len(events) != 3 || !events[2].Stale || events[2].Exchange != "kraken" {
+		t.Fatalf("expected a stale divergence of kraken, got %+v", events)
+	}
+
+	// Too few books for a median closes it
+	d.UnregisterOrderbook("binance")
+	d.UnregisterOrderbook("bybit")
+	d.evaluate(later.Add(11 * time.Second))
+	if len(events) != 4 || events[3].Open || len(d.Divergent()) != 0 {
+		t.Errorf("expected the divergence to close without a median, got %+v", events)
+	}
+}
@@ -0,0 +1,53 @@
+package analytics
+
+import (
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// FeeSchedule is the trading fee of a venue, as fractions of notional (0.001 = 10bp)
+type FeeSchedule struct {
+	Maker decimal.Decimal // Negative for a rebate
+	Taker decimal.Decimal
+}
+
+// Fees holds the fee schedule of every venue
+type Fees struct {
+	Venues  map[string]FeeSchedule // By exchange
+	Default FeeSchedule            // Of exchanges missing from Venues
+}
+
+// For returns the fee schedule of an exchange
+func (f Fees) For(name string) FeeSchedule {
+	if schedule, ok := f.Venues[name]; ok {
+		return schedule
+	}
+	return f.Default
+}
+
+// EffectiveSpread is the spread of a book once the fees of its venue are paid, as fractions
+// of mid, so books of venues on very different fee tiers compare like for like
+type EffectiveSpread struct {
+	Fees  FeeSchedule
+	Raw   decimal.Decimal // (ask - bid) / mid
+	Taker decimal.Decimal // Cost of buying at the ask and selling at the bid as taker, fees included
+	Maker decimal.Decimal // Left from a fill at both the bid and the ask as maker, after fees; negative when fees exceed the spread
+}
+
+// ComputeEffectiveSpread returns the fee-adjusted spread of the best bid and ask of a book;
+// ok is false unless the book has both sides and is not crossed
+func ComputeEffectiveSpread(book *orderbook.BookSnapshot, fees FeeSchedule) (EffectiveSpread, bool) {
+	if !book.Initialized || len(book.Bids) == 0 || len(book.Asks) == 0 || !book.Asks[0].Price.GreaterThan(book.Bids[0].Price) {
+		return EffectiveSpread{}, false
+	}
+	bid, ask := book.Bids[0].Price, book.Asks[0].Price
+	mid := book.MidPrice()
+	one := decimal.NewFromInt(1)
+	return EffectiveSpread{
+		Fees:  fees,
+		Raw:   ask.Sub(bid).Div(mid),
+		Taker: ask.Mul(one.Add(fees.Taker)).Sub(bid.Mul(one.Sub(fees.Taker))).Div(mid),
+		Maker: ask.Mul(one.Sub(fees.Maker)).Sub(bid.Mul(one.Add(fees.Maker))).Div(mid),
+	}, true
+}
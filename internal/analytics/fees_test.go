@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestEffectiveSpread(t *testing.T) {
+	fees := Fees{
+		Venues:  map[string]FeeSchedule{"binance": {Maker: decimal.NewFromFloat(-0.0001), Taker: decimal.NewFromFloat(0.0004)}},
+		Default: FeeSchedule{Maker: decimal.NewFromFloat(0.0002), Taker: decimal.NewFromFloat(0.001)},
+	}
+	if got := fees.For("okx"); !got.Taker.Equal(decimal.NewFromFloat(0.001)) {
+		t.Errorf("expected the default schedule for okx, got %+v", got)
+	}
+
+	// A 2bp spread at mid 100
+	book := liveBook(t, []exchange.PriceLevel{{Price: "99.99", Quantity: "1"}}, []exchange.PriceLevel{{Price: "100.01", Quantity: "1"}}).Snapshot()
+	spread, ok := ComputeEffectiveSpread(book, fees.For("binance"))
+	if !ok {
+		t.Fatal("expected an effective spread of a live book")
+	}
+	if !spread.Raw.Equal(decimal.RequireFromString("0.0002")) {
+		t.Errorf("expected a raw spread of 2bp, got %s", spread.Raw)
+	}
+	// Crossing pays the 2bp spread and 4bp on each leg
+	if !spread.Taker.Equal(decimal.RequireFromString("0.001")) {
+		t.Errorf("expected a taker spread of 10bp, got %s", spread.Taker)
+	}
+	// Quoting both sides earns the spread and a 1bp rebate on each leg
+	if !spread.Maker.Equal(decimal.RequireFromString("0.0004")) {
+		t.Errorf("expected a maker capture of 4bp, got %s", spread.Maker)
+	}
+
+	// The same book at the default maker fee loses money quoting
+	if spread, _ = ComputeEffectiveSpread(book, fees.For("okx")); !spread.Maker.Equal(decimal.RequireFromString("-0.0002")) {
+		t.Errorf("expected a maker capture of -2bp, got %s", spread.Maker)
+	}
+
+	crossed := liveBook(t, []exchange.PriceLevel{{Price: "101", Quantity: "1"}}, []exchange.PriceLevel{{Price: "100", Quantity: "1"}}).Snapshot()
+	if _, ok := ComputeEffectiveSpread(crossed, fees.Default); ok {
+		t.Error("expected no effective spread of a crossed book")
+	}
+}
@@ -0,0 +1,243 @@
+package analytics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+// FlowConfig configures taker flow tracking
+type FlowConfig struct {
+	Window     time.Duration   // Trailing window of the taker volumes, default 5m
+	LargeTrade decimal.Decimal // Quote notional at or above which a trade is large; zero reports none
+}
+
+// FlowStats is the taker flow of an exchange, or of every exchange consolidated
+// Quantities are in book units, summed across venues like the CBBO sums them
+type FlowStats struct {
+	Window      time.Duration
+	CVD         decimal.Decimal // Taker buy minus taker sell quantity since tracking started
+	BuyVolume   decimal.Decimal // Taker buy quantity within the window
+	SellVolume  decimal.Decimal // Taker sell quantity within the window
+	Trades      int             // Trades within the window
+	LargeTrades int             // Large trades within the window
+	LastTrade   time.Time       // When the latest trade was recorded
+	Venues      int             // Exchanges that contributed
+}
+
+// Delta returns the taker buy minus sell quantity within the window
+func (s FlowStats) Delta() decimal.Decimal {
+	return s.BuyVolume.Sub(s.SellVolume)
+}
+
+// BuyRatio returns the share of the taker volume within the window bought, zero without volume
+func (s FlowStats) BuyRatio() decimal.Decimal {
+	total := s.BuyVolume.Add(s.SellVolume)
+	if total.IsZero() {
+		return decimal.Zero
+	}
+	return s.BuyVolume.Div(total)
+}
+
+// LargeTrade reports a trade whose notional reached FlowConfig.LargeTrade
+type LargeTrade struct {
+	Exchange string // Registered name of the exchange, which may differ from Trade.Exchange
+	Symbol   string
+	Trade    exchange.Trade
+	Notional decimal.Decimal
+}
+
+// flowTrade is a trade within the window of an exchange
+type flowTrade struct {
+	at       time.Time
+	quantity decimal.Decimal
+	buy      bool
+	large    bool
+}
+
+// venueFlow is the flow of one exchange
+type venueFlow struct {
+	cvd       decimal.Decimal
+	trades    []flowTrade // Oldest first
+	lastTrade time.Time
+}
+
+// Flow tracks the cumulative volume delta and taker volumes of the trades of every
+// exchange, and reports large trades to its handlers
+type Flow struct {
+	config   FlowConfig
+	mu       sync.Mutex
+	symbol   string
+	venues   map[string]*venueFlow
+	handlers []func(LargeTrade)
+}
+
+// NewFlow creates a tracker for the trades of symbol
+func NewFlow(config FlowConfig, symbol string) *Flow {
+	if config.Window <= 0 {
+		config.Window = 5 * time.Minute
+	}
+	return &Flow{
+		config: config,
+		symbol: symbol,
+		venues: make(map[string]*venueFlow),
+	}
+}
+
+// OnLargeTrade adds a handler called with every large trade, outside the tracker's lock
+func (f *Flow) OnLargeTrade(handler func(LargeTrade)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers = append(f.handlers, handler)
+}
+
+// Record adds a trade of the exchange registered as name; trades without a taker side or
+// with an unparsable price or quantity are ignored
+func (f *Flow) Record(name string, trade *exchange.Trade) {
+	f.record(name, trade, time.Now())
+}
+
+func (f *Flow) record(name string, trade *exchange.Trade, now time.Time) {
+	if trade.Side != exchange.TakerBuy && trade.Side != exchange.TakerSell {
+		return
+	}
+	price, err := decimal.NewFromString(trade.Price)
+	if err != nil {
+		return
+	}
+	quantity, err := decimal.NewFromString(trade.Quantity)
+	if err != nil {
+		return
+	}
+	notional := price.Mul(quantity)
+	large := f.config.LargeTrade.IsPositive() && notional.GreaterThanOrEqual(f.config.LargeTrade)
+	buy := trade.Side == exchange.TakerBuy
+
+	f.mu.Lock()
+	venue, ok := f.venues[name]
+	if !ok {
+		venue = &venueFlow{}
+		f.venues[name] = venue
+	}
+	if buy {
+		venue.cvd = venue.cvd.Add(quantity)
+	} else {
+		venue.cvd = venue.cvd.Sub(quantity)
+	}
+	venue.trades = append(venue.trades, flowTrade{at: now, quantity: quantity, buy: buy, large: large})
+	venue.lastTrade = now
+	f.prune(venue, now)
+	symbol := f.symbol
+	handlers := f.handlers
+	f.mu.Unlock()
+
+	if large {
+		event := LargeTrade{Exchange: name, Symbol: symbol, Trade: *trade, Notional: notional}
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+// prune drops the trades of an exchange that left the window; f.mu must be held
+func (f *Flow) prune(venue *venueFlow, now time.Time) {
+	cutoff := now.Add(-f.config.Window)
+	keep := 0
+	for keep < len(venue.trades) && venue.trades[keep].at.Before(cutoff) {
+		keep++
+	}
+	if keep > 0 {
+		venue.trades = append(venue.trades[:0], venue.trades[keep:]...)
+	}
+}
+
+// UnregisterExchange forgets the flow of an exchange whose connection ended
+func (f *Flow) UnregisterExchange(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.venues, name)
+}
+
+// SetSymbol changes the symbol reported with large trades and restarts tracking, for the
+// trades of books registered after a symbol switch
+func (f *Flow) SetSymbol(symbol string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.symbol = symbol
+	clear(f.venues)
+}
+
+// Stats returns the flow of an exchange; ok is false before its first trade
+func (f *Flow) Stats(name string) (stats FlowStats, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	venue, ok := f.venues[name]
+	if !ok {
+		return FlowStats{}, false
+	}
+	return f.stats(venue, time.Now()), true
+}
+
+// All returns the flow of every exchange with trades, by name
+func (f *Flow) All() map[string]FlowStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	all := make(map[string]FlowStats, len(f.venues))
+	for name, venue := range f.venues {
+		all[name] = f.stats(venue, now)
+	}
+	return all
+}
+
+// Consolidated returns the flow summed over every exchange; ok is false before the first trade
+func (f *Flow) Consolidated() (FlowStats, bool) {
+	all := f.All()
+	if len(all) == 0 {
+		return FlowStats{}, false
+	}
+	flows := make([]FlowStats, 0, len(all))
+	for _, stats := range all {
+		flows = append(flows, stats)
+	}
+	return MergeFlow(flows), true
+}
+
+// stats summarizes the window of an exchange; f.mu must be held
+func (f *Flow) stats(venue *venueFlow, now time.Time) FlowStats {
+	f.prune(venue, now)
+	stats := FlowStats{Window: f.config.Window, CVD: venue.cvd, LastTrade: venue.lastTrade, Venues: 1}
+	for _, trade := range venue.trades {
+		if trade.buy {
+			stats.BuyVolume = stats.BuyVolume.Add(trade.quantity)
+		} else {
+			stats.SellVolume = stats.SellVolume.Add(trade.quantity)
+		}
+		stats.Trades++
+		if trade.large {
+			stats.LargeTrades++
+		}
+	}
+	return stats
+}
+
+// MergeFlow sums the flow of several exchanges
+func MergeFlow(flows []FlowStats) FlowStats {
+	var merged FlowStats
+	for _, stats := range flows {
+		merged.Window = max(merged.Window, stats.Window)
+		merged.CVD = merged.CVD.Add(stats.CVD)
+		merged.BuyVolume = merged.BuyVolume.Add(stats.BuyVolume)
+		merged.SellVolume = merged.SellVolume.Add(stats.SellVolume)
+		merged.Trades += stats.Trades
+		merged.LargeTrades += stats.LargeTrades
+		if stats.LastTrade.After(merged.LastTrade) {
+			merged.LastTrade = stats.LastTrade
+		}
+		merged.Venues += stats.Venues
+	}
+	return merged
+}
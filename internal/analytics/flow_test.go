@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFlow(t *testing.T) {
+	f := NewFlow(FlowConfig{Window: time.Minute, LargeTrade: decimal.NewFromInt(1000)}, "BTCUSDT")
+	var large []LargeTrade
+	f.OnLargeTrade(func(e LargeTrade) { large = append(large, e) })
+
+	start := time.Now().Add(-90 * time.Second)
+	trade := func(side exchange.TradeSide, quantity string) *exchange.Trade {
+		return &exchange.Trade{Exchange: "okx", Price: "100", Quantity: quantity, Side: side}
+	}
+
+	// The first buy leaves the window but stays in the CVD
+	f.record("okx", trade(exchange.TakerBuy, "4"), start)
+	f.record("okx", trade(exchange.TakerSell, "1"), start.Add(60*time.Second))
+	f.record("okx", trade(exchange.TakerBuy, "12"), start.Add(80*time.Second))
+	f.record("okx", trade("", "100"), start.Add(80*time.Second))
+	f.record("bybit", trade(exchange.TakerSell, "2"), start.Add(85*time.Second))
+
+	okx, ok := f.Stats("okx")
+	if !ok {
+		t.Fatal("expected flow for okx")
+	}
+	if !okx.CVD.Equal(decimal.NewFromInt(15)) || !okx.BuyVolume.Equal(decimal.NewFromInt(12)) || !okx.SellVolume.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("unexpected okx flow: cvd %s, buys %s, sells %s", okx.CVD, okx.BuyVolume, okx.SellVolume)
+	}
+	if okx.Trades != 2 || okx.LargeTrades != 1 || !okx.BuyRatio().Equal(decimal.NewFromInt(12).Div(decimal.NewFromInt(13))) {
+		t.Errorf("unexpected okx window: %d trades, %d large, buy ratio %s", okx.Trades, okx.LargeTrades, okx.BuyRatio())
+	}
+
+	// 4 x 100 stays below the threshold, 12 x 100 reaches it
+	if len(large) != 1 || large[0].Exchange != "okx" || large[0].Symbol != "BTCUSDT" || !large[0].Notional.Equal(decimal.NewFromInt(1200)) {
+		t.Errorf("unexpected large trades %+v", large)
+	}
+
+	all, ok := f.Consolidated()
+	if !ok || all.Venues != 2 || !all.CVD.Equal(decimal.NewFromInt(13)) || !all.Delta().Equal(decimal.NewFromInt(9)) {
+		t.Errorf("unexpected consolidated flow %+v", all)
+	}
+
+	f.UnregisterExchange("bybit")
+	if _, ok := f.Stats("bybit"); ok {
+		t.Error("expected no flow for bybit after unregistering")
+	}
+	f.SetSymbol("ETHUSDT")
+	if _, ok := f.Consolidated(); ok {
+		t.Error("expected a symbol switch to restart tracking")
+	}
+}
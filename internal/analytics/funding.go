@@ -0,0 +1,193 @@
+package analytics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultFundingInterval is assumed between fundings until a venue's funding times show its own
+const defaultFundingInterval = 8 * time.Hour
+
+// year is the period rates are annualized over
+const year = 365 * 24 * time.Hour
+
+// FundingConfig configures funding and open interest tracking
+type FundingConfig struct {
+	Window time.Duration // Trailing window of the open interest change, default 1h
+}
+
+// FundingStats is the latest funding and open interest of a perpetual market
+// Prices and open interest are zero when the venue does not publish them
+type FundingStats struct {
+	Rate               decimal.Decimal // Funding rate of the current interval, as a fraction
+	NextFunding        time.Time       // Zero when the venue does not say
+	Interval           time.Duration   // Between fundings, as observed from the funding times; 8h until then
+	MarkPrice          decimal.Decimal
+	IndexPrice         decimal.Decimal
+	OpenInterest       decimal.Decimal // In book units
+	OpenInterestChange decimal.Decimal // Since the oldest open interest within the window
+	Window             time.Duration
+	Updated            time.Time // When the venue last updated any field
+}
+
+// OpenInterestNotional returns the open interest valued at the mark price
+func (s FundingStats) OpenInterestNotional() decimal.Decimal {
+	return s.OpenInterest.Mul(s.MarkPrice)
+}
+
+// AnnualizedRate returns the funding rate compounded simply over a year of intervals
+func (s FundingStats) AnnualizedRate() decimal.Decimal {
+	return s.Rate.Mul(periodsPerYear(s.Interval))
+}
+
+// periodsPerYear returns the number of intervals in a year
+func periodsPerYear(interval time.Duration) decimal.Decimal {
+	return decimal.NewFromInt(int64(year)).Div(decimal.NewFromInt(int64(interval)))
+}
+
+// Premium returns how far the mark price trades from the index, as a fraction; zero
+// without both prices
+func (s FundingStats) Premium() decimal.Decimal {
+	if !s.IndexPrice.IsPositive() || !s.MarkPrice.IsPositive() {
+		return decimal.Zero
+	}
+	return s.MarkPrice.Div(s.IndexPrice).Sub(decimal.NewFromInt(1))
+}
+
+// openInterestSample is an open interest within the window of an exchange
+type openInterestSample struct {
+	at    time.Time
+	value decimal.Decimal
+}
+
+// venueFunding is the funding of one exchange
+type venueFunding struct {
+	latest   FundingStats
+	interval time.Duration        // Observed between funding times, 0 until the next funding time moves
+	samples  []openInterestSample // Oldest first
+}
+
+// Funding tracks the funding, prices and open interest of the perpetual exchanges
+type Funding struct {
+	config FundingConfig
+	mu     sync.Mutex
+	venues map[string]*venueFunding
+}
+
+// NewFunding creates a funding tracker
+func NewFunding(config FundingConfig) *Funding {
+	if config.Window <= 0 {
+		config.Window = time.Hour
+	}
+	return &Funding{
+		config: config,
+		venues: make(map[string]*venueFunding),
+	}
+}
+
+// Record stores the funding of the exchange registered as name; fields that are empty or
+// do not parse read as zero
+func (f *Funding) Record(name string, funding *exchange.Funding) {
+	f.record(name, funding, time.Now())
+}
+
+func (f *Funding) record(name string, funding *exchange.Funding, now time.Time) {
+	parse := func(value string) decimal.Decimal {
+		parsed, _ := decimal.NewFromString(value)
+		return parsed
+	}
+	stats := FundingStats{
+		Rate:         parse(funding.Rate),
+		NextFunding:  funding.NextFunding,
+		MarkPrice:    parse(funding.MarkPrice),
+		IndexPrice:   parse(funding.IndexPrice),
+		OpenInterest: parse(funding.OpenInterest),
+		Window:       f.config.Window,
+		Updated:      funding.Timestamp,
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	venue, ok := f.venues[name]
+	if !ok {
+		venue = &venueFunding{}
+		f.venues[name] = venue
+	}
+	if previous := venue.latest.NextFunding; !previous.IsZero() && stats.NextFunding.Sub(previous) >= time.Minute {
+		venue.interval = stats.NextFunding.Sub(previous)
+	}
+	stats.Interval = venue.interval
+	if stats.Interval == 0 {
+		stats.Interval = defaultFundingInterval
+	}
+	venue.latest = stats
+	if funding.OpenInterest != "" {
+		venue.samples = append(venue.samples, openInterestSample{at: now, value: stats.OpenInterest})
+	}
+	f.prune(venue, now)
+}
+
+// prune drops the open interest samples of an exchange that left the window, keeping the
+// latest; f.mu must be held
+func (f *Funding) prune(venue *venueFunding, now time.Time) {
+	cutoff := now.Add(-f.config.Window)
+	keep := 0
+	for keep < len(venue.samples)-1 && venue.samples[keep].at.Before(cutoff) {
+		keep++
+	}
+	if keep > 0 {
+		venue.samples = append(venue.samples[:0], venue.samples[keep:]...)
+	}
+}
+
+// UnregisterExchange forgets the funding of an exchange whose connection ended
+func (f *Funding) UnregisterExchange(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.venues, name)
+}
+
+// Reset forgets every exchange, for a symbol switch
+func (f *Funding) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.venues)
+}
+
+// Stats returns the funding of an exchange; ok is false before its first update
+func (f *Funding) Stats(name string) (stats FundingStats, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	venue, ok := f.venues[name]
+	if !ok {
+		return FundingStats{}, false
+	}
+	f.prune(venue, time.Now())
+	stats = venue.latest
+	if len(venue.samples) > 0 {
+		stats.OpenInterestChange = venue.samples[len(venue.samples)-1].value.Sub(venue.samples[0].value)
+	}
+	return stats, true
+}
+
+// All returns the funding of every exchange with an update, by name
+func (f *Funding) All() map[string]FundingStats {
+	f.mu.Lock()
+	names := make([]string, 0, len(f.venues))
+	for name := range f.venues {
+		names = append(names, name)
+	}
+	f.mu.Unlock()
+
+	all := make(map[string]FundingStats, len(names))
+	for _, name := range names {
+		if stats, ok := f.Stats(name); ok {
+			all[name] = stats
+		}
+	}
+	return all
+}
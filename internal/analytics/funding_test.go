@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFunding(t *testing.T) {
+	f := NewFunding(FundingConfig{Window: time.Hour})
+	start := time.Now().Add(-90 * time.Minute)
+	update := func(oi string) *exchange.Funding {
+		return &exchange.Funding{Exchange: exchange.Bybitf, Rate: "0.0001", MarkPrice: "101", IndexPrice: "100", OpenInterest: oi}
+	}
+
+	// The first open interest leaves the window, so the change is measured from the second
+	f.record("bybitf", update("50"), start)
+	f.record("bybitf", update("80"), start.Add(40*time.Minute))
+	f.record("bybitf", update("95"), start.Add(80*time.Minute))
+
+	stats, ok := f.Stats("bybitf")
+	if !ok {
+		t.Fatal("expected funding for bybitf")
+	}
+	if !stats.Rate.Equal(decimal.RequireFromString("0.0001")) || !stats.OpenInterest.Equal(decimal.NewFromInt(95)) {
+		t.Errorf("unexpected funding %+v", stats)
+	}
+	if !stats.OpenInterestChange.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("open interest change %s, want 15", stats.OpenInterestChange)
+	}
+	if !stats.OpenInterestNotional().Equal(decimal.NewFromInt(9595)) || !stats.Premium().Equal(decimal.RequireFromString("0.01")) {
+		t.Errorf("unexpected notional %s or premium %s", stats.OpenInterestNotional(), stats.Premium())
+	}
+
+	// Venues polling open interest send funding without it until the first poll
+	f.record("binancef", &exchange.Funding{Exchange: exchange.Binancef, Rate: "-0.0002", MarkPrice: "101"}, start.Add(85*time.Minute))
+	if stats, _ := f.Stats("binancef"); !stats.OpenInterestChange.IsZero() || !stats.Premium().IsZero() {
+		t.Errorf("unexpected funding before open interest %+v", stats)
+	}
+
+	// The interval is observed once the funding time moves, and annualizes the rate
+	if stats.Interval != 8*time.Hour || !stats.AnnualizedRate().Equal(decimal.RequireFromString("0.1095")) {
+		t.Errorf("unexpected default interval %v or annualized rate %s", stats.Interval, stats.AnnualizedRate())
+	}
+	next := start.Truncate(time.Hour).Add(3 * time.Hour)
+	for i := range 2 {
+		hourly := update("95")
+		hourly.NextFunding = next.Add(time.Duration(i) * time.Hour)
+		f.record("hyperliquidf", hourly, start.Add(85*time.Minute))
+	}
+	if stats, _ := f.Stats("hyperliquidf"); stats.Interval != time.Hour || !stats.AnnualizedRate().Equal(decimal.RequireFromString("0.876")) {
+		t.Errorf("unexpected hourly interval %v or annualized rate %s", stats.Interval, stats.AnnualizedRate())
+	}
+
+	if len(f.All()) != 3 {
+		t.Errorf("expected three exchanges, got %v", f.All())
+	}
+	f.UnregisterExchange("bybitf")
+	if _, ok := f.Stats("bybitf"); ok {
+		t.Error("expected no funding for bybitf after unregistering")
+	}
+}
@@ -0,0 +1,216 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// IndexWeighting selects how the mids of the venues are weighted into an index price
+type IndexWeighting string
+
+const (
+	WeightEqual     IndexWeighting = "equal"     // Every venue counts the same
+	WeightLiquidity IndexWeighting = "liquidity" // By the bid and ask notional within the band around mid
+	WeightVolume    IndexWeighting = "volume"    // By the taker volume within the flow window
+)
+
+// ParseIndexWeighting validates a weighting name; an empty name selects WeightEqual
+func ParseIndexWeighting(name string) (IndexWeighting, error) {
+	switch weighting := IndexWeighting(name); weighting {
+	case "":
+		return WeightEqual, nil
+	case WeightEqual, WeightLiquidity, WeightVolume:
+		return weighting, nil
+	default:
+		return "", fmt.Errorf("unknown index weighting %q (want equal, liquidity or volume)", name)
+	}
+}
+
+// IndexConfig configures an index price
+type IndexConfig struct {
+	Weighting    IndexWeighting  // Default WeightEqual
+	Band         decimal.Decimal // Band around mid the liquidity weighting sums the notional within, default 0.5%
+	MaxDeviation decimal.Decimal // Mids further than this fraction from the median mid are trimmed, default 0.01 (1%)
+	MaxStaleness time.Duration   // Books without updates for this long are left out, default 10s
+	MinVenues    int             // Venues the index needs after trimming, default 1
+	Flow         *Flow           // Taker volumes of the volume weighting, which needs it
+}
+
+// Reasons a venue is left out of the index
+const (
+	ExcludedStale    = "stale"     // No update for MaxStaleness
+	ExcludedOutlier  = "outlier"   // Beyond MaxDeviation from the median mid
+	ExcludedNoWeight = "no weight" // No liquidity or volume to weight it by
+)
+
+// IndexComponent is the contribution of one venue to the index price
+type IndexComponent struct {
+	Exchange  string
+	Mid       decimal.Decimal
+	Deviation decimal.Decimal // Mid / Median - 1
+	Weight    decimal.Decimal // Share of the index, 0-1; zero when left out
+	Excluded  string          // Why the venue was left out, empty when it counts
+}
+
+// IndexPrice is a weighted average of the mids of the venues after trimming outliers,
+// like the index prices derivative exchanges mark their contracts to
+type IndexPrice struct {
+	Price      decimal.Decimal
+	Median     decimal.Decimal // Of the fresh mids the outliers were trimmed against
+	Weighting  IndexWeighting
+	Venues     int              // Components that count
+	Components []IndexComponent // Every initialized, uncrossed book, sorted by exchange
+	Time       time.Time
+}
+
+// Index computes an index price across the registered books. Books register and unregister
+// like they do with a collector
+type Index struct {
+	config IndexConfig
+	mu     sync.Mutex
+	books  map[string]*orderbook.OrderBook
+}
+
+// NewIndex creates an index price over the books registered with it
+func NewIndex(config IndexConfig) *Index {
+	if config.Weighting == "" {
+		config.Weighting = WeightEqual
+	}
+	if !config.Band.IsPositive() {
+		config.Band = decimal.NewFromFloat(0.005)
+	}
+	if !config.MaxDeviation.IsPositive() {
+		config.MaxDeviation = decimal.NewFromFloat(0.01)
+	}
+	if config.MaxStaleness <= 0 {
+		config.MaxStaleness = 10 * time.Second
+	}
+	if config.MinVenues <= 0 {
+		config.MinVenues = 1
+	}
+	return &Index{config: config, books: make(map[string]*orderbook.OrderBook)}
+}
+
+// RegisterOrderbook adds the book of an exchange to the index
+func (x *Index) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.books[name] = ob
+}
+
+// UnregisterOrderbook removes the book of an exchange from the index
+func (x *Index) UnregisterOrderbook(name string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.books, name)
+}
+
+// Weighting returns how the venues are weighted
+func (x *Index) Weighting() IndexWeighting {
+	return x.config.Weighting
+}
+
+// Compute returns the index price of the current books; ok is false with fewer than
+// MinVenues venues left to count
+func (x *Index) Compute() (IndexPrice, bool) {
+	return x.compute(time.Now())
+}
+
+// compute trims stale books and mids beyond the deviation from the median, then weights the rest
+func (x *Index) compute(now time.Time) (IndexPrice, bool) {
+	x.mu.Lock()
+	books := make(map[string]*orderbook.BookSnapshot, len(x.books))
+	for name, ob := range x.books {
+		books[name] = ob.Snapshot()
+	}
+	x.mu.Unlock()
+
+	index := IndexPrice{Price: decimal.Zero, Median: decimal.Zero, Weighting: x.config.Weighting, Time: now}
+	fresh := make(map[string]decimal.Decimal, len(books))
+	for name, book := range books {
+		if !book.Initialized || len(book.Bids) == 0 || len(book.Asks) == 0 || !book.Asks[0].Price.GreaterThan(book.Bids[0].Price) {
+			continue
+		}
+		component := IndexComponent{Exchange: name, Mid: book.MidPrice(), Deviation: decimal.Zero, Weight: decimal.Zero}
+		if now.Sub(book.Stats.LastUpdateTime) >= x.config.MaxStaleness {
+			component.Excluded = ExcludedStale
+		} else {
+			fresh[name] = component.Mid
+		}
+		index.Components = append(index.Components, component)
+	}
+	sort.Slice(index.Components, func(i, j int) bool { return index.Components[i].Exchange < index.Components[j].Exchange })
+
+	median, ok := medianMid(fresh, 1)
+	if !ok {
+		return index, false
+	}
+	index.Median = median
+
+	one := decimal.NewFromInt(1)
+	total := decimal.Zero
+	weights := make([]decimal.Decimal, len(index.Components))
+	for i := range index.Components {
+		component := &index.Components[i]
+		component.Deviation = component.Mid.Div(median).Sub(one)
+		if component.Excluded != "" {
+			continue
+		}
+		if component.Deviation.Abs().GreaterThan(x.config.MaxDeviation) {
+			component.Excluded = ExcludedOutlier
+			continue
+		}
+		weight := x.weight(component.Exchange, books[component.Exchange])
+		if !weight.IsPositive() {
+			component.Excluded = ExcludedNoWeight
+			continue
+		}
+		weights[i] = weight
+		total = total.Add(weight)
+		index.Venues++
+	}
+	if index.Venues < x.config.MinVenues || !total.IsPositive() {
+		return index, false
+	}
+
+	for i := range index.Components {
+		component := &index.Components[i]
+		if component.Excluded != "" {
+			continue
+		}
+		component.Weight = weights[i].Div(total)
+		index.Price = index.Price.Add(component.Mid.Mul(component.Weight))
+	}
+	return index, true
+}
+
+// weight returns the raw weight of a venue under the configured weighting
+func (x *Index) weight(name string, book *orderbook.BookSnapshot) decimal.Decimal {
+	switch x.config.Weighting {
+	case WeightLiquidity:
+		// A feed capped short of the band only counts the notional it carries
+		depth := book.DepthAtOffsets([]decimal.Decimal{x.config.Band})
+		if len(depth) == 0 {
+			return decimal.Zero
+		}
+		return depth[0].BidNotional.Add(depth[0].AskNotional)
+	case WeightVolume:
+		if x.config.Flow == nil {
+			return decimal.Zero
+		}
+		stats, ok := x.config.Flow.Stats(name)
+		if !ok {
+			return decimal.Zero
+		}
+		return stats.BuyVolume.Add(stats.SellVolume)
+	default:
+		return decimal.NewFromInt(1)
+	}
+}
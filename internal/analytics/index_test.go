@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIndex(t *testing.T) {
+	if _, err := ParseIndexWeighting("median"); err == nil {
+		t.Error("expected an error for an unknown weighting")
+	}
+
+	flow := NewFlow(FlowConfig{Window: time.Minute}, "BTCUSDT")
+	x := NewIndex(IndexConfig{Weighting: WeightLiquidity, Flow: flow})
+	// okx holds 3 of bid notional within the band per 1 of binance, bybit is an outlier
+	x.RegisterOrderbook("okx", liveBook(t, []exchange.PriceLevel{{Price: "99", Quantity: "3"}}, []exchange.PriceLevel{{Price: "101", Quantity: "1"}}), exchange.Capabilities{})
+	x.RegisterOrderbook("binance", liveBook(t, []exchange.PriceLevel{{Price: "99.5", Quantity: "1"}}, []exchange.PriceLevel{{Price: "100.5", Quantity: "1"}}), exchange.Capabilities{})
+	x.RegisterOrderbook("bybit", liveBook(t, []exchange.PriceLevel{{Price: "109", Quantity: "100"}}, []exchange.PriceLevel{{Price: "111", Quantity: "100"}}), exchange.Capabilities{})
+
+	// A 2% band reaches every level of okx and binance
+	x.config.Band = decimal.NewFromFloat(0.02)
+	index, ok := x.Compute()
+	if !ok || index.Venues != 2 || len(index.Components) != 3 {
+		t.Fatalf("expected two of three venues to count, got %+v", index)
+	}
+	if !index.Median.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected a median mid of 100, got %s", index.Median)
+	}
+	if bybit := index.Components[1]; bybit.Exchange != "bybit" || bybit.Excluded != ExcludedOutlier || !bybit.Weight.IsZero() {
+		t.Errorf("expected bybit trimmed as an outlier, got %+v", bybit)
+	}
+	okx := index.Components[2]
+	want := decimal.NewFromInt(398).Div(decimal.NewFromInt(598)) // 297 + 101 of those and 99.5 + 100.5
+	if !okx.Weight.Round(12).Equal(want.Round(12)) || !index.Price.Round(12).Equal(decimal.NewFromInt(100)) {
+		t.Errorf("unexpected okx weight %s and index %s", okx.Weight, index.Price)
+	}
+
+	// Volume weighting leaves out the venues without trades
+	x.config.Weighting = WeightVolume
+	flow.Record("binance", &exchange.Trade{Price: "100", Quantity: "2", Side: exchange.TakerBuy})
+	if index, ok = x.Compute(); !ok || index.Venues != 1 || !index.Price.Equal(decimal.NewFromInt(100)) || index.Components[2].Excluded != ExcludedNoWeight {
+		t.Errorf("expected binance alone in the volume-weighted index, got %+v", index)
+	}
+
+	// Equal weights, and every book stale a minute later
+	x.config.Weighting = WeightEqual
+	if index, _ = x.Compute(); !index.Components[0].Weight.Equal(decimal.NewFromFloat(0.5)) {
+		t.Errorf("expected equal weights, got %+v", index.Components)
+	}
+	if index, ok = x.compute(time.Now().Add(time.Minute)); ok || index.Components[0].Excluded != ExcludedStale {
+		t.Errorf("expected no index of stale books, got %+v", index)
+	}
+
+	x.UnregisterOrderbook("okx")
+	x.UnregisterOrderbook("binance")
+	x.UnregisterOrderbook("bybit")
+	if _, ok := x.Compute(); ok {
+		t.Error("expected no index without books")
+	}
+}
@@ -0,0 +1,281 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// ResiliencyConfig configures resiliency tracking
+type ResiliencyConfig struct {
+	Band      decimal.Decimal // Distance from mid whose depth is watched, as a fraction; default 0.005 (0.5%)
+	Depletion decimal.Decimal // Share of a side's depth that must vanish between two samples to count as a large depletion, default 0.3
+	Recovery  decimal.Decimal // Share of the depth before a depletion that must be back for it to recover, default 0.9
+	Timeout   time.Duration   // Longest a depletion is followed before it counts as unrecovered, default 1m
+	Window    time.Duration   // Trailing window of the depletions scored, default 15m
+	Interval  time.Duration   // Time between samples, default 1s
+}
+
+// ResiliencyStats is how quickly the depth near mid of a book came back after large depletions
+type ResiliencyStats struct {
+	Score         decimal.Decimal // 0-1: the mean over depletions of 1 - recovery time / Timeout, 0 when unrecovered; 1 without depletions
+	Depletions    int             // Ended within the window, recovered or not
+	Recovered     int
+	MeanRecovery  time.Duration   // Of the recovered depletions
+	Replenishment decimal.Decimal // Size placed within the band while depleted over the size the depletions took; zero without depletions
+	BidDepth      decimal.Decimal // Within the band as of the latest sample, in base units
+	AskDepth      decimal.Decimal
+	Window        time.Duration
+}
+
+// depletion is a large drop of one side's depth being followed until it recovers or times out
+type depletion struct {
+	start       time.Time
+	before      decimal.Decimal // Depth of the sample before the drop
+	trough      decimal.Decimal
+	replenished decimal.Decimal // Size placed within the band since the drop
+}
+
+// depletionResult is a depletion that ended
+type depletionResult struct {
+	end         time.Time
+	recovered   bool
+	elapsed     time.Duration // Until the depth recovered, or the timeout
+	depleted    decimal.Decimal
+	replenished decimal.Decimal
+}
+
+// venueResiliency is the sampled depth of one book
+type venueResiliency struct {
+	book    *orderbook.OrderBook
+	near    *orderbook.BookSnapshot // Levels within the band at the latest sample, nil before it
+	ongoing [2]*depletion           // Bid and ask
+	results []depletionResult       // Oldest first
+}
+
+// Resiliency samples the depth within a band of mid of each registered book, detects
+// large depletions and measures how fast and how much liquidity is replenished after
+// them, from the level changes between samples. Books register and unregister like they
+// do with a collector
+type Resiliency struct {
+	config ResiliencyConfig
+	mu     sync.Mutex
+	venues map[string]*venueResiliency
+}
+
+// NewResiliency creates a resiliency tracker; call Start to sample the books
+func NewResiliency(config ResiliencyConfig) *Resiliency {
+	if !config.Band.IsPositive() {
+		config.Band = decimal.NewFromFloat(0.005)
+	}
+	if !config.Depletion.IsPositive() {
+		config.Depletion = decimal.NewFromFloat(0.3)
+	}
+	if !config.Recovery.IsPositive() {
+		config.Recovery = decimal.NewFromFloat(0.9)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = time.Minute
+	}
+	if config.Window <= 0 {
+		config.Window = 15 * time.Minute
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	return &Resiliency{
+		config: config,
+		venues: make(map[string]*venueResiliency),
+	}
+}
+
+// RegisterOrderbook adds the book of an exchange to the books sampled
+func (r *Resiliency) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.venues[name] = &venueResiliency{book: ob}
+}
+
+// UnregisterOrderbook removes the book of an exchange and its depletions
+func (r *Resiliency) UnregisterOrderbook(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.venues, name)
+}
+
+// Reset forgets the samples and depletions of every book, for a symbol switch
+func (r *Resiliency) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, venue := range r.venues {
+		*venue = venueResiliency{book: venue.book}
+	}
+}
+
+// Start samples the books every interval until ctx is done
+func (r *Resiliency) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.sample(now)
+		}
+	}
+}
+
+// sample compares the depth within the band of every book to the previous sample,
+// opening, following and ending depletions
+func (r *Resiliency) sample(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, venue := range r.venues {
+		book := venue.book.Snapshot()
+		mid := book.MidPrice()
+		if !book.Initialized || !mid.IsPositive() {
+			continue
+		}
+		near := r.nearMid(book, mid)
+		if previous := venue.near; previous != nil {
+			diff := orderbook.Diff(previous, near)
+			placed := [2]decimal.Decimal{diff.Bids.Placed, diff.Asks.Placed}
+			before := [2]decimal.Decimal{sideDepth(previous.Bids), sideDepth(previous.Asks)}
+			after := [2]decimal.Decimal{sideDepth(near.Bids), sideDepth(near.Asks)}
+			for side := range venue.ongoing {
+				r.follow(venue, side, before[side], after[side], placed[side], now)
+			}
+		}
+		venue.near = near
+
+		cutoff := now.Add(-r.config.Window)
+		keep := 0
+		for keep < len(venue.results) && venue.results[keep].end.Before(cutoff) {
+			keep++
+		}
+		venue.results = venue.results[keep:]
+	}
+}
+
+// follow moves the depletion of one side along from a sample that went from before to
+// after depth, placed being the size added within the band in between (r.mu must be held)
+func (r *Resiliency) follow(venue *venueResiliency, side int, before, after, placed decimal.Decimal, now time.Time) {
+	d := venue.ongoing[side]
+	if d == nil {
+		if before.IsPositive() && before.Sub(after).GreaterThanOrEqual(before.Mul(r.config.Depletion)) {
+			venue.ongoing[side] = &depletion{start: now, before: before, trough: after, replenished: decimal.Zero}
+		}
+		return
+	}
+
+	d.trough = decimal.Min(d.trough, after)
+	d.replenished = d.replenished.Add(placed)
+	elapsed := now.Sub(d.start)
+	recovered := after.GreaterThanOrEqual(d.before.Mul(r.config.Recovery))
+	if !recovered && elapsed < r.config.Timeout {
+		return
+	}
+	if !recovered {
+		elapsed = r.config.Timeout
+	}
+	venue.results = append(venue.results, depletionResult{
+		end:         now,
+		recovered:   recovered,
+		elapsed:     elapsed,
+		depleted:    d.before.Sub(d.trough),
+		replenished: d.replenished,
+	})
+	venue.ongoing[side] = nil
+}
+
+// nearMid returns a copy of the book holding only the levels within the band of mid
+func (r *Resiliency) nearMid(book *orderbook.BookSnapshot, mid decimal.Decimal) *orderbook.BookSnapshot {
+	offset := mid.Mul(r.config.Band)
+	floor, ceiling := mid.Sub(offset), mid.Add(offset)
+	bids := 0
+	for bids < len(book.Bids) && book.Bids[bids].Price.GreaterThanOrEqual(floor) {
+		bids++
+	}
+	asks := 0
+	for asks < len(book.Asks) && book.Asks[asks].Price.LessThanOrEqual(ceiling) {
+		asks++
+	}
+	return &orderbook.BookSnapshot{Bids: book.Bids[:bids], Asks: book.Asks[:asks], Initialized: true, TakenAt: book.TakenAt}
+}
+
+// sideDepth sums the size of levels
+func sideDepth(levels []types.PriceLevel) decimal.Decimal {
+	total := decimal.Zero
+	for _, level := range levels {
+		total = total.Add(level.Quantity)
+	}
+	return total
+}
+
+// Stats returns the resiliency of an exchange; ok is false before its book was sampled
+func (r *Resiliency) Stats(name string) (ResiliencyStats, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	venue, ok := r.venues[name]
+	if !ok || venue.near == nil {
+		return ResiliencyStats{}, false
+	}
+
+	stats := ResiliencyStats{
+		Score:      decimal.NewFromInt(1),
+		Depletions: len(venue.results),
+		BidDepth:   sideDepth(venue.near.Bids),
+		AskDepth:   sideDepth(venue.near.Asks),
+		Window:     r.config.Window,
+	}
+	if len(venue.results) == 0 {
+		return stats, true
+	}
+
+	timeout := decimal.NewFromInt(int64(r.config.Timeout))
+	score, depleted, replenished := decimal.Zero, decimal.Zero, decimal.Zero
+	var recovery time.Duration
+	for _, result := range venue.results {
+		if result.recovered {
+			stats.Recovered++
+			recovery += result.elapsed
+			score = score.Add(decimal.NewFromInt(1).Sub(decimal.NewFromInt(int64(result.elapsed)).Div(timeout)))
+		}
+		depleted = depleted.Add(result.depleted)
+		replenished = replenished.Add(result.replenished)
+	}
+	stats.Score = score.Div(decimal.NewFromInt(int64(len(venue.results))))
+	if stats.Recovered > 0 {
+		stats.MeanRecovery = recovery / time.Duration(stats.Recovered)
+	}
+	if depleted.IsPositive() {
+		stats.Replenishment = replenished.Div(depleted)
+	}
+	return stats, true
+}
+
+// All returns the resiliency of every sampled book, by exchange
+func (r *Resiliency) All() map[string]ResiliencyStats {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.venues))
+	for name := range r.venues {
+		names = append(names, name)
+	}
+	r.mu.Unlock()
+
+	all := make(map[string]ResiliencyStats, len(names))
+	for _, name := range names {
+		if stats, ok := r.Stats(name); ok {
+			all[name] = stats
+		}
+	}
+	return all
+}
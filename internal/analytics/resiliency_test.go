@@ -0,0 +1,81 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestResiliency(t *testing.T) {
+	r := NewResiliency(ResiliencyConfig{Timeout: time.Minute, Window: 15 * time.Minute})
+	// Levels 2% away stay outside the 0.5% band
+	bids := func(quantity string) []exchange.PriceLevel {
+		return []exchange.PriceLevel{{Price: "99.8", Quantity: quantity}, {Price: "98", Quantity: "50"}}
+	}
+	asks := func(quantity string) []exchange.PriceLevel {
+		return []exchange.PriceLevel{{Price: "100.2", Quantity: quantity}, {Price: "102", Quantity: "50"}}
+	}
+	ob := liveBook(t, bids("10"), asks("10"))
+	reload := func(bid, ask string) {
+		if err := ob.LoadSnapshot(&exchange.Snapshot{Bids: bids(bid), Asks: asks(ask)}); err != nil {
+			t.Fatalf("LoadSnapshot() error: %v", err)
+		}
+	}
+	r.RegisterOrderbook("binance", ob, exchange.Capabilities{})
+	if _, ok := r.Stats("binance"); ok {
+		t.Error("expected no resiliency before the first sample")
+	}
+
+	start := time.Now()
+	r.sample(start)
+	stats, ok := r.Stats("binance")
+	if !ok || !stats.Score.Equal(decimal.NewFromInt(1)) || stats.Depletions != 0 || !stats.BidDepth.Equal(decimal.NewFromInt(10)) {
+		t.Fatalf("unexpected resiliency without depletions %+v", stats)
+	}
+
+	// The bids lose 60% and are back 15s later: a quarter of the timeout
+	reload("4", "10")
+	r.sample(start.Add(time.Second))
+	reload("10", "10")
+	r.sample(start.Add(16 * time.Second))
+	stats, _ = r.Stats("binance")
+	if stats.Depletions != 1 || stats.Recovered != 1 || stats.MeanRecovery != 15*time.Second || !stats.Score.Equal(decimal.RequireFromString("0.75")) {
+		t.Errorf("unexpected resiliency after a recovery %+v", stats)
+	}
+	if !stats.Replenishment.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("replenishment %s, want 1", stats.Replenishment)
+	}
+
+	// The asks lose 80% and do not come back within the timeout
+	reload("10", "2")
+	r.sample(start.Add(20 * time.Second))
+	r.sample(start.Add(81 * time.Second))
+	stats, _ = r.Stats("binance")
+	if stats.Depletions != 2 || stats.Recovered != 1 || !stats.Score.Equal(decimal.RequireFromString("0.375")) {
+		t.Errorf("unexpected resiliency after a timeout %+v", stats)
+	}
+	if want := decimal.NewFromInt(6).Div(decimal.NewFromInt(14)); !stats.Replenishment.Equal(want) {
+		t.Errorf("replenishment %s, want %s", stats.Replenishment, want)
+	}
+
+	// The recovered depletion leaves the window
+	r.sample(start.Add(16*time.Second + 15*time.Minute + time.Second))
+	if stats, _ = r.Stats("binance"); stats.Depletions != 1 || !stats.Score.IsZero() {
+		t.Errorf("unexpected resiliency after the window %+v", stats)
+	}
+
+	if len(r.All()) != 1 {
+		t.Errorf("expected one book, got %v", r.All())
+	}
+	r.Reset()
+	if _, ok := r.Stats("binance"); ok {
+		t.Error("expected no resiliency after a reset")
+	}
+	r.UnregisterOrderbook("binance")
+	if len(r.All()) != 0 {
+		t.Error("expected no books after unregistering")
+	}
+}
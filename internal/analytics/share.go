@@ -0,0 +1,271 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// MarketShareConfig configures market share tracking
+type MarketShareConfig struct {
+	Interval time.Duration // Time between samples of the consolidated best bid and ask, default 1s
+}
+
+// BandShare is the share of a venue in the notional within one band, among the venues
+// whose feed reaches it
+type BandShare struct {
+	Pct   decimal.Decimal // Band half-width as a fraction of mid
+	Bid   decimal.Decimal // Share of the bid notional, 0-1
+	Ask   decimal.Decimal // Share of the ask notional, 0-1
+	Total decimal.Decimal // Share of the bid and ask notional together
+}
+
+// MarketShareStats is the share of a venue in the consolidated liquidity and best quotes
+type MarketShareStats struct {
+	Bands   []BandShare     // Of the current books, for the bands the venue's feed reaches
+	BestBid decimal.Decimal // Share of the sampled time the venue quoted the consolidated best bid; tied venues each count
+	BestAsk decimal.Decimal
+	Sampled time.Duration // Time the best quotes were sampled over since the book registered
+}
+
+// VenueShare is the market share of one venue
+type VenueShare struct {
+	Exchange string
+	MarketShareStats
+}
+
+// shareBook is a registered book
+type shareBook struct {
+	book *orderbook.OrderBook
+	caps exchange.Capabilities
+}
+
+// shareTime is the time a venue spent at the best quotes
+type shareTime struct {
+	sampled, bid, ask time.Duration
+}
+
+// MarketShare computes the share of each registered book in the liquidity within each
+// band across the books, and samples which books quote the consolidated best bid and ask
+// for their time-weighted share of it. Books register and unregister like they do with a
+// collector
+type MarketShare struct {
+	config MarketShareConfig
+	mu     sync.Mutex
+	books  map[string]shareBook
+	times  map[string]*shareTime
+	last   time.Time // Of the latest sample, zero before it
+}
+
+// NewMarketShare creates a market share tracker; call Start to sample the best quotes
+func NewMarketShare(config MarketShareConfig) *MarketShare {
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	return &MarketShare{
+		config: config,
+		books:  make(map[string]shareBook),
+		times:  make(map[string]*shareTime),
+	}
+}
+
+// RegisterOrderbook adds the book of an exchange
+func (m *MarketShare) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.books[name] = shareBook{book: ob, caps: caps}
+	m.times[name] = &shareTime{}
+}
+
+// UnregisterOrderbook removes the book of an exchange and its time at the best quotes
+func (m *MarketShare) UnregisterOrderbook(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.books, name)
+	delete(m.times, name)
+}
+
+// Reset forgets the time every book spent at the best quotes, for a symbol switch
+func (m *MarketShare) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name := range m.times {
+		m.times[name] = &shareTime{}
+	}
+	m.last = time.Time{}
+}
+
+// Start samples the best quotes every interval until ctx is done
+func (m *MarketShare) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.sample(now)
+		}
+	}
+}
+
+// sample credits the time since the previous sample to the books quoting the consolidated
+// best bid and ask; a gap longer than a few intervals is credited as one interval
+func (m *MarketShare) sample(now time.Time) {
+	books := m.snapshots()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elapsed := now.Sub(m.last)
+	if m.last.IsZero() || elapsed <= 0 {
+		m.last = now
+		return
+	}
+	m.last = now
+	if elapsed > 5*m.config.Interval {
+		elapsed = m.config.Interval
+	}
+
+	cbbo, ok := ComputeCBBO(books)
+	if !ok {
+		return
+	}
+	for name := range books {
+		if times, ok := m.times[name]; ok {
+			times.sampled += elapsed
+		}
+	}
+	for _, name := range cbbo.BidVenues {
+		if times, ok := m.times[name]; ok {
+			times.bid += elapsed
+		}
+	}
+	for _, name := range cbbo.AskVenues {
+		if times, ok := m.times[name]; ok {
+			times.ask += elapsed
+		}
+	}
+}
+
+// snapshots copies the initialized registered books
+func (m *MarketShare) snapshots() map[string]*orderbook.BookSnapshot {
+	m.mu.Lock()
+	registered := make(map[string]*orderbook.OrderBook, len(m.books))
+	for name, b := range m.books {
+		registered[name] = b.book
+	}
+	m.mu.Unlock()
+
+	books := make(map[string]*orderbook.BookSnapshot, len(registered))
+	for name, ob := range registered {
+		if book := ob.Snapshot(); book.Initialized {
+			books[name] = book
+		}
+	}
+	return books
+}
+
+// Stats returns the market share of an exchange; ok is false unless its book is initialized
+func (m *MarketShare) Stats(name string) (MarketShareStats, bool) {
+	all := m.All()
+	stats, ok := all[name]
+	return stats, ok
+}
+
+// All returns the market share of every initialized book, by exchange
+func (m *MarketShare) All() map[string]MarketShareStats {
+	books := m.snapshots()
+
+	m.mu.Lock()
+	caps := make(map[string]exchange.Capabilities, len(books))
+	all := make(map[string]MarketShareStats, len(books))
+	for name := range books {
+		caps[name] = m.books[name].caps
+		stats := MarketShareStats{BestBid: decimal.Zero, BestAsk: decimal.Zero}
+		if times, ok := m.times[name]; ok && times.sampled > 0 {
+			sampled := decimal.NewFromInt(int64(times.sampled))
+			stats.BestBid = decimal.NewFromInt(int64(times.bid)).Div(sampled)
+			stats.BestAsk = decimal.NewFromInt(int64(times.ask)).Div(sampled)
+			stats.Sampled = times.sampled
+		}
+		all[name] = stats
+	}
+	m.mu.Unlock()
+
+	// Bands are configured alike on every book; a venue whose feed falls short of a band
+	// leaves it out of the totals
+	type bandTotal struct{ bid, ask decimal.Decimal }
+	totals := make(map[string]*bandTotal)
+	covered := func(name string, book *orderbook.BookSnapshot, pct decimal.Decimal) bool {
+		return caps[name].MaxDepth == 0 || book.CoversDepth(pct.InexactFloat64())
+	}
+	for name, book := range books {
+		for _, band := range book.Stats.Bands {
+			if !covered(name, book, band.Pct) {
+				continue
+			}
+			total, ok := totals[band.Pct.String()]
+			if !ok {
+				total = &bandTotal{bid: decimal.Zero, ask: decimal.Zero}
+				totals[band.Pct.String()] = total
+			}
+			total.bid = total.bid.Add(band.BidNotional)
+			total.ask = total.ask.Add(band.AskNotional)
+		}
+	}
+	share := func(part, total decimal.Decimal) decimal.Decimal {
+		if !total.IsPositive() {
+			return decimal.Zero
+		}
+		return part.Div(total)
+	}
+	for name, book := range books {
+		stats := all[name]
+		for _, band := range book.Stats.Bands {
+			if !covered(name, book, band.Pct) {
+				continue
+			}
+			total := totals[band.Pct.String()]
+			stats.Bands = append(stats.Bands, BandShare{
+				Pct:   band.Pct,
+				Bid:   share(band.BidNotional, total.bid),
+				Ask:   share(band.AskNotional, total.ask),
+				Total: share(band.BidNotional.Add(band.AskNotional), total.bid.Add(total.ask)),
+			})
+		}
+		all[name] = stats
+	}
+	return all
+}
+
+// Ranked returns the market share of every initialized book, the largest share of its
+// widest band first, then the most time at the best quotes
+func (m *MarketShare) Ranked() []VenueShare {
+	var ranked []VenueShare
+	for name, stats := range m.All() {
+		ranked = append(ranked, VenueShare{Exchange: name, MarketShareStats: stats})
+	}
+	widest := func(s MarketShareStats) decimal.Decimal {
+		if len(s.Bands) == 0 {
+			return decimal.Zero
+		}
+		return s.Bands[len(s.Bands)-1].Total
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if !widest(a.MarketShareStats).Equal(widest(b.MarketShareStats)) {
+			return widest(a.MarketShareStats).GreaterThan(widest(b.MarketShareStats))
+		}
+		if quotes, other := a.BestBid.Add(a.BestAsk), b.BestBid.Add(b.BestAsk); !quotes.Equal(other) {
+			return quotes.GreaterThan(other)
+		}
+		return a.Exchange < b.Exchange
+	})
+	return ranked
+}
@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMarketShare(t *testing.T) {
+	m := NewMarketShare(MarketShareConfig{})
+	m.RegisterOrderbook("binance", liveBook(t, []exchange.PriceLevel{{Price: "99.9", Quantity: "3"}}, []exchange.PriceLevel{{Price: "100.2", Quantity: "1"}}), exchange.Capabilities{})
+	m.RegisterOrderbook("okx", liveBook(t, []exchange.PriceLevel{{Price: "99.8", Quantity: "1"}}, []exchange.PriceLevel{{Price: "100.2", Quantity: "1"}}), exchange.Capabilities{})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		m.sample(start.Add(time.Duration(i) * time.Second))
+	}
+
+	binance, ok := m.Stats("binance")
+	if !ok {
+		t.Fatal("expected the market share of binance")
+	}
+	okx, _ := m.Stats("okx")
+	if binance.Sampled != 2*time.Second || !binance.BestBid.Equal(decimal.NewFromInt(1)) || !binance.BestAsk.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("unexpected best quote share of binance %+v", binance)
+	}
+	if !okx.BestBid.IsZero() || !okx.BestAsk.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("unexpected best quote share of okx %+v", okx)
+	}
+
+	if len(binance.Bands) != 3 || len(okx.Bands) != 3 {
+		t.Fatalf("expected every default band, got %+v and %+v", binance.Bands, okx.Bands)
+	}
+	for i := range binance.Bands {
+		a, b := binance.Bands[i], okx.Bands[i]
+		if !a.Bid.Add(b.Bid).Equal(decimal.NewFromInt(1)) || !a.Ask.Equal(decimal.NewFromFloat(0.5)) || !a.Total.GreaterThan(b.Total) {
+			t.Errorf("unexpected shares of band %s: %+v and %+v", a.Pct, a, b)
+		}
+	}
+
+	ranked := m.Ranked()
+	if len(ranked) != 2 || ranked[0].Exchange != "binance" {
+		t.Errorf("expected binance to rank first, got %+v", ranked)
+	}
+
+	// A gap is credited as one interval
+	m.sample(start.Add(time.Minute))
+	if binance, _ = m.Stats("binance"); binance.Sampled != 3*time.Second {
+		t.Errorf("expected a gap to count as one interval, sampled %s", binance.Sampled)
+	}
+
+	m.Reset()
+	if binance, _ = m.Stats("binance"); binance.Sampled != 0 || !binance.BestBid.IsZero() {
+		t.Errorf("expected no sampled time after a reset, got %+v", binance)
+	}
+	m.UnregisterOrderbook("okx")
+	if binance, _ = m.Stats("binance"); !binance.Bands[0].Bid.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the whole band after unregistering okx, got %+v", binance.Bands[0])
+	}
+}
@@ -0,0 +1,403 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// SpoofingConfig configures the spoofing and layering detector
+type SpoofingConfig struct {
+	Depth        int             // Levels per side watched, default 20
+	MinSize      decimal.Decimal // Size in base units a pull must reach to count; zero uses SizeMultiple
+	SizeMultiple decimal.Decimal // Without MinSize, a pull must reach this multiple of the mean watched level size, default 3
+	MaxAge       time.Duration   // Levels older than this when pulled are ignored, default 5s
+	Repeats      int             // Pulls at one price within Window that flag spoofing, default 3
+	Window       time.Duration   // Trailing window the pulls at a price are counted over, default 1m
+	Layers       int             // Levels of one side pulled together that flag layering, default 3
+	Interval     time.Duration   // Time between samples, default 250ms
+	History      int             // Events kept for Events, default 100
+}
+
+// SpoofingKind identifies the pattern an event flags
+type SpoofingKind string
+
+const (
+	KindSpoofing SpoofingKind = "spoofing" // Size placed and pulled at the same price repeatedly
+	KindLayering SpoofingKind = "layering" // Several levels of one side pulled together
+)
+
+// SpoofingEvent reports the pulls that flagged a pattern. Pulls are large, short-lived
+// levels removed or shrunk without a trade at or through their price
+type SpoofingEvent struct {
+	Exchange string
+	Symbol   string
+	Kind     SpoofingKind
+	Side     types.Side      // types.Buy for bids, types.Sell for asks
+	Price    decimal.Decimal // Of the repeated level, or the best level pulled for layering
+	Size     decimal.Decimal // Pulled by the pulls flagged, in base units
+	Pulls    int             // Pulls flagged: the repeats at the price, or the levels pulled together
+	Lifetime time.Duration   // Mean age of the levels when pulled
+	Time     time.Time
+}
+
+// SpoofingStats counts the pulls and patterns of an exchange since its book registered
+type SpoofingStats struct {
+	Pulls     int       // Large, short-lived and untraded pulls
+	Spoofing  int       // Spoofing events
+	Layering  int       // Layering events
+	LastEvent time.Time // Zero before the first event
+}
+
+// spoofingPull is one qualifying pull
+type spoofingPull struct {
+	at    time.Time
+	price decimal.Decimal
+	size  decimal.Decimal
+	age   time.Duration // Of the level when pulled
+}
+
+// spoofingTrade is a trade seen within MaxAge
+type spoofingTrade struct {
+	at    time.Time
+	price decimal.Decimal
+}
+
+// venueSpoofing is the watched book of one exchange
+type venueSpoofing struct {
+	book     *orderbook.OrderBook
+	previous *orderbook.BookSnapshot       // Watched levels of the latest sample, nil before it
+	meta     [2]map[string]types.LevelMeta // Bid and ask metadata of the latest sample, by price
+	trades   []spoofingTrade               // Oldest first
+	repeats  map[string][]spoofingPull     // Qualifying pulls within the window, by side and price, oldest first
+	stats    SpoofingStats
+}
+
+// Spoofing watches the top levels of each registered book for large levels that are
+// pulled shortly after being placed without trading, flagging prices where it repeats
+// (spoofing) and sides where several levels go at once (layering). Level ages come from
+// the book's level metadata and pulls from the diff between samples; trades tell pulls
+// from fills, so only books of venues with a trades feed are watched
+type Spoofing struct {
+	config   SpoofingConfig
+	mu       sync.Mutex
+	symbol   string
+	venues   map[string]*venueSpoofing
+	events   []SpoofingEvent // Newest last, at most History
+	handlers []func(SpoofingEvent)
+}
+
+// NewSpoofing creates a detector for the books of symbol; call Start to sample them
+func NewSpoofing(config SpoofingConfig, symbol string) *Spoofing {
+	if config.Depth <= 0 {
+		config.Depth = 20
+	}
+	if !config.SizeMultiple.IsPositive() {
+		config.SizeMultiple = decimal.NewFromInt(3)
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 5 * time.Second
+	}
+	if config.Repeats <= 0 {
+		config.Repeats = 3
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Layers <= 0 {
+		config.Layers = 3
+	}
+	if config.Interval <= 0 {
+		config.Interval = 250 * time.Millisecond
+	}
+	if config.History <= 0 {
+		config.History = 100
+	}
+	return &Spoofing{
+		config: config,
+		symbol: symbol,
+		venues: make(map[string]*venueSpoofing),
+	}
+}
+
+// OnEvent adds a handler called with every event, outside the detector's lock
+func (s *Spoofing) OnEvent(handler func(SpoofingEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// RegisterOrderbook adds the book of an exchange with a trades feed to the books watched
+func (s *Spoofing) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	if !caps.Trades {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.venues[name] = newVenueSpoofing(ob)
+}
+
+func newVenueSpoofing(ob *orderbook.OrderBook) *venueSpoofing {
+	return &venueSpoofing{book: ob, repeats: make(map[string][]spoofingPull)}
+}
+
+// UnregisterOrderbook removes the book of an exchange and its counters
+func (s *Spoofing) UnregisterOrderbook(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.venues, name)
+}
+
+// SetSymbol changes the symbol reported with events, for books registered after a symbol
+// switch, and forgets the samples, counters and events of the previous symbol
+func (s *Spoofing) SetSymbol(symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.symbol = symbol
+	s.events = nil
+	for name, venue := range s.venues {
+		s.venues[name] = newVenueSpoofing(venue.book)
+	}
+}
+
+// Record notes a trade of the exchange registered as name, so the levels it filled are
+// not taken for pulls
+func (s *Spoofing) Record(name string, trade *exchange.Trade) {
+	s.record(name, trade, time.Now())
+}
+
+func (s *Spoofing) record(name string, trade *exchange.Trade, now time.Time) {
+	price, err := decimal.NewFromString(trade.Price)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if venue, ok := s.venues[name]; ok {
+		venue.trades = append(venue.trades, spoofingTrade{at: now, price: price})
+	}
+}
+
+// Start samples the books every interval until ctx is done
+func (s *Spoofing) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sample(now)
+		}
+	}
+}
+
+// sample diffs the watched levels of every book against the previous sample and flags
+// the patterns the pulls complete
+func (s *Spoofing) sample(now time.Time) {
+	s.mu.Lock()
+	var events []SpoofingEvent
+	for name, venue := range s.venues {
+		if !venue.book.IsInitialized() {
+			venue.previous = nil
+			continue
+		}
+		bids, asks := venue.book.LevelMetadata(s.config.Depth)
+		current := &orderbook.BookSnapshot{Bids: priceLevels(bids), Asks: priceLevels(asks), Initialized: true, TakenAt: now}
+		if venue.previous != nil {
+			diff := orderbook.Diff(venue.previous, current)
+			events = append(events, s.flag(name, venue, types.Buy, diff.Bids, current.Bids, now)...)
+			events = append(events, s.flag(name, venue, types.Sell, diff.Asks, current.Asks, now)...)
+		}
+		venue.previous = current
+		venue.meta = [2]map[string]types.LevelMeta{metaByPrice(bids), metaByPrice(asks)}
+		s.prune(venue, now)
+	}
+	s.events = append(s.events, events...)
+	if extra := len(s.events) - s.config.History; extra > 0 {
+		s.events = append(s.events[:0], s.events[extra:]...)
+	}
+	handlers := s.handlers
+	s.mu.Unlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+// flag finds the qualifying pulls of one side and returns the events they complete (s.mu must be held)
+func (s *Spoofing) flag(name string, venue *venueSpoofing, side types.Side, diff orderbook.SideDiff, levels []types.PriceLevel, now time.Time) []SpoofingEvent {
+	pulls := s.pulls(venue, side, diff, levels, now)
+	if len(pulls) == 0 {
+		return nil
+	}
+
+	var events []SpoofingEvent
+	event := func(kind SpoofingKind, flagged []spoofingPull) SpoofingEvent {
+		e := SpoofingEvent{Exchange: name, Symbol: s.symbol, Kind: kind, Side: side, Price: flagged[0].price, Size: decimal.Zero, Pulls: len(flagged), Time: now}
+		var lifetime time.Duration
+		for _, pull := range flagged {
+			e.Size = e.Size.Add(pull.size)
+			lifetime += pull.age
+		}
+		e.Lifetime = lifetime / time.Duration(len(flagged))
+		venue.stats.LastEvent = now
+		return e
+	}
+
+	for _, pull := range pulls {
+		venue.stats.Pulls++
+		key := string(side) + pull.price.String()
+		venue.repeats[key] = append(venue.repeats[key], pull)
+		if len(venue.repeats[key]) >= s.config.Repeats {
+			events = append(events, event(KindSpoofing, venue.repeats[key]))
+			venue.stats.Spoofing++
+			delete(venue.repeats, key)
+		}
+	}
+	if len(pulls) >= s.config.Layers {
+		events = append(events, event(KindLayering, pulls))
+		venue.stats.Layering++
+	}
+	return events
+}
+
+// pulls returns the size removed from levels within the watched depth that was large,
+// placed within MaxAge and not traded, best price first (s.mu must be held)
+func (s *Spoofing) pulls(venue *venueSpoofing, side types.Side, diff orderbook.SideDiff, levels []types.PriceLevel, now time.Time) []spoofingPull {
+	bid := side == types.Buy
+	meta := venue.meta[0]
+	if !bid {
+		meta = venue.meta[1]
+	}
+	if len(levels) == 0 || len(meta) == 0 {
+		return nil
+	}
+
+	threshold := s.config.MinSize
+	if !threshold.IsPositive() {
+		total := decimal.Zero
+		for _, level := range meta {
+			total = total.Add(level.Quantity)
+		}
+		threshold = total.Div(decimal.NewFromInt(int64(len(meta)))).Mul(s.config.SizeMultiple)
+	}
+
+	// Levels past the deepest one still watched may only have been pushed out of the depth
+	deepest := levels[len(levels)-1].Price
+	var pulls []spoofingPull
+	changes := append(append([]orderbook.LevelChange(nil), diff.Removed...), diff.Changed...)
+	for _, change := range changes {
+		size := change.Delta.Neg()
+		if !size.IsPositive() || size.LessThan(threshold) {
+			continue
+		}
+		if (bid && change.Price.LessThan(deepest)) || (!bid && change.Price.GreaterThan(deepest)) {
+			continue
+		}
+		level, ok := meta[change.Price.String()]
+		if !ok || now.Sub(level.Created) > s.config.MaxAge || venue.traded(bid, change.Price, level.Created) {
+			continue
+		}
+		pulls = append(pulls, spoofingPull{at: now, price: change.Price, size: size, age: now.Sub(level.Created)})
+	}
+
+	// Removed and changed levels are each sorted best first; merge them back into one order
+	sort.Slice(pulls, func(i, j int) bool {
+		if bid {
+			return pulls[i].price.GreaterThan(pulls[j].price)
+		}
+		return pulls[i].price.LessThan(pulls[j].price)
+	})
+	return pulls
+}
+
+// traded reports whether a trade at or through price happened since a level was placed
+func (v *venueSpoofing) traded(bid bool, price decimal.Decimal, since time.Time) bool {
+	for _, trade := range v.trades {
+		if trade.at.Before(since) {
+			continue
+		}
+		if (bid && trade.price.LessThanOrEqual(price)) || (!bid && trade.price.GreaterThanOrEqual(price)) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune drops the trades older than MaxAge and the pulls that left the window (s.mu must be held)
+func (s *Spoofing) prune(venue *venueSpoofing, now time.Time) {
+	keep := 0
+	for keep < len(venue.trades) && now.Sub(venue.trades[keep].at) > s.config.MaxAge {
+		keep++
+	}
+	venue.trades = append(venue.trades[:0], venue.trades[keep:]...)
+
+	for key, pulls := range venue.repeats {
+		keep := 0
+		for keep < len(pulls) && now.Sub(pulls[keep].at) > s.config.Window {
+			keep++
+		}
+		if keep == len(pulls) {
+			delete(venue.repeats, key)
+		} else {
+			venue.repeats[key] = pulls[keep:]
+		}
+	}
+}
+
+// priceLevels strips the metadata off levels
+func priceLevels(meta []types.LevelMeta) []types.PriceLevel {
+	levels := make([]types.PriceLevel, len(meta))
+	for i, level := range meta {
+		levels[i] = types.PriceLevel{Price: level.Price, Quantity: level.Quantity}
+	}
+	return levels
+}
+
+// metaByPrice indexes level metadata by price
+func metaByPrice(meta []types.LevelMeta) map[string]types.LevelMeta {
+	byPrice := make(map[string]types.LevelMeta, len(meta))
+	for _, level := range meta {
+		byPrice[level.Price.String()] = level
+	}
+	return byPrice
+}
+
+// Stats returns the counters of an exchange; ok is false unless its book is watched
+func (s *Spoofing) Stats(name string) (SpoofingStats, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	venue, ok := s.venues[name]
+	if !ok {
+		return SpoofingStats{}, false
+	}
+	return venue.stats, true
+}
+
+// All returns the counters of every watched book, by exchange
+func (s *Spoofing) All() map[string]SpoofingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := make(map[string]SpoofingStats, len(s.venues))
+	for name, venue := range s.venues {
+		all[name] = venue.stats
+	}
+	return all
+}
+
+// Events returns the latest events, oldest first
+func (s *Spoofing) Events() []SpoofingEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SpoofingEvent(nil), s.events...)
+}
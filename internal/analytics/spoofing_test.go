@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSpoofing(t *testing.T) {
+	s := NewSpoofing(SpoofingConfig{MinSize: decimal.NewFromInt(5)}, "BTCUSDT")
+	var events []SpoofingEvent
+	s.OnEvent(func(e SpoofingEvent) { events = append(events, e) })
+
+	bids := []exchange.PriceLevel{{Price: "99", Quantity: "1"}, {Price: "98", Quantity: "1"}, {Price: "97", Quantity: "1"}}
+	asks := []exchange.PriceLevel{{Price: "101", Quantity: "1"}, {Price: "102", Quantity: "1"}, {Price: "103", Quantity: "1"}}
+	ob := liveBook(t, bids, asks)
+	load := func(bids, asks []exchange.PriceLevel) {
+		if err := ob.LoadSnapshot(&exchange.Snapshot{Bids: bids, Asks: asks}); err != nil {
+			t.Fatalf("LoadSnapshot() error: %v", err)
+		}
+	}
+	s.RegisterOrderbook("okx", ob, exchange.Capabilities{Trades: true})
+	s.RegisterOrderbook("bitstamp", ob, exchange.Capabilities{})
+	if _, ok := s.Stats("bitstamp"); ok {
+		t.Error("expected books without trades to be left out")
+	}
+
+	now := time.Now()
+	step := func() {
+		now = now.Add(250 * time.Millisecond)
+		s.sample(now)
+	}
+	spoofed := append([]exchange.PriceLevel{{Price: "99", Quantity: "1"}, {Price: "98.5", Quantity: "10"}}, bids[1:]...)
+	step()
+
+	// Size placed and pulled at 98.5 three times flags spoofing
+	for range 3 {
+		load(spoofed, asks)
+		step()
+		load(bids, asks)
+		step()
+	}
+	if len(events) != 1 || events[0].Kind != KindSpoofing || events[0].Side != types.Buy || events[0].Pulls != 3 {
+		t.Fatalf("expected one spoofing event, got %+v", events)
+	}
+	if !events[0].Price.Equal(decimal.RequireFromString("98.5")) || !events[0].Size.Equal(decimal.NewFromInt(30)) || events[0].Symbol != "BTCUSDT" {
+		t.Errorf("unexpected spoofing event %+v", events[0])
+	}
+
+	// A level that traded before going is a fill, not a pull
+	load(spoofed, asks)
+	step()
+	s.Record("okx", &exchange.Trade{Price: "98.5", Quantity: "10", Side: exchange.TakerSell})
+	load(bids, asks)
+	step()
+
+	// Three ask levels pulled together flag layering
+	layered := []exchange.PriceLevel{{Price: "101", Quantity: "1"}, {Price: "101.5", Quantity: "10"}, {Price: "102", Quantity: "1"}, {Price: "102.5", Quantity: "10"}, {Price: "103", Quantity: "10"}}
+	load(bids, layered)
+	step()
+	load(bids, asks)
+	step()
+	if len(events) != 2 || events[1].Kind != KindLayering || events[1].Side != types.Sell || events[1].Pulls != 3 {
+		t.Fatalf("expected a layering event, got %+v", events)
+	}
+	if !events[1].Price.Equal(decimal.RequireFromString("101.5")) || !events[1].Size.Equal(decimal.NewFromInt(29)) {
+		t.Errorf("unexpected layering event %+v", events[1])
+	}
+
+	stats, ok := s.Stats("okx")
+	if !ok || stats.Pulls != 6 || stats.Spoofing != 1 || stats.Layering != 1 || !stats.LastEvent.Equal(now) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if len(s.Events()) != 2 || len(s.All()) != 1 {
+		t.Errorf("expected two events and one book, got %v and %v", s.Events(), s.All())
+	}
+
+	s.SetSymbol("ETHUSDT")
+	if stats, _ := s.Stats("okx"); stats.Pulls != 0 || len(s.Events()) != 0 {
+		t.Errorf("expected a symbol switch to reset the counters, got %+v", stats)
+	}
+}
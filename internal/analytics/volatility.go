@@ -0,0 +1,202 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// VolatilityConfig configures realized volatility tracking
+type VolatilityConfig struct {
+	Windows  []time.Duration // Trailing windows the volatility is computed over, default 1m, 5m and 1h
+	Interval time.Duration   // Time between mid samples, default 1s
+}
+
+// WindowVolatility is the realized volatility of mid over one trailing window
+type WindowVolatility struct {
+	Window     time.Duration
+	Returns    int             // Log returns between the samples in the window
+	Volatility decimal.Decimal // Square root of the sum of squared log returns, over the window
+	Annualized decimal.Decimal // Volatility scaled to a year from the time the returns span
+}
+
+// VolatilityStats is the realized volatility of the mid of a book, or of the CBBO
+type VolatilityStats struct {
+	Windows []WindowVolatility // Shortest window first
+}
+
+// midSample is the mid at one point in time
+type midSample struct {
+	at  time.Time
+	mid float64
+}
+
+// midSeries keeps the mid samples of one book, or of the CBBO, for the longest window
+type midSeries struct {
+	samples []midSample // Oldest first
+}
+
+// add appends a sample and drops those older than keep
+func (m *midSeries) add(at time.Time, mid float64, keep time.Duration) {
+	m.samples = append(m.samples, midSample{at: at, mid: mid})
+	cutoff := at.Add(-keep)
+	drop := 0
+	for drop < len(m.samples) && m.samples[drop].at.Before(cutoff) {
+		drop++
+	}
+	m.samples = m.samples[drop:]
+}
+
+// Volatility samples the mid of each registered book and of the CBBO across them, and
+// computes their realized volatility over trailing windows. Books register and unregister
+// like they do with a collector
+type Volatility struct {
+	config       VolatilityConfig
+	mu           sync.Mutex
+	books        map[string]*orderbook.OrderBook
+	series       map[string]*midSeries
+	consolidated midSeries
+}
+
+// NewVolatility creates a volatility tracker; call Start to sample the books
+func NewVolatility(config VolatilityConfig) *Volatility {
+	if len(config.Windows) == 0 {
+		config.Windows = []time.Duration{time.Minute, 5 * time.Minute, time.Hour}
+	}
+	config.Windows = append([]time.Duration(nil), config.Windows...)
+	sort.Slice(config.Windows, func(i, j int) bool { return config.Windows[i] < config.Windows[j] })
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	return &Volatility{
+		config: config,
+		books:  make(map[string]*orderbook.OrderBook),
+		series: make(map[string]*midSeries),
+	}
+}
+
+// RegisterOrderbook adds the book of an exchange to the books sampled
+func (v *Volatility) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.books[name] = ob
+	v.series[name] = &midSeries{}
+}
+
+// UnregisterOrderbook removes the book of an exchange and its samples
+func (v *Volatility) UnregisterOrderbook(name string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.books, name)
+	delete(v.series, name)
+}
+
+// Reset forgets the samples of every book and of the CBBO, for a symbol switch
+func (v *Volatility) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for name := range v.series {
+		v.series[name] = &midSeries{}
+	}
+	v.consolidated = midSeries{}
+}
+
+// Start samples the books every interval until ctx is done
+func (v *Volatility) Start(ctx context.Context) {
+	ticker := time.NewTicker(v.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			v.sample(now)
+		}
+	}
+}
+
+// sample records the mid of every initialized, uncrossed book and of the CBBO across them
+func (v *Volatility) sample(now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keep := v.config.Windows[len(v.config.Windows)-1]
+	books := make(map[string]*orderbook.BookSnapshot, len(v.books))
+	for name, ob := range v.books {
+		book := ob.Snapshot()
+		books[name] = book
+		if !book.Initialized || len(book.Bids) == 0 || len(book.Asks) == 0 || !book.Asks[0].Price.GreaterThan(book.Bids[0].Price) {
+			continue
+		}
+		v.series[name].add(now, book.MidPrice().InexactFloat64(), keep)
+	}
+	if cbbo, ok := ComputeCBBO(books); ok && !cbbo.Crossed() {
+		v.consolidated.add(now, cbbo.Mid().InexactFloat64(), keep)
+	}
+}
+
+// Stats returns the realized volatility of an exchange; ok is false before its book was sampled
+func (v *Volatility) Stats(name string) (VolatilityStats, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	series, ok := v.series[name]
+	if !ok || len(series.samples) == 0 {
+		return VolatilityStats{}, false
+	}
+	return v.stats(series), true
+}
+
+// All returns the realized volatility of every sampled book, by exchange
+func (v *Volatility) All() map[string]VolatilityStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	all := make(map[string]VolatilityStats, len(v.series))
+	for name, series := range v.series {
+		if len(series.samples) > 0 {
+			all[name] = v.stats(series)
+		}
+	}
+	return all
+}
+
+// Consolidated returns the realized volatility of the CBBO mid; ok is false before it was sampled
+func (v *Volatility) Consolidated() (VolatilityStats, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.consolidated.samples) == 0 {
+		return VolatilityStats{}, false
+	}
+	return v.stats(&v.consolidated), true
+}
+
+// stats sums the squared log returns of the samples within each window up to the newest
+// sample (v.mu must be held)
+func (v *Volatility) stats(series *midSeries) VolatilityStats {
+	newest := series.samples[len(series.samples)-1].at
+	stats := VolatilityStats{Windows: make([]WindowVolatility, 0, len(v.config.Windows))}
+	for _, window := range v.config.Windows {
+		cutoff := newest.Add(-window)
+		first := sort.Search(len(series.samples), func(i int) bool { return !series.samples[i].at.Before(cutoff) })
+		result := WindowVolatility{Window: window, Volatility: decimal.Zero, Annualized: decimal.Zero}
+		sumSq := 0.0
+		for i := first + 1; i < len(series.samples); i++ {
+			ret := math.Log(series.samples[i].mid / series.samples[i-1].mid)
+			sumSq += ret * ret
+			result.Returns++
+		}
+		if span := newest.Sub(series.samples[first].at); result.Returns > 0 && span > 0 {
+			realized := math.Sqrt(sumSq)
+			result.Volatility = decimal.NewFromFloat(realized)
+			result.Annualized = decimal.NewFromFloat(realized * math.Sqrt(float64(year)/float64(span)))
+		}
+		stats.Windows = append(stats.Windows, result)
+	}
+	return stats
+}
@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestVolatility(t *testing.T) {
+	v := NewVolatility(VolatilityConfig{Windows: []time.Duration{time.Hour, 2 * time.Second}})
+	okx := liveBook(t, []exchange.PriceLevel{{Price: "99", Quantity: "1"}}, []exchange.PriceLevel{{Price: "101", Quantity: "1"}})
+	v.RegisterOrderbook("okx", okx, exchange.Capabilities{})
+	v.RegisterOrderbook("bybit", liveBook(t, []exchange.PriceLevel{{Price: "98.5", Quantity: "1"}}, []exchange.PriceLevel{{Price: "100.5", Quantity: "1"}}), exchange.Capabilities{})
+
+	if _, ok := v.Stats("okx"); ok {
+		t.Fatal("expected no volatility before the first sample")
+	}
+	quote := func(bid, ask string) {
+		if err := okx.LoadSnapshot(&exchange.Snapshot{Bids: []exchange.PriceLevel{{Price: bid, Quantity: "1"}}, Asks: []exchange.PriceLevel{{Price: ask, Quantity: "1"}}}); err != nil {
+			t.Fatalf("LoadSnapshot() error: %v", err)
+		}
+		okx.ProcessBufferedEvents()
+	}
+	start := time.Now()
+	v.sample(start)
+	quote("100", "102")
+	v.sample(start.Add(time.Second))
+	quote("99", "101")
+	v.sample(start.Add(2 * time.Second))
+
+	stats, ok := v.Stats("okx")
+	if !ok || len(stats.Windows) != 2 || stats.Windows[0].Window != 2*time.Second {
+		t.Fatalf("expected both windows, shortest first, got %+v", stats)
+	}
+	want := math.Sqrt(math.Pow(math.Log(1.01), 2) + math.Pow(math.Log(100.0/101), 2))
+	for _, window := range stats.Windows {
+		if window.Returns != 2 || math.Abs(window.Volatility.InexactFloat64()-want) > 1e-12 {
+			t.Errorf("unexpected volatility over %s: %+v, want %v", window.Window, window, want)
+		}
+		annualized := want * math.Sqrt(float64(year)/float64(2*time.Second))
+		if math.Abs(window.Annualized.InexactFloat64()-annualized) > 1e-6 {
+			t.Errorf("unexpected annualized volatility over %s: %s, want %v", window.Window, window.Annualized, annualized)
+		}
+	}
+
+	// The short window drops the first return, an unchanged mid adds none to the sum
+	v.sample(start.Add(3 * time.Second))
+	stats, _ = v.Stats("okx")
+	if short := stats.Windows[0]; short.Returns != 2 || math.Abs(short.Volatility.InexactFloat64()-math.Abs(math.Log(100.0/101))) > 1e-12 {
+		t.Errorf("unexpected volatility over the short window %+v", short)
+	}
+	if long := stats.Windows[1]; long.Returns != 3 {
+		t.Errorf("expected every return in the long window, got %+v", long)
+	}
+
+	if bybit, ok := v.Stats("bybit"); !ok || !bybit.Windows[1].Volatility.Equal(decimal.Zero) {
+		t.Errorf("expected no volatility of an unchanged book, got %+v", bybit)
+	}
+	if _, ok := v.Consolidated(); !ok {
+		t.Error("expected the volatility of the CBBO mid")
+	}
+	if len(v.All()) != 2 {
+		t.Errorf("expected two books, got %v", v.All())
+	}
+	v.Reset()
+	if _, ok := v.Stats("okx"); ok {
+		t.Error("expected no volatility after a reset")
+	}
+}
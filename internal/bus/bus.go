@@ -0,0 +1,187 @@
+// Package bus carries the events of the exchange connections to their consumers
+//
+// Connections publish depth updates, trades, funding, their books and their state on a Bus;
+// the books, storage, publishers, analytics and the API subscribe to the topics they need,
+// so a new consumer subscribes once instead of being wired into every connection
+package bus
+
+import (
+	"sync"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/telemetry"
+)
+
+// Topic delivers events of one type to its subscribers
+// Handlers run on the publishing goroutine in the order they subscribed, so the events of
+// one connection reach every handler in order; handlers must not block
+type Topic[T any] struct {
+	mu       sync.RWMutex
+	handlers []*handler[T]
+}
+
+type handler[T any] struct {
+	fn func(T)
+}
+
+// Subscribe calls fn with every event published from now on; the returned function
+// unsubscribes it
+func (t *Topic[T]) Subscribe(fn func(T)) (unsubscribe func()) {
+	h := &handler[T]{fn: fn}
+	t.mu.Lock()
+	t.handlers = append(t.handlers, h)
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			for i, other := range t.handlers {
+				if other == h {
+					// Copy instead of deleting in place, a Publish may still range the old slice
+					t.handlers = append(t.handlers[:i:i], t.handlers[i+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// Publish calls every subscriber with event
+func (t *Topic[T]) Publish(event T) {
+	t.mu.RLock()
+	handlers := t.handlers
+	t.mu.RUnlock()
+	for _, h := range handlers {
+		h.fn(event)
+	}
+}
+
+// Subscribers returns the number of handlers subscribed
+func (t *Topic[T]) Subscribers() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.handlers)
+}
+
+// DepthEvent is a depth update of the book of an exchange
+type DepthEvent struct {
+	Exchange string
+	Symbol   string
+	Update   *exchange.DepthUpdate
+	Trace    *telemetry.Trace // Nil while telemetry is off; subscribers add their stages to it
+}
+
+// TradeEvent is a public trade on an exchange
+type TradeEvent struct {
+	Exchange string
+	Symbol   string
+	Trade    *exchange.Trade
+}
+
+// FundingEvent is a funding rate or open interest update of a perpetual exchange
+type FundingEvent struct {
+	Exchange string
+	Symbol   string
+	Funding  *exchange.Funding
+}
+
+// BookEvent announces the book of an exchange once it is ready, and again with Removed set
+// when its connection stops
+type BookEvent struct {
+	Exchange string
+	Symbol   string
+	Book     *orderbook.OrderBook
+	Caps     exchange.Capabilities
+	Feed     exchange.Exchange // Connection the book is fed by, for its health
+	Removed  bool
+}
+
+// ConnectionState is the state a connection reports on the bus
+type ConnectionState string
+
+const (
+	Connected    ConnectionState = "connected"    // Connected and subscribed
+	Failed       ConnectionState = "failed"       // Setup failed, Err says why
+	Disconnected ConnectionState = "disconnected" // The venue closed the stream
+	Stopped      ConnectionState = "stopped"      // Closed on shutdown, a symbol change or removal
+)
+
+// ConnectionEvent is a change in the state of the connection to an exchange
+type ConnectionEvent struct {
+	Exchange string
+	Symbol   string
+	State    ConnectionState
+	Err      error
+}
+
+// Bus holds a topic per event type
+type Bus struct {
+	Depth       Topic[DepthEvent]
+	Trades      Topic[TradeEvent]
+	Funding     Topic[FundingEvent]
+	Books       Topic[BookEvent]
+	Connections Topic[ConnectionEvent]
+}
+
+// New creates a bus without subscribers
+func New() *Bus {
+	return &Bus{}
+}
+
+// BookConsumer is implemented by everything that tracks the live books
+type BookConsumer interface {
+	RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities)
+	UnregisterOrderbook(name string)
+}
+
+// AttachBooks registers every book announced on the bus with consumer, and unregisters it
+// when it is removed
+func (b *Bus) AttachBooks(consumer BookConsumer) (unsubscribe func()) {
+	return b.Books.Subscribe(func(event BookEvent) {
+		if event.Removed {
+			consumer.UnregisterOrderbook(event.Exchange)
+			return
+		}
+		consumer.RegisterOrderbook(event.Exchange, event.Book, event.Caps)
+	})
+}
+
+// DrainDepth passes the depth updates of ex to apply, then publishes them, until its update
+// channel closes, which closes the returned channel
+// apply feeds the book of the connection, so subscribers only see updates the book has
+func (b *Bus) DrainDepth(name, symbol string, ex exchange.Exchange, apply func(DepthEvent)) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for update := range ex.Updates() {
+			trace := telemetry.StartUpdate(name, symbol, update.FinalUpdateID)
+			event := DepthEvent{Exchange: name, Symbol: symbol, Update: update, Trace: trace}
+			apply(event)
+			b.Depth.Publish(event)
+			trace.End()
+		}
+	}()
+	return done
+}
+
+// DrainTrades publishes the trades of ex, and the funding of perpetual venues, until their
+// channels close with the connection
+func (b *Bus) DrainTrades(name, symbol string, ex exchange.Exchange) {
+	if trades := ex.Trades(); trades != nil {
+		go func() {
+			for trade := range trades {
+				b.Trades.Publish(TradeEvent{Exchange: name, Symbol: symbol, Trade: trade})
+			}
+		}()
+	}
+	if source, ok := ex.(exchange.FundingSource); ok && source.Funding() != nil {
+		go func() {
+			for funding := range source.Funding() {
+				b.Funding.Publish(FundingEvent{Exchange: name, Symbol: symbol, Funding: funding})
+			}
+		}()
+	}
+}
@@ -0,0 +1,121 @@
+package bus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+)
+
+// feed is an exchange whose channels the test writes to
+type feed struct {
+	exchange.Exchange
+	updates chan *exchange.DepthUpdate
+	trades  chan *exchange.Trade
+}
+
+func (f *feed) Updates() <-chan *exchange.DepthUpdate { return f.updates }
+func (f *feed) Trades() <-chan *exchange.Trade        { return f.trades }
+
+type books struct {
+	registered map[string]*orderbook.OrderBook
+}
+
+func (b *books) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	b.registered[name] = ob
+}
+
+func (b *books) UnregisterOrderbook(name string) {
+	delete(b.registered, name)
+}
+
+func TestTopic(t *testing.T) {
+	var topic Topic[int]
+	var got []string
+	first := topic.Subscribe(func(n int) { got = append(got, "first") })
+	topic.Subscribe(func(n int) { got = append(got, "second") })
+
+	topic.Publish(1)
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Fatalf("expected both handlers in subscription order, got %v", got)
+	}
+
+	first()
+	first()
+	got = nil
+	topic.Publish(2)
+	if len(got) != 1 || got[0] != "second" || topic.Subscribers() != 1 {
+		t.Errorf("expected only the second handler after unsubscribing, got %v", got)
+	}
+}
+
+func TestAttachBooks(t *testing.T) {
+	b := New()
+	consumer := &books{registered: make(map[string]*orderbook.OrderBook)}
+	detach := b.AttachBooks(consumer)
+
+	ob := orderbook.New()
+	b.Books.Publish(BookEvent{Exchange: "okx", Book: ob})
+	if consumer.registered["okx"] != ob {
+		t.Fatalf("expected the okx book registered, got %v", consumer.registered)
+	}
+	b.Books.Publish(BookEvent{Exchange: "okx", Removed: true})
+	if len(consumer.registered) != 0 {
+		t.Fatalf("expected the okx book unregistered, got %v", consumer.registered)
+	}
+
+	detach()
+	b.Books.Publish(BookEvent{Exchange: "bybit", Book: ob})
+	if len(consumer.registered) != 0 {
+		t.Errorf("expected nothing registered once detached, got %v", consumer.registered)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	b := New()
+	f := &feed{updates: make(chan *exchange.DepthUpdate, 1), trades: make(chan *exchange.Trade, 1)}
+	depth := make(chan DepthEvent, 1)
+	trades := make(chan TradeEvent, 1)
+	var applied int64
+	b.Depth.Subscribe(func(event DepthEvent) {
+		// The book has the update before any subscriber sees it
+		if applied != event.Update.FinalUpdateID {
+			t.Errorf("expected update %d applied before it was published, applied %d", event.Update.FinalUpdateID, applied)
+		}
+		depth <- event
+	})
+	b.Trades.Subscribe(func(event TradeEvent) { trades <- event })
+
+	done := b.DrainDepth("okx", "BTCUSDT", f, func(event DepthEvent) { applied = event.Update.FinalUpdateID })
+	b.DrainTrades("okx", "BTCUSDT", f)
+	f.updates <- &exchange.DepthUpdate{FinalUpdateID: 7}
+	f.trades <- &exchange.Trade{TradeID: "1"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	select {
+	case event := <-depth:
+		if event.Exchange != "okx" || event.Symbol != "BTCUSDT" || event.Update.FinalUpdateID != 7 {
+			t.Errorf("unexpected depth event %+v", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the update")
+	}
+	select {
+	case event := <-trades:
+		if event.Exchange != "okx" || event.Trade.TradeID != "1" {
+			t.Errorf("unexpected trade event %+v", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the trade")
+	}
+
+	close(f.updates)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("expected the drain to end with the update channel")
+	}
+}
@@ -2,15 +2,25 @@ package collector
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"orderbook/internal/database"
-	"orderbook/internal/orderbook"
-	"orderbook/internal/types"
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/telemetry"
+	"github.com/imajinl/crypto-orderbook/internal/types"
 
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // DatabaseClient interface for different database implementations
@@ -21,113 +31,571 @@ type DatabaseClient interface {
 	Close() error
 }
 
+// DepthWriter is implemented by clients that can store the levels of each book
+// alongside its snapshot; see SetDepthLevels
+type DepthWriter interface {
+	InsertDepthSnapshots(depth []*database.DepthSnapshotAPI) error
+}
+
+// LatestWriter is implemented by clients that keep one row with the newest snapshot of
+// each book alongside the history; see SetLatest
+type LatestWriter interface {
+	UpsertLatestSnapshots(snapshots []*database.OrderbookSnapshotAPI) error
+}
+
 // Collector handles periodic data collection and storage
 type Collector struct {
-	dbClient   DatabaseClient
-	orderbooks map[string]*orderbook.OrderBook
-	mu         sync.RWMutex
-	symbol     string
-	interval   time.Duration
-	enabled    bool
+	dbClient     DatabaseClient
+	orderbooks   map[string]*orderbook.OrderBook
+	capabilities map[string]exchange.Capabilities
+	mu           sync.RWMutex
+	symbol       string
+	interval     time.Duration
+	retick       chan struct{} // Signals Start that the interval changed
+	enabled      bool
+	impactSizes  []decimal.Decimal // Order sizes (base units) simulated per snapshot
+	notionals    []decimal.Decimal // Order notionals (quote currency) simulated per snapshot
+	curveOffsets []decimal.Decimal // Offsets from mid at which the depth curve is stored
+	depthLevels  int               // Levels per side stored through a DepthWriter, 0 for none
+	depthBucket  decimal.Decimal   // Width of the price buckets stored levels are merged into, as a fraction of mid; zero keeps raw levels
+	dedup        *Dedup            // Skips snapshots unchanged since the last write, nil writes every one
+	lastWritten  map[string]*database.OrderbookSnapshotAPI
+	skipped      atomic.Int64
+	stored       atomic.Int64                       // Snapshots written
+	insertErrors atomic.Int64                       // Failed writes of snapshots, levels or latest rows
+	lastStored   atomic.Int64                       // Unix nanoseconds of the last successful snapshot write, 0 before it
+	latest       bool                               // Upserts every snapshot through a LatestWriter, including unchanged ones
+	consolidated bool                               // Also stores the CBBO across the books as analytics.ConsolidatedExchange
+	flow         *analytics.Flow                    // Taker flow attached to each snapshot, nil for none
+	funding      *analytics.Funding                 // Funding and open interest attached to perpetual snapshots, nil for none
+	basis        *analytics.Basis                   // Spot-perp basis attached to perpetual snapshots, nil for none
+	resiliency   *analytics.Resiliency              // Replenishment of the depth near mid attached to each snapshot, nil for none
+	share        *analytics.MarketShare             // Share of the consolidated liquidity attached to each snapshot, nil for none
+	volatility   *analytics.Volatility              // Realized volatility attached to each snapshot and the consolidated row, nil for none
+	fees         *analytics.Fees                    // Fee schedules the effective spread attached to each snapshot is computed with, nil for none
+	index        *analytics.Index                   // Index price attached to the consolidated row, nil for none
+	touch        map[string]*orderbook.TouchTracker // Size at the best bid and ask between collections per book, nil when not tracked
+	quiet        atomic.Bool                        // Only log failures, for collectors running at short intervals
+}
+
+// logger returns the logger of collectors
+func logger() *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, "collector")
 }
 
 // NewCollector creates a new data collector
 func NewCollector(dbClient DatabaseClient, symbol string, interval time.Duration) *Collector {
 	return &Collector{
-		dbClient:   dbClient,
-		orderbooks: make(map[string]*orderbook.OrderBook),
-		symbol:     symbol,
-		interval:   interval,
-		enabled:    true,
+		dbClient:     dbClient,
+		orderbooks:   make(map[string]*orderbook.OrderBook),
+		capabilities: make(map[string]exchange.Capabilities),
+		lastWritten:  make(map[string]*database.OrderbookSnapshotAPI),
+		symbol:       symbol,
+		interval:     interval,
+		retick:       make(chan struct{}, 1),
+		enabled:      true,
 	}
 }
 
 // RegisterOrderbook registers an orderbook for data collection
-func (c *Collector) RegisterOrderbook(exchange string, ob *orderbook.OrderBook) {
+// The feed capabilities decide which liquidity bands can be stored reliably
+func (c *Collector) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.orderbooks[exchange] = ob
-	log.Printf("[Collector] Registered orderbook for exchange: %s", exchange)
+	if c.touch != nil {
+		if tracker, ok := c.touch[name]; ok {
+			c.orderbooks[name].UntrackTouch(tracker)
+		}
+		c.touch[name] = ob.TrackTouch()
+	}
+	c.orderbooks[name] = ob
+	c.capabilities[name] = caps
+	logger().Info("Registered orderbook", logging.KeyExchange, name, logging.KeySymbol, c.symbol)
 }
 
 // UnregisterOrderbook removes an orderbook from data collection
 func (c *Collector) UnregisterOrderbook(exchange string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if tracker, ok := c.touch[exchange]; ok {
+		c.orderbooks[exchange].UntrackTouch(tracker)
+		delete(c.touch, exchange)
+	}
 	delete(c.orderbooks, exchange)
-	log.Printf("[Collector] Unregistered orderbook for exchange: %s", exchange)
+	delete(c.capabilities, exchange)
+	delete(c.lastWritten, exchange)
+	logger().Info("Unregistered orderbook", logging.KeyExchange, exchange, logging.KeySymbol, c.symbol)
 }
 
 // Start begins the data collection process
 func (c *Collector) Start(ctx context.Context) {
-	ticker := time.NewTicker(c.interval)
+	c.mu.RLock()
+	symbol, interval := c.symbol, c.interval
+	c.mu.RUnlock()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	log.Printf("[Collector] Starting data collection for %s every %v", c.symbol, c.interval)
+	logger().Info("Starting data collection", logging.KeySymbol, symbol, "interval", interval)
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("[Collector] Data collection stopped")
+			logger().Info("Data collection stopped")
 			return
+		case <-c.retick:
+			ticker.Reset(c.Interval())
 		case <-ticker.C:
-			if c.enabled {
+			if c.Enabled() {
 				c.collectAndStore()
 			}
 		}
 	}
 }
 
+// SetImpactSizes selects the order sizes, in base units, whose market impact is stored with each snapshot
+func (c *Collector) SetImpactSizes(sizes []decimal.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.impactSizes = sizes
+}
+
+// SetSlippageNotionals selects the order notionals, in quote currency, whose slippage is stored with each snapshot
+func (c *Collector) SetSlippageNotionals(notionals []decimal.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notionals = notionals
+}
+
+// SetCurveOffsets selects the offsets from mid (0.001 = 10bp) at which the cumulative depth curve is stored
+func (c *Collector) SetCurveOffsets(offsets []decimal.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.curveOffsets = offsets
+}
+
+// SetDepthLevels stores the top levels per side of every book with each snapshot when the
+// client is a DepthWriter; 0 stores none
+func (c *Collector) SetDepthLevels(levels int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.depthLevels = levels
+}
+
+// SetDepthBucket merges stored levels into price buckets of width bucket * mid (0.0005 = 5bp),
+// rounded to a 1, 2 or 5 step, before the top levels are taken, so each stored level covers a
+// wider, stable price range; zero stores raw levels
+func (c *Collector) SetDepthBucket(bucket decimal.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.depthBucket = bucket
+}
+
+// SetDedup skips storing the snapshot of a book, and its levels, while it has not changed
+// beyond the thresholds since the last snapshot written for that exchange; nil stores every one
+func (c *Collector) SetDedup(dedup *Dedup) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dedup = dedup
+	clear(c.lastWritten)
+}
+
+// SetLatest keeps the newest snapshot of every book in the client's latest table when it
+// is a LatestWriter. Snapshots skipped as unchanged are still upserted, so the row's
+// timestamp shows the book is live
+func (c *Collector) SetLatest(latest bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latest = latest
+}
+
+// SetConsolidated also stores the consolidated best bid and offer across the registered books
+// with each collection, as a row of exchange analytics.ConsolidatedExchange
+func (c *Collector) SetConsolidated(consolidated bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.consolidated = consolidated
+}
+
+// SetFlow attaches the taker flow of each exchange to its snapshots, and the consolidated
+// flow to the consolidated row
+func (c *Collector) SetFlow(flow *analytics.Flow) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flow = flow
+}
+
+// SetFunding attaches the funding and open interest of each perpetual exchange to its snapshots
+func (c *Collector) SetFunding(funding *analytics.Funding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.funding = funding
+}
+
+// SetBasis attaches the basis of each perpetual over its spot book to its snapshots
+func (c *Collector) SetBasis(basis *analytics.Basis) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.basis = basis
+}
+
+// SetResiliency attaches how quickly each book replenishes after large depletions to its snapshots
+func (c *Collector) SetResiliency(resiliency *analytics.Resiliency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resiliency = resiliency
+}
+
+// SetMarketShare attaches the share of each book in the liquidity and best quotes across the books to its snapshots
+func (c *Collector) SetMarketShare(share *analytics.MarketShare) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.share = share
+}
+
+// SetVolatility attaches the realized volatility of each book to its snapshots, and that
+// of the CBBO mid to the consolidated row
+func (c *Collector) SetVolatility(volatility *analytics.Volatility) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.volatility = volatility
+}
+
+// SetFees attaches the spread of each book after the fees of its venue to its snapshots
+func (c *Collector) SetFees(fees *analytics.Fees) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fees = fees
+}
+
+// SetIndex attaches the index price across the books to the consolidated row
+func (c *Collector) SetIndex(index *analytics.Index) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.index = index
+}
+
+// SetTouch tracks the size at the best bid and ask of every book between collections and
+// attaches it to each snapshot
+func (c *Collector) SetTouch(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if enabled == (c.touch != nil) {
+		return
+	}
+	if !enabled {
+		for name, tracker := range c.touch {
+			c.orderbooks[name].UntrackTouch(tracker)
+		}
+		c.touch = nil
+		return
+	}
+	c.touch = make(map[string]*orderbook.TouchTracker, len(c.orderbooks))
+	for name, ob := range c.orderbooks {
+		c.touch[name] = ob.TrackTouch()
+	}
+}
+
+// Skipped returns the number of snapshots not stored because they were unchanged
+func (c *Collector) Skipped() int64 {
+	return c.skipped.Load()
+}
+
+// SetQuiet stops logging every successful collection, leaving only failures
+func (c *Collector) SetQuiet(quiet bool) {
+	c.quiet.Store(quiet)
+}
+
+// SetSymbol changes the symbol stored with snapshots, for books registered after a symbol switch
+func (c *Collector) SetSymbol(symbol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.symbol = symbol
+	c.lastWritten = make(map[string]*database.OrderbookSnapshotAPI)
+}
+
 // SetEnabled enables or disables data collection
 func (c *Collector) SetEnabled(enabled bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.enabled = enabled
-	log.Printf("[Collector] Data collection %s", map[bool]string{true: "enabled", false: "disabled"}[enabled])
+	logger().Info("Data collection " + map[bool]string{true: "enabled", false: "disabled"}[enabled])
+}
+
+// Enabled reports whether data collection is enabled
+func (c *Collector) Enabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.enabled
+}
+
+// SetInterval changes the interval between collections, taking effect from the next one
+func (c *Collector) SetInterval(interval time.Duration) {
+	c.mu.Lock()
+	c.interval = interval
+	c.mu.Unlock()
+	select {
+	case c.retick <- struct{}{}:
+	default:
+	}
+}
+
+// Interval returns the interval between collections
+func (c *Collector) Interval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.interval
 }
 
 // collectAndStore collects data from all registered orderbooks and stores it
 func (c *Collector) collectAndStore() {
+	c.CollectAt(time.Now())
+}
+
+// CollectAt collects and stores the registered orderbooks once, stamping the snapshots with
+// at instead of the wall clock, e.g. to backfill snapshots from a recording
+func (c *Collector) CollectAt(at time.Time) {
 	c.mu.RLock()
 	orderbooks := make(map[string]*orderbook.OrderBook)
 	for k, v := range c.orderbooks {
 		orderbooks[k] = v
 	}
+	capabilities := make(map[string]exchange.Capabilities)
+	for k, v := range c.capabilities {
+		capabilities[k] = v
+	}
+	impactSizes := c.impactSizes
+	notionals := c.notionals
+	curveOffsets := c.curveOffsets
+	depthLevels := c.depthLevels
+	depthBucket := c.depthBucket
+	dedup := c.dedup
+	lastWritten := make(map[string]*database.OrderbookSnapshotAPI, len(c.lastWritten))
+	for k, v := range c.lastWritten {
+		lastWritten[k] = v
+	}
+	latestEnabled := c.latest
+	consolidated := c.consolidated
+	flow := c.flow
+	funding := c.funding
+	basis := c.basis
+	resiliency := c.resiliency
+	share := c.share
+	volatility := c.volatility
+	fees := c.fees
+	index := c.index
+	touch := make(map[string]*orderbook.TouchTracker, len(c.touch))
+	for k, v := range c.touch {
+		touch[k] = v
+	}
+	symbol := c.symbol
 	c.mu.RUnlock()
 
+	depthWriter, _ := c.dbClient.(DepthWriter)
+	if depthLevels <= 0 {
+		depthWriter = nil
+	}
+	latestWriter, _ := c.dbClient.(LatestWriter)
+	if !latestEnabled {
+		latestWriter = nil
+	}
+
 	if len(orderbooks) == 0 {
-		log.Println("[Collector] No orderbooks registered, skipping collection")
+		logger().Info("No orderbooks registered, skipping collection")
 		return
 	}
 
+	tr := telemetry.StartCollect(symbol)
+	defer tr.End()
+	output := attribute.String("output", fmt.Sprintf("%T", c.dbClient))
+
 	var snapshots []*database.OrderbookSnapshotAPI
+	var depth []*database.DepthSnapshotAPI
+	var latest []*database.OrderbookSnapshotAPI
+	books := make(map[string]*orderbook.BookSnapshot, len(orderbooks))
+	var shares map[string]analytics.MarketShareStats
+	if share != nil {
+		// Shares span every book, so they are computed once per collection
+		shares = share.All()
+	}
 	successCount := 0
+	skipped := 0
 
-	for exchange, ob := range orderbooks {
-		if !ob.IsInitialized() {
-			log.Printf("[Collector] Skipping %s - orderbook not initialized", exchange)
+	for name, ob := range orderbooks {
+		endStats := tr.Stage(telemetry.StageStats, attribute.String("exchange", name), output)
+		// One copy per book keeps stats and levels consistent with each other
+		book := ob.Snapshot()
+		if !book.Initialized {
+			if !c.quiet.Load() {
+				logger().Info("Skipping orderbook, not initialized", logging.KeyExchange, name, logging.KeySymbol, symbol)
+			}
+			endStats(nil)
 			continue
 		}
+		books[name] = book
 
-		stats := ob.GetStats()
-		snapshot := c.createSnapshot(exchange, stats, ob)
+		snapshot := c.createSnapshot(name, symbol, book, capabilities[name])
+		snapshot.Timestamp = at
+		snapshot.Impact = impactFor(impactSizes, ob.PriceForSize)
+		snapshot.Slippage = impactFor(notionals, ob.PriceForNotional)
+		snapshot.DepthCurve = curveFor(book, curveOffsets, capabilities[name])
+		if flow != nil {
+			if stats, ok := flow.Stats(name); ok {
+				snapshot.Flow = NewFlow(stats)
+			}
+		}
+		if funding != nil {
+			if stats, ok := funding.Stats(name); ok {
+				snapshot.Funding = NewFunding(stats, at)
+			}
+		}
+		if basis != nil {
+			if stats, ok := basis.Stats(name); ok {
+				snapshot.Basis = NewBasis(stats)
+			}
+		}
+		if resiliency != nil {
+			if stats, ok := resiliency.Stats(name); ok {
+				snapshot.Resiliency = NewResiliency(stats)
+			}
+		}
+		if stats, ok := shares[name]; ok {
+			snapshot.MarketShare = NewMarketShare(stats)
+		}
+		if volatility != nil {
+			if stats, ok := volatility.Stats(name); ok {
+				snapshot.Volatility = NewVolatility(stats)
+			}
+		}
+		if fees != nil {
+			if spread, ok := analytics.ComputeEffectiveSpread(book, fees.For(name)); ok {
+				snapshot.EffectiveSpread = NewEffectiveSpread(spread)
+			}
+		}
+		if tracker, ok := touch[name]; ok {
+			if stats, ok := tracker.Take(); ok {
+				snapshot.Touch = NewTouch(stats)
+			}
+		}
+		endStats(nil)
+		if latestWriter != nil {
+			latest = append(latest, snapshot)
+		}
+		if dedup != nil && !dedup.Changed(lastWritten[name], snapshot) {
+			skipped++
+			continue
+		}
 		snapshots = append(snapshots, snapshot)
+		if depthWriter != nil {
+			depth = append(depth, depthFor(snapshot, book, depthLevels, depthBucket))
+		}
 		successCount++
 	}
 
+	if cbbo, ok := analytics.ComputeCBBO(books); ok && consolidated {
+		snapshot := NewConsolidatedSnapshot(symbol, cbbo)
+		snapshot.Timestamp = at
+		if flow != nil {
+			if stats, ok := flow.Consolidated(); ok {
+				snapshot.Flow = NewFlow(stats)
+			}
+		}
+		if volatility != nil {
+			if stats, ok := volatility.Consolidated(); ok {
+				snapshot.Volatility = NewVolatility(stats)
+			}
+		}
+		if index != nil {
+			if price, ok := index.Compute(); ok {
+				snapshot.IndexPrice = NewIndexPrice(price)
+			}
+		}
+		if latestWriter != nil {
+			latest = append(latest, snapshot)
+		}
+		if dedup != nil && !dedup.Changed(lastWritten[analytics.ConsolidatedExchange], snapshot) {
+			skipped++
+		} else {
+			snapshots = append(snapshots, snapshot)
+			successCount++
+		}
+	}
+
+	if len(depth) > 0 {
+		endStore := tr.Stage(telemetry.StageStore, attribute.String("write", "levels"), output)
+		err := depthWriter.InsertDepthSnapshots(depth)
+		endStore(err)
+		if err != nil {
+			c.insertErrors.Add(1)
+			logger().Error("Failed to insert levels", logging.KeySymbol, symbol, "books", len(depth), "error", err)
+		}
+	}
+
 	if len(snapshots) > 0 {
-		if err := c.dbClient.InsertOrderbookSnapshotsBatch(snapshots); err != nil {
-			log.Printf("[Collector] Failed to insert batch of %d snapshots: %v", len(snapshots), err)
+		endStore := tr.Stage(telemetry.StageStore, attribute.String("write", "snapshots"), output)
+		err := c.dbClient.InsertOrderbookSnapshotsBatch(snapshots)
+		endStore(err)
+		if err != nil {
+			c.insertErrors.Add(1)
+			logger().Error("Failed to insert batch of snapshots", logging.KeySymbol, symbol, "snapshots", len(snapshots), "error", err)
 		} else {
-			log.Printf("[Collector] Successfully stored %d snapshots", successCount)
+			c.stored.Add(int64(len(snapshots)))
+			c.lastStored.Store(time.Now().UnixNano())
+			if dedup != nil {
+				c.remember(snapshots)
+			}
+			if !c.quiet.Load() {
+				logger().Info("Stored snapshots", logging.KeySymbol, symbol, "snapshots", successCount)
+			}
+		}
+	} else if !c.quiet.Load() && skipped == 0 {
+		logger().Info("No valid snapshots to store")
+	}
+
+	if len(latest) > 0 {
+		endStore := tr.Stage(telemetry.StageStore, attribute.String("write", "latest"), output)
+		err := latestWriter.UpsertLatestSnapshots(latest)
+		endStore(err)
+		if err != nil {
+			c.insertErrors.Add(1)
+			logger().Error("Failed to update latest snapshots", logging.KeySymbol, symbol, "books", len(latest), "error", err)
+		}
+	}
+
+	if skipped > 0 {
+		c.skipped.Add(int64(skipped))
+		if !c.quiet.Load() {
+			logger().Info("Skipped unchanged snapshots", logging.KeySymbol, symbol, "snapshots", skipped)
+		}
+	}
+}
+
+// remember keeps the snapshots written as the baseline of the next comparison, for books
+// still registered
+func (c *Collector) remember(snapshots []*database.OrderbookSnapshotAPI) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range snapshots {
+		if _, ok := c.orderbooks[s.Exchange]; ok || s.Exchange == analytics.ConsolidatedExchange {
+			c.lastWritten[s.Exchange] = s
 		}
-	} else {
-		log.Println("[Collector] No valid snapshots to store")
 	}
 }
 
-// createSnapshot creates a database snapshot from orderbook stats
-func (c *Collector) createSnapshot(exchange string, stats types.Stats, ob *orderbook.OrderBook) *database.OrderbookSnapshotAPI {
+// createSnapshot creates a database snapshot from a point-in-time copy of the book
+func (c *Collector) createSnapshot(name, symbol string, book *orderbook.BookSnapshot, caps exchange.Capabilities) *database.OrderbookSnapshotAPI {
+	// Log orderbook data for debugging/monitoring (optional); levels are stored through a DepthWriter
+	if !c.quiet.Load() {
+		logger().Debug("Collected book", logging.KeyExchange, name, logging.KeySymbol, symbol, "bids", len(book.Bids), "asks", len(book.Asks))
+	}
+	return NewSnapshot(name, symbol, book, caps)
+}
+
+// NewSnapshot converts a point-in-time copy of the book of symbol on exchange name into the
+// snapshot stored, published and cached; impact and the depth curve are left unset
+// Bands a depth-limited feed does not reach are NULL rather than understated
+func NewSnapshot(name, symbol string, book *orderbook.BookSnapshot, caps exchange.Capabilities) *database.OrderbookSnapshotAPI {
+	stats := book.Stats
+
 	// Calculate mid price
 	var midPrice *float64
 	if !stats.BestBid.IsZero() && !stats.BestAsk.IsZero() && stats.BestAsk.GreaterThan(stats.BestBid) {
@@ -141,53 +609,381 @@ func (c *Collector) createSnapshot(exchange string, stats types.Stats, ob *order
 	bestAsk := stats.BestAsk.InexactFloat64()
 	spread := stats.Spread.InexactFloat64()
 
-	bidLiq05 := stats.BidLiquidity05Pct.InexactFloat64()
-	askLiq05 := stats.AskLiquidity05Pct.InexactFloat64()
-	bidLiq2 := stats.BidLiquidity2Pct.InexactFloat64()
-	askLiq2 := stats.AskLiquidity2Pct.InexactFloat64()
-	bidLiq10 := stats.BidLiquidity10Pct.InexactFloat64()
-	askLiq10 := stats.AskLiquidity10Pct.InexactFloat64()
+	// Configured bands fill their dedicated column when one exists, the rest go to liquidity_bands
+	var liq05, liq2, liq10 database.BandLiquidityAPI
+	var extraBands map[string]database.BandLiquidityAPI
+	for _, band := range stats.Bands {
+		covered := caps.MaxDepth == 0 || book.CoversDepth(band.Pct.InexactFloat64())
+		value := database.BandLiquidityAPI{
+			Bid:         optionalValue(band.Bid, covered),
+			Ask:         optionalValue(band.Ask, covered),
+			BidNotional: optionalValue(band.BidNotional, covered),
+			AskNotional: optionalValue(band.AskNotional, covered),
+		}
+
+		switch {
+		case band.Pct.Equal(types.DefaultLiquidityBands[0]):
+			liq05 = value
+		case band.Pct.Equal(types.DefaultLiquidityBands[1]):
+			liq2 = value
+		case band.Pct.Equal(types.DefaultLiquidityBands[2]):
+			liq10 = value
+		default:
+			if extraBands == nil {
+				extraBands = make(map[string]database.BandLiquidityAPI)
+			}
+			extraBands[types.BandLabel(band.Pct)] = value
+		}
+	}
 	totalBids := stats.TotalBidsQty.InexactFloat64()
 	totalAsks := stats.TotalAsksQty.InexactFloat64()
+	totalBidsNotional := stats.TotalBidsNotional.InexactFloat64()
+	totalAsksNotional := stats.TotalAsksNotional.InexactFloat64()
+
+	var rolling *database.RollingAPI
+	if stats.Rolling.Samples > 0 {
+		rolling = &database.RollingAPI{
+			WindowSeconds:       stats.Rolling.Window.Seconds(),
+			Samples:             stats.Rolling.Samples,
+			AvgSpread:           stats.Rolling.AvgSpread.InexactFloat64(),
+			SpreadVolatility:    stats.Rolling.SpreadVolatility.InexactFloat64(),
+			MidReturnVolatility: stats.Rolling.MidReturnVolatility.InexactFloat64(),
+		}
+	}
 
-	// Collect bid/ask data for logging (not uploaded to database)
-	bidLevels := c.convertPriceLevels(ob.GetBids())
-	askLevels := c.convertPriceLevels(ob.GetAsks())
+	var ofi map[string]database.OFIAPI
+	if len(stats.OFI) > 0 {
+		ofi = make(map[string]database.OFIAPI, len(stats.OFI))
+		for _, window := range stats.OFI {
+			ofi[WindowLabel(window.Window)] = database.OFIAPI{OFI: window.OFI.InexactFloat64(), Updates: window.Updates}
+		}
+	}
 
-	// Log orderbook data for debugging/monitoring (optional)
-	log.Printf("[Collector] %s: %d bids, %d asks", exchange, len(bidLevels), len(askLevels))
+	microprice := optionalValue(stats.Microprice, !stats.Microprice.IsZero())
+	imbalance := make(map[string]database.ImbalanceAPI, len(stats.Imbalances))
+	for _, depth := range stats.Imbalances {
+		imbalance[strconv.Itoa(depth.Levels)] = database.ImbalanceAPI{
+			Imbalance:   depth.Imbalance.InexactFloat64(),
+			WeightedMid: depth.WeightedMid.InexactFloat64(),
+		}
+	}
 
 	return &database.OrderbookSnapshotAPI{
-		Exchange:          exchange,
-		Symbol:            c.symbol,
+		Exchange:          name,
+		Symbol:            symbol,
 		Timestamp:         time.Now(),
 		BestBid:           &bestBid,
 		BestAsk:           &bestAsk,
 		MidPrice:          midPrice,
 		Spread:            &spread,
-		BidLiquidity05Pct: &bidLiq05,
-		AskLiquidity05Pct: &askLiq05,
-		BidLiquidity2Pct:  &bidLiq2,
-		AskLiquidity2Pct:  &askLiq2,
-		BidLiquidity10Pct: &bidLiq10,
-		AskLiquidity10Pct: &askLiq10,
+		BidLiquidity05Pct: liq05.Bid,
+		AskLiquidity05Pct: liq05.Ask,
+		BidLiquidity2Pct:  liq2.Bid,
+		AskLiquidity2Pct:  liq2.Ask,
+		BidLiquidity10Pct: liq10.Bid,
+		AskLiquidity10Pct: liq10.Ask,
 		TotalBidsQty:      &totalBids,
 		TotalAsksQty:      &totalAsks,
+		BidNotional05Pct:  liq05.BidNotional,
+		AskNotional05Pct:  liq05.AskNotional,
+		BidNotional2Pct:   liq2.BidNotional,
+		AskNotional2Pct:   liq2.AskNotional,
+		BidNotional10Pct:  liq10.BidNotional,
+		AskNotional10Pct:  liq10.AskNotional,
+		TotalBidsNotional: &totalBidsNotional,
+		TotalAsksNotional: &totalAsksNotional,
+		Microprice:        microprice,
+		Imbalance:         imbalance,
+		Rolling:           rolling,
+		OFI:               ofi,
+		LiquidityBands:    extraBands,
+	}
+}
+
+// NewConsolidatedSnapshot converts the CBBO of symbol into a snapshot of exchange
+// analytics.ConsolidatedExchange; only the top of book is set
+func NewConsolidatedSnapshot(symbol string, cbbo analytics.CBBO) *database.OrderbookSnapshotAPI {
+	bestBid := cbbo.BestBid.InexactFloat64()
+	bestAsk := cbbo.BestAsk.InexactFloat64()
+	spread := cbbo.Spread.InexactFloat64()
+	mid := cbbo.Mid().InexactFloat64()
+	return &database.OrderbookSnapshotAPI{
+		Exchange:  analytics.ConsolidatedExchange,
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+		BestBid:   &bestBid,
+		BestAsk:   &bestAsk,
+		MidPrice:  &mid,
+		Spread:    &spread,
+		Imbalance: map[string]database.ImbalanceAPI{},
+	}
+}
+
+// NewFlow converts taker flow into its stored form
+func NewFlow(stats analytics.FlowStats) *database.FlowAPI {
+	return &database.FlowAPI{
+		WindowSeconds: stats.Window.Seconds(),
+		CVD:           stats.CVD.InexactFloat64(),
+		BuyVolume:     stats.BuyVolume.InexactFloat64(),
+		SellVolume:    stats.SellVolume.InexactFloat64(),
+		BuyRatio:      stats.BuyRatio().InexactFloat64(),
+		Trades:        stats.Trades,
+		LargeTrades:   stats.LargeTrades,
+	}
+}
+
+// NewFunding converts funding and open interest into its stored form, counting the time to
+// the next funding from now
+func NewFunding(stats analytics.FundingStats, now time.Time) *database.FundingAPI {
+	funding := &database.FundingAPI{
+		Rate:                 stats.Rate.InexactFloat64(),
+		MarkPrice:            stats.MarkPrice.InexactFloat64(),
+		IndexPrice:           stats.IndexPrice.InexactFloat64(),
+		OpenInterest:         stats.OpenInterest.InexactFloat64(),
+		OpenInterestNotional: stats.OpenInterestNotional().InexactFloat64(),
+		OpenInterestChange:   stats.OpenInterestChange.InexactFloat64(),
+		WindowSeconds:        stats.Window.Seconds(),
+	}
+	if stats.NextFunding.After(now) {
+		funding.SecondsToFunding = stats.NextFunding.Sub(now).Seconds()
+	}
+	return funding
+}
+
+// NewBasis converts a spot-perp basis into its stored form
+func NewBasis(stats analytics.BasisStats) *database.BasisAPI {
+	return &database.BasisAPI{
+		SpotExchange:      stats.Spot,
+		SpotMid:           stats.SpotMid.InexactFloat64(),
+		BasisBps:          stats.BasisBps().InexactFloat64(),
+		AnnualizedBasis:   stats.AnnualizedBasis.InexactFloat64(),
+		AnnualizedFunding: stats.AnnualizedFunding.InexactFloat64(),
 	}
 }
 
-// convertPriceLevels converts orderbook price levels to a JSON-serializable format
-func (c *Collector) convertPriceLevels(levels map[string]types.PriceLevel) []map[string]interface{} {
-	var result []map[string]interface{}
-	for price, level := range levels {
-		result = append(result, map[string]interface{}{
-			"price":    price,
-			"quantity": level.Quantity.String(),
-		})
+// NewResiliency converts the resiliency of a book into its stored form
+func NewResiliency(stats analytics.ResiliencyStats) *database.ResiliencyAPI {
+	return &database.ResiliencyAPI{
+		Score:               stats.Score.InexactFloat64(),
+		Depletions:          stats.Depletions,
+		Recovered:           stats.Recovered,
+		MeanRecoverySeconds: stats.MeanRecovery.Seconds(),
+		Replenishment:       stats.Replenishment.InexactFloat64(),
+		BidDepth:            stats.BidDepth.InexactFloat64(),
+		AskDepth:            stats.AskDepth.InexactFloat64(),
+		WindowSeconds:       stats.Window.Seconds(),
+	}
+}
+
+// NewMarketShare converts the market share of a book into its stored form
+func NewMarketShare(stats analytics.MarketShareStats) *database.MarketShareAPI {
+	share := &database.MarketShareAPI{
+		BestBid:        stats.BestBid.InexactFloat64(),
+		BestAsk:        stats.BestAsk.InexactFloat64(),
+		SampledSeconds: stats.Sampled.Seconds(),
+	}
+	if len(stats.Bands) > 0 {
+		share.Bands = make(map[string]database.BandShareAPI, len(stats.Bands))
+		for _, band := range stats.Bands {
+			share.Bands[types.BandLabel(band.Pct)] = database.BandShareAPI{
+				Bid:   band.Bid.InexactFloat64(),
+				Ask:   band.Ask.InexactFloat64(),
+				Total: band.Total.InexactFloat64(),
+			}
+		}
+	}
+	return share
+}
+
+// NewVolatility converts the realized volatility of a book into its stored form, keyed by window
+func NewVolatility(stats analytics.VolatilityStats) map[string]database.VolatilityAPI {
+	volatility := make(map[string]database.VolatilityAPI, len(stats.Windows))
+	for _, window := range stats.Windows {
+		volatility[WindowLabel(window.Window)] = database.VolatilityAPI{
+			Volatility: window.Volatility.InexactFloat64(),
+			Annualized: window.Annualized.InexactFloat64(),
+			Returns:    window.Returns,
+		}
+	}
+	return volatility
+}
+
+// NewEffectiveSpread converts the fee-adjusted spread of a book into its stored form, in basis points
+func NewEffectiveSpread(spread analytics.EffectiveSpread) *database.EffectiveSpreadAPI {
+	bps := decimal.NewFromInt(10000)
+	return &database.EffectiveSpreadAPI{
+		RawBps:      spread.Raw.Mul(bps).InexactFloat64(),
+		TakerBps:    spread.Taker.Mul(bps).InexactFloat64(),
+		MakerBps:    spread.Maker.Mul(bps).InexactFloat64(),
+		MakerFeeBps: spread.Fees.Maker.Mul(bps).InexactFloat64(),
+		TakerFeeBps: spread.Fees.Taker.Mul(bps).InexactFloat64(),
+	}
+}
+
+// NewIndexPrice converts an index price into its stored form
+func NewIndexPrice(index analytics.IndexPrice) *database.IndexAPI {
+	stored := &database.IndexAPI{
+		Price:     index.Price.InexactFloat64(),
+		Weighting: string(index.Weighting),
+		Venues:    index.Venues,
+		Weights:   make(map[string]float64, index.Venues),
+	}
+	for _, component := range index.Components {
+		if component.Excluded == "" {
+			stored.Weights[component.Exchange] = component.Weight.InexactFloat64()
+		}
+	}
+	return stored
+}
+
+// NewTouch converts the size at the best bid and ask over an interval into its stored form
+func NewTouch(stats types.TouchStats) *database.TouchAPI {
+	return &database.TouchAPI{
+		BidMean:         stats.Bid.Mean.InexactFloat64(),
+		BidMin:          stats.Bid.Min.InexactFloat64(),
+		BidTimeWeighted: stats.Bid.TimeWeighted.InexactFloat64(),
+		AskMean:         stats.Ask.Mean.InexactFloat64(),
+		AskMin:          stats.Ask.Min.InexactFloat64(),
+		AskTimeWeighted: stats.Ask.TimeWeighted.InexactFloat64(),
+		Updates:         stats.Updates,
+	}
+}
+
+// WindowLabel formats a window without its trailing zero units, e.g. 5m or 1h30m
+func WindowLabel(window time.Duration) string {
+	label := window.String()
+	if strings.HasSuffix(label, "m0s") {
+		label = strings.TrimSuffix(label, "0s")
+	}
+	if strings.HasSuffix(label, "h0m") {
+		label = strings.TrimSuffix(label, "0m")
+	}
+	return label
+}
+
+// impactFor simulates market orders of each size on both sides of the book, walking it with
+// execute, e.g. PriceForSize for sizes in base units or PriceForNotional for notionals
+func impactFor(sizes []decimal.Decimal, execute func(side types.Side, size decimal.Decimal) types.Execution) map[string]database.ImpactAPI {
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	impact := make(map[string]database.ImpactAPI, len(sizes))
+	for _, size := range sizes {
+		buy := execute(types.Buy, size)
+		sell := execute(types.Sell, size)
+		impact[size.String()] = database.ImpactAPI{
+			BuyVWAP:      optionalValue(buy.VWAP, buy.Complete),
+			BuySlippage:  optionalValue(buy.Slippage, buy.Complete),
+			SellVWAP:     optionalValue(sell.VWAP, sell.Complete),
+			SellSlippage: optionalValue(sell.Slippage, sell.Complete),
+		}
+	}
+	return impact
+}
+
+// curveFor samples the cumulative depth curve at each offset, leaving offsets a depth-limited feed does not reach as NULL
+func curveFor(book *orderbook.BookSnapshot, offsets []decimal.Decimal, caps exchange.Capabilities) map[string]database.BandLiquidityAPI {
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	curve := make(map[string]database.BandLiquidityAPI, len(offsets))
+	for _, point := range book.DepthAtOffsets(offsets) {
+		covered := caps.MaxDepth == 0 || book.CoversDepth(point.Pct.InexactFloat64())
+		curve[types.BandLabel(point.Pct)] = database.BandLiquidityAPI{
+			Bid:         optionalValue(point.Bid, covered),
+			Ask:         optionalValue(point.Ask, covered),
+			BidNotional: optionalValue(point.BidNotional, covered),
+			AskNotional: optionalValue(point.AskNotional, covered),
+		}
+	}
+	return curve
+}
+
+// depthFor copies the top levels per side of a book, stamped like its snapshot, first merging
+// them into buckets when a bucket width is set and the book has a mid
+func depthFor(snapshot *database.OrderbookSnapshotAPI, book *orderbook.BookSnapshot, levels int, bucket decimal.Decimal) *database.DepthSnapshotAPI {
+	bids, asks := book.Bids, book.Asks
+	if bucket.IsPositive() && snapshot.MidPrice != nil {
+		step := BucketStep(decimal.NewFromFloat(*snapshot.MidPrice).Mul(bucket))
+		bids = bucketLevels(bids, step, true)
+		asks = bucketLevels(asks, step, false)
+	}
+	return &database.DepthSnapshotAPI{
+		Exchange:  snapshot.Exchange,
+		Symbol:    snapshot.Symbol,
+		Timestamp: snapshot.Timestamp,
+		Bids:      levelsFor(bids, levels),
+		Asks:      levelsFor(asks, levels),
+	}
+}
+
+// BucketStep rounds a bucket width down to 1, 2 or 5 times a power of ten, so bucket
+// edges stay put while mid drifts
+func BucketStep(width decimal.Decimal) decimal.Decimal {
+	if !width.IsPositive() {
+		return width
+	}
+	exponent := int32(len(width.Truncate(0).String())) - 1 // Digits before the point, less one
+	if width.LessThan(decimal.NewFromInt(1)) {
+		exponent = 0
+		for scaled := width; scaled.LessThan(decimal.NewFromInt(1)); scaled = scaled.Shift(1) {
+			exponent--
+		}
+	}
+	power := decimal.New(1, exponent)
+	for _, factor := range []int64{5, 2} {
+		if step := power.Mul(decimal.NewFromInt(factor)); width.GreaterThanOrEqual(step) {
+			return step
+		}
+	}
+	return power
+}
+
+// bucketLevels sums levels, best first, into buckets of step: bids into the bucket at or below
+// their price and asks into the one at or above, so each bucket is reported at its edge nearest mid
+func bucketLevels(levels []types.PriceLevel, step decimal.Decimal, descending bool) []types.PriceLevel {
+	var buckets []types.PriceLevel
+	for _, level := range levels {
+		edge := level.Price.Div(step)
+		if descending {
+			edge = edge.Floor()
+		} else {
+			edge = edge.Ceil()
+		}
+		edge = edge.Mul(step)
+
+		if n := len(buckets); n > 0 && buckets[n-1].Price.Equal(edge) {
+			buckets[n-1].Quantity = buckets[n-1].Quantity.Add(level.Quantity)
+			continue
+		}
+		buckets = append(buckets, types.PriceLevel{Price: edge, Quantity: level.Quantity})
+	}
+	return buckets
+}
+
+// levelsFor converts up to n levels, best first
+func levelsFor(levels []types.PriceLevel, n int) []database.LevelAPI {
+	if len(levels) > n {
+		levels = levels[:n]
+	}
+	result := make([]database.LevelAPI, len(levels))
+	for i, level := range levels {
+		result[i] = database.LevelAPI{Price: level.Price.InexactFloat64(), Quantity: level.Quantity.InexactFloat64()}
 	}
 	return result
 }
 
+// optionalValue converts a value, returning nil when it is not valid
+func optionalValue(value decimal.Decimal, covered bool) *float64 {
+	if !covered {
+		return nil
+	}
+	v := value.InexactFloat64()
+	return &v
+}
+
 // GetStats returns collector statistics
 func (c *Collector) GetStats() map[string]interface{} {
 	c.mu.RLock()
@@ -199,11 +995,19 @@ func (c *Collector) GetStats() map[string]interface{} {
 		"enabled":          c.enabled,
 		"registered_count": len(c.orderbooks),
 		"exchanges":        make([]string, 0, len(c.orderbooks)),
+		"stored":           c.stored.Load(),
+		"skipped":          c.skipped.Load(),
+		"insert_errors":    c.insertErrors.Load(),
+		"last_store":       nil,
 	}
 
 	for exchange := range c.orderbooks {
 		stats["exchanges"] = append(stats["exchanges"].([]string), exchange)
 	}
+	sort.Strings(stats["exchanges"].([]string))
+	if last := c.lastStored.Load(); last != 0 {
+		stats["last_store"] = time.Unix(0, last)
+	}
 
 	return stats
 }
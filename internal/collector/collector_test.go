@@ -0,0 +1,349 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestBucketStep(t *testing.T) {
+	tests := []struct {
+		width, want string
+	}{
+		{"30", "20"},
+		{"60", "50"},
+		{"1", "1"},
+		{"0.3", "0.2"},
+		{"0.0099", "0.005"},
+	}
+	for _, tt := range tests {
+		if got := BucketStep(decimal.RequireFromString(tt.width)); !got.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("BucketStep(%s) = %s, want %s", tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestDepthForBuckets(t *testing.T) {
+	level := func(price, qty string) types.PriceLevel {
+		return types.PriceLevel{Price: decimal.RequireFromString(price), Quantity: decimal.RequireFromString(qty)}
+	}
+	book := &orderbook.BookSnapshot{
+		Bids: []types.PriceLevel{level("99.9", "1"), level("99.1", "2"), level("98.5", "3"), level("97", "4")},
+		Asks: []types.PriceLevel{level("100.1", "1"), level("100.9", "2"), level("101.2", "3")},
+	}
+	mid := 100.0
+	snapshot := &database.OrderbookSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: time.Now(), MidPrice: &mid}
+
+	// 1% of a 100 mid is a step of 1: bids fall to the bucket below, asks rise to the one above
+	depth := depthFor(snapshot, book, 2, decimal.RequireFromString("0.01"))
+	wantBids := []database.LevelAPI{{Price: 99, Quantity: 3}, {Price: 98, Quantity: 3}}
+	wantAsks := []database.LevelAPI{{Price: 101, Quantity: 3}, {Price: 102, Quantity: 3}}
+	for i := range wantBids {
+		if depth.Bids[i] != wantBids[i] || depth.Asks[i] != wantAsks[i] {
+			t.Fatalf("unexpected buckets bids %v asks %v", depth.Bids, depth.Asks)
+		}
+	}
+	if len(depth.Bids) != 2 {
+		t.Errorf("expected the top 2 buckets, got %v", depth.Bids)
+	}
+
+	// Without a bucket width the raw levels are kept
+	if raw := depthFor(snapshot, book, 10, decimal.Zero); len(raw.Bids) != 4 || raw.Bids[1].Price != 99.1 {
+		t.Errorf("unexpected raw levels %v", raw.Bids)
+	}
+}
+
+// tradeSink records the batches it is given and fails when told to
+type tradeSink struct {
+	batches [][]*database.TradeAPI
+	fail    bool
+}
+
+func (s *tradeSink) InsertTrades(trades []*database.TradeAPI) error {
+	if s.fail {
+		return errors.New("down")
+	}
+	s.batches = append(s.batches, trades)
+	return nil
+}
+
+func TestTradeRecorder(t *testing.T) {
+	sink := &tradeSink{}
+	recorder := NewTradeRecorder(sink, 2, time.Hour)
+	for i := 0; i < 5; i++ {
+		recorder.Record(&database.TradeAPI{Exchange: "okx", Symbol: "BTCUSDT", Price: 100, Size: 1, Side: "sell"})
+	}
+	recorder.Flush()
+	if len(sink.batches) != 3 || len(sink.batches[2]) != 1 || recorder.Written() != 5 {
+		t.Fatalf("expected batches of 2, 2 and 1, got %d batches and %d written", len(sink.batches), recorder.Written())
+	}
+
+	sink.fail = true
+	recorder.Record(&database.TradeAPI{Exchange: "okx", Symbol: "BTCUSDT"})
+	recorder.Flush()
+	if recorder.Dropped() != 1 {
+		t.Errorf("expected the failed trade to be dropped, got %d", recorder.Dropped())
+	}
+
+	// Beyond 100 pending batches new trades are dropped
+	for i := 0; i < 201; i++ {
+		recorder.Record(&database.TradeAPI{})
+	}
+	if recorder.Dropped() != 2 {
+		t.Errorf("expected 1 trade dropped by the full buffer, got %d", recorder.Dropped()-1)
+	}
+}
+
+func TestNewTrade(t *testing.T) {
+	trade := &exchange.Trade{Exchange: "okx", Symbol: "BTC-USDT", TradeID: "7", Price: "100.5", Quantity: "0.25", Side: exchange.TakerSell, Timestamp: time.Now()}
+	stored, err := NewTrade("BTCUSDT", trade)
+	if err != nil {
+		t.Fatalf("NewTrade error: %v", err)
+	}
+	if stored.Symbol != "BTCUSDT" || stored.Price != 100.5 || stored.Size != 0.25 || stored.Side != "sell" || !stored.Taker || stored.TradeID != "7" {
+		t.Errorf("unexpected stored trade %+v", stored)
+	}
+
+	trade.Price = "n/a"
+	if _, err := NewTrade("BTCUSDT", trade); err == nil {
+		t.Error("expected an error for an invalid price")
+	}
+}
+
+func TestNewFunding(t *testing.T) {
+	now := time.Now()
+	stats := analytics.FundingStats{
+		Rate:               decimal.RequireFromString("0.0001"),
+		NextFunding:        now.Add(90 * time.Second),
+		MarkPrice:          decimal.NewFromInt(100),
+		OpenInterest:       decimal.NewFromInt(3),
+		OpenInterestChange: decimal.NewFromInt(-1),
+		Window:             time.Hour,
+	}
+	stored := NewFunding(stats, now)
+	if stored.Rate != 0.0001 || stored.SecondsToFunding != 90 || stored.OpenInterestNotional != 300 || stored.OpenInterestChange != -1 || stored.WindowSeconds != 3600 {
+		t.Errorf("unexpected stored funding %+v", stored)
+	}
+
+	// A funding time already passed is unknown until the venue sends the next one
+	if stored := NewFunding(stats, now.Add(time.Hour)); stored.SecondsToFunding != 0 {
+		t.Errorf("seconds to funding %v, want 0", stored.SecondsToFunding)
+	}
+}
+
+func TestDedupChanged(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	start := time.Now()
+	prev := &database.OrderbookSnapshotAPI{Timestamp: start, BestBid: value(100), BestAsk: value(100.1), TotalBidsQty: value(50),
+		Imbalance: map[string]database.ImbalanceAPI{"5": {Imbalance: 0.2}}}
+	next := func(change func(s *database.OrderbookSnapshotAPI)) *database.OrderbookSnapshotAPI {
+		s := *prev
+		s.Timestamp = start.Add(time.Minute)
+		change(&s)
+		return &s
+	}
+
+	dedup := &Dedup{Price: 0.0001, Quantity: 0.01, MaxAge: time.Hour}
+	tests := []struct {
+		name   string
+		next   *database.OrderbookSnapshotAPI
+		change bool
+	}{
+		{"unchanged", next(func(s *database.OrderbookSnapshotAPI) {}), false},
+		{"bid within 1bp", next(func(s *database.OrderbookSnapshotAPI) { s.BestBid = value(100.005) }), false},
+		{"bid beyond 1bp", next(func(s *database.OrderbookSnapshotAPI) { s.BestBid = value(100.02) }), true},
+		{"quantity within 1%", next(func(s *database.OrderbookSnapshotAPI) { s.TotalBidsQty = value(50.4) }), false},
+		{"quantity beyond 1%", next(func(s *database.OrderbookSnapshotAPI) { s.TotalBidsQty = value(51) }), true},
+		{"mid appeared", next(func(s *database.OrderbookSnapshotAPI) { s.MidPrice = value(100.05) }), true},
+		{"imbalance moved", next(func(s *database.OrderbookSnapshotAPI) {
+			s.Imbalance = map[string]database.ImbalanceAPI{"5": {Imbalance: 0.25}}
+		}), true},
+		{"max age", next(func(s *database.OrderbookSnapshotAPI) { s.Timestamp = start.Add(time.Hour) }), true},
+	}
+	for _, tt := range tests {
+		if got := dedup.Changed(prev, tt.next); got != tt.change {
+			t.Errorf("%s: Changed() = %v, want %v", tt.name, got, tt.change)
+		}
+	}
+
+	if !dedup.Changed(nil, prev) {
+		t.Error("expected the first snapshot of a book to be written")
+	}
+	if exact := (&Dedup{}); !exact.Changed(prev, tests[1].next) {
+		t.Error("expected zero thresholds to write any change")
+	}
+}
+
+// batchSink signals every batch of snapshots it is given
+type batchSink struct {
+	batches chan int
+}
+
+func (s *batchSink) InsertOrderbookSnapshot(*database.OrderbookSnapshotAPI) error { return nil }
+func (s *batchSink) TestConnection() error                                        { return nil }
+func (s *batchSink) Close() error                                                 { return nil }
+
+func (s *batchSink) InsertOrderbookSnapshotsBatch(snapshots []*database.OrderbookSnapshotAPI) error {
+	s.batches <- len(snapshots)
+	return nil
+}
+
+func TestSetInterval(t *testing.T) {
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		Bids: []exchange.PriceLevel{{Price: "99", Quantity: "1"}},
+		Asks: []exchange.PriceLevel{{Price: "101", Quantity: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	sink := &batchSink{batches: make(chan int, 100)}
+	c := NewCollector(sink, "BTCUSDT", time.Hour)
+	c.SetQuiet(true)
+	c.RegisterOrderbook("okx", ob, exchange.Capabilities{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Start(ctx)
+
+	// The hourly ticker is replaced while Start runs
+	c.SetInterval(5 * time.Millisecond)
+	select {
+	case n := <-sink.batches:
+		if n != 1 {
+			t.Errorf("stored %d snapshots, want 1", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a collection at the new interval")
+	}
+	if c.Interval() != 5*time.Millisecond {
+		t.Errorf("Interval() = %v", c.Interval())
+	}
+
+	c.SetEnabled(false)
+	time.Sleep(20 * time.Millisecond)
+	for len(sink.batches) > 0 {
+		<-sink.batches
+	}
+	select {
+	case <-sink.batches:
+		t.Error("expected no collections while disabled")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// snapshotSink keeps the snapshots of the last batch
+type snapshotSink struct {
+	batchSink
+	last []*database.OrderbookSnapshotAPI
+}
+
+func (s *snapshotSink) InsertOrderbookSnapshotsBatch(snapshots []*database.OrderbookSnapshotAPI) error {
+	s.last = snapshots
+	return nil
+}
+
+func TestConsolidated(t *testing.T) {
+	book := func(bid, ask string) *orderbook.OrderBook {
+		ob := orderbook.New()
+		err := ob.LoadSnapshot(&exchange.Snapshot{
+			Bids: []exchange.PriceLevel{{Price: bid, Quantity: "1"}},
+			Asks: []exchange.PriceLevel{{Price: ask, Quantity: "1"}},
+		})
+		if err != nil {
+			t.Fatalf("LoadSnapshot() error: %v", err)
+		}
+		ob.ProcessBufferedEvents()
+		return ob
+	}
+
+	sink := &snapshotSink{}
+	c := NewCollector(sink, "BTCUSDT", time.Minute)
+	c.SetQuiet(true)
+	c.RegisterOrderbook("okx", book("99", "101"), exchange.Capabilities{})
+	c.RegisterOrderbook("bybit", book("100", "102"), exchange.Capabilities{})
+	c.collectAndStore()
+	if len(sink.last) != 2 {
+		t.Fatalf("stored %d snapshots without SetConsolidated, want 2", len(sink.last))
+	}
+
+	c.SetConsolidated(true)
+	c.collectAndStore()
+	var consolidated *database.OrderbookSnapshotAPI
+	for _, s := range sink.last {
+		if s.Exchange == analytics.ConsolidatedExchange {
+			consolidated = s
+		}
+	}
+	if consolidated == nil {
+		t.Fatalf("expected a %s row among %d snapshots", analytics.ConsolidatedExchange, len(sink.last))
+	}
+	if *consolidated.BestBid != 100 || *consolidated.BestAsk != 101 || *consolidated.Spread != 1 || *consolidated.MidPrice != 100.5 || consolidated.Symbol != "BTCUSDT" {
+		t.Errorf("unexpected consolidated row %+v", consolidated)
+	}
+}
+
+func TestNewVolatility(t *testing.T) {
+	stats := analytics.VolatilityStats{Windows: []analytics.WindowVolatility{
+		{Window: time.Minute, Returns: 59, Volatility: decimal.RequireFromString("0.001"), Annualized: decimal.RequireFromString("0.72")},
+		{Window: 90 * time.Minute, Returns: 5399},
+		{Window: time.Hour},
+		{Window: 30 * time.Second},
+	}}
+	volatility := NewVolatility(stats)
+	for _, label := range []string{"1m", "1h30m", "1h", "30s"} {
+		if _, ok := volatility[label]; !ok {
+			t.Errorf("expected a %s window in %v", label, volatility)
+		}
+	}
+	if one := volatility["1m"]; one.Returns != 59 || one.Volatility != 0.001 || one.Annualized != 0.72 {
+		t.Errorf("unexpected 1m volatility %+v", one)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "2"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "3"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	sink := &snapshotSink{}
+	c := NewCollector(sink, "BTCUSDT", time.Minute)
+	c.SetQuiet(true)
+	c.RegisterOrderbook("okx", ob, exchange.Capabilities{})
+	c.collectAndStore()
+	if len(sink.last) != 1 || sink.last[0].Touch != nil {
+		t.Fatalf("expected no touch without SetTouch, got %+v", sink.last)
+	}
+
+	c.SetTouch(true)
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{FirstUpdateID: 2, FinalUpdateID: 2, PrevUpdateID: 1, Bids: []exchange.PriceLevel{{Price: "99", Quantity: "1"}}})
+	c.collectAndStore()
+	touch := sink.last[0].Touch
+	if touch == nil || touch.Updates != 1 || touch.BidMin != 1 || touch.BidMean != 1.5 || touch.AskMin != 3 {
+		t.Errorf("unexpected touch %+v", touch)
+	}
+
+	// Every collection starts a new interval
+	c.collectAndStore()
+	if touch = sink.last[0].Touch; touch == nil || touch.Updates != 0 || touch.BidMean != 1 {
+		t.Errorf("expected only the resting sizes in the next interval, got %+v", touch)
+	}
+	c.UnregisterOrderbook("okx")
+}
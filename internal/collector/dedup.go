@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"math"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+)
+
+// Dedup decides when a snapshot differs enough from the last one written for its exchange
+// to be stored; see SetDedup. Zero thresholds skip only snapshots that are exactly equal
+type Dedup struct {
+	Price    float64       // Relative move of best bid, best ask, mid or microprice that forces a write (0.0001 = 1bp)
+	Quantity float64       // Relative change of band, curve or total liquidity, or absolute change of an imbalance, that forces a write
+	MaxAge   time.Duration // Longest an unchanged book goes without a write, 0 never rewrites it
+}
+
+// Changed reports whether next should be written after prev, the last snapshot written of
+// the same book. Impact, slippage and rolling stats follow the compared metrics and are not compared
+func (d *Dedup) Changed(prev, next *database.OrderbookSnapshotAPI) bool {
+	if prev == nil {
+		return true
+	}
+	if d.MaxAge > 0 && next.Timestamp.Sub(prev.Timestamp) >= d.MaxAge {
+		return true
+	}
+
+	prices := [][2]*float64{
+		{prev.BestBid, next.BestBid}, {prev.BestAsk, next.BestAsk},
+		{prev.MidPrice, next.MidPrice}, {prev.Microprice, next.Microprice},
+	}
+	for _, p := range prices {
+		if moved(p[0], p[1], d.Price) {
+			return true
+		}
+	}
+
+	quantities := [][2]*float64{
+		{prev.BidLiquidity05Pct, next.BidLiquidity05Pct}, {prev.AskLiquidity05Pct, next.AskLiquidity05Pct},
+		{prev.BidLiquidity2Pct, next.BidLiquidity2Pct}, {prev.AskLiquidity2Pct, next.AskLiquidity2Pct},
+		{prev.BidLiquidity10Pct, next.BidLiquidity10Pct}, {prev.AskLiquidity10Pct, next.AskLiquidity10Pct},
+		{prev.TotalBidsQty, next.TotalBidsQty}, {prev.TotalAsksQty, next.TotalAsksQty},
+	}
+	for _, q := range quantities {
+		if moved(q[0], q[1], d.Quantity) {
+			return true
+		}
+	}
+	if bandsMoved(prev.LiquidityBands, next.LiquidityBands, d.Quantity) || bandsMoved(prev.DepthCurve, next.DepthCurve, d.Quantity) {
+		return true
+	}
+
+	if len(prev.Imbalance) != len(next.Imbalance) {
+		return true
+	}
+	for levels, value := range next.Imbalance {
+		old, ok := prev.Imbalance[levels]
+		if !ok || math.Abs(value.Imbalance-old.Imbalance) > d.Quantity {
+			return true
+		}
+	}
+	return false
+}
+
+// moved reports whether a value appeared, disappeared or changed by more than threshold
+// relative to its previous value
+func moved(prev, next *float64, threshold float64) bool {
+	if prev == nil || next == nil {
+		return (prev == nil) != (next == nil)
+	}
+	if *prev == *next {
+		return false
+	}
+	if *prev == 0 {
+		return true
+	}
+	return math.Abs(*next-*prev)/math.Abs(*prev) > threshold
+}
+
+// bandsMoved compares band or curve liquidity keyed by label
+func bandsMoved(prev, next map[string]database.BandLiquidityAPI, threshold float64) bool {
+	if len(prev) != len(next) {
+		return true
+	}
+	for label, band := range next {
+		old, ok := prev[label]
+		if !ok || moved(old.Bid, band.Bid, threshold) || moved(old.Ask, band.Ask, threshold) {
+			return true
+		}
+	}
+	return false
+}
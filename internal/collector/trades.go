@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+// TradeWriter is implemented by clients that can store the trade tape
+type TradeWriter interface {
+	InsertTrades(trades []*database.TradeAPI) error
+}
+
+// TradeRecorder buffers trades and writes them in batches, when a batch fills or on an
+// interval, so feeds hand trades over without waiting on the database. At most 100 batches
+// are held; beyond that new trades are dropped and counted
+type TradeRecorder struct {
+	writer     TradeWriter
+	batchSize  int
+	interval   time.Duration
+	maxPending int
+	mu         sync.Mutex
+	pending    []*database.TradeAPI
+	full       chan struct{} // Signalled when a batch is ready
+	written    atomic.Int64
+	dropped    atomic.Int64
+}
+
+// NewTradeRecorder creates a recorder writing batches of batchSize trades (default 1000)
+// at least every interval (default 1s)
+func NewTradeRecorder(writer TradeWriter, batchSize int, interval time.Duration) *TradeRecorder {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &TradeRecorder{
+		writer:     writer,
+		batchSize:  batchSize,
+		interval:   interval,
+		maxPending: 100 * batchSize,
+		full:       make(chan struct{}, 1),
+	}
+}
+
+// Record queues a trade for the next batch
+func (r *TradeRecorder) Record(trade *database.TradeAPI) {
+	r.mu.Lock()
+	if len(r.pending) >= r.maxPending {
+		r.mu.Unlock()
+		r.dropped.Add(1)
+		return
+	}
+	r.pending = append(r.pending, trade)
+	ready := len(r.pending) >= r.batchSize
+	r.mu.Unlock()
+
+	if ready {
+		select {
+		case r.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// NewTrade converts a public trade to a stored one under symbol, the symbol the snapshots
+// are stored under; the side is the taker's
+func NewTrade(symbol string, trade *exchange.Trade) (*database.TradeAPI, error) {
+	price, err := strconv.ParseFloat(trade.Price, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price %q: %w", trade.Price, err)
+	}
+	size, err := strconv.ParseFloat(trade.Quantity, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity %q: %w", trade.Quantity, err)
+	}
+	return &database.TradeAPI{
+		Exchange:  string(trade.Exchange),
+		Symbol:    symbol,
+		TradeID:   trade.TradeID,
+		Timestamp: trade.Timestamp,
+		Price:     price,
+		Size:      size,
+		Side:      string(trade.Side),
+		Taker:     true,
+	}, nil
+}
+
+// Start writes batches until the context is cancelled, then writes what is left
+func (r *TradeRecorder) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.Flush()
+			return
+		case <-ticker.C:
+		case <-r.full:
+		}
+		r.Flush()
+	}
+}
+
+// Flush writes every pending trade in batches; a failed batch is logged and dropped
+func (r *TradeRecorder) Flush() {
+	r.mu.Lock()
+	trades := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+
+	for start := 0; start < len(trades); start += r.batchSize {
+		batch := trades[start:min(start+r.batchSize, len(trades))]
+		if err := r.writer.InsertTrades(batch); err != nil {
+			r.dropped.Add(int64(len(batch)))
+			logger().Error("Failed to store trades", "trades", len(batch), "error", err)
+			continue
+		}
+		r.written.Add(int64(len(batch)))
+	}
+}
+
+// Written returns the number of trades stored
+func (r *TradeRecorder) Written() int64 {
+	return r.written.Load()
+}
+
+// Dropped returns the number of trades lost to a full buffer or failed writes
+func (r *TradeRecorder) Dropped() int64 {
+	return r.dropped.Load()
+}
@@ -3,8 +3,13 @@ package config
 import (
 	"time"
 
-	"orderbook/internal/exchange"
-	"orderbook/internal/types"
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/retention"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
 )
 
 // Config holds all application configuration
@@ -12,12 +17,48 @@ type Config struct {
 	Exchanges []ExchangeConfig
 	Display   DisplayConfig
 	App       AppConfig
+	Storage   StorageConfig
 }
 
 // ExchangeConfig holds exchange-specific configuration
 type ExchangeConfig struct {
-	Name   exchange.ExchangeName
-	Symbol string
+	Name           exchange.ExchangeName
+	Symbol         string
+	InstrumentType string                  // OKX only: SPOT, SWAP, FUTURES or OPTION; empty keeps spot REST polling
+	Contract       string                  // OKX only: expiry (250627) or expiry-strike-type (250627-100000-C)
+	Path           string                  // file only: recording to replay, set from AppConfig.ReplayFile; empty falls back to REPLAY_FILE
+	Venues         []exchange.ExchangeName // aggregated only: venues to merge; empty falls back to AGGREGATE_EXCHANGES
+	TickSize       float64                 // aggregated only: bucket size; 0 falls back to AGGREGATE_TICK_SIZE
+	GapPolicy      types.GapPolicy         // Reaction to sequence gaps; empty uses AppConfig.GapPolicy
+	L3             bool                    // Order-level book where supported, read from <NAME>_L3
+	Depth          int                     // Binance snapshot size or Bybit channel depth, read from <NAME>_DEPTH; 0 keeps the adapter default
+	UpdateSpeed    time.Duration           // Binance diff stream interval (100ms, 250ms, 500ms or 1s), read from <NAME>_UPDATE_SPEED
+	APIKey         string                  // Optional credentials, read from <NAME>_API_KEY
+	APISecret      string                  // Read from <NAME>_API_SECRET
+	Passphrase     string                  // Read from <NAME>_API_PASSPHRASE
+}
+
+// StorageConfig selects the storage driver snapshots are written with and configures it
+type StorageConfig struct {
+	Driver         string                        // Registered storage driver, default supabase; none disables storage
+	DSN            string                        // Supabase URL, Postgres or ClickHouse connection string, or gRPC target
+	Path           string                        // SQLite database file, Parquet archive or CSV directory
+	DepthLevels    int                           // Levels per side stored with each snapshot by drivers that support it
+	DepthBucket    decimal.Decimal               // Bucket width stored levels are merged into, as a fraction of mid
+	RotateSize     int64                         // Parquet file size limit in bytes
+	RotateInterval time.Duration                 // Parquet file age limit
+	Upload         string                        // Command uploading each finished Parquet file
+	Influx         database.InfluxConfig         // influx only
+	CSV            database.CSVConfig            // csv only; Dir defaults to Path
+	Async          *database.SupabaseAsyncConfig // supabase only: background inserts, nil sends them inline
+	Dedup          *collector.Dedup              // Skips snapshots unchanged since the last write, nil stores every one
+	Latest         bool                          // Also upserts the newest snapshot of every book by drivers with a latest table
+	Consolidated   bool                          // Also stores the best bid and offer across the books as exchange CONSOLIDATED
+	Touch          bool                          // Also stores the size at the best bid and ask of every book between snapshots
+	Trades         bool                          // Also stores the public trades of every exchange by drivers with a trades table
+
+	Retention         *retention.Policy // Deletes and downsamples old snapshots, nil keeps everything
+	RetentionInterval time.Duration     // Interval between retention runs, default 1h
 }
 
 // DisplayConfig holds display-related configuration
@@ -32,6 +73,22 @@ type AppConfig struct {
 	ReinitCheckInterval time.Duration
 	MaxBufferSize       int
 	UpdateChannelSize   int
+	GapPolicy           types.GapPolicy   // Default reaction to sequence gaps
+	CrossPolicy         types.CrossPolicy // Reaction to a best bid at or above the best ask
+	LiquidityBands      []decimal.Decimal // Bands reported around mid, as fractions (0.02 = 2%)
+	ImbalanceDepths     []int             // Top-N level counts for imbalance and weighted mid
+	MaxDepth            int               // Levels kept per side, 0 keeps all
+	MaxBand             decimal.Decimal   // Levels further than this fraction from mid are dropped, zero keeps all
+	StaleAfter          time.Duration     // Books without updates for this long are flagged on the console
+	StaleResync         time.Duration     // Books without updates for this long are resynced, 0 never
+	RollingWindow       time.Duration     // Trailing window for rolling spread and mid metrics, 0 disables them
+	OFIWindows          []time.Duration   // Trailing windows for order flow imbalance at the top of book, empty disables it
+	FixedPoint          bool              // Store levels as scaled int64 using the decimals of each listing
+
+	Exchanges       []exchange.ExchangeName // Exchanges connected to, empty connects to the default monitoring set
+	ConfigFile      string                  // Config file reloaded while running, empty disables reloading
+	ShutdownTimeout time.Duration           // Longest shutdown may take to flush and close everything before the process exits anyway
+	ReplayFile      string                  // Recording the file exchange plays back, empty falls back to REPLAY_FILE
 }
 
 // Default returns the default configuration for BTCUSDT on Binance Futures
@@ -52,6 +109,12 @@ func Default() Config {
 			ReinitCheckInterval: 5 * time.Second,
 			MaxBufferSize:       100,
 			UpdateChannelSize:   1000,
+			GapPolicy:           types.GapBuffer,
+			CrossPolicy:         types.CrossLog,
+			LiquidityBands:      types.DefaultLiquidityBands,
+			ImbalanceDepths:     types.DefaultImbalanceDepths,
+			StaleAfter:          30 * time.Second,
+			ShutdownTimeout:     10 * time.Second,
 		},
 	}
 }
@@ -85,6 +148,56 @@ func (c *Config) SetDisplayTop(top int) {
 	c.Display.Top = top
 }
 
+// SetGapPolicy updates the default gap policy
+func (c *Config) SetGapPolicy(policy types.GapPolicy) {
+	c.App.GapPolicy = policy
+}
+
+// SetCrossPolicy updates the crossed book policy
+func (c *Config) SetCrossPolicy(policy types.CrossPolicy) {
+	c.App.CrossPolicy = policy
+}
+
+// SetLiquidityBands updates the reported liquidity bands
+func (c *Config) SetLiquidityBands(bands []decimal.Decimal) {
+	c.App.LiquidityBands = types.NormalizeBands(bands)
+}
+
+// SetImbalanceDepths updates the reported imbalance depths
+func (c *Config) SetImbalanceDepths(depths []int) {
+	c.App.ImbalanceDepths = depths
+}
+
+// SetDepthLimit updates the per-side level cap and the price band beyond which levels are dropped
+func (c *Config) SetDepthLimit(levels int, band decimal.Decimal) {
+	c.App.MaxDepth = levels
+	c.App.MaxBand = band
+}
+
+// SetStaleness updates how long a book may go without updates before it is flagged and resynced
+func (c *Config) SetStaleness(flagAfter, resyncAfter time.Duration) {
+	c.App.StaleAfter = flagAfter
+	c.App.StaleResync = resyncAfter
+}
+
+// SetRollingWindow updates the trailing window of the rolling metrics
+func (c *Config) SetRollingWindow(window time.Duration) {
+	c.App.RollingWindow = window
+}
+
+// SetFixedPoint selects scaled int64 books instead of decimal ones
+func (c *Config) SetFixedPoint(enabled bool) {
+	c.App.FixedPoint = enabled
+}
+
+// GapPolicyFor returns the gap policy of an exchange, falling back to the default
+func (c *Config) GapPolicyFor(ex ExchangeConfig) types.GapPolicy {
+	if ex.GapPolicy != "" {
+		return ex.GapPolicy
+	}
+	return c.App.GapPolicy
+}
+
 // SetUpdateInterval updates the display update interval
 func (c *Config) SetUpdateInterval(interval time.Duration) {
 	c.Display.UpdateInterval = interval
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/factory"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/shopspring/decimal"
+)
+
+// reloadDelay lets an editor finish writing before the file is read again
+const reloadDelay = 200 * time.Millisecond
+
+// File holds the settings of the config file that can change while running; what the
+// file leaves out keeps its flag value
+type File struct {
+	Symbol    string                   // Symbol monitored, empty keeps -symbol
+	Exchanges []exchange.ExchangeName  // Exchanges connected to, empty keeps -exchanges
+	Intervals map[string]time.Duration // Intervals by name, as the admin API takes them: log, db, publish, redis or ws
+	Bands     []decimal.Decimal        // Liquidity bands around mid as fractions, nil keeps -bands
+}
+
+// fileJSON is the config file as written
+type fileJSON struct {
+	Symbol    string            `json:"symbol,omitempty"`
+	Exchanges []string          `json:"exchanges,omitempty"`
+	Intervals map[string]string `json:"intervals,omitempty"`
+	Bands     string            `json:"bands,omitempty"`
+}
+
+// LoadFile reads and validates a config file, e.g.
+//
+//	{"symbol": "BTCUSDT",
+//	 "exchanges": ["binance", "okx", "kraken"],
+//	 "intervals": {"log": "10s", "db": "20s"},
+//	 "bands": "0.1,0.5,2"}
+func LoadFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var raw fileJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&raw); err != nil {
+		return File{}, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	file := File{Symbol: strings.TrimSpace(raw.Symbol)}
+	if file.Exchanges, err = factory.ParseExchanges(strings.Join(raw.Exchanges, ",")); err != nil {
+		return File{}, fmt.Errorf("invalid exchanges: %w", err)
+	}
+	if len(raw.Intervals) > 0 {
+		file.Intervals = make(map[string]time.Duration, len(raw.Intervals))
+		for name, text := range raw.Intervals {
+			interval, err := time.ParseDuration(text)
+			if err != nil || interval <= 0 {
+				return File{}, fmt.Errorf("invalid %s interval %q: want a positive duration", name, text)
+			}
+			file.Intervals[name] = interval
+		}
+	}
+	if raw.Bands != "" {
+		if file.Bands, err = types.ParseLiquidityBands(raw.Bands); err != nil {
+			return File{}, fmt.Errorf("invalid bands: %w", err)
+		}
+	}
+	return file, nil
+}
+
+// WatchFile calls apply with the config file every time it changes, until ctx is done
+// The directory is watched rather than the file, so editors that replace the file on save
+// are followed; a file that no longer parses is logged and ignored
+func WatchFile(ctx context.Context, path string, apply func(File)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch config: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config: %w", err)
+	}
+
+	logger := slog.Default().With(logging.KeyComponent, "config")
+	name := filepath.Clean(path)
+	go func() {
+		defer watcher.Close()
+		reload := time.NewTimer(0)
+		if !reload.Stop() {
+			<-reload.C
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == name && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					reload.Reset(reloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("Config watch failed", "event", "watch_failed", "error", err)
+			case <-reload.C:
+				file, err := LoadFile(path)
+				if err != nil {
+					logger.Error("Ignoring changed config", "event", "reload_failed", "path", path, "error", err)
+					continue
+				}
+				logger.Info("Config changed", "event", "reloaded", "path", path)
+				apply(file)
+			}
+		}
+	}()
+	return nil
+}
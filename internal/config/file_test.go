@@ -0,0 +1,76 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"symbol": "ETHUSDT", "exchanges": ["binance", "OKX"], "intervals": {"log": "5s"}, "bands": "0.5,2"}`)
+	file, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if file.Symbol != "ETHUSDT" || len(file.Exchanges) != 2 || file.Exchanges[1] != exchange.OKX {
+		t.Errorf("unexpected symbol and exchanges %+v", file)
+	}
+	if file.Intervals["log"] != 5*time.Second || len(file.Bands) != 2 || file.Bands[0].String() != "0.005" {
+		t.Errorf("unexpected intervals and bands %+v", file)
+	}
+
+	for _, body := range []string{
+		`{"exchanges": ["mtgox"]}`,
+		`{"intervals": {"log": "soon"}}`,
+		`{"intervals": {"db": "-1s"}}`,
+		`{"bands": "wide"}`,
+		`{"symbols": ["BTCUSDT"]}`,
+	} {
+		writeFile(t, path, body)
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("expected an error for %s", body)
+		}
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"symbol": "BTCUSDT"}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan File, 4)
+	if err := WatchFile(ctx, path, func(file File) { changes <- file }); err != nil {
+		t.Fatalf("WatchFile() error: %v", err)
+	}
+
+	// A broken file is skipped, and the next valid one applied
+	writeFile(t, path, `{"symbol": `)
+	time.Sleep(2 * reloadDelay)
+	writeFile(t, path, `{"symbol": "ETHUSDT"}`)
+	select {
+	case file := <-changes:
+		if file.Symbol != "ETHUSDT" {
+			t.Errorf("expected the new symbol, got %+v", file)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the change")
+	}
+	select {
+	case file := <-changes:
+		t.Errorf("expected a single change, also got %+v", file)
+	case <-time.After(2 * reloadDelay):
+	}
+}
@@ -0,0 +1,632 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// ClickHouseConfig configures the ClickHouse client
+type ClickHouseConfig struct {
+	DSN           string        // e.g. clickhouse://default:@localhost:9000/orderbook
+	BatchSize     int           // Rows buffered per table before a flush, default 10000
+	FlushInterval time.Duration // Longest a row waits before a flush, default 5s
+	QueueSize     int           // Pending inserts before new ones are dropped, default 1000
+}
+
+// ClickHouseClient stores snapshots and optional full-depth levels in ClickHouse over
+// the native protocol. Inserts are queued and written in batches by a background
+// writer, so a slow server never delays the collector
+type ClickHouseClient struct {
+	conn    driver.Conn
+	config  ClickHouseConfig
+	mu      sync.RWMutex // Guards closed against concurrent enqueues
+	closed  bool
+	queue   chan clickHouseRows
+	stopped chan struct{} // Closed once the writer has flushed and exited
+	dropped atomic.Int64  // Rows dropped because the queue was full
+	failed  atomic.Int64  // Rows lost to failed batch inserts
+}
+
+// clickHouseRows is one queued insert for either table
+type clickHouseRows struct {
+	snapshots []*OrderbookSnapshotAPI
+	depth     []*DepthSnapshotAPI
+	trades    []*TradeAPI
+	latest    []*OrderbookSnapshotAPI // Written to orderbook_latest
+}
+
+// NewClickHouseClient connects to ClickHouse, creates the tables if needed and starts the writer
+func NewClickHouseClient(config ClickHouseConfig) (*ClickHouseClient, error) {
+	options, err := clickhouse.ParseDSN(config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ClickHouse DSN: %w", err)
+	}
+	if options.Compression == nil {
+		options.Compression = &clickhouse.Compression{Method: clickhouse.CompressionLZ4}
+	}
+
+	conn, err := clickhouse.Open(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ClickHouse connection: %w", err)
+	}
+
+	if err := createClickHouseTables(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return newClickHouseClient(conn, config), nil
+}
+
+// newClickHouseClient fills in the config defaults and starts the writer on an open connection
+func newClickHouseClient(conn driver.Conn, config ClickHouseConfig) *ClickHouseClient {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 10000
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+
+	c := &ClickHouseClient{
+		conn:    conn,
+		config:  config,
+		queue:   make(chan clickHouseRows, config.QueueSize),
+		stopped: make(chan struct{}),
+	}
+	go c.writeLoop()
+	return c
+}
+
+// clickHouseSchema holds one table per kind of row; levels are only written when depth storage is enabled
+var clickHouseSchema = []string{
+	`CREATE TABLE IF NOT EXISTS orderbook_snapshots (
+		exchange LowCardinality(String),
+		symbol LowCardinality(String),
+		timestamp DateTime64(3, 'UTC'),
+		best_bid Nullable(Float64),
+		best_ask Nullable(Float64),
+		mid_price Nullable(Float64),
+		spread Nullable(Float64),
+		bid_liquidity_05_pct Nullable(Float64),
+		ask_liquidity_05_pct Nullable(Float64),
+		bid_liquidity_2_pct Nullable(Float64),
+		ask_liquidity_2_pct Nullable(Float64),
+		bid_liquidity_10_pct Nullable(Float64),
+		ask_liquidity_10_pct Nullable(Float64),
+		total_bids_qty Nullable(Float64),
+		total_asks_qty Nullable(Float64),
+		bid_notional_05_pct Nullable(Float64),
+		ask_notional_05_pct Nullable(Float64),
+		bid_notional_2_pct Nullable(Float64),
+		ask_notional_2_pct Nullable(Float64),
+		bid_notional_10_pct Nullable(Float64),
+		ask_notional_10_pct Nullable(Float64),
+		total_bids_notional Nullable(Float64),
+		total_asks_notional Nullable(Float64),
+		microprice Nullable(Float64),
+		imbalance Map(String, Float64),
+		weighted_mid Map(String, Float64),
+		band_bid Map(String, Float64),
+		band_ask Map(String, Float64),
+		band_bid_notional Map(String, Float64),
+		band_ask_notional Map(String, Float64),
+		curve_bid Map(String, Float64),
+		curve_ask Map(String, Float64),
+		curve_bid_notional Map(String, Float64),
+		curve_ask_notional Map(String, Float64),
+		impact_buy_vwap Map(String, Float64),
+		impact_buy_slippage Map(String, Float64),
+		impact_sell_vwap Map(String, Float64),
+		impact_sell_slippage Map(String, Float64),
+		rolling_window_seconds Nullable(Float64),
+		rolling_samples Nullable(UInt32),
+		rolling_avg_spread Nullable(Float64),
+		rolling_spread_volatility Nullable(Float64),
+		rolling_mid_return_volatility Nullable(Float64),
+		flow_window_seconds Nullable(Float64),
+		flow_cvd Nullable(Float64),
+		flow_buy_volume Nullable(Float64),
+		flow_sell_volume Nullable(Float64),
+		flow_buy_ratio Nullable(Float64),
+		flow_trades Nullable(UInt32),
+		flow_large_trades Nullable(UInt32),
+		funding_rate Nullable(Float64),
+		funding_seconds_to_funding Nullable(Float64),
+		funding_mark_price Nullable(Float64),
+		funding_index_price Nullable(Float64),
+		funding_open_interest Nullable(Float64),
+		funding_open_interest_notional Nullable(Float64),
+		funding_open_interest_change Nullable(Float64),
+		funding_window_seconds Nullable(Float64),
+		basis_spot_exchange Nullable(String),
+		basis_spot_mid Nullable(Float64),
+		basis_bps Nullable(Float64),
+		basis_annualized Nullable(Float64),
+		basis_annualized_funding Nullable(Float64),
+		slippage_buy_vwap Map(String, Float64),
+		slippage_buy Map(String, Float64),
+		slippage_sell_vwap Map(String, Float64),
+		slippage_sell Map(String, Float64),
+		resiliency_score Nullable(Float64),
+		resiliency_depletions Nullable(UInt32),
+		resiliency_recovered Nullable(UInt32),
+		resiliency_mean_recovery_seconds Nullable(Float64),
+		resiliency_replenishment Nullable(Float64),
+		resiliency_bid_depth Nullable(Float64),
+		resiliency_ask_depth Nullable(Float64),
+		resiliency_window_seconds Nullable(Float64),
+		share_bid Map(String, Float64),
+		share_ask Map(String, Float64),
+		share_total Map(String, Float64),
+		share_best_bid Nullable(Float64),
+		share_best_ask Nullable(Float64),
+		share_sampled_seconds Nullable(Float64),
+		volatility Map(String, Float64),
+		volatility_annualized Map(String, Float64),
+		volatility_returns Map(String, UInt32),
+		ofi Map(String, Float64),
+		ofi_updates Map(String, UInt32),
+		effective_spread_raw_bps Nullable(Float64),
+		effective_spread_taker_bps Nullable(Float64),
+		effective_spread_maker_bps Nullable(Float64),
+		maker_fee_bps Nullable(Float64),
+		taker_fee_bps Nullable(Float64),
+		index_price Nullable(Float64),
+		index_weighting Nullable(String),
+		index_venues Nullable(UInt32),
+		index_weights Map(String, Float64),
+		touch_bid_mean Nullable(Float64),
+		touch_bid_min Nullable(Float64),
+		touch_bid_time_weighted Nullable(Float64),
+		touch_ask_mean Nullable(Float64),
+		touch_ask_min Nullable(Float64),
+		touch_ask_time_weighted Nullable(Float64),
+		touch_updates Nullable(UInt32)
+	) ENGINE = MergeTree
+	PARTITION BY toYYYYMMDD(timestamp)
+	ORDER BY (exchange, symbol, timestamp)`,
+	`CREATE TABLE IF NOT EXISTS orderbook_levels (
+		exchange LowCardinality(String),
+		symbol LowCardinality(String),
+		timestamp DateTime64(3, 'UTC'),
+		side Enum8('bid' = 1, 'ask' = 2),
+		level UInt16,
+		price Float64,
+		quantity Float64
+	) ENGINE = MergeTree
+	PARTITION BY toYYYYMMDD(timestamp)
+	ORDER BY (exchange, symbol, timestamp, side, level)`,
+	`CREATE TABLE IF NOT EXISTS trades (
+		exchange LowCardinality(String),
+		symbol LowCardinality(String),
+		trade_id String,
+		timestamp DateTime64(3, 'UTC'),
+		price Float64,
+		size Float64,
+		side Enum8('buy' = 1, 'sell' = 2),
+		taker Bool
+	) ENGINE = MergeTree
+	PARTITION BY toYYYYMMDD(timestamp)
+	ORDER BY (exchange, symbol, timestamp)`,
+}
+
+// createClickHouseTables creates the tables if they do not exist, and adds the snapshot
+// columns of later releases to tables created by an earlier one
+func createClickHouseTables(conn driver.Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	latest := clickHouseLatestSchema(clickHouseSchema[0])
+	for _, statement := range append(clickHouseSchema, latest) {
+		if err := conn.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create ClickHouse table: %w", err)
+		}
+	}
+	for _, table := range []string{"orderbook_snapshots", latestTable} {
+		if err := conn.Exec(ctx, clickHouseAddColumns(table, clickHouseSchema[0])); err != nil {
+			return fmt.Errorf("failed to add ClickHouse columns to %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// clickHouseColumns returns the name and type of each column of a CREATE TABLE statement, in order
+func clickHouseColumns(statement string) [][2]string {
+	body := statement[strings.Index(statement, "(")+1 : strings.Index(statement, ") ENGINE")]
+	var columns [][2]string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+		if name, kind, ok := strings.Cut(line, " "); ok {
+			columns = append(columns, [2]string{name, kind})
+		}
+	}
+	return columns
+}
+
+// clickHouseAddColumns adds every column of the CREATE TABLE statement that table lacks, each
+// after the column before it, so batches inserted without a column list still line up
+func clickHouseAddColumns(table, statement string) string {
+	columns := clickHouseColumns(statement)
+	actions := make([]string, 0, len(columns)-1)
+	for i := 1; i < len(columns); i++ {
+		actions = append(actions, fmt.Sprintf("ADD COLUMN IF NOT EXISTS %s %s AFTER %s", columns[i][0], columns[i][1], columns[i-1][0]))
+	}
+	return "ALTER TABLE " + table + " " + strings.Join(actions, ", ")
+}
+
+// InsertOrderbookSnapshot queues a single snapshot
+func (c *ClickHouseClient) InsertOrderbookSnapshot(snapshot *OrderbookSnapshotAPI) error {
+	return c.InsertOrderbookSnapshotsBatch([]*OrderbookSnapshotAPI{snapshot})
+}
+
+// InsertOrderbookSnapshotsBatch queues snapshots for the next batch; an error means they were dropped
+func (c *ClickHouseClient) InsertOrderbookSnapshotsBatch(snapshots []*OrderbookSnapshotAPI) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return c.enqueue(clickHouseRows{snapshots: snapshots}, len(snapshots))
+}
+
+// InsertDepthSnapshots queues the levels of each book for the orderbook_levels table
+func (c *ClickHouseClient) InsertDepthSnapshots(depth []*DepthSnapshotAPI) error {
+	if len(depth) == 0 {
+		return nil
+	}
+	return c.enqueue(clickHouseRows{depth: depth}, len(depth))
+}
+
+// InsertTrades queues trades for the trades table
+func (c *ClickHouseClient) InsertTrades(trades []*TradeAPI) error {
+	if len(trades) == 0 {
+		return nil
+	}
+	return c.enqueue(clickHouseRows{trades: trades}, len(trades))
+}
+
+// enqueue hands rows to the writer without blocking
+func (c *ClickHouseClient) enqueue(rows clickHouseRows, count int) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return fmt.Errorf("ClickHouse client is closed")
+	}
+
+	select {
+	case c.queue <- rows:
+		return nil
+	default:
+		c.dropped.Add(int64(count))
+		return fmt.Errorf("ClickHouse insert queue full, dropped %d rows (%d total)", count, c.dropped.Load())
+	}
+}
+
+// writeLoop buffers queued rows and flushes each table when its batch is full or the interval passes
+func (c *ClickHouseClient) writeLoop() {
+	defer close(c.stopped)
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+
+	var snapshots []*OrderbookSnapshotAPI
+	var depth []*DepthSnapshotAPI
+	var trades []*TradeAPI
+	var latest []*OrderbookSnapshotAPI
+	flush := func() {
+		if len(snapshots) > 0 {
+			if err := c.writeSnapshots("orderbook_snapshots", snapshots); err != nil {
+				c.failed.Add(int64(len(snapshots)))
+				logger("clickhouse").Error("Failed to insert snapshots", "event", "insert_failed", "snapshots", len(snapshots), "error", err)
+			}
+			snapshots = nil
+		}
+		if len(depth) > 0 {
+			if err := c.writeLevels(depth); err != nil {
+				c.failed.Add(int64(len(depth)))
+				logger("clickhouse").Error("Failed to insert levels", "event", "insert_failed", "books", len(depth), "error", err)
+			}
+			depth = nil
+		}
+		if len(trades) > 0 {
+			if err := c.writeTrades(trades); err != nil {
+				c.failed.Add(int64(len(trades)))
+				logger("clickhouse").Error("Failed to insert trades", "event", "insert_failed", "trades", len(trades), "error", err)
+			}
+			trades = nil
+		}
+		if len(latest) > 0 {
+			if err := c.writeSnapshots(latestTable, latest); err != nil {
+				c.failed.Add(int64(len(latest)))
+				logger("clickhouse").Error("Failed to update latest snapshots", "event", "insert_failed", "snapshots", len(latest), "error", err)
+			}
+			latest = nil
+		}
+	}
+
+	for {
+		select {
+		case rows, ok := <-c.queue:
+			if !ok {
+				flush()
+				return
+			}
+			snapshots = append(snapshots, rows.snapshots...)
+			depth = append(depth, rows.depth...)
+			trades = append(trades, rows.trades...)
+			latest = append(latest, rows.latest...)
+			if len(snapshots) >= c.config.BatchSize || len(depth) >= c.config.BatchSize || len(trades) >= c.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// writeSnapshots inserts snapshots into a table with the orderbook_snapshots columns as one native batch
+func (c *ClickHouseClient) writeSnapshots(table string, snapshots []*OrderbookSnapshotAPI) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	batch, err := c.conn.PrepareBatch(ctx, "INSERT INTO "+table)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+
+	for _, s := range snapshots {
+		if err := batch.Append(clickHouseSnapshotRow(s)...); err != nil {
+			batch.Abort()
+			return fmt.Errorf("failed to append snapshot: %w", err)
+		}
+	}
+
+	return batch.Send()
+}
+
+// clickHouseSnapshotRow returns the values of a snapshot in the column order of orderbook_snapshots,
+// splitting map-valued fields into one Map column per value
+func clickHouseSnapshotRow(s *OrderbookSnapshotAPI) []any {
+	imbalance := make(map[string]float64, len(s.Imbalance))
+	weightedMid := make(map[string]float64, len(s.Imbalance))
+	for levels, value := range s.Imbalance {
+		imbalance[levels] = value.Imbalance
+		weightedMid[levels] = value.WeightedMid
+	}
+	bandBid, bandAsk, bandBidNotional, bandAskNotional := splitBands(s.LiquidityBands)
+	curveBid, curveAsk, curveBidNotional, curveAskNotional := splitBands(s.DepthCurve)
+	buyVWAP, buySlippage, sellVWAP, sellSlippage := splitImpact(s.Impact)
+	notionalBuyVWAP, notionalBuySlippage, notionalSellVWAP, notionalSellSlippage := splitImpact(s.Slippage)
+
+	var window, avgSpread, spreadVolatility, midVolatility *float64
+	var samples *uint32
+	if s.Rolling != nil {
+		count := uint32(s.Rolling.Samples)
+		window, samples = &s.Rolling.WindowSeconds, &count
+		avgSpread, spreadVolatility, midVolatility = &s.Rolling.AvgSpread, &s.Rolling.SpreadVolatility, &s.Rolling.MidReturnVolatility
+	}
+
+	var flowWindow, cvd, buyVolume, sellVolume, buyRatio *float64
+	var trades, largeTrades *uint32
+	if f := s.Flow; f != nil {
+		count, large := uint32(f.Trades), uint32(f.LargeTrades)
+		flowWindow, cvd, buyVolume, sellVolume, buyRatio = &f.WindowSeconds, &f.CVD, &f.BuyVolume, &f.SellVolume, &f.BuyRatio
+		trades, largeTrades = &count, &large
+	}
+
+	var rate, toFunding, markPrice, indexPrice, openInterest, oiNotional, oiChange, fundingWindow *float64
+	if f := s.Funding; f != nil {
+		rate, toFunding, markPrice, indexPrice = &f.Rate, &f.SecondsToFunding, &f.MarkPrice, &f.IndexPrice
+		openInterest, oiNotional, oiChange, fundingWindow = &f.OpenInterest, &f.OpenInterestNotional, &f.OpenInterestChange, &f.WindowSeconds
+	}
+
+	var basisSpot *string
+	var spotMid, basisBps, annualizedBasis, annualizedFunding *float64
+	if b := s.Basis; b != nil {
+		basisSpot, spotMid, basisBps = &b.SpotExchange, &b.SpotMid, &b.BasisBps
+		annualizedBasis, annualizedFunding = &b.AnnualizedBasis, &b.AnnualizedFunding
+	}
+
+	var score, meanRecovery, replenishment, bidDepth, askDepth, resiliencyWindow *float64
+	var depletions, recovered *uint32
+	if r := s.Resiliency; r != nil {
+		count, back := uint32(r.Depletions), uint32(r.Recovered)
+		score, meanRecovery, replenishment = &r.Score, &r.MeanRecoverySeconds, &r.Replenishment
+		bidDepth, askDepth, resiliencyWindow = &r.BidDepth, &r.AskDepth, &r.WindowSeconds
+		depletions, recovered = &count, &back
+	}
+
+	var shareBestBid, shareBestAsk, shareSampled *float64
+	var shareBands map[string]BandShareAPI
+	if m := s.MarketShare; m != nil {
+		shareBestBid, shareBestAsk, shareSampled, shareBands = &m.BestBid, &m.BestAsk, &m.SampledSeconds, m.Bands
+	}
+	shareBid, shareAsk, shareTotal := splitShares(shareBands)
+	realized, annualized, returns := splitVolatility(s.Volatility)
+	ofi, ofiUpdates := splitOFI(s.OFI)
+	var rawSpread, takerSpread, makerSpread, makerFee, takerFee *float64
+	if e := s.EffectiveSpread; e != nil {
+		rawSpread, takerSpread, makerSpread = &e.RawBps, &e.TakerBps, &e.MakerBps
+		makerFee, takerFee = &e.MakerFeeBps, &e.TakerFeeBps
+	}
+	var index *float64
+	var indexWeighting *string
+	var indexVenues *uint32
+	indexWeights := map[string]float64{}
+	if x := s.IndexPrice; x != nil {
+		venues := uint32(x.Venues)
+		index, indexWeighting, indexVenues = &x.Price, &x.Weighting, &venues
+		if x.Weights != nil {
+			indexWeights = x.Weights
+		}
+	}
+	var touchBidMean, touchBidMin, touchBidWeighted, touchAskMean, touchAskMin, touchAskWeighted *float64
+	var touchUpdates *uint32
+	if t := s.Touch; t != nil {
+		updates := uint32(t.Updates)
+		touchBidMean, touchBidMin, touchBidWeighted = &t.BidMean, &t.BidMin, &t.BidTimeWeighted
+		touchAskMean, touchAskMin, touchAskWeighted = &t.AskMean, &t.AskMin, &t.AskTimeWeighted
+		touchUpdates = &updates
+	}
+
+	return []any{
+		s.Exchange, s.Symbol, s.Timestamp,
+		s.BestBid, s.BestAsk, s.MidPrice, s.Spread,
+		s.BidLiquidity05Pct, s.AskLiquidity05Pct, s.BidLiquidity2Pct, s.AskLiquidity2Pct, s.BidLiquidity10Pct, s.AskLiquidity10Pct,
+		s.TotalBidsQty, s.TotalAsksQty,
+		s.BidNotional05Pct, s.AskNotional05Pct, s.BidNotional2Pct, s.AskNotional2Pct, s.BidNotional10Pct, s.AskNotional10Pct,
+		s.TotalBidsNotional, s.TotalAsksNotional,
+		s.Microprice, imbalance, weightedMid,
+		bandBid, bandAsk, bandBidNotional, bandAskNotional,
+		curveBid, curveAsk, curveBidNotional, curveAskNotional,
+		buyVWAP, buySlippage, sellVWAP, sellSlippage,
+		window, samples, avgSpread, spreadVolatility, midVolatility,
+		flowWindow, cvd, buyVolume, sellVolume, buyRatio, trades, largeTrades,
+		rate, toFunding, markPrice, indexPrice, openInterest, oiNotional, oiChange, fundingWindow,
+		basisSpot, spotMid, basisBps, annualizedBasis, annualizedFunding,
+		notionalBuyVWAP, notionalBuySlippage, notionalSellVWAP, notionalSellSlippage,
+		score, depletions, recovered, meanRecovery, replenishment, bidDepth, askDepth, resiliencyWindow,
+		shareBid, shareAsk, shareTotal, shareBestBid, shareBestAsk, shareSampled,
+		realized, annualized, returns,
+		ofi, ofiUpdates,
+		rawSpread, takerSpread, makerSpread, makerFee, takerFee,
+		index, indexWeighting, indexVenues, indexWeights,
+		touchBidMean, touchBidMin, touchBidWeighted, touchAskMean, touchAskMin, touchAskWeighted, touchUpdates,
+	}
+}
+
+// writeLevels inserts one row per level of each book as one native batch
+func (c *ClickHouseClient) writeLevels(depth []*DepthSnapshotAPI) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	batch, err := c.conn.PrepareBatch(ctx, "INSERT INTO orderbook_levels")
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+
+	for _, book := range depth {
+		for side, levels := range map[string][]LevelAPI{"bid": book.Bids, "ask": book.Asks} {
+			for i, level := range levels {
+				if err := batch.Append(book.Exchange, book.Symbol, book.Timestamp, side, uint16(i), level.Price, level.Quantity); err != nil {
+					batch.Abort()
+					return fmt.Errorf("failed to append level: %w", err)
+				}
+			}
+		}
+	}
+
+	return batch.Send()
+}
+
+// writeTrades inserts trades as one native batch
+func (c *ClickHouseClient) writeTrades(trades []*TradeAPI) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	batch, err := c.conn.PrepareBatch(ctx, "INSERT INTO trades")
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+
+	for _, t := range trades {
+		if err := batch.Append(t.Exchange, t.Symbol, t.TradeID, t.Timestamp, t.Price, t.Size, t.Side, t.Taker); err != nil {
+			batch.Abort()
+			return fmt.Errorf("failed to append trade: %w", err)
+		}
+	}
+
+	return batch.Send()
+}
+
+// splitBands turns band liquidity keyed by label into one map per column, leaving out bands the feed does not reach
+func splitBands(bands map[string]BandLiquidityAPI) (bid, ask, bidNotional, askNotional map[string]float64) {
+	bid, ask = make(map[string]float64), make(map[string]float64)
+	bidNotional, askNotional = make(map[string]float64), make(map[string]float64)
+	for label, band := range bands {
+		setPresent(bid, label, band.Bid)
+		setPresent(ask, label, band.Ask)
+		setPresent(bidNotional, label, band.BidNotional)
+		setPresent(askNotional, label, band.AskNotional)
+	}
+	return bid, ask, bidNotional, askNotional
+}
+
+// splitImpact turns market impact keyed by size or notional into one map per column, leaving out sizes the book cannot fill
+func splitImpact(impact map[string]ImpactAPI) (buyVWAP, buySlippage, sellVWAP, sellSlippage map[string]float64) {
+	buyVWAP, buySlippage = make(map[string]float64), make(map[string]float64)
+	sellVWAP, sellSlippage = make(map[string]float64), make(map[string]float64)
+	for size, value := range impact {
+		setPresent(buyVWAP, size, value.BuyVWAP)
+		setPresent(buySlippage, size, value.BuySlippage)
+		setPresent(sellVWAP, size, value.SellVWAP)
+		setPresent(sellSlippage, size, value.SellSlippage)
+	}
+	return buyVWAP, buySlippage, sellVWAP, sellSlippage
+}
+
+// splitShares turns band shares keyed by label into one map per column
+func splitShares(bands map[string]BandShareAPI) (bid, ask, total map[string]float64) {
+	bid, ask, total = make(map[string]float64), make(map[string]float64), make(map[string]float64)
+	for label, share := range bands {
+		bid[label], ask[label], total[label] = share.Bid, share.Ask, share.Total
+	}
+	return bid, ask, total
+}
+
+// splitVolatility turns realized volatility keyed by window into one map per column
+func splitVolatility(volatility map[string]VolatilityAPI) (realized, annualized map[string]float64, returns map[string]uint32) {
+	realized, annualized, returns = make(map[string]float64), make(map[string]float64), make(map[string]uint32)
+	for window, value := range volatility {
+		realized[window], annualized[window], returns[window] = value.Volatility, value.Annualized, uint32(value.Returns)
+	}
+	return realized, annualized, returns
+}
+
+// splitOFI turns order flow imbalance keyed by window into one map per column
+func splitOFI(windows map[string]OFIAPI) (ofi map[string]float64, updates map[string]uint32) {
+	ofi, updates = make(map[string]float64), make(map[string]uint32)
+	for window, value := range windows {
+		ofi[window], updates[window] = value.OFI, uint32(value.Updates)
+	}
+	return ofi, updates
+}
+
+// setPresent stores a value under key unless it is nil
+func setPresent(values map[string]float64, key string, value *float64) {
+	if value != nil {
+		values[key] = *value
+	}
+}
+
+// TestConnection pings the server
+func (c *ClickHouseClient) TestConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return c.conn.Ping(ctx)
+}
+
+// Close flushes the queued rows and closes the connection
+func (c *ClickHouseClient) Close() error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	<-c.stopped
+	return c.conn.Close()
+}
+
+// Dropped returns the rows lost to a full queue or failed inserts
+func (c *ClickHouseClient) Dropped() int64 {
+	return c.dropped.Load() + c.failed.Load()
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// fakeClickHouseConn records the size of every batch sent; unused methods panic
+type fakeClickHouseConn struct {
+	driver.Conn
+	mu      sync.Mutex
+	batches map[string][]int // Row count of each sent batch by query
+	fail    bool             // Send fails, losing the batch
+}
+
+func (c *fakeClickHouseConn) PrepareBatch(ctx context.Context, query string, opts ...driver.PrepareBatchOption) (driver.Batch, error) {
+	return &fakeClickHouseBatch{conn: c, query: query}, nil
+}
+
+func (c *fakeClickHouseConn) Close() error {
+	return nil
+}
+
+// sent returns the row count of each batch sent for query
+func (c *fakeClickHouseConn) sent(query string) []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int(nil), c.batches[query]...)
+}
+
+type fakeClickHouseBatch struct {
+	driver.Batch
+	conn  *fakeClickHouseConn
+	query string
+	rows  [][]any
+}
+
+func (b *fakeClickHouseBatch) Append(v ...any) error {
+	b.rows = append(b.rows, v)
+	return nil
+}
+
+func (b *fakeClickHouseBatch) Abort() error {
+	return nil
+}
+
+func (b *fakeClickHouseBatch) Send() error {
+	b.conn.mu.Lock()
+	defer b.conn.mu.Unlock()
+	if b.conn.fail {
+		return fmt.Errorf("server unavailable")
+	}
+	if b.conn.batches == nil {
+		b.conn.batches = make(map[string][]int)
+	}
+	b.conn.batches[b.query] = append(b.conn.batches[b.query], len(b.rows))
+	return nil
+}
+
+func TestClickHouseSnapshotRow(t *testing.T) {
+	bid, band := 99.5, 3.0
+	snapshot := &OrderbookSnapshotAPI{
+		Exchange:       "bybit",
+		Symbol:         "BTCUSDT",
+		Timestamp:      time.UnixMilli(1700000000123),
+		BestBid:        &bid,
+		Imbalance:      map[string]ImbalanceAPI{"5": {Imbalance: 0.6, WeightedMid: 100.1}},
+		LiquidityBands: map[string]BandLiquidityAPI{"25bp": {Bid: &band}},
+		Rolling:        &RollingAPI{WindowSeconds: 300, Samples: 12},
+		OFI:            map[string]OFIAPI{"1m": {OFI: -2, Updates: 7}},
+	}
+
+	columns := clickHouseColumns(clickHouseSchema[0])
+	row := clickHouseSnapshotRow(snapshot)
+	if len(row) != len(columns) {
+		t.Fatalf("expected %d values, one per column, got %d", len(columns), len(row))
+	}
+	value := make(map[string]any, len(columns))
+	for i, column := range columns {
+		value[column[0]] = row[i]
+	}
+
+	if value["exchange"] != "bybit" || *value["best_bid"].(*float64) != 99.5 || value["best_ask"].(*float64) != nil {
+		t.Errorf("unexpected top of book %v %v %v", value["exchange"], value["best_bid"], value["best_ask"])
+	}
+	if imbalance := value["imbalance"].(map[string]float64); imbalance["5"] != 0.6 || value["weighted_mid"].(map[string]float64)["5"] != 100.1 {
+		t.Errorf("unexpected imbalance maps %v %v", imbalance, value["weighted_mid"])
+	}
+	// Bands the feed does not reach are left out of the maps rather than stored as zero
+	if bands, asks := value["band_bid"].(map[string]float64), value["band_ask"].(map[string]float64); bands["25bp"] != 3 || len(asks) != 0 {
+		t.Errorf("unexpected band maps %v %v", bands, asks)
+	}
+	if samples := value["rolling_samples"].(*uint32); samples == nil || *samples != 12 {
+		t.Errorf("unexpected rolling samples %v", samples)
+	}
+	if ofi, updates := value["ofi"].(map[string]float64), value["ofi_updates"].(map[string]uint32); ofi["1m"] != -2 || updates["1m"] != 7 {
+		t.Errorf("unexpected OFI maps %v %v", ofi, updates)
+	}
+	if value["touch_updates"].(*uint32) != nil || value["funding_rate"].(*float64) != nil {
+		t.Error("expected NULL for metrics that are not tracked")
+	}
+	if weights := value["index_weights"].(map[string]float64); weights == nil {
+		t.Error("expected an empty, not nil, index_weights map")
+	}
+}
+
+func TestClickHouseAddColumns(t *testing.T) {
+	statement := clickHouseAddColumns(latestTable, clickHouseSchema[0])
+	if !strings.HasPrefix(statement, "ALTER TABLE orderbook_latest ADD COLUMN IF NOT EXISTS symbol LowCardinality(String) AFTER exchange, ") {
+		t.Errorf("unexpected statement start %.120s", statement)
+	}
+	for _, action := range []string{
+		"ADD COLUMN IF NOT EXISTS imbalance Map(String, Float64) AFTER microprice,",
+		"ADD COLUMN IF NOT EXISTS touch_updates Nullable(UInt32) AFTER touch_ask_time_weighted",
+	} {
+		if !strings.Contains(statement, action) {
+			t.Errorf("expected %q in %s", action, statement)
+		}
+	}
+	if strings.Contains(statement, "ENGINE") || strings.HasSuffix(statement, ",") {
+		t.Errorf("expected only column actions, got %s", statement)
+	}
+}
+
+func TestClickHouseClientFlush(t *testing.T) {
+	conn := &fakeClickHouseConn{}
+	client := newClickHouseClient(conn, ClickHouseConfig{BatchSize: 2, FlushInterval: time.Hour})
+
+	snapshot := &OrderbookSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT"}
+	for i := 0; i < 3; i++ {
+		if err := client.InsertOrderbookSnapshot(snapshot); err != nil {
+			t.Fatalf("InsertOrderbookSnapshot() error: %v", err)
+		}
+	}
+	depth := &DepthSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT", Bids: []LevelAPI{{Price: 99, Quantity: 1}}, Asks: []LevelAPI{{Price: 101, Quantity: 2}, {Price: 102, Quantity: 3}}}
+	if err := client.InsertDepthSnapshots([]*DepthSnapshotAPI{depth}); err != nil {
+		t.Fatalf("InsertDepthSnapshots() error: %v", err)
+	}
+	if err := client.InsertTrades([]*TradeAPI{{Exchange: "bybit", Symbol: "BTCUSDT", TradeID: "1", Side: "buy"}}); err != nil {
+		t.Fatalf("InsertTrades() error: %v", err)
+	}
+
+	// A full batch is written at once; the rest waits for the interval or Close
+	deadline := time.Now().Add(time.Second)
+	for len(conn.sent("INSERT INTO orderbook_snapshots")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if sent := conn.sent("INSERT INTO orderbook_snapshots"); len(sent) != 1 || sent[0] != 2 {
+		t.Fatalf("expected one full batch of 2 before Close, got %v", sent)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if sent := conn.sent("INSERT INTO orderbook_snapshots"); len(sent) != 2 || sent[1] != 1 {
+		t.Errorf("expected the last snapshot flushed on Close, got %v", sent)
+	}
+	if sent := conn.sent("INSERT INTO orderbook_levels"); len(sent) != 1 || sent[0] != 3 {
+		t.Errorf("expected one row per level, got %v", sent)
+	}
+	if sent := conn.sent("INSERT INTO trades"); len(sent) != 1 || sent[0] != 1 {
+		t.Errorf("expected the trade flushed on Close, got %v", sent)
+	}
+	if err := client.InsertOrderbookSnapshot(snapshot); err == nil {
+		t.Error("expected an error once closed")
+	}
+}
+
+func TestClickHouseClientFailedFlush(t *testing.T) {
+	conn := &fakeClickHouseConn{fail: true}
+	client := newClickHouseClient(conn, ClickHouseConfig{FlushInterval: time.Hour})
+
+	snapshot := &OrderbookSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT"}
+	if err := client.InsertOrderbookSnapshotsBatch([]*OrderbookSnapshotAPI{snapshot, snapshot}); err != nil {
+		t.Fatalf("InsertOrderbookSnapshotsBatch() error: %v", err)
+	}
+	client.Close()
+
+	if dropped := client.Dropped(); dropped != 2 {
+		t.Errorf("expected the 2 rows of the failed batch counted as dropped, got %d", dropped)
+	}
+}
@@ -0,0 +1,390 @@
+package database
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCSVColumns are the columns written when CSVConfig.Columns is empty
+var DefaultCSVColumns = []string{
+	"timestamp", "exchange", "symbol", "best_bid", "best_ask", "mid_price", "spread",
+	"bid_liquidity_05_pct", "ask_liquidity_05_pct", "bid_liquidity_2_pct", "ask_liquidity_2_pct",
+	"bid_liquidity_10_pct", "ask_liquidity_10_pct", "total_bids_qty", "total_asks_qty",
+	"bid_notional_05_pct", "ask_notional_05_pct", "bid_notional_2_pct", "ask_notional_2_pct",
+	"bid_notional_10_pct", "ask_notional_10_pct", "total_bids_notional", "total_asks_notional",
+	"microprice",
+}
+
+// csvColumnPrefixes start the names of map-valued metrics, followed by their key
+var csvColumnPrefixes = []string{
+	"imbalance_", "weighted_mid_", "band_bid_", "band_ask_", "curve_bid_", "curve_ask_",
+	"impact_buy_vwap_", "impact_buy_slippage_", "impact_sell_vwap_", "impact_sell_slippage_",
+	"slippage_buy_vwap_", "slippage_buy_", "slippage_sell_vwap_", "slippage_sell_",
+	"share_bid_", "share_ask_", "share_total_",
+	"volatility_", "volatility_annualized_", "volatility_returns_",
+	"ofi_", "ofi_updates_",
+	"index_weight_",
+}
+
+// csvRollingColumns are written when the collector keeps rolling stats
+var csvRollingColumns = []string{
+	"rolling_window_seconds", "rolling_samples", "rolling_avg_spread", "rolling_spread_volatility", "rolling_mid_return_volatility",
+}
+
+// csvFlowColumns are written when taker flow is tracked
+var csvFlowColumns = []string{
+	"flow_window_seconds", "flow_cvd", "flow_buy_volume", "flow_sell_volume", "flow_buy_ratio", "flow_trades", "flow_large_trades",
+}
+
+// csvFundingColumns are written for perpetual markets whose venue publishes funding
+var csvFundingColumns = []string{
+	"funding_rate", "funding_seconds_to_funding", "funding_mark_price", "funding_index_price",
+	"funding_open_interest", "funding_open_interest_notional", "funding_open_interest_change", "funding_window_seconds",
+}
+
+// csvBasisColumns are written for perpetuals paired with a spot book
+var csvBasisColumns = []string{"basis_spot_mid", "basis_bps", "basis_annualized", "basis_annualized_funding"}
+
+// csvResiliencyColumns are written when resiliency is tracked
+var csvResiliencyColumns = []string{
+	"resiliency_score", "resiliency_depletions", "resiliency_recovered", "resiliency_mean_recovery_seconds",
+	"resiliency_replenishment", "resiliency_bid_depth", "resiliency_ask_depth", "resiliency_window_seconds",
+}
+
+// csvMarketShareColumns are written when market share is tracked
+var csvMarketShareColumns = []string{"share_best_bid", "share_best_ask", "share_sampled_seconds"}
+
+// csvEffectiveSpreadColumns are written when fee-adjusted spreads are enabled
+var csvEffectiveSpreadColumns = []string{
+	"effective_spread_raw_bps", "effective_spread_taker_bps", "effective_spread_maker_bps", "maker_fee_bps", "taker_fee_bps",
+}
+
+// csvIndexColumns are written with the consolidated row when an index price is computed
+var csvIndexColumns = []string{"index_price", "index_venues"}
+
+// csvTouchColumns are written when the size at the best bid and ask is tracked
+var csvTouchColumns = []string{
+	"touch_bid_mean", "touch_bid_min", "touch_bid_time_weighted", "touch_ask_mean", "touch_ask_min", "touch_ask_time_weighted", "touch_updates",
+}
+
+// csvTimeLayout is read as a date and time by spreadsheets and pandas alike
+const csvTimeLayout = "2006-01-02 15:04:05.000"
+
+// CSVConfig configures the CSV writer
+type CSVConfig struct {
+	Dir string // Directory files are written to, default csv
+
+	// Columns selects and orders the columns: timestamp (UTC), exchange, symbol and any
+	// metric under its orderbook_snapshots name, or a map-valued one under its InfluxDB field
+	// name such as imbalance_5 or band_bid_25bp; default DefaultCSVColumns
+	Columns []string
+
+	Period     time.Duration // Time covered by one file, aligned to UTC midnight: 24h (default) or a divisor such as 1h
+	RotateSize int64         // Bytes written to a file before the next part is started, 0 for no limit
+}
+
+// CSVClient writes snapshots to flat CSV files, one per exchange, symbol and period
+// (e.g. csv/bybit_BTCUSDT_2024-05-01.csv), each starting with a header row. After a
+// restart rows are appended to the current file when its header still matches the columns;
+// otherwise, and once RotateSize is reached, a numbered part (..._2024-05-01.1.csv) is started
+type CSVClient struct {
+	config CSVConfig
+	mu     sync.Mutex
+	closed bool
+	files  map[string]*csvFile // Keyed by exchange and symbol
+}
+
+// csvFile is the file currently written for one exchange and symbol
+type csvFile struct {
+	path    string
+	period  time.Time // Start of the period the file covers
+	part    int
+	file    *os.File
+	buf     *bufio.Writer
+	writer  *csv.Writer
+	written int64 // Bytes in the file, counted as buffered rows are flushed
+}
+
+// NewCSVClient checks the columns and creates the directory; files are only created once rows arrive
+func NewCSVClient(config CSVConfig) (*CSVClient, error) {
+	if config.Dir == "" {
+		config.Dir = "csv"
+	}
+	if len(config.Columns) == 0 {
+		config.Columns = DefaultCSVColumns
+	}
+	if config.Period <= 0 {
+		config.Period = 24 * time.Hour
+	}
+	if (24*time.Hour)%config.Period != 0 {
+		return nil, fmt.Errorf("CSV period %v does not divide a day", config.Period)
+	}
+	for _, column := range config.Columns {
+		if !validCSVColumn(column) {
+			return nil, fmt.Errorf("unknown CSV column %q", column)
+		}
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create CSV directory: %w", err)
+	}
+	return &CSVClient{config: config, files: make(map[string]*csvFile)}, nil
+}
+
+// validCSVColumn reports whether a column names a snapshot field
+func validCSVColumn(column string) bool {
+	for _, known := range [][]string{DefaultCSVColumns, csvRollingColumns, csvFlowColumns, csvFundingColumns, csvBasisColumns, csvResiliencyColumns, csvMarketShareColumns, csvEffectiveSpreadColumns, csvIndexColumns, csvTouchColumns} {
+		for _, name := range known {
+			if column == name {
+				return true
+			}
+		}
+	}
+	if strings.ContainsAny(column, ",\"\r\n") {
+		return false
+	}
+	for _, prefix := range csvColumnPrefixes {
+		if len(column) > len(prefix) && strings.HasPrefix(column, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// InsertOrderbookSnapshot writes a single snapshot
+func (c *CSVClient) InsertOrderbookSnapshot(snapshot *OrderbookSnapshotAPI) error {
+	return c.InsertOrderbookSnapshotsBatch([]*OrderbookSnapshotAPI{snapshot})
+}
+
+// InsertOrderbookSnapshotsBatch appends one row per snapshot and flushes the files written to,
+// so every stored row can be read right away
+func (c *CSVClient) InsertOrderbookSnapshotsBatch(snapshots []*OrderbookSnapshotAPI) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return fmt.Errorf("CSV client is closed")
+	}
+
+	touched := make(map[*csvFile]bool)
+	var firstErr error
+	for _, s := range snapshots {
+		file, err := c.fileFor(s)
+		if err == nil {
+			err = file.writeRow(csvRow(c.config.Columns, s))
+		}
+		if err != nil {
+			firstErr = err
+			break
+		}
+		touched[file] = true
+	}
+
+	for file := range touched {
+		if err := file.flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// fileFor returns the file a snapshot belongs to, moving on to the next period or part as
+// needed (must be called with mutex locked)
+func (c *CSVClient) fileFor(s *OrderbookSnapshotAPI) (*csvFile, error) {
+	key := s.Exchange + "\x00" + s.Symbol
+	period := s.Timestamp.UTC().Truncate(c.config.Period)
+
+	file, ok := c.files[key]
+	if ok && file.period.Equal(period) && (c.config.RotateSize <= 0 || file.written < c.config.RotateSize) {
+		return file, nil
+	}
+	part := 0
+	if ok {
+		if err := file.close(); err != nil {
+			return nil, err
+		}
+		delete(c.files, key)
+		if file.period.Equal(period) {
+			part = file.part + 1
+		}
+	}
+
+	file, err := c.open(s.Exchange, s.Symbol, period, part)
+	if err != nil {
+		return nil, err
+	}
+	c.files[key] = file
+	return file, nil
+}
+
+// open appends to the first part of a period, from part on, whose header matches the
+// columns and that has room left, or creates one
+func (c *CSVClient) open(exchange, symbol string, period time.Time, part int) (*csvFile, error) {
+	layout := "2006-01-02"
+	if c.config.Period < 24*time.Hour {
+		layout = "2006-01-02T1504"
+	}
+	base := partitionValue(exchange) + "_" + partitionValue(symbol) + "_" + period.Format(layout)
+	header := strings.Join(c.config.Columns, ",")
+
+	for ; ; part++ {
+		name := base + ".csv"
+		if part > 0 {
+			name = base + "." + strconv.Itoa(part) + ".csv"
+		}
+		path := filepath.Join(c.config.Dir, name)
+
+		existing, err := csvHeader(path)
+		if err != nil {
+			return nil, err
+		}
+		if existing.found && (existing.header != header || (c.config.RotateSize > 0 && existing.size >= c.config.RotateSize)) {
+			continue
+		}
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open CSV file: %w", err)
+		}
+		file := &csvFile{path: path, period: period, part: part, file: f, written: existing.size}
+		file.buf = bufio.NewWriter(&csvCounter{file: file})
+		file.writer = csv.NewWriter(file.buf)
+		if !existing.found {
+			if err := file.writeRow(c.config.Columns); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+		return file, nil
+	}
+}
+
+// csvExisting describes a file found on disk
+type csvExisting struct {
+	found  bool
+	header string
+	size   int64
+}
+
+// csvHeader reads the first line of a file, if it exists
+func csvHeader(path string) (csvExisting, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return csvExisting{}, nil
+	}
+	if err != nil {
+		return csvExisting{}, fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return csvExisting{}, fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	if info.Size() == 0 {
+		return csvExisting{}, nil
+	}
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return csvExisting{}, fmt.Errorf("failed to read CSV header of %s: %w", path, err)
+	}
+	return csvExisting{found: true, header: strings.TrimRight(line, "\r\n"), size: info.Size()}, nil
+}
+
+// writeRow buffers one record
+func (f *csvFile) writeRow(record []string) error {
+	if err := f.writer.Write(record); err != nil {
+		return fmt.Errorf("failed to write CSV row to %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// flush writes buffered rows to the file
+func (f *csvFile) flush() error {
+	f.writer.Flush()
+	if err := f.writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV file %s: %w", f.path, err)
+	}
+	if err := f.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write CSV file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// close flushes and closes the file
+func (f *csvFile) close() error {
+	err := f.flush()
+	if closeErr := f.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close CSV file %s: %w", f.path, closeErr)
+	}
+	return err
+}
+
+// csvCounter writes to the file of a csvFile, counting the bytes toward its rotation size
+type csvCounter struct {
+	file *csvFile
+}
+
+func (c *csvCounter) Write(p []byte) (int, error) {
+	n, err := c.file.file.Write(p)
+	c.file.written += int64(n)
+	return n, err
+}
+
+// csvRow formats the columns of a snapshot, leaving metrics it does not have empty
+func csvRow(columns []string, s *OrderbookSnapshotAPI) []string {
+	var fields map[string]float64
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "timestamp":
+			row[i] = s.Timestamp.UTC().Format(csvTimeLayout)
+		case "exchange":
+			row[i] = s.Exchange
+		case "symbol":
+			row[i] = s.Symbol
+		default:
+			if fields == nil {
+				fields = snapshotFields(s)
+			}
+			if value, ok := fields[column]; ok {
+				row[i] = strconv.FormatFloat(value, 'f', -1, 64)
+			}
+		}
+	}
+	return row
+}
+
+// TestConnection checks that the directory is writable
+func (c *CSVClient) TestConnection() error {
+	probe, err := os.CreateTemp(c.config.Dir, ".probe-*")
+	if err != nil {
+		return fmt.Errorf("CSV directory is not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// Close flushes and closes every open file
+func (c *CSVClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	var firstErr error
+	for _, file := range c.files {
+		if err := file.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.files = nil
+	return firstErr
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVClient(t *testing.T) {
+	dir := t.TempDir()
+	columns := []string{"timestamp", "exchange", "best_bid", "imbalance_5", "band_bid_25bp"}
+	client, err := NewCSVClient(CSVConfig{Dir: dir, Columns: columns})
+	if err != nil {
+		t.Fatalf("NewCSVClient() error: %v", err)
+	}
+	if err := client.TestConnection(); err != nil {
+		t.Fatalf("TestConnection() error: %v", err)
+	}
+
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bid, band := 99.5, 3.0
+	snapshots := []*OrderbookSnapshotAPI{
+		{Exchange: "bybit", Symbol: "BTC/USDT", Timestamp: day, BestBid: &bid,
+			Imbalance:      map[string]ImbalanceAPI{"5": {Imbalance: 0.6}},
+			LiquidityBands: map[string]BandLiquidityAPI{"25bp": {Bid: &band}}},
+		{Exchange: "bybit", Symbol: "BTC/USDT", Timestamp: day.Add(time.Second)},
+		{Exchange: "bybit", Symbol: "BTC/USDT", Timestamp: day.Add(24 * time.Hour)},
+	}
+	if err := client.InsertOrderbookSnapshotsBatch(snapshots); err != nil {
+		t.Fatalf("InsertOrderbookSnapshotsBatch() error: %v", err)
+	}
+
+	// Rows are readable before Close
+	data, err := os.ReadFile(filepath.Join(dir, "bybit_BTC_USDT_2024-05-01.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "timestamp,exchange,best_bid,imbalance_5,band_bid_25bp\n" +
+		"2024-05-01 12:00:00.000,bybit,99.5,0.6,3\n" +
+		"2024-05-01 12:00:01.000,bybit,,,\n"
+	if string(data) != want {
+		t.Errorf("unexpected file:\n%s", data)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bybit_BTC_USDT_2024-05-02.csv")); err != nil {
+		t.Errorf("expected a file for the next day: %v", err)
+	}
+
+	// A restart appends to a file with the same columns and starts a part for new ones
+	again, err := NewCSVClient(CSVConfig{Dir: dir, Columns: columns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := again.InsertOrderbookSnapshot(snapshots[1]); err != nil {
+		t.Fatal(err)
+	}
+	again.Close()
+	if data, _ := os.ReadFile(filepath.Join(dir, "bybit_BTC_USDT_2024-05-01.csv")); strings.Count(string(data), "\n") != 4 {
+		t.Errorf("expected the row appended below the header, got:\n%s", data)
+	}
+
+	other, err := NewCSVClient(CSVConfig{Dir: dir, Columns: []string{"timestamp", "mid_price"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.InsertOrderbookSnapshot(snapshots[0]); err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+	if data, err := os.ReadFile(filepath.Join(dir, "bybit_BTC_USDT_2024-05-01.1.csv")); err != nil || !strings.HasPrefix(string(data), "timestamp,mid_price\n") {
+		t.Errorf("expected a new part for the new columns, got %q (%v)", data, err)
+	}
+
+	if _, err := NewCSVClient(CSVConfig{Dir: dir, Columns: []string{"best_bd"}}); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+	if _, err := NewCSVClient(CSVConfig{Dir: dir, Period: 7 * time.Hour}); err == nil {
+		t.Error("expected an error for a period that does not divide a day")
+	}
+}
+
+func TestCSVClientRotateSize(t *testing.T) {
+	dir := t.TempDir()
+	client, err := NewCSVClient(CSVConfig{Dir: dir, Columns: []string{"timestamp"}, Period: time.Hour, RotateSize: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		snapshot := &OrderbookSnapshotAPI{Exchange: "okx", Symbol: "BTCUSDT", Timestamp: start.Add(time.Duration(i) * time.Second)}
+		if err := client.InsertOrderbookSnapshot(snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Close()
+
+	// The header and first row fill the 30 bytes, so every later row starts a part
+	for _, name := range []string{"okx_BTCUSDT_2024-05-01T1200.csv", "okx_BTCUSDT_2024-05-01T1200.1.csv", "okx_BTCUSDT_2024-05-01T1200.2.csv"} {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err != nil || !strings.HasPrefix(string(data), "timestamp\n") {
+			t.Errorf("unexpected %s: %q (%v)", name, data, err)
+		}
+	}
+}
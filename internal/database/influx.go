@@ -0,0 +1,314 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfluxConfig configures the InfluxDB v2 client
+type InfluxConfig struct {
+	URL         string // e.g. http://localhost:8086
+	Token       string // API token with write access to the bucket
+	Org         string
+	Bucket      string
+	Measurement string // Default orderbook
+}
+
+// InfluxClient writes snapshots as points in InfluxDB v2 line protocol, one point per
+// snapshot tagged with exchange and symbol. Map-valued metrics become one field per key,
+// e.g. imbalance_5, band_bid_25bp or impact_buy_slippage_10
+type InfluxClient struct {
+	config InfluxConfig
+	client *http.Client
+}
+
+// NewInfluxClient creates a client for the bucket
+func NewInfluxClient(config InfluxConfig) (*InfluxClient, error) {
+	if config.URL == "" || config.Org == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("InfluxDB URL, org and bucket are required")
+	}
+	if config.Measurement == "" {
+		config.Measurement = "orderbook"
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	return &InfluxClient{
+		config: config,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// InsertOrderbookSnapshot writes a single snapshot
+func (c *InfluxClient) InsertOrderbookSnapshot(snapshot *OrderbookSnapshotAPI) error {
+	return c.InsertOrderbookSnapshotsBatch([]*OrderbookSnapshotAPI{snapshot})
+}
+
+// InsertOrderbookSnapshotsBatch writes snapshots in one request
+func (c *InfluxClient) InsertOrderbookSnapshotsBatch(snapshots []*OrderbookSnapshotAPI) error {
+	var body bytes.Buffer
+	for _, s := range snapshots {
+		if line := influxLine(c.config.Measurement, s); line != "" {
+			body.WriteString(line)
+			body.WriteByte('\n')
+		}
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	query := url.Values{"org": {c.config.Org}, "bucket": {c.config.Bucket}, "precision": {"ms"}}
+	req, err := http.NewRequest("POST", c.config.URL+"/api/v2/write?"+query.Encode(), &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Authorization", "Token "+c.config.Token)
+
+	return c.do(req)
+}
+
+// TestConnection checks that the server is up and the token can see the bucket
+func (c *InfluxClient) TestConnection() error {
+	query := url.Values{"org": {c.config.Org}, "name": {c.config.Bucket}}
+	req, err := http.NewRequest("GET", c.config.URL+"/api/v2/buckets?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+c.config.Token)
+
+	return c.do(req)
+}
+
+// do executes a request, failing on any non-2xx status
+func (c *InfluxClient) do(req *http.Request) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("InfluxDB request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// Close is a no-op for the HTTP client
+func (c *InfluxClient) Close() error {
+	return nil
+}
+
+// influxLine encodes a snapshot as one line-protocol point, or "" when it has no fields
+func influxLine(measurement string, s *OrderbookSnapshotAPI) string {
+	fields := make(map[string]string)
+	for key, value := range snapshotFields(s) {
+		fields[key] = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	if s.Rolling != nil {
+		fields["rolling_samples"] = strconv.Itoa(s.Rolling.Samples) + "i"
+	}
+	if s.Flow != nil {
+		fields["flow_trades"] = strconv.Itoa(s.Flow.Trades) + "i"
+		fields["flow_large_trades"] = strconv.Itoa(s.Flow.LargeTrades) + "i"
+	}
+	if s.Resiliency != nil {
+		fields["resiliency_depletions"] = strconv.Itoa(s.Resiliency.Depletions) + "i"
+		fields["resiliency_recovered"] = strconv.Itoa(s.Resiliency.Recovered) + "i"
+	}
+	for window, value := range s.Volatility {
+		fields["volatility_returns_"+window] = strconv.Itoa(value.Returns) + "i"
+	}
+	for window, value := range s.OFI {
+		fields["ofi_updates_"+window] = strconv.Itoa(value.Updates) + "i"
+	}
+	if s.IndexPrice != nil {
+		fields["index_venues"] = strconv.Itoa(s.IndexPrice.Venues) + "i"
+	}
+	if s.Touch != nil {
+		fields["touch_updates"] = strconv.Itoa(s.Touch.Updates) + "i"
+	}
+
+	if len(fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var line strings.Builder
+	line.WriteString(influxEscaper.Replace(measurement))
+	line.WriteString(",exchange=" + influxEscaper.Replace(s.Exchange))
+	line.WriteString(",symbol=" + influxEscaper.Replace(s.Symbol))
+	for i, key := range keys {
+		if i == 0 {
+			line.WriteByte(' ')
+		} else {
+			line.WriteByte(',')
+		}
+		line.WriteString(influxEscaper.Replace(key) + "=" + fields[key])
+	}
+	line.WriteString(" " + strconv.FormatInt(s.Timestamp.UnixMilli(), 10))
+	return line.String()
+}
+
+// influxEscaper escapes the characters line protocol reserves in measurements, tags and field keys
+var influxEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// snapshotFields flattens the metrics a snapshot has into one value per field, map-valued
+// metrics becoming one field per key, e.g. imbalance_5, band_bid_25bp or impact_buy_slippage_10
+func snapshotFields(s *OrderbookSnapshotAPI) map[string]float64 {
+	fields := make(map[string]float64)
+	float := func(key string, value *float64) {
+		if value != nil {
+			fields[key] = *value
+		}
+	}
+	floats := func(prefix string, values map[string]float64) {
+		for key, value := range values {
+			fields[prefix+"_"+key] = value
+		}
+	}
+
+	float("best_bid", s.BestBid)
+	float("best_ask", s.BestAsk)
+	float("mid_price", s.MidPrice)
+	float("spread", s.Spread)
+	float("bid_liquidity_05_pct", s.BidLiquidity05Pct)
+	float("ask_liquidity_05_pct", s.AskLiquidity05Pct)
+	float("bid_liquidity_2_pct", s.BidLiquidity2Pct)
+	float("ask_liquidity_2_pct", s.AskLiquidity2Pct)
+	float("bid_liquidity_10_pct", s.BidLiquidity10Pct)
+	float("ask_liquidity_10_pct", s.AskLiquidity10Pct)
+	float("total_bids_qty", s.TotalBidsQty)
+	float("total_asks_qty", s.TotalAsksQty)
+	float("bid_notional_05_pct", s.BidNotional05Pct)
+	float("ask_notional_05_pct", s.AskNotional05Pct)
+	float("bid_notional_2_pct", s.BidNotional2Pct)
+	float("ask_notional_2_pct", s.AskNotional2Pct)
+	float("bid_notional_10_pct", s.BidNotional10Pct)
+	float("ask_notional_10_pct", s.AskNotional10Pct)
+	float("total_bids_notional", s.TotalBidsNotional)
+	float("total_asks_notional", s.TotalAsksNotional)
+	float("microprice", s.Microprice)
+
+	for levels, value := range s.Imbalance {
+		float("imbalance_"+levels, &value.Imbalance)
+		float("weighted_mid_"+levels, &value.WeightedMid)
+	}
+	bandBid, bandAsk, bandBidNotional, bandAskNotional := splitBands(s.LiquidityBands)
+	floats("band_bid", bandBid)
+	floats("band_ask", bandAsk)
+	floats("band_bid_notional", bandBidNotional)
+	floats("band_ask_notional", bandAskNotional)
+	curveBid, curveAsk, curveBidNotional, curveAskNotional := splitBands(s.DepthCurve)
+	floats("curve_bid", curveBid)
+	floats("curve_ask", curveAsk)
+	floats("curve_bid_notional", curveBidNotional)
+	floats("curve_ask_notional", curveAskNotional)
+	buyVWAP, buySlippage, sellVWAP, sellSlippage := splitImpact(s.Impact)
+	floats("impact_buy_vwap", buyVWAP)
+	floats("impact_buy_slippage", buySlippage)
+	floats("impact_sell_vwap", sellVWAP)
+	floats("impact_sell_slippage", sellSlippage)
+	notionalBuyVWAP, notionalBuySlippage, notionalSellVWAP, notionalSellSlippage := splitImpact(s.Slippage)
+	floats("slippage_buy_vwap", notionalBuyVWAP)
+	floats("slippage_buy", notionalBuySlippage)
+	floats("slippage_sell_vwap", notionalSellVWAP)
+	floats("slippage_sell", notionalSellSlippage)
+
+	if s.Rolling != nil {
+		float("rolling_window_seconds", &s.Rolling.WindowSeconds)
+		fields["rolling_samples"] = float64(s.Rolling.Samples)
+		float("rolling_avg_spread", &s.Rolling.AvgSpread)
+		float("rolling_spread_volatility", &s.Rolling.SpreadVolatility)
+		float("rolling_mid_return_volatility", &s.Rolling.MidReturnVolatility)
+	}
+	if f := s.Flow; f != nil {
+		float("flow_window_seconds", &f.WindowSeconds)
+		float("flow_cvd", &f.CVD)
+		float("flow_buy_volume", &f.BuyVolume)
+		float("flow_sell_volume", &f.SellVolume)
+		float("flow_buy_ratio", &f.BuyRatio)
+		fields["flow_trades"] = float64(f.Trades)
+		fields["flow_large_trades"] = float64(f.LargeTrades)
+	}
+	if f := s.Funding; f != nil {
+		float("funding_rate", &f.Rate)
+		float("funding_seconds_to_funding", &f.SecondsToFunding)
+		float("funding_mark_price", &f.MarkPrice)
+		float("funding_index_price", &f.IndexPrice)
+		float("funding_open_interest", &f.OpenInterest)
+		float("funding_open_interest_notional", &f.OpenInterestNotional)
+		float("funding_open_interest_change", &f.OpenInterestChange)
+		float("funding_window_seconds", &f.WindowSeconds)
+	}
+	if b := s.Basis; b != nil {
+		float("basis_spot_mid", &b.SpotMid)
+		float("basis_bps", &b.BasisBps)
+		float("basis_annualized", &b.AnnualizedBasis)
+		float("basis_annualized_funding", &b.AnnualizedFunding)
+	}
+	if r := s.Resiliency; r != nil {
+		float("resiliency_score", &r.Score)
+		fields["resiliency_depletions"] = float64(r.Depletions)
+		fields["resiliency_recovered"] = float64(r.Recovered)
+		float("resiliency_mean_recovery_seconds", &r.MeanRecoverySeconds)
+		float("resiliency_replenishment", &r.Replenishment)
+		float("resiliency_bid_depth", &r.BidDepth)
+		float("resiliency_ask_depth", &r.AskDepth)
+		float("resiliency_window_seconds", &r.WindowSeconds)
+	}
+	if m := s.MarketShare; m != nil {
+		shareBid, shareAsk, shareTotal := splitShares(m.Bands)
+		floats("share_bid", shareBid)
+		floats("share_ask", shareAsk)
+		floats("share_total", shareTotal)
+		float("share_best_bid", &m.BestBid)
+		float("share_best_ask", &m.BestAsk)
+		float("share_sampled_seconds", &m.SampledSeconds)
+	}
+	for window, value := range s.Volatility {
+		float("volatility_"+window, &value.Volatility)
+		float("volatility_annualized_"+window, &value.Annualized)
+		fields["volatility_returns_"+window] = float64(value.Returns)
+	}
+	for window, value := range s.OFI {
+		float("ofi_"+window, &value.OFI)
+		fields["ofi_updates_"+window] = float64(value.Updates)
+	}
+	if e := s.EffectiveSpread; e != nil {
+		float("effective_spread_raw_bps", &e.RawBps)
+		float("effective_spread_taker_bps", &e.TakerBps)
+		float("effective_spread_maker_bps", &e.MakerBps)
+		float("maker_fee_bps", &e.MakerFeeBps)
+		float("taker_fee_bps", &e.TakerFeeBps)
+	}
+	if x := s.IndexPrice; x != nil {
+		float("index_price", &x.Price)
+		fields["index_venues"] = float64(x.Venues)
+		for name, weight := range x.Weights {
+			fields["index_weight_"+name] = weight
+		}
+	}
+	if t := s.Touch; t != nil {
+		float("touch_bid_mean", &t.BidMean)
+		float("touch_bid_min", &t.BidMin)
+		float("touch_bid_time_weighted", &t.BidTimeWeighted)
+		float("touch_ask_mean", &t.AskMean)
+		float("touch_ask_min", &t.AskMin)
+		float("touch_ask_time_weighted", &t.AskTimeWeighted)
+		fields["touch_updates"] = float64(t.Updates)
+	}
+	return fields
+}
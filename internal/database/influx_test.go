@@ -0,0 +1,75 @@
+package database
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfluxLine(t *testing.T) {
+	bid, ask, band := 99.5, 100.5, 3.0
+	snapshot := &OrderbookSnapshotAPI{
+		Exchange:       "binancef",
+		Symbol:         "BTC USDT",
+		Timestamp:      time.UnixMilli(1700000000123),
+		BestBid:        &bid,
+		BestAsk:        &ask,
+		Imbalance:      map[string]ImbalanceAPI{"5": {Imbalance: 0.6, WeightedMid: 100.1}},
+		LiquidityBands: map[string]BandLiquidityAPI{"25bp": {Bid: &band}},
+		Rolling:        &RollingAPI{WindowSeconds: 300, Samples: 12},
+	}
+
+	want := `orderbook,exchange=binancef,symbol=BTC\ USDT band_bid_25bp=3,best_ask=100.5,best_bid=99.5,imbalance_5=0.6,` +
+		`rolling_avg_spread=0,rolling_mid_return_volatility=0,rolling_samples=12i,rolling_spread_volatility=0,` +
+		`rolling_window_seconds=300,weighted_mid_5=100.1 1700000000123`
+	if got := influxLine("orderbook", snapshot); got != want {
+		t.Errorf("unexpected line\n got %s\nwant %s", got, want)
+	}
+	if got := influxLine("orderbook", &OrderbookSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT"}); got != "" {
+		t.Errorf("expected no line without fields, got %q", got)
+	}
+}
+
+func TestInfluxClient(t *testing.T) {
+	var body, query, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, query, auth = string(data), r.URL.RawQuery, r.Header.Get("Authorization")
+		if r.URL.Path != "/api/v2/write" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewInfluxClient(InfluxConfig{URL: server.URL + "/", Token: "secret", Org: "desk", Bucket: "books"})
+	if err != nil {
+		t.Fatalf("NewInfluxClient() error: %v", err)
+	}
+	if err := client.TestConnection(); err != nil {
+		t.Fatalf("TestConnection() error: %v", err)
+	}
+
+	bid := 99.5
+	snapshots := []*OrderbookSnapshotAPI{
+		{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: time.UnixMilli(1), BestBid: &bid},
+		{Exchange: "okx", Symbol: "BTCUSDT", Timestamp: time.UnixMilli(2), BestBid: &bid},
+	}
+	if err := client.InsertOrderbookSnapshotsBatch(snapshots); err != nil {
+		t.Fatalf("InsertOrderbookSnapshotsBatch() error: %v", err)
+	}
+	if auth != "Token secret" || query != "bucket=books&org=desk&precision=ms" {
+		t.Errorf("unexpected request auth %q query %q", auth, query)
+	}
+	if lines := strings.Split(strings.TrimSpace(body), "\n"); len(lines) != 2 || lines[1] != "orderbook,exchange=okx,symbol=BTCUSDT best_bid=99.5 2" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	if _, err := NewInfluxClient(InfluxConfig{URL: server.URL}); err == nil {
+		t.Error("expected an error without org and bucket")
+	}
+}
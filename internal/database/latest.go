@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Latest support: SQLite, PostgreSQL, Supabase and ClickHouse keep orderbook_latest, one
+// row per exchange and symbol with the orderbook_snapshots columns, upserted alongside the
+// append-only history so dashboards can read current state without scanning for max(timestamp)
+
+// latestTable holds the most recent snapshot of every book
+const latestTable = "orderbook_latest"
+
+// latestTableSchema rewrites the CREATE TABLE of orderbook_snapshots into orderbook_latest,
+// replacing its generated id with a primary key on exchange and symbol
+func latestTableSchema(statement string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.Replace(statement, "orderbook_snapshots", latestTable, 1), "\n") {
+		if !strings.Contains(line, "PRIMARY KEY") {
+			lines = append(lines, line)
+		}
+	}
+	last := len(lines) - 1
+	lines[last-1] += ","
+	lines = append(lines[:last], "\t\tPRIMARY KEY (exchange, symbol)", lines[last])
+	return strings.Join(lines, "\n")
+}
+
+// clickHouseLatestSchema turns the orderbook_snapshots table into orderbook_latest, whose
+// ReplacingMergeTree keeps the newest row per book once parts merge; read it with FINAL
+func clickHouseLatestSchema(statement string) string {
+	statement = strings.Replace(statement, "orderbook_snapshots", latestTable, 1)
+	return statement[:strings.Index(statement, ") ENGINE")] + ") ENGINE = ReplacingMergeTree(timestamp)\n\tORDER BY (exchange, symbol)"
+}
+
+// latestPerBook keeps the newest snapshot of each book, in the order books first appear,
+// since one upsert statement may not touch a row twice
+func latestPerBook(snapshots []*OrderbookSnapshotAPI) []*OrderbookSnapshotAPI {
+	index := make(map[[2]string]int, len(snapshots))
+	var latest []*OrderbookSnapshotAPI
+	for _, s := range snapshots {
+		key := [2]string{s.Exchange, s.Symbol}
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(latest)
+			latest = append(latest, s)
+		} else if !s.Timestamp.Before(latest[i].Timestamp) {
+			latest[i] = s
+		}
+	}
+	return latest
+}
+
+// upsertClause updates every column but the book's key when the book already has a row
+func upsertClause(columns []string) string {
+	var updates []string
+	for _, column := range columns {
+		if column != "exchange" && column != "symbol" {
+			updates = append(updates, column+" = excluded."+column)
+		}
+	}
+	return " ON CONFLICT (exchange, symbol) DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// UpsertLatestSnapshots replaces the orderbook_latest row of each book in one transaction
+func (c *SQLiteClient) UpsertLatestSnapshots(snapshots []*OrderbookSnapshotAPI) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)%s", latestTable,
+		strings.Join(sqliteSnapshotColumns, ", "), placeholders(len(sqliteSnapshotColumns)), upsertClause(sqliteSnapshotColumns))
+	return c.insert(query, len(snapshots), func(stmt *sql.Stmt, i int) error {
+		values, err := sqliteSnapshotValues(snapshots[i])
+		if err != nil {
+			return err
+		}
+		_, err = stmt.Exec(values...)
+		return err
+	})
+}
+
+// UpsertLatestSnapshots replaces the orderbook_latest row of each book with multi-row statements
+func (c *PostgresClient) UpsertLatestSnapshots(snapshots []*OrderbookSnapshotAPI) error {
+	snapshots = latestPerBook(snapshots)
+	rows := make([][]any, 0, len(snapshots))
+	for _, s := range snapshots {
+		values, err := sqliteSnapshotValues(s)
+		if err != nil {
+			return err
+		}
+		values[2] = s.Timestamp
+		rows = append(rows, values)
+	}
+	return c.insertWith(latestTable, sqliteSnapshotColumns, rows, upsertClause(sqliteSnapshotColumns))
+}
+
+// UpsertLatestSnapshots replaces the orderbook_latest row of each book, or queues them
+// after StartAsync. The table needs the orderbook_snapshots columns and a unique
+// constraint on (exchange, symbol)
+func (c *SupabaseAPIClient) UpsertLatestSnapshots(snapshots []*OrderbookSnapshotAPI) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	if c.async != nil {
+		return c.async.enqueue(supabaseRows{latest: snapshots})
+	}
+	return c.postLatest(snapshots)
+}
+
+// postLatest upserts snapshots in one request
+func (c *SupabaseAPIClient) postLatest(snapshots []*OrderbookSnapshotAPI) error {
+	body, err := json.Marshal(latestPerBook(snapshots))
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshots: %w", err)
+	}
+	_, err = c.restRequest("POST", latestTable, url.Values{"on_conflict": {"exchange,symbol"}}, body)
+	return err
+}
+
+// UpsertLatestSnapshots queues snapshots for orderbook_latest; an error means they were dropped
+func (c *ClickHouseClient) UpsertLatestSnapshots(snapshots []*OrderbookSnapshotAPI) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return c.enqueue(clickHouseRows{latest: snapshots}, len(snapshots))
+}
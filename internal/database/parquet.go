@@ -0,0 +1,542 @@
+package database
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ParquetConfig configures the Parquet archive writer
+type ParquetConfig struct {
+	Dir            string        // Root of the archive, default parquet
+	RotateSize     int64         // Bytes written to a file before it is rotated, default 128MB
+	RotateInterval time.Duration // Longest a file stays open, default 1h
+	RowGroupRows   int64         // Rows per row group; rotation size is checked per row group, default 5000
+
+	// Upload, when set, is called in the background with each finished file and its key
+	// relative to Dir (e.g. orderbook_snapshots/date=2024-05-01/exchange=bybit/symbol=BTCUSDT/part-....parquet)
+	// so it can be copied to S3 or GCS; see CommandUpload
+	Upload func(path, key string) error
+}
+
+// ParquetClient archives snapshots as rolling Parquet files partitioned Hive-style by
+// table, date, exchange and symbol, readable as one dataset by pandas, DuckDB or Spark.
+// Files are written under a .tmp name and renamed once their footer is written, so
+// readers scanning the directory only ever see complete files
+type ParquetClient struct {
+	config    ParquetConfig
+	mu        sync.Mutex
+	closed    bool
+	snapshots *parquetTable[parquetSnapshot]
+	levels    *parquetTable[parquetLevel]
+	trades    *parquetTable[parquetTrade]
+	uploads   sync.WaitGroup
+}
+
+// parquetSnapshot is one row of orderbook_snapshots; columns match the ClickHouse table
+type parquetSnapshot struct {
+	Exchange                   string             `parquet:"exchange,dict"`
+	Symbol                     string             `parquet:"symbol,dict"`
+	Timestamp                  int64              `parquet:"timestamp,timestamp(millisecond)"`
+	BestBid                    *float64           `parquet:"best_bid,optional"`
+	BestAsk                    *float64           `parquet:"best_ask,optional"`
+	MidPrice                   *float64           `parquet:"mid_price,optional"`
+	Spread                     *float64           `parquet:"spread,optional"`
+	BidLiquidity05Pct          *float64           `parquet:"bid_liquidity_05_pct,optional"`
+	AskLiquidity05Pct          *float64           `parquet:"ask_liquidity_05_pct,optional"`
+	BidLiquidity2Pct           *float64           `parquet:"bid_liquidity_2_pct,optional"`
+	AskLiquidity2Pct           *float64           `parquet:"ask_liquidity_2_pct,optional"`
+	BidLiquidity10Pct          *float64           `parquet:"bid_liquidity_10_pct,optional"`
+	AskLiquidity10Pct          *float64           `parquet:"ask_liquidity_10_pct,optional"`
+	TotalBidsQty               *float64           `parquet:"total_bids_qty,optional"`
+	TotalAsksQty               *float64           `parquet:"total_asks_qty,optional"`
+	BidNotional05Pct           *float64           `parquet:"bid_notional_05_pct,optional"`
+	AskNotional05Pct           *float64           `parquet:"ask_notional_05_pct,optional"`
+	BidNotional2Pct            *float64           `parquet:"bid_notional_2_pct,optional"`
+	AskNotional2Pct            *float64           `parquet:"ask_notional_2_pct,optional"`
+	BidNotional10Pct           *float64           `parquet:"bid_notional_10_pct,optional"`
+	AskNotional10Pct           *float64           `parquet:"ask_notional_10_pct,optional"`
+	TotalBidsNotional          *float64           `parquet:"total_bids_notional,optional"`
+	TotalAsksNotional          *float64           `parquet:"total_asks_notional,optional"`
+	Microprice                 *float64           `parquet:"microprice,optional"`
+	Imbalance                  map[string]float64 `parquet:"imbalance"`
+	WeightedMid                map[string]float64 `parquet:"weighted_mid"`
+	BandBid                    map[string]float64 `parquet:"band_bid"`
+	BandAsk                    map[string]float64 `parquet:"band_ask"`
+	BandBidNotional            map[string]float64 `parquet:"band_bid_notional"`
+	BandAskNotional            map[string]float64 `parquet:"band_ask_notional"`
+	CurveBid                   map[string]float64 `parquet:"curve_bid"`
+	CurveAsk                   map[string]float64 `parquet:"curve_ask"`
+	CurveBidNotional           map[string]float64 `parquet:"curve_bid_notional"`
+	CurveAskNotional           map[string]float64 `parquet:"curve_ask_notional"`
+	ImpactBuyVWAP              map[string]float64 `parquet:"impact_buy_vwap"`
+	ImpactBuySlippage          map[string]float64 `parquet:"impact_buy_slippage"`
+	ImpactSellVWAP             map[string]float64 `parquet:"impact_sell_vwap"`
+	ImpactSellSlippage         map[string]float64 `parquet:"impact_sell_slippage"`
+	RollingWindowSeconds       *float64           `parquet:"rolling_window_seconds,optional"`
+	RollingSamples             *int32             `parquet:"rolling_samples,optional"`
+	RollingAvgSpread           *float64           `parquet:"rolling_avg_spread,optional"`
+	RollingSpreadVolatility    *float64           `parquet:"rolling_spread_volatility,optional"`
+	RollingMidReturnVolatility *float64           `parquet:"rolling_mid_return_volatility,optional"`
+	FlowWindowSeconds          *float64           `parquet:"flow_window_seconds,optional"`
+	FlowCVD                    *float64           `parquet:"flow_cvd,optional"`
+	FlowBuyVolume              *float64           `parquet:"flow_buy_volume,optional"`
+	FlowSellVolume             *float64           `parquet:"flow_sell_volume,optional"`
+	FlowBuyRatio               *float64           `parquet:"flow_buy_ratio,optional"`
+	FlowTrades                 *int32             `parquet:"flow_trades,optional"`
+	FlowLargeTrades            *int32             `parquet:"flow_large_trades,optional"`
+	FundingRate                *float64           `parquet:"funding_rate,optional"`
+	FundingSecondsToFunding    *float64           `parquet:"funding_seconds_to_funding,optional"`
+	FundingMarkPrice           *float64           `parquet:"funding_mark_price,optional"`
+	FundingIndexPrice          *float64           `parquet:"funding_index_price,optional"`
+	FundingOpenInterest        *float64           `parquet:"funding_open_interest,optional"`
+	FundingOINotional          *float64           `parquet:"funding_open_interest_notional,optional"`
+	FundingOIChange            *float64           `parquet:"funding_open_interest_change,optional"`
+	FundingWindowSeconds       *float64           `parquet:"funding_window_seconds,optional"`
+	BasisSpotExchange          *string            `parquet:"basis_spot_exchange,optional,dict"`
+	BasisSpotMid               *float64           `parquet:"basis_spot_mid,optional"`
+	BasisBps                   *float64           `parquet:"basis_bps,optional"`
+	BasisAnnualized            *float64           `parquet:"basis_annualized,optional"`
+	BasisAnnualizedFunding     *float64           `parquet:"basis_annualized_funding,optional"`
+	SlippageBuyVWAP            map[string]float64 `parquet:"slippage_buy_vwap"`
+	SlippageBuy                map[string]float64 `parquet:"slippage_buy"`
+	SlippageSellVWAP           map[string]float64 `parquet:"slippage_sell_vwap"`
+	SlippageSell               map[string]float64 `parquet:"slippage_sell"`
+	ResiliencyScore            *float64           `parquet:"resiliency_score,optional"`
+	ResiliencyDepletions       *int32             `parquet:"resiliency_depletions,optional"`
+	ResiliencyRecovered        *int32             `parquet:"resiliency_recovered,optional"`
+	ResiliencyMeanRecovery     *float64           `parquet:"resiliency_mean_recovery_seconds,optional"`
+	ResiliencyReplenishment    *float64           `parquet:"resiliency_replenishment,optional"`
+	ResiliencyBidDepth         *float64           `parquet:"resiliency_bid_depth,optional"`
+	ResiliencyAskDepth         *float64           `parquet:"resiliency_ask_depth,optional"`
+	ResiliencyWindowSeconds    *float64           `parquet:"resiliency_window_seconds,optional"`
+	ShareBid                   map[string]float64 `parquet:"share_bid"`
+	ShareAsk                   map[string]float64 `parquet:"share_ask"`
+	ShareTotal                 map[string]float64 `parquet:"share_total"`
+	ShareBestBid               *float64           `parquet:"share_best_bid,optional"`
+	ShareBestAsk               *float64           `parquet:"share_best_ask,optional"`
+	ShareSampledSeconds        *float64           `parquet:"share_sampled_seconds,optional"`
+	Volatility                 map[string]float64 `parquet:"volatility"`
+	VolatilityAnnualized       map[string]float64 `parquet:"volatility_annualized"`
+	VolatilityReturns          map[string]uint32  `parquet:"volatility_returns"`
+	OFI                        map[string]float64 `parquet:"ofi"`
+	OFIUpdates                 map[string]uint32  `parquet:"ofi_updates"`
+	EffectiveSpreadRawBps      *float64           `parquet:"effective_spread_raw_bps,optional"`
+	EffectiveSpreadTakerBps    *float64           `parquet:"effective_spread_taker_bps,optional"`
+	EffectiveSpreadMakerBps    *float64           `parquet:"effective_spread_maker_bps,optional"`
+	MakerFeeBps                *float64           `parquet:"maker_fee_bps,optional"`
+	TakerFeeBps                *float64           `parquet:"taker_fee_bps,optional"`
+	IndexPrice                 *float64           `parquet:"index_price,optional"`
+	IndexWeighting             *string            `parquet:"index_weighting,optional,dict"`
+	IndexVenues                *int32             `parquet:"index_venues,optional"`
+	IndexWeights               map[string]float64 `parquet:"index_weights"`
+	TouchBidMean               *float64           `parquet:"touch_bid_mean,optional"`
+	TouchBidMin                *float64           `parquet:"touch_bid_min,optional"`
+	TouchBidTimeWeighted       *float64           `parquet:"touch_bid_time_weighted,optional"`
+	TouchAskMean               *float64           `parquet:"touch_ask_mean,optional"`
+	TouchAskMin                *float64           `parquet:"touch_ask_min,optional"`
+	TouchAskTimeWeighted       *float64           `parquet:"touch_ask_time_weighted,optional"`
+	TouchUpdates               *int32             `parquet:"touch_updates,optional"`
+}
+
+// parquetLevel is one row of orderbook_levels
+type parquetLevel struct {
+	Exchange  string  `parquet:"exchange,dict"`
+	Symbol    string  `parquet:"symbol,dict"`
+	Timestamp int64   `parquet:"timestamp,timestamp(millisecond)"`
+	Side      string  `parquet:"side,dict"`
+	Level     int32   `parquet:"level"`
+	Price     float64 `parquet:"price"`
+	Quantity  float64 `parquet:"quantity"`
+}
+
+// parquetTrade is one row of trades
+type parquetTrade struct {
+	Exchange  string  `parquet:"exchange,dict"`
+	Symbol    string  `parquet:"symbol,dict"`
+	TradeID   string  `parquet:"trade_id"`
+	Timestamp int64   `parquet:"timestamp,timestamp(millisecond)"`
+	Price     float64 `parquet:"price"`
+	Size      float64 `parquet:"size"`
+	Side      string  `parquet:"side,dict"`
+	Taker     bool    `parquet:"taker"`
+}
+
+// NewParquetClient creates the archive directory; files are only created once rows arrive
+func NewParquetClient(config ParquetConfig) (*ParquetClient, error) {
+	if config.Dir == "" {
+		config.Dir = "parquet"
+	}
+	if config.RotateSize <= 0 {
+		config.RotateSize = 128 << 20
+	}
+	if config.RotateInterval <= 0 {
+		config.RotateInterval = time.Hour
+	}
+	if config.RowGroupRows <= 0 {
+		config.RowGroupRows = 5000
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create Parquet directory: %w", err)
+	}
+
+	c := &ParquetClient{config: config}
+	c.snapshots = newParquetTable[parquetSnapshot](c, "orderbook_snapshots")
+	c.levels = newParquetTable[parquetLevel](c, "orderbook_levels")
+	c.trades = newParquetTable[parquetTrade](c, "trades")
+	return c, nil
+}
+
+// InsertOrderbookSnapshot writes a single snapshot
+func (c *ParquetClient) InsertOrderbookSnapshot(snapshot *OrderbookSnapshotAPI) error {
+	return c.InsertOrderbookSnapshotsBatch([]*OrderbookSnapshotAPI{snapshot})
+}
+
+// InsertOrderbookSnapshotsBatch appends snapshots to the open file of their partition
+func (c *ParquetClient) InsertOrderbookSnapshotsBatch(snapshots []*OrderbookSnapshotAPI) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return fmt.Errorf("parquet client is closed")
+	}
+
+	for _, s := range snapshots {
+		if err := c.snapshots.write(s.Exchange, s.Symbol, s.Timestamp, parquetSnapshotRow(s)); err != nil {
+			return err
+		}
+	}
+	return c.rotateExpired()
+}
+
+// InsertDepthSnapshots appends one row per level of each book to orderbook_levels
+func (c *ParquetClient) InsertDepthSnapshots(depth []*DepthSnapshotAPI) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return fmt.Errorf("parquet client is closed")
+	}
+
+	for _, book := range depth {
+		timestamp := book.Timestamp.UnixMilli()
+		for side, levels := range map[string][]LevelAPI{"bid": book.Bids, "ask": book.Asks} {
+			for i, level := range levels {
+				row := parquetLevel{Exchange: book.Exchange, Symbol: book.Symbol, Timestamp: timestamp,
+					Side: side, Level: int32(i), Price: level.Price, Quantity: level.Quantity}
+				if err := c.levels.write(book.Exchange, book.Symbol, book.Timestamp, row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return c.rotateExpired()
+}
+
+// InsertTrades appends one row per trade to trades
+func (c *ParquetClient) InsertTrades(trades []*TradeAPI) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return fmt.Errorf("parquet client is closed")
+	}
+
+	for _, t := range trades {
+		row := parquetTrade{Exchange: t.Exchange, Symbol: t.Symbol, TradeID: t.TradeID, Timestamp: t.Timestamp.UnixMilli(),
+			Price: t.Price, Size: t.Size, Side: t.Side, Taker: t.Taker}
+		if err := c.trades.write(t.Exchange, t.Symbol, t.Timestamp, row); err != nil {
+			return err
+		}
+	}
+	return c.rotateExpired()
+}
+
+// rotateExpired finishes files open longer than the rotation interval, including those of a
+// previous day's partition (must be called with mutex locked)
+func (c *ParquetClient) rotateExpired() error {
+	cutoff := time.Now().Add(-c.config.RotateInterval)
+	if err := c.snapshots.rotateOpenedBefore(cutoff); err != nil {
+		return err
+	}
+	if err := c.levels.rotateOpenedBefore(cutoff); err != nil {
+		return err
+	}
+	return c.trades.rotateOpenedBefore(cutoff)
+}
+
+// finished hands a renamed file to the upload hook
+func (c *ParquetClient) finished(path string) {
+	if c.config.Upload == nil {
+		return
+	}
+	key, err := filepath.Rel(c.config.Dir, path)
+	if err != nil {
+		key = filepath.Base(path)
+	}
+	key = filepath.ToSlash(key)
+
+	c.uploads.Add(1)
+	go func() {
+		defer c.uploads.Done()
+		if err := c.config.Upload(path, key); err != nil {
+			logger("parquet").Error("Failed to upload archive", "event", "upload_failed", "key", key, "error", err)
+		}
+	}()
+}
+
+// TestConnection checks that the archive directory is writable
+func (c *ParquetClient) TestConnection() error {
+	probe, err := os.CreateTemp(c.config.Dir, ".probe-*")
+	if err != nil {
+		return fmt.Errorf("parquet directory is not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// Close finishes every open file and waits for their uploads
+func (c *ParquetClient) Close() error {
+	c.mu.Lock()
+	var err error
+	if !c.closed {
+		c.closed = true
+		err = c.snapshots.rotateOpenedBefore(time.Now().Add(time.Hour))
+		if levelsErr := c.levels.rotateOpenedBefore(time.Now().Add(time.Hour)); err == nil {
+			err = levelsErr
+		}
+		if tradesErr := c.trades.rotateOpenedBefore(time.Now().Add(time.Hour)); err == nil {
+			err = tradesErr
+		}
+	}
+	c.mu.Unlock()
+
+	c.uploads.Wait()
+	return err
+}
+
+// parquetTable holds the open file of each partition of one table
+type parquetTable[T any] struct {
+	client *ParquetClient
+	name   string
+	files  map[string]*parquetFile[T] // Keyed by partition directory
+}
+
+// parquetFile is a file being written; rows are buffered in memory until a row group fills
+type parquetFile[T any] struct {
+	path    string // Final name; rows go to path + ".tmp" until the file is finished
+	file    *os.File
+	counter *countingWriter
+	writer  *parquet.GenericWriter[T]
+	opened  time.Time
+	pending int64 // Rows buffered since the last row group
+}
+
+func newParquetTable[T any](client *ParquetClient, name string) *parquetTable[T] {
+	return &parquetTable[T]{client: client, name: name, files: make(map[string]*parquetFile[T])}
+}
+
+// write appends a row to its partition's file, rotating the file once it reaches the rotation size
+func (t *parquetTable[T]) write(exchange, symbol string, timestamp time.Time, row T) error {
+	partition := filepath.Join(t.client.config.Dir, t.name,
+		"date="+timestamp.UTC().Format("2006-01-02"),
+		"exchange="+partitionValue(exchange),
+		"symbol="+partitionValue(symbol))
+
+	file, ok := t.files[partition]
+	if !ok {
+		var err error
+		if file, err = t.open(partition); err != nil {
+			return err
+		}
+		t.files[partition] = file
+	}
+
+	if _, err := file.writer.Write([]T{row}); err != nil {
+		return fmt.Errorf("failed to write %s row: %w", t.name, err)
+	}
+	if file.pending++; file.pending < t.client.config.RowGroupRows {
+		return nil
+	}
+	file.pending = 0
+	if err := file.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s row group: %w", t.name, err)
+	}
+	if file.counter.written >= t.client.config.RotateSize {
+		delete(t.files, partition)
+		return t.finish(file)
+	}
+	return nil
+}
+
+// open creates a new part file in a partition directory
+func (t *parquetTable[T]) open(partition string) (*parquetFile[T], error) {
+	if err := os.MkdirAll(partition, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create partition: %w", err)
+	}
+
+	opened := time.Now()
+	path := filepath.Join(partition, fmt.Sprintf("part-%d.parquet", opened.UnixNano()))
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Parquet file: %w", err)
+	}
+
+	// The buffer sits below the counter so the rotation size sees every row group as soon as it is written
+	counter := &countingWriter{w: bufio.NewWriterSize(f, 64<<10)}
+	writer := parquet.NewGenericWriter[T](counter, parquet.Compression(&parquet.Zstd), parquet.WriteBufferSize(0))
+	return &parquetFile[T]{path: path, file: f, counter: counter, writer: writer, opened: opened}, nil
+}
+
+// rotateOpenedBefore finishes every file opened before cutoff
+func (t *parquetTable[T]) rotateOpenedBefore(cutoff time.Time) error {
+	var firstErr error
+	for partition, file := range t.files {
+		if !file.opened.Before(cutoff) {
+			continue
+		}
+		delete(t.files, partition)
+		if err := t.finish(file); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// finish writes the footer, moves the file to its final name and queues its upload
+func (t *parquetTable[T]) finish(file *parquetFile[T]) error {
+	err := file.writer.Close()
+	if err == nil {
+		err = file.counter.w.Flush()
+	}
+	if err != nil {
+		file.file.Close()
+		return fmt.Errorf("failed to finish Parquet file %s: %w", file.path, err)
+	}
+	if err := file.file.Close(); err != nil {
+		return fmt.Errorf("failed to close Parquet file %s: %w", file.path, err)
+	}
+	if err := os.Rename(file.path+".tmp", file.path); err != nil {
+		return fmt.Errorf("failed to rename Parquet file %s: %w", file.path, err)
+	}
+	t.client.finished(file.path)
+	return nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w       *bufio.Writer
+	written int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.written += int64(n)
+	return n, err
+}
+
+// partitionValue keeps a value usable as a single directory name
+func partitionValue(value string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "=", "_").Replace(value)
+}
+
+// parquetSnapshotRow flattens a snapshot into the orderbook_snapshots columns
+func parquetSnapshotRow(s *OrderbookSnapshotAPI) parquetSnapshot {
+	row := parquetSnapshot{
+		Exchange: s.Exchange, Symbol: s.Symbol, Timestamp: s.Timestamp.UnixMilli(),
+		BestBid: s.BestBid, BestAsk: s.BestAsk, MidPrice: s.MidPrice, Spread: s.Spread,
+		BidLiquidity05Pct: s.BidLiquidity05Pct, AskLiquidity05Pct: s.AskLiquidity05Pct,
+		BidLiquidity2Pct: s.BidLiquidity2Pct, AskLiquidity2Pct: s.AskLiquidity2Pct,
+		BidLiquidity10Pct: s.BidLiquidity10Pct, AskLiquidity10Pct: s.AskLiquidity10Pct,
+		TotalBidsQty: s.TotalBidsQty, TotalAsksQty: s.TotalAsksQty,
+		BidNotional05Pct: s.BidNotional05Pct, AskNotional05Pct: s.AskNotional05Pct,
+		BidNotional2Pct: s.BidNotional2Pct, AskNotional2Pct: s.AskNotional2Pct,
+		BidNotional10Pct: s.BidNotional10Pct, AskNotional10Pct: s.AskNotional10Pct,
+		TotalBidsNotional: s.TotalBidsNotional, TotalAsksNotional: s.TotalAsksNotional,
+		Microprice:  s.Microprice,
+		Imbalance:   make(map[string]float64, len(s.Imbalance)),
+		WeightedMid: make(map[string]float64, len(s.Imbalance)),
+	}
+	for levels, value := range s.Imbalance {
+		row.Imbalance[levels] = value.Imbalance
+		row.WeightedMid[levels] = value.WeightedMid
+	}
+	row.BandBid, row.BandAsk, row.BandBidNotional, row.BandAskNotional = splitBands(s.LiquidityBands)
+	row.CurveBid, row.CurveAsk, row.CurveBidNotional, row.CurveAskNotional = splitBands(s.DepthCurve)
+	row.ImpactBuyVWAP, row.ImpactBuySlippage, row.ImpactSellVWAP, row.ImpactSellSlippage = splitImpact(s.Impact)
+	row.SlippageBuyVWAP, row.SlippageBuy, row.SlippageSellVWAP, row.SlippageSell = splitImpact(s.Slippage)
+
+	if s.Rolling != nil {
+		samples := int32(s.Rolling.Samples)
+		row.RollingWindowSeconds, row.RollingSamples = &s.Rolling.WindowSeconds, &samples
+		row.RollingAvgSpread = &s.Rolling.AvgSpread
+		row.RollingSpreadVolatility = &s.Rolling.SpreadVolatility
+		row.RollingMidReturnVolatility = &s.Rolling.MidReturnVolatility
+	}
+	if f := s.Flow; f != nil {
+		trades, large := int32(f.Trades), int32(f.LargeTrades)
+		row.FlowWindowSeconds, row.FlowCVD = &f.WindowSeconds, &f.CVD
+		row.FlowBuyVolume, row.FlowSellVolume, row.FlowBuyRatio = &f.BuyVolume, &f.SellVolume, &f.BuyRatio
+		row.FlowTrades, row.FlowLargeTrades = &trades, &large
+	}
+	if f := s.Funding; f != nil {
+		row.FundingRate, row.FundingSecondsToFunding = &f.Rate, &f.SecondsToFunding
+		row.FundingMarkPrice, row.FundingIndexPrice = &f.MarkPrice, &f.IndexPrice
+		row.FundingOpenInterest, row.FundingOINotional, row.FundingOIChange = &f.OpenInterest, &f.OpenInterestNotional, &f.OpenInterestChange
+		row.FundingWindowSeconds = &f.WindowSeconds
+	}
+	if b := s.Basis; b != nil {
+		row.BasisSpotExchange, row.BasisSpotMid, row.BasisBps = &b.SpotExchange, &b.SpotMid, &b.BasisBps
+		row.BasisAnnualized, row.BasisAnnualizedFunding = &b.AnnualizedBasis, &b.AnnualizedFunding
+	}
+	if r := s.Resiliency; r != nil {
+		depletions, recovered := int32(r.Depletions), int32(r.Recovered)
+		row.ResiliencyScore, row.ResiliencyDepletions, row.ResiliencyRecovered = &r.Score, &depletions, &recovered
+		row.ResiliencyMeanRecovery, row.ResiliencyReplenishment = &r.MeanRecoverySeconds, &r.Replenishment
+		row.ResiliencyBidDepth, row.ResiliencyAskDepth, row.ResiliencyWindowSeconds = &r.BidDepth, &r.AskDepth, &r.WindowSeconds
+	}
+	if m := s.MarketShare; m != nil {
+		row.ShareBid, row.ShareAsk, row.ShareTotal = splitShares(m.Bands)
+		row.ShareBestBid, row.ShareBestAsk, row.ShareSampledSeconds = &m.BestBid, &m.BestAsk, &m.SampledSeconds
+	}
+	row.Volatility, row.VolatilityAnnualized, row.VolatilityReturns = splitVolatility(s.Volatility)
+	row.OFI, row.OFIUpdates = splitOFI(s.OFI)
+	if e := s.EffectiveSpread; e != nil {
+		row.EffectiveSpreadRawBps, row.EffectiveSpreadTakerBps, row.EffectiveSpreadMakerBps = &e.RawBps, &e.TakerBps, &e.MakerBps
+		row.MakerFeeBps, row.TakerFeeBps = &e.MakerFeeBps, &e.TakerFeeBps
+	}
+	if x := s.IndexPrice; x != nil {
+		venues := int32(x.Venues)
+		row.IndexPrice, row.IndexWeighting, row.IndexVenues, row.IndexWeights = &x.Price, &x.Weighting, &venues, x.Weights
+	}
+	if t := s.Touch; t != nil {
+		updates := int32(t.Updates)
+		row.TouchBidMean, row.TouchBidMin, row.TouchBidTimeWeighted = &t.BidMean, &t.BidMin, &t.BidTimeWeighted
+		row.TouchAskMean, row.TouchAskMin, row.TouchAskTimeWeighted = &t.AskMean, &t.AskMin, &t.AskTimeWeighted
+		row.TouchUpdates = &updates
+	}
+	return row
+}
+
+// CommandUpload returns an upload hook running a shell command for each finished file,
+// with {path} and {key} replaced by the quoted local path and archive key, e.g.
+// "aws s3 cp {path} s3://bucket/orderbook/{key}" or "gsutil cp {path} gs://bucket/{key}"
+func CommandUpload(command string) func(path, key string) error {
+	return func(path, key string) error {
+		script := strings.NewReplacer("{path}", shellQuote(path), "{key}", shellQuote(key)).Replace(command)
+		output, err := exec.Command("sh", "-c", script).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+}
+
+// shellQuote quotes a value as a single shell word
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
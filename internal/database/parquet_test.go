@@ -0,0 +1,167 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestParquetClient(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	var uploaded []string
+	client, err := NewParquetClient(ParquetConfig{Dir: dir, Upload: func(path, key string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("uploaded file %s is missing: %v", path, err)
+		}
+		uploaded = append(uploaded, key)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("NewParquetClient() error: %v", err)
+	}
+	if err := client.TestConnection(); err != nil {
+		t.Fatalf("TestConnection() error: %v", err)
+	}
+
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bid, ask, band := 99.5, 100.5, 3.0
+	snapshots := []*OrderbookSnapshotAPI{
+		{Exchange: "binancef", Symbol: "BTCUSDT", Timestamp: timestamp, BestBid: &bid, BestAsk: &ask,
+			Imbalance:      map[string]ImbalanceAPI{"5": {Imbalance: 0.6, WeightedMid: 100.1}},
+			LiquidityBands: map[string]BandLiquidityAPI{"25bp": {Bid: &band}},
+			Rolling:        &RollingAPI{WindowSeconds: 300, Samples: 12}},
+		{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: timestamp},
+	}
+	if err := client.InsertOrderbookSnapshotsBatch(snapshots); err != nil {
+		t.Fatalf("InsertOrderbookSnapshotsBatch() error: %v", err)
+	}
+	depth := []*DepthSnapshotAPI{{Exchange: "binancef", Symbol: "BTCUSDT", Timestamp: timestamp,
+		Bids: []LevelAPI{{Price: 99.5, Quantity: 1}, {Price: 99, Quantity: 2}}, Asks: []LevelAPI{{Price: 100.5, Quantity: 3}}}}
+	if err := client.InsertDepthSnapshots(depth); err != nil {
+		t.Fatalf("InsertDepthSnapshots() error: %v", err)
+	}
+
+	// Nothing is visible under a final name until the files are finished
+	if files, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*", "*", "*.parquet")); len(files) != 0 {
+		t.Errorf("expected no finished files before Close, got %v", files)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if len(uploaded) != 3 {
+		t.Fatalf("expected 3 uploads, got %v", uploaded)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "orderbook_snapshots", "date=2024-05-01", "exchange=binancef", "symbol=BTCUSDT", "*.parquet"))
+	if len(files) != 1 {
+		t.Fatalf("expected one binancef snapshot file, got %v", files)
+	}
+	rows, err := parquet.ReadFile[parquetSnapshot](files[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Timestamp != timestamp.UnixMilli() || rows[0].BestBid == nil || *rows[0].BestBid != bid {
+		t.Fatalf("unexpected snapshot rows %+v", rows)
+	}
+	if rows[0].Imbalance["5"] != 0.6 || rows[0].BandBid["25bp"] != band || rows[0].RollingSamples == nil || *rows[0].RollingSamples != 12 {
+		t.Errorf("unexpected snapshot row %+v", rows[0])
+	}
+	if rows[0].Spread != nil || len(rows[0].BandAsk) != 0 {
+		t.Errorf("expected missing values to stay null, got %+v", rows[0])
+	}
+
+	files, _ = filepath.Glob(filepath.Join(dir, "orderbook_levels", "date=2024-05-01", "exchange=binancef", "symbol=BTCUSDT", "*.parquet"))
+	if len(files) != 1 {
+		t.Fatalf("expected one levels file, got %v", files)
+	}
+	levels, err := parquet.ReadFile[parquetLevel](files[0])
+	if err != nil || len(levels) != 3 {
+		t.Fatalf("expected 3 levels, got %d (%v)", len(levels), err)
+	}
+}
+
+func TestParquetClientRotation(t *testing.T) {
+	dir := t.TempDir()
+	client, err := NewParquetClient(ParquetConfig{Dir: dir, RotateSize: 1, RowGroupRows: 1})
+	if err != nil {
+		t.Fatalf("NewParquetClient() error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		snapshot := &OrderbookSnapshotAPI{Exchange: "okx", Symbol: "BTC-USDT", Timestamp: time.Now()}
+		if err := client.InsertOrderbookSnapshot(snapshot); err != nil {
+			t.Fatalf("InsertOrderbookSnapshot() error: %v", err)
+		}
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if err := client.InsertOrderbookSnapshot(&OrderbookSnapshotAPI{}); err == nil {
+		t.Error("expected an error inserting after Close")
+	}
+
+	// Every row group fills the tiny rotation size, so each row gets its own file
+	files, _ := filepath.Glob(filepath.Join(dir, "orderbook_snapshots", "date=*", "exchange=okx", "symbol=BTC-USDT", "*"))
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %v", files)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, ".tmp") {
+			t.Errorf("unfinished file %s left after Close", file)
+		}
+	}
+}
+
+func TestCommandUpload(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "it's.parquet")
+	if err := os.WriteFile(source, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	upload := CommandUpload("mkdir -p " + dir + "/bucket/a && cp {path} " + dir + "/bucket/{key}")
+	if err := upload(source, "a/b.parquet"); err != nil {
+		t.Fatalf("upload error: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "bucket", "a", "b.parquet")); err != nil || string(data) != "data" {
+		t.Errorf("expected the file to be copied, got %q (%v)", data, err)
+	}
+	if err := CommandUpload("exit 3")(source, "a/b.parquet"); err == nil {
+		t.Error("expected a failing command to return an error")
+	}
+}
+
+func TestParquetClientPrune(t *testing.T) {
+	dir := t.TempDir()
+	client, err := NewParquetClient(ParquetConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewParquetClient() error: %v", err)
+	}
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{day.Add(time.Hour), day.Add(25 * time.Hour)} {
+		if err := client.InsertOrderbookSnapshot(&OrderbookSnapshotAPI{Exchange: "okx", Symbol: "BTCUSDT", Timestamp: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if removed, err := client.PruneBefore(day.Add(48 * time.Hour)); err != nil || removed != 0 {
+		t.Errorf("expected partitions with open files to be kept, got %d, %v", removed, err)
+	}
+	client.Close()
+
+	// Only the first day lies wholly before the cutoff
+	if removed, err := client.PruneBefore(day.Add(36 * time.Hour)); err != nil || removed != 1 {
+		t.Errorf("PruneBefore() = %d, %v; want 1 file", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "orderbook_snapshots", "date=2024-05-02")); err != nil {
+		t.Errorf("expected the second day to be kept: %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package database
+
+import "testing"
+
+func TestPostgresInsert(t *testing.T) {
+	got := postgresInsert("orderbook_depth", []string{"exchange", "bids"}, 2)
+	want := "INSERT INTO orderbook_depth (exchange, bids) VALUES ($1, $2), ($3, $4)"
+	if got != want {
+		t.Errorf("postgresInsert() = %q, want %q", got, want)
+	}
+}
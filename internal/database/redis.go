@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig configures the Redis latest-book cache
+type RedisConfig struct {
+	URL     string        // e.g. redis://localhost:6379/0
+	Prefix  string        // Key prefix, default orderbook
+	TTL     time.Duration // Expiry of each key so books that stop updating disappear, default 1m
+	Publish bool          // Also publish every value on a channel named like its key
+}
+
+// RedisClient keeps the latest stats and top levels of every book in Redis:
+//
+//	<prefix>:<exchange>:<symbol>:stats  JSON snapshot, as stored by the other backends
+//	<prefix>:<exchange>:<symbol>:depth  JSON top levels per side, best first
+//	<prefix>:books                      set of <exchange>:<symbol> with a cached book
+//
+// Values are overwritten rather than appended, so readers always see the current book
+type RedisClient struct {
+	client *redis.Client
+	config RedisConfig
+}
+
+// NewRedisClient creates a client for the server at config.URL; it connects lazily
+func NewRedisClient(config RedisConfig) (*RedisClient, error) {
+	if config.Prefix == "" {
+		config.Prefix = "orderbook"
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+
+	options, err := redis.ParseURL(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	return &RedisClient{client: redis.NewClient(options), config: config}, nil
+}
+
+// InsertOrderbookSnapshot caches a single snapshot
+func (c *RedisClient) InsertOrderbookSnapshot(snapshot *OrderbookSnapshotAPI) error {
+	return c.InsertOrderbookSnapshotsBatch([]*OrderbookSnapshotAPI{snapshot})
+}
+
+// InsertOrderbookSnapshotsBatch replaces the cached stats of each book in one round trip
+func (c *RedisClient) InsertOrderbookSnapshotsBatch(snapshots []*OrderbookSnapshotAPI) error {
+	values := make([]redisValue, len(snapshots))
+	for i, s := range snapshots {
+		values[i] = redisValue{book: s.Exchange + ":" + s.Symbol, suffix: "stats", value: s}
+	}
+	return c.set(values)
+}
+
+// InsertDepthSnapshots replaces the cached levels of each book in one round trip
+func (c *RedisClient) InsertDepthSnapshots(depth []*DepthSnapshotAPI) error {
+	values := make([]redisValue, len(depth))
+	for i, book := range depth {
+		values[i] = redisValue{book: book.Exchange + ":" + book.Symbol, suffix: "depth", value: book}
+	}
+	return c.set(values)
+}
+
+// redisValue is one value to cache under <prefix>:<book>:<suffix>
+type redisValue struct {
+	book   string
+	suffix string
+	value  any
+}
+
+// set writes values in a single pipeline
+func (c *RedisClient) set(values []redisValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	index := c.config.Prefix + ":books"
+	pipe := c.client.Pipeline()
+	for _, v := range values {
+		data, err := json.Marshal(v.value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", v.suffix, err)
+		}
+		key := c.config.Prefix + ":" + v.book + ":" + v.suffix
+		pipe.Set(ctx, key, data, c.config.TTL)
+		pipe.SAdd(ctx, index, v.book)
+		if c.config.Publish {
+			pipe.Publish(ctx, key, data)
+		}
+	}
+	pipe.Expire(ctx, index, c.config.TTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update Redis: %w", err)
+	}
+	return nil
+}
+
+// TestConnection pings the server
+func (c *RedisClient) TestConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.client.Ping(ctx).Err()
+}
+
+// Close closes the connection pool
+func (c *RedisClient) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisClient(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := NewRedisClient(RedisConfig{URL: "redis://" + server.Addr(), TTL: 30 * time.Second, Publish: true})
+	if err != nil {
+		t.Fatalf("NewRedisClient() error: %v", err)
+	}
+	defer client.Close()
+	if err := client.TestConnection(); err != nil {
+		t.Fatalf("TestConnection() error: %v", err)
+	}
+
+	subscription := client.client.Subscribe(context.Background(), "orderbook:bybit:BTCUSDT:stats")
+	defer subscription.Close()
+	if _, err := subscription.Receive(context.Background()); err != nil {
+		t.Fatalf("Subscribe() error: %v", err)
+	}
+
+	bid := 99.5
+	for _, best := range []float64{bid, bid + 1} {
+		snapshot := &OrderbookSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT", BestBid: &best}
+		if err := client.InsertOrderbookSnapshot(snapshot); err != nil {
+			t.Fatalf("InsertOrderbookSnapshot() error: %v", err)
+		}
+	}
+	depth := []*DepthSnapshotAPI{{Exchange: "bybit", Symbol: "BTCUSDT", Bids: []LevelAPI{{Price: 100.5, Quantity: 2}}}}
+	if err := client.InsertDepthSnapshots(depth); err != nil {
+		t.Fatalf("InsertDepthSnapshots() error: %v", err)
+	}
+
+	// The latest snapshot replaces the previous one
+	var stats OrderbookSnapshotAPI
+	value, err := server.Get("orderbook:bybit:BTCUSDT:stats")
+	if err != nil || json.Unmarshal([]byte(value), &stats) != nil || stats.BestBid == nil || *stats.BestBid != bid+1 {
+		t.Fatalf("unexpected cached stats %q (%v)", value, err)
+	}
+	if ttl := server.TTL("orderbook:bybit:BTCUSDT:stats"); ttl != 30*time.Second {
+		t.Errorf("expected a 30s TTL, got %v", ttl)
+	}
+
+	var book DepthSnapshotAPI
+	value, err = server.Get("orderbook:bybit:BTCUSDT:depth")
+	if err != nil || json.Unmarshal([]byte(value), &book) != nil || len(book.Bids) != 1 || book.Bids[0].Price != 100.5 {
+		t.Errorf("unexpected cached depth %q (%v)", value, err)
+	}
+	if members, err := server.Members("orderbook:books"); err != nil || len(members) != 1 || members[0] != "bybit:BTCUSDT" {
+		t.Errorf("unexpected book index %v (%v)", members, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	message, err := subscription.ReceiveMessage(ctx)
+	if err != nil || json.Unmarshal([]byte(message.Payload), &stats) != nil || *stats.BestBid != bid {
+		t.Errorf("expected the first snapshot on the channel, got %v (%v)", message, err)
+	}
+}
@@ -0,0 +1,444 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention support: SQLite, PostgreSQL and Supabase can read their snapshots back so old
+// rows can be downsampled into rollup tables with the orderbook_snapshots columns; ClickHouse
+// and Parquet can only delete old rows
+
+// tableName restricts retention tables to names safe to splice into queries
+var tableName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// checkTable rejects table names that are not plain identifiers
+func checkTable(table string) error {
+	if !tableName.MatchString(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+	return nil
+}
+
+// snapshotTableSchema rewrites the orderbook_snapshots statements of a schema for another table
+func snapshotTableSchema(schema []string, table string) []string {
+	var statements []string
+	for _, statement := range schema {
+		if strings.Contains(statement, " ON orderbook_snapshots ") || strings.Contains(statement, "TABLE IF NOT EXISTS orderbook_snapshots ") {
+			statements = append(statements, strings.ReplaceAll(statement, "orderbook_snapshots", table))
+		}
+	}
+	return statements
+}
+
+// scanSnapshots reads rows selected with sqliteSnapshotColumns
+func scanSnapshots(rows *sql.Rows) ([]*OrderbookSnapshotAPI, error) {
+	defer rows.Close()
+
+	var snapshots []*OrderbookSnapshotAPI
+	for rows.Next() {
+		s := &OrderbookSnapshotAPI{}
+		var timestamp any
+		var bands, imbalance, rolling, curve, impact, flow, funding, basis, slippage, resiliency, share, volatility, ofi, effective, index, touch sql.NullString
+		if err := rows.Scan(
+			&s.Exchange, &s.Symbol, &timestamp,
+			&s.BestBid, &s.BestAsk, &s.MidPrice, &s.Spread,
+			&s.BidLiquidity05Pct, &s.AskLiquidity05Pct, &s.BidLiquidity2Pct, &s.AskLiquidity2Pct,
+			&s.BidLiquidity10Pct, &s.AskLiquidity10Pct, &s.TotalBidsQty, &s.TotalAsksQty,
+			&s.BidNotional05Pct, &s.AskNotional05Pct, &s.BidNotional2Pct, &s.AskNotional2Pct,
+			&s.BidNotional10Pct, &s.AskNotional10Pct, &s.TotalBidsNotional, &s.TotalAsksNotional,
+			&bands, &s.Microprice, &imbalance, &rolling, &curve, &impact, &flow, &funding, &basis, &slippage, &resiliency, &share, &volatility, &ofi, &effective, &index, &touch,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+
+		switch t := timestamp.(type) {
+		case time.Time:
+			s.Timestamp = t
+		case string:
+			parsed, err := time.Parse(time.RFC3339Nano, t)
+			if err != nil {
+				return nil, fmt.Errorf("invalid snapshot timestamp %q: %w", t, err)
+			}
+			s.Timestamp = parsed
+		default:
+			return nil, fmt.Errorf("unexpected snapshot timestamp %T", timestamp)
+		}
+
+		for _, column := range []struct {
+			text  sql.NullString
+			value any
+		}{{bands, &s.LiquidityBands}, {imbalance, &s.Imbalance}, {rolling, &s.Rolling}, {curve, &s.DepthCurve}, {impact, &s.Impact}, {flow, &s.Flow}, {funding, &s.Funding}, {basis, &s.Basis}, {slippage, &s.Slippage}, {resiliency, &s.Resiliency}, {share, &s.MarketShare}, {volatility, &s.Volatility}, {ofi, &s.OFI}, {effective, &s.EffectiveSpread}, {index, &s.IndexPrice}, {touch, &s.Touch}} {
+			if column.text.Valid {
+				if err := json.Unmarshal([]byte(column.text.String), column.value); err != nil {
+					return nil, fmt.Errorf("invalid snapshot column: %w", err)
+				}
+			}
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// sqliteBound formats a whole-second time for comparison with the RFC3339Nano text SQLite
+// stores: "…15:04:05." sorts after every earlier second and before every fraction of this
+// one, while the stored text trims trailing zeros and so does not sort by time on its own
+func sqliteBound(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05") + "."
+}
+
+// CreateSnapshotTable creates a rollup table with the orderbook_snapshots columns
+func (c *SQLiteClient) CreateSnapshotTable(table string) error {
+	if err := checkTable(table); err != nil {
+		return err
+	}
+	for _, statement := range snapshotTableSchema(sqliteSchema, table) {
+		if _, err := c.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// OldestSnapshot returns the earliest timestamp of a snapshot table, false when it is empty
+func (c *SQLiteClient) OldestSnapshot(table string) (time.Time, bool, error) {
+	if err := checkTable(table); err != nil {
+		return time.Time{}, false, err
+	}
+	var oldest sql.NullString
+	if err := c.db.QueryRow("SELECT MIN(timestamp) FROM " + table).Scan(&oldest); err != nil || !oldest.Valid {
+		return time.Time{}, false, err
+	}
+	// MIN compares text, so it may pick a later fraction of the oldest second
+	t, err := time.Parse(time.RFC3339Nano, oldest.String)
+	return t.Truncate(time.Second), err == nil, err
+}
+
+// SnapshotsBetween returns the snapshots of a table with from <= timestamp < to, both whole seconds
+func (c *SQLiteClient) SnapshotsBetween(table string, from, to time.Time) ([]*OrderbookSnapshotAPI, error) {
+	if err := checkTable(table); err != nil {
+		return nil, err
+	}
+	rows, err := c.db.Query(fmt.Sprintf("SELECT %s FROM %s WHERE timestamp >= ? AND timestamp < ? ORDER BY timestamp",
+		strings.Join(sqliteSnapshotColumns, ", "), table), sqliteBound(from), sqliteBound(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	return scanSnapshots(rows)
+}
+
+// InsertSnapshotsInto inserts snapshots into a rollup table
+func (c *SQLiteClient) InsertSnapshotsInto(table string, snapshots []*OrderbookSnapshotAPI) error {
+	if err := checkTable(table); err != nil {
+		return err
+	}
+	return c.insertSnapshots(table, snapshots)
+}
+
+// DeleteSnapshotsBefore deletes the snapshots of a table older than a whole-second cutoff
+func (c *SQLiteClient) DeleteSnapshotsBefore(table string, cutoff time.Time) (int64, error) {
+	if err := checkTable(table); err != nil {
+		return 0, err
+	}
+	return c.deleteBefore(table, cutoff)
+}
+
+// DeleteDepthBefore deletes stored levels older than a whole-second cutoff
+func (c *SQLiteClient) DeleteDepthBefore(cutoff time.Time) (int64, error) {
+	return c.deleteBefore("orderbook_levels", cutoff)
+}
+
+func (c *SQLiteClient) deleteBefore(table string, cutoff time.Time) (int64, error) {
+	result, err := c.db.Exec("DELETE FROM "+table+" WHERE timestamp < ?", sqliteBound(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete from %s: %w", table, err)
+	}
+	return result.RowsAffected()
+}
+
+// CreateSnapshotTable creates a rollup table with the orderbook_snapshots columns
+func (c *PostgresClient) CreateSnapshotTable(table string) error {
+	if err := checkTable(table); err != nil {
+		return err
+	}
+	for _, statement := range snapshotTableSchema(postgresSchema, table) {
+		if _, err := c.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// OldestSnapshot returns the earliest timestamp of a snapshot table, false when it is empty
+func (c *PostgresClient) OldestSnapshot(table string) (time.Time, bool, error) {
+	if err := checkTable(table); err != nil {
+		return time.Time{}, false, err
+	}
+	var oldest sql.NullTime
+	if err := c.db.QueryRow("SELECT MIN(timestamp) FROM " + table).Scan(&oldest); err != nil {
+		return time.Time{}, false, err
+	}
+	return oldest.Time, oldest.Valid, nil
+}
+
+// SnapshotsBetween returns the snapshots of a table with from <= timestamp < to
+func (c *PostgresClient) SnapshotsBetween(table string, from, to time.Time) ([]*OrderbookSnapshotAPI, error) {
+	if err := checkTable(table); err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(sqliteSnapshotColumns))
+	for i, column := range sqliteSnapshotColumns {
+		columns[i] = column
+		if postgresJSONColumns[column] {
+			columns[i] = column + "::text" // Scanned as JSON text like the SQLite columns
+		}
+	}
+	rows, err := c.db.Query(fmt.Sprintf("SELECT %s FROM %s WHERE timestamp >= $1 AND timestamp < $2 ORDER BY timestamp",
+		strings.Join(columns, ", "), table), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	return scanSnapshots(rows)
+}
+
+// postgresJSONColumns are the JSONB columns of orderbook_snapshots
+var postgresJSONColumns = map[string]bool{
+	"liquidity_bands": true, "imbalance": true, "rolling": true, "depth_curve": true, "impact": true, "flow": true, "funding": true, "basis": true, "slippage": true, "resiliency": true,
+	"market_share": true, "volatility": true, "ofi": true, "effective_spread": true, "index_price": true, "touch": true,
+}
+
+// InsertSnapshotsInto inserts snapshots into a rollup table
+func (c *PostgresClient) InsertSnapshotsInto(table string, snapshots []*OrderbookSnapshotAPI) error {
+	if err := checkTable(table); err != nil {
+		return err
+	}
+	return c.insertSnapshots(table, snapshots)
+}
+
+// DeleteSnapshotsBefore deletes the snapshots of a table older than cutoff
+func (c *PostgresClient) DeleteSnapshotsBefore(table string, cutoff time.Time) (int64, error) {
+	if err := checkTable(table); err != nil {
+		return 0, err
+	}
+	return c.deleteBefore(table, cutoff)
+}
+
+// DeleteDepthBefore deletes stored levels older than cutoff
+func (c *PostgresClient) DeleteDepthBefore(cutoff time.Time) (int64, error) {
+	return c.deleteBefore("orderbook_depth", cutoff)
+}
+
+func (c *PostgresClient) deleteBefore(table string, cutoff time.Time) (int64, error) {
+	result, err := c.db.Exec("DELETE FROM "+table+" WHERE timestamp < $1", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete from %s: %w", table, err)
+	}
+	return result.RowsAffected()
+}
+
+// supabasePageSize is the number of rows read per request, the default PostgREST limit
+const supabasePageSize = 1000
+
+// CreateSnapshotTable checks that a rollup table exists; the REST API cannot create
+// tables, so it has to be created with the orderbook_snapshots columns beforehand
+func (c *SupabaseAPIClient) CreateSnapshotTable(table string) error {
+	if err := checkTable(table); err != nil {
+		return err
+	}
+	if _, err := c.restRequest("GET", table, url.Values{"select": {"timestamp"}, "limit": {"1"}}, nil); err != nil {
+		return fmt.Errorf("table %s must exist with the orderbook_snapshots columns: %w", table, err)
+	}
+	return nil
+}
+
+// OldestSnapshot returns the earliest timestamp of a snapshot table, false when it is empty
+func (c *SupabaseAPIClient) OldestSnapshot(table string) (time.Time, bool, error) {
+	if err := checkTable(table); err != nil {
+		return time.Time{}, false, err
+	}
+	body, err := c.restRequest("GET", table, url.Values{"select": {"timestamp"}, "order": {"timestamp.asc"}, "limit": {"1"}}, nil)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	var rows []struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to decode %s: %w", table, err)
+	}
+	if len(rows) == 0 {
+		return time.Time{}, false, nil
+	}
+	return rows[0].Timestamp, true, nil
+}
+
+// SnapshotsBetween returns the snapshots of a table with from <= timestamp < to, a page at a time
+func (c *SupabaseAPIClient) SnapshotsBetween(table string, from, to time.Time) ([]*OrderbookSnapshotAPI, error) {
+	if err := checkTable(table); err != nil {
+		return nil, err
+	}
+	var snapshots []*OrderbookSnapshotAPI
+	for offset := 0; ; offset += supabasePageSize {
+		query := url.Values{
+			"timestamp": {"gte." + from.UTC().Format(time.RFC3339Nano), "lt." + to.UTC().Format(time.RFC3339Nano)},
+			"order":     {"timestamp.asc,id.asc"},
+			"limit":     {strconv.Itoa(supabasePageSize)},
+			"offset":    {strconv.Itoa(offset)},
+		}
+		body, err := c.restRequest("GET", table, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []*OrderbookSnapshotAPI
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", table, err)
+		}
+		snapshots = append(snapshots, page...)
+		if len(page) < supabasePageSize {
+			return snapshots, nil
+		}
+	}
+}
+
+// InsertSnapshotsInto inserts snapshots into a rollup table
+func (c *SupabaseAPIClient) InsertSnapshotsInto(table string, snapshots []*OrderbookSnapshotAPI) error {
+	if err := checkTable(table); err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshots: %w", err)
+	}
+	_, err = c.restRequest("POST", table, nil, data)
+	return err
+}
+
+// DeleteSnapshotsBefore deletes the snapshots of a table older than cutoff
+func (c *SupabaseAPIClient) DeleteSnapshotsBefore(table string, cutoff time.Time) (int64, error) {
+	if err := checkTable(table); err != nil {
+		return 0, err
+	}
+	return c.deleteBefore(table, cutoff)
+}
+
+// DeleteDepthBefore deletes stored levels older than cutoff
+func (c *SupabaseAPIClient) DeleteDepthBefore(cutoff time.Time) (int64, error) {
+	return c.deleteBefore("orderbook_depth", cutoff)
+}
+
+// deleteBefore deletes old rows, returning how many were deleted
+func (c *SupabaseAPIClient) deleteBefore(table string, cutoff time.Time) (int64, error) {
+	body, err := c.restRequest("DELETE", table, url.Values{"timestamp": {"lt." + cutoff.UTC().Format(time.RFC3339Nano)}, "select": {"timestamp"}}, nil)
+	if err != nil {
+		return 0, err
+	}
+	var rows []json.RawMessage
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return 0, fmt.Errorf("failed to decode deleted rows: %w", err)
+	}
+	return int64(len(rows)), nil
+}
+
+// restRequest sends one request to a table of the REST API and returns the response body
+func (c *SupabaseAPIClient) restRequest(method, table string, query url.Values, body []byte) ([]byte, error) {
+	target := fmt.Sprintf("%s/rest/v1/%s", c.baseURL, table)
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, target, strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apikey", c.apiKey)
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if method == "POST" && query.Has("on_conflict") {
+		req.Header.Set("Prefer", "resolution=merge-duplicates,return=minimal")
+	} else if method == "POST" {
+		req.Header.Set("Prefer", "return=minimal")
+	} else if method == "DELETE" {
+		req.Header.Set("Prefer", "return=representation")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(data))
+	}
+	return data, nil
+}
+
+// PruneBefore deletes snapshots and levels older than cutoff. Deletes are asynchronous
+// mutations in ClickHouse, so the count is not known and 0 is returned
+func (c *ClickHouseClient) PruneBefore(cutoff time.Time) (int64, error) {
+	for _, table := range []string{"orderbook_snapshots", "orderbook_levels"} {
+		if err := c.conn.Exec(context.Background(), "ALTER TABLE "+table+" DELETE WHERE timestamp < ?", cutoff); err != nil {
+			return 0, fmt.Errorf("failed to delete from %s: %w", table, err)
+		}
+	}
+	return 0, nil
+}
+
+// PruneBefore removes the date partitions of every table whose whole day is older than
+// cutoff, returning how many files were removed; partitions with an open file are kept
+func (c *ParquetClient) PruneBefore(cutoff time.Time) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	open := func(partition string) bool {
+		for file := range c.snapshots.files {
+			if strings.HasPrefix(file, partition+string(filepath.Separator)) {
+				return true
+			}
+		}
+		for file := range c.levels.files {
+			if strings.HasPrefix(file, partition+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var removed int64
+	for _, table := range []string{"orderbook_snapshots", "orderbook_levels"} {
+		partitions, err := filepath.Glob(filepath.Join(c.config.Dir, table, "date=*"))
+		if err != nil {
+			return removed, err
+		}
+		for _, partition := range partitions {
+			day, err := time.Parse("2006-01-02", strings.TrimPrefix(filepath.Base(partition), "date="))
+			if err != nil || day.Add(24*time.Hour).After(cutoff) || open(partition) {
+				continue
+			}
+			files, _ := filepath.Glob(filepath.Join(partition, "*", "*", "*.parquet"))
+			if err := os.RemoveAll(partition); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", partition, err)
+			}
+			removed += int64(len(files))
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,307 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go driver, registered as "sqlite"
+)
+
+// SQLiteClient stores snapshots in a local SQLite file, creating the schema on open
+// The database runs in WAL mode so other processes can read it while collecting
+type SQLiteClient struct {
+	db *sql.DB
+}
+
+// sqliteSchema mirrors the Supabase table; map-valued fields are stored as JSON text
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS orderbook_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		exchange TEXT NOT NULL,
+		symbol TEXT NOT NULL,
+		timestamp TEXT NOT NULL,
+		best_bid REAL,
+		best_ask REAL,
+		mid_price REAL,
+		spread REAL,
+		bid_liquidity_05_pct REAL,
+		ask_liquidity_05_pct REAL,
+		bid_liquidity_2_pct REAL,
+		ask_liquidity_2_pct REAL,
+		bid_liquidity_10_pct REAL,
+		ask_liquidity_10_pct REAL,
+		total_bids_qty REAL,
+		total_asks_qty REAL,
+		bid_notional_05_pct REAL,
+		ask_notional_05_pct REAL,
+		bid_notional_2_pct REAL,
+		ask_notional_2_pct REAL,
+		bid_notional_10_pct REAL,
+		ask_notional_10_pct REAL,
+		total_bids_notional REAL,
+		total_asks_notional REAL,
+		liquidity_bands TEXT,
+		microprice REAL,
+		imbalance TEXT,
+		rolling TEXT,
+		depth_curve TEXT,
+		impact TEXT,
+		flow TEXT,
+		funding TEXT,
+		basis TEXT,
+		slippage TEXT,
+		resiliency TEXT,
+		market_share TEXT,
+		volatility TEXT,
+		ofi TEXT,
+		effective_spread TEXT,
+		index_price TEXT,
+		touch TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS orderbook_snapshots_time ON orderbook_snapshots (exchange, symbol, timestamp)`,
+	`CREATE TABLE IF NOT EXISTS orderbook_levels (
+		exchange TEXT NOT NULL,
+		symbol TEXT NOT NULL,
+		timestamp TEXT NOT NULL,
+		side TEXT NOT NULL,
+		level INTEGER NOT NULL,
+		price REAL NOT NULL,
+		quantity REAL NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS orderbook_levels_time ON orderbook_levels (exchange, symbol, timestamp)`,
+	`CREATE TABLE IF NOT EXISTS trades (
+		exchange TEXT NOT NULL,
+		symbol TEXT NOT NULL,
+		trade_id TEXT NOT NULL,
+		timestamp TEXT NOT NULL,
+		price REAL NOT NULL,
+		size REAL NOT NULL,
+		side TEXT NOT NULL,
+		taker INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS trades_time ON trades (exchange, symbol, timestamp)`,
+}
+
+// sqliteSnapshotColumns are written in the order of sqliteSnapshotValues
+var sqliteSnapshotColumns = []string{
+	"exchange", "symbol", "timestamp", "best_bid", "best_ask", "mid_price", "spread",
+	"bid_liquidity_05_pct", "ask_liquidity_05_pct", "bid_liquidity_2_pct", "ask_liquidity_2_pct",
+	"bid_liquidity_10_pct", "ask_liquidity_10_pct", "total_bids_qty", "total_asks_qty",
+	"bid_notional_05_pct", "ask_notional_05_pct", "bid_notional_2_pct", "ask_notional_2_pct",
+	"bid_notional_10_pct", "ask_notional_10_pct", "total_bids_notional", "total_asks_notional",
+	"liquidity_bands", "microprice", "imbalance", "rolling", "depth_curve", "impact", "flow", "funding", "basis", "slippage", "resiliency",
+	"market_share", "volatility", "ofi", "effective_spread", "index_price", "touch",
+}
+
+// NewSQLiteClient opens or creates the database file at path
+func NewSQLiteClient(path string) (*SQLiteClient, error) {
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	// SQLite allows one writer at a time; a single connection avoids busy errors between our own writes
+	db.SetMaxOpenConns(1)
+
+	for _, statement := range append(sqliteSchema, latestTableSchema(sqliteSchema[0])) {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create SQLite schema: %w", err)
+		}
+	}
+	return &SQLiteClient{db: db}, nil
+}
+
+// InsertOrderbookSnapshot inserts a single snapshot
+func (c *SQLiteClient) InsertOrderbookSnapshot(snapshot *OrderbookSnapshotAPI) error {
+	return c.InsertOrderbookSnapshotsBatch([]*OrderbookSnapshotAPI{snapshot})
+}
+
+// InsertOrderbookSnapshotsBatch inserts snapshots in one transaction
+func (c *SQLiteClient) InsertOrderbookSnapshotsBatch(snapshots []*OrderbookSnapshotAPI) error {
+	return c.insertSnapshots("orderbook_snapshots", snapshots)
+}
+
+// insertSnapshots inserts snapshots into a table with the orderbook_snapshots columns
+func (c *SQLiteClient) insertSnapshots(table string, snapshots []*OrderbookSnapshotAPI) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(sqliteSnapshotColumns, ", "), placeholders(len(sqliteSnapshotColumns)))
+	return c.insert(query, len(snapshots), func(stmt *sql.Stmt, i int) error {
+		values, err := sqliteSnapshotValues(snapshots[i])
+		if err != nil {
+			return err
+		}
+		_, err = stmt.Exec(values...)
+		return err
+	})
+}
+
+// InsertDepthSnapshots inserts one row per level of each book
+func (c *SQLiteClient) InsertDepthSnapshots(depth []*DepthSnapshotAPI) error {
+	if len(depth) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO orderbook_levels (exchange, symbol, timestamp, side, level, price, quantity) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	return c.insert(query, len(depth), func(stmt *sql.Stmt, i int) error {
+		book := depth[i]
+		timestamp := book.Timestamp.UTC().Format(time.RFC3339Nano)
+		for side, levels := range map[string][]LevelAPI{"bid": book.Bids, "ask": book.Asks} {
+			for level, value := range levels {
+				if _, err := stmt.Exec(book.Exchange, book.Symbol, timestamp, side, level, value.Price, value.Quantity); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// InsertTrades inserts one row per trade
+func (c *SQLiteClient) InsertTrades(trades []*TradeAPI) error {
+	if len(trades) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO trades (exchange, symbol, trade_id, timestamp, price, size, side, taker) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	return c.insert(query, len(trades), func(stmt *sql.Stmt, i int) error {
+		t := trades[i]
+		_, err := stmt.Exec(t.Exchange, t.Symbol, t.TradeID, t.Timestamp.UTC().Format(time.RFC3339Nano), t.Price, t.Size, t.Side, t.Taker)
+		return err
+	})
+}
+
+// insert runs one prepared statement per item inside a transaction
+func (c *SQLiteClient) insert(query string, items int, exec func(stmt *sql.Stmt, i int) error) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < items; i++ {
+		if err := exec(stmt, i); err != nil {
+			return fmt.Errorf("failed to insert row: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// sqliteSnapshotValues flattens a snapshot into the values of sqliteSnapshotColumns
+func sqliteSnapshotValues(s *OrderbookSnapshotAPI) ([]any, error) {
+	bands, err := jsonText(s.LiquidityBands, len(s.LiquidityBands) > 0)
+	if err != nil {
+		return nil, err
+	}
+	imbalance, err := jsonText(s.Imbalance, len(s.Imbalance) > 0)
+	if err != nil {
+		return nil, err
+	}
+	rolling, err := jsonText(s.Rolling, s.Rolling != nil)
+	if err != nil {
+		return nil, err
+	}
+	curve, err := jsonText(s.DepthCurve, len(s.DepthCurve) > 0)
+	if err != nil {
+		return nil, err
+	}
+	impact, err := jsonText(s.Impact, len(s.Impact) > 0)
+	if err != nil {
+		return nil, err
+	}
+	flow, err := jsonText(s.Flow, s.Flow != nil)
+	if err != nil {
+		return nil, err
+	}
+	funding, err := jsonText(s.Funding, s.Funding != nil)
+	if err != nil {
+		return nil, err
+	}
+	basis, err := jsonText(s.Basis, s.Basis != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	slippage, err := jsonText(s.Slippage, len(s.Slippage) > 0)
+	if err != nil {
+		return nil, err
+	}
+	resiliency, err := jsonText(s.Resiliency, s.Resiliency != nil)
+	if err != nil {
+		return nil, err
+	}
+	share, err := jsonText(s.MarketShare, s.MarketShare != nil)
+	if err != nil {
+		return nil, err
+	}
+	volatility, err := jsonText(s.Volatility, len(s.Volatility) > 0)
+	if err != nil {
+		return nil, err
+	}
+	ofi, err := jsonText(s.OFI, len(s.OFI) > 0)
+	if err != nil {
+		return nil, err
+	}
+	effective, err := jsonText(s.EffectiveSpread, s.EffectiveSpread != nil)
+	if err != nil {
+		return nil, err
+	}
+	index, err := jsonText(s.IndexPrice, s.IndexPrice != nil)
+	if err != nil {
+		return nil, err
+	}
+	touch, err := jsonText(s.Touch, s.Touch != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		s.Exchange, s.Symbol, s.Timestamp.UTC().Format(time.RFC3339Nano),
+		s.BestBid, s.BestAsk, s.MidPrice, s.Spread,
+		s.BidLiquidity05Pct, s.AskLiquidity05Pct, s.BidLiquidity2Pct, s.AskLiquidity2Pct,
+		s.BidLiquidity10Pct, s.AskLiquidity10Pct, s.TotalBidsQty, s.TotalAsksQty,
+		s.BidNotional05Pct, s.AskNotional05Pct, s.BidNotional2Pct, s.AskNotional2Pct,
+		s.BidNotional10Pct, s.AskNotional10Pct, s.TotalBidsNotional, s.TotalAsksNotional,
+		bands, s.Microprice, imbalance, rolling, curve, impact, flow, funding, basis, slippage, resiliency, share, volatility, ofi, effective, index, touch,
+	}, nil
+}
+
+// jsonText encodes value for a TEXT column, or NULL when it is not present
+func jsonText(value any, present bool) (any, error) {
+	if !present {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal column: %w", err)
+	}
+	return string(data), nil
+}
+
+// placeholders returns n comma-separated bind parameters
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// TestConnection checks that the database file is usable
+func (c *SQLiteClient) TestConnection() error {
+	return c.db.Ping()
+}
+
+// Close closes the database
+func (c *SQLiteClient) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.db")
+	client, err := NewSQLiteClient(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() error: %v", err)
+	}
+	defer client.Close()
+
+	bid, ask := 99.5, 100.5
+	snapshots := []*OrderbookSnapshotAPI{
+		{Exchange: "binancef", Symbol: "BTCUSDT", Timestamp: time.Now(), BestBid: &bid, BestAsk: &ask,
+			Imbalance: map[string]ImbalanceAPI{"5": {Imbalance: 0.6, WeightedMid: 100.1}}},
+		{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: time.Now()},
+	}
+	if err := client.InsertOrderbookSnapshotsBatch(snapshots); err != nil {
+		t.Fatalf("InsertOrderbookSnapshotsBatch() error: %v", err)
+	}
+
+	depth := []*DepthSnapshotAPI{{Exchange: "binancef", Symbol: "BTCUSDT", Timestamp: time.Now(),
+		Bids: []LevelAPI{{Price: 99.5, Quantity: 1}, {Price: 99, Quantity: 2}}, Asks: []LevelAPI{{Price: 100.5, Quantity: 3}}}}
+	if err := client.InsertDepthSnapshots(depth); err != nil {
+		t.Fatalf("InsertDepthSnapshots() error: %v", err)
+	}
+
+	trades := []*TradeAPI{{Exchange: "bybit", Symbol: "BTCUSDT", TradeID: "1", Timestamp: time.Now(), Price: 100, Size: 0.5, Side: "buy", Taker: true}}
+	if err := client.InsertTrades(trades); err != nil {
+		t.Fatalf("InsertTrades() error: %v", err)
+	}
+
+	// The second upsert of binancef replaces its row
+	newer := 99.75
+	if err := client.UpsertLatestSnapshots(snapshots); err != nil {
+		t.Fatalf("UpsertLatestSnapshots() error: %v", err)
+	}
+	if err := client.UpsertLatestSnapshots([]*OrderbookSnapshotAPI{{Exchange: "binancef", Symbol: "BTCUSDT", Timestamp: time.Now(), BestBid: &newer}}); err != nil {
+		t.Fatalf("UpsertLatestSnapshots() update error: %v", err)
+	}
+
+	// A second connection reads while the first stays open, as it would while collecting
+	reader, err := NewSQLiteClient(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() reopen error: %v", err)
+	}
+	defer reader.Close()
+
+	var count int
+	var imbalance string
+	var missing *float64
+	if err := reader.db.QueryRow("SELECT COUNT(*) FROM orderbook_snapshots").Scan(&count); err != nil || count != 2 {
+		t.Fatalf("expected 2 snapshots, got %d (%v)", count, err)
+	}
+	row := reader.db.QueryRow("SELECT imbalance FROM orderbook_snapshots WHERE exchange = 'binancef'")
+	if err := row.Scan(&imbalance); err != nil || imbalance != `{"5":{"imbalance":0.6,"weighted_mid":100.1}}` {
+		t.Errorf("unexpected imbalance %q (%v)", imbalance, err)
+	}
+	if err := reader.db.QueryRow("SELECT best_bid FROM orderbook_snapshots WHERE exchange = 'bybit'").Scan(&missing); err != nil || missing != nil {
+		t.Errorf("expected a NULL best bid, got %v (%v)", missing, err)
+	}
+	if err := reader.db.QueryRow("SELECT COUNT(*) FROM orderbook_levels WHERE side = 'bid'").Scan(&count); err != nil || count != 2 {
+		t.Errorf("expected 2 bid levels, got %d (%v)", count, err)
+	}
+	var side string
+	if err := reader.db.QueryRow("SELECT side FROM trades WHERE trade_id = '1'").Scan(&side); err != nil || side != "buy" {
+		t.Errorf("unexpected trade side %q (%v)", side, err)
+	}
+
+	var latestBid float64
+	if err := reader.db.QueryRow("SELECT COUNT(*) FROM orderbook_latest").Scan(&count); err != nil || count != 2 {
+		t.Errorf("expected 2 latest rows, got %d (%v)", count, err)
+	}
+	if err := reader.db.QueryRow("SELECT best_bid FROM orderbook_latest WHERE exchange = 'binancef'").Scan(&latestBid); err != nil || latestBid != newer {
+		t.Errorf("expected the latest best bid %v, got %v (%v)", newer, latestBid, err)
+	}
+
+	var mode string
+	if err := reader.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Errorf("expected WAL mode, got %q (%v)", mode, err)
+	}
+}
@@ -14,6 +14,7 @@ type SupabaseAPIClient struct {
 	baseURL string
 	apiKey  string
 	client  *http.Client
+	async   *supabaseAsync // Background inserts, nil when they run inline
 }
 
 // NewSupabaseAPIClient creates a new API client
@@ -44,10 +45,236 @@ type OrderbookSnapshotAPI struct {
 	AskLiquidity10Pct *float64  `json:"ask_liquidity_10_pct"`
 	TotalBidsQty      *float64  `json:"total_bids_qty"`
 	TotalAsksQty      *float64  `json:"total_asks_qty"`
+
+	// Notional liquidity in quote currency (price * size); existing Supabase tables get these
+	// columns from supabase/migrations/20261014165141_notional_liquidity.sql
+	BidNotional05Pct  *float64 `json:"bid_notional_05_pct"`
+	AskNotional05Pct  *float64 `json:"ask_notional_05_pct"`
+	BidNotional2Pct   *float64 `json:"bid_notional_2_pct"`
+	AskNotional2Pct   *float64 `json:"ask_notional_2_pct"`
+	BidNotional10Pct  *float64 `json:"bid_notional_10_pct"`
+	AskNotional10Pct  *float64 `json:"ask_notional_10_pct"`
+	TotalBidsNotional *float64 `json:"total_bids_notional"`
+	TotalAsksNotional *float64 `json:"total_asks_notional"`
+
+	// Configured bands without a dedicated column, keyed by label (e.g. "0.1%", "25bp")
+	// Only sent when such bands are configured, so the default schema needs no liquidity_bands column
+	LiquidityBands map[string]BandLiquidityAPI `json:"liquidity_bands,omitempty"`
+
+	// Short-horizon signals; imbalance is keyed by the number of levels per side. Existing
+	// Supabase tables get these columns from supabase/migrations/20261014165331_microprice_imbalance.sql
+	Microprice *float64                `json:"microprice"`
+	Imbalance  map[string]ImbalanceAPI `json:"imbalance"`
+
+	// Trailing window top-of-book metrics, only sent when rolling stats are enabled; needs a rolling column
+	Rolling *RollingAPI `json:"rolling,omitempty"`
+
+	// Cumulative depth at each configured curve offset, keyed by label (e.g. "25bp"); needs a depth_curve column
+	DepthCurve map[string]BandLiquidityAPI `json:"depth_curve,omitempty"`
+
+	// Market impact per configured order size (base units), keyed by size; needs an impact column
+	Impact map[string]ImpactAPI `json:"impact,omitempty"`
+
+	// Market impact per configured notional (quote currency), keyed by notional; needs a slippage column
+	Slippage map[string]ImpactAPI `json:"slippage,omitempty"`
+
+	// Taker flow from the public trades, only sent when flow is tracked and trades arrived; needs a flow column
+	Flow *FlowAPI `json:"flow,omitempty"`
+
+	// Funding and open interest of perpetual markets, only sent when their venue publishes them; needs a funding column
+	Funding *FundingAPI `json:"funding,omitempty"`
+
+	// Basis against the spot book of the same venue, only sent for perpetuals of a live pair; needs a basis column
+	Basis *BasisAPI `json:"basis,omitempty"`
+
+	// Recovery of the depth near mid after large depletions, only sent when resiliency is tracked; needs a resiliency column
+	Resiliency *ResiliencyAPI `json:"resiliency,omitempty"`
+
+	// Share of the consolidated liquidity and best quotes, only sent when market share is tracked; needs a market_share column
+	MarketShare *MarketShareAPI `json:"market_share,omitempty"`
+
+	// Realized volatility of mid per trailing window, keyed by window (e.g. "5m"), only sent when volatility is tracked; needs a volatility column
+	Volatility map[string]VolatilityAPI `json:"volatility,omitempty"`
+
+	// Order flow imbalance at the top of book per trailing window, keyed by window (e.g. "1m"), only sent when OFI is enabled; needs an ofi column
+	OFI map[string]OFIAPI `json:"ofi,omitempty"`
+
+	// Spread after the maker and taker fees of the venue, only sent when fee-adjusted spreads are enabled; needs an effective_spread column
+	EffectiveSpread *EffectiveSpreadAPI `json:"effective_spread,omitempty"`
+
+	// Index price across the venues, only sent with the consolidated row when an index is computed; needs an index_price column
+	IndexPrice *IndexAPI `json:"index_price,omitempty"`
+
+	// Size at the best bid and ask since the previous snapshot, only sent when touch tracking is enabled; needs a touch column
+	Touch *TouchAPI `json:"touch,omitempty"`
+}
+
+// ImbalanceAPI represents the imbalance and weighted mid over the top levels
+type ImbalanceAPI struct {
+	Imbalance   float64 `json:"imbalance"`
+	WeightedMid float64 `json:"weighted_mid"`
+}
+
+// RollingAPI represents spread and mid statistics over a trailing window
+type RollingAPI struct {
+	WindowSeconds       float64 `json:"window_seconds"`
+	Samples             int     `json:"samples"`
+	AvgSpread           float64 `json:"avg_spread"`
+	SpreadVolatility    float64 `json:"spread_volatility"`
+	MidReturnVolatility float64 `json:"mid_return_volatility"`
+}
+
+// FlowAPI represents the cumulative volume delta and the taker volumes over a trailing window
+type FlowAPI struct {
+	WindowSeconds float64 `json:"window_seconds"`
+	CVD           float64 `json:"cvd"` // Taker buy minus sell quantity since tracking started
+	BuyVolume     float64 `json:"buy_volume"`
+	SellVolume    float64 `json:"sell_volume"`
+	BuyRatio      float64 `json:"buy_ratio"` // Share of the window's taker volume bought
+	Trades        int     `json:"trades"`
+	LargeTrades   int     `json:"large_trades"`
+}
+
+// FundingAPI represents the funding, prices and open interest of a perpetual market
+// Prices and open interest are zero when the venue does not publish them
+type FundingAPI struct {
+	Rate                 float64 `json:"rate"`               // Funding rate of the current interval, as a fraction
+	SecondsToFunding     float64 `json:"seconds_to_funding"` // Zero when the venue does not say
+	MarkPrice            float64 `json:"mark_price"`
+	IndexPrice           float64 `json:"index_price"`
+	OpenInterest         float64 `json:"open_interest"` // Base units
+	OpenInterestNotional float64 `json:"open_interest_notional"`
+	OpenInterestChange   float64 `json:"open_interest_change"` // Over the trailing window
+	WindowSeconds        float64 `json:"window_seconds"`
 }
 
-// InsertOrderbookSnapshot inserts a single snapshot via API
+// BasisAPI represents the basis of a perpetual over the spot book of its venue
+type BasisAPI struct {
+	SpotExchange      string  `json:"spot_exchange"`
+	SpotMid           float64 `json:"spot_mid"`
+	BasisBps          float64 `json:"basis_bps"`          // (perp mid / spot mid - 1) in basis points
+	AnnualizedBasis   float64 `json:"annualized_basis"`   // Basis earned every funding interval, over a year; zero without funding
+	AnnualizedFunding float64 `json:"annualized_funding"` // Zero without funding
+}
+
+// ResiliencyAPI represents how quickly liquidity near mid was replenished after large depletions
+type ResiliencyAPI struct {
+	Score               float64 `json:"score"`      // 0-1, 1 recovering at once or without depletions
+	Depletions          int     `json:"depletions"` // Ended within the trailing window
+	Recovered           int     `json:"recovered"`
+	MeanRecoverySeconds float64 `json:"mean_recovery_seconds"`
+	Replenishment       float64 `json:"replenishment"` // Size placed near mid while depleted over the size taken
+	BidDepth            float64 `json:"bid_depth"`     // Base units near mid
+	AskDepth            float64 `json:"ask_depth"`
+	WindowSeconds       float64 `json:"window_seconds"`
+}
+
+// MarketShareAPI represents the share of a venue in the liquidity and best quotes across the venues
+type MarketShareAPI struct {
+	Bands          map[string]BandShareAPI `json:"bands,omitempty"` // Keyed by band label (e.g. "0.5%"), leaving out bands the feed does not reach
+	BestBid        float64                 `json:"best_bid"`        // Share of the sampled time at the consolidated best bid, ties counting for each venue
+	BestAsk        float64                 `json:"best_ask"`
+	SampledSeconds float64                 `json:"sampled_seconds"`
+}
+
+// BandShareAPI represents the share of a venue in the notional within one band, 0-1
+type BandShareAPI struct {
+	Bid   float64 `json:"bid"`
+	Ask   float64 `json:"ask"`
+	Total float64 `json:"total"`
+}
+
+// VolatilityAPI represents the realized volatility of mid over one trailing window
+type VolatilityAPI struct {
+	Volatility float64 `json:"volatility"` // Square root of the sum of squared log returns of mid
+	Annualized float64 `json:"annualized"`
+	Returns    int     `json:"returns"` // Between the mid samples in the window
+}
+
+// OFIAPI represents the order flow imbalance at the top of book over one trailing window
+type OFIAPI struct {
+	OFI     float64 `json:"ofi"` // Signed size changes at the best bid and ask, in base units
+	Updates int     `json:"updates"`
+}
+
+// EffectiveSpreadAPI represents the spread of the best bid and ask once the fees of the venue are paid, in basis points of mid
+type EffectiveSpreadAPI struct {
+	RawBps      float64 `json:"raw_bps"`
+	TakerBps    float64 `json:"taker_bps"` // Cost of crossing the spread both ways as taker
+	MakerBps    float64 `json:"maker_bps"` // Left from quoting both sides as maker, negative when fees exceed the spread
+	MakerFeeBps float64 `json:"maker_fee_bps"`
+	TakerFeeBps float64 `json:"taker_fee_bps"`
+}
+
+// IndexAPI represents a weighted index price across the venues after trimming outliers
+type IndexAPI struct {
+	Price     float64            `json:"price"`
+	Weighting string             `json:"weighting"` // equal, liquidity or volume
+	Venues    int                `json:"venues"`    // Counted in the index
+	Weights   map[string]float64 `json:"weights"`   // Share of the index by exchange, counted venues only
+}
+
+// TouchAPI represents the size at the best bid and ask over the collection interval, in base units
+type TouchAPI struct {
+	BidMean         float64 `json:"bid_mean"`
+	BidMin          float64 `json:"bid_min"`
+	BidTimeWeighted float64 `json:"bid_time_weighted"`
+	AskMean         float64 `json:"ask_mean"`
+	AskMin          float64 `json:"ask_min"`
+	AskTimeWeighted float64 `json:"ask_time_weighted"`
+	Updates         int     `json:"updates"` // Book updates within the interval
+}
+
+// ImpactAPI represents the simulated cost of a market order of one size or notional on each side
+// Values are nil when the book is too thin to fill it
+type ImpactAPI struct {
+	BuyVWAP      *float64 `json:"buy_vwap"`
+	BuySlippage  *float64 `json:"buy_slippage"`
+	SellVWAP     *float64 `json:"sell_vwap"`
+	SellSlippage *float64 `json:"sell_slippage"`
+}
+
+// BandLiquidityAPI represents the liquidity within one band; nil when the feed does not reach it
+type BandLiquidityAPI struct {
+	Bid         *float64 `json:"bid"`
+	Ask         *float64 `json:"ask"`
+	BidNotional *float64 `json:"bid_notional"`
+	AskNotional *float64 `json:"ask_notional"`
+}
+
+// DepthSnapshotAPI represents the top levels of one book at the time of a snapshot, best first
+type DepthSnapshotAPI struct {
+	Exchange  string     `json:"exchange"`
+	Symbol    string     `json:"symbol"`
+	Timestamp time.Time  `json:"timestamp"`
+	Bids      []LevelAPI `json:"bids"`
+	Asks      []LevelAPI `json:"asks"`
+}
+
+// LevelAPI represents one price level
+type LevelAPI struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// TradeAPI represents one trade of the tape
+type TradeAPI struct {
+	Exchange  string    `json:"exchange"`
+	Symbol    string    `json:"symbol"`
+	TradeID   string    `json:"trade_id"` // Venue trade ID, empty when the venue has none
+	Timestamp time.Time `json:"timestamp"`
+	Price     float64   `json:"price"`
+	Size      float64   `json:"size"`
+	Side      string    `json:"side"`  // buy or sell, the side of the order described
+	Taker     bool      `json:"taker"` // Whether that order took liquidity; public tapes report the taker's side
+}
+
+// InsertOrderbookSnapshot inserts a single snapshot via API, or queues it after StartAsync
 func (c *SupabaseAPIClient) InsertOrderbookSnapshot(snapshot *OrderbookSnapshotAPI) error {
+	if c.async != nil {
+		return c.async.enqueue(supabaseRows{snapshots: []*OrderbookSnapshotAPI{snapshot}})
+	}
+
 	jsonData, err := json.Marshal(snapshot)
 	if err != nil {
 		return fmt.Errorf("failed to marshal snapshot: %w", err)
@@ -77,12 +304,19 @@ func (c *SupabaseAPIClient) InsertOrderbookSnapshot(snapshot *OrderbookSnapshotA
 	return nil
 }
 
-// InsertOrderbookSnapshotsBatch inserts multiple snapshots via API
+// InsertOrderbookSnapshotsBatch inserts multiple snapshots via API, or queues them after StartAsync
 func (c *SupabaseAPIClient) InsertOrderbookSnapshotsBatch(snapshots []*OrderbookSnapshotAPI) error {
 	if len(snapshots) == 0 {
 		return nil
 	}
+	if c.async != nil {
+		return c.async.enqueue(supabaseRows{snapshots: snapshots})
+	}
+	return c.postSnapshots(snapshots)
+}
 
+// postSnapshots sends snapshots in one request
+func (c *SupabaseAPIClient) postSnapshots(snapshots []*OrderbookSnapshotAPI) error {
 	jsonData, err := json.Marshal(snapshots)
 	if err != nil {
 		return fmt.Errorf("failed to marshal snapshots: %w", err)
@@ -113,6 +347,74 @@ func (c *SupabaseAPIClient) InsertOrderbookSnapshotsBatch(snapshots []*Orderbook
 	return nil
 }
 
+// InsertDepthSnapshots inserts the levels of each book into orderbook_depth, one row per
+// book with bids and asks as JSONB arrays of {"price", "quantity"}, best first; the table needs
+// exchange text, symbol text, timestamp timestamptz, bids jsonb and asks jsonb columns
+func (c *SupabaseAPIClient) InsertDepthSnapshots(depth []*DepthSnapshotAPI) error {
+	if len(depth) == 0 {
+		return nil
+	}
+	if c.async != nil {
+		return c.async.enqueue(supabaseRows{depth: depth})
+	}
+	return c.postDepth(depth)
+}
+
+// postDepth sends the levels of books in one request
+func (c *SupabaseAPIClient) postDepth(depth []*DepthSnapshotAPI) error {
+	jsonData, err := json.Marshal(depth)
+	if err != nil {
+		return fmt.Errorf("failed to marshal depth: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/rest/v1/orderbook_depth", c.baseURL)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apikey", c.apiKey)
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Prefer", "return=minimal")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// InsertTrades inserts trades into the trades table, or queues them after StartAsync; the
+// table needs exchange text, symbol text, trade_id text, timestamp timestamptz, price float8,
+// size float8, side text and taker boolean columns
+func (c *SupabaseAPIClient) InsertTrades(trades []*TradeAPI) error {
+	if len(trades) == 0 {
+		return nil
+	}
+	if c.async != nil {
+		return c.async.enqueue(supabaseRows{trades: trades})
+	}
+	return c.postTrades(trades)
+}
+
+// postTrades sends trades in one request
+func (c *SupabaseAPIClient) postTrades(trades []*TradeAPI) error {
+	jsonData, err := json.Marshal(trades)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trades: %w", err)
+	}
+	_, err = c.restRequest("POST", "trades", nil, jsonData)
+	return err
+}
+
 // TestConnection tests the API connection
 func (c *SupabaseAPIClient) TestConnection() error {
 	url := fmt.Sprintf("%s/rest/v1/orderbook_snapshots?select=id&limit=1", c.baseURL)
@@ -138,7 +440,10 @@ func (c *SupabaseAPIClient) TestConnection() error {
 	return nil
 }
 
-// Close is a no-op for API client
+// Close flushes queued inserts; without StartAsync it is a no-op
 func (c *SupabaseAPIClient) Close() error {
+	if c.async != nil {
+		c.stopAsync()
+	}
 	return nil
 }
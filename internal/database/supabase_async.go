@@ -0,0 +1,230 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// SupabaseAsyncConfig configures background inserts of the Supabase client
+type SupabaseAsyncConfig struct {
+	QueueSize   int           // Pending inserts before new ones are dropped, default 1000
+	BatchSize   int           // Rows per request, default 500
+	BatchWindow time.Duration // Longest a row waits before it is sent, default 1s
+	Concurrency int           // Requests in flight at once, default 2
+}
+
+// SupabaseAsyncStats counts the rows of background inserts
+type SupabaseAsyncStats struct {
+	Queued  int64 // Accepted but not yet written
+	Written int64
+	Dropped int64 // Dropped because the queue was full
+	Failed  int64 // Lost to failed requests
+}
+
+// supabaseAsync is the queue and writers behind StartAsync
+type supabaseAsync struct {
+	config  SupabaseAsyncConfig
+	mu      sync.RWMutex // Guards closed against concurrent enqueues
+	closed  bool
+	queue   chan supabaseRows
+	stopped chan struct{} // Closed once every writer has flushed and exited
+	queued  atomic.Int64
+	written atomic.Int64
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+// supabaseRows is one queued insert for one of the tables
+type supabaseRows struct {
+	snapshots []*OrderbookSnapshotAPI
+	depth     []*DepthSnapshotAPI
+	trades    []*TradeAPI
+	latest    []*OrderbookSnapshotAPI // Upserted into orderbook_latest
+}
+
+// logger returns the logger of a storage client
+func logger(client string) *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, client)
+}
+
+// StartAsync makes inserts return as soon as they are queued; a background loop merges
+// them into batches sent by a pool of writers, so a slow API never delays the collector.
+// An insert error then means the rows were dropped; failed requests are logged and counted
+// in AsyncStats. Call it before the first insert; Close flushes the queue
+func (c *SupabaseAPIClient) StartAsync(config SupabaseAsyncConfig) {
+	if c.async != nil {
+		return
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 500
+	}
+	if config.BatchWindow <= 0 {
+		config.BatchWindow = time.Second
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 2
+	}
+
+	c.async = &supabaseAsync{
+		config:  config,
+		queue:   make(chan supabaseRows, config.QueueSize),
+		stopped: make(chan struct{}),
+	}
+	go c.batchLoop()
+}
+
+// AsyncStats returns the counters of background inserts, all zero when StartAsync was not called
+func (c *SupabaseAPIClient) AsyncStats() SupabaseAsyncStats {
+	if c.async == nil {
+		return SupabaseAsyncStats{}
+	}
+	return SupabaseAsyncStats{
+		Queued:  c.async.queued.Load(),
+		Written: c.async.written.Load(),
+		Dropped: c.async.dropped.Load(),
+		Failed:  c.async.failed.Load(),
+	}
+}
+
+// enqueue hands rows to the batch loop without blocking
+func (a *supabaseAsync) enqueue(rows supabaseRows) error {
+	count := int64(len(rows.snapshots) + len(rows.depth) + len(rows.trades) + len(rows.latest))
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return fmt.Errorf("Supabase client is closed")
+	}
+
+	select {
+	case a.queue <- rows:
+		a.queued.Add(count)
+		return nil
+	default:
+		a.dropped.Add(count)
+		return fmt.Errorf("Supabase insert queue full, dropped %d rows (%d total)", count, a.dropped.Load())
+	}
+}
+
+// batchLoop buffers queued rows and hands each table's batch to the writers when it is
+// full or its oldest row has waited the batch window
+func (c *SupabaseAPIClient) batchLoop() {
+	a := c.async
+	defer close(a.stopped)
+
+	requests := make(chan supabaseRows)
+	var writers sync.WaitGroup
+	for i := 0; i < a.config.Concurrency; i++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for rows := range requests {
+				c.writeRows(rows)
+			}
+		}()
+	}
+	defer writers.Wait()
+	defer close(requests)
+
+	window := time.NewTimer(a.config.BatchWindow)
+	window.Stop()
+	waiting := false
+
+	var snapshots []*OrderbookSnapshotAPI
+	var depth []*DepthSnapshotAPI
+	var trades []*TradeAPI
+	var latest []*OrderbookSnapshotAPI
+	send := func(all bool) {
+		for len(snapshots) >= a.config.BatchSize || (all && len(snapshots) > 0) {
+			n := min(len(snapshots), a.config.BatchSize)
+			requests <- supabaseRows{snapshots: snapshots[:n]}
+			snapshots = snapshots[n:]
+		}
+		for len(depth) >= a.config.BatchSize || (all && len(depth) > 0) {
+			n := min(len(depth), a.config.BatchSize)
+			requests <- supabaseRows{depth: depth[:n]}
+			depth = depth[n:]
+		}
+		for len(trades) >= a.config.BatchSize || (all && len(trades) > 0) {
+			n := min(len(trades), a.config.BatchSize)
+			requests <- supabaseRows{trades: trades[:n]}
+			trades = trades[n:]
+		}
+		for len(latest) >= a.config.BatchSize || (all && len(latest) > 0) {
+			n := min(len(latest), a.config.BatchSize)
+			requests <- supabaseRows{latest: latest[:n]}
+			latest = latest[n:]
+		}
+		if all {
+			window.Stop()
+			waiting = false
+		}
+	}
+
+	for {
+		select {
+		case rows, ok := <-a.queue:
+			if !ok {
+				send(true)
+				return
+			}
+			snapshots = append(snapshots, rows.snapshots...)
+			depth = append(depth, rows.depth...)
+			trades = append(trades, rows.trades...)
+			latest = append(latest, rows.latest...)
+			if !waiting {
+				window.Reset(a.config.BatchWindow)
+				waiting = true
+			}
+			send(false)
+		case <-window.C:
+			waiting = false
+			send(true)
+		}
+	}
+}
+
+// writeRows sends one batch and updates the counters
+func (c *SupabaseAPIClient) writeRows(rows supabaseRows) {
+	a := c.async
+	var err error
+	var count int
+	switch {
+	case len(rows.snapshots) > 0:
+		count, err = len(rows.snapshots), c.postSnapshots(rows.snapshots)
+	case len(rows.depth) > 0:
+		count, err = len(rows.depth), c.postDepth(rows.depth)
+	case len(rows.trades) > 0:
+		count, err = len(rows.trades), c.postTrades(rows.trades)
+	default:
+		count, err = len(rows.latest), c.postLatest(rows.latest)
+	}
+
+	a.queued.Add(-int64(count))
+	if err != nil {
+		a.failed.Add(int64(count))
+		logger("supabase").Error("Failed to insert rows", "event", "insert_failed", "rows", count, "error", err)
+		return
+	}
+	a.written.Add(int64(count))
+}
+
+// stopAsync flushes the queue and waits for the writers
+func (c *SupabaseAPIClient) stopAsync() {
+	a := c.async
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	<-a.stopped
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSupabaseAsyncBatches(t *testing.T) {
+	var mu sync.Mutex
+	batches := map[string][]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rows []json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&rows); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		mu.Lock()
+		batches[r.URL.Path] = append(batches[r.URL.Path], len(rows))
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewSupabaseAPIClient(server.URL, "key")
+	client.StartAsync(SupabaseAsyncConfig{BatchSize: 3, BatchWindow: time.Hour})
+
+	snapshot := &OrderbookSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT"}
+	for i := 0; i < 4; i++ {
+		if err := client.InsertOrderbookSnapshotsBatch([]*OrderbookSnapshotAPI{snapshot}); err != nil {
+			t.Fatalf("InsertOrderbookSnapshotsBatch() error: %v", err)
+		}
+	}
+	if err := client.InsertDepthSnapshots([]*DepthSnapshotAPI{{Exchange: "bybit"}}); err != nil {
+		t.Fatalf("InsertDepthSnapshots() error: %v", err)
+	}
+	client.Close()
+
+	// One full batch of three, the rest flushed on close
+	mu.Lock()
+	defer mu.Unlock()
+	snapshots := batches["/rest/v1/orderbook_snapshots"]
+	if len(snapshots) != 2 || snapshots[0]+snapshots[1] != 4 || max(snapshots[0], snapshots[1]) != 3 {
+		t.Errorf("unexpected snapshot batches %v", snapshots)
+	}
+	if depth := batches["/rest/v1/orderbook_depth"]; len(depth) != 1 || depth[0] != 1 {
+		t.Errorf("unexpected depth batches %v", depth)
+	}
+	if stats := client.AsyncStats(); stats != (SupabaseAsyncStats{Written: 5}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if err := client.InsertOrderbookSnapshot(snapshot); err == nil {
+		t.Error("expected an error once closed")
+	}
+}
+
+func TestSupabaseAsyncDropsAndFailures(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewSupabaseAPIClient(server.URL, "key")
+	client.StartAsync(SupabaseAsyncConfig{QueueSize: 1, BatchSize: 1, Concurrency: 1})
+
+	// The writer blocks on the first row and the loop on the second, so the queue fills
+	snapshot := []*OrderbookSnapshotAPI{{Exchange: "okx", Symbol: "BTCUSDT"}}
+	dropped := false
+	for i := 0; i < 10 && !dropped; i++ {
+		dropped = client.InsertOrderbookSnapshotsBatch(snapshot) != nil
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !dropped {
+		t.Fatal("expected a full queue to drop rows")
+	}
+	close(release)
+	client.Close()
+
+	stats := client.AsyncStats()
+	if stats.Queued != 0 || stats.Written != 0 || stats.Dropped == 0 || stats.Failed == 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
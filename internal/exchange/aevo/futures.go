@@ -0,0 +1,404 @@
+package aevo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsURL        = "wss://ws.aevo.xyz"
+	pingInterval = 15 * time.Second
+)
+
+// FuturesExchange implements the Exchange interface for Aevo perpetuals
+// Aevo books carry no sequence numbers, so the last_updated nanosecond
+// timestamp of each message is used as its update ID
+type FuturesExchange struct {
+	symbol           string
+	instrument       string // Aevo format (e.g., BTC-PERP)
+	wsConn           *websocket.Conn
+	writeMu          sync.Mutex
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	lastUpdated      int64
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+	snapshotReceived bool
+}
+
+// NewFuturesExchange creates a new Aevo perpetual exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		instrument: config.Symbol,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.Aevof
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Aevo
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	channel := fmt.Sprintf("orderbook:%s", e.instrument)
+	tradesChannel := fmt.Sprintf("trades:%s", e.instrument)
+	if err := e.send(WSRequest{Op: "subscribe", Data: []string{channel, tradesChannel}}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", channel, "trade_channel", tradesChannel)
+
+	go e.pingLoop()
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the latest orderbook snapshot received from WebSocket
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Aevo perpetual feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.instrument,
+	}
+}
+
+// send writes a request to the WebSocket
+func (e *FuturesExchange) send(req WSRequest) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// pingLoop keeps the connection alive while the book is quiet
+func (e *FuturesExchange) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-e.done:
+			return
+		case <-ticker.C:
+			if err := e.send(WSRequest{Op: "ping"}); err != nil {
+				e.log().Error("Failed to send ping", "event", "heartbeat_failed", "error", err)
+			}
+		}
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.updateLastPing()
+
+			if msg.Error != "" {
+				e.incrementErrorCount()
+				e.log().Error("Request error", "event", "request_failed", "error", msg.Error)
+				continue
+			}
+
+			if len(msg.Data) == 0 {
+				continue
+			}
+
+			if strings.HasPrefix(msg.Channel, "trades:") {
+				e.incrementMessageCount()
+				e.handleTrade(msg.Data)
+				continue
+			}
+
+			if !strings.HasPrefix(msg.Channel, "orderbook:") {
+				continue
+			}
+
+			e.incrementMessageCount()
+
+			var book OrderBook
+			if err := json.Unmarshal(msg.Data, &book); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to parse orderbook", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			lastUpdated, err := strconv.ParseInt(book.LastUpdated, 10, 64)
+			if err != nil {
+				e.incrementErrorCount()
+				e.log().Warn("Invalid last_updated", "event", "parse_failed", "last_updated", book.LastUpdated, "error", err)
+				continue
+			}
+
+			bids := convertLevels(book.Bids)
+			asks := convertLevels(book.Asks)
+
+			// Aevo resends snapshots periodically; keep the newest for reinitialization
+			if book.Type == "snapshot" {
+				e.snapshotMu.Lock()
+				e.snapshot = &exchange.Snapshot{
+					Exchange:     e.GetName(),
+					Symbol:       book.InstrumentName,
+					LastUpdateID: lastUpdated,
+					Bids:         bids,
+					Asks:         asks,
+					Timestamp:    time.Unix(0, lastUpdated),
+				}
+				e.snapshotReceived = true
+				e.snapshotMu.Unlock()
+				e.lastUpdated = lastUpdated
+				continue
+			}
+
+			prev := e.lastUpdated
+			e.lastUpdated = lastUpdated
+
+			canonicalUpdate := &exchange.DepthUpdate{
+				Exchange:      e.GetName(),
+				Symbol:        book.InstrumentName,
+				EventTime:     time.Unix(0, lastUpdated),
+				FirstUpdateID: prev + 1,
+				FinalUpdateID: lastUpdated,
+				PrevUpdateID:  prev,
+				Bids:          bids,
+				Asks:          asks,
+			}
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrade forwards a trades channel message
+func (e *FuturesExchange) handleTrade(raw json.RawMessage) {
+	var trade Trade
+	if err := json.Unmarshal(raw, &trade); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trade", "event", "parse_failed", "error", err)
+		return
+	}
+
+	created, _ := strconv.ParseInt(trade.CreatedTimestamp, 10, 64)
+	exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.InstrumentName,
+		TradeID:   trade.TradeID,
+		Price:     trade.Price,
+		Quantity:  trade.Amount,
+		Side:      exchange.TradeSide(trade.Side),
+		Timestamp: time.Unix(0, created),
+	})
+}
+
+// convertLevels converts [price, amount, iv] entries to canonical price levels
+func convertLevels(levels [][]string) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) >= 2 {
+			result = append(result, exchange.PriceLevel{
+				Price:    level[0],
+				Quantity: level[1],
+			})
+		}
+	}
+	return result
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,40 @@
+package aevo
+
+import "encoding/json"
+
+// Config holds configuration for Aevo exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC-PERP), as resolved by symbols.Native
+}
+
+// WSRequest represents an Aevo WebSocket request
+type WSRequest struct {
+	Op   string   `json:"op"` // "subscribe" or "ping"
+	Data []string `json:"data,omitempty"`
+}
+
+// WSMessage represents a WebSocket message from Aevo; Data is decoded by channel
+type WSMessage struct {
+	Channel string          `json:"channel"` // e.g., orderbook:BTC-PERP, trades:BTC-PERP
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+}
+
+// OrderBook represents an orderbook snapshot or update payload
+type OrderBook struct {
+	Type           string     `json:"type"` // "snapshot" or "update"
+	InstrumentName string     `json:"instrument_name"`
+	Bids           [][]string `json:"bids"`         // [price, amount, iv]
+	Asks           [][]string `json:"asks"`         // [price, amount, iv]
+	LastUpdated    string     `json:"last_updated"` // Nanoseconds
+}
+
+// Trade represents a trades channel payload
+type Trade struct {
+	TradeID          string `json:"trade_id"`
+	InstrumentName   string `json:"instrument_name"`
+	Side             string `json:"side"` // Taker side: "buy" or "sell"
+	Price            string `json:"price"`
+	Amount           string `json:"amount"`
+	CreatedTimestamp string `json:"created_timestamp"` // Nanoseconds
+}
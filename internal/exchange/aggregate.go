@@ -0,0 +1,485 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/shopspring/decimal"
+)
+
+// Aggregate implements the Exchange interface by merging several venues into one
+// consolidated book. Quantities are summed per price, optionally bucketed by a tick
+// size (bids floored, asks ceiled), and every change to a bucket is emitted as a
+// depth update on a single contiguous sequence
+type Aggregate struct {
+	symbol     string
+	tickSize   decimal.Decimal
+	venues     []*aggregateVenue
+	updateChan chan *DepthUpdate
+	tradeChan  chan *Trade // Trades of every venue, relabelled as the aggregate's
+	done       chan struct{}
+	loaded     chan struct{} // Closed once every venue has attempted its first snapshot
+	ctx        context.Context
+	cancel     context.CancelFunc
+	health     atomic.Value // stores HealthStatus
+	isRunning  bool
+	wg         sync.WaitGroup
+	bookMu     sync.Mutex
+	sequence   int64
+	bids       map[string]decimal.Decimal // Consolidated quantity per bucket price
+	asks       map[string]decimal.Decimal
+}
+
+// aggregateVenue holds the last known levels of one underlying venue
+type aggregateVenue struct {
+	ex           Exchange
+	lastUpdateID int64
+	bids         map[string]decimal.Decimal // Quantity per venue price
+	asks         map[string]decimal.Decimal
+}
+
+// NewAggregate creates a consolidated book over the given venues
+// A zero tickSize merges levels at their exact prices
+func NewAggregate(symbol string, venues []Exchange, tickSize decimal.Decimal) *Aggregate {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a := &Aggregate{
+		symbol:     symbol,
+		tickSize:   tickSize,
+		venues:     make([]*aggregateVenue, 0, len(venues)),
+		updateChan: make(chan *DepthUpdate, 1000),
+		tradeChan:  make(chan *Trade, 1000),
+		done:       make(chan struct{}),
+		loaded:     make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+		bids:       make(map[string]decimal.Decimal),
+		asks:       make(map[string]decimal.Decimal),
+	}
+
+	for _, ex := range venues {
+		a.venues = append(a.venues, &aggregateVenue{
+			ex:   ex,
+			bids: make(map[string]decimal.Decimal),
+			asks: make(map[string]decimal.Decimal),
+		})
+	}
+
+	a.health.Store(HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return a
+}
+
+// GetName returns the exchange name
+func (a *Aggregate) GetName() ExchangeName {
+	return Aggregated
+}
+
+// GetSymbol returns the trading symbol
+func (a *Aggregate) GetSymbol() string {
+	return a.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (a *Aggregate) log() *slog.Logger {
+	return logging.For(string(a.GetName()), a.GetSymbol())
+}
+
+// Connect connects every venue concurrently and starts merging their books
+// Venues that fail to connect are skipped; it only fails if none connect
+func (a *Aggregate) Connect(ctx context.Context) error {
+	connected := make([]*aggregateVenue, 0, len(a.venues))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, v := range a.venues {
+		wg.Add(1)
+		go func(v *aggregateVenue) {
+			defer wg.Done()
+			if err := v.ex.Connect(ctx); err != nil {
+				a.incrementErrorCount()
+				a.log().Error("Failed to connect venue", "event", "connect_failed", "venue", v.ex.GetName(), "error", err)
+				return
+			}
+			mu.Lock()
+			connected = append(connected, v)
+			mu.Unlock()
+		}(v)
+	}
+	wg.Wait()
+
+	if len(connected) == 0 {
+		return fmt.Errorf("no venues connected")
+	}
+	a.venues = connected
+
+	a.updateConnectionStatus(true)
+	a.log().Info("Merging venues", "event", "merging", "venues", len(connected))
+
+	var loading sync.WaitGroup
+	var trading sync.WaitGroup
+	for _, v := range connected {
+		a.wg.Add(1)
+		loading.Add(1)
+		go a.runVenue(v, &loading)
+		if trades := v.ex.Trades(); trades != nil {
+			trading.Add(1)
+			go a.forwardTrades(v, trades, &trading)
+		}
+	}
+
+	go func() {
+		loading.Wait()
+		close(a.loaded)
+	}()
+
+	go func() {
+		a.wg.Wait()
+		trading.Wait()
+		close(a.updateChan)
+		close(a.tradeChan)
+		a.updateConnectionStatus(false)
+	}()
+
+	a.isRunning = true
+	return nil
+}
+
+// Close closes every venue
+func (a *Aggregate) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	select {
+	case <-a.done:
+	default:
+		close(a.done)
+	}
+
+	for _, v := range a.venues {
+		if err := v.ex.Close(); err != nil {
+			a.log().Warn("Failed to close venue", "event", "close_failed", "venue", v.ex.GetName(), "error", err)
+		}
+	}
+
+	a.updateConnectionStatus(false)
+	return nil
+}
+
+// GetSnapshot returns the consolidated book once every venue has loaded its snapshot
+func (a *Aggregate) GetSnapshot(ctx context.Context) (*Snapshot, error) {
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timeout.C:
+		return nil, fmt.Errorf("timeout waiting for venue snapshots")
+	case <-a.loaded:
+	}
+
+	a.bookMu.Lock()
+	defer a.bookMu.Unlock()
+
+	return &Snapshot{
+		Exchange:     a.GetName(),
+		Symbol:       a.symbol,
+		LastUpdateID: a.sequence,
+		Bids:         levelsFromTotals(a.bids),
+		Asks:         levelsFromTotals(a.asks),
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (a *Aggregate) Updates() <-chan *DepthUpdate {
+	return a.updateChan
+}
+
+// Trades returns a channel that receives the trades of every venue, as trades of the
+// aggregate with venue-prefixed IDs
+func (a *Aggregate) Trades() <-chan *Trade {
+	return a.tradeChan
+}
+
+// IsConnected checks if the aggregate is merging venues
+func (a *Aggregate) IsConnected() bool {
+	return a.isRunning
+}
+
+// Health returns connection health information
+func (a *Aggregate) Health() HealthStatus {
+	if status, ok := a.health.Load().(HealthStatus); ok {
+		return status
+	}
+	return HealthStatus{}
+}
+
+// Capabilities returns the features of the consolidated book
+// Depth is limited by the shallowest venue and updates follow the fastest one
+func (a *Aggregate) Capabilities() Capabilities {
+	caps := Capabilities{NativeSymbol: a.symbol}
+	for i, v := range a.venues {
+		venueCaps := v.ex.Capabilities()
+		if venueCaps.MaxDepth > 0 && (caps.MaxDepth == 0 || venueCaps.MaxDepth < caps.MaxDepth) {
+			caps.MaxDepth = venueCaps.MaxDepth
+		}
+		if i == 0 || venueCaps.UpdateInterval < caps.UpdateInterval {
+			caps.UpdateInterval = venueCaps.UpdateInterval
+		}
+		caps.Trades = caps.Trades || venueCaps.Trades
+	}
+	return caps
+}
+
+// runVenue loads a venue snapshot and folds its updates into the consolidated book
+// When the venue disconnects its liquidity is removed from the book
+func (a *Aggregate) runVenue(v *aggregateVenue, loading *sync.WaitGroup) {
+	defer a.wg.Done()
+
+	snapshot, err := v.ex.GetSnapshot(a.ctx)
+	if err != nil {
+		loading.Done()
+		a.incrementErrorCount()
+		a.log().Error("Failed to get venue snapshot", "event", "snapshot_failed", "venue", v.ex.GetName(), "error", err)
+		return
+	}
+	a.loadVenue(v, snapshot)
+	loading.Done()
+
+	for update := range v.ex.Updates() {
+		a.incrementMessageCount()
+		a.updateLastPing()
+
+		if update.FinalUpdateID <= v.lastUpdateID {
+			continue
+		}
+
+		contiguous := update.PrevUpdateID == v.lastUpdateID ||
+			update.FirstUpdateID <= v.lastUpdateID+1
+		if !contiguous {
+			a.incrementErrorCount()
+			a.log().Warn("Sequence gap, resynchronizing venue", "event", "sequence_gap", "venue", v.ex.GetName(), "last_update_id", v.lastUpdateID, "first_update_id", update.FirstUpdateID)
+
+			snapshot, err := v.ex.GetSnapshot(a.ctx)
+			if err != nil {
+				a.log().Error("Failed to resync venue", "event", "resync_failed", "venue", v.ex.GetName(), "error", err)
+				continue
+			}
+			a.loadVenue(v, snapshot)
+			continue
+		}
+
+		a.applyVenue(v, update)
+	}
+
+	a.log().Warn("Venue disconnected, removing its liquidity", "event", "venue_disconnected", "venue", v.ex.GetName())
+	a.loadVenue(v, &Snapshot{})
+}
+
+// forwardTrades relabels the trades of a venue until its feed closes
+func (a *Aggregate) forwardTrades(v *aggregateVenue, trades <-chan *Trade, trading *sync.WaitGroup) {
+	defer trading.Done()
+	for trade := range trades {
+		forwarded := *trade
+		forwarded.Exchange = a.GetName()
+		forwarded.Symbol = a.symbol
+		if trade.TradeID != "" {
+			forwarded.TradeID = string(v.ex.GetName()) + ":" + trade.TradeID
+		}
+		SendTrade(a.GetName(), a.tradeChan, &forwarded)
+	}
+}
+
+// loadVenue replaces all of a venue's levels with the snapshot
+func (a *Aggregate) loadVenue(v *aggregateVenue, snapshot *Snapshot) {
+	a.bookMu.Lock()
+	defer a.bookMu.Unlock()
+
+	v.lastUpdateID = snapshot.LastUpdateID
+
+	bidChanged := make(map[string]struct{})
+	askChanged := make(map[string]struct{})
+
+	for price := range v.bids {
+		a.setLevel(v.bids, a.bids, PriceLevel{Price: price, Quantity: "0"}, true, bidChanged)
+	}
+	for price := range v.asks {
+		a.setLevel(v.asks, a.asks, PriceLevel{Price: price, Quantity: "0"}, false, askChanged)
+	}
+	for _, level := range snapshot.Bids {
+		a.setLevel(v.bids, a.bids, level, true, bidChanged)
+	}
+	for _, level := range snapshot.Asks {
+		a.setLevel(v.asks, a.asks, level, false, askChanged)
+	}
+
+	a.emit(bidChanged, askChanged)
+}
+
+// applyVenue applies a venue update to its levels and the consolidated book
+func (a *Aggregate) applyVenue(v *aggregateVenue, update *DepthUpdate) {
+	a.bookMu.Lock()
+	defer a.bookMu.Unlock()
+
+	v.lastUpdateID = update.FinalUpdateID
+
+	bidChanged := make(map[string]struct{})
+	askChanged := make(map[string]struct{})
+
+	for _, level := range update.Bids {
+		a.setLevel(v.bids, a.bids, level, true, bidChanged)
+	}
+	for _, level := range update.Asks {
+		a.setLevel(v.asks, a.asks, level, false, askChanged)
+	}
+
+	a.emit(bidChanged, askChanged)
+}
+
+// setLevel sets a venue level and adjusts the bucket it falls in by the difference
+// (must be called with bookMu locked)
+func (a *Aggregate) setLevel(venueSide, bookSide map[string]decimal.Decimal, level PriceLevel, isBid bool, changed map[string]struct{}) {
+	price, err := decimal.NewFromString(level.Price)
+	if err != nil {
+		a.log().Warn("Invalid price", "event", "parse_failed", "price", level.Price, "error", err)
+		return
+	}
+	qty, err := decimal.NewFromString(level.Quantity)
+	if err != nil {
+		a.log().Warn("Invalid quantity", "event", "parse_failed", "quantity", level.Quantity, "error", err)
+		return
+	}
+
+	old := venueSide[level.Price]
+	if qty.IsZero() {
+		delete(venueSide, level.Price)
+	} else {
+		venueSide[level.Price] = qty
+	}
+
+	if old.Equal(qty) {
+		return
+	}
+
+	key := a.bucket(price, isBid).String()
+	total := bookSide[key].Sub(old).Add(qty)
+	if total.Sign() <= 0 {
+		delete(bookSide, key)
+	} else {
+		bookSide[key] = total
+	}
+	changed[key] = struct{}{}
+}
+
+// bucket rounds a price to the tick size, flooring bids and ceiling asks
+func (a *Aggregate) bucket(price decimal.Decimal, isBid bool) decimal.Decimal {
+	if a.tickSize.IsZero() {
+		return price
+	}
+
+	divided := price.Div(a.tickSize)
+	if isBid {
+		return divided.Floor().Mul(a.tickSize)
+	}
+	return divided.Ceil().Mul(a.tickSize)
+}
+
+// emit sends the changed buckets as the next update on the consolidated sequence
+// (must be called with bookMu locked)
+func (a *Aggregate) emit(bidChanged, askChanged map[string]struct{}) {
+	if len(bidChanged) == 0 && len(askChanged) == 0 {
+		return
+	}
+
+	a.sequence++
+	update := &DepthUpdate{
+		Exchange:      a.GetName(),
+		Symbol:        a.symbol,
+		EventTime:     time.Now(),
+		FirstUpdateID: a.sequence,
+		FinalUpdateID: a.sequence,
+		PrevUpdateID:  a.sequence - 1,
+		Bids:          changedLevels(a.bids, bidChanged),
+		Asks:          changedLevels(a.asks, askChanged),
+	}
+
+	select {
+	case a.updateChan <- update:
+	case <-a.ctx.Done():
+	case <-a.done:
+	default:
+		a.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", update.FinalUpdateID)
+	}
+}
+
+// changedLevels returns the current totals of the changed buckets, zero when removed
+func changedLevels(totals map[string]decimal.Decimal, changed map[string]struct{}) []PriceLevel {
+	levels := make([]PriceLevel, 0, len(changed))
+	for key := range changed {
+		qty, ok := totals[key]
+		if !ok {
+			levels = append(levels, PriceLevel{Price: key, Quantity: "0"})
+			continue
+		}
+		levels = append(levels, PriceLevel{Price: key, Quantity: qty.String()})
+	}
+	return levels
+}
+
+// levelsFromTotals converts bucket totals into price levels
+func levelsFromTotals(totals map[string]decimal.Decimal) []PriceLevel {
+	levels := make([]PriceLevel, 0, len(totals))
+	for key, qty := range totals {
+		levels = append(levels, PriceLevel{Price: key, Quantity: qty.String()})
+	}
+	return levels
+}
+
+// updateConnectionStatus updates the connection status in health
+func (a *Aggregate) updateConnectionStatus(connected bool) {
+	status := a.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	a.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (a *Aggregate) incrementMessageCount() {
+	status := a.Health()
+	status.MessageCount++
+	a.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (a *Aggregate) incrementErrorCount() {
+	status := a.Health()
+	status.ErrorCount++
+	a.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (a *Aggregate) updateLastPing() {
+	status := a.Health()
+	status.LastPing = time.Now()
+	a.health.Store(status)
+}
@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// fakeExchange serves a fixed snapshot and whatever updates the test pushes
+type fakeExchange struct {
+	name     ExchangeName
+	snapshot *Snapshot
+	updates  chan *DepthUpdate
+	trades   chan *Trade
+}
+
+func newFakeExchange(name ExchangeName, snapshot *Snapshot) *fakeExchange {
+	return &fakeExchange{name: name, snapshot: snapshot, updates: make(chan *DepthUpdate, 10), trades: make(chan *Trade, 10)}
+}
+
+func (f *fakeExchange) GetName() ExchangeName             { return f.name }
+func (f *fakeExchange) GetSymbol() string                 { return "BTCUSDT" }
+func (f *fakeExchange) Connect(ctx context.Context) error { return nil }
+func (f *fakeExchange) Close() error                      { return nil }
+func (f *fakeExchange) Updates() <-chan *DepthUpdate      { return f.updates }
+func (f *fakeExchange) Trades() <-chan *Trade             { return f.trades }
+func (f *fakeExchange) IsConnected() bool                 { return true }
+func (f *fakeExchange) Health() HealthStatus              { return HealthStatus{} }
+func (f *fakeExchange) Capabilities() Capabilities        { return Capabilities{} }
+func (f *fakeExchange) GetSnapshot(ctx context.Context) (*Snapshot, error) {
+	return f.snapshot, nil
+}
+
+func levelMapOf(levels []PriceLevel) map[string]string {
+	m := make(map[string]string, len(levels))
+	for _, level := range levels {
+		m[level.Price] = level.Quantity
+	}
+	return m
+}
+
+func TestAggregateMergesVenues(t *testing.T) {
+	venueA := newFakeExchange(Binance, &Snapshot{
+		LastUpdateID: 10,
+		Bids:         []PriceLevel{{Price: "100.4", Quantity: "1"}, {Price: "99", Quantity: "2"}},
+		Asks:         []PriceLevel{{Price: "101.2", Quantity: "1"}},
+	})
+	venueB := newFakeExchange(Bybit, &Snapshot{
+		LastUpdateID: 5,
+		Bids:         []PriceLevel{{Price: "100.7", Quantity: "3"}},
+		Asks:         []PriceLevel{{Price: "101.00", Quantity: "2"}},
+	})
+
+	agg := NewAggregate("BTCUSDT", []Exchange{venueA, venueB}, decimal.NewFromInt(1))
+	if err := agg.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer agg.Close()
+
+	snapshot, err := agg.GetSnapshot(context.Background())
+	if err != nil {
+		t.Fatalf("GetSnapshot() error: %v", err)
+	}
+
+	bids := levelMapOf(snapshot.Bids)
+	asks := levelMapOf(snapshot.Asks)
+	if bids["100"] != "4" || bids["99"] != "2" || len(bids) != 2 {
+		t.Errorf("Unexpected bids: %v", bids)
+	}
+	// Asks are ceiled, so 101.2 lands in 102 while 101.00 stays at 101
+	if asks["101"] != "2" || asks["102"] != "1" || len(asks) != 2 {
+		t.Errorf("Unexpected asks: %v", asks)
+	}
+
+	// Removing venue A's 100.4 bid leaves venue B's 3 in the 100 bucket
+	venueA.updates <- &DepthUpdate{
+		FirstUpdateID: 11,
+		FinalUpdateID: 11,
+		PrevUpdateID:  10,
+		Bids:          []PriceLevel{{Price: "100.4", Quantity: "0"}},
+	}
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case update := <-agg.Updates():
+			if update.FinalUpdateID <= snapshot.LastUpdateID {
+				continue
+			}
+			if update.PrevUpdateID != snapshot.LastUpdateID {
+				t.Errorf("Expected update chained to %d, got pu=%d", snapshot.LastUpdateID, update.PrevUpdateID)
+			}
+			if got := levelMapOf(update.Bids); got["100"] != "3" || len(got) != 1 {
+				t.Errorf("Expected bucket 100 at 3, got %v", got)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Timed out waiting for consolidated update")
+		}
+	}
+}
+
+func TestAggregateTrades(t *testing.T) {
+	venueA := newFakeExchange(Binance, &Snapshot{LastUpdateID: 1})
+	venueB := newFakeExchange(Bybit, &Snapshot{LastUpdateID: 1})
+	venueB.trades = nil // No trades feed
+
+	agg := NewAggregate("BTCUSDT", []Exchange{venueA, venueB}, decimal.Zero)
+	if err := agg.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer agg.Close()
+
+	venueA.trades <- &Trade{Exchange: Binance, Symbol: "btcusdt", TradeID: "7", Price: "100", Quantity: "2", Side: TakerBuy}
+	select {
+	case trade := <-agg.Trades():
+		if trade.Exchange != Aggregated || trade.Symbol != "BTCUSDT" || trade.TradeID != "binance:7" || trade.Side != TakerBuy || trade.Quantity != "2" {
+			t.Errorf("unexpected trade %+v", trade)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the forwarded trade")
+	}
+
+	// The trades channel closes with the updates once every venue feed has ended
+	close(venueA.trades)
+	close(venueA.updates)
+	close(venueB.updates)
+	select {
+	case _, ok := <-agg.Trades():
+		if ok {
+			t.Error("expected the trades channel to close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the trades channel to close")
+	}
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
 // FuturesExchange implements the Exchange interface for Asterdex Futures
@@ -21,6 +23,7 @@ type FuturesExchange struct {
 	restURL    string
 	wsConn     *websocket.Conn
 	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
 	done       chan struct{}
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -37,7 +40,7 @@ func NewFuturesExchange(config Config) *FuturesExchange {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	symbol := strings.ToLower(config.Symbol)
-	wsURL := fmt.Sprintf("wss://fstream.asterdex.com/ws/%s@depth", symbol)
+	wsURL := fmt.Sprintf("wss://fstream.asterdex.com/stream?streams=%s@depth/%s@aggTrade", symbol, symbol)
 	restURL := fmt.Sprintf("https://fapi.asterdex.com/fapi/v1/depth?symbol=%s&limit=1000", strings.ToUpper(config.Symbol))
 
 	ex := &FuturesExchange{
@@ -45,6 +48,7 @@ func NewFuturesExchange(config Config) *FuturesExchange {
 		wsURL:      wsURL,
 		restURL:    restURL,
 		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
 		done:       make(chan struct{}),
 		ctx:        ctx,
 		cancel:     cancel,
@@ -70,6 +74,11 @@ func (e *FuturesExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to Asterdex Futures
 func (e *FuturesExchange) Connect(ctx context.Context) error {
 	dialer := websocket.Dialer{
@@ -84,7 +93,7 @@ func (e *FuturesExchange) Connect(ctx context.Context) error {
 
 	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
+	e.log().Info("WebSocket connected", "event", "ws_connected")
 
 	go e.readMessages()
 
@@ -107,7 +116,7 @@ func (e *FuturesExchange) Close() error {
 		err := e.wsConn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
 		}
 
 		select {
@@ -122,7 +131,7 @@ func (e *FuturesExchange) Close() error {
 
 // GetSnapshot fetches the initial orderbook snapshot via REST API
 func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Fetching orderbook snapshot...", e.GetName())
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
 
 	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
 	if err != nil {
@@ -152,6 +161,11 @@ func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
 // IsConnected checks if the WebSocket connection is active
 func (e *FuturesExchange) IsConnected() bool {
 	return e.wsConn != nil
@@ -165,30 +179,58 @@ func (e *FuturesExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the Asterdex Futures feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       1000,
+		UpdateInterval: 250 * time.Millisecond,
+		Trades:         true,
+		NativeSymbol:   strings.ToUpper(e.symbol),
+	}
+}
+
 // readMessages continuously reads WebSocket messages
 func (e *FuturesExchange) readMessages() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
 	defer e.updateConnectionStatus(false)
 
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
 			return
 		case <-e.done:
 			return
 		default:
-			var msg DepthUpdate
-			if err := e.wsConn.ReadJSON(&msg); err != nil {
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
 				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
 				return
 			}
 
 			e.incrementMessageCount()
 			e.updateLastPing()
 
-			canonicalUpdate := e.convertDepthUpdate(&msg)
+			if strings.HasSuffix(msg.Stream, "@aggTrade") {
+				var trade AggTrade
+				if err := json.Unmarshal(msg.Data, &trade); err != nil {
+					e.incrementErrorCount()
+					e.log().Error("Failed to decode trade", "event", "parse_failed", "error", err)
+					continue
+				}
+				exchange.SendTrade(e.GetName(), e.tradeChan, e.convertTrade(&trade))
+				continue
+			}
+
+			var update DepthUpdate
+			if err := json.Unmarshal(msg.Data, &update); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to decode depth update", "event", "parse_failed", "error", err)
+				continue
+			}
+			canonicalUpdate := e.convertDepthUpdate(&update)
 
 			select {
 			case e.updateChan <- canonicalUpdate:
@@ -197,7 +239,7 @@ func (e *FuturesExchange) readMessages() {
 			case <-e.done:
 				return
 			default:
-				log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
 			}
 		}
 	}
@@ -261,9 +303,25 @@ func (e *FuturesExchange) convertDepthUpdate(update *DepthUpdate) *exchange.Dept
 	}
 }
 
+// convertTrade converts an Asterdex aggregate trade to canonical format
+func (e *FuturesExchange) convertTrade(trade *AggTrade) *exchange.Trade {
+	return &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.Symbol,
+		TradeID:   strconv.FormatInt(trade.AggTradeID, 10),
+		Price:     trade.Price,
+		Quantity:  trade.Quantity,
+		Side:      exchange.TakerSide(trade.BuyerIsMaker),
+		Timestamp: time.UnixMilli(trade.TradeTime),
+	}
+}
+
 // updateConnectionStatus updates the connection status in health
 func (e *FuturesExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
@@ -1,5 +1,7 @@
 package asterdex
 
+import "encoding/json"
+
 // SnapshotResponse represents the REST API response for Asterdex order book snapshot
 type SnapshotResponse struct {
 	LastUpdateID int64      `json:"lastUpdateId"`
@@ -7,6 +9,12 @@ type SnapshotResponse struct {
 	Asks         [][]string `json:"asks"`
 }
 
+// WSMessage represents a combined stream message from Asterdex; Data is decoded by stream
+type WSMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
 // DepthUpdate represents a depth update event from Asterdex WebSocket
 type DepthUpdate struct {
 	EventType       string     `json:"e"`  // Event type
@@ -19,3 +27,14 @@ type DepthUpdate struct {
 	Bids            [][]string `json:"b"`  // Bids to be updated
 	Asks            [][]string `json:"a"`  // Asks to be updated
 }
+
+// AggTrade represents an aggregate trade event from Asterdex WebSocket
+type AggTrade struct {
+	EventType    string `json:"e"`
+	Symbol       string `json:"s"`
+	AggTradeID   int64  `json:"a"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	BuyerIsMaker bool   `json:"m"`
+}
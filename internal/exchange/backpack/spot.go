@@ -0,0 +1,374 @@
+package backpack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// SpotExchange implements the Exchange interface for Backpack Spot
+type SpotExchange struct {
+	symbol     string
+	market     string // Backpack format (e.g., BTC_USDC)
+	wsURL      string
+	restURL    string
+	wsConn     *websocket.Conn
+	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
+	done       chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	health     atomic.Value // stores exchange.HealthStatus
+}
+
+// NewSpotExchange creates a new Backpack Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	market := config.Symbol
+
+	ex := &SpotExchange{
+		symbol:     config.Symbol,
+		market:     market,
+		wsURL:      "wss://ws.backpack.exchange",
+		restURL:    fmt.Sprintf("https://api.backpack.exchange/api/v1/depth?symbol=%s", market),
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.Backpack
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Backpack Spot
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	stream := fmt.Sprintf("depth.%s", e.market)
+	tradeStream := fmt.Sprintf("trade.%s", e.market)
+	subscribeMsg := SubscribeRequest{
+		Method: "SUBSCRIBE",
+		Params: []string{stream, tradeStream},
+	}
+
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", stream, "trade_channel", tradeStream)
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the initial orderbook snapshot via REST API
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("snapshot request failed with status %d", resp.StatusCode)
+	}
+
+	var backpackSnapshot SnapshotResponse
+	if err := json.NewDecoder(resp.Body).Decode(&backpackSnapshot); err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	return e.convertSnapshot(&backpackSnapshot)
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Backpack feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.market,
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			if strings.HasPrefix(msg.Stream, "trade.") {
+				e.handleTrade(msg.Data)
+				continue
+			}
+
+			var update DepthUpdate
+			if len(msg.Data) == 0 || json.Unmarshal(msg.Data, &update) != nil || update.EventType != "depth" {
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			canonicalUpdate := e.convertDepthUpdate(&update)
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrade forwards a trade stream event
+func (e *SpotExchange) handleTrade(data json.RawMessage) {
+	e.incrementMessageCount()
+	e.updateLastPing()
+
+	var trade TradeEvent
+	if err := json.Unmarshal(data, &trade); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trade", "event", "parse_failed", "error", err)
+		return
+	}
+
+	exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.Symbol,
+		TradeID:   strconv.FormatInt(trade.TradeID, 10),
+		Price:     trade.Price,
+		Quantity:  trade.Quantity,
+		Side:      exchange.TakerSide(trade.BuyerIsMaker),
+		Timestamp: time.UnixMicro(trade.TradeTime),
+	})
+}
+
+// convertSnapshot converts Backpack snapshot to canonical format
+// lastUpdateId is sent as a string
+func (e *SpotExchange) convertSnapshot(snapshot *SnapshotResponse) (*exchange.Snapshot, error) {
+	lastUpdateID, err := strconv.ParseInt(snapshot.LastUpdateID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lastUpdateId %q: %w", snapshot.LastUpdateID, err)
+	}
+
+	bids := make([]exchange.PriceLevel, len(snapshot.Bids))
+	for i, bid := range snapshot.Bids {
+		bids[i] = exchange.PriceLevel{
+			Price:    bid[0],
+			Quantity: bid[1],
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, len(snapshot.Asks))
+	for i, ask := range snapshot.Asks {
+		asks[i] = exchange.PriceLevel{
+			Price:    ask[0],
+			Quantity: ask[1],
+		}
+	}
+
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.market,
+		LastUpdateID: lastUpdateID,
+		Bids:         bids,
+		Asks:         asks,
+		Timestamp:    time.UnixMicro(snapshot.Timestamp),
+	}, nil
+}
+
+// convertDepthUpdate converts Backpack depth update to canonical format
+// Backpack diffs are contiguous (U = previous u + 1) but carry no pu field
+func (e *SpotExchange) convertDepthUpdate(update *DepthUpdate) *exchange.DepthUpdate {
+	bids := make([]exchange.PriceLevel, len(update.Bids))
+	for i, bid := range update.Bids {
+		bids[i] = exchange.PriceLevel{
+			Price:    bid[0],
+			Quantity: bid[1],
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, len(update.Asks))
+	for i, ask := range update.Asks {
+		asks[i] = exchange.PriceLevel{
+			Price:    ask[0],
+			Quantity: ask[1],
+		}
+	}
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        update.Symbol,
+		EventTime:     time.UnixMicro(update.EventTime),
+		FirstUpdateID: update.FirstUpdateID,
+		FinalUpdateID: update.FinalUpdateID,
+		PrevUpdateID:  update.FirstUpdateID - 1,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,50 @@
+package backpack
+
+import "encoding/json"
+
+// Config holds configuration for Backpack exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC_USDC), as resolved by symbols.Native
+}
+
+// SubscribeRequest represents a Backpack WebSocket subscription request
+type SubscribeRequest struct {
+	Method string   `json:"method"` // "SUBSCRIBE"
+	Params []string `json:"params"`
+}
+
+// SnapshotResponse represents the REST API response for Backpack depth snapshot
+type SnapshotResponse struct {
+	LastUpdateID string     `json:"lastUpdateId"`
+	Timestamp    int64      `json:"timestamp"` // Microseconds
+	Bids         [][]string `json:"bids"`
+	Asks         [][]string `json:"asks"`
+}
+
+// WSMessage represents a WebSocket message from Backpack; Data is decoded by stream
+type WSMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// DepthUpdate represents a depth diff event from Backpack WebSocket
+type DepthUpdate struct {
+	EventType     string     `json:"e"`
+	EventTime     int64      `json:"E"` // Microseconds
+	Symbol        string     `json:"s"`
+	FirstUpdateID int64      `json:"U"`
+	FinalUpdateID int64      `json:"u"`
+	Bids          [][]string `json:"b"`
+	Asks          [][]string `json:"a"`
+}
+
+// TradeEvent represents a trade event from Backpack WebSocket
+type TradeEvent struct {
+	EventType    string `json:"e"`
+	Symbol       string `json:"s"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeID      int64  `json:"t"`
+	TradeTime    int64  `json:"T"` // Microseconds
+	BuyerIsMaker bool   `json:"m"`
+}
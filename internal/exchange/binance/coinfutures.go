@@ -0,0 +1,381 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/shopspring/decimal"
+)
+
+// CoinFuturesExchange implements the Exchange interface for Binance COIN-margined futures
+// Inverse contracts are quoted in USD contracts (100 USD for BTC, 10 USD for most others),
+// so sizes are converted to base units at each level's price to stay comparable across venues
+type CoinFuturesExchange struct {
+	symbol       string
+	market       string // Binance format (e.g., BTCUSD_PERP)
+	wsURL        string
+	restURL      string
+	wsConn       *websocket.Conn
+	updateChan   chan *exchange.DepthUpdate
+	tradeChan    chan *exchange.Trade
+	done         chan struct{}
+	ctx          context.Context
+	cancel       context.CancelFunc
+	health       atomic.Value // stores exchange.HealthStatus
+	contractSize decimal.Decimal
+	depth        int
+	speed        time.Duration
+}
+
+// NewCoinFuturesExchange creates a new Binance COIN-margined futures exchange instance
+func NewCoinFuturesExchange(config Config) *CoinFuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	market := config.Symbol
+	suffix, speed, depth := futuresMarket.stream(exchange.BinanceCoinf, config)
+	wsURL := fmt.Sprintf("wss://dstream.binance.com/stream?streams=%[1]s@depth%[2]s/%[1]s@aggTrade", strings.ToLower(market), suffix)
+	restURL := fmt.Sprintf("https://dapi.binance.com/dapi/v1/depth?symbol=%s&limit=%d", market, depth)
+
+	ex := &CoinFuturesExchange{
+		symbol:     config.Symbol,
+		market:     market,
+		wsURL:      wsURL,
+		restURL:    restURL,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+		depth:      depth,
+		speed:      speed,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *CoinFuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.BinanceCoinf
+}
+
+// GetSymbol returns the trading symbol
+func (e *CoinFuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *CoinFuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect loads the contract size and establishes WebSocket connection to Binance COIN-margined futures
+func (e *CoinFuturesExchange) Connect(ctx context.Context) error {
+	if err := e.loadContractSize(ctx); err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to load contract %s: %w", e.market, err)
+	}
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *CoinFuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the initial orderbook snapshot via REST API
+func (e *CoinFuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var binanceSnapshot SnapshotResponse
+	if err := json.NewDecoder(resp.Body).Decode(&binanceSnapshot); err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.market,
+		LastUpdateID: binanceSnapshot.LastUpdateID,
+		Bids:         e.convertLevels(binanceSnapshot.Bids),
+		Asks:         e.convertLevels(binanceSnapshot.Asks),
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (e *CoinFuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *CoinFuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *CoinFuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *CoinFuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Binance COIN-margined futures feed
+func (e *CoinFuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       e.depth,
+		UpdateInterval: e.speed,
+		Trades:         true,
+		Funding:        strings.HasSuffix(e.market, "_PERP"),
+		NativeSymbol:   e.market,
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *CoinFuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			if strings.HasSuffix(msg.Stream, "@aggTrade") {
+				var trade TradeEvent
+				if err := json.Unmarshal(msg.Data, &trade); err != nil {
+					e.incrementErrorCount()
+					e.log().Error("Failed to decode trade", "event", "parse_failed", "error", err)
+					continue
+				}
+				exchange.SendTrade(e.GetName(), e.tradeChan, e.convertTrade(&trade))
+				continue
+			}
+
+			var update DepthUpdate
+			if err := json.Unmarshal(msg.Data, &update); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to decode depth update", "event", "parse_failed", "error", err)
+				continue
+			}
+			canonicalUpdate := &exchange.DepthUpdate{
+				Exchange:      e.GetName(),
+				Symbol:        update.Symbol,
+				EventTime:     time.UnixMilli(update.EventTime),
+				FirstUpdateID: update.FirstUpdateID,
+				FinalUpdateID: update.FinalUpdateID,
+				PrevUpdateID:  update.PrevUpdateID,
+				Bids:          e.convertLevels(update.Bids),
+				Asks:          e.convertLevels(update.Asks),
+			}
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// loadContractSize fetches the USD value of one contract from exchange info
+func (e *CoinFuturesExchange) loadContractSize(ctx context.Context) error {
+	url := "https://dapi.binance.com/dapi/v1/exchangeInfo"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get exchange info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var info ExchangeInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return fmt.Errorf("failed to decode exchange info: %w", err)
+	}
+
+	for _, s := range info.Symbols {
+		if s.Symbol == e.market {
+			if s.ContractSize <= 0 {
+				return fmt.Errorf("invalid contract size %v", s.ContractSize)
+			}
+			e.contractSize = decimal.NewFromFloat(s.ContractSize)
+			e.log().Info("Loaded contract size", "event", "contract_size", "market", e.market, "contract_size_usd", e.contractSize.String())
+			return nil
+		}
+	}
+
+	return fmt.Errorf("contract not found")
+}
+
+// convertLevels converts Binance levels to canonical format
+// Contract counts become base units: contracts * contract size (USD) / price
+func (e *CoinFuturesExchange) convertLevels(levels [][]string) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+
+		quantity := level[1]
+		price, priceErr := decimal.NewFromString(level[0])
+		contracts, qtyErr := decimal.NewFromString(quantity)
+		if priceErr == nil && qtyErr == nil && price.IsPositive() && !e.contractSize.IsZero() {
+			quantity = contracts.Mul(e.contractSize).Div(price).String()
+		}
+
+		result = append(result, exchange.PriceLevel{
+			Price:    level[0],
+			Quantity: quantity,
+		})
+	}
+	return result
+}
+
+// convertTrade converts a Binance aggregate trade to canonical format, in base units like the levels
+func (e *CoinFuturesExchange) convertTrade(trade *TradeEvent) *exchange.Trade {
+	quantity := e.convertLevels([][]string{{trade.Price, trade.Quantity}})[0].Quantity
+	return &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.Symbol,
+		TradeID:   strconv.FormatInt(trade.AggTradeID, 10),
+		Price:     trade.Price,
+		Quantity:  quantity,
+		Side:      exchange.TakerSide(trade.BuyerIsMaker),
+		Timestamp: time.UnixMilli(trade.TradeTime),
+	}
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *CoinFuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *CoinFuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *CoinFuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *CoinFuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -4,32 +4,45 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
+// openInterestInterval is how often the open interest of futures is polled
+const openInterestInterval = 15 * time.Second
+
 // FuturesExchange implements the Exchange interface for Binance Futures
 type FuturesExchange struct {
-	symbol     string
-	wsURL      string
-	restURL    string
-	wsConn     *websocket.Conn
-	updateChan chan *exchange.DepthUpdate
-	done       chan struct{}
-	ctx        context.Context
-	cancel     context.CancelFunc
-	health     atomic.Value // stores exchange.HealthStatus
+	symbol      string
+	wsURL       string
+	restURL     string
+	oiURL       string
+	wsConn      *websocket.Conn
+	updateChan  chan *exchange.DepthUpdate
+	tradeChan   chan *exchange.Trade
+	fundingChan chan *exchange.Funding
+	funding     exchange.FundingState
+	done        chan struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+	health      atomic.Value // stores exchange.HealthStatus
+	depth       int
+	speed       time.Duration
 }
 
-// Config holds configuration for Binance Futures exchange
+// Config holds configuration for Binance exchanges
 type Config struct {
-	Symbol string
+	Symbol      string        // Native symbol (e.g. BTCUSDT, or BTCUSD_PERP on COIN-margined futures), as resolved by symbols.Native
+	Depth       int           // Levels of the snapshot the book starts from, 0 keeps the largest the market serves
+	UpdateSpeed time.Duration // Interval of the diff depth stream, 0 keeps 1s on spot and 250ms on futures
 }
 
 // NewFuturesExchange creates a new Binance Futures exchange instance
@@ -37,17 +50,24 @@ func NewFuturesExchange(config Config) *FuturesExchange {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	symbol := strings.ToLower(config.Symbol)
-	wsURL := fmt.Sprintf("wss://fstream.binance.com/stream?streams=%s@depth", symbol)
-	restURL := fmt.Sprintf("https://fapi.binance.com/fapi/v1/depth?symbol=%s&limit=1000", strings.ToUpper(config.Symbol))
+	suffix, speed, depth := futuresMarket.stream(exchange.Binancef, config)
+	wsURL := fmt.Sprintf("wss://fstream.binance.com/stream?streams=%[1]s@depth%[2]s/%[1]s@aggTrade/%[1]s@markPrice@1s", symbol, suffix)
+	restURL := fmt.Sprintf("https://fapi.binance.com/fapi/v1/depth?symbol=%s&limit=%d", strings.ToUpper(config.Symbol), depth)
+	oiURL := fmt.Sprintf("https://fapi.binance.com/fapi/v1/openInterest?symbol=%s", strings.ToUpper(config.Symbol))
 
 	ex := &FuturesExchange{
-		symbol:     config.Symbol,
-		wsURL:      wsURL,
-		restURL:    restURL,
-		updateChan: make(chan *exchange.DepthUpdate, 1000),
-		done:       make(chan struct{}),
-		ctx:        ctx,
-		cancel:     cancel,
+		symbol:      config.Symbol,
+		wsURL:       wsURL,
+		restURL:     restURL,
+		oiURL:       oiURL,
+		updateChan:  make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:   make(chan *exchange.Trade, 1000),
+		fundingChan: make(chan *exchange.Funding, 100),
+		done:        make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+		depth:       depth,
+		speed:       speed,
 	}
 
 	ex.health.Store(exchange.HealthStatus{
@@ -70,6 +90,11 @@ func (e *FuturesExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to Binance Futures
 func (e *FuturesExchange) Connect(ctx context.Context) error {
 	dialer := websocket.Dialer{
@@ -84,9 +109,10 @@ func (e *FuturesExchange) Connect(ctx context.Context) error {
 
 	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
+	e.log().Info("WebSocket connected", "event", "ws_connected")
 
 	go e.readMessages()
+	go e.pollOpenInterest()
 
 	return nil
 }
@@ -107,7 +133,7 @@ func (e *FuturesExchange) Close() error {
 		err := e.wsConn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
 		}
 
 		select {
@@ -122,7 +148,7 @@ func (e *FuturesExchange) Close() error {
 
 // GetSnapshot fetches the initial orderbook snapshot via REST API
 func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Fetching orderbook snapshot...", e.GetName())
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
 
 	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
 	if err != nil {
@@ -152,6 +178,16 @@ func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// Funding returns a channel that receives funding and open interest
+func (e *FuturesExchange) Funding() <-chan *exchange.Funding {
+	return e.fundingChan
+}
+
 // IsConnected checks if the WebSocket connection is active
 func (e *FuturesExchange) IsConnected() bool {
 	return e.wsConn != nil
@@ -165,30 +201,78 @@ func (e *FuturesExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the Binance Futures feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       e.depth,
+		UpdateInterval: e.speed,
+		Trades:         true,
+		Funding:        true,
+		NativeSymbol:   strings.ToUpper(e.symbol),
+	}
+}
+
 // readMessages continuously reads WebSocket messages
 func (e *FuturesExchange) readMessages() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer close(e.fundingChan)
 	defer e.updateConnectionStatus(false)
 
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
 			return
 		case <-e.done:
 			return
 		default:
 			var msg WSMessage
-			if err := e.wsConn.ReadJSON(&msg); err != nil {
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
 				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
 				return
 			}
 
 			e.incrementMessageCount()
 			e.updateLastPing()
 
-			canonicalUpdate := e.convertDepthUpdate(&msg.Data)
+			if strings.HasSuffix(msg.Stream, "@aggTrade") {
+				var trade TradeEvent
+				if err := json.Unmarshal(msg.Data, &trade); err != nil {
+					e.incrementErrorCount()
+					e.log().Error("Failed to decode trade", "event", "parse_failed", "error", err)
+					continue
+				}
+				exchange.SendTrade(e.GetName(), e.tradeChan, e.convertTrade(&trade))
+				continue
+			}
+			if strings.HasSuffix(msg.Stream, "@markPrice@1s") {
+				var mark MarkPriceEvent
+				if err := json.Unmarshal(msg.Data, &mark); err != nil {
+					e.incrementErrorCount()
+					e.log().Error("Failed to decode mark price", "event", "parse_failed", "error", err)
+					continue
+				}
+				exchange.SendFunding(e.GetName(), e.fundingChan, e.funding.Merge(exchange.Funding{
+					Exchange:    e.GetName(),
+					Symbol:      mark.Symbol,
+					Rate:        mark.FundingRate,
+					NextFunding: time.UnixMilli(mark.NextFundingTime),
+					MarkPrice:   mark.MarkPrice,
+					IndexPrice:  mark.IndexPrice,
+					Timestamp:   time.UnixMilli(mark.EventTime),
+				}))
+				continue
+			}
+
+			var update DepthUpdate
+			if err := json.Unmarshal(msg.Data, &update); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to decode depth update", "event", "parse_failed", "error", err)
+				continue
+			}
+			canonicalUpdate := e.convertDepthUpdate(&update)
 
 			select {
 			case e.updateChan <- canonicalUpdate:
@@ -197,12 +281,63 @@ func (e *FuturesExchange) readMessages() {
 			case <-e.done:
 				return
 			default:
-				log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
 			}
 		}
 	}
 }
 
+// pollOpenInterest fetches the open interest, which has no stream, until the connection
+// closes; it is merged into the funding sent with the next mark price
+func (e *FuturesExchange) pollOpenInterest() {
+	ticker := time.NewTicker(openInterestInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.fetchOpenInterest(); err != nil {
+			e.incrementErrorCount()
+			e.log().Error("Failed to fetch open interest", "event", "funding_failed", "error", err)
+		}
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-e.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchOpenInterest merges the current open interest into the funding state
+func (e *FuturesExchange) fetchOpenInterest() error {
+	req, err := http.NewRequestWithContext(e.ctx, "GET", e.oiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get open interest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("open interest request failed with status %d", resp.StatusCode)
+	}
+
+	var oi OpenInterestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&oi); err != nil {
+		return fmt.Errorf("failed to decode open interest: %w", err)
+	}
+	e.funding.Merge(exchange.Funding{
+		Exchange:     e.GetName(),
+		Symbol:       oi.Symbol,
+		OpenInterest: oi.OpenInterest,
+		Timestamp:    time.UnixMilli(oi.Time),
+	})
+	return nil
+}
+
 // convertSnapshot converts Binance snapshot to canonical format
 func (e *FuturesExchange) convertSnapshot(snapshot *SnapshotResponse) *exchange.Snapshot {
 	bids := make([]exchange.PriceLevel, len(snapshot.Bids))
@@ -261,9 +396,25 @@ func (e *FuturesExchange) convertDepthUpdate(update *DepthUpdate) *exchange.Dept
 	}
 }
 
+// convertTrade converts a Binance aggregate trade to canonical format
+func (e *FuturesExchange) convertTrade(trade *TradeEvent) *exchange.Trade {
+	return &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.Symbol,
+		TradeID:   strconv.FormatInt(trade.AggTradeID, 10),
+		Price:     trade.Price,
+		Quantity:  trade.Quantity,
+		Side:      exchange.TakerSide(trade.BuyerIsMaker),
+		Timestamp: time.UnixMilli(trade.TradeTime),
+	}
+}
+
 // updateConnectionStatus updates the connection status in health
 func (e *FuturesExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
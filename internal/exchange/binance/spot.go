@@ -4,45 +4,68 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
 // SpotExchange implements the Exchange interface for Binance Spot
+// Binance.US runs the same API on its own hosts and is served by the same type
 type SpotExchange struct {
+	name       exchange.ExchangeName
 	symbol     string
 	wsURL      string
 	restURL    string
 	wsConn     *websocket.Conn
 	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
 	done       chan struct{}
 	ctx        context.Context
 	cancel     context.CancelFunc
 	health     atomic.Value // stores exchange.HealthStatus
+	depth      int
+	speed      time.Duration
 }
 
 // NewSpotExchange creates a new Binance Spot exchange instance
 func NewSpotExchange(config Config) *SpotExchange {
+	return newSpotExchange(exchange.Binance, "stream.binance.com:9443", "api.binance.com", config)
+}
+
+// NewUSSpotExchange creates a new Binance.US Spot exchange instance
+// Binance.US lists its own pairs, including USD-quoted ones such as BTCUSD
+func NewUSSpotExchange(config Config) *SpotExchange {
+	return newSpotExchange(exchange.BinanceUS, "stream.binance.us:9443", "api.binance.us", config)
+}
+
+// newSpotExchange creates a spot exchange instance against the given stream and REST hosts
+func newSpotExchange(name exchange.ExchangeName, wsHost, restHost string, config Config) *SpotExchange {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	symbol := strings.ToLower(config.Symbol)
-	wsURL := fmt.Sprintf("wss://stream.binance.com:9443/stream?streams=%s@depth", symbol)
-	restURL := fmt.Sprintf("https://api.binance.com/api/v3/depth?symbol=%s&limit=5000", strings.ToUpper(config.Symbol))
+	suffix, speed, depth := spotMarket.stream(name, config)
+	wsURL := fmt.Sprintf("wss://%[1]s/stream?streams=%[2]s@depth%[3]s/%[2]s@trade", wsHost, symbol, suffix)
+	restURL := fmt.Sprintf("https://%s/api/v3/depth?symbol=%s&limit=%d", restHost, strings.ToUpper(config.Symbol), depth)
 
 	ex := &SpotExchange{
+		name:       name,
 		symbol:     config.Symbol,
 		wsURL:      wsURL,
 		restURL:    restURL,
 		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
 		done:       make(chan struct{}),
 		ctx:        ctx,
 		cancel:     cancel,
+		depth:      depth,
+		speed:      speed,
 	}
 
 	ex.health.Store(exchange.HealthStatus{
@@ -57,7 +80,7 @@ func NewSpotExchange(config Config) *SpotExchange {
 
 // GetName returns the exchange name
 func (e *SpotExchange) GetName() exchange.ExchangeName {
-	return exchange.Binance
+	return e.name
 }
 
 // GetSymbol returns the trading symbol
@@ -65,6 +88,11 @@ func (e *SpotExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to Binance Spot
 func (e *SpotExchange) Connect(ctx context.Context) error {
 	dialer := websocket.Dialer{
@@ -79,7 +107,7 @@ func (e *SpotExchange) Connect(ctx context.Context) error {
 
 	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
+	e.log().Info("WebSocket connected", "event", "ws_connected")
 
 	go e.readMessages()
 
@@ -102,7 +130,7 @@ func (e *SpotExchange) Close() error {
 		err := e.wsConn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
 		}
 
 		select {
@@ -117,7 +145,7 @@ func (e *SpotExchange) Close() error {
 
 // GetSnapshot fetches the initial orderbook snapshot via REST API
 func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Fetching orderbook snapshot...", e.GetName())
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
 
 	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
 	if err != nil {
@@ -147,6 +175,11 @@ func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
 // IsConnected checks if the WebSocket connection is active
 func (e *SpotExchange) IsConnected() bool {
 	return e.wsConn != nil
@@ -160,30 +193,58 @@ func (e *SpotExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the Binance Spot feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       e.depth,
+		UpdateInterval: e.speed,
+		Trades:         true,
+		NativeSymbol:   strings.ToUpper(e.symbol),
+	}
+}
+
 // readMessages continuously reads WebSocket messages
 func (e *SpotExchange) readMessages() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
 	defer e.updateConnectionStatus(false)
 
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
 			return
 		case <-e.done:
 			return
 		default:
 			var msg WSMessage
-			if err := e.wsConn.ReadJSON(&msg); err != nil {
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
 				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
 				return
 			}
 
 			e.incrementMessageCount()
 			e.updateLastPing()
 
-			canonicalUpdate := e.convertDepthUpdate(&msg.Data)
+			if strings.HasSuffix(msg.Stream, "@trade") {
+				var trade TradeEvent
+				if err := json.Unmarshal(msg.Data, &trade); err != nil {
+					e.incrementErrorCount()
+					e.log().Error("Failed to decode trade", "event", "parse_failed", "error", err)
+					continue
+				}
+				exchange.SendTrade(e.GetName(), e.tradeChan, e.convertTrade(&trade))
+				continue
+			}
+
+			var update DepthUpdate
+			if err := json.Unmarshal(msg.Data, &update); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to decode depth update", "event", "parse_failed", "error", err)
+				continue
+			}
+			canonicalUpdate := e.convertDepthUpdate(&update)
 
 			select {
 			case e.updateChan <- canonicalUpdate:
@@ -192,7 +253,7 @@ func (e *SpotExchange) readMessages() {
 			case <-e.done:
 				return
 			default:
-				log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
 			}
 		}
 	}
@@ -256,9 +317,25 @@ func (e *SpotExchange) convertDepthUpdate(update *DepthUpdate) *exchange.DepthUp
 	}
 }
 
+// convertTrade converts a Binance trade to canonical format
+func (e *SpotExchange) convertTrade(trade *TradeEvent) *exchange.Trade {
+	return &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.Symbol,
+		TradeID:   strconv.FormatInt(trade.TradeID, 10),
+		Price:     trade.Price,
+		Quantity:  trade.Quantity,
+		Side:      exchange.TakerSide(trade.BuyerIsMaker),
+		Timestamp: time.UnixMilli(trade.TradeTime),
+	}
+}
+
 // updateConnectionStatus updates the connection status in health
 func (e *SpotExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
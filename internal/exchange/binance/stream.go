@@ -0,0 +1,45 @@
+package binance
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// market lists the diff stream speeds and snapshot sizes a Binance market offers
+type market struct {
+	speeds []time.Duration // Intervals of the diff depth stream, the first is the stream without a suffix
+	depths []int           // Snapshot limits, the last is the default
+}
+
+var (
+	spotMarket    = market{speeds: []time.Duration{time.Second, 100 * time.Millisecond}, depths: []int{5, 10, 20, 50, 100, 500, 1000, 5000}}
+	futuresMarket = market{speeds: []time.Duration{250 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond}, depths: []int{5, 10, 20, 50, 100, 500, 1000}}
+)
+
+// stream returns the diff stream suffix, its interval and the snapshot limit selected by
+// config; a value the market does not offer is logged and replaced by the default
+func (m market) stream(name exchange.ExchangeName, config Config) (suffix string, speed time.Duration, depth int) {
+	speed, depth = m.speeds[0], m.depths[len(m.depths)-1]
+	if config.UpdateSpeed != 0 {
+		if slices.Contains(m.speeds, config.UpdateSpeed) {
+			speed = config.UpdateSpeed
+		} else {
+			logging.For(string(name), config.Symbol).Warn("Ignoring unsupported update speed", "event", "config_ignored", "update_speed", config.UpdateSpeed, "supported", m.speeds)
+		}
+	}
+	if config.Depth != 0 {
+		if slices.Contains(m.depths, config.Depth) {
+			depth = config.Depth
+		} else {
+			logging.For(string(name), config.Symbol).Warn("Ignoring unsupported depth", "event", "config_ignored", "depth", config.Depth, "supported", m.depths)
+		}
+	}
+	if speed != m.speeds[0] {
+		suffix = fmt.Sprintf("@%dms", speed.Milliseconds())
+	}
+	return suffix, speed, depth
+}
@@ -1,5 +1,7 @@
 package binance
 
+import "encoding/json"
+
 // SnapshotResponse represents the REST API response for Binance order book snapshot
 type SnapshotResponse struct {
 	LastUpdateID int64      `json:"lastUpdateId"`
@@ -7,10 +9,10 @@ type SnapshotResponse struct {
 	Asks         [][]string `json:"asks"`
 }
 
-// WSMessage represents a WebSocket message from Binance
+// WSMessage represents a WebSocket message from Binance; Data is decoded by stream
 type WSMessage struct {
-	Stream string      `json:"stream"`
-	Data   DepthUpdate `json:"data"`
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
 }
 
 // DepthUpdate represents a depth update event from Binance WebSocket
@@ -24,3 +26,43 @@ type DepthUpdate struct {
 	Bids          [][]string `json:"b"`
 	Asks          [][]string `json:"a"`
 }
+
+// TradeEvent represents a trade (spot) or aggregate trade (futures) from Binance WebSocket
+type TradeEvent struct {
+	EventType    string `json:"e"`
+	Symbol       string `json:"s"`
+	TradeID      int64  `json:"t"` // trade only
+	AggTradeID   int64  `json:"a"` // aggTrade only
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	BuyerIsMaker bool   `json:"m"`
+}
+
+// MarkPriceEvent represents a markPriceUpdate of a futures stream from Binance WebSocket
+type MarkPriceEvent struct {
+	EventTime       int64  `json:"E"`
+	Symbol          string `json:"s"`
+	MarkPrice       string `json:"p"`
+	IndexPrice      string `json:"i"`
+	FundingRate     string `json:"r"`
+	NextFundingTime int64  `json:"T"`
+}
+
+// OpenInterestResponse represents the openInterest REST response of USD-M futures
+type OpenInterestResponse struct {
+	Symbol       string `json:"symbol"`
+	OpenInterest string `json:"openInterest"` // Base units
+	Time         int64  `json:"time"`
+}
+
+// ExchangeInfoResponse represents the exchangeInfo REST response of COIN-margined futures
+type ExchangeInfoResponse struct {
+	Symbols []ContractInfo `json:"symbols"`
+}
+
+// ContractInfo describes one COIN-margined contract
+type ContractInfo struct {
+	Symbol       string  `json:"symbol"`       // e.g., BTCUSD_PERP
+	ContractSize float64 `json:"contractSize"` // USD value of one contract
+}
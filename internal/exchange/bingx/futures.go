@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -12,7 +12,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
 const (
@@ -25,6 +26,7 @@ type FuturesExchange struct {
 	bingxSymbol    string // BingX format (e.g., BTC-USDT)
 	wsConn         *websocket.Conn
 	updateChan     chan *exchange.DepthUpdate
+	tradeChan      chan *exchange.Trade
 	done           chan struct{}
 	ctx            context.Context
 	cancel         context.CancelFunc
@@ -39,12 +41,13 @@ type FuturesExchange struct {
 func NewFuturesExchange(config Config) *FuturesExchange {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	bingxSymbol := convertToBingXSymbol(config.Symbol)
+	bingxSymbol := config.Symbol
 
 	ex := &FuturesExchange{
 		symbol:        config.Symbol,
 		bingxSymbol:   bingxSymbol,
 		updateChan:    make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:     make(chan *exchange.Trade, 1000),
 		done:          make(chan struct{}),
 		ctx:           ctx,
 		cancel:        cancel,
@@ -72,6 +75,11 @@ func (e *FuturesExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to BingX Futures
 func (e *FuturesExchange) Connect(ctx context.Context) error {
 	dialer := websocket.Dialer{
@@ -91,7 +99,7 @@ func (e *FuturesExchange) Connect(ctx context.Context) error {
 
 	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
+	e.log().Info("WebSocket connected", "event", "ws_connected")
 
 	// Subscribe to incremental depth
 	subMsg := SubscriptionMessage{
@@ -105,7 +113,19 @@ func (e *FuturesExchange) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to subscribe: %w", err)
 	}
 
-	log.Printf("[%s] Subscribed to %s", e.GetName(), subMsg.DataType)
+	e.log().Info("Subscribed", "event", "subscribed", "channel", subMsg.DataType)
+
+	// Subscribe to trades
+	tradeMsg := SubscriptionMessage{
+		ID:       uuid.New().String(),
+		ReqType:  "sub",
+		DataType: fmt.Sprintf("%s@trade", e.bingxSymbol),
+	}
+
+	if err := conn.WriteJSON(tradeMsg); err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
 
 	go e.readMessages()
 	go e.pingLoop()
@@ -129,7 +149,7 @@ func (e *FuturesExchange) Close() error {
 		err := e.wsConn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
 		}
 
 		select {
@@ -144,7 +164,7 @@ func (e *FuturesExchange) Close() error {
 
 // GetSnapshot waits for and returns the initial orderbook snapshot from WebSocket
 func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Waiting for initial snapshot from WebSocket...", e.GetName())
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
 
 	select {
 	case <-e.snapshotReady:
@@ -164,6 +184,11 @@ func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
 // IsConnected checks if the WebSocket connection is active
 func (e *FuturesExchange) IsConnected() bool {
 	return e.wsConn != nil
@@ -177,6 +202,14 @@ func (e *FuturesExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the BingX Futures feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.bingxSymbol,
+	}
+}
+
 // pingLoop sends periodic pings (not needed for BingX, they send pings to us)
 // But we keep the goroutine structure for consistency
 func (e *FuturesExchange) pingLoop() {
@@ -200,25 +233,26 @@ func (e *FuturesExchange) pingLoop() {
 // readMessages continuously reads WebSocket messages
 func (e *FuturesExchange) readMessages() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
 	defer e.updateConnectionStatus(false)
 
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
 			return
 		case <-e.done:
 			return
 		default:
-			messageType, message, err := e.wsConn.ReadMessage()
+			messageType, message, err := exchange.ReadMessage(e.wsConn, e.GetName())
 			if err != nil {
 				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
 				return
 			}
 
 			if err := e.handleMessage(messageType, message); err != nil {
-				log.Printf("[%s] Error handling message: %v", e.GetName(), err)
+				e.log().Error("Failed to handle message", "event", "message_failed", "error", err)
 			}
 		}
 	}
@@ -247,11 +281,19 @@ func (e *FuturesExchange) handleMessage(messageType int, message []byte) error {
 	if strings.Contains(lowerMsg, "ping") || lowerMsg == "ping" {
 		// Respond with "Pong" (capitalized as per BingX futures docs)
 		if err := e.wsConn.WriteMessage(websocket.TextMessage, []byte("Pong")); err != nil {
-			log.Printf("[%s] Failed to send Pong: %v", e.GetName(), err)
+			e.log().Error("Failed to send pong", "event", "heartbeat_failed", "error", err)
 		}
 		return nil
 	}
 
+	// Trades carry a different payload than depth data
+	var trades TradeWSMessage
+	if err := json.Unmarshal([]byte(decodedMsg), &trades); err == nil && strings.HasSuffix(trades.DataType, "@trade") {
+		e.incrementMessageCount()
+		e.updateLastPing()
+		return e.handleTrades(trades.Data)
+	}
+
 	// Parse JSON message
 	var msg FuturesWSMessage
 	if err := json.Unmarshal([]byte(decodedMsg), &msg); err != nil {
@@ -279,6 +321,27 @@ func (e *FuturesExchange) handleMessage(messageType int, message []byte) error {
 	return nil
 }
 
+// handleTrades forwards a batch of trades
+func (e *FuturesExchange) handleTrades(data json.RawMessage) error {
+	var trades []FuturesTrade
+	if err := json.Unmarshal(data, &trades); err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to parse trades: %w", err)
+	}
+
+	for _, trade := range trades {
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.Symbol,
+			Price:     trade.Price,
+			Quantity:  trade.Quantity,
+			Side:      exchange.TakerSide(trade.BuyerIsMaker),
+			Timestamp: time.UnixMilli(trade.TradeTime),
+		})
+	}
+	return nil
+}
+
 // handleSnapshot processes the initial full depth snapshot
 func (e *FuturesExchange) handleSnapshot(msg *FuturesWSMessage) {
 	e.snapshotMutex.Lock()
@@ -293,8 +356,7 @@ func (e *FuturesExchange) handleSnapshot(msg *FuturesWSMessage) {
 	e.snapshot = snapshot
 	e.hasSnapshot = true
 
-	log.Printf("[%s] Received initial snapshot with lastUpdateId=%d, bids=%d, asks=%d",
-		e.GetName(), snapshot.LastUpdateID, len(snapshot.Bids), len(snapshot.Asks))
+	e.log().Info("Received orderbook snapshot", "event", "snapshot", "last_update_id", snapshot.LastUpdateID, "bids", len(snapshot.Bids), "asks", len(snapshot.Asks))
 
 	// Signal that snapshot is ready
 	select {
@@ -315,7 +377,7 @@ func (e *FuturesExchange) handleUpdate(msg *FuturesWSMessage) {
 	case <-e.done:
 		return
 	default:
-		log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+		e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
 	}
 }
 
@@ -388,6 +450,9 @@ func (e *FuturesExchange) convertDepthUpdate(data *FuturesDepthData) *exchange.D
 // updateConnectionStatus updates the connection status in health
 func (e *FuturesExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
@@ -7,7 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,7 +15,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
 const (
@@ -28,6 +29,7 @@ type SpotExchange struct {
 	bingxSymbol    string // BingX format (e.g., BTC-USDT)
 	wsConn         *websocket.Conn
 	updateChan     chan *exchange.DepthUpdate
+	tradeChan      chan *exchange.Trade
 	done           chan struct{}
 	ctx            context.Context
 	cancel         context.CancelFunc
@@ -42,12 +44,13 @@ type SpotExchange struct {
 func NewSpotExchange(config Config) *SpotExchange {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	bingxSymbol := convertToBingXSymbol(config.Symbol)
+	bingxSymbol := config.Symbol
 
 	ex := &SpotExchange{
 		symbol:        config.Symbol,
 		bingxSymbol:   bingxSymbol,
 		updateChan:    make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:     make(chan *exchange.Trade, 1000),
 		done:          make(chan struct{}),
 		ctx:           ctx,
 		cancel:        cancel,
@@ -75,6 +78,11 @@ func (e *SpotExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to BingX Spot
 func (e *SpotExchange) Connect(ctx context.Context) error {
 	dialer := websocket.Dialer{
@@ -94,7 +102,7 @@ func (e *SpotExchange) Connect(ctx context.Context) error {
 
 	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
+	e.log().Info("WebSocket connected", "event", "ws_connected")
 
 	// Subscribe to incremental depth
 	subMsg := SubscriptionMessage{
@@ -108,7 +116,19 @@ func (e *SpotExchange) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to subscribe: %w", err)
 	}
 
-	log.Printf("[%s] Subscribed to %s", e.GetName(), subMsg.DataType)
+	e.log().Info("Subscribed", "event", "subscribed", "channel", subMsg.DataType)
+
+	// Subscribe to trades
+	tradeMsg := SubscriptionMessage{
+		ID:       uuid.New().String(),
+		ReqType:  "sub",
+		DataType: fmt.Sprintf("%s@trade", e.bingxSymbol),
+	}
+
+	if err := conn.WriteJSON(tradeMsg); err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
 
 	go e.readMessages()
 	go e.pingLoop()
@@ -132,7 +152,7 @@ func (e *SpotExchange) Close() error {
 		err := e.wsConn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
 		}
 
 		select {
@@ -147,7 +167,7 @@ func (e *SpotExchange) Close() error {
 
 // GetSnapshot waits for and returns the initial orderbook snapshot from WebSocket
 func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Waiting for initial snapshot from WebSocket...", e.GetName())
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
 
 	select {
 	case <-e.snapshotReady:
@@ -167,6 +187,11 @@ func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
 // IsConnected checks if the WebSocket connection is active
 func (e *SpotExchange) IsConnected() bool {
 	return e.wsConn != nil
@@ -180,6 +205,14 @@ func (e *SpotExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the BingX Spot feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.bingxSymbol,
+	}
+}
+
 // pingLoop sends periodic pings (not needed for BingX, they send pings to us)
 // But we keep the goroutine structure for consistency
 func (e *SpotExchange) pingLoop() {
@@ -203,25 +236,26 @@ func (e *SpotExchange) pingLoop() {
 // readMessages continuously reads WebSocket messages
 func (e *SpotExchange) readMessages() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
 	defer e.updateConnectionStatus(false)
 
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
 			return
 		case <-e.done:
 			return
 		default:
-			messageType, message, err := e.wsConn.ReadMessage()
+			messageType, message, err := exchange.ReadMessage(e.wsConn, e.GetName())
 			if err != nil {
 				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
 				return
 			}
 
 			if err := e.handleMessage(messageType, message); err != nil {
-				log.Printf("[%s] Error handling message: %v", e.GetName(), err)
+				e.log().Error("Failed to handle message", "event", "message_failed", "error", err)
 			}
 		}
 	}
@@ -248,11 +282,19 @@ func (e *SpotExchange) handleMessage(messageType int, message []byte) error {
 	// Handle ping/pong
 	if strings.Contains(decodedMsg, "ping") || decodedMsg == "ping" {
 		if err := e.wsConn.WriteMessage(websocket.TextMessage, []byte("pong")); err != nil {
-			log.Printf("[%s] Failed to send pong: %v", e.GetName(), err)
+			e.log().Error("Failed to send pong", "event", "heartbeat_failed", "error", err)
 		}
 		return nil
 	}
 
+	// Trades carry a different payload than depth data
+	var trades TradeWSMessage
+	if err := json.Unmarshal([]byte(decodedMsg), &trades); err == nil && strings.HasSuffix(trades.DataType, "@trade") {
+		e.incrementMessageCount()
+		e.updateLastPing()
+		return e.handleTrades(trades.Data)
+	}
+
 	// Parse JSON message
 	var msg WSMessage
 	if err := json.Unmarshal([]byte(decodedMsg), &msg); err != nil {
@@ -280,6 +322,26 @@ func (e *SpotExchange) handleMessage(messageType int, message []byte) error {
 	return nil
 }
 
+// handleTrades forwards a trade
+func (e *SpotExchange) handleTrades(data json.RawMessage) error {
+	var trade SpotTrade
+	if err := json.Unmarshal(data, &trade); err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to parse trade: %w", err)
+	}
+
+	exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.Symbol,
+		TradeID:   trade.TradeID,
+		Price:     trade.Price,
+		Quantity:  trade.Quantity,
+		Side:      exchange.TakerSide(trade.BuyerIsMaker),
+		Timestamp: time.UnixMilli(trade.TradeTime),
+	})
+	return nil
+}
+
 // handleSnapshot processes the initial full depth snapshot
 func (e *SpotExchange) handleSnapshot(msg *WSMessage) {
 	e.snapshotMutex.Lock()
@@ -294,8 +356,7 @@ func (e *SpotExchange) handleSnapshot(msg *WSMessage) {
 	e.snapshot = snapshot
 	e.hasSnapshot = true
 
-	log.Printf("[%s] Received initial snapshot with lastUpdateId=%d, bids=%d, asks=%d",
-		e.GetName(), snapshot.LastUpdateID, len(snapshot.Bids), len(snapshot.Asks))
+	e.log().Info("Received orderbook snapshot", "event", "snapshot", "last_update_id", snapshot.LastUpdateID, "bids", len(snapshot.Bids), "asks", len(snapshot.Asks))
 
 	// Signal that snapshot is ready
 	select {
@@ -316,7 +377,7 @@ func (e *SpotExchange) handleUpdate(msg *WSMessage) {
 	case <-e.done:
 		return
 	default:
-		log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+		e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
 	}
 }
 
@@ -394,37 +455,12 @@ func decodeGzip(data []byte) (string, error) {
 	return string(decodedMsg), nil
 }
 
-// convertToBingXSymbol converts various symbol formats to BingX format
-// Examples: BTCUSDT -> BTC-USDT, BTC-USDT -> BTC-USDT
-func convertToBingXSymbol(symbol string) string {
-	if strings.Contains(symbol, "-") {
-		return strings.ToUpper(symbol)
-	}
-
-	symbol = strings.ToUpper(symbol)
-
-	if strings.HasSuffix(symbol, "USDT") {
-		base := strings.TrimSuffix(symbol, "USDT")
-		return fmt.Sprintf("%s-USDT", base)
-	}
-
-	if strings.HasSuffix(symbol, "USD") && !strings.HasSuffix(symbol, "USDT") {
-		base := strings.TrimSuffix(symbol, "USD")
-		return fmt.Sprintf("%s-USD", base)
-	}
-
-	if strings.HasSuffix(symbol, "USDC") {
-		base := strings.TrimSuffix(symbol, "USDC")
-		return fmt.Sprintf("%s-USDC", base)
-	}
-
-	log.Printf("[BingX] Warning: Could not convert symbol %s to BingX format, using as-is", symbol)
-	return symbol
-}
-
 // updateConnectionStatus updates the connection status in health
 func (e *SpotExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
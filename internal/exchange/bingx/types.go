@@ -1,8 +1,10 @@
 package bingx
 
+import "encoding/json"
+
 // Config holds configuration for BingX exchange
 type Config struct {
-	Symbol string
+	Symbol string // Native symbol (e.g. BTC-USDT), as resolved by symbols.Native
 }
 
 // SubscriptionMessage represents the subscription request to BingX WebSocket
@@ -47,3 +49,29 @@ type FuturesWSMessage struct {
 	Data       FuturesDepthData `json:"data,omitempty"`
 	Timestamp  int64            `json:"ts,omitempty"`
 }
+
+// TradeWSMessage represents a trade message from BingX; Data is a SpotTrade (Spot)
+// or a list of FuturesTrade (Futures)
+type TradeWSMessage struct {
+	DataType string          `json:"dataType"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// SpotTrade represents a trade from BingX Spot
+type SpotTrade struct {
+	Symbol       string `json:"s"`
+	TradeID      string `json:"t"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	BuyerIsMaker bool   `json:"m"`
+}
+
+// FuturesTrade represents a trade from BingX Futures, which carries no trade ID
+type FuturesTrade struct {
+	Symbol       string `json:"s"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	BuyerIsMaker bool   `json:"m"`
+}
@@ -0,0 +1,148 @@
+package bitget
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+// checksumDepth is the number of levels per side covered by Bitget checksums
+const checksumDepth = 25
+
+// localBook keeps the raw price/size strings needed to verify Bitget checksums
+type localBook struct {
+	bids map[string]string
+	asks map[string]string
+}
+
+// newLocalBook creates an empty local book
+func newLocalBook() *localBook {
+	return &localBook{
+		bids: make(map[string]string),
+		asks: make(map[string]string),
+	}
+}
+
+// load replaces the book content with a snapshot
+func (b *localBook) load(bids, asks [][]string) {
+	b.bids = make(map[string]string, len(bids))
+	b.asks = make(map[string]string, len(asks))
+	b.apply(bids, asks)
+}
+
+// apply applies incremental changes, removing levels with zero size
+func (b *localBook) apply(bids, asks [][]string) {
+	applySide(b.bids, bids)
+	applySide(b.asks, asks)
+}
+
+// diff returns the changes needed to turn the current book into the given snapshot
+func (b *localBook) diff(bids, asks [][]string) ([]exchange.PriceLevel, []exchange.PriceLevel) {
+	return diffSide(b.bids, bids), diffSide(b.asks, asks)
+}
+
+// checksum computes the CRC32 of the top levels interleaved as
+// bid1Price:bid1Size:ask1Price:ask1Size:...
+func (b *localBook) checksum() int64 {
+	bids := topPrices(b.bids, true)
+	asks := topPrices(b.asks, false)
+
+	parts := make([]string, 0, 4*checksumDepth)
+	for i := 0; i < checksumDepth; i++ {
+		if i < len(bids) {
+			parts = append(parts, bids[i], b.bids[bids[i]])
+		}
+		if i < len(asks) {
+			parts = append(parts, asks[i], b.asks[asks[i]])
+		}
+	}
+
+	return int64(int32(crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))))
+}
+
+// applySide applies [price, size] changes to one side of the book
+func applySide(side map[string]string, levels [][]string) {
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		if size, err := strconv.ParseFloat(level[1], 64); err == nil && size == 0 {
+			delete(side, level[0])
+			continue
+		}
+		side[level[0]] = level[1]
+	}
+}
+
+// diffSide returns the snapshot levels plus zero-size entries for levels no longer present
+func diffSide(current map[string]string, snapshot [][]string) []exchange.PriceLevel {
+	seen := make(map[string]struct{}, len(snapshot))
+	changes := make([]exchange.PriceLevel, 0, len(snapshot))
+
+	for _, level := range snapshot {
+		if len(level) < 2 {
+			continue
+		}
+		seen[level[0]] = struct{}{}
+		changes = append(changes, exchange.PriceLevel{Price: level[0], Quantity: level[1]})
+	}
+
+	for price := range current {
+		if _, ok := seen[price]; !ok {
+			changes = append(changes, exchange.PriceLevel{Price: price, Quantity: "0"})
+		}
+	}
+
+	return changes
+}
+
+// topPrices returns the best checksumDepth prices of a side in book order
+func topPrices(side map[string]string, descending bool) []string {
+	type entry struct {
+		raw   string
+		price float64
+	}
+
+	entries := make([]entry, 0, len(side))
+	for raw := range side {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{raw: raw, price: price})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if descending {
+			return entries[i].price > entries[j].price
+		}
+		return entries[i].price < entries[j].price
+	})
+
+	if len(entries) > checksumDepth {
+		entries = entries[:checksumDepth]
+	}
+
+	prices := make([]string, len(entries))
+	for i, e := range entries {
+		prices[i] = e.raw
+	}
+	return prices
+}
+
+// convertLevels converts raw [price, size] pairs to canonical price levels
+func convertLevels(levels [][]string) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) >= 2 {
+			result = append(result, exchange.PriceLevel{
+				Price:    level[0],
+				Quantity: level[1],
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,431 @@
+package bitget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+// FuturesExchange implements the Exchange interface for Bitget USDT-M Futures
+type FuturesExchange struct {
+	symbol           string
+	instID           string // Bitget format (e.g., BTCUSDT)
+	wsConn           *websocket.Conn
+	writeMu          sync.Mutex
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	snapshotReceived bool
+	resyncing        bool
+	lastSeq          int64
+	book             *localBook
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+}
+
+// NewFuturesExchange creates a new Bitget USDT-M Futures exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		instID:     config.Symbol,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+		book:       newLocalBook(),
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.Bitgetf
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Bitget USDT-M Futures
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	if err := e.sendSubscription("subscribe", "books"); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	if err := e.sendSubscription("subscribe", "trade"); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "books", "trade_channel", "trade")
+
+	go e.readMessages()
+	go e.pingLoop()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the initial orderbook snapshot via WebSocket
+// Bitget pushes a full snapshot as the first message after subscribing
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			snap := e.snapshot
+			e.snapshotMu.Unlock()
+
+			if snap != nil {
+				return snap, nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Bitget Futures feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		UpdateInterval: 200 * time.Millisecond,
+		Checksum:       true,
+		Trades:         true,
+		NativeSymbol:   e.instID,
+	}
+}
+
+// sendSubscription sends a subscribe or unsubscribe request for a channel
+func (e *FuturesExchange) sendSubscription(op, channel string) error {
+	msg := SubscribeRequest{
+		Op: op,
+		Args: []SubscribeArg{{
+			InstType: "USDT-FUTURES",
+			Channel:  channel,
+			InstID:   e.instID,
+		}},
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(msg)
+}
+
+// pingLoop sends the text "ping" required by Bitget to keep the connection alive
+func (e *FuturesExchange) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.writeMu.Lock()
+			err := e.wsConn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			e.writeMu.Unlock()
+			if err != nil {
+				e.log().Error("Failed to send ping", "event", "heartbeat_failed", "error", err)
+			}
+		}
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			_, message, err := exchange.ReadMessage(e.wsConn, e.GetName())
+			if err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			if string(message) == "pong" {
+				continue
+			}
+
+			var msg WSMessage
+			if err := json.Unmarshal(message, &msg); err != nil {
+				e.log().Error("Failed to parse message", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			if msg.Event == "error" {
+				e.incrementErrorCount()
+				e.log().Error("Subscription error", "event", "subscribe_failed", "code", msg.Code, "error", msg.Msg)
+				continue
+			}
+
+			if msg.Arg.Channel == "trade" {
+				e.incrementMessageCount()
+				e.updateLastPing()
+				if msg.Action == "update" {
+					if err := sendTrades(e.GetName(), e.instID, e.tradeChan, message); err != nil {
+						e.incrementErrorCount()
+						e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+					}
+				}
+				continue
+			}
+
+			if msg.Arg.Channel != "books" || len(msg.Data) == 0 {
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			update := e.handleBook(msg.Action, &msg.Data[0])
+			if update == nil {
+				continue
+			}
+
+			select {
+			case e.updateChan <- update:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", update.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleBook applies a books message to the local book, verifies the checksum and
+// returns the canonical update to forward (nil when nothing should be forwarded)
+func (e *FuturesExchange) handleBook(action string, data *BookData) *exchange.DepthUpdate {
+	if action == "snapshot" {
+		if !e.snapshotReceived {
+			e.book.load(data.Bids, data.Asks)
+			e.storeSnapshot(data)
+			e.snapshotReceived = true
+			return nil
+		}
+
+		// Snapshot after a resubscribe: emit the difference so the downstream
+		// book converges without a full reinitialization
+		bids, asks := e.book.diff(data.Bids, data.Asks)
+		e.book.load(data.Bids, data.Asks)
+		e.resyncing = false
+		e.log().Info("Resynchronized from new snapshot", "event", "resync", "seq", data.Seq)
+		return e.newDepthUpdate(data, bids, asks)
+	}
+
+	if e.resyncing || !e.snapshotReceived {
+		return nil
+	}
+
+	e.book.apply(data.Bids, data.Asks)
+
+	if data.Checksum != 0 && e.book.checksum() != data.Checksum {
+		e.incrementErrorCount()
+		e.log().Warn("Checksum mismatch, resubscribing", "event", "checksum_mismatch", "seq", data.Seq)
+		e.resync()
+		return nil
+	}
+
+	return e.newDepthUpdate(data, convertLevels(data.Bids), convertLevels(data.Asks))
+}
+
+// resync resubscribes to the books channel to obtain a fresh snapshot
+func (e *FuturesExchange) resync() {
+	e.resyncing = true
+	if err := e.sendSubscription("unsubscribe", "books"); err != nil {
+		e.log().Error("Failed to unsubscribe", "event", "unsubscribe_failed", "error", err)
+	}
+	if err := e.sendSubscription("subscribe", "books"); err != nil {
+		e.log().Error("Failed to resubscribe", "event", "resubscribe_failed", "error", err)
+	}
+}
+
+// storeSnapshot converts and stores the initial snapshot
+func (e *FuturesExchange) storeSnapshot(data *BookData) {
+	snapshot := &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.instID,
+		LastUpdateID: data.Seq,
+		Bids:         convertLevels(data.Bids),
+		Asks:         convertLevels(data.Asks),
+		Timestamp:    parseTimestamp(data.Ts),
+	}
+
+	e.snapshotMu.Lock()
+	e.snapshot = snapshot
+	e.lastSeq = data.Seq
+	e.snapshotMu.Unlock()
+}
+
+// newDepthUpdate builds a canonical update chained to the previously forwarded seq
+func (e *FuturesExchange) newDepthUpdate(data *BookData, bids, asks []exchange.PriceLevel) *exchange.DepthUpdate {
+	prevSeq := e.lastSeq
+	e.lastSeq = data.Seq
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        e.instID,
+		EventTime:     parseTimestamp(data.Ts),
+		FirstUpdateID: data.Seq,
+		FinalUpdateID: data.Seq,
+		PrevUpdateID:  prevSeq,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,54 @@
+package bitget
+
+// Config holds configuration for Bitget exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTCUSDT), as resolved by symbols.Native
+}
+
+// SubscribeArg identifies a single channel subscription
+type SubscribeArg struct {
+	InstType string `json:"instType"` // "SPOT" or "USDT-FUTURES"
+	Channel  string `json:"channel"`
+	InstID   string `json:"instId"`
+}
+
+// SubscribeRequest represents a subscribe/unsubscribe request to Bitget WebSocket v2
+type SubscribeRequest struct {
+	Op   string         `json:"op"`
+	Args []SubscribeArg `json:"args"`
+}
+
+// WSMessage represents a WebSocket message from Bitget
+type WSMessage struct {
+	Event  string       `json:"event,omitempty"`  // "subscribe", "unsubscribe" or "error"
+	Code   string       `json:"code,omitempty"`   // Only present on errors
+	Msg    string       `json:"msg,omitempty"`    // Only present on errors
+	Action string       `json:"action,omitempty"` // "snapshot" or "update"
+	Arg    SubscribeArg `json:"arg"`
+	Data   []BookData   `json:"data"`
+	Ts     int64        `json:"ts"`
+}
+
+// BookData represents the orderbook data in a books channel message
+type BookData struct {
+	Asks     [][]string `json:"asks"` // [price, size]
+	Bids     [][]string `json:"bids"` // [price, size]
+	Checksum int64      `json:"checksum"`
+	Seq      int64      `json:"seq"`
+	Ts       string     `json:"ts"`
+}
+
+// TradesMessage represents a trade channel message from Bitget
+type TradesMessage struct {
+	Arg  SubscribeArg `json:"arg"`
+	Data []TradeData  `json:"data"`
+}
+
+// TradeData represents a single trade
+type TradeData struct {
+	Ts      string `json:"ts"`
+	Price   string `json:"price"`
+	Size    string `json:"size"`
+	Side    string `json:"side"` // Taker side: "buy" or "sell"
+	TradeID string `json:"tradeId"`
+}
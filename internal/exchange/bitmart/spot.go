@@ -0,0 +1,398 @@
+package bitmart
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsURL        = "wss://ws-manager-compress.bitmart.com/api?protocol=1.1"
+	depthChannel = "spot/depth/increase100"
+	tradeChannel = "spot/trade"
+	pingInterval = 15 * time.Second // BitMart drops connections idle for 20 seconds
+)
+
+// SpotExchange implements the Exchange interface for BitMart Spot
+// The increase100 channel opens with a 100-level snapshot and numbers every
+// update with a version one above the previous one
+type SpotExchange struct {
+	symbol           string
+	market           string // BitMart format (e.g., BTC_USDT)
+	wsConn           *websocket.Conn
+	writeMu          sync.Mutex
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+	snapshotReceived bool
+}
+
+// NewSpotExchange creates a new BitMart Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &SpotExchange{
+		symbol:     config.Symbol,
+		market:     config.Symbol,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.BitMart
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to BitMart
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout:  10 * time.Second,
+		EnableCompression: true,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	topic := fmt.Sprintf("%s:%s", depthChannel, e.market)
+	tradeTopic := fmt.Sprintf("%s:%s", tradeChannel, e.market)
+	e.writeMu.Lock()
+	err = conn.WriteJSON(WSRequest{Op: "subscribe", Args: []string{topic, tradeTopic}})
+	e.writeMu.Unlock()
+	if err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", topic, "trade_channel", tradeTopic)
+
+	go e.pingLoop()
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the orderbook snapshot received from WebSocket
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the BitMart Spot feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:     100,
+		Trades:       true,
+		NativeSymbol: e.market,
+	}
+}
+
+// pingLoop sends the text "ping" BitMart expects when the book is quiet
+func (e *SpotExchange) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.writeMu.Lock()
+			err := e.wsConn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			e.writeMu.Unlock()
+			if err != nil {
+				e.log().Error("Failed to send ping", "event", "heartbeat_failed", "error", err)
+			}
+		}
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			_, message, err := exchange.ReadMessage(e.wsConn, e.GetName())
+			if err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.updateLastPing()
+
+			if string(message) == "pong" {
+				continue
+			}
+
+			var msg WSMessage
+			if err := json.Unmarshal(message, &msg); err != nil {
+				e.log().Error("Failed to parse message", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			if msg.ErrorCode != "" {
+				e.incrementErrorCount()
+				e.log().Error("Request error", "event", "request_failed", "code", msg.ErrorCode, "error", msg.ErrorMsg)
+				continue
+			}
+
+			if msg.Table == tradeChannel {
+				e.handleTrades(message)
+				continue
+			}
+
+			if msg.Table != depthChannel || len(msg.Data) == 0 {
+				continue
+			}
+
+			e.incrementMessageCount()
+
+			for i := range msg.Data {
+				event := &msg.Data[i]
+				bids := convertLevels(event.Bids)
+				asks := convertLevels(event.Asks)
+
+				if event.Type == "snapshot" {
+					e.snapshotMu.Lock()
+					e.snapshot = &exchange.Snapshot{
+						Exchange:     e.GetName(),
+						Symbol:       event.Symbol,
+						LastUpdateID: event.Version,
+						Bids:         bids,
+						Asks:         asks,
+						Timestamp:    time.UnixMilli(event.MsT),
+					}
+					e.snapshotReceived = true
+					e.snapshotMu.Unlock()
+
+					e.log().Info("Received orderbook snapshot", "event", "snapshot", "bids", len(bids), "asks", len(asks))
+					continue
+				}
+
+				canonicalUpdate := &exchange.DepthUpdate{
+					Exchange:      e.GetName(),
+					Symbol:        event.Symbol,
+					EventTime:     time.UnixMilli(event.MsT),
+					FirstUpdateID: event.Version,
+					FinalUpdateID: event.Version,
+					PrevUpdateID:  event.Version - 1,
+					Bids:          bids,
+					Asks:          asks,
+				}
+
+				select {
+				case e.updateChan <- canonicalUpdate:
+				case <-e.ctx.Done():
+					return
+				case <-e.done:
+					return
+				default:
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+				}
+			}
+		}
+	}
+}
+
+// handleTrades forwards a spot/trade message
+func (e *SpotExchange) handleTrades(message []byte) {
+	e.incrementMessageCount()
+
+	var msg TradesMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+		return
+	}
+
+	for _, trade := range msg.Data {
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.Symbol,
+			Price:     trade.Price,
+			Quantity:  trade.Size,
+			Side:      exchange.TradeSide(trade.Side),
+			Timestamp: time.UnixMilli(trade.MsT),
+		})
+	}
+}
+
+// convertLevels converts BitMart levels to canonical format
+func convertLevels(levels [][]string) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		result = append(result, exchange.PriceLevel{
+			Price:    level[0],
+			Quantity: level[1],
+		})
+	}
+	return result
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,47 @@
+package bitmart
+
+// Config holds configuration for BitMart exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC_USDT), as resolved by symbols.Native
+}
+
+// WSRequest represents a BitMart WebSocket request
+type WSRequest struct {
+	Op   string   `json:"op"` // "subscribe"
+	Args []string `json:"args"`
+}
+
+// WSMessage represents a WebSocket message from BitMart
+type WSMessage struct {
+	Table     string       `json:"table"` // e.g., spot/depth/increase100
+	Data      []DepthEvent `json:"data"`
+	Event     string       `json:"event"`
+	ErrorCode string       `json:"errorCode"`
+	ErrorMsg  string       `json:"errorMessage"`
+}
+
+// DepthEvent represents an increase100 depth payload
+// Versions increase by one per event, so a skipped version means a missed update
+type DepthEvent struct {
+	Symbol  string     `json:"symbol"`
+	Type    string     `json:"type"` // "snapshot" or "update"
+	Version int64      `json:"version"`
+	MsT     int64      `json:"ms_t"` // Milliseconds
+	Asks    [][]string `json:"asks"` // [price, quantity]
+	Bids    [][]string `json:"bids"` // [price, quantity]
+}
+
+// TradesMessage represents a spot/trade message
+type TradesMessage struct {
+	Table string      `json:"table"`
+	Data  []TradeData `json:"data"`
+}
+
+// TradeData represents a single public trade; BitMart sends no trade ID
+type TradeData struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+	Size   string `json:"size"`
+	Side   string `json:"side"` // Taker side: "buy" or "sell"
+	MsT    int64  `json:"ms_t"` // Milliseconds
+}
@@ -0,0 +1,392 @@
+package bitstamp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+// SpotExchange implements the Exchange interface for Bitstamp Spot
+// Bitstamp diffs carry no sequence numbers, so the microtimestamp of each
+// message is used as the update ID to line diffs up with the REST snapshot
+type SpotExchange struct {
+	symbol     string
+	pair       string // Bitstamp format (e.g., btcusd)
+	wsURL      string
+	restURL    string
+	wsConn     *websocket.Conn
+	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
+	done       chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	health     atomic.Value // stores exchange.HealthStatus
+	lastMicro  int64
+}
+
+// NewSpotExchange creates a new Bitstamp Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pair := config.Symbol
+
+	ex := &SpotExchange{
+		symbol:     config.Symbol,
+		pair:       pair,
+		wsURL:      "wss://ws.bitstamp.net",
+		restURL:    fmt.Sprintf("https://www.bitstamp.net/api/v2/order_book/%s/", pair),
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.Bitstamp
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Bitstamp
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	// live_order_book only carries the top 100 levels, so the full book comes
+	// from REST and the socket is only used for diffs
+	channel := fmt.Sprintf("diff_order_book_%s", e.pair)
+	subscribeMsg := SubscribeRequest{
+		Event: "bts:subscribe",
+		Data:  SubscribeData{Channel: channel},
+	}
+
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	tradesChannel := fmt.Sprintf("live_trades_%s", e.pair)
+	subscribeMsg.Data.Channel = tradesChannel
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", channel, "trade_channel", tradesChannel)
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the full orderbook snapshot via REST API
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("snapshot request failed with status %d", resp.StatusCode)
+	}
+
+	var book OrderBook
+	if err := json.NewDecoder(resp.Body).Decode(&book); err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	micro, err := strconv.ParseInt(book.Microtimestamp, 10, 64)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("invalid snapshot microtimestamp %q: %w", book.Microtimestamp, err)
+	}
+
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.pair,
+		LastUpdateID: micro,
+		Bids:         convertLevels(book.Bids),
+		Asks:         convertLevels(book.Asks),
+		Timestamp:    time.UnixMicro(micro),
+	}, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Bitstamp feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.pair,
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			if msg.Event == "trade" {
+				e.incrementMessageCount()
+				e.updateLastPing()
+				e.handleTrade(msg.Data)
+				continue
+			}
+
+			if msg.Event != "data" {
+				if msg.Event == "bts:request_reconnect" {
+					e.log().Warn("Server requested reconnect", "event", "reconnect_requested")
+				}
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			var book OrderBook
+			if err := json.Unmarshal(msg.Data, &book); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to parse update", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			canonicalUpdate, err := e.convertDepthUpdate(&book)
+			if err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to convert update", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrade forwards a live_trades message
+func (e *SpotExchange) handleTrade(raw json.RawMessage) {
+	var trade Trade
+	if err := json.Unmarshal(raw, &trade); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trade", "event", "parse_failed", "error", err)
+		return
+	}
+
+	side := exchange.TakerBuy
+	if trade.Type == 1 {
+		side = exchange.TakerSell
+	}
+	micro, _ := strconv.ParseInt(trade.Microtimestamp, 10, 64)
+	exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    e.pair,
+		TradeID:   strconv.FormatInt(trade.ID, 10),
+		Price:     trade.PriceStr,
+		Quantity:  trade.AmountStr,
+		Side:      side,
+		Timestamp: time.UnixMicro(micro),
+	})
+}
+
+// convertDepthUpdate converts a Bitstamp diff to canonical format
+// Each diff spans (previous microtimestamp, own microtimestamp]
+func (e *SpotExchange) convertDepthUpdate(book *OrderBook) (*exchange.DepthUpdate, error) {
+	micro, err := strconv.ParseInt(book.Microtimestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid microtimestamp %q: %w", book.Microtimestamp, err)
+	}
+
+	prev := e.lastMicro
+	if prev == 0 {
+		prev = micro - 1
+	}
+	e.lastMicro = micro
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        e.pair,
+		EventTime:     time.UnixMicro(micro),
+		FirstUpdateID: prev + 1,
+		FinalUpdateID: micro,
+		PrevUpdateID:  prev,
+		Bids:          convertLevels(book.Bids),
+		Asks:          convertLevels(book.Asks),
+	}, nil
+}
+
+// convertLevels converts [price, amount] entries to canonical price levels
+func convertLevels(levels [][]string) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) >= 2 {
+			result = append(result, exchange.PriceLevel{
+				Price:    level[0],
+				Quantity: level[1],
+			})
+		}
+	}
+	return result
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,45 @@
+package bitstamp
+
+import "encoding/json"
+
+// Config holds configuration for Bitstamp exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. btcusd), as resolved by symbols.Native
+}
+
+// SubscribeRequest represents a Bitstamp WebSocket subscription request
+type SubscribeRequest struct {
+	Event string        `json:"event"` // "bts:subscribe"
+	Data  SubscribeData `json:"data"`
+}
+
+// SubscribeData holds the channel to subscribe to
+type SubscribeData struct {
+	Channel string `json:"channel"`
+}
+
+// WSMessage represents a WebSocket message from Bitstamp; Data is an OrderBook on
+// "data" events and a Trade on "trade" events
+type WSMessage struct {
+	Event   string          `json:"event"` // "data", "trade", "bts:subscription_succeeded", "bts:request_reconnect"
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// OrderBook represents the book payload shared by the REST snapshot,
+// live_order_book and diff_order_book messages
+type OrderBook struct {
+	Timestamp      string     `json:"timestamp"`
+	Microtimestamp string     `json:"microtimestamp"`
+	Bids           [][]string `json:"bids"` // [price, amount]
+	Asks           [][]string `json:"asks"` // [price, amount]
+}
+
+// Trade represents a live_trades message payload
+type Trade struct {
+	ID             int64  `json:"id"`
+	AmountStr      string `json:"amount_str"`
+	PriceStr       string `json:"price_str"`
+	Type           int    `json:"type"` // Taker side: 0 buy, 1 sell
+	Microtimestamp string `json:"microtimestamp"`
+}
@@ -0,0 +1,33 @@
+package bybit
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// defaultDepth is the orderbook channel subscribed to unless another is configured
+const defaultDepth = 1000
+
+// Push intervals of the orderbook channels by depth; depth 1 is left out because it only
+// sends snapshots, which the adapters apply as deltas
+var (
+	spotDepths   = map[int]time.Duration{50: 20 * time.Millisecond, 200: 200 * time.Millisecond, 1000: 200 * time.Millisecond}
+	linearDepths = map[int]time.Duration{50: 20 * time.Millisecond, 200: 100 * time.Millisecond, 500: 100 * time.Millisecond, 1000: 200 * time.Millisecond}
+)
+
+// orderbookDepth returns the channel depth selected by config and its push interval; a depth
+// the market does not offer is logged and replaced by the default
+func orderbookDepth(name exchange.ExchangeName, config Config, depths map[int]time.Duration) (int, time.Duration) {
+	if config.Depth == 0 {
+		return defaultDepth, depths[defaultDepth]
+	}
+	if interval, ok := depths[config.Depth]; ok {
+		return config.Depth, interval
+	}
+	logging.For(string(name), config.Symbol).Warn("Ignoring unsupported depth", "event", "config_ignored", "depth", config.Depth, "supported", slices.Sorted(maps.Keys(depths)))
+	return defaultDepth, depths[defaultDepth]
+}
@@ -2,23 +2,28 @@ package bybit
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"orderbook/internal/exchange"
-
-	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/shard"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
 // FuturesExchange implements the Exchange interface for Bybit Futures
 type FuturesExchange struct {
 	symbol           string
-	wsURL            string
-	wsConn           *websocket.Conn
+	pool             *shard.Pool
 	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	fundingChan      chan *exchange.Funding
+	funding          exchange.FundingState
 	done             chan struct{}
 	ctx              context.Context
 	cancel           context.CancelFunc
@@ -27,26 +32,32 @@ type FuturesExchange struct {
 	lastSeq          int64
 	snapshot         *exchange.Snapshot
 	snapshotMu       sync.Mutex
+	depth            int
+	interval         time.Duration
 }
 
-// Config holds configuration for Bybit Futures exchange
+// Config holds configuration for Bybit exchanges
 type Config struct {
 	Symbol string
+	Depth  int // Levels of the orderbook channel: 50, 200, 500 (linear only) or 1000; 0 keeps 1000
 }
 
 // NewFuturesExchange creates a new Bybit Futures exchange instance
 func NewFuturesExchange(config Config) *FuturesExchange {
 	ctx, cancel := context.WithCancel(context.Background())
-
-	wsURL := "wss://stream.bybit.com/v5/public/linear"
+	depth, interval := orderbookDepth(exchange.Bybitf, config, linearDepths)
 
 	ex := &FuturesExchange{
-		symbol:     config.Symbol,
-		wsURL:      wsURL,
-		updateChan: make(chan *exchange.DepthUpdate, 1000),
-		done:       make(chan struct{}),
-		ctx:        ctx,
-		cancel:     cancel,
+		symbol:      config.Symbol,
+		pool:        newPool(exchange.Bybitf, config.Symbol, "wss://stream.bybit.com/v5/public/linear"),
+		updateChan:  make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:   make(chan *exchange.Trade, 1000),
+		fundingChan: make(chan *exchange.Funding, 100),
+		done:        make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+		depth:       depth,
+		interval:    interval,
 	}
 
 	ex.health.Store(exchange.HealthStatus{
@@ -69,74 +80,48 @@ func (e *FuturesExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to Bybit Futures
 func (e *FuturesExchange) Connect(ctx context.Context) error {
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
-
-	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
-	if err != nil {
+	if err := e.pool.Connect(ctx, e.topics(), e.handleMessage); err != nil {
 		e.incrementErrorCount()
-		return fmt.Errorf("websocket connection failed: %w", err)
+		return err
 	}
 
-	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "orderbook", "depth", e.depth)
 
-	// Subscribe to orderbook stream (using depth 200 for full orderbook)
-	subscribeMsg := SubscribeMessage{
-		Op:   "subscribe",
-		Args: []string{fmt.Sprintf("orderbook.1000.%s", e.symbol)},
-	}
-
-	if err := conn.WriteJSON(subscribeMsg); err != nil {
-		e.incrementErrorCount()
-		conn.Close()
-		return fmt.Errorf("failed to subscribe: %w", err)
-	}
-
-	log.Printf("[%s] Subscribed to orderbook.1000.%s", e.GetName(), e.symbol)
-
-	go e.readMessages()
+	go e.watchPool()
 
 	return nil
 }
 
-// Close closes the WebSocket connection
+// Close closes every WebSocket connection
 func (e *FuturesExchange) Close() error {
 	if e.cancel != nil {
 		e.cancel()
 	}
 
-	if e.wsConn != nil {
-		select {
-		case <-e.done:
-		default:
-			close(e.done)
-		}
-
-		err := e.wsConn.WriteMessage(websocket.CloseMessage,
-			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
-		}
-
-		select {
-		case <-time.After(time.Second):
-		}
-
-		e.updateConnectionStatus(false)
-		return e.wsConn.Close()
+	select {
+	case <-e.done:
+	default:
+		close(e.done)
 	}
-	return nil
+
+	err := e.pool.Close()
+	e.updateConnectionStatus(false)
+	return err
 }
 
 // GetSnapshot fetches the initial orderbook snapshot via WebSocket
 // For Bybit, the first message received will be a snapshot
 func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Waiting for orderbook snapshot from WebSocket...", e.GetName())
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
 
 	// Wait for the first snapshot message from the WebSocket
 	timeout := time.NewTimer(10 * time.Second)
@@ -166,9 +151,19 @@ func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
-// IsConnected checks if the WebSocket connection is active
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// Funding returns a channel that receives funding and open interest
+func (e *FuturesExchange) Funding() <-chan *exchange.Funding {
+	return e.fundingChan
+}
+
+// IsConnected checks if any WebSocket connection is active
 func (e *FuturesExchange) IsConnected() bool {
-	return e.wsConn != nil
+	return e.pool.Connected()
 }
 
 // Health returns connection health information
@@ -179,67 +174,148 @@ func (e *FuturesExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
-// readMessages continuously reads WebSocket messages
-func (e *FuturesExchange) readMessages() {
+// Capabilities returns the features of the Bybit Futures feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       e.depth,
+		UpdateInterval: e.interval,
+		Trades:         true,
+		Funding:        true,
+		NativeSymbol:   e.symbol,
+	}
+}
+
+// ConnectionCount returns the number of WebSocket connections the topics are sharded over
+func (e *FuturesExchange) ConnectionCount() int {
+	return e.pool.Len()
+}
+
+// ConnectionHealth returns health information per WebSocket connection
+func (e *FuturesExchange) ConnectionHealth() []exchange.HealthStatus {
+	return e.pool.Health()
+}
+
+// topics returns the orderbook topics to subscribe to
+func (e *FuturesExchange) topics() []string {
+	return []string{fmt.Sprintf("orderbook.%d.%s", e.depth, e.symbol), fmt.Sprintf("publicTrade.%s", e.symbol), fmt.Sprintf("tickers.%s", e.symbol)}
+}
+
+// watchPool closes the update, trade and funding channels once every connection has stopped reading
+func (e *FuturesExchange) watchPool() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer close(e.fundingChan)
 	defer e.updateConnectionStatus(false)
 
-	for {
-		select {
-		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
-			return
-		case <-e.done:
-			return
-		default:
-			var msg WSMessage
-			if err := e.wsConn.ReadJSON(&msg); err != nil {
-				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
-				return
-			}
+	<-e.pool.Done()
+}
 
-			// Skip non-orderbook messages
-			if msg.Topic == "" || msg.Data.Symbol == "" {
-				continue
-			}
+// handleMessage processes one WebSocket message from any pool connection
+func (e *FuturesExchange) handleMessage(c *shard.Conn, message []byte) {
+	var msg WSMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse message", "event", "parse_failed", "connection", c.Index, "error", err)
+		return
+	}
+
+	// Skip non-orderbook messages
+	if strings.HasPrefix(msg.Topic, "publicTrade.") {
+		e.incrementMessageCount()
+		e.updateLastPing()
+		e.handleTrades(c, &msg)
+		return
+	}
+	if strings.HasPrefix(msg.Topic, "tickers.") {
+		e.incrementMessageCount()
+		e.updateLastPing()
+		e.handleTicker(c, &msg)
+		return
+	}
+	if msg.Topic == "" || json.Unmarshal(msg.Data, &msg.Book) != nil || msg.Book.Symbol == "" {
+		return
+	}
 
-			e.incrementMessageCount()
-			e.updateLastPing()
+	e.incrementMessageCount()
+	e.updateLastPing()
 
-			// Handle initial snapshot
-			if msg.Type == "snapshot" && !e.snapshotReceived {
-				e.storeSnapshot(&msg)
-				e.snapshotReceived = true
-			}
+	// Handle initial snapshot
+	if msg.Type == "snapshot" && !e.snapshotReceived {
+		e.storeSnapshot(&msg)
+		e.snapshotReceived = true
+	}
 
-			canonicalUpdate := e.convertDepthUpdate(&msg)
+	canonicalUpdate := e.convertDepthUpdate(&msg)
 
-			select {
-			case e.updateChan <- canonicalUpdate:
-			case <-e.ctx.Done():
-				return
-			case <-e.done:
-				return
-			default:
-				log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
-			}
+	select {
+	case e.updateChan <- canonicalUpdate:
+	case <-e.ctx.Done():
+	case <-e.done:
+	default:
+		e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+	}
+}
+
+// handleTrades forwards the trades of a publicTrade message
+func (e *FuturesExchange) handleTrades(c *shard.Conn, msg *WSMessage) {
+	var trades []TradeData
+	if err := json.Unmarshal(msg.Data, &trades); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "connection", c.Index, "error", err)
+		return
+	}
+	for _, trade := range trades {
+		side := exchange.TakerBuy
+		if trade.Side == "Sell" {
+			side = exchange.TakerSell
 		}
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.Symbol,
+			TradeID:   trade.TradeID,
+			Price:     trade.Price,
+			Quantity:  trade.Size,
+			Side:      side,
+			Timestamp: time.UnixMilli(trade.Time),
+		})
+	}
+}
+
+// handleTicker merges the funding and open interest of a tickers snapshot or delta
+func (e *FuturesExchange) handleTicker(c *shard.Conn, msg *WSMessage) {
+	var ticker TickerData
+	if err := json.Unmarshal(msg.Data, &ticker); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse ticker", "event", "parse_failed", "connection", c.Index, "error", err)
+		return
+	}
+	update := exchange.Funding{
+		Exchange:     e.GetName(),
+		Symbol:       e.symbol,
+		Rate:         ticker.FundingRate,
+		MarkPrice:    ticker.MarkPrice,
+		IndexPrice:   ticker.IndexPrice,
+		OpenInterest: ticker.OpenInterest,
+		Timestamp:    time.UnixMilli(msg.TS),
 	}
+	if next, err := strconv.ParseInt(ticker.NextFundingTime, 10, 64); err == nil && next > 0 {
+		update.NextFunding = time.UnixMilli(next)
+	}
+	exchange.SendFunding(e.GetName(), e.fundingChan, e.funding.Merge(update))
 }
 
 // storeSnapshot converts and stores the initial snapshot
 func (e *FuturesExchange) storeSnapshot(msg *WSMessage) {
-	bids := make([]exchange.PriceLevel, len(msg.Data.Bids))
-	for i, bid := range msg.Data.Bids {
+	bids := make([]exchange.PriceLevel, len(msg.Book.Bids))
+	for i, bid := range msg.Book.Bids {
 		bids[i] = exchange.PriceLevel{
 			Price:    bid[0],
 			Quantity: bid[1],
 		}
 	}
 
-	asks := make([]exchange.PriceLevel, len(msg.Data.Asks))
-	for i, ask := range msg.Data.Asks {
+	asks := make([]exchange.PriceLevel, len(msg.Book.Asks))
+	for i, ask := range msg.Book.Asks {
 		asks[i] = exchange.PriceLevel{
 			Price:    ask[0],
 			Quantity: ask[1],
@@ -248,8 +324,8 @@ func (e *FuturesExchange) storeSnapshot(msg *WSMessage) {
 
 	snapshot := &exchange.Snapshot{
 		Exchange:     e.GetName(),
-		Symbol:       msg.Data.Symbol,
-		LastUpdateID: msg.Data.SeqNum,
+		Symbol:       msg.Book.Symbol,
+		LastUpdateID: msg.Book.SeqNum,
 		Bids:         bids,
 		Asks:         asks,
 		Timestamp:    time.UnixMilli(msg.TS),
@@ -257,22 +333,22 @@ func (e *FuturesExchange) storeSnapshot(msg *WSMessage) {
 
 	e.snapshotMu.Lock()
 	e.snapshot = snapshot
-	e.lastSeq = msg.Data.SeqNum
+	e.lastSeq = msg.Book.SeqNum
 	e.snapshotMu.Unlock()
 }
 
 // convertDepthUpdate converts Bybit depth update to canonical format
 func (e *FuturesExchange) convertDepthUpdate(msg *WSMessage) *exchange.DepthUpdate {
-	bids := make([]exchange.PriceLevel, len(msg.Data.Bids))
-	for i, bid := range msg.Data.Bids {
+	bids := make([]exchange.PriceLevel, len(msg.Book.Bids))
+	for i, bid := range msg.Book.Bids {
 		bids[i] = exchange.PriceLevel{
 			Price:    bid[0],
 			Quantity: bid[1],
 		}
 	}
 
-	asks := make([]exchange.PriceLevel, len(msg.Data.Asks))
-	for i, ask := range msg.Data.Asks {
+	asks := make([]exchange.PriceLevel, len(msg.Book.Asks))
+	for i, ask := range msg.Book.Asks {
 		asks[i] = exchange.PriceLevel{
 			Price:    ask[0],
 			Quantity: ask[1],
@@ -282,14 +358,14 @@ func (e *FuturesExchange) convertDepthUpdate(msg *WSMessage) *exchange.DepthUpda
 	// Use seq for continuity tracking
 	// Set PrevUpdateID to lastSeq to enable continuity checking
 	prevSeq := e.lastSeq
-	e.lastSeq = msg.Data.SeqNum
+	e.lastSeq = msg.Book.SeqNum
 
 	return &exchange.DepthUpdate{
 		Exchange:      e.GetName(),
-		Symbol:        msg.Data.Symbol,
+		Symbol:        msg.Book.Symbol,
 		EventTime:     time.UnixMilli(msg.TS),
-		FirstUpdateID: msg.Data.SeqNum,
-		FinalUpdateID: msg.Data.SeqNum,
+		FirstUpdateID: msg.Book.SeqNum,
+		FinalUpdateID: msg.Book.SeqNum,
 		PrevUpdateID:  prevSeq,
 		Bids:          bids,
 		Asks:          asks,
@@ -299,6 +375,9 @@ func (e *FuturesExchange) convertDepthUpdate(msg *WSMessage) *exchange.DepthUpda
 // updateConnectionStatus updates the connection status in health
 func (e *FuturesExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
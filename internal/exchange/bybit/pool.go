@@ -0,0 +1,26 @@
+package bybit
+
+import (
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/shard"
+)
+
+// maxTopicsPerConn is the number of args Bybit accepts in one spot subscribe request
+// Linear streams allow more, but the same limit keeps both markets uniform
+const maxTopicsPerConn = 10
+
+// newPool creates a connection pool that subscribes each connection to its topics
+func newPool(name exchange.ExchangeName, symbol, url string) *shard.Pool {
+	return shard.NewPool(shard.Config{
+		Name:             name,
+		Symbol:           symbol,
+		URL:              url,
+		MaxTopicsPerConn: maxTopicsPerConn,
+		Subscribe: func(c *shard.Conn) error {
+			return c.WriteJSON(SubscribeMessage{
+				Op:   "subscribe",
+				Args: c.Topics,
+			})
+		},
+	})
+}
@@ -2,23 +2,25 @@ package bybit
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"orderbook/internal/exchange"
-
-	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/shard"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
 // SpotExchange implements the Exchange interface for Bybit Spot
 type SpotExchange struct {
 	symbol           string
-	wsURL            string
-	wsConn           *websocket.Conn
+	pool             *shard.Pool
 	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
 	done             chan struct{}
 	ctx              context.Context
 	cancel           context.CancelFunc
@@ -27,21 +29,25 @@ type SpotExchange struct {
 	lastSeq          int64
 	snapshot         *exchange.Snapshot
 	snapshotMu       sync.Mutex
+	depth            int
+	interval         time.Duration
 }
 
 // NewSpotExchange creates a new Bybit Spot exchange instance
 func NewSpotExchange(config Config) *SpotExchange {
 	ctx, cancel := context.WithCancel(context.Background())
-
-	wsURL := "wss://stream.bybit.com/v5/public/spot"
+	depth, interval := orderbookDepth(exchange.Bybit, config, spotDepths)
 
 	ex := &SpotExchange{
 		symbol:     config.Symbol,
-		wsURL:      wsURL,
+		pool:       newPool(exchange.Bybit, config.Symbol, "wss://stream.bybit.com/v5/public/spot"),
 		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
 		done:       make(chan struct{}),
 		ctx:        ctx,
 		cancel:     cancel,
+		depth:      depth,
+		interval:   interval,
 	}
 
 	ex.health.Store(exchange.HealthStatus{
@@ -64,72 +70,47 @@ func (e *SpotExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to Bybit Spot
 func (e *SpotExchange) Connect(ctx context.Context) error {
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
-
-	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
-	if err != nil {
+	if err := e.pool.Connect(ctx, e.topics(), e.handleMessage); err != nil {
 		e.incrementErrorCount()
-		return fmt.Errorf("websocket connection failed: %w", err)
+		return err
 	}
 
-	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
-
-	subscribeMsg := SubscribeMessage{
-		Op:   "subscribe",
-		Args: []string{fmt.Sprintf("orderbook.1000.%s", e.symbol)},
-	}
-
-	if err := conn.WriteJSON(subscribeMsg); err != nil {
-		e.incrementErrorCount()
-		conn.Close()
-		return fmt.Errorf("failed to subscribe: %w", err)
-	}
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "orderbook", "depth", e.depth)
 
-	log.Printf("[%s] Subscribed to orderbook.1000.%s", e.GetName(), e.symbol)
-
-	go e.readMessages()
+	go e.watchPool()
 
 	return nil
 }
 
-// Close closes the WebSocket connection
+// Close closes every WebSocket connection
 func (e *SpotExchange) Close() error {
 	if e.cancel != nil {
 		e.cancel()
 	}
 
-	if e.wsConn != nil {
-		select {
-		case <-e.done:
-		default:
-			close(e.done)
-		}
-
-		err := e.wsConn.WriteMessage(websocket.CloseMessage,
-			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
-		}
-
-		select {
-		case <-time.After(time.Second):
-		}
-
-		e.updateConnectionStatus(false)
-		return e.wsConn.Close()
+	select {
+	case <-e.done:
+	default:
+		close(e.done)
 	}
-	return nil
+
+	err := e.pool.Close()
+	e.updateConnectionStatus(false)
+	return err
 }
 
 // GetSnapshot fetches the initial orderbook snapshot via WebSocket
 func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Waiting for orderbook snapshot from WebSocket...", e.GetName())
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
 
 	timeout := time.NewTimer(10 * time.Second)
 	defer timeout.Stop()
@@ -158,9 +139,14 @@ func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
-// IsConnected checks if the WebSocket connection is active
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if any WebSocket connection is active
 func (e *SpotExchange) IsConnected() bool {
-	return e.wsConn != nil
+	return e.pool.Connected()
 }
 
 // Health returns connection health information
@@ -171,65 +157,115 @@ func (e *SpotExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
-// readMessages continuously reads WebSocket messages
-func (e *SpotExchange) readMessages() {
+// Capabilities returns the features of the Bybit Spot feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       e.depth,
+		UpdateInterval: e.interval,
+		Trades:         true,
+		NativeSymbol:   e.symbol,
+	}
+}
+
+// ConnectionCount returns the number of WebSocket connections the topics are sharded over
+func (e *SpotExchange) ConnectionCount() int {
+	return e.pool.Len()
+}
+
+// ConnectionHealth returns health information per WebSocket connection
+func (e *SpotExchange) ConnectionHealth() []exchange.HealthStatus {
+	return e.pool.Health()
+}
+
+// topics returns the orderbook topics to subscribe to
+func (e *SpotExchange) topics() []string {
+	return []string{fmt.Sprintf("orderbook.%d.%s", e.depth, e.symbol), fmt.Sprintf("publicTrade.%s", e.symbol)}
+}
+
+// watchPool closes the update and trade channels once every connection has stopped reading
+func (e *SpotExchange) watchPool() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
 	defer e.updateConnectionStatus(false)
 
-	for {
-		select {
-		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
-			return
-		case <-e.done:
-			return
-		default:
-			var msg WSMessage
-			if err := e.wsConn.ReadJSON(&msg); err != nil {
-				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
-				return
-			}
+	<-e.pool.Done()
+}
 
-			if msg.Topic == "" || msg.Data.Symbol == "" {
-				continue
-			}
+// handleMessage processes one WebSocket message from any pool connection
+func (e *SpotExchange) handleMessage(c *shard.Conn, message []byte) {
+	var msg WSMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse message", "event", "parse_failed", "connection", c.Index, "error", err)
+		return
+	}
 
-			e.incrementMessageCount()
-			e.updateLastPing()
+	if strings.HasPrefix(msg.Topic, "publicTrade.") {
+		e.incrementMessageCount()
+		e.updateLastPing()
+		e.handleTrades(c, &msg)
+		return
+	}
+	if msg.Topic == "" || json.Unmarshal(msg.Data, &msg.Book) != nil || msg.Book.Symbol == "" {
+		return
+	}
 
-			if msg.Type == "snapshot" && !e.snapshotReceived {
-				e.storeSnapshot(&msg)
-				e.snapshotReceived = true
-			}
+	e.incrementMessageCount()
+	e.updateLastPing()
 
-			canonicalUpdate := e.convertDepthUpdate(&msg)
+	if msg.Type == "snapshot" && !e.snapshotReceived {
+		e.storeSnapshot(&msg)
+		e.snapshotReceived = true
+	}
 
-			select {
-			case e.updateChan <- canonicalUpdate:
-			case <-e.ctx.Done():
-				return
-			case <-e.done:
-				return
-			default:
-				log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
-			}
+	canonicalUpdate := e.convertDepthUpdate(&msg)
+
+	select {
+	case e.updateChan <- canonicalUpdate:
+	case <-e.ctx.Done():
+	case <-e.done:
+	default:
+		e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+	}
+}
+
+// handleTrades forwards the trades of a publicTrade message
+func (e *SpotExchange) handleTrades(c *shard.Conn, msg *WSMessage) {
+	var trades []TradeData
+	if err := json.Unmarshal(msg.Data, &trades); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "connection", c.Index, "error", err)
+		return
+	}
+	for _, trade := range trades {
+		side := exchange.TakerBuy
+		if trade.Side == "Sell" {
+			side = exchange.TakerSell
 		}
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.Symbol,
+			TradeID:   trade.TradeID,
+			Price:     trade.Price,
+			Quantity:  trade.Size,
+			Side:      side,
+			Timestamp: time.UnixMilli(trade.Time),
+		})
 	}
 }
 
 // storeSnapshot converts and stores the initial snapshot
 func (e *SpotExchange) storeSnapshot(msg *WSMessage) {
-	bids := make([]exchange.PriceLevel, len(msg.Data.Bids))
-	for i, bid := range msg.Data.Bids {
+	bids := make([]exchange.PriceLevel, len(msg.Book.Bids))
+	for i, bid := range msg.Book.Bids {
 		bids[i] = exchange.PriceLevel{
 			Price:    bid[0],
 			Quantity: bid[1],
 		}
 	}
 
-	asks := make([]exchange.PriceLevel, len(msg.Data.Asks))
-	for i, ask := range msg.Data.Asks {
+	asks := make([]exchange.PriceLevel, len(msg.Book.Asks))
+	for i, ask := range msg.Book.Asks {
 		asks[i] = exchange.PriceLevel{
 			Price:    ask[0],
 			Quantity: ask[1],
@@ -238,8 +274,8 @@ func (e *SpotExchange) storeSnapshot(msg *WSMessage) {
 
 	snapshot := &exchange.Snapshot{
 		Exchange:     e.GetName(),
-		Symbol:       msg.Data.Symbol,
-		LastUpdateID: msg.Data.SeqNum,
+		Symbol:       msg.Book.Symbol,
+		LastUpdateID: msg.Book.SeqNum,
 		Bids:         bids,
 		Asks:         asks,
 		Timestamp:    time.UnixMilli(msg.TS),
@@ -247,22 +283,22 @@ func (e *SpotExchange) storeSnapshot(msg *WSMessage) {
 
 	e.snapshotMu.Lock()
 	e.snapshot = snapshot
-	e.lastSeq = msg.Data.SeqNum
+	e.lastSeq = msg.Book.SeqNum
 	e.snapshotMu.Unlock()
 }
 
 // convertDepthUpdate converts Bybit depth update to canonical format
 func (e *SpotExchange) convertDepthUpdate(msg *WSMessage) *exchange.DepthUpdate {
-	bids := make([]exchange.PriceLevel, len(msg.Data.Bids))
-	for i, bid := range msg.Data.Bids {
+	bids := make([]exchange.PriceLevel, len(msg.Book.Bids))
+	for i, bid := range msg.Book.Bids {
 		bids[i] = exchange.PriceLevel{
 			Price:    bid[0],
 			Quantity: bid[1],
 		}
 	}
 
-	asks := make([]exchange.PriceLevel, len(msg.Data.Asks))
-	for i, ask := range msg.Data.Asks {
+	asks := make([]exchange.PriceLevel, len(msg.Book.Asks))
+	for i, ask := range msg.Book.Asks {
 		asks[i] = exchange.PriceLevel{
 			Price:    ask[0],
 			Quantity: ask[1],
@@ -270,14 +306,14 @@ func (e *SpotExchange) convertDepthUpdate(msg *WSMessage) *exchange.DepthUpdate
 	}
 
 	prevSeq := e.lastSeq
-	e.lastSeq = msg.Data.SeqNum
+	e.lastSeq = msg.Book.SeqNum
 
 	return &exchange.DepthUpdate{
 		Exchange:      e.GetName(),
-		Symbol:        msg.Data.Symbol,
+		Symbol:        msg.Book.Symbol,
 		EventTime:     time.UnixMilli(msg.TS),
-		FirstUpdateID: msg.Data.SeqNum,
-		FinalUpdateID: msg.Data.SeqNum,
+		FirstUpdateID: msg.Book.SeqNum,
+		FinalUpdateID: msg.Book.SeqNum,
 		PrevUpdateID:  prevSeq,
 		Bids:          bids,
 		Asks:          asks,
@@ -287,6 +323,9 @@ func (e *SpotExchange) convertDepthUpdate(msg *WSMessage) *exchange.DepthUpdate
 // updateConnectionStatus updates the connection status in health
 func (e *SpotExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
@@ -1,12 +1,16 @@
 package bybit
 
+import "encoding/json"
+
 // WSMessage represents a WebSocket message from Bybit
+// Data holds an object on orderbook and tickers topics and an array on publicTrade topics
 type WSMessage struct {
-	Topic string        `json:"topic"`
-	Type  string        `json:"type"` // "snapshot" or "delta"
-	TS    int64         `json:"ts"`
-	Data  OrderbookData `json:"data"`
-	CTS   int64         `json:"cts"` // matching engine timestamp
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"` // "snapshot" or "delta"
+	TS    int64           `json:"ts"`
+	Data  json.RawMessage `json:"data"`
+	CTS   int64           `json:"cts"` // matching engine timestamp
+	Book  OrderbookData   `json:"-"`   // Data decoded, on orderbook topics
 }
 
 // OrderbookData represents the orderbook data from Bybit
@@ -18,6 +22,27 @@ type OrderbookData struct {
 	SeqNum   int64      `json:"seq"`
 }
 
+// TradeData represents one trade of a publicTrade message from Bybit
+type TradeData struct {
+	Time    int64  `json:"T"`
+	Symbol  string `json:"s"`
+	Side    string `json:"S"` // Taker side: "Buy" or "Sell"
+	Size    string `json:"v"`
+	Price   string `json:"p"`
+	TradeID string `json:"i"`
+}
+
+// TickerData represents the data of a linear tickers message from Bybit
+// Deltas only carry the fields that changed
+type TickerData struct {
+	Symbol          string `json:"symbol"`
+	MarkPrice       string `json:"markPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	OpenInterest    string `json:"openInterest"` // Base units on linear contracts
+	FundingRate     string `json:"fundingRate"`
+	NextFundingTime string `json:"nextFundingTime"` // Unix milliseconds
+}
+
 // SubscribeMessage represents a subscription request
 type SubscribeMessage struct {
 	Op   string   `json:"op"`
@@ -4,13 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 
 	"github.com/gorilla/websocket"
 	"github.com/shopspring/decimal"
@@ -22,6 +23,7 @@ type SpotExchange struct {
 	wsURL            string
 	wsConn           *websocket.Conn
 	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
 	done             chan struct{}
 	ctx              context.Context
 	cancel           context.CancelFunc
@@ -37,12 +39,13 @@ func NewSpotExchange(config Config) *SpotExchange {
 
 	wsURL := "wss://advanced-trade-ws.coinbase.com"
 
-	coinbaseSymbol := convertToCoinbaseSymbol(config.Symbol)
+	coinbaseSymbol := config.Symbol
 
 	ex := &SpotExchange{
 		symbol:     coinbaseSymbol,
 		wsURL:      wsURL,
 		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
 		done:       make(chan struct{}),
 		ctx:        ctx,
 		cancel:     cancel,
@@ -68,6 +71,11 @@ func (e *SpotExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to Coinbase
 func (e *SpotExchange) Connect(ctx context.Context) error {
 	dialer := websocket.Dialer{
@@ -82,7 +90,7 @@ func (e *SpotExchange) Connect(ctx context.Context) error {
 
 	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
+	e.log().Info("WebSocket connected", "event", "ws_connected")
 
 	subscribeMsg := SubscribeRequest{
 		Type:       "subscribe",
@@ -96,7 +104,14 @@ func (e *SpotExchange) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to subscribe: %w", err)
 	}
 
-	log.Printf("[%s] Subscribed to level2 channel for %s", e.GetName(), e.symbol)
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "level2")
+
+	subscribeMsg.Channel = "market_trades"
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
 
 	go e.readMessages()
 
@@ -119,7 +134,7 @@ func (e *SpotExchange) Close() error {
 		err := e.wsConn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
 		}
 
 		select {
@@ -134,7 +149,7 @@ func (e *SpotExchange) Close() error {
 
 // GetSnapshot fetches the initial orderbook snapshot via WebSocket
 func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Waiting for orderbook snapshot from WebSocket...", e.GetName())
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
 
 	timeout := time.NewTimer(10 * time.Second)
 	defer timeout.Stop()
@@ -163,6 +178,11 @@ func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
 // IsConnected checks if the WebSocket connection is active
 func (e *SpotExchange) IsConnected() bool {
 	return e.wsConn != nil
@@ -176,23 +196,32 @@ func (e *SpotExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the Coinbase feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.symbol,
+	}
+}
+
 // readMessages continuously reads WebSocket messages
 func (e *SpotExchange) readMessages() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
 	defer e.updateConnectionStatus(false)
 
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
 			return
 		case <-e.done:
 			return
 		default:
-			_, message, err := e.wsConn.ReadMessage()
+			_, message, err := exchange.ReadMessage(e.wsConn, e.GetName())
 			if err != nil {
 				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
 				return
 			}
 
@@ -201,6 +230,13 @@ func (e *SpotExchange) readMessages() {
 				continue
 			}
 
+			if msg.Channel == "market_trades" {
+				e.incrementMessageCount()
+				e.updateLastPing()
+				e.handleTrades(&msg)
+				continue
+			}
+
 			if msg.Channel != "l2_data" || len(msg.Events) == 0 {
 				continue
 			}
@@ -225,13 +261,34 @@ func (e *SpotExchange) readMessages() {
 				case <-e.done:
 					return
 				default:
-					log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
 				}
 			}
 		}
 	}
 }
 
+// handleTrades forwards new trades; the snapshot of recent trades sent on subscribing is skipped
+func (e *SpotExchange) handleTrades(msg *WSMessage) {
+	for _, event := range msg.Events {
+		if event.Type != "update" {
+			continue
+		}
+		for _, trade := range event.Trades {
+			timestamp, _ := time.Parse(time.RFC3339Nano, trade.Time)
+			exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+				Exchange:  e.GetName(),
+				Symbol:    trade.ProductID,
+				TradeID:   trade.TradeID,
+				Price:     trade.Price,
+				Quantity:  trade.Size,
+				Side:      exchange.TradeSide(strings.ToLower(trade.Side)),
+				Timestamp: timestamp,
+			})
+		}
+	}
+}
+
 // storeSnapshot converts and stores the initial snapshot
 func (e *SpotExchange) storeSnapshot(event *Event) {
 	var allBids, allAsks []exchange.PriceLevel
@@ -362,37 +419,12 @@ func (e *SpotExchange) convertDepthUpdate(event *Event) *exchange.DepthUpdate {
 	}
 }
 
-// convertToCoinbaseSymbol converts various symbol formats to Coinbase format
-// Examples: BTCUSDT -> BTC-USD, BTC-USD -> BTC-USD
-func convertToCoinbaseSymbol(symbol string) string {
-	if strings.Contains(symbol, "-") {
-		return strings.ToUpper(symbol)
-	}
-
-	symbol = strings.ToUpper(symbol)
-
-	if strings.HasSuffix(symbol, "USDT") {
-		base := strings.TrimSuffix(symbol, "USDT")
-		return fmt.Sprintf("%s-USD", base)
-	}
-
-	if strings.HasSuffix(symbol, "USD") && !strings.HasSuffix(symbol, "USDT") {
-		base := strings.TrimSuffix(symbol, "USD")
-		return fmt.Sprintf("%s-USD", base)
-	}
-
-	if strings.HasSuffix(symbol, "USDC") {
-		base := strings.TrimSuffix(symbol, "USDC")
-		return fmt.Sprintf("%s-USDC", base)
-	}
-
-	log.Printf("[Coinbase] Warning: Could not convert symbol %s to Coinbase format, using as-is", symbol)
-	return symbol
-}
-
 // updateConnectionStatus updates the connection status in health
 func (e *SpotExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
@@ -2,7 +2,7 @@ package coinbase
 
 // Config holds configuration for Coinbase exchange
 type Config struct {
-	Symbol string
+	Symbol string // Native symbol (e.g. BTC-USD), as resolved by symbols.Native
 }
 
 // SubscribeRequest represents a subscription request to Coinbase WebSocket
@@ -24,6 +24,7 @@ type Event struct {
 	Type      string   `json:"type"` // "snapshot" or "update"
 	ProductID string   `json:"product_id"`
 	Updates   []Update `json:"updates"`
+	Trades    []Trade  `json:"trades"` // market_trades channel
 }
 
 // Update represents a single price level update
@@ -33,3 +34,13 @@ type Update struct {
 	PriceLevel  string `json:"price_level"`  // price
 	NewQuantity string `json:"new_quantity"` // quantity (if "0", remove level)
 }
+
+// Trade represents a trade on the market_trades channel
+type Trade struct {
+	TradeID   string `json:"trade_id"`
+	ProductID string `json:"product_id"`
+	Price     string `json:"price"`
+	Size      string `json:"size"`
+	Side      string `json:"side"` // Taker side: "BUY" or "SELL"
+	Time      string `json:"time"`
+}
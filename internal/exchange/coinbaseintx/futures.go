@@ -0,0 +1,387 @@
+package coinbaseintx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+// FuturesExchange implements the Exchange interface for Coinbase International perpetuals
+type FuturesExchange struct {
+	symbol           string
+	wsURL            string
+	wsConn           *websocket.Conn
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	lastSequence     int64
+	snapshotReceived bool
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+}
+
+// NewFuturesExchange creates a new Coinbase International perpetual exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		wsURL:      "wss://ws-md.international.coinbase.com",
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.CoinbaseIntx
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Coinbase International
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	subscribeMsg := SubscribeRequest{
+		Type:       "SUBSCRIBE",
+		ProductIDs: []string{e.symbol},
+		Channels:   []string{"LEVEL2", "MATCH"},
+	}
+
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "LEVEL2", "trade_channel", "MATCH")
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the orderbook snapshot received from WebSocket
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Coinbase International perpetual feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.symbol,
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			if msg.Type == "REJECT" {
+				e.incrementErrorCount()
+				e.log().Error("Subscription rejected", "event", "subscribe_failed", "error", msg.Reason)
+				continue
+			}
+
+			if msg.Channel == "MATCH" {
+				e.handleMatch(&msg)
+				continue
+			}
+
+			if msg.Channel != "LEVEL2" {
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			switch msg.Type {
+			case "SNAPSHOT":
+				e.storeSnapshot(&msg)
+			case "UPDATE":
+				canonicalUpdate := e.convertDepthUpdate(&msg)
+
+				select {
+				case e.updateChan <- canonicalUpdate:
+				case <-e.ctx.Done():
+					return
+				case <-e.done:
+					return
+				default:
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+				}
+			}
+		}
+	}
+}
+
+// handleMatch forwards a MATCH channel update; the snapshot of recent matches is skipped
+func (e *FuturesExchange) handleMatch(msg *WSMessage) {
+	if msg.Type != "UPDATE" {
+		return
+	}
+
+	e.incrementMessageCount()
+	e.updateLastPing()
+
+	exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    msg.ProductID,
+		TradeID:   msg.MatchID,
+		Price:     msg.TradePrice,
+		Quantity:  msg.TradeQty,
+		Side:      exchange.TradeSide(strings.ToLower(msg.AggressorSide)),
+		Timestamp: parseTime(msg.Time),
+	})
+}
+
+// storeSnapshot converts and stores the snapshot sent after subscribing
+func (e *FuturesExchange) storeSnapshot(msg *WSMessage) {
+	snapshot := &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       msg.ProductID,
+		LastUpdateID: msg.Sequence,
+		Bids:         convertLevels(msg.Bids),
+		Asks:         convertLevels(msg.Asks),
+		Timestamp:    parseTime(msg.Time),
+	}
+
+	e.snapshotMu.Lock()
+	e.snapshot = snapshot
+	e.snapshotReceived = true
+	e.snapshotMu.Unlock()
+
+	e.lastSequence = msg.Sequence
+}
+
+// convertDepthUpdate converts Coinbase International level2 update to canonical format
+// Sequence numbers are shared across the connection, so updates chain on the last one seen
+func (e *FuturesExchange) convertDepthUpdate(msg *WSMessage) *exchange.DepthUpdate {
+	var bids []exchange.PriceLevel
+	var asks []exchange.PriceLevel
+
+	for _, change := range msg.Changes {
+		if len(change) < 3 {
+			continue
+		}
+
+		priceLevel := exchange.PriceLevel{
+			Price:    change[1],
+			Quantity: change[2],
+		}
+
+		if change[0] == "BUY" {
+			bids = append(bids, priceLevel)
+		} else if change[0] == "SELL" {
+			asks = append(asks, priceLevel)
+		}
+	}
+
+	prev := e.lastSequence
+	e.lastSequence = msg.Sequence
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        msg.ProductID,
+		EventTime:     parseTime(msg.Time),
+		FirstUpdateID: prev + 1,
+		FinalUpdateID: msg.Sequence,
+		PrevUpdateID:  prev,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+// convertLevels converts [price, size] entries to canonical price levels
+func convertLevels(levels [][]string) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) >= 2 {
+			result = append(result, exchange.PriceLevel{
+				Price:    level[0],
+				Quantity: level[1],
+			})
+		}
+	}
+	return result
+}
+
+// parseTime parses an RFC3339 message time, falling back to the local clock
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,34 @@
+package coinbaseintx
+
+// Config holds configuration for Coinbase International exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC-PERP), as resolved by symbols.Native
+}
+
+// SubscribeRequest represents a subscription request to Coinbase International WebSocket
+// Market data channels accept unauthenticated subscriptions, so no key, passphrase or
+// signature fields are sent
+type SubscribeRequest struct {
+	Type       string   `json:"type"` // "SUBSCRIBE"
+	ProductIDs []string `json:"product_ids"`
+	Channels   []string `json:"channels"`
+}
+
+// WSMessage represents a WebSocket message from Coinbase International
+type WSMessage struct {
+	Channel   string     `json:"channel"` // "LEVEL2", "MATCH"
+	Type      string     `json:"type"`    // "SNAPSHOT", "UPDATE", "SUBSCRIPTIONS", "REJECT"
+	ProductID string     `json:"product_id"`
+	Sequence  int64      `json:"sequence"`
+	Time      string     `json:"time"`
+	Bids      [][]string `json:"bids"`    // [price, size] (snapshots only)
+	Asks      [][]string `json:"asks"`    // [price, size] (snapshots only)
+	Changes   [][]string `json:"changes"` // [side, price, size] (updates only)
+	Reason    string     `json:"reason"`  // Set on REJECT
+
+	// MATCH fields
+	MatchID       string `json:"match_id"`
+	TradePrice    string `json:"trade_price"`
+	TradeQty      string `json:"trade_qty"`
+	AggressorSide string `json:"aggressor_side"` // "BUY" or "SELL"
+}
@@ -0,0 +1,454 @@
+package cryptocom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsURL     = "wss://stream.crypto.com/exchange/v1/market"
+	bookDepth = 50
+
+	// Crypto.com rejects requests sent right after the handshake, and answers
+	// bursts with TOO_MANY_REQUESTS, so subscriptions are delayed and retried
+	connectDelay       = 1 * time.Second
+	codeTooManyRequest = 10006
+	maxSubscribeRetry  = 5
+)
+
+// SpotExchange implements the Exchange interface for Crypto.com Exchange Spot
+type SpotExchange struct {
+	symbol       string
+	instrument   string // Crypto.com format (e.g., BTC_USDT)
+	wsConn       *websocket.Conn
+	writeMu      sync.Mutex
+	updateChan   chan *exchange.DepthUpdate
+	tradeChan    chan *exchange.Trade
+	done         chan struct{}
+	ctx          context.Context
+	cancel       context.CancelFunc
+	health       atomic.Value // stores exchange.HealthStatus
+	requestID    int64
+	subscribeTry int
+	snapshot     *exchange.Snapshot
+	snapshotMu   sync.Mutex
+}
+
+// NewSpotExchange creates a new Crypto.com Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &SpotExchange{
+		symbol:     config.Symbol,
+		instrument: config.Symbol,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.CryptoCom
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Crypto.com
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	select {
+	case <-time.After(connectDelay):
+	case <-ctx.Done():
+		conn.Close()
+		return ctx.Err()
+	}
+
+	if err := e.subscribe(); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", e.channel(), "trade_channel", e.tradeChannel())
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the latest orderbook snapshot pushed on the book channel
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			snap := e.snapshot
+			e.snapshotMu.Unlock()
+
+			if snap != nil {
+				return snap, nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Crypto.com feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       bookDepth,
+		UpdateInterval: 10 * time.Millisecond,
+		Trades:         true,
+		NativeSymbol:   e.instrument,
+	}
+}
+
+// channel returns the book subscription channel name
+func (e *SpotExchange) channel() string {
+	return fmt.Sprintf("book.%s.%d", e.instrument, bookDepth)
+}
+
+// tradeChannel returns the trade subscription channel name
+func (e *SpotExchange) tradeChannel() string {
+	return "trade." + e.instrument
+}
+
+// subscribe sends the book and trade subscription request
+func (e *SpotExchange) subscribe() error {
+	return e.send(Request{
+		Method: "subscribe",
+		Params: map[string]interface{}{
+			"channels":               []string{e.channel(), e.tradeChannel()},
+			"book_subscription_type": "SNAPSHOT_AND_UPDATE",
+			"book_update_frequency":  10,
+		},
+		Nonce: time.Now().UnixMilli(),
+	})
+}
+
+// send writes a request, assigning an id when none is set
+func (e *SpotExchange) send(req Request) error {
+	if req.ID == 0 {
+		req.ID = atomic.AddInt64(&e.requestID, 1)
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			if msg.Method == "public/heartbeat" {
+				if err := e.send(Request{ID: msg.ID, Method: "public/respond-heartbeat"}); err != nil {
+					e.log().Error("Failed to answer heartbeat", "event", "heartbeat_failed", "error", err)
+				}
+				continue
+			}
+
+			if msg.Code != 0 {
+				e.handleError(&msg)
+				continue
+			}
+
+			if msg.Method != "subscribe" || len(msg.Result) == 0 {
+				continue
+			}
+
+			var result BookResult
+			if err := json.Unmarshal(msg.Result, &result); err != nil {
+				e.log().Error("Failed to parse book data", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			if len(result.Data) == 0 {
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			data := &result.Data[0]
+
+			switch result.Channel {
+			case "trade":
+				e.handleTrades(msg.Result)
+			case "book":
+				e.storeSnapshot(data)
+			case "book.update":
+				if data.Update == nil {
+					continue
+				}
+				canonicalUpdate := e.convertDepthUpdate(data)
+
+				select {
+				case e.updateChan <- canonicalUpdate:
+				case <-e.ctx.Done():
+					return
+				case <-e.done:
+					return
+				default:
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+				}
+			}
+		}
+	}
+}
+
+// handleError logs error responses and retries rate-limited subscriptions with backoff
+func (e *SpotExchange) handleError(msg *WSMessage) {
+	e.incrementErrorCount()
+
+	if msg.Code != codeTooManyRequest || msg.Method != "subscribe" {
+		e.log().Error("Request error", "event", "request_failed", "code", msg.Code, "error", msg.Message)
+		return
+	}
+
+	e.subscribeTry++
+	if e.subscribeTry > maxSubscribeRetry {
+		e.log().Error("Subscription rate limited, giving up", "event", "subscribe_failed", "attempts", maxSubscribeRetry)
+		return
+	}
+
+	backoff := time.Duration(e.subscribeTry) * connectDelay
+	e.log().Warn("Subscription rate limited, retrying", "event", "subscribe_retry", "backoff", backoff)
+
+	time.AfterFunc(backoff, func() {
+		select {
+		case <-e.done:
+			return
+		default:
+		}
+		if err := e.subscribe(); err != nil {
+			e.log().Error("Failed to resubscribe", "event", "resubscribe_failed", "error", err)
+		}
+	})
+}
+
+// handleTrades forwards the trades of a trade subscription message
+func (e *SpotExchange) handleTrades(raw json.RawMessage) {
+	var result TradeResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+		return
+	}
+
+	for _, trade := range result.Data {
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    result.InstrumentName,
+			TradeID:   trade.TradeID,
+			Price:     trade.Price,
+			Quantity:  trade.Quantity,
+			Side:      exchange.TradeSide(strings.ToLower(trade.Side)),
+			Timestamp: time.UnixMilli(trade.Time),
+		})
+	}
+}
+
+// storeSnapshot converts and stores the latest book snapshot
+// Crypto.com may push a fresh snapshot at any time, so the newest one is kept
+func (e *SpotExchange) storeSnapshot(data *BookData) {
+	snapshot := &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.instrument,
+		LastUpdateID: data.UpdateID,
+		Bids:         convertLevels(data.Bids),
+		Asks:         convertLevels(data.Asks),
+		Timestamp:    time.UnixMilli(data.Time),
+	}
+
+	e.snapshotMu.Lock()
+	e.snapshot = snapshot
+	e.snapshotMu.Unlock()
+}
+
+// convertDepthUpdate converts Crypto.com book delta to canonical format
+func (e *SpotExchange) convertDepthUpdate(data *BookData) *exchange.DepthUpdate {
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        e.instrument,
+		EventTime:     time.UnixMilli(data.Time),
+		FirstUpdateID: data.PrevUpdateID + 1,
+		FinalUpdateID: data.UpdateID,
+		PrevUpdateID:  data.PrevUpdateID,
+		Bids:          convertLevels(data.Update.Bids),
+		Asks:          convertLevels(data.Update.Asks),
+	}
+}
+
+// convertLevels converts [price, quantity, count] entries to canonical price levels
+func convertLevels(levels [][]string) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) >= 2 {
+			result = append(result, exchange.PriceLevel{
+				Price:    level[0],
+				Quantity: level[1],
+			})
+		}
+	}
+	return result
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,66 @@
+package cryptocom
+
+import "encoding/json"
+
+// Config holds configuration for Crypto.com exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC_USDT), as resolved by symbols.Native
+}
+
+// Request represents a request sent to the Crypto.com market data WebSocket
+type Request struct {
+	ID     int64                  `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	Nonce  int64                  `json:"nonce,omitempty"`
+}
+
+// WSMessage represents a WebSocket message from Crypto.com
+type WSMessage struct {
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"` // "subscribe" or "public/heartbeat"
+	Code    int             `json:"code"`
+	Message string          `json:"message,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+}
+
+// BookResult represents the result of a book subscription message
+type BookResult struct {
+	InstrumentName string     `json:"instrument_name"`
+	Subscription   string     `json:"subscription"`
+	Channel        string     `json:"channel"` // "book" for snapshots, "book.update" for deltas
+	Depth          int        `json:"depth"`
+	Data           []BookData `json:"data"`
+}
+
+// BookData represents a single book snapshot or delta
+type BookData struct {
+	Bids         [][]string  `json:"bids"`   // [price, quantity, order_count] (snapshots only)
+	Asks         [][]string  `json:"asks"`   // [price, quantity, order_count] (snapshots only)
+	Update       *BookUpdate `json:"update"` // Deltas only
+	Time         int64       `json:"t"`
+	UpdateID     int64       `json:"u"`
+	PrevUpdateID int64       `json:"pu"` // Deltas only
+}
+
+// BookUpdate holds the changed levels of a delta
+type BookUpdate struct {
+	Bids [][]string `json:"bids"`
+	Asks [][]string `json:"asks"`
+}
+
+// TradeResult represents the result of a trade subscription message
+type TradeResult struct {
+	InstrumentName string      `json:"instrument_name"`
+	Channel        string      `json:"channel"` // "trade"
+	Data           []TradeData `json:"data"`
+}
+
+// TradeData represents a single trade
+type TradeData struct {
+	TradeID  string `json:"d"`
+	Time     int64  `json:"t"`
+	Price    string `json:"p"`
+	Quantity string `json:"q"`
+	Side     string `json:"s"` // Taker side: "BUY" or "SELL"
+}
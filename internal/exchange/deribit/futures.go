@@ -0,0 +1,443 @@
+package deribit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+const (
+	wsURL             = "wss://www.deribit.com/ws/api/v2"
+	restBaseURL       = "https://www.deribit.com/api/v2/public/get_order_book"
+	heartbeatInterval = 30 // seconds, minimum allowed by Deribit is 10
+)
+
+// FuturesExchange implements the Exchange interface for Deribit derivatives
+type FuturesExchange struct {
+	symbol     string
+	instrument string // Deribit format (e.g., BTC-PERPETUAL)
+	restURL    string
+	wsConn     *websocket.Conn
+	writeMu    sync.Mutex // Serializes writes from Connect, the heartbeat replies and Close
+	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
+	done       chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	health     atomic.Value // stores exchange.HealthStatus
+	requestID  int64
+}
+
+// NewFuturesExchange creates a new Deribit exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	instrument := config.Symbol
+	restURL := fmt.Sprintf("%s?instrument_name=%s&depth=10000", restBaseURL, url.QueryEscape(instrument))
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		instrument: instrument,
+		restURL:    restURL,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.Deribitf
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Deribit
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	// Deribit closes idle connections unless heartbeats are enabled and answered
+	if err := e.sendRequest("public/set_heartbeat", map[string]interface{}{
+		"interval": heartbeatInterval,
+	}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to enable heartbeat: %w", err)
+	}
+
+	channel := fmt.Sprintf("book.%s.100ms", e.instrument)
+	tradesChannel := fmt.Sprintf("trades.%s.100ms", e.instrument)
+	if err := e.sendRequest("public/subscribe", map[string]interface{}{
+		"channels": []string{channel, tradesChannel},
+	}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", channel, "trade_channel", tradesChannel)
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the orderbook snapshot via REST API
+// The snapshot change_id lines up with prev_change_id of the WebSocket stream
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var deribitResp OrderBookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&deribitResp); err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	if deribitResp.Error != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("API error: code=%d, msg=%s", deribitResp.Error.Code, deribitResp.Error.Message)
+	}
+
+	snapshot := e.convertSnapshot(&deribitResp.Result)
+	return snapshot, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Deribit perpetual feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		UpdateInterval: 100 * time.Millisecond,
+		Trades:         true,
+		NativeSymbol:   e.instrument,
+	}
+}
+
+// sendRequest sends a JSON-RPC request over the WebSocket
+func (e *FuturesExchange) sendRequest(method string, params map[string]interface{}) error {
+	req := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddInt64(&e.requestID, 1),
+		Method:  method,
+		Params:  params,
+	}
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			if msg.Error != nil {
+				e.incrementErrorCount()
+				e.log().Error("RPC error", "event", "request_failed", "code", msg.Error.Code, "error", msg.Error.Message)
+				continue
+			}
+
+			switch msg.Method {
+			case "heartbeat":
+				e.handleHeartbeat(msg.Params)
+			case "subscription":
+				var params SubscriptionParams
+				if err := json.Unmarshal(msg.Params, &params); err != nil {
+					e.log().Error("Failed to parse subscription data", "event", "parse_failed", "error", err)
+					continue
+				}
+
+				if strings.HasPrefix(params.Channel, "trades.") {
+					e.handleTrades(params.Data)
+					continue
+				}
+
+				var book BookData
+				if err := json.Unmarshal(params.Data, &book); err != nil {
+					e.log().Error("Failed to parse book data", "event", "parse_failed", "error", err)
+					continue
+				}
+
+				// The initial "snapshot" notification is superseded by the REST snapshot
+				if book.Type != "change" {
+					continue
+				}
+
+				canonicalUpdate := e.convertDepthUpdate(&book)
+
+				select {
+				case e.updateChan <- canonicalUpdate:
+				case <-e.ctx.Done():
+					return
+				case <-e.done:
+					return
+				default:
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+				}
+			}
+		}
+	}
+}
+
+// handleTrades forwards the trades of a trades notification
+func (e *FuturesExchange) handleTrades(raw json.RawMessage) {
+	var trades []TradeData
+	if err := json.Unmarshal(raw, &trades); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+		return
+	}
+
+	for _, trade := range trades {
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.InstrumentName,
+			TradeID:   trade.TradeID,
+			Price:     fmt.Sprintf("%.10f", trade.Price),
+			Quantity:  fmt.Sprintf("%.10f", trade.Amount),
+			Side:      exchange.TradeSide(trade.Direction),
+			Timestamp: time.UnixMilli(trade.Timestamp),
+		})
+	}
+}
+
+// handleHeartbeat answers test_request heartbeats so the server keeps the connection open
+func (e *FuturesExchange) handleHeartbeat(raw json.RawMessage) {
+	var params HeartbeatParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return
+	}
+
+	if params.Type == "test_request" {
+		if err := e.sendRequest("public/test", nil); err != nil {
+			e.log().Error("Failed to answer heartbeat", "event", "heartbeat_failed", "error", err)
+		}
+	}
+}
+
+// convertSnapshot converts Deribit REST snapshot to canonical format
+func (e *FuturesExchange) convertSnapshot(data *OrderBookData) *exchange.Snapshot {
+	bids := make([]exchange.PriceLevel, 0, len(data.Bids))
+	for _, bid := range data.Bids {
+		if len(bid) >= 2 {
+			bids = append(bids, exchange.PriceLevel{
+				Price:    fmt.Sprintf("%.10f", bid[0]),
+				Quantity: fmt.Sprintf("%.10f", bid[1]),
+			})
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, 0, len(data.Asks))
+	for _, ask := range data.Asks {
+		if len(ask) >= 2 {
+			asks = append(asks, exchange.PriceLevel{
+				Price:    fmt.Sprintf("%.10f", ask[0]),
+				Quantity: fmt.Sprintf("%.10f", ask[1]),
+			})
+		}
+	}
+
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.instrument,
+		LastUpdateID: data.ChangeID,
+		Bids:         bids,
+		Asks:         asks,
+		Timestamp:    time.UnixMilli(data.Timestamp),
+	}
+}
+
+// convertDepthUpdate converts Deribit book change to canonical format
+// change_id values are not contiguous, so FirstUpdateID is derived from
+// prev_change_id to let the orderbook's overlap check bridge the snapshot
+func (e *FuturesExchange) convertDepthUpdate(data *BookData) *exchange.DepthUpdate {
+	bids := make([]exchange.PriceLevel, len(data.Bids))
+	for i, bid := range data.Bids {
+		bids[i] = convertBookChange(bid)
+	}
+
+	asks := make([]exchange.PriceLevel, len(data.Asks))
+	for i, ask := range data.Asks {
+		asks[i] = convertBookChange(ask)
+	}
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        data.InstrumentName,
+		EventTime:     time.UnixMilli(data.Timestamp),
+		FirstUpdateID: data.PrevChangeID + 1,
+		FinalUpdateID: data.ChangeID,
+		PrevUpdateID:  data.PrevChangeID,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+// convertBookChange converts a single Deribit book entry, mapping deletes to zero quantity
+func convertBookChange(change BookChange) exchange.PriceLevel {
+	amount := change.Amount
+	if change.Action == "delete" {
+		amount = 0
+	}
+	return exchange.PriceLevel{
+		Price:    fmt.Sprintf("%.10f", change.Price),
+		Quantity: fmt.Sprintf("%.10f", amount),
+	}
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,108 @@
+package deribit
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Config holds configuration for Deribit exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC-PERPETUAL), as resolved by symbols.Native
+}
+
+// JSONRPCRequest represents a JSON-RPC 2.0 request sent over the Deribit WebSocket
+type JSONRPCRequest struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      int64                  `json:"id"`
+	Method  string                 `json:"method"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
+
+// WSMessage represents a generic JSON-RPC message received from Deribit
+type WSMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"` // "subscription" or "heartbeat" for notifications
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError represents a JSON-RPC error object
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// SubscriptionParams holds the params of a subscription notification; Data is decoded by channel
+type SubscriptionParams struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// HeartbeatParams holds the params of a heartbeat notification
+type HeartbeatParams struct {
+	Type string `json:"type"` // "heartbeat" or "test_request"
+}
+
+// BookData represents a book.{instrument}.{interval} notification
+type BookData struct {
+	Type           string       `json:"type"` // "snapshot" or "change"
+	Timestamp      int64        `json:"timestamp"`
+	InstrumentName string       `json:"instrument_name"`
+	ChangeID       int64        `json:"change_id"`
+	PrevChangeID   int64        `json:"prev_change_id"` // Only present in "change" messages
+	Bids           []BookChange `json:"bids"`
+	Asks           []BookChange `json:"asks"`
+}
+
+// TradeData represents a trade of a trades.{instrument}.{interval} notification
+type TradeData struct {
+	TradeID        string  `json:"trade_id"`
+	InstrumentName string  `json:"instrument_name"`
+	Timestamp      int64   `json:"timestamp"`
+	Price          float64 `json:"price"`
+	Amount         float64 `json:"amount"`    // Same units as book amounts (USD for inverse contracts)
+	Direction      string  `json:"direction"` // Taker side: "buy" or "sell"
+}
+
+// BookChange represents a single ["new"|"change"|"delete", price, amount] entry
+type BookChange struct {
+	Action string
+	Price  float64
+	Amount float64
+}
+
+// UnmarshalJSON decodes the heterogeneous array format used by Deribit
+func (c *BookChange) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("unexpected book entry length %d", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &c.Action); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &c.Price); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &c.Amount)
+}
+
+// OrderBookResponse represents the REST API response for public/get_order_book
+type OrderBookResponse struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Result  OrderBookData `json:"result"`
+	Error   *RPCError     `json:"error,omitempty"`
+}
+
+// OrderBookData represents the orderbook data in the REST response
+type OrderBookData struct {
+	InstrumentName string      `json:"instrument_name"`
+	Timestamp      int64       `json:"timestamp"`
+	ChangeID       int64       `json:"change_id"`
+	Bids           [][]float64 `json:"bids"` // [price, amount]
+	Asks           [][]float64 `json:"asks"` // [price, amount]
+}
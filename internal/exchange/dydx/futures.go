@@ -0,0 +1,463 @@
+package dydx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsURL = "wss://indexer.dydx.trade/v4/ws"
+)
+
+// FuturesExchange implements the Exchange interface for dYdX v4 perpetuals
+type FuturesExchange struct {
+	symbol           string
+	market           string // dYdX format (e.g., BTC-USD)
+	wsConn           *websocket.Conn
+	writeMu          sync.Mutex // Serializes writes from Connect, Resync and Close
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	snapshotReceived bool         // Guarded by snapshotMu, cleared by Resync
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+	lastMessageID    int64 // Latest message_id on the connection, of any channel
+	lastBookID       int64 // message_id of the latest orderbook message
+}
+
+// NewFuturesExchange creates a new dYdX v4 exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	market := config.Symbol
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		market:     market,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.Dydxf
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to the dYdX indexer
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	subscribeMsg := SubscribeRequest{
+		Type:    "subscribe",
+		Channel: "v4_orderbook",
+		ID:      e.market,
+	}
+
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	subscribeMsg.Channel = "v4_trades"
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "v4_orderbook", "trade_channel", "v4_trades")
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the initial orderbook snapshot via WebSocket
+// For dYdX, the "subscribed" message carries the full book
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			snap := e.snapshot
+			e.snapshotMu.Unlock()
+
+			if snap != nil {
+				return snap, nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Resync resubscribes to the orderbook so the indexer sends a fresh book in a new
+// "subscribed" message, which the next GetSnapshot waits for
+func (e *FuturesExchange) Resync() error {
+	e.snapshotMu.Lock()
+	e.snapshot = nil
+	e.snapshotReceived = false
+	e.snapshotMu.Unlock()
+
+	if err := e.sendOrderbook("unsubscribe"); err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	if err := e.sendOrderbook("subscribe"); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Resubscribed", "event", "resubscribed", "channel", "v4_orderbook")
+	return nil
+}
+
+// sendOrderbook sends a v4_orderbook request of the given type
+func (e *FuturesExchange) sendOrderbook(requestType string) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(SubscribeRequest{
+		Type:    requestType,
+		Channel: "v4_orderbook",
+		ID:      e.market,
+	})
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the dYdX perpetual feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.market,
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			// Orderbook continuity is checked against message IDs, which count the messages of
+			// every channel, so a gap is a message missing from the connection
+			contiguous := msg.MessageID == e.lastMessageID+1
+			e.lastMessageID = msg.MessageID
+
+			if msg.Type == "error" {
+				e.incrementErrorCount()
+				e.log().Error("Indexer error", "event", "request_failed", "error", msg.Message)
+				continue
+			}
+
+			if msg.Channel == "v4_trades" {
+				e.incrementMessageCount()
+				e.updateLastPing()
+				if msg.Type == "channel_data" {
+					e.handleTrades(msg.Contents)
+				}
+				continue
+			}
+
+			if msg.Channel != "v4_orderbook" {
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			switch msg.Type {
+			case "subscribed":
+				// A book follows every subscribe, including the one sent by Resync
+				e.snapshotMu.Lock()
+				received := e.snapshotReceived
+				e.snapshotMu.Unlock()
+				if received {
+					continue
+				}
+				var contents SnapshotContents
+				if err := json.Unmarshal(msg.Contents, &contents); err != nil {
+					e.log().Error("Failed to parse snapshot", "event", "parse_failed", "error", err)
+					continue
+				}
+				e.storeSnapshot(&contents, msg.MessageID)
+				e.lastBookID = msg.MessageID
+
+			case "channel_data":
+				var contents UpdateContents
+				if err := json.Unmarshal(msg.Contents, &contents); err != nil {
+					e.log().Error("Failed to parse update", "event", "parse_failed", "error", err)
+					continue
+				}
+
+				prevID := e.lastBookID
+				if !contiguous {
+					prevID = msg.MessageID - 1
+				}
+				e.lastBookID = msg.MessageID
+
+				canonicalUpdate := e.convertDepthUpdate(&contents, prevID, msg.MessageID)
+
+				select {
+				case e.updateChan <- canonicalUpdate:
+				case <-e.ctx.Done():
+					return
+				case <-e.done:
+					return
+				default:
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+				}
+			}
+		}
+	}
+}
+
+// handleTrades forwards the trades of a v4_trades update
+func (e *FuturesExchange) handleTrades(raw json.RawMessage) {
+	var contents TradesContents
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+		return
+	}
+
+	for _, trade := range contents.Trades {
+		timestamp, _ := time.Parse(time.RFC3339Nano, trade.CreatedAt)
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    e.market,
+			TradeID:   trade.ID,
+			Price:     trade.Price,
+			Quantity:  trade.Size,
+			Side:      exchange.TradeSide(strings.ToLower(trade.Side)),
+			Timestamp: timestamp,
+		})
+	}
+}
+
+// storeSnapshot converts and stores the initial snapshot
+func (e *FuturesExchange) storeSnapshot(contents *SnapshotContents, messageID int64) {
+	bids := make([]exchange.PriceLevel, len(contents.Bids))
+	for i, bid := range contents.Bids {
+		bids[i] = exchange.PriceLevel{
+			Price:    bid.Price,
+			Quantity: bid.Size,
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, len(contents.Asks))
+	for i, ask := range contents.Asks {
+		asks[i] = exchange.PriceLevel{
+			Price:    ask.Price,
+			Quantity: ask.Size,
+		}
+	}
+
+	snapshot := &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.market,
+		LastUpdateID: messageID,
+		Bids:         bids,
+		Asks:         asks,
+		Timestamp:    time.Now(),
+	}
+
+	e.snapshotMu.Lock()
+	e.snapshot = snapshot
+	e.snapshotReceived = true
+	e.snapshotMu.Unlock()
+}
+
+// convertDepthUpdate converts dYdX orderbook update to canonical format
+// message_id increments by one per message on the connection, so an update covers
+// the IDs since the previous orderbook message, trades messages in between included
+func (e *FuturesExchange) convertDepthUpdate(contents *UpdateContents, prevID, messageID int64) *exchange.DepthUpdate {
+	bids := make([]exchange.PriceLevel, 0, len(contents.Bids))
+	for _, bid := range contents.Bids {
+		if len(bid) >= 2 {
+			bids = append(bids, exchange.PriceLevel{
+				Price:    bid[0],
+				Quantity: bid[1],
+			})
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, 0, len(contents.Asks))
+	for _, ask := range contents.Asks {
+		if len(ask) >= 2 {
+			asks = append(asks, exchange.PriceLevel{
+				Price:    ask[0],
+				Quantity: ask[1],
+			})
+		}
+	}
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        e.market,
+		EventTime:     time.Now(),
+		FirstUpdateID: prevID + 1,
+		FinalUpdateID: messageID,
+		PrevUpdateID:  prevID,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,59 @@
+package dydx
+
+import "encoding/json"
+
+// Config holds configuration for dYdX exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC-USD), as resolved by symbols.Native
+}
+
+// SubscribeRequest represents a subscription request to the dYdX v4 indexer WebSocket
+type SubscribeRequest struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	ID      string `json:"id"`
+}
+
+// WSMessage represents a WebSocket message from the dYdX indexer
+type WSMessage struct {
+	Type         string          `json:"type"` // "connected", "subscribed", "channel_data", "error"
+	ConnectionID string          `json:"connection_id"`
+	MessageID    int64           `json:"message_id"`
+	Channel      string          `json:"channel"`
+	ID           string          `json:"id"`
+	Version      string          `json:"version"`
+	Message      string          `json:"message,omitempty"` // Only present in "error" messages
+	Contents     json.RawMessage `json:"contents"`
+}
+
+// SnapshotContents represents the initial orderbook sent with the "subscribed" message
+type SnapshotContents struct {
+	Bids []SnapshotLevel `json:"bids"`
+	Asks []SnapshotLevel `json:"asks"`
+}
+
+// SnapshotLevel represents a price level in the initial snapshot
+type SnapshotLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// UpdateContents represents an incremental orderbook update
+type UpdateContents struct {
+	Bids [][]string `json:"bids"` // [price, size]
+	Asks [][]string `json:"asks"` // [price, size]
+}
+
+// TradesContents represents the trades of a v4_trades message
+type TradesContents struct {
+	Trades []Trade `json:"trades"`
+}
+
+// Trade represents a single trade on the v4_trades channel
+type Trade struct {
+	ID        string `json:"id"`
+	Side      string `json:"side"` // Taker side: "BUY" or "SELL"
+	Size      string `json:"size"`
+	Price     string `json:"price"`
+	CreatedAt string `json:"createdAt"`
+}
@@ -0,0 +1,52 @@
+package exchange
+
+import (
+	"sync"
+
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// SendFunding hands funding to the funding channel of an adapter without blocking its read
+// loop; updates are dropped while the channel is full, the next one carries every field
+func SendFunding(name ExchangeName, funding chan<- *Funding, update *Funding) {
+	select {
+	case funding <- update:
+	default:
+		logging.For(string(name), update.Symbol).Warn("Funding channel full, skipping update", "event", "funding_dropped")
+	}
+}
+
+// FundingState merges the partial funding updates of a venue, which may arrive on several
+// channels or from polled endpoints at once
+type FundingState struct {
+	mu     sync.Mutex
+	latest Funding
+}
+
+// Merge applies the non-empty fields of update and returns a copy of the merged funding
+func (s *FundingState) Merge(update Funding) *Funding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	latest := &s.latest
+	latest.Exchange, latest.Symbol = update.Exchange, update.Symbol
+	for _, field := range []struct{ from, to *string }{
+		{&update.Rate, &latest.Rate},
+		{&update.MarkPrice, &latest.MarkPrice},
+		{&update.IndexPrice, &latest.IndexPrice},
+		{&update.OpenInterest, &latest.OpenInterest},
+	} {
+		if *field.from != "" {
+			*field.to = *field.from
+		}
+	}
+	if !update.NextFunding.IsZero() {
+		latest.NextFunding = update.NextFunding
+	}
+	if update.Timestamp.After(latest.Timestamp) {
+		latest.Timestamp = update.Timestamp
+	}
+
+	merged := *latest
+	return &merged
+}
@@ -0,0 +1,28 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFundingStateMerge(t *testing.T) {
+	var state FundingState
+	now := time.Now()
+
+	state.Merge(Funding{Exchange: Bybitf, Symbol: "BTCUSDT", Rate: "0.0001", MarkPrice: "100", NextFunding: now.Add(time.Hour), Timestamp: now})
+	merged := state.Merge(Funding{Exchange: Bybitf, Symbol: "BTCUSDT", OpenInterest: "52.5", Timestamp: now.Add(time.Second)})
+
+	// Fields the second update left empty keep their values
+	if merged.Rate != "0.0001" || merged.MarkPrice != "100" || merged.OpenInterest != "52.5" || merged.IndexPrice != "" {
+		t.Errorf("unexpected merged funding %+v", merged)
+	}
+	if !merged.NextFunding.Equal(now.Add(time.Hour)) || !merged.Timestamp.Equal(now.Add(time.Second)) {
+		t.Errorf("unexpected merged times %+v", merged)
+	}
+
+	// Merged copies do not change with later updates
+	state.Merge(Funding{Exchange: Bybitf, Symbol: "BTCUSDT", Rate: "-0.0002"})
+	if merged.Rate != "0.0001" {
+		t.Errorf("merged copy changed to rate %s", merged.Rate)
+	}
+}
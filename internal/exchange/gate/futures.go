@@ -0,0 +1,416 @@
+package gate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+const (
+	futuresWsURL   = "wss://fx-ws.gateio.ws/v4/ws/usdt"
+	futuresRestURL = "https://api.gateio.ws/api/v4/futures/usdt/order_book"
+)
+
+// FuturesExchange implements the Exchange interface for Gate.io USDT Perpetual Futures
+type FuturesExchange struct {
+	symbol     string
+	contract   string // Gate.io format (e.g., BTC_USDT)
+	restURL    string
+	wsConn     *websocket.Conn
+	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
+	done       chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	health     atomic.Value // stores exchange.HealthStatus
+	book       bookSync
+}
+
+// NewFuturesExchange creates a new Gate.io Futures exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	contract := config.Symbol
+	restURL := fmt.Sprintf("%s?contract=%s&limit=100&with_id=true", futuresRestURL, contract)
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		contract:   contract,
+		restURL:    restURL,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.Gatef
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Gate.io USDT Perpetual Futures
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, futuresWsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	subscribeMsg := SubscribeRequest{
+		Time:    time.Now().Unix(),
+		Channel: "futures.order_book_update",
+		Event:   "subscribe",
+		Payload: []string{e.contract, "100ms", "100"},
+	}
+
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	tradesMsg := SubscribeRequest{
+		Time:    time.Now().Unix(),
+		Channel: "futures.trades",
+		Event:   "subscribe",
+		Payload: []string{e.contract},
+	}
+
+	if err := conn.WriteJSON(tradesMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "futures.order_book_update", "trade_channel", "futures.trades")
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the orderbook snapshot via REST API and resets the
+// local sequence baseline to the snapshot id
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var gateSnapshot FuturesSnapshotResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gateSnapshot); err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	if gateSnapshot.ID == 0 {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("snapshot missing update id")
+	}
+
+	e.book.reset(gateSnapshot.ID)
+
+	snapshot := e.convertSnapshot(&gateSnapshot)
+	return snapshot, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Gate.io Futures feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       100,
+		UpdateInterval: 100 * time.Millisecond,
+		Trades:         true,
+		NativeSymbol:   e.contract,
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			if msg.Error != nil {
+				e.incrementErrorCount()
+				e.log().Error("Subscription error", "event", "subscribe_failed", "code", msg.Error.Code, "error", msg.Error.Message)
+				continue
+			}
+
+			if msg.Channel == "futures.trades" && msg.Event == "update" {
+				e.incrementMessageCount()
+				e.updateLastPing()
+				e.handleTrades(msg.Result)
+				continue
+			}
+
+			if msg.Channel != "futures.order_book_update" || msg.Event != "update" {
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			var update FuturesDepthUpdate
+			if err := json.Unmarshal(msg.Result, &update); err != nil {
+				e.log().Error("Failed to parse update", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			result, prevID := e.book.check(update.FirstUpdateID, update.FinalUpdateID)
+			switch result {
+			case syncStale:
+				continue
+			case syncGap:
+				e.incrementErrorCount()
+				e.log().Warn("Sequence gap, waiting for resync", "event", "sequence_gap", "first_update_id", update.FirstUpdateID, "prev_update_id", prevID, "gaps", e.book.gaps())
+			}
+
+			canonicalUpdate := e.convertDepthUpdate(&update, prevID)
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrades forwards the trades of a futures.trades event
+func (e *FuturesExchange) handleTrades(result json.RawMessage) {
+	var trades []FuturesTrade
+	if err := json.Unmarshal(result, &trades); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+		return
+	}
+
+	for _, trade := range trades {
+		side, size := exchange.TakerBuy, trade.Size
+		if size < 0 {
+			side, size = exchange.TakerSell, -size
+		}
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.Contract,
+			TradeID:   strconv.FormatInt(trade.ID, 10),
+			Price:     trade.Price,
+			Quantity:  strconv.FormatInt(size, 10),
+			Side:      side,
+			Timestamp: time.UnixMilli(trade.CreateTimeMs),
+		})
+	}
+}
+
+// convertSnapshot converts Gate.io futures snapshot to canonical format
+// Quantities are reported in contracts
+func (e *FuturesExchange) convertSnapshot(snapshot *FuturesSnapshotResponse) *exchange.Snapshot {
+	bids := make([]exchange.PriceLevel, len(snapshot.Bids))
+	for i, bid := range snapshot.Bids {
+		bids[i] = exchange.PriceLevel{
+			Price:    bid.Price,
+			Quantity: bid.SizeString(),
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, len(snapshot.Asks))
+	for i, ask := range snapshot.Asks {
+		asks[i] = exchange.PriceLevel{
+			Price:    ask.Price,
+			Quantity: ask.SizeString(),
+		}
+	}
+
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.contract,
+		LastUpdateID: snapshot.ID,
+		Bids:         bids,
+		Asks:         asks,
+		Timestamp:    time.UnixMilli(int64(snapshot.Current * 1000)),
+	}
+}
+
+// convertDepthUpdate converts Gate.io futures depth update to canonical format
+func (e *FuturesExchange) convertDepthUpdate(update *FuturesDepthUpdate, prevID int64) *exchange.DepthUpdate {
+	bids := make([]exchange.PriceLevel, len(update.Bids))
+	for i, bid := range update.Bids {
+		bids[i] = exchange.PriceLevel{
+			Price:    bid.Price,
+			Quantity: bid.SizeString(),
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, len(update.Asks))
+	for i, ask := range update.Asks {
+		asks[i] = exchange.PriceLevel{
+			Price:    ask.Price,
+			Quantity: ask.SizeString(),
+		}
+	}
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        update.Contract,
+		EventTime:     time.UnixMilli(update.Time),
+		FirstUpdateID: update.FirstUpdateID,
+		FinalUpdateID: update.FinalUpdateID,
+		PrevUpdateID:  prevID,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,418 @@
+package gate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+const (
+	spotWsURL   = "wss://api.gateio.ws/ws/v4/"
+	spotRestURL = "https://api.gateio.ws/api/v4/spot/order_book"
+)
+
+// SpotExchange implements the Exchange interface for Gate.io Spot
+type SpotExchange struct {
+	symbol     string
+	pair       string // Gate.io format (e.g., BTC_USDT)
+	restURL    string
+	wsConn     *websocket.Conn
+	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
+	done       chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	health     atomic.Value // stores exchange.HealthStatus
+	book       bookSync
+}
+
+// NewSpotExchange creates a new Gate.io Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pair := config.Symbol
+	restURL := fmt.Sprintf("%s?currency_pair=%s&limit=1000&with_id=true", spotRestURL, pair)
+
+	ex := &SpotExchange{
+		symbol:     config.Symbol,
+		pair:       pair,
+		restURL:    restURL,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.Gate
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Gate.io Spot
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, spotWsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	subscribeMsg := SubscribeRequest{
+		Time:    time.Now().Unix(),
+		Channel: "spot.order_book_update",
+		Event:   "subscribe",
+		Payload: []string{e.pair, "100ms"},
+	}
+
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	tradesMsg := SubscribeRequest{
+		Time:    time.Now().Unix(),
+		Channel: "spot.trades",
+		Event:   "subscribe",
+		Payload: []string{e.pair},
+	}
+
+	if err := conn.WriteJSON(tradesMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "spot.order_book_update", "trade_channel", "spot.trades")
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the orderbook snapshot via REST API and resets the
+// local sequence baseline to the snapshot id
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var gateSnapshot SpotSnapshotResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gateSnapshot); err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	if gateSnapshot.ID == 0 {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("snapshot missing update id")
+	}
+
+	e.book.reset(gateSnapshot.ID)
+
+	snapshot := e.convertSnapshot(&gateSnapshot)
+	return snapshot, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Gate.io Spot feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       1000,
+		UpdateInterval: 100 * time.Millisecond,
+		Trades:         true,
+		NativeSymbol:   e.pair,
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			if msg.Error != nil {
+				e.incrementErrorCount()
+				e.log().Error("Subscription error", "event", "subscribe_failed", "code", msg.Error.Code, "error", msg.Error.Message)
+				continue
+			}
+
+			if msg.Channel == "spot.trades" && msg.Event == "update" {
+				e.incrementMessageCount()
+				e.updateLastPing()
+				e.handleTrades(msg.Result)
+				continue
+			}
+
+			if msg.Channel != "spot.order_book_update" || msg.Event != "update" {
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			var update SpotDepthUpdate
+			if err := json.Unmarshal(msg.Result, &update); err != nil {
+				e.log().Error("Failed to parse update", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			result, prevID := e.book.check(update.FirstUpdateID, update.FinalUpdateID)
+			switch result {
+			case syncStale:
+				continue
+			case syncGap:
+				e.incrementErrorCount()
+				e.log().Warn("Sequence gap, waiting for resync", "event", "sequence_gap", "first_update_id", update.FirstUpdateID, "prev_update_id", prevID, "gaps", e.book.gaps())
+			}
+
+			canonicalUpdate := e.convertDepthUpdate(&update, prevID)
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrades forwards the trade of a spot.trades event
+func (e *SpotExchange) handleTrades(result json.RawMessage) {
+	var trade SpotTrade
+	if err := json.Unmarshal(result, &trade); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trade", "event", "parse_failed", "error", err)
+		return
+	}
+
+	ms, _ := strconv.ParseFloat(trade.CreateTimeMs, 64)
+	exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.CurrencyPair,
+		TradeID:   strconv.FormatInt(trade.ID, 10),
+		Price:     trade.Price,
+		Quantity:  trade.Amount,
+		Side:      exchange.TradeSide(trade.Side),
+		Timestamp: time.UnixMicro(int64(ms * 1000)),
+	})
+}
+
+// convertSnapshot converts Gate.io spot snapshot to canonical format
+func (e *SpotExchange) convertSnapshot(snapshot *SpotSnapshotResponse) *exchange.Snapshot {
+	bids := make([]exchange.PriceLevel, 0, len(snapshot.Bids))
+	for _, bid := range snapshot.Bids {
+		if len(bid) >= 2 {
+			bids = append(bids, exchange.PriceLevel{
+				Price:    bid[0],
+				Quantity: bid[1],
+			})
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, 0, len(snapshot.Asks))
+	for _, ask := range snapshot.Asks {
+		if len(ask) >= 2 {
+			asks = append(asks, exchange.PriceLevel{
+				Price:    ask[0],
+				Quantity: ask[1],
+			})
+		}
+	}
+
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.pair,
+		LastUpdateID: snapshot.ID,
+		Bids:         bids,
+		Asks:         asks,
+		Timestamp:    time.UnixMilli(snapshot.Current),
+	}
+}
+
+// convertDepthUpdate converts Gate.io spot depth update to canonical format
+func (e *SpotExchange) convertDepthUpdate(update *SpotDepthUpdate, prevID int64) *exchange.DepthUpdate {
+	bids := make([]exchange.PriceLevel, 0, len(update.Bids))
+	for _, bid := range update.Bids {
+		if len(bid) >= 2 {
+			bids = append(bids, exchange.PriceLevel{
+				Price:    bid[0],
+				Quantity: bid[1],
+			})
+		}
+	}
+
+	asks := make([]exchange.PriceLevel, 0, len(update.Asks))
+	for _, ask := range update.Asks {
+		if len(ask) >= 2 {
+			asks = append(asks, exchange.PriceLevel{
+				Price:    ask[0],
+				Quantity: ask[1],
+			})
+		}
+	}
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        update.Symbol,
+		EventTime:     time.UnixMilli(update.Time),
+		FirstUpdateID: update.FirstUpdateID,
+		FinalUpdateID: update.FinalUpdateID,
+		PrevUpdateID:  prevID,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
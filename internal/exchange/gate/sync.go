@@ -0,0 +1,66 @@
+package gate
+
+import (
+	"sync"
+)
+
+// syncResult describes how an incremental update relates to the local book state
+type syncResult int
+
+const (
+	syncUnsynced syncResult = iota // No snapshot yet, forward as-is
+	syncStale                      // Fully covered by the snapshot, drop
+	syncApply                      // Continuous with the previous update, forward
+	syncGap                        // Updates were missed, book needs a new snapshot
+)
+
+// bookSync implements Gate.io's documented reconciliation rule: after a snapshot
+// with id N, the first applied update must satisfy U <= N+1 <= u, and every
+// following update must start at the previous u+1
+type bookSync struct {
+	mu       sync.Mutex
+	lastID   int64
+	synced   bool
+	gapCount int64
+}
+
+// reset records the id of a freshly fetched snapshot
+func (s *bookSync) reset(snapshotID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID = snapshotID
+	s.synced = true
+}
+
+// check validates the update range [first, final] and returns the validated
+// previous id to report downstream
+func (s *bookSync) check(first, final int64) (syncResult, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.synced {
+		return syncUnsynced, first - 1
+	}
+
+	if final <= s.lastID {
+		return syncStale, s.lastID
+	}
+
+	if first > s.lastID+1 {
+		// Stay unsynced until the next snapshot so downstream sees the discontinuity
+		s.synced = false
+		s.gapCount++
+		return syncGap, first - 1
+	}
+
+	prev := s.lastID
+	s.lastID = final
+	return syncApply, prev
+}
+
+// gaps returns the number of sequence gaps detected
+func (s *bookSync) gaps() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gapCount
+}
@@ -0,0 +1,105 @@
+package gate
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Config holds configuration for Gate.io exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC_USDT), as resolved by symbols.Native
+}
+
+// SubscribeRequest represents a subscription request to Gate.io WebSocket v4
+type SubscribeRequest struct {
+	Time    int64    `json:"time"`
+	Channel string   `json:"channel"`
+	Event   string   `json:"event"`
+	Payload []string `json:"payload"`
+}
+
+// WSMessage represents a generic WebSocket message from Gate.io
+type WSMessage struct {
+	Time    int64           `json:"time"`
+	TimeMs  int64           `json:"time_ms"`
+	Channel string          `json:"channel"`
+	Event   string          `json:"event"` // "subscribe" ack or "update"
+	Error   *WSError        `json:"error,omitempty"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// WSError represents an error returned on the WebSocket
+type WSError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// SpotSnapshotResponse represents the REST API response for spot order book
+type SpotSnapshotResponse struct {
+	ID      int64      `json:"id"`
+	Current int64      `json:"current"` // Response time in ms
+	Update  int64      `json:"update"`  // Last book change time in ms
+	Asks    [][]string `json:"asks"`    // [price, amount]
+	Bids    [][]string `json:"bids"`    // [price, amount]
+}
+
+// SpotDepthUpdate represents a spot.order_book_update event
+type SpotDepthUpdate struct {
+	Time          int64      `json:"t"`
+	Event         string     `json:"e"`
+	EventTime     int64      `json:"E"`
+	Symbol        string     `json:"s"`
+	FirstUpdateID int64      `json:"U"`
+	FinalUpdateID int64      `json:"u"`
+	Bids          [][]string `json:"b"` // [price, amount]
+	Asks          [][]string `json:"a"` // [price, amount]
+}
+
+// SpotTrade represents a spot.trades event
+type SpotTrade struct {
+	ID           int64  `json:"id"`
+	CreateTimeMs string `json:"create_time_ms"` // Milliseconds with a fractional part
+	Side         string `json:"side"`           // Taker side: "buy" or "sell"
+	CurrencyPair string `json:"currency_pair"`
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+}
+
+// FuturesLevel represents a single futures price level {p, s}
+type FuturesLevel struct {
+	Price string `json:"p"`
+	Size  int64  `json:"s"` // Size in contracts
+}
+
+// SizeString returns the contract size as a string
+func (l FuturesLevel) SizeString() string {
+	return strconv.FormatInt(l.Size, 10)
+}
+
+// FuturesSnapshotResponse represents the REST API response for futures order book
+type FuturesSnapshotResponse struct {
+	ID      int64          `json:"id"`
+	Current float64        `json:"current"` // Response time in seconds
+	Update  float64        `json:"update"`  // Last book change time in seconds
+	Asks    []FuturesLevel `json:"asks"`
+	Bids    []FuturesLevel `json:"bids"`
+}
+
+// FuturesDepthUpdate represents a futures.order_book_update event
+type FuturesDepthUpdate struct {
+	Time          int64          `json:"t"`
+	Contract      string         `json:"s"`
+	FirstUpdateID int64          `json:"U"`
+	FinalUpdateID int64          `json:"u"`
+	Bids          []FuturesLevel `json:"b"`
+	Asks          []FuturesLevel `json:"a"`
+}
+
+// FuturesTrade represents a trade of a futures.trades event
+type FuturesTrade struct {
+	ID           int64  `json:"id"`
+	CreateTimeMs int64  `json:"create_time_ms"`
+	Contract     string `json:"contract"`
+	Size         int64  `json:"size"` // Contracts; negative when the taker sold
+	Price        string `json:"price"`
+}
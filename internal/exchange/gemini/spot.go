@@ -0,0 +1,347 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+// SpotExchange implements the Exchange interface for Gemini Spot
+// The first l2_updates message after subscribing is the full book; later ones
+// are deltas. Gemini v2 carries no sequence numbers, so messages are numbered
+// locally in arrival order
+type SpotExchange struct {
+	symbol           string
+	pair             string // Gemini format (e.g., BTCUSD)
+	wsURL            string
+	wsConn           *websocket.Conn
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	sequence         int64
+	snapshotMu       sync.Mutex
+	snapshot         *exchange.Snapshot
+	snapshotReceived bool
+}
+
+// NewSpotExchange creates a new Gemini Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &SpotExchange{
+		symbol:     config.Symbol,
+		pair:       config.Symbol,
+		wsURL:      "wss://api.gemini.com/v2/marketdata",
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.Gemini
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Gemini
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	subscribeMsg := SubscribeRequest{
+		Type: "subscribe",
+		Subscriptions: []Subscription{
+			{Name: "l2", Symbols: []string{e.pair}},
+		},
+	}
+
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "l2")
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the orderbook snapshot received from WebSocket
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Gemini feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.pair,
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.updateLastPing()
+
+			if msg.Type == "trade" {
+				e.incrementMessageCount()
+				exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+					Exchange:  e.GetName(),
+					Symbol:    msg.Symbol,
+					TradeID:   strconv.FormatInt(msg.EventID, 10),
+					Price:     msg.Price,
+					Quantity:  msg.Quantity,
+					Side:      exchange.TradeSide(msg.Side),
+					Timestamp: time.UnixMilli(msg.Timestamp),
+				})
+				continue
+			}
+
+			if msg.Type != "l2_updates" {
+				continue
+			}
+
+			e.incrementMessageCount()
+
+			bids, asks := convertChanges(msg.Changes)
+			e.sequence++
+
+			e.snapshotMu.Lock()
+			if !e.snapshotReceived {
+				e.snapshot = &exchange.Snapshot{
+					Exchange:     e.GetName(),
+					Symbol:       e.pair,
+					LastUpdateID: e.sequence,
+					Bids:         bids,
+					Asks:         asks,
+					Timestamp:    time.Now(),
+				}
+				e.snapshotReceived = true
+				e.snapshotMu.Unlock()
+				e.log().Info("Received orderbook snapshot", "event", "snapshot", "bids", len(bids), "asks", len(asks))
+				continue
+			}
+			e.snapshotMu.Unlock()
+
+			canonicalUpdate := &exchange.DepthUpdate{
+				Exchange:      e.GetName(),
+				Symbol:        e.pair,
+				EventTime:     time.Now(),
+				FirstUpdateID: e.sequence,
+				FinalUpdateID: e.sequence,
+				PrevUpdateID:  e.sequence - 1,
+				Bids:          bids,
+				Asks:          asks,
+			}
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// convertChanges splits Gemini [side, price, quantity] changes into bids and asks
+func convertChanges(changes [][]string) ([]exchange.PriceLevel, []exchange.PriceLevel) {
+	bids := make([]exchange.PriceLevel, 0, len(changes))
+	asks := make([]exchange.PriceLevel, 0, len(changes))
+
+	for _, change := range changes {
+		if len(change) < 3 {
+			continue
+		}
+
+		level := exchange.PriceLevel{
+			Price:    change[1],
+			Quantity: change[2],
+		}
+
+		switch change[0] {
+		case "buy":
+			bids = append(bids, level)
+		case "sell":
+			asks = append(asks, level)
+		}
+	}
+
+	return bids, asks
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
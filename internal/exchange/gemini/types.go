@@ -0,0 +1,33 @@
+package gemini
+
+// Config holds configuration for Gemini exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTCUSD), as resolved by symbols.Native
+}
+
+// SubscribeRequest represents a Gemini market data v2 subscription request
+type SubscribeRequest struct {
+	Type          string         `json:"type"` // "subscribe"
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// Subscription names a feed and the symbols to receive it for
+type Subscription struct {
+	Name    string   `json:"name"` // "l2"
+	Symbols []string `json:"symbols"`
+}
+
+// WSMessage represents a WebSocket message from Gemini
+// The l2 feed also carries the trades of its symbols as "trade" messages
+type WSMessage struct {
+	Type    string     `json:"type"` // "l2_updates", "trade", "heartbeat"
+	Symbol  string     `json:"symbol"`
+	Changes [][]string `json:"changes"` // [side, price, quantity], side is "buy" or "sell"
+
+	// Trade fields
+	EventID   int64  `json:"event_id"`
+	Timestamp int64  `json:"timestamp"` // Milliseconds
+	Price     string `json:"price"`
+	Quantity  string `json:"quantity"`
+	Side      string `json:"side"` // Taker side: "buy" or "sell"
+}
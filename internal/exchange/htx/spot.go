@@ -0,0 +1,436 @@
+package htx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+const (
+	wsURL     = "wss://api.huobi.pro/feed"
+	mbpLevels = 150
+)
+
+// SpotExchange implements the Exchange interface for HTX (Huobi) Spot
+type SpotExchange struct {
+	symbol       string
+	htxSymbol    string // HTX format (e.g., btcusdt)
+	channel      string // market.{symbol}.mbp.{levels}
+	tradeChannel string // market.{symbol}.trade.detail
+	wsConn       *websocket.Conn
+	writeMu      sync.Mutex
+	updateChan   chan *exchange.DepthUpdate
+	tradeChan    chan *exchange.Trade
+	snapshotChan chan *exchange.Snapshot
+	done         chan struct{}
+	ctx          context.Context
+	cancel       context.CancelFunc
+	health       atomic.Value // stores exchange.HealthStatus
+	requestID    int64
+}
+
+// NewSpotExchange creates a new HTX Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	htxSymbol := config.Symbol
+
+	ex := &SpotExchange{
+		symbol:       config.Symbol,
+		htxSymbol:    htxSymbol,
+		channel:      fmt.Sprintf("market.%s.mbp.%d", htxSymbol, mbpLevels),
+		tradeChannel: fmt.Sprintf("market.%s.trade.detail", htxSymbol),
+		updateChan:   make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:    make(chan *exchange.Trade, 1000),
+		snapshotChan: make(chan *exchange.Snapshot, 1),
+		done:         make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.HTX
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to HTX
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	subMsg := SubscribeRequest{
+		Sub: e.channel,
+		ID:  e.nextRequestID(),
+	}
+
+	if err := e.writeJSON(subMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	tradeMsg := SubscribeRequest{
+		Sub: e.tradeChannel,
+		ID:  e.nextRequestID(),
+	}
+
+	if err := e.writeJSON(tradeMsg); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", e.channel, "trade_channel", e.tradeChannel)
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot requests the full MBP book over the WebSocket
+// Its seqNum lines up with prevSeqNum of the incremental stream
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Requesting orderbook snapshot", "event", "snapshot_fetch")
+
+	// Discard a stale response left over from a previous timed-out request
+	select {
+	case <-e.snapshotChan:
+	default:
+	}
+
+	req := SnapshotRequest{
+		Req: e.channel,
+		ID:  e.nextRequestID(),
+	}
+
+	if err := e.writeJSON(req); err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to request snapshot: %w", err)
+	}
+
+	select {
+	case snapshot := <-e.snapshotChan:
+		return snapshot, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(10 * time.Second):
+		return nil, fmt.Errorf("timeout waiting for snapshot")
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the HTX feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       mbpLevels,
+		UpdateInterval: 100 * time.Millisecond,
+		Trades:         true,
+		NativeSymbol:   e.htxSymbol,
+	}
+}
+
+// writeJSON serializes writes from the reader (pongs) and snapshot requests
+func (e *SpotExchange) writeJSON(v interface{}) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(v)
+}
+
+// nextRequestID returns a unique id for subscription and request messages
+func (e *SpotExchange) nextRequestID() string {
+	return fmt.Sprintf("id%d", atomic.AddInt64(&e.requestID, 1))
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			_, message, err := exchange.ReadMessage(e.wsConn, e.GetName())
+			if err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			// Every HTX frame is gzip compressed
+			decoded, err := decodeGzip(message)
+			if err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to decode gzip", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			var msg WSMessage
+			if err := json.Unmarshal(decoded, &msg); err != nil {
+				e.log().Error("Failed to parse message", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			if msg.Ping != 0 {
+				if err := e.writeJSON(PongMessage{Pong: msg.Ping}); err != nil {
+					e.log().Error("Failed to send pong", "event", "heartbeat_failed", "error", err)
+				}
+				continue
+			}
+
+			if msg.Status == "error" {
+				e.incrementErrorCount()
+				e.log().Error("Request error", "event", "request_failed", "code", msg.ErrCode, "error", msg.ErrMsg)
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			switch {
+			case msg.Rep == e.channel:
+				e.handleSnapshot(&msg)
+			case msg.Ch == e.tradeChannel:
+				e.handleTrades(decoded)
+			case msg.Ch == e.channel && msg.Tick != nil:
+				canonicalUpdate := e.convertDepthUpdate(msg.Tick, msg.Ts)
+
+				select {
+				case e.updateChan <- canonicalUpdate:
+				case <-e.ctx.Done():
+					return
+				case <-e.done:
+					return
+				default:
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+				}
+			}
+		}
+	}
+}
+
+// handleSnapshot delivers a "req" response to the waiting GetSnapshot call
+func (e *SpotExchange) handleSnapshot(msg *WSMessage) {
+	var data MBPData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		e.log().Error("Failed to parse snapshot", "event", "parse_failed", "error", err)
+		return
+	}
+
+	snapshot := e.convertSnapshot(&data, msg.Ts)
+
+	select {
+	case e.snapshotChan <- snapshot:
+	default:
+		e.log().Warn("Dropping unrequested snapshot", "event", "snapshot_dropped")
+	}
+}
+
+// handleTrades forwards the trades of a trade.detail message
+func (e *SpotExchange) handleTrades(message []byte) {
+	var msg TradeMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+		return
+	}
+
+	for _, trade := range msg.Tick.Data {
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    e.htxSymbol,
+			TradeID:   strconv.FormatInt(trade.TradeID, 10),
+			Price:     fmt.Sprintf("%.10f", trade.Price),
+			Quantity:  fmt.Sprintf("%.10f", trade.Amount),
+			Side:      exchange.TradeSide(trade.Direction),
+			Timestamp: time.UnixMilli(trade.Ts),
+		})
+	}
+}
+
+// convertSnapshot converts HTX MBP snapshot to canonical format
+func (e *SpotExchange) convertSnapshot(data *MBPData, ts int64) *exchange.Snapshot {
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.htxSymbol,
+		LastUpdateID: data.SeqNum,
+		Bids:         convertLevels(data.Bids),
+		Asks:         convertLevels(data.Asks),
+		Timestamp:    time.UnixMilli(ts),
+	}
+}
+
+// convertDepthUpdate converts HTX MBP increment to canonical format
+func (e *SpotExchange) convertDepthUpdate(data *MBPData, ts int64) *exchange.DepthUpdate {
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        e.htxSymbol,
+		EventTime:     time.UnixMilli(ts),
+		FirstUpdateID: data.PrevSeqNum + 1,
+		FinalUpdateID: data.SeqNum,
+		PrevUpdateID:  data.PrevSeqNum,
+		Bids:          convertLevels(data.Bids),
+		Asks:          convertLevels(data.Asks),
+	}
+}
+
+// convertLevels converts numeric [price, size] pairs to canonical price levels
+func convertLevels(levels [][]float64) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) >= 2 {
+			result = append(result, exchange.PriceLevel{
+				Price:    fmt.Sprintf("%.10f", level[0]),
+				Quantity: fmt.Sprintf("%.10f", level[1]),
+			})
+		}
+	}
+	return result
+}
+
+// decodeGzip decompresses gzip-encoded data
+func decodeGzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,65 @@
+package htx
+
+import "encoding/json"
+
+// Config holds configuration for HTX exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. btcusdt), as resolved by symbols.Native
+}
+
+// SubscribeRequest represents a subscription request to HTX WebSocket
+type SubscribeRequest struct {
+	Sub string `json:"sub"`
+	ID  string `json:"id"`
+}
+
+// SnapshotRequest represents a one-off request for the full MBP book
+type SnapshotRequest struct {
+	Req string `json:"req"`
+	ID  string `json:"id"`
+}
+
+// PongMessage represents the pong reply echoing the server timestamp
+type PongMessage struct {
+	Pong int64 `json:"pong"`
+}
+
+// WSMessage represents a decompressed WebSocket message from HTX
+type WSMessage struct {
+	Ping    int64           `json:"ping,omitempty"`
+	ID      string          `json:"id,omitempty"`
+	Status  string          `json:"status,omitempty"`
+	Subbed  string          `json:"subbed,omitempty"`
+	Ch      string          `json:"ch,omitempty"`  // Set on pushed incremental updates
+	Rep     string          `json:"rep,omitempty"` // Set on responses to "req"
+	Ts      int64           `json:"ts,omitempty"`
+	ErrCode string          `json:"err-code,omitempty"`
+	ErrMsg  string          `json:"err-msg,omitempty"`
+	Tick    *MBPData        `json:"tick,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// MBPData represents market-by-price data for both snapshots and increments
+type MBPData struct {
+	SeqNum     int64       `json:"seqNum"`
+	PrevSeqNum int64       `json:"prevSeqNum"` // Only present in incremental updates
+	Bids       [][]float64 `json:"bids"`       // [price, size]
+	Asks       [][]float64 `json:"asks"`       // [price, size]
+}
+
+// TradeMessage represents a pushed trade.detail message
+type TradeMessage struct {
+	Ch   string `json:"ch"`
+	Tick struct {
+		Data []TradeData `json:"data"`
+	} `json:"tick"`
+}
+
+// TradeData represents a single trade
+type TradeData struct {
+	TradeID   int64   `json:"tradeId"`
+	Ts        int64   `json:"ts"`
+	Amount    float64 `json:"amount"`
+	Price     float64 `json:"price"`
+	Direction string  `json:"direction"` // Taker side: "buy" or "sell"
+}
@@ -5,27 +5,30 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
 // FuturesExchange implements the Exchange interface for Hyperliquid
 type FuturesExchange struct {
-	symbol     string
-	wsURL      string
-	restURL    string
-	wsConn     *websocket.Conn
-	updateChan chan *exchange.DepthUpdate
-	done       chan struct{}
-	ctx        context.Context
-	cancel     context.CancelFunc
-	health     atomic.Value // stores exchange.HealthStatus
+	symbol      string
+	wsURL       string
+	restURL     string
+	wsConn      *websocket.Conn
+	updateChan  chan *exchange.DepthUpdate
+	tradeChan   chan *exchange.Trade
+	fundingChan chan *exchange.Funding
+	done        chan struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+	health      atomic.Value // stores exchange.HealthStatus
 }
 
 // Config holds configuration for Hyperliquid exchange
@@ -41,13 +44,15 @@ func NewFuturesExchange(config Config) *FuturesExchange {
 	symbol := strings.TrimSuffix(config.Symbol, "USDT")
 
 	ex := &FuturesExchange{
-		symbol:     symbol,
-		wsURL:      "wss://api.hyperliquid.xyz/ws",
-		restURL:    "https://api.hyperliquid.xyz/info",
-		updateChan: make(chan *exchange.DepthUpdate, 1000),
-		done:       make(chan struct{}),
-		ctx:        ctx,
-		cancel:     cancel,
+		symbol:      symbol,
+		wsURL:       "wss://api.hyperliquid.xyz/ws",
+		restURL:     "https://api.hyperliquid.xyz/info",
+		updateChan:  make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:   make(chan *exchange.Trade, 1000),
+		fundingChan: make(chan *exchange.Funding, 100),
+		done:        make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 
 	ex.health.Store(exchange.HealthStatus{
@@ -70,6 +75,11 @@ func (e *FuturesExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect establishes WebSocket connection to Hyperliquid
 func (e *FuturesExchange) Connect(ctx context.Context) error {
 	dialer := websocket.Dialer{
@@ -84,7 +94,7 @@ func (e *FuturesExchange) Connect(ctx context.Context) error {
 
 	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
+	e.log().Info("WebSocket connected", "event", "ws_connected")
 
 	// Subscribe to L2 book updates
 	subscription := SubscriptionMessage{
@@ -100,6 +110,28 @@ func (e *FuturesExchange) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to send subscription: %w", err)
 	}
 
+	// Subscribe to trades
+	subscription.Subscription = map[string]interface{}{
+		"type": "trades",
+		"coin": e.symbol,
+	}
+
+	if err := conn.WriteJSON(subscription); err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to send trades subscription: %w", err)
+	}
+
+	// Subscribe to funding, open interest and prices
+	subscription.Subscription = map[string]interface{}{
+		"type": "activeAssetCtx",
+		"coin": e.symbol,
+	}
+
+	if err := conn.WriteJSON(subscription); err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to send asset context subscription: %w", err)
+	}
+
 	go e.readMessages()
 
 	return nil
@@ -121,7 +153,7 @@ func (e *FuturesExchange) Close() error {
 		err := e.wsConn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
 		}
 
 		select {
@@ -136,7 +168,7 @@ func (e *FuturesExchange) Close() error {
 
 // GetSnapshot fetches the initial orderbook snapshot via REST API
 func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Fetching orderbook snapshot...", e.GetName())
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
 
 	requestBody := map[string]interface{}{
 		"type": "l2Book",
@@ -178,6 +210,16 @@ func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// Funding returns a channel that receives funding and open interest
+func (e *FuturesExchange) Funding() <-chan *exchange.Funding {
+	return e.fundingChan
+}
+
 // IsConnected checks if the WebSocket connection is active
 func (e *FuturesExchange) IsConnected() bool {
 	return e.wsConn != nil
@@ -191,23 +233,35 @@ func (e *FuturesExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the Hyperliquid perpetual feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:     20,
+		Trades:       true,
+		Funding:      true,
+		NativeSymbol: e.symbol,
+	}
+}
+
 // readMessages continuously reads WebSocket messages
 func (e *FuturesExchange) readMessages() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer close(e.fundingChan)
 	defer e.updateConnectionStatus(false)
 
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
 			return
 		case <-e.done:
 			return
 		default:
 			var msg WSMessage
-			if err := e.wsConn.ReadJSON(&msg); err != nil {
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
 				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
 				return
 			}
 
@@ -219,17 +273,33 @@ func (e *FuturesExchange) readMessages() {
 				continue
 			}
 
+			// Handle trades
+			if msg.Channel == "trades" {
+				if err := sendTrades(e.GetName(), e.symbol, e.tradeChan, msg.Data); err != nil {
+					e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+				}
+				continue
+			}
+
+			// Handle funding, open interest and prices
+			if msg.Channel == "activeAssetCtx" {
+				if err := e.sendFunding(msg.Data); err != nil {
+					e.log().Error("Failed to parse asset context", "event", "parse_failed", "error", err)
+				}
+				continue
+			}
+
 			// Handle L2 book updates
 			if msg.Channel == "l2Book" {
 				var bookData WsBook
 				dataBytes, err := json.Marshal(msg.Data)
 				if err != nil {
-					log.Printf("[%s] Error marshalling book data: %v", e.GetName(), err)
+					e.log().Error("Failed to marshal book data", "event", "parse_failed", "error", err)
 					continue
 				}
 
 				if err := json.Unmarshal(dataBytes, &bookData); err != nil {
-					log.Printf("[%s] Error unmarshalling book data: %v", e.GetName(), err)
+					e.log().Error("Failed to parse book data", "event", "parse_failed", "error", err)
 					continue
 				}
 
@@ -242,13 +312,40 @@ func (e *FuturesExchange) readMessages() {
 				case <-e.done:
 					return
 				default:
-					log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
 				}
 			}
 		}
 	}
 }
 
+// sendFunding forwards the funding of an asset context; every context carries all fields
+// Funding is charged every hour on the hour
+func (e *FuturesExchange) sendFunding(data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var assetCtx WsActiveAssetCtx
+	if err := json.Unmarshal(dataBytes, &assetCtx); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	exchange.SendFunding(e.GetName(), e.fundingChan, &exchange.Funding{
+		Exchange:     e.GetName(),
+		Symbol:       e.symbol,
+		Rate:         assetCtx.Ctx.Funding,
+		NextFunding:  now.Truncate(time.Hour).Add(time.Hour),
+		MarkPrice:    assetCtx.Ctx.MarkPx,
+		IndexPrice:   assetCtx.Ctx.OraclePx,
+		OpenInterest: assetCtx.Ctx.OpenInterest,
+		Timestamp:    now,
+	})
+	return nil
+}
+
 // convertSnapshot converts Hyperliquid snapshot to canonical format
 func (e *FuturesExchange) convertSnapshot(snapshot *L2BookResponse) *exchange.Snapshot {
 	bids := make([]exchange.PriceLevel, len(snapshot.Levels[0]))
@@ -310,6 +407,9 @@ func (e *FuturesExchange) convertDepthUpdate(update *WsBook) *exchange.DepthUpda
 // updateConnectionStatus updates the connection status in health
 func (e *FuturesExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
@@ -0,0 +1,486 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// SpotExchange implements the Exchange interface for Hyperliquid Spot
+// Spot books are addressed by pair index ("@107"), resolved from spotMeta on connect.
+// Each l2Book message is a full top-of-book view, so the first one is kept as the
+// snapshot and later ones are diffed against the previous view to produce updates
+type SpotExchange struct {
+	symbol           string
+	coin             string // Hyperliquid spot pair name (e.g., @107)
+	wsURL            string
+	restURL          string
+	wsConn           *websocket.Conn
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	lastTime         int64
+	prevBids         map[string]string
+	prevAsks         map[string]string
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+	snapshotReceived bool
+}
+
+// NewSpotExchange creates a new Hyperliquid Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &SpotExchange{
+		symbol:     config.Symbol,
+		wsURL:      "wss://api.hyperliquid.xyz/ws",
+		restURL:    "https://api.hyperliquid.xyz/info",
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+		prevBids:   make(map[string]string),
+		prevAsks:   make(map[string]string),
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.Hyperliquid
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect resolves the spot pair and establishes WebSocket connection to Hyperliquid
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	coin, err := e.resolveSpotCoin(ctx)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to resolve spot pair: %w", err)
+	}
+	e.coin = coin
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	subscription := SubscriptionMessage{
+		Method: "subscribe",
+		Subscription: map[string]interface{}{
+			"type": "l2Book",
+			"coin": e.coin,
+		},
+	}
+
+	if err := conn.WriteJSON(subscription); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to send subscription: %w", err)
+	}
+
+	subscription.Subscription = map[string]interface{}{
+		"type": "trades",
+		"coin": e.coin,
+	}
+
+	if err := conn.WriteJSON(subscription); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to send trades subscription: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "l2Book", "trade_channel", "trades", "coin", e.coin)
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the orderbook snapshot received from WebSocket
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Hyperliquid spot feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:     20,
+		Trades:       true,
+		NativeSymbol: e.coin,
+	}
+}
+
+// resolveSpotCoin looks up the pair name Hyperliquid uses for the configured symbol
+// Bridged majors are listed under Unit tokens (UBTC, UETH, USOL), so those are tried
+// after the plain base name
+func (e *SpotExchange) resolveSpotCoin(ctx context.Context) (string, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{"type": "spotMeta"})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.restURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get spot metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var meta SpotMetaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return "", fmt.Errorf("failed to decode spot metadata: %w", err)
+	}
+
+	base, quote := splitSpotSymbol(e.symbol)
+
+	tokenIndex := make(map[string]int, len(meta.Tokens))
+	for _, token := range meta.Tokens {
+		tokenIndex[strings.ToUpper(token.Name)] = token.Index
+	}
+
+	quoteIdx, ok := tokenIndex[quote]
+	if !ok {
+		return "", fmt.Errorf("quote token %s not listed on Hyperliquid spot", quote)
+	}
+
+	for _, candidate := range []string{base, "U" + base} {
+		baseIdx, ok := tokenIndex[candidate]
+		if !ok {
+			continue
+		}
+		for _, pair := range meta.Universe {
+			if pair.Tokens[0] == baseIdx && pair.Tokens[1] == quoteIdx {
+				return pair.Name, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no Hyperliquid spot pair for %s/%s", base, quote)
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			if msg.Channel == "trades" {
+				if err := sendTrades(e.GetName(), e.symbol, e.tradeChan, msg.Data); err != nil {
+					e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+				}
+				continue
+			}
+
+			if msg.Channel != "l2Book" {
+				continue
+			}
+
+			var bookData WsBook
+			dataBytes, err := json.Marshal(msg.Data)
+			if err != nil {
+				e.log().Error("Failed to marshal book data", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			if err := json.Unmarshal(dataBytes, &bookData); err != nil {
+				e.log().Error("Failed to parse book data", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			bids := diffLevels(e.prevBids, bookData.Levels[0])
+			asks := diffLevels(e.prevAsks, bookData.Levels[1])
+
+			e.snapshotMu.Lock()
+			if !e.snapshotReceived {
+				e.snapshot = &exchange.Snapshot{
+					Exchange:     e.GetName(),
+					Symbol:       bookData.Coin,
+					LastUpdateID: bookData.Time,
+					Bids:         bids,
+					Asks:         asks,
+					Timestamp:    time.UnixMilli(bookData.Time),
+				}
+				e.snapshotReceived = true
+				e.snapshotMu.Unlock()
+				e.lastTime = bookData.Time
+				continue
+			}
+			e.snapshotMu.Unlock()
+
+			if len(bids) == 0 && len(asks) == 0 {
+				continue
+			}
+
+			prev := e.lastTime
+			e.lastTime = bookData.Time
+
+			canonicalUpdate := &exchange.DepthUpdate{
+				Exchange:      e.GetName(),
+				Symbol:        bookData.Coin,
+				EventTime:     time.UnixMilli(bookData.Time),
+				FirstUpdateID: prev + 1,
+				FinalUpdateID: bookData.Time,
+				PrevUpdateID:  prev,
+				Bids:          bids,
+				Asks:          asks,
+			}
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// diffLevels returns the levels that changed since the previous view, with removed
+// levels at zero quantity, and replaces prev with the current view
+func diffLevels(prev map[string]string, current []WsLevel) []exchange.PriceLevel {
+	changes := make([]exchange.PriceLevel, 0, len(current))
+	seen := make(map[string]struct{}, len(current))
+
+	for _, level := range current {
+		seen[level.Px] = struct{}{}
+		if prev[level.Px] != level.Sz {
+			changes = append(changes, exchange.PriceLevel{Price: level.Px, Quantity: level.Sz})
+			prev[level.Px] = level.Sz
+		}
+	}
+
+	for price := range prev {
+		if _, ok := seen[price]; !ok {
+			changes = append(changes, exchange.PriceLevel{Price: price, Quantity: "0"})
+			delete(prev, price)
+		}
+	}
+
+	return changes
+}
+
+// sendTrades converts the data of a trades message and hands the trades to the trade channel
+func sendTrades(name exchange.ExchangeName, symbol string, trades chan<- *exchange.Trade, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var wsTrades []WsTrade
+	if err := json.Unmarshal(dataBytes, &wsTrades); err != nil {
+		return err
+	}
+
+	for _, trade := range wsTrades {
+		side := exchange.TakerBuy
+		if trade.Side == "A" {
+			side = exchange.TakerSell
+		}
+		exchange.SendTrade(name, trades, &exchange.Trade{
+			Exchange:  name,
+			Symbol:    symbol,
+			TradeID:   strconv.FormatInt(trade.Tid, 10),
+			Price:     trade.Px,
+			Quantity:  trade.Sz,
+			Side:      side,
+			Timestamp: time.UnixMilli(trade.Time),
+		})
+	}
+	return nil
+}
+
+// splitSpotSymbol splits a symbol into Hyperliquid spot base and quote tokens
+// Hyperliquid spot is quoted in USDC, so USDT and USD inputs map to USDC
+// Examples: BTCUSDT -> (BTC, USDC), HYPE-USDC -> (HYPE, USDC), PURR/USDC -> (PURR, USDC)
+func splitSpotSymbol(symbol string) (string, string) {
+	symbol = strings.ToUpper(symbol)
+	symbol = strings.NewReplacer("-", "", "_", "", "/", "").Replace(symbol)
+
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if strings.HasSuffix(symbol, quote) {
+			return strings.TrimSuffix(symbol, quote), "USDC"
+		}
+	}
+
+	logging.For(string(exchange.Hyperliquid), symbol).Warn("Could not split symbol into base and quote, assuming USDC quote", "event", "symbol_unresolved")
+	return symbol, "USDC"
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -21,6 +21,30 @@ type WsLevel struct {
 	N  int    `json:"n"`  // number of orders
 }
 
+// WsTrade represents a trade on the trades channel
+type WsTrade struct {
+	Coin string `json:"coin"`
+	Side string `json:"side"` // Taker side: "B" (buy) or "A" (sell)
+	Px   string `json:"px"`
+	Sz   string `json:"sz"`
+	Time int64  `json:"time"`
+	Tid  int64  `json:"tid"`
+}
+
+// WsActiveAssetCtx represents the activeAssetCtx channel of a perpetual
+type WsActiveAssetCtx struct {
+	Coin string       `json:"coin"`
+	Ctx  PerpAssetCtx `json:"ctx"`
+}
+
+// PerpAssetCtx holds the funding, open interest and prices of a perpetual
+type PerpAssetCtx struct {
+	Funding      string `json:"funding"`      // Hourly rate
+	OpenInterest string `json:"openInterest"` // Base units
+	OraclePx     string `json:"oraclePx"`     // Spot index price
+	MarkPx       string `json:"markPx"`
+}
+
 // SubscriptionMessage represents the WebSocket subscription message
 type SubscriptionMessage struct {
 	Method       string                 `json:"method"`
@@ -37,4 +61,23 @@ type SubscriptionResponse struct {
 type WSMessage struct {
 	Channel string      `json:"channel"`
 	Data    interface{} `json:"data"`
-}
\ No newline at end of file
+}
+
+// SpotMetaResponse represents the REST API response for Hyperliquid spot metadata
+type SpotMetaResponse struct {
+	Tokens   []SpotToken `json:"tokens"`
+	Universe []SpotPair  `json:"universe"`
+}
+
+// SpotToken represents a token listed on Hyperliquid spot
+type SpotToken struct {
+	Name  string `json:"name"`
+	Index int    `json:"index"`
+}
+
+// SpotPair represents a spot pair; Name is "PURR/USDC" for the first pair and "@<index>" for the rest
+type SpotPair struct {
+	Name   string `json:"name"`
+	Tokens [2]int `json:"tokens"` // [base token index, quote token index]
+	Index  int    `json:"index"`
+}
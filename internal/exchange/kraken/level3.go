@@ -0,0 +1,464 @@
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	level3URL     = "wss://ws-l3.kraken.com/v2"
+	tokenURL      = "https://api.kraken.com"
+	tokenPath     = "/0/private/GetWebSocketsToken"
+	level3Depth   = 1000
+	level3Channel = "level3"
+)
+
+// Level3Exchange implements the Exchange interface for the Kraken Spot level3 feed
+// Every resting order is published individually, so snapshots and updates carry
+// orders rather than aggregated levels. The feed needs an authenticated token
+type Level3Exchange struct {
+	symbol          string
+	apiKey          string
+	apiSecret       string
+	token           string
+	wsURL           string
+	wsConn          *websocket.Conn
+	writeMu         sync.Mutex
+	pricePrecision  int
+	qtyPrecision    int
+	precisionLoaded bool
+	updateChan      chan *exchange.DepthUpdate
+	done            chan struct{}
+	ctx             context.Context
+	cancel          context.CancelFunc
+	health          atomic.Value
+	snapshot        *exchange.Snapshot
+	snapshotMu      sync.Mutex
+}
+
+// NewLevel3Exchange creates a new Kraken level3 exchange instance
+func NewLevel3Exchange(config Config) *Level3Exchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &Level3Exchange{
+		symbol:     config.Symbol,
+		apiKey:     config.APIKey,
+		apiSecret:  config.APISecret,
+		wsURL:      level3URL,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *Level3Exchange) GetName() exchange.ExchangeName {
+	return exchange.Kraken
+}
+
+// GetSymbol returns the trading symbol
+func (e *Level3Exchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *Level3Exchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect fetches a WebSocket token and the pair precision, then subscribes to the level3 channel
+func (e *Level3Exchange) Connect(ctx context.Context) error {
+	token, err := e.fetchToken(ctx)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to get websocket token: %w", err)
+	}
+	e.token = token
+
+	if pair, err := fetchAssetPair(ctx, e.symbol); err != nil {
+		e.log().Warn("Failed to load precision", "event", "precision_failed", "error", err)
+	} else {
+		e.pricePrecision = pair.PairDecimals
+		e.qtyPrecision = pair.LotDecimals
+		e.precisionLoaded = true
+	}
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, e.wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("Level3 WebSocket connected", "event", "ws_connected")
+
+	if err := e.sendLevel3("subscribe"); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "level3")
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *Level3Exchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot waits for the order snapshot sent after subscribing
+func (e *Level3Exchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for level3 snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			snap := e.snapshot
+			e.snapshotMu.Unlock()
+
+			if snap != nil {
+				return snap, nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *Level3Exchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns nil; the level3 feed has no trades channel
+func (e *Level3Exchange) Trades() <-chan *exchange.Trade {
+	return nil
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *Level3Exchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *Level3Exchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Kraken level3 feed
+func (e *Level3Exchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:     level3Depth,
+		L3:           true,
+		NativeSymbol: e.symbol,
+	}
+}
+
+// Resync resubscribes to the channel so Kraken sends a fresh order snapshot
+func (e *Level3Exchange) Resync() error {
+	e.snapshotMu.Lock()
+	e.snapshot = nil
+	e.snapshotMu.Unlock()
+
+	if err := e.sendLevel3("unsubscribe"); err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	if err := e.sendLevel3("subscribe"); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Resubscribed", "event", "resubscribed", "channel", "level3")
+	return nil
+}
+
+// sendLevel3 sends a level3 channel request with the given method
+func (e *Level3Exchange) sendLevel3(method string) error {
+	req := Level3Request{
+		Method: method,
+		Params: Level3Params{
+			Channel:  level3Channel,
+			Symbol:   []string{e.symbol},
+			Depth:    level3Depth,
+			Snapshot: method == "subscribe",
+			Token:    e.token,
+		},
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// fetchToken requests a WebSocket session token from the private REST API
+func (e *Level3Exchange) fetchToken(ctx context.Context) (string, error) {
+	nonce := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	form := url.Values{"nonce": {nonce}}.Encode()
+
+	signature, err := signRequest(e.apiSecret, tokenPath, nonce, form)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL+tokenPath, strings.NewReader(form))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("API-Key", e.apiKey)
+	req.Header.Set("API-Sign", signature)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if len(tokenResp.Error) > 0 {
+		return "", fmt.Errorf("token error: %s", strings.Join(tokenResp.Error, ", "))
+	}
+
+	return tokenResp.Result.Token, nil
+}
+
+// signRequest computes the API-Sign header: HMAC-SHA512 of the path and
+// SHA256(nonce + form), keyed with the base64-decoded secret
+func signRequest(secret, path, nonce, form string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("invalid API secret: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(nonce + form))
+	mac := hmac.New(sha512.New, key)
+	mac.Write([]byte(path))
+	mac.Write(digest[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *Level3Exchange) readMessages() {
+	defer close(e.updateChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			_, message, err := exchange.ReadMessage(e.wsConn, e.GetName())
+			if err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			var subResp SubscribeResponse
+			if err := json.Unmarshal(message, &subResp); err == nil && subResp.Method != "" {
+				if !subResp.Success {
+					e.log().Error("Level3 request failed", "event", "request_failed", "method", subResp.Method, "error", subResp.Error)
+				}
+				continue
+			}
+
+			var msg Level3Message
+			if err := json.Unmarshal(message, &msg); err != nil {
+				e.log().Error("Failed to parse message", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			if msg.Channel != level3Channel || len(msg.Data) == 0 {
+				continue
+			}
+
+			e.incrementMessageCount()
+			e.updateLastPing()
+
+			data := msg.Data[0]
+
+			if msg.Type == "snapshot" {
+				e.storeSnapshot(&data)
+				continue
+			}
+
+			if msg.Type == "update" {
+				select {
+				case e.updateChan <- e.convertDepthUpdate(&data):
+				case <-e.ctx.Done():
+					return
+				case <-e.done:
+					return
+				default:
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped")
+				}
+			}
+		}
+	}
+}
+
+// storeSnapshot converts and stores an order snapshot
+func (e *Level3Exchange) storeSnapshot(data *Level3Data) {
+	snapshot := &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       data.Symbol,
+		LastUpdateID: 0, // The level3 feed has no update IDs
+		BidOrders:    e.convertOrders(data.Bids),
+		AskOrders:    e.convertOrders(data.Asks),
+		Timestamp:    time.Now(),
+	}
+
+	e.snapshotMu.Lock()
+	e.snapshot = snapshot
+	e.snapshotMu.Unlock()
+}
+
+// convertDepthUpdate converts a level3 update to canonical format
+func (e *Level3Exchange) convertDepthUpdate(data *Level3Data) *exchange.DepthUpdate {
+	eventTime := time.Now()
+	if data.Timestamp != "" {
+		if parsed, err := time.Parse(time.RFC3339Nano, data.Timestamp); err == nil {
+			eventTime = parsed
+		}
+	}
+
+	return &exchange.DepthUpdate{
+		Exchange:  e.GetName(),
+		Symbol:    data.Symbol,
+		EventTime: eventTime,
+		BidOrders: e.convertOrders(data.Bids),
+		AskOrders: e.convertOrders(data.Asks),
+	}
+}
+
+// convertOrders converts level3 orders to canonical format; deletes become zero quantities
+func (e *Level3Exchange) convertOrders(orders []Level3Order) []exchange.Order {
+	pricePrecision, qtyPrecision := 10, 10
+	if e.precisionLoaded {
+		pricePrecision, qtyPrecision = e.pricePrecision, e.qtyPrecision
+	}
+
+	result := make([]exchange.Order, len(orders))
+	for i, order := range orders {
+		quantity := strconv.FormatFloat(order.OrderQty, 'f', qtyPrecision, 64)
+		if order.Event == "delete" {
+			quantity = "0"
+		}
+		result[i] = exchange.Order{
+			ID:       order.OrderID,
+			Price:    strconv.FormatFloat(order.LimitPrice, 'f', pricePrecision, 64),
+			Quantity: quantity,
+		}
+	}
+	return result
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *Level3Exchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *Level3Exchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *Level3Exchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *Level3Exchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -4,23 +4,38 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 
 	"github.com/gorilla/websocket"
 )
 
+const (
+	assetPairsURL = "https://api.kraken.com/0/public/AssetPairs"
+	bookDepth     = 1000
+)
+
 // SpotExchange implements the Exchange interface for Kraken Spot
+// Levels are formatted with the pair's precision so the book checksum
+// published with every update can be verified downstream
 type SpotExchange struct {
 	symbol           string
 	wsURL            string
 	wsConn           *websocket.Conn
+	writeMu          sync.Mutex
+	pricePrecision   int
+	qtyPrecision     int
+	precisionLoaded  bool
 	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
 	done             chan struct{}
 	ctx              context.Context
 	cancel           context.CancelFunc
@@ -36,13 +51,13 @@ func NewSpotExchange(config Config) *SpotExchange {
 
 	wsURL := "wss://ws.kraken.com/v2"
 
-	// Convert symbol to Kraken format (e.g., BTCUSDT -> BTC/USD)
-	krakenSymbol := convertToKrakenSymbol(config.Symbol)
+	krakenSymbol := config.Symbol
 
 	ex := &SpotExchange{
 		symbol:     krakenSymbol,
 		wsURL:      wsURL,
 		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
 		done:       make(chan struct{}),
 		ctx:        ctx,
 		cancel:     cancel,
@@ -68,8 +83,17 @@ func (e *SpotExchange) GetSymbol() string {
 	return e.symbol
 }
 
-// Connect establishes WebSocket connection to Kraken
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect loads the pair precision and establishes WebSocket connection to Kraken
 func (e *SpotExchange) Connect(ctx context.Context) error {
+	if err := e.loadPrecision(ctx); err != nil {
+		e.log().Warn("Failed to load precision, checksums disabled", "event", "precision_failed", "error", err)
+	}
+
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
 	}
@@ -82,25 +106,21 @@ func (e *SpotExchange) Connect(ctx context.Context) error {
 
 	e.wsConn = conn
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] WebSocket connected successfully", e.GetName())
-
-	subscribeMsg := SubscribeRequest{
-		Method: "subscribe",
-		Params: SubscribeParams{
-			Channel:  "book",
-			Symbol:   []string{e.symbol},
-			Depth:    1000,
-			Snapshot: true,
-		},
-	}
+	e.log().Info("WebSocket connected", "event", "ws_connected")
 
-	if err := conn.WriteJSON(subscribeMsg); err != nil {
+	if err := e.sendBook("subscribe"); err != nil {
 		e.incrementErrorCount()
 		conn.Close()
 		return fmt.Errorf("failed to subscribe: %w", err)
 	}
 
-	log.Printf("[%s] Subscribed to book channel for %s", e.GetName(), e.symbol)
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "book")
+
+	if err := e.subscribeTrades(); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
 
 	go e.readMessages()
 
@@ -120,10 +140,12 @@ func (e *SpotExchange) Close() error {
 			close(e.done)
 		}
 
+		e.writeMu.Lock()
 		err := e.wsConn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
 		if err != nil {
-			log.Printf("[%s] Error sending close message: %v", e.GetName(), err)
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
 		}
 
 		select {
@@ -138,7 +160,7 @@ func (e *SpotExchange) Close() error {
 
 // GetSnapshot fetches the initial orderbook snapshot via WebSocket
 func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
-	log.Printf("[%s] Waiting for orderbook snapshot from WebSocket...", e.GetName())
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
 
 	timeout := time.NewTimer(10 * time.Second)
 	defer timeout.Stop()
@@ -167,6 +189,11 @@ func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
 // IsConnected checks if the WebSocket connection is active
 func (e *SpotExchange) IsConnected() bool {
 	return e.wsConn != nil
@@ -180,31 +207,138 @@ func (e *SpotExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the Kraken feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:     1000,
+		Checksum:     true,
+		Trades:       true,
+		NativeSymbol: e.symbol,
+	}
+}
+
+// Resync resubscribes to the book so Kraken sends a fresh snapshot
+func (e *SpotExchange) Resync() error {
+	e.snapshotMu.Lock()
+	e.snapshot = nil
+	e.snapshotMu.Unlock()
+
+	if err := e.sendBook("unsubscribe"); err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	if err := e.sendBook("subscribe"); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.log().Info("Resubscribed", "event", "resubscribed", "channel", "book")
+	return nil
+}
+
+// sendBook sends a book channel request with the given method
+func (e *SpotExchange) sendBook(method string) error {
+	req := SubscribeRequest{
+		Method: method,
+		Params: SubscribeParams{
+			Channel:  "book",
+			Symbol:   []string{e.symbol},
+			Depth:    bookDepth,
+			Snapshot: method == "subscribe",
+		},
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// subscribeTrades subscribes to the trade channel, without the snapshot of recent trades
+func (e *SpotExchange) subscribeTrades() error {
+	req := SubscribeRequest{
+		Method: "subscribe",
+		Params: SubscribeParams{
+			Channel: "trade",
+			Symbol:  []string{e.symbol},
+		},
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// loadPrecision fetches the price and volume decimals of the pair from REST
+func (e *SpotExchange) loadPrecision(ctx context.Context) error {
+	pair, err := fetchAssetPair(ctx, e.symbol)
+	if err != nil {
+		return err
+	}
+
+	e.pricePrecision = pair.PairDecimals
+	e.qtyPrecision = pair.LotDecimals
+	e.precisionLoaded = true
+	e.log().Info("Loaded precision", "event", "precision", "price_decimals", e.pricePrecision, "qty_decimals", e.qtyPrecision)
+	return nil
+}
+
+// fetchAssetPair looks up a pair by its WebSocket symbol in the REST AssetPairs listing
+func fetchAssetPair(ctx context.Context, symbol string) (AssetPair, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", assetPairsURL, nil)
+	if err != nil {
+		return AssetPair{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return AssetPair{}, fmt.Errorf("failed to get asset pairs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var pairs AssetPairsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return AssetPair{}, fmt.Errorf("failed to decode asset pairs: %w", err)
+	}
+	if len(pairs.Error) > 0 {
+		return AssetPair{}, fmt.Errorf("asset pairs error: %s", strings.Join(pairs.Error, ", "))
+	}
+
+	// REST names still use the legacy XBT and XDG codes
+	legacy := strings.NewReplacer("XBT", "BTC", "XDG", "DOGE")
+	for _, pair := range pairs.Result {
+		if legacy.Replace(pair.WSName) == symbol {
+			return pair, nil
+		}
+	}
+
+	return AssetPair{}, fmt.Errorf("pair not found")
+}
+
 // readMessages continuously reads WebSocket messages
 func (e *SpotExchange) readMessages() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
 	defer e.updateConnectionStatus(false)
 
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping message reading", e.GetName())
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
 			return
 		case <-e.done:
 			return
 		default:
-			_, message, err := e.wsConn.ReadMessage()
+			_, message, err := exchange.ReadMessage(e.wsConn, e.GetName())
 			if err != nil {
 				e.incrementErrorCount()
-				log.Printf("[%s] WebSocket read error: %v", e.GetName(), err)
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
 				return
 			}
 
 			// Try to parse as subscription response first
 			var subResp SubscribeResponse
-			if err := json.Unmarshal(message, &subResp); err == nil && subResp.Method == "subscribe" {
+			if err := json.Unmarshal(message, &subResp); err == nil && subResp.Method != "" {
 				if !subResp.Success {
-					log.Printf("[%s] Subscription failed: %s", e.GetName(), subResp.Error)
+					e.log().Error("Request failed", "event", "request_failed", "method", subResp.Method, "error", subResp.Error)
 				}
 				continue
 			}
@@ -212,7 +346,14 @@ func (e *SpotExchange) readMessages() {
 			// Parse as data message
 			var msg WSMessage
 			if err := json.Unmarshal(message, &msg); err != nil {
-				log.Printf("[%s] Failed to parse message: %v", e.GetName(), err)
+				e.log().Error("Failed to parse message", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			if msg.Channel == "trade" {
+				e.incrementMessageCount()
+				e.updateLastPing()
+				e.handleTrades(message)
 				continue
 			}
 
@@ -225,7 +366,8 @@ func (e *SpotExchange) readMessages() {
 
 			bookData := msg.Data[0]
 
-			if msg.Type == "snapshot" && !e.snapshotReceived {
+			// A snapshot only follows a subscribe, including one sent by Resync
+			if msg.Type == "snapshot" {
 				e.storeSnapshot(&bookData)
 				e.snapshotReceived = true
 			}
@@ -240,30 +382,44 @@ func (e *SpotExchange) readMessages() {
 				case <-e.done:
 					return
 				default:
-					log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+					e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
 				}
 			}
 		}
 	}
 }
 
-// storeSnapshot converts and stores the initial snapshot
-func (e *SpotExchange) storeSnapshot(data *BookData) {
-	bids := make([]exchange.PriceLevel, len(data.Bids))
-	for i, bid := range data.Bids {
-		bids[i] = exchange.PriceLevel{
-			Price:    fmt.Sprintf("%.10f", bid.Price),
-			Quantity: fmt.Sprintf("%.10f", bid.Qty),
-		}
+// handleTrades forwards the trades of a trade channel message
+func (e *SpotExchange) handleTrades(message []byte) {
+	var msg TradeMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+		return
 	}
-
-	asks := make([]exchange.PriceLevel, len(data.Asks))
-	for i, ask := range data.Asks {
-		asks[i] = exchange.PriceLevel{
-			Price:    fmt.Sprintf("%.10f", ask.Price),
-			Quantity: fmt.Sprintf("%.10f", ask.Qty),
+	for _, trade := range msg.Data {
+		level := e.convertLevels([]PriceQty{{Price: trade.Price, Qty: trade.Qty}})[0]
+		timestamp, _ := time.Parse(time.RFC3339Nano, trade.Timestamp)
+		side := exchange.TakerBuy
+		if trade.Side == "sell" {
+			side = exchange.TakerSell
 		}
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.Symbol,
+			TradeID:   strconv.FormatInt(trade.TradeID, 10),
+			Price:     level.Price,
+			Quantity:  level.Quantity,
+			Side:      side,
+			Timestamp: timestamp,
+		})
 	}
+}
+
+// storeSnapshot converts and stores the initial snapshot
+func (e *SpotExchange) storeSnapshot(data *BookData) {
+	bids := e.convertLevels(data.Bids)
+	asks := e.convertLevels(data.Asks)
 
 	snapshot := &exchange.Snapshot{
 		Exchange:     e.GetName(),
@@ -281,20 +437,14 @@ func (e *SpotExchange) storeSnapshot(data *BookData) {
 
 // convertDepthUpdate converts Kraken depth update to canonical format
 func (e *SpotExchange) convertDepthUpdate(data *BookData, msgType string) *exchange.DepthUpdate {
-	bids := make([]exchange.PriceLevel, len(data.Bids))
-	for i, bid := range data.Bids {
-		bids[i] = exchange.PriceLevel{
-			Price:    fmt.Sprintf("%.10f", bid.Price),
-			Quantity: fmt.Sprintf("%.10f", bid.Qty),
-		}
-	}
-
-	asks := make([]exchange.PriceLevel, len(data.Asks))
-	for i, ask := range data.Asks {
-		asks[i] = exchange.PriceLevel{
-			Price:    fmt.Sprintf("%.10f", ask.Price),
-			Quantity: fmt.Sprintf("%.10f", ask.Qty),
-		}
+	bids := e.convertLevels(data.Bids)
+	asks := e.convertLevels(data.Asks)
+
+	// The checksum covers the precision-formatted levels, so it is only usable with known precision
+	var checksum *uint32
+	if e.precisionLoaded {
+		value := uint32(data.Checksum)
+		checksum = &value
 	}
 
 	var eventTime time.Time
@@ -313,51 +463,33 @@ func (e *SpotExchange) convertDepthUpdate(data *BookData, msgType string) *excha
 		PrevUpdateID:  0,
 		Bids:          bids,
 		Asks:          asks,
+		Checksum:      checksum,
 	}
 }
 
-// convertToKrakenSymbol converts various symbol formats to Kraken format
-// Examples: BTCUSDT -> BTC/USD, ETHUSDT -> ETH/USD, BTC/USD -> BTC/USD
-func convertToKrakenSymbol(symbol string) string {
-	// If already has slash, assume it's correct
-	if strings.Contains(symbol, "/") {
-		return strings.ToUpper(symbol)
-	}
-
-	symbol = strings.ToUpper(symbol)
-
-	// Common conversions for USDT pairs
-	if strings.HasSuffix(symbol, "USDT") {
-		base := strings.TrimSuffix(symbol, "USDT")
-		return fmt.Sprintf("%s/USD", base)
-	}
-
-	// Common conversions for USD pairs (without T)
-	if strings.HasSuffix(symbol, "USD") && !strings.HasSuffix(symbol, "USDT") {
-		base := strings.TrimSuffix(symbol, "USD")
-		return fmt.Sprintf("%s/USD", base)
+// convertLevels converts Kraken levels to canonical format using the pair precision
+func (e *SpotExchange) convertLevels(levels []PriceQty) []exchange.PriceLevel {
+	pricePrecision, qtyPrecision := 10, 10
+	if e.precisionLoaded {
+		pricePrecision, qtyPrecision = e.pricePrecision, e.qtyPrecision
 	}
 
-	// Common conversions for EUR pairs
-	if strings.HasSuffix(symbol, "EUR") {
-		base := strings.TrimSuffix(symbol, "EUR")
-		return fmt.Sprintf("%s/EUR", base)
-	}
-
-	// Common conversions for GBP pairs
-	if strings.HasSuffix(symbol, "GBP") {
-		base := strings.TrimSuffix(symbol, "GBP")
-		return fmt.Sprintf("%s/GBP", base)
+	result := make([]exchange.PriceLevel, len(levels))
+	for i, level := range levels {
+		result[i] = exchange.PriceLevel{
+			Price:    strconv.FormatFloat(level.Price, 'f', pricePrecision, 64),
+			Quantity: strconv.FormatFloat(level.Qty, 'f', qtyPrecision, 64),
+		}
 	}
-
-	// If we can't determine, return as-is and let Kraken reject it
-	log.Printf("[Kraken] Warning: Could not convert symbol %s to Kraken format, using as-is", symbol)
-	return symbol
+	return result
 }
 
 // updateConnectionStatus updates the connection status in health
 func (e *SpotExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
@@ -2,7 +2,11 @@ package kraken
 
 // Config holds configuration for Kraken exchange
 type Config struct {
-	Symbol string
+	Symbol string // Native symbol (e.g. BTC/USD), as resolved by symbols.Native
+
+	// Credentials for the level3 feed, which needs an authenticated WebSocket token
+	APIKey    string
+	APISecret string
 }
 
 // SubscribeRequest represents a subscription request to Kraken WebSocket v2
@@ -16,7 +20,7 @@ type SubscribeRequest struct {
 type SubscribeParams struct {
 	Channel  string   `json:"channel"`
 	Symbol   []string `json:"symbol"`
-	Depth    int      `json:"depth"`
+	Depth    int      `json:"depth,omitempty"` // book only
 	Snapshot bool     `json:"snapshot"`
 }
 
@@ -61,3 +65,82 @@ type PriceQty struct {
 	Price float64 `json:"price"`
 	Qty   float64 `json:"qty"`
 }
+
+// TradeMessage represents a trade channel message from Kraken
+type TradeMessage struct {
+	Channel string      `json:"channel"`
+	Type    string      `json:"type"` // "snapshot" or "update"
+	Data    []TradeData `json:"data"`
+}
+
+// TradeData represents one trade
+type TradeData struct {
+	Symbol    string  `json:"symbol"`
+	Side      string  `json:"side"` // Taker side: "buy" or "sell"
+	Price     float64 `json:"price"`
+	Qty       float64 `json:"qty"`
+	TradeID   int64   `json:"trade_id"`
+	Timestamp string  `json:"timestamp"`
+}
+
+// AssetPairsResponse represents the REST AssetPairs response
+type AssetPairsResponse struct {
+	Error  []string             `json:"error"`
+	Result map[string]AssetPair `json:"result"`
+}
+
+// AssetPair holds the precision of a trading pair
+type AssetPair struct {
+	WSName       string `json:"wsname"`
+	PairDecimals int    `json:"pair_decimals"`
+	LotDecimals  int    `json:"lot_decimals"`
+}
+
+// Level3Request represents a level3 channel request; the channel requires a session token
+type Level3Request struct {
+	Method string       `json:"method"`
+	Params Level3Params `json:"params"`
+}
+
+// Level3Params holds the level3 subscription parameters
+type Level3Params struct {
+	Channel  string   `json:"channel"`
+	Symbol   []string `json:"symbol"`
+	Depth    int      `json:"depth,omitempty"`
+	Snapshot bool     `json:"snapshot"`
+	Token    string   `json:"token"`
+}
+
+// Level3Message represents a level3 data message
+type Level3Message struct {
+	Channel string       `json:"channel"`
+	Type    string       `json:"type"` // "snapshot" or "update"
+	Data    []Level3Data `json:"data"`
+}
+
+// Level3Data holds the orders of one symbol
+type Level3Data struct {
+	Symbol    string        `json:"symbol"`
+	Bids      []Level3Order `json:"bids"`
+	Asks      []Level3Order `json:"asks"`
+	Checksum  int64         `json:"checksum"`
+	Timestamp string        `json:"timestamp,omitempty"`
+}
+
+// Level3Order represents a resting order; updates carry an add, modify or delete event
+type Level3Order struct {
+	Event      string  `json:"event,omitempty"`
+	OrderID    string  `json:"order_id"`
+	LimitPrice float64 `json:"limit_price"`
+	OrderQty   float64 `json:"order_qty"`
+	Timestamp  string  `json:"timestamp"`
+}
+
+// TokenResponse represents the REST GetWebSocketsToken response
+type TokenResponse struct {
+	Error  []string `json:"error"`
+	Result struct {
+		Token   string `json:"token"`
+		Expires int    `json:"expires"`
+	} `json:"result"`
+}
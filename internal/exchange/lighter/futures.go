@@ -0,0 +1,450 @@
+package lighter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsURL         = "wss://mainnet.zklighter.elliot.ai/stream"
+	orderBooksURL = "https://mainnet.zklighter.elliot.ai/api/v1/orderBooks"
+	pingInterval  = 60 * time.Second // Idle connections are dropped after two minutes
+)
+
+// FuturesExchange implements the Exchange interface for Lighter perpetuals
+// Lighter is a zk-rollup orderbook DEX; markets are addressed by a numeric index
+// resolved from the REST market list, and the order_book channel opens with the
+// full book before streaming changed levels tagged with an increasing offset
+type FuturesExchange struct {
+	symbol           string
+	market           string // Lighter format (e.g., BTC)
+	marketID         int
+	wsConn           *websocket.Conn
+	writeMu          sync.Mutex
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	lastOffset       int64
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+	snapshotReceived bool
+}
+
+// NewFuturesExchange creates a new Lighter perpetual exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		market:     config.Symbol,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.Lighterf
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect resolves the market index and establishes WebSocket connection to Lighter
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	marketID, err := e.resolveMarketID(ctx)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to resolve market %s: %w", e.market, err)
+	}
+	e.marketID = marketID
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	channel := fmt.Sprintf("order_book/%d", e.marketID)
+	if err := e.send(WSRequest{Type: "subscribe", Channel: channel}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	tradeChannel := fmt.Sprintf("trade/%d", e.marketID)
+	if err := e.send(WSRequest{Type: "subscribe", Channel: tradeChannel}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", channel, "trade_channel", tradeChannel)
+
+	go e.pingLoop()
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the orderbook snapshot received from WebSocket
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Lighter perpetual feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		UpdateInterval: 50 * time.Millisecond,
+		Trades:         true,
+		NativeSymbol:   e.market,
+	}
+}
+
+// send writes a JSON request
+func (e *FuturesExchange) send(req WSRequest) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// pingLoop keeps the connection alive while the book is quiet
+func (e *FuturesExchange) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-e.done:
+			return
+		case <-ticker.C:
+			if err := e.send(WSRequest{Type: "ping"}); err != nil {
+				e.log().Error("Failed to send ping", "event", "heartbeat_failed", "error", err)
+			}
+		}
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.updateLastPing()
+
+			if msg.Error != nil {
+				e.incrementErrorCount()
+				e.log().Error("Request error", "event", "request_failed", "code", msg.Error.Code, "error", msg.Error.Message)
+				continue
+			}
+
+			if msg.Type == "ping" {
+				if err := e.send(WSRequest{Type: "pong"}); err != nil {
+					e.log().Error("Failed to send pong", "event", "heartbeat_failed", "error", err)
+				}
+				continue
+			}
+
+			if msg.Type == "update/trade" {
+				e.handleTrades(msg.Trades)
+				continue
+			}
+
+			if msg.OrderBook == nil {
+				continue
+			}
+
+			e.incrementMessageCount()
+
+			bids := convertLevels(msg.OrderBook.Bids)
+			asks := convertLevels(msg.OrderBook.Asks)
+			offset := msg.Offset
+			if offset == 0 {
+				offset = msg.OrderBook.Offset
+			}
+			ts := time.Now()
+			if msg.Timestamp > 0 {
+				ts = time.UnixMilli(msg.Timestamp)
+			}
+
+			if msg.Type == "subscribed/order_book" {
+				e.snapshotMu.Lock()
+				e.snapshot = &exchange.Snapshot{
+					Exchange:     e.GetName(),
+					Symbol:       e.market,
+					LastUpdateID: offset,
+					Bids:         bids,
+					Asks:         asks,
+					Timestamp:    ts,
+				}
+				e.snapshotReceived = true
+				e.snapshotMu.Unlock()
+				e.lastOffset = offset
+
+				e.log().Info("Received orderbook snapshot", "event", "snapshot", "bids", len(bids), "asks", len(asks))
+				continue
+			}
+
+			// Offsets increase but are shared across channels, so they are not contiguous per market
+			prev := e.lastOffset
+			e.lastOffset = offset
+
+			canonicalUpdate := &exchange.DepthUpdate{
+				Exchange:      e.GetName(),
+				Symbol:        e.market,
+				EventTime:     ts,
+				FirstUpdateID: prev + 1,
+				FinalUpdateID: offset,
+				PrevUpdateID:  prev,
+				Bids:          bids,
+				Asks:          asks,
+			}
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrades forwards the trades of a trade channel update; the recent trades sent on
+// subscribing are skipped
+func (e *FuturesExchange) handleTrades(trades []Trade) {
+	e.incrementMessageCount()
+
+	for _, trade := range trades {
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    e.market,
+			TradeID:   strconv.FormatInt(trade.TradeID, 10),
+			Price:     trade.Price,
+			Quantity:  trade.Size,
+			Side:      exchange.TakerSide(!trade.IsMakerAsk),
+			Timestamp: time.UnixMilli(trade.Timestamp),
+		})
+	}
+}
+
+// resolveMarketID looks up the numeric market index of the configured market
+func (e *FuturesExchange) resolveMarketID(ctx context.Context) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", orderBooksURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get markets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var markets OrderBooksResponse
+	if err := json.NewDecoder(resp.Body).Decode(&markets); err != nil {
+		return 0, fmt.Errorf("failed to decode markets: %w", err)
+	}
+
+	if markets.Code != http.StatusOK {
+		return 0, fmt.Errorf("API error: code=%d, msg=%s", markets.Code, markets.Message)
+	}
+
+	for _, market := range markets.OrderBooks {
+		if strings.EqualFold(market.Symbol, e.market) {
+			if market.Status != "" && market.Status != "active" {
+				return 0, fmt.Errorf("market is %s", market.Status)
+			}
+			return market.MarketID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("market not found")
+}
+
+// convertLevels converts Lighter levels to canonical format
+func convertLevels(levels []BookLevel) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, len(levels))
+	for i, level := range levels {
+		result[i] = exchange.PriceLevel{
+			Price:    level.Price,
+			Quantity: level.Size,
+		}
+	}
+	return result
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,67 @@
+package lighter
+
+// Config holds configuration for Lighter exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC), as resolved by symbols.Native
+}
+
+// WSRequest represents a Lighter WebSocket request
+type WSRequest struct {
+	Type    string `json:"type"`              // "subscribe", "ping" or "pong"
+	Channel string `json:"channel,omitempty"` // e.g., order_book/1
+}
+
+// WSMessage represents a WebSocket message from Lighter
+type WSMessage struct {
+	Type      string     `json:"type"`    // "subscribed/order_book", "update/order_book", "update/trade", "ping", ...
+	Channel   string     `json:"channel"` // e.g., order_book:1
+	Offset    int64      `json:"offset"`
+	Timestamp int64      `json:"timestamp"` // Milliseconds, absent on some messages
+	OrderBook *OrderBook `json:"order_book"`
+	Trades    []Trade    `json:"trades"` // Set on trade channel messages
+	Error     *WSError   `json:"error"`
+}
+
+// OrderBook represents an order_book payload
+// The subscription response carries the full book; updates carry changed levels only
+type OrderBook struct {
+	Code   int         `json:"code"`
+	Offset int64       `json:"offset"`
+	Bids   []BookLevel `json:"bids"`
+	Asks   []BookLevel `json:"asks"`
+}
+
+// BookLevel represents a single price level; a zero size removes the level
+type BookLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// Trade represents a single trade on the trade channel
+type Trade struct {
+	TradeID    int64  `json:"trade_id"`
+	Size       string `json:"size"`
+	Price      string `json:"price"`
+	IsMakerAsk bool   `json:"is_maker_ask"`
+	Timestamp  int64  `json:"timestamp"` // Milliseconds
+}
+
+// WSError represents an error pushed on the WebSocket
+type WSError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// OrderBooksResponse represents the orderBooks REST response listing markets
+type OrderBooksResponse struct {
+	Code       int          `json:"code"`
+	Message    string       `json:"message"`
+	OrderBooks []MarketInfo `json:"order_books"`
+}
+
+// MarketInfo describes one Lighter market
+type MarketInfo struct {
+	Symbol   string `json:"symbol"` // Base asset (e.g., BTC)
+	MarketID int    `json:"market_id"`
+	Status   string `json:"status"` // "active" or "inactive"
+}
@@ -0,0 +1,724 @@
+package okx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/shard"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	wsPublicURL       = "wss://ws.okx.com:8443/ws/v5/public"
+	instrumentsURL    = "https://www.okx.com/api/v5/public/instruments"
+	wsPingInterval    = 25 * time.Second
+	channelBooks      = "books"
+	channelBooksL2TBT = "books-l2-tbt"
+	channelTrades     = "trades"
+	channelFunding    = "funding-rate"
+	channelMarkPrice  = "mark-price"
+	channelOpenInt    = "open-interest"
+	channelIndex      = "index-tickers"
+	loginPath         = "/users/self/verify"
+
+	// maxTopicsPerConn keeps each subscribe request well under OKX's 64 KB limit
+	// and spreads tick-by-tick traffic over several connections
+	maxTopicsPerConn = 50
+)
+
+// BookExchange implements the Exchange interface for OKX over the WebSocket books
+// channels, for any instrument type
+// Derivative sizes are published in contracts and are converted to base units
+// with the instrument's contract value so stats stay comparable across venues
+type BookExchange struct {
+	symbol           string
+	instType         string
+	instID           string // OKX format (e.g., BTC-USDT-SWAP)
+	channel          string
+	pool             *shard.Pool
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	fundingChan      chan *exchange.Funding // nil unless the instrument is a perpetual swap
+	funding          exchange.FundingState
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	contractSize     decimal.Decimal
+	apiKey           string
+	apiSecret        string
+	passphrase       string
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+	snapshotReceived bool
+}
+
+// NewBookExchange creates a new OKX WebSocket book exchange instance
+func NewBookExchange(config BookConfig) *BookExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	instType := strings.ToUpper(config.InstType)
+	if instType == "" {
+		instType = InstTypeSpot
+	}
+
+	ex := &BookExchange{
+		symbol:       config.Symbol,
+		instType:     instType,
+		instID:       buildInstID(instType, config.Symbol, config.Contract),
+		channel:      channelForInstType(instType),
+		updateChan:   make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:    make(chan *exchange.Trade, 1000),
+		done:         make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
+		contractSize: decimal.NewFromInt(1),
+		apiKey:       config.APIKey,
+		apiSecret:    config.APISecret,
+		passphrase:   config.Passphrase,
+	}
+
+	if instType == InstTypeSwap {
+		ex.fundingChan = make(chan *exchange.Funding, 100)
+	}
+
+	ex.pool = shard.NewPool(shard.Config{
+		Name:             ex.GetName(),
+		Symbol:           ex.instID,
+		URL:              wsPublicURL,
+		MaxTopicsPerConn: maxTopicsPerConn,
+		PingInterval:     wsPingInterval,
+		PingMessage:      []byte("ping"),
+		OnConnect:        ex.onConnect,
+		Subscribe:        ex.subscribe,
+	})
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *BookExchange) GetName() exchange.ExchangeName {
+	return exchange.OKX
+}
+
+// GetSymbol returns the trading symbol
+func (e *BookExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *BookExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect loads contract details and establishes WebSocket connection to OKX
+func (e *BookExchange) Connect(ctx context.Context) error {
+	if e.instType != InstTypeSpot {
+		if err := e.loadContractSize(ctx); err != nil {
+			e.incrementErrorCount()
+			return fmt.Errorf("failed to load instrument %s: %w", e.instID, err)
+		}
+	}
+
+	if err := e.pool.Connect(ctx, []string{e.instID}, e.handleMessage); err != nil {
+		e.incrementErrorCount()
+		return err
+	}
+
+	e.updateConnectionStatus(true)
+	e.log().Info("Subscribed", "event", "subscribed", "channel", e.channel, "inst_type", e.instType)
+
+	go e.watchPool()
+
+	return nil
+}
+
+// Close closes every WebSocket connection
+func (e *BookExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	select {
+	case <-e.done:
+	default:
+		close(e.done)
+	}
+
+	err := e.pool.Close()
+	e.updateConnectionStatus(false)
+	return err
+}
+
+// GetSnapshot returns the latest orderbook snapshot received from WebSocket
+func (e *BookExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *BookExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *BookExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// Funding returns a channel that receives funding and open interest, nil unless the
+// instrument is a perpetual swap
+func (e *BookExchange) Funding() <-chan *exchange.Funding {
+	return e.fundingChan
+}
+
+// IsConnected checks if any WebSocket connection is active
+func (e *BookExchange) IsConnected() bool {
+	return e.pool.Connected()
+}
+
+// Health returns connection health information
+func (e *BookExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the OKX WebSocket books feed
+// Both channels carry 400 levels per side; tick-by-tick books push every change
+func (e *BookExchange) Capabilities() exchange.Capabilities {
+	interval := 100 * time.Millisecond
+	if e.channel == channelBooksL2TBT {
+		interval = 10 * time.Millisecond
+	}
+
+	return exchange.Capabilities{
+		MaxDepth:       400,
+		UpdateInterval: interval,
+		Checksum:       true,
+		Trades:         true,
+		Funding:        e.instType == InstTypeSwap,
+		NativeSymbol:   e.instID,
+	}
+}
+
+// ConnectionCount returns the number of WebSocket connections the instruments are sharded over
+func (e *BookExchange) ConnectionCount() int {
+	return e.pool.Len()
+}
+
+// ConnectionHealth returns health information per WebSocket connection
+func (e *BookExchange) ConnectionHealth() []exchange.HealthStatus {
+	return e.pool.Health()
+}
+
+// onConnect logs in a new connection when credentials are configured
+// A failed login keeps the connection on the public books channel
+func (e *BookExchange) onConnect(c *shard.Conn) error {
+	e.log().Info("WebSocket connected", "event", "ws_connected", "connection", c.Index)
+
+	if e.apiKey == "" {
+		return nil
+	}
+
+	if err := e.login(c); err != nil {
+		e.incrementErrorCount()
+		e.log().Warn("Login failed, continuing with public books", "event", "login_failed", "error", err)
+		return nil
+	}
+
+	// Logged-in VIP sessions get tick-by-tick books for every instrument type
+	e.channel = channelBooksL2TBT
+	e.log().Info("Logged in", "event", "logged_in", "connection", c.Index)
+	return nil
+}
+
+// subscribe subscribes a connection to the current books channel and the trades of its
+// instruments, and swaps to their funding, mark price, open interest and index
+func (e *BookExchange) subscribe(c *shard.Conn) error {
+	if err := e.sendOp(c, "subscribe", e.channel); err != nil {
+		return err
+	}
+	if err := e.sendOp(c, "subscribe", channelTrades); err != nil {
+		return err
+	}
+	if e.fundingChan == nil {
+		return nil
+	}
+
+	var args []WSArg
+	for _, instID := range c.Topics {
+		args = append(args,
+			WSArg{Channel: channelFunding, InstID: instID},
+			WSArg{Channel: channelMarkPrice, InstID: instID},
+			WSArg{Channel: channelOpenInt, InstID: instID},
+			WSArg{Channel: channelIndex, InstID: strings.TrimSuffix(instID, "-SWAP")})
+	}
+	return c.WriteJSON(WSRequest{Op: "subscribe", Args: args})
+}
+
+// sendOp sends a subscribe or unsubscribe request for the given channel and the connection's instruments
+func (e *BookExchange) sendOp(c *shard.Conn, op, channel string) error {
+	args := make([]WSArg, len(c.Topics))
+	for i, instID := range c.Topics {
+		args[i] = WSArg{Channel: channel, InstID: instID}
+	}
+
+	return c.WriteJSON(WSRequest{
+		Op:   op,
+		Args: args,
+	})
+}
+
+// login signs in the connection and waits for OKX to acknowledge it
+// Called before the read loop starts, so it reads the response itself
+func (e *BookExchange) login(c *shard.Conn) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(e.apiSecret))
+	mac.Write([]byte(timestamp + "GET" + loginPath))
+
+	req := WSLoginRequest{
+		Op: "login",
+		Args: []WSLoginArg{{
+			APIKey:     e.apiKey,
+			Passphrase: e.passphrase,
+			Timestamp:  timestamp,
+			Sign:       base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		}},
+	}
+
+	if err := c.WriteJSON(req); err != nil {
+		return fmt.Errorf("failed to send login: %w", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+
+	for {
+		var msg WSMessage
+		if err := c.ReadJSON(&msg, time.Until(deadline)); err != nil {
+			return fmt.Errorf("failed to read login response: %w", err)
+		}
+
+		switch msg.Event {
+		case "login":
+			if msg.Code != "" && msg.Code != "0" {
+				return fmt.Errorf("code=%s, msg=%s", msg.Code, msg.Msg)
+			}
+			return nil
+		case "error":
+			return fmt.Errorf("code=%s, msg=%s", msg.Code, msg.Msg)
+		}
+	}
+}
+
+// watchPool closes the update, trade and funding channels once every connection has stopped reading
+func (e *BookExchange) watchPool() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	if e.fundingChan != nil {
+		defer close(e.fundingChan)
+	}
+	defer e.updateConnectionStatus(false)
+
+	<-e.pool.Done()
+}
+
+// handleMessage processes one WebSocket message from any pool connection
+func (e *BookExchange) handleMessage(c *shard.Conn, message []byte) {
+	e.updateLastPing()
+
+	if string(message) == "pong" {
+		return
+	}
+
+	var msg WSMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		e.log().Error("Failed to parse message", "event", "parse_failed", "error", err)
+		return
+	}
+
+	if msg.Event == "error" {
+		e.incrementErrorCount()
+		e.handleSubscribeError(&msg)
+		return
+	}
+
+	if msg.Event != "" || len(msg.Data) == 0 {
+		return
+	}
+
+	e.incrementMessageCount()
+
+	if msg.Arg != nil {
+		switch msg.Arg.Channel {
+		case channelTrades:
+			e.handleTrades(message)
+			return
+		case channelFunding, channelMarkPrice, channelOpenInt, channelIndex:
+			e.handleFunding(message)
+			return
+		}
+	}
+
+	data := &msg.Data[0]
+	bids := e.convertLevels(data.Bids)
+	asks := e.convertLevels(data.Asks)
+	ts := parseMillis(data.Ts)
+
+	if msg.Action == "snapshot" {
+		e.snapshotMu.Lock()
+		e.snapshot = &exchange.Snapshot{
+			Exchange:     e.GetName(),
+			Symbol:       e.instID,
+			LastUpdateID: data.SeqID,
+			Bids:         bids,
+			Asks:         asks,
+			Timestamp:    ts,
+		}
+		e.snapshotReceived = true
+		e.snapshotMu.Unlock()
+
+		e.log().Info("Received orderbook snapshot", "event", "snapshot", "bids", len(bids), "asks", len(asks))
+		return
+	}
+
+	canonicalUpdate := &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        e.instID,
+		EventTime:     ts,
+		FirstUpdateID: data.PrevSeqID + 1,
+		FinalUpdateID: data.SeqID,
+		PrevUpdateID:  data.PrevSeqID,
+		Bids:          bids,
+		Asks:          asks,
+	}
+
+	// The checksum covers sizes in contracts, so it only matches unscaled levels
+	if e.contractSize.Equal(decimal.NewFromInt(1)) {
+		checksum := uint32(data.Checksum)
+		canonicalUpdate.Checksum = &checksum
+	}
+
+	select {
+	case e.updateChan <- canonicalUpdate:
+	case <-e.ctx.Done():
+	case <-e.done:
+	default:
+		e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+	}
+}
+
+// handleTrades forwards the trades of a trades channel message
+func (e *BookExchange) handleTrades(message []byte) {
+	var msg TradesMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trades", "event", "parse_failed", "error", err)
+		return
+	}
+	for _, trade := range msg.Data {
+		level := e.convertLevels([][]string{{trade.Px, trade.Sz}})[0]
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.InstID,
+			TradeID:   trade.TradeID,
+			Price:     level.Price,
+			Quantity:  level.Quantity,
+			Side:      exchange.TradeSide(trade.Side),
+			Timestamp: parseMillis(trade.Ts),
+		})
+	}
+}
+
+// handleFunding merges a funding channel message into the funding of the swap
+// Open interest is published in contracts and is converted like book sizes
+func (e *BookExchange) handleFunding(message []byte) {
+	var msg FundingMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse funding", "event", "parse_failed", "error", err)
+		return
+	}
+	for _, data := range msg.Data {
+		update := exchange.Funding{
+			Exchange:   e.GetName(),
+			Symbol:     e.instID,
+			Rate:       data.FundingRate,
+			MarkPrice:  data.MarkPx,
+			IndexPrice: data.IdxPx,
+			Timestamp:  parseMillis(data.Ts),
+		}
+		if data.FundingTime != "" {
+			update.NextFunding = parseMillis(data.FundingTime)
+		}
+		if oi, err := decimal.NewFromString(data.Oi); err == nil {
+			update.OpenInterest = oi.Mul(e.contractSize).String()
+		}
+		exchange.SendFunding(e.GetName(), e.fundingChan, e.funding.Merge(update))
+	}
+}
+
+// Resync resubscribes every connection so OKX sends a fresh snapshot
+func (e *BookExchange) Resync() error {
+	e.snapshotMu.Lock()
+	e.snapshot = nil
+	e.snapshotReceived = false
+	e.snapshotMu.Unlock()
+
+	for _, c := range e.pool.Conns() {
+		if err := e.sendOp(c, "unsubscribe", e.channel); err != nil {
+			return fmt.Errorf("failed to unsubscribe on connection %d: %w", c.Index, err)
+		}
+		if err := e.sendOp(c, "subscribe", e.channel); err != nil {
+			return fmt.Errorf("failed to subscribe on connection %d: %w", c.Index, err)
+		}
+	}
+
+	e.log().Info("Resubscribed", "event", "resubscribed", "channel", e.channel)
+	return nil
+}
+
+// handleSubscribeError falls back to the public books channel when tick-by-tick
+// books are refused, since books-l2-tbt is restricted to logged-in VIP accounts
+// Every connection is resubscribed, as they all share the refused channel
+func (e *BookExchange) handleSubscribeError(msg *WSMessage) {
+	e.log().Error("Request error", "event", "request_failed", "code", msg.Code, "error", msg.Msg)
+
+	if e.channel != channelBooksL2TBT {
+		return
+	}
+
+	e.channel = channelBooks
+	e.log().Warn("Falling back to another channel", "event", "channel_fallback", "channel", e.channel)
+
+	for _, c := range e.pool.Conns() {
+		if err := e.sendOp(c, "subscribe", e.channel); err != nil {
+			e.log().Error("Failed to subscribe", "event", "subscribe_failed", "channel", e.channel, "connection", c.Index, "error", err)
+		}
+	}
+}
+
+// loadContractSize fetches the contract value used to convert contract sizes to base units
+// Inverse contracts are valued in the quote currency and keep their contract sizes
+func (e *BookExchange) loadContractSize(ctx context.Context) error {
+	url := fmt.Sprintf("%s?instType=%s&instId=%s", instrumentsURL, e.instType, e.instID)
+	if e.instType == InstTypeOption {
+		url += "&instFamily=" + instFamily(e.instID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get instrument: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var instruments InstrumentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&instruments); err != nil {
+		return fmt.Errorf("failed to decode instrument: %w", err)
+	}
+
+	if instruments.Code != "0" {
+		return fmt.Errorf("API error: code=%s, msg=%s", instruments.Code, instruments.Msg)
+	}
+
+	if len(instruments.Data) == 0 {
+		return fmt.Errorf("instrument not found")
+	}
+
+	info := instruments.Data[0]
+	base := strings.SplitN(e.instID, "-", 2)[0]
+	if info.CtValCcy != base {
+		e.log().Warn("Contract is not valued in the base asset, sizes stay in contracts", "event", "contract_size", "instrument", e.instID, "currency", info.CtValCcy)
+		return nil
+	}
+
+	ctVal, err := decimal.NewFromString(info.CtVal)
+	if err != nil {
+		return fmt.Errorf("invalid ctVal %q: %w", info.CtVal, err)
+	}
+
+	if info.CtMult != "" {
+		if ctMult, err := decimal.NewFromString(info.CtMult); err == nil {
+			ctVal = ctVal.Mul(ctMult)
+		}
+	}
+
+	e.contractSize = ctVal
+	e.log().Info("Loaded contract size", "event", "contract_size", "instrument", e.instID, "contract_size", ctVal.String(), "currency", base)
+	return nil
+}
+
+// convertLevels converts OKX levels to canonical format, scaling sizes to base units
+func (e *BookExchange) convertLevels(levels [][]string) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+
+		quantity := level[1]
+		if !e.contractSize.Equal(decimal.NewFromInt(1)) {
+			if qty, err := decimal.NewFromString(quantity); err == nil {
+				quantity = qty.Mul(e.contractSize).String()
+			}
+		}
+
+		result = append(result, exchange.PriceLevel{
+			Price:    level[0],
+			Quantity: quantity,
+		})
+	}
+	return result
+}
+
+// buildInstID constructs the OKX instrument ID for an instrument type from the native pair
+// Examples: (SPOT, BTC-USDT) -> BTC-USDT, (SWAP, BTC-USDT) -> BTC-USDT-SWAP,
+// (FUTURES, BTC-USDT, 250627) -> BTC-USDT-250627, (OPTION, BTC-USDT, 250627-100000-C) -> BTC-USD-250627-100000-C
+func buildInstID(instType, symbol, contract string) string {
+	pair := strings.ToUpper(symbol)
+
+	// Fully qualified IDs such as BTC-USD-250627 are used as-is
+	if strings.Count(pair, "-") >= 2 {
+		return pair
+	}
+
+	switch instType {
+	case InstTypeSwap:
+		return pair + "-SWAP"
+	case InstTypeFutures:
+		if contract == "" {
+			logging.For(string(exchange.OKX), symbol).Warn("FUTURES instrument has no expiry, using the pair as-is", "event", "symbol_unresolved", "inst_id", pair)
+			return pair
+		}
+		return pair + "-" + strings.ToUpper(contract)
+	case InstTypeOption:
+		// Options are only listed against USD underlyings
+		underlying := pair
+		for _, quote := range []string{"-USDT", "-USDC"} {
+			if strings.HasSuffix(pair, quote) {
+				underlying = strings.TrimSuffix(pair, quote) + "-USD"
+			}
+		}
+		if contract == "" {
+			logging.For(string(exchange.OKX), symbol).Warn("OPTION instrument has no expiry/strike, using the underlying as-is", "event", "symbol_unresolved", "inst_id", underlying)
+			return underlying
+		}
+		return underlying + "-" + strings.ToUpper(contract)
+	default:
+		return pair
+	}
+}
+
+// channelForInstType selects the books channel for an instrument type
+// Spot and perpetual books are busy enough to benefit from tick-by-tick updates;
+// dated futures and options use the 100ms books channel
+func channelForInstType(instType string) string {
+	switch instType {
+	case InstTypeSpot, InstTypeSwap:
+		return channelBooksL2TBT
+	default:
+		return channelBooks
+	}
+}
+
+// instFamily returns the instrument family (e.g., BTC-USD) of an instrument ID
+func instFamily(instID string) string {
+	parts := strings.Split(instID, "-")
+	if len(parts) < 2 {
+		return instID
+	}
+	return parts[0] + "-" + parts[1]
+}
+
+// parseMillis parses an OKX millisecond timestamp string, falling back to the local clock
+func parseMillis(value string) time.Time {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Now()
+	}
+	return time.UnixMilli(ms)
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *BookExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *BookExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *BookExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *BookExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -4,18 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"strings"
+	"strconv"
 	"sync/atomic"
 	"time"
 
-	"orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
 )
 
 const (
 	pollInterval = 1 * time.Second
 	restBaseURL  = "https://www.okx.com/api/v5/market/books-full"
+	tradesURL    = "https://www.okx.com/api/v5/market/trades"
 )
 
 // SpotExchange implements the Exchange interface for OKX using REST polling
@@ -24,18 +26,20 @@ type SpotExchange struct {
 	instId     string // OKX format (e.g., BTC-USDT)
 	restURL    string
 	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
 	done       chan struct{}
 	ctx        context.Context
 	cancel     context.CancelFunc
 	health     atomic.Value
 	isRunning  bool
+	lastTrade  int64 // Newest trade ID forwarded, 0 before the first trades poll
 }
 
 // NewSpotExchange creates a new OKX Spot exchange instance
 func NewSpotExchange(config Config) *SpotExchange {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	instId := convertToOKXSymbol(config.Symbol)
+	instId := config.Symbol
 	restURL := fmt.Sprintf("%s?instId=%s&sz=5000", restBaseURL, instId)
 
 	ex := &SpotExchange{
@@ -43,6 +47,7 @@ func NewSpotExchange(config Config) *SpotExchange {
 		instId:     instId,
 		restURL:    restURL,
 		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
 		done:       make(chan struct{}),
 		ctx:        ctx,
 		cancel:     cancel,
@@ -69,10 +74,15 @@ func (e *SpotExchange) GetSymbol() string {
 	return e.symbol
 }
 
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
 // Connect starts the REST polling loop
 func (e *SpotExchange) Connect(ctx context.Context) error {
 	e.updateConnectionStatus(true)
-	log.Printf("[%s] Starting REST polling (interval: %v)", e.GetName(), pollInterval)
+	e.log().Info("Starting REST polling", "event", "polling", "interval", pollInterval)
 
 	e.isRunning = true
 	go e.pollLoop()
@@ -93,7 +103,7 @@ func (e *SpotExchange) Close() error {
 	}
 
 	e.updateConnectionStatus(false)
-	log.Printf("[%s] Polling stopped", e.GetName())
+	e.log().Info("Polling stopped", "event", "poll_stopped")
 	return nil
 }
 
@@ -137,6 +147,11 @@ func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
 	return e.updateChan
 }
 
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
 // IsConnected checks if the polling is active
 func (e *SpotExchange) IsConnected() bool {
 	return e.isRunning
@@ -150,9 +165,20 @@ func (e *SpotExchange) Health() exchange.HealthStatus {
 	return exchange.HealthStatus{}
 }
 
+// Capabilities returns the features of the OKX REST poller feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       5000,
+		UpdateInterval: pollInterval,
+		Trades:         true,
+		NativeSymbol:   e.instId,
+	}
+}
+
 // pollLoop continuously polls REST endpoint every second
 func (e *SpotExchange) pollLoop() {
 	defer close(e.updateChan)
+	defer close(e.tradeChan)
 	defer e.updateConnectionStatus(false)
 
 	ticker := time.NewTicker(pollInterval)
@@ -161,7 +187,7 @@ func (e *SpotExchange) pollLoop() {
 	for {
 		select {
 		case <-e.ctx.Done():
-			log.Printf("[%s] Context cancelled, stopping polling", e.GetName())
+			e.log().Debug("Stopped polling", "event", "poll_stopped")
 			return
 		case <-e.done:
 			return
@@ -178,13 +204,18 @@ func (e *SpotExchange) poll() {
 
 	snapshot, err := e.GetSnapshot(ctx)
 	if err != nil {
-		log.Printf("[%s] Failed to poll: %v", e.GetName(), err)
+		e.log().Error("Failed to poll", "event", "poll_failed", "error", err)
 		return
 	}
 
 	e.incrementMessageCount()
 	e.updateLastPing()
 
+	if err := e.pollTrades(ctx); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to poll trades", "event", "poll_failed", "error", err)
+	}
+
 	update := &exchange.DepthUpdate{
 		Exchange:      e.GetName(),
 		Symbol:        e.instId,
@@ -201,10 +232,61 @@ func (e *SpotExchange) poll() {
 	case <-e.ctx.Done():
 	case <-e.done:
 	default:
-		log.Printf("[%s] Warning: update channel full, skipping update", e.GetName())
+		e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", update.FinalUpdateID)
 	}
 }
 
+// pollTrades forwards the trades since the previous poll, oldest first
+// The first poll only records where the trade history stands
+func (e *SpotExchange) pollTrades(ctx context.Context) error {
+	url := fmt.Sprintf("%s?instId=%s&limit=500", tradesURL, e.instId)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get trades: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var trades TradesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&trades); err != nil {
+		return fmt.Errorf("failed to decode trades: %w", err)
+	}
+
+	if trades.Code != "0" {
+		return fmt.Errorf("API error: code=%s, msg=%s", trades.Code, trades.Msg)
+	}
+
+	first := e.lastTrade == 0
+	newest := e.lastTrade
+	for i := len(trades.Data) - 1; i >= 0; i-- {
+		trade := trades.Data[i]
+		id, err := strconv.ParseInt(trade.TradeID, 10, 64)
+		if err != nil || id <= e.lastTrade {
+			continue
+		}
+		newest = max(newest, id)
+		if first {
+			continue
+		}
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    trade.InstID,
+			TradeID:   trade.TradeID,
+			Price:     trade.Px,
+			Quantity:  trade.Sz,
+			Side:      exchange.TradeSide(trade.Side),
+			Timestamp: parseMillis(trade.Ts),
+		})
+	}
+	e.lastTrade = newest
+	return nil
+}
+
 // convertSnapshot converts OKX REST snapshot to canonical format
 func (e *SpotExchange) convertSnapshot(data *OrderBookData) *exchange.Snapshot {
 	bids := make([]exchange.PriceLevel, len(data.Bids))
@@ -237,37 +319,12 @@ func (e *SpotExchange) convertSnapshot(data *OrderBookData) *exchange.Snapshot {
 	}
 }
 
-// convertToOKXSymbol converts various symbol formats to OKX format
-// Examples: BTCUSDT -> BTC-USDT, BTC-USDT -> BTC-USDT
-func convertToOKXSymbol(symbol string) string {
-	if strings.Contains(symbol, "-") {
-		return strings.ToUpper(symbol)
-	}
-
-	symbol = strings.ToUpper(symbol)
-
-	if strings.HasSuffix(symbol, "USDT") {
-		base := strings.TrimSuffix(symbol, "USDT")
-		return fmt.Sprintf("%s-USDT", base)
-	}
-
-	if strings.HasSuffix(symbol, "USD") && !strings.HasSuffix(symbol, "USDT") {
-		base := strings.TrimSuffix(symbol, "USD")
-		return fmt.Sprintf("%s-USD", base)
-	}
-
-	if strings.HasSuffix(symbol, "USDC") {
-		base := strings.TrimSuffix(symbol, "USDC")
-		return fmt.Sprintf("%s-USDC", base)
-	}
-
-	log.Printf("[OKX] Warning: Could not convert symbol %s to OKX format, using as-is", symbol)
-	return symbol
-}
-
 // updateConnectionStatus updates the connection status in health
 func (e *SpotExchange) updateConnectionStatus(connected bool) {
 	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
 	status.Connected = connected
 	if !connected {
 		now := time.Now()
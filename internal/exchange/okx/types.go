@@ -2,7 +2,7 @@ package okx
 
 // Config holds configuration for OKX exchange
 type Config struct {
-	Symbol string
+	Symbol string // Native symbol (e.g. BTC-USDT), as resolved by symbols.Native
 }
 
 // OrderBookResponse represents the REST API response for OKX order book
@@ -18,3 +18,125 @@ type OrderBookData struct {
 	Bids [][]string `json:"bids"` // [price, quantity, deprecated, order_count]
 	Ts   string     `json:"ts"`   // timestamp
 }
+
+// Instrument types accepted by OKX
+const (
+	InstTypeSpot    = "SPOT"
+	InstTypeSwap    = "SWAP"
+	InstTypeFutures = "FUTURES"
+	InstTypeOption  = "OPTION"
+)
+
+// BookConfig holds configuration for an OKX WebSocket book of a given instrument type
+type BookConfig struct {
+	Symbol   string // Native symbol (e.g. BTC-USDT), as resolved by symbols.Native
+	InstType string // SPOT, SWAP, FUTURES or OPTION
+	Contract string // Expiry (FUTURES, e.g. 250627) or expiry-strike-type (OPTION, e.g. 250627-100000-C)
+
+	// Optional API credentials; a logged-in session with a VIP tier unlocks tick-by-tick books
+	APIKey     string
+	APISecret  string
+	Passphrase string
+}
+
+// WSRequest represents an OKX WebSocket request
+type WSRequest struct {
+	Op   string  `json:"op"` // "subscribe" or "unsubscribe"
+	Args []WSArg `json:"args"`
+}
+
+// WSLoginRequest represents an OKX WebSocket login request
+type WSLoginRequest struct {
+	Op   string       `json:"op"` // "login"
+	Args []WSLoginArg `json:"args"`
+}
+
+// WSLoginArg carries the signed login credentials
+type WSLoginArg struct {
+	APIKey     string `json:"apiKey"`
+	Passphrase string `json:"passphrase"`
+	Timestamp  string `json:"timestamp"` // Unix seconds
+	Sign       string `json:"sign"`      // base64(HMAC-SHA256(secret, timestamp + "GET" + "/users/self/verify"))
+}
+
+// WSArg identifies a channel subscription
+type WSArg struct {
+	Channel string `json:"channel"`
+	InstID  string `json:"instId"`
+}
+
+// WSMessage represents a WebSocket message from OKX
+type WSMessage struct {
+	Event  string     `json:"event"` // "subscribe", "login", "error"
+	Code   string     `json:"code"`
+	Msg    string     `json:"msg"`
+	Arg    *WSArg     `json:"arg"`
+	Action string     `json:"action"` // "snapshot" or "update"
+	Data   []BookData `json:"data"`
+}
+
+// BookData represents a books channel payload
+type BookData struct {
+	Asks      [][]string `json:"asks"` // [price, quantity, deprecated, order_count]
+	Bids      [][]string `json:"bids"` // [price, quantity, deprecated, order_count]
+	Ts        string     `json:"ts"`
+	SeqID     int64      `json:"seqId"`
+	PrevSeqID int64      `json:"prevSeqId"`
+	Checksum  int64      `json:"checksum"` // Signed CRC32 of the top 25 levels
+}
+
+// TradesMessage represents a trades channel message
+type TradesMessage struct {
+	Arg  *WSArg      `json:"arg"`
+	Data []TradeData `json:"data"`
+}
+
+// FundingMessage represents a message of the funding-rate, mark-price, open-interest or
+// index-tickers channel; each fills its own fields of the data
+type FundingMessage struct {
+	Arg  *WSArg        `json:"arg"`
+	Data []FundingData `json:"data"`
+}
+
+// FundingData represents the payload of a funding channel
+type FundingData struct {
+	InstID      string `json:"instId"`
+	FundingRate string `json:"fundingRate"` // funding-rate
+	FundingTime string `json:"fundingTime"` // funding-rate, next settlement in Unix milliseconds
+	MarkPx      string `json:"markPx"`      // mark-price
+	Oi          string `json:"oi"`          // open-interest, in contracts
+	IdxPx       string `json:"idxPx"`       // index-tickers
+	Ts          string `json:"ts"`
+}
+
+// TradesResponse represents the REST API response for OKX recent trades, newest first
+type TradesResponse struct {
+	Code string      `json:"code"`
+	Msg  string      `json:"msg"`
+	Data []TradeData `json:"data"`
+}
+
+// TradeData represents one trade
+type TradeData struct {
+	InstID  string `json:"instId"`
+	TradeID string `json:"tradeId"`
+	Px      string `json:"px"`
+	Sz      string `json:"sz"`   // Contracts for derivatives
+	Side    string `json:"side"` // Taker side: "buy" or "sell"
+	Ts      string `json:"ts"`
+}
+
+// InstrumentsResponse represents the REST API response for OKX public instruments
+type InstrumentsResponse struct {
+	Code string           `json:"code"`
+	Msg  string           `json:"msg"`
+	Data []InstrumentInfo `json:"data"`
+}
+
+// InstrumentInfo holds the contract details needed to convert sizes to base units
+type InstrumentInfo struct {
+	InstID   string `json:"instId"`
+	CtVal    string `json:"ctVal"`    // Contract value
+	CtValCcy string `json:"ctValCcy"` // Currency of the contract value
+	CtMult   string `json:"ctMult"`   // Contract multiplier
+}
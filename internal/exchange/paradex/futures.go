@@ -0,0 +1,395 @@
+package paradex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+const wsURL = "wss://ws.api.prod.paradex.trade/v1"
+
+// FuturesExchange implements the Exchange interface for Paradex perpetuals
+// The deltas channel opens with a full snapshot and then streams inserts,
+// updates and deletes sequenced by seq_no
+type FuturesExchange struct {
+	symbol           string
+	market           string // Paradex format (e.g., BTC-USD-PERP)
+	wsConn           *websocket.Conn
+	writeMu          sync.Mutex
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	requestID        int64
+	lastSeqNo        int64
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+	snapshotReceived bool
+}
+
+// NewFuturesExchange creates a new Paradex perpetual exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		market:     config.Symbol,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.Paradexf
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Paradex
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	channel := fmt.Sprintf("order_book.%s.deltas", e.market)
+	if err := e.send("subscribe", map[string]interface{}{"channel": channel}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	tradesChannel := fmt.Sprintf("trades.%s", e.market)
+	if err := e.send("subscribe", map[string]interface{}{"channel": tradesChannel}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", channel, "trade_channel", tradesChannel)
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the orderbook snapshot received from WebSocket
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Paradex perpetual feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		Trades:       true,
+		NativeSymbol: e.market,
+	}
+}
+
+// send writes a JSON-RPC request
+// Paradex pings with WebSocket control frames, which gorilla answers automatically
+func (e *FuturesExchange) send(method string, params map[string]interface{}) error {
+	req := RPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      atomic.AddInt64(&e.requestID, 1),
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg RPCMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.updateLastPing()
+
+			if msg.Error != nil {
+				e.incrementErrorCount()
+				e.log().Error("Request error", "event", "request_failed", "code", msg.Error.Code, "error", msg.Error.Message)
+				continue
+			}
+
+			if msg.Method != "subscription" || msg.Params == nil || len(msg.Params.Data) == 0 {
+				continue
+			}
+
+			e.incrementMessageCount()
+
+			if strings.HasPrefix(msg.Params.Channel, "trades.") {
+				e.handleTrade(msg.Params.Data)
+				continue
+			}
+
+			delta := &BookDelta{}
+			if err := json.Unmarshal(msg.Params.Data, delta); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to parse orderbook", "event", "parse_failed", "error", err)
+				continue
+			}
+			bids, asks := convertDelta(delta)
+
+			if delta.UpdateType == "s" {
+				e.snapshotMu.Lock()
+				e.snapshot = &exchange.Snapshot{
+					Exchange:     e.GetName(),
+					Symbol:       delta.Market,
+					LastUpdateID: delta.SeqNo,
+					Bids:         bids,
+					Asks:         asks,
+					Timestamp:    time.UnixMilli(delta.LastUpdatedAt),
+				}
+				e.snapshotReceived = true
+				e.snapshotMu.Unlock()
+				e.lastSeqNo = delta.SeqNo
+
+				e.log().Info("Received orderbook snapshot", "event", "snapshot", "bids", len(bids), "asks", len(asks))
+				continue
+			}
+
+			prev := e.lastSeqNo
+			e.lastSeqNo = delta.SeqNo
+
+			canonicalUpdate := &exchange.DepthUpdate{
+				Exchange:      e.GetName(),
+				Symbol:        delta.Market,
+				EventTime:     time.UnixMilli(delta.LastUpdatedAt),
+				FirstUpdateID: prev + 1,
+				FinalUpdateID: delta.SeqNo,
+				PrevUpdateID:  prev,
+				Bids:          bids,
+				Asks:          asks,
+			}
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrade forwards a trades channel message
+func (e *FuturesExchange) handleTrade(raw json.RawMessage) {
+	var trade Trade
+	if err := json.Unmarshal(raw, &trade); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trade", "event", "parse_failed", "error", err)
+		return
+	}
+
+	exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.Market,
+		TradeID:   trade.ID,
+		Price:     trade.Price,
+		Quantity:  trade.Size,
+		Side:      exchange.TradeSide(strings.ToLower(trade.Side)),
+		Timestamp: time.UnixMilli(trade.CreatedAt),
+	})
+}
+
+// convertDelta splits inserts, updates and deletes into canonical bids and asks
+// Deletes are emitted with zero quantity so the orderbook removes the level
+func convertDelta(delta *BookDelta) ([]exchange.PriceLevel, []exchange.PriceLevel) {
+	bids := make([]exchange.PriceLevel, 0)
+	asks := make([]exchange.PriceLevel, 0)
+
+	add := func(level BookLevel, quantity string) {
+		priceLevel := exchange.PriceLevel{Price: level.Price, Quantity: quantity}
+		if level.Side == "BUY" {
+			bids = append(bids, priceLevel)
+		} else {
+			asks = append(asks, priceLevel)
+		}
+	}
+
+	for _, level := range delta.Inserts {
+		add(level, level.Size)
+	}
+	for _, level := range delta.Updates {
+		add(level, level.Size)
+	}
+	for _, level := range delta.Deletes {
+		add(level, "0")
+	}
+
+	return bids, asks
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
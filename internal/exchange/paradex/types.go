@@ -0,0 +1,65 @@
+package paradex
+
+import "encoding/json"
+
+// Config holds configuration for Paradex exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC-USD-PERP), as resolved by symbols.Native
+}
+
+// RPCRequest represents a Paradex JSON-RPC request
+type RPCRequest struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	Method  string                 `json:"method"`
+	Params  map[string]interface{} `json:"params"`
+	ID      int64                  `json:"id"`
+}
+
+// RPCMessage represents a Paradex JSON-RPC response or subscription notification
+type RPCMessage struct {
+	ID     *int64              `json:"id"`
+	Method string              `json:"method"` // "subscription" for pushed data
+	Params *SubscriptionParams `json:"params"`
+	Error  *RPCError           `json:"error"`
+}
+
+// SubscriptionParams wraps the channel and payload of a notification; Data is decoded by channel
+type SubscriptionParams struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// BookDelta represents an order_book deltas payload
+// The first message after subscribing has update_type "s" and carries the full book
+type BookDelta struct {
+	SeqNo         int64       `json:"seq_no"`
+	Market        string      `json:"market"`
+	LastUpdatedAt int64       `json:"last_updated_at"` // Milliseconds
+	UpdateType    string      `json:"update_type"`     // "s" snapshot, "d" delta
+	Inserts       []BookLevel `json:"inserts"`
+	Updates       []BookLevel `json:"updates"`
+	Deletes       []BookLevel `json:"deletes"`
+}
+
+// BookLevel represents a single price level change
+type BookLevel struct {
+	Side  string `json:"side"` // "BUY" or "SELL"
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// Trade represents a trades channel payload
+type Trade struct {
+	ID        string `json:"id"`
+	Market    string `json:"market"`
+	Side      string `json:"side"` // Taker side: "BUY" or "SELL"
+	Size      string `json:"size"`
+	Price     string `json:"price"`
+	CreatedAt int64  `json:"created_at"` // Milliseconds
+}
+
+// RPCError represents a JSON-RPC error
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,412 @@
+package phemex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	wsURL        = "wss://ws.phemex.com"
+	pingInterval = 5 * time.Second
+
+	// Spot priceEp and baseQtyEv are both scaled by 10^8 for USDT pairs
+	spotPriceScale = 8
+	spotQtyScale   = 8
+)
+
+// SpotExchange implements the Exchange interface for Phemex Spot
+type SpotExchange struct {
+	symbol           string
+	phemexSymbol     string // Phemex spot format (e.g., sBTCUSDT)
+	wsConn           *websocket.Conn
+	writeMu          sync.Mutex
+	updateChan       chan *exchange.DepthUpdate
+	tradeChan        chan *exchange.Trade
+	done             chan struct{}
+	ctx              context.Context
+	cancel           context.CancelFunc
+	health           atomic.Value // stores exchange.HealthStatus
+	requestID        int64
+	lastSequence     int64
+	snapshot         *exchange.Snapshot
+	snapshotMu       sync.Mutex
+	snapshotReceived bool
+}
+
+// NewSpotExchange creates a new Phemex Spot exchange instance
+func NewSpotExchange(config Config) *SpotExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ex := &SpotExchange{
+		symbol:       config.Symbol,
+		phemexSymbol: config.Symbol,
+		updateChan:   make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:    make(chan *exchange.Trade, 1000),
+		done:         make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *SpotExchange) GetName() exchange.ExchangeName {
+	return exchange.Phemex
+}
+
+// GetSymbol returns the trading symbol
+func (e *SpotExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *SpotExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to Phemex Spot
+func (e *SpotExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	if err := e.send("orderbook.subscribe", e.phemexSymbol); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	if err := e.send("trade.subscribe", e.phemexSymbol); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", "orderbook", "trade_channel", "trade")
+
+	go e.pingLoop()
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *SpotExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot returns the orderbook snapshot received from WebSocket
+func (e *SpotExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Waiting for orderbook snapshot from WebSocket", "event", "snapshot_wait")
+
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("timeout waiting for snapshot")
+		default:
+			e.snapshotMu.Lock()
+			if e.snapshotReceived && e.snapshot != nil {
+				snap := e.snapshot
+				e.snapshotMu.Unlock()
+				return snap, nil
+			}
+			e.snapshotMu.Unlock()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// Updates returns a channel that receives depth updates
+func (e *SpotExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *SpotExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *SpotExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *SpotExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the Phemex Spot feed
+func (e *SpotExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:     30,
+		Trades:       true,
+		NativeSymbol: e.phemexSymbol,
+	}
+}
+
+// send writes a JSON-RPC request
+func (e *SpotExchange) send(method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	req := Request{
+		ID:     atomic.AddInt64(&e.requestID, 1),
+		Method: method,
+		Params: params,
+	}
+
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// pingLoop sends the server.ping heartbeat Phemex requires at least every 30 seconds
+func (e *SpotExchange) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-e.done:
+			return
+		case <-ticker.C:
+			if err := e.send("server.ping"); err != nil {
+				e.log().Error("Failed to send ping", "event", "heartbeat_failed", "error", err)
+			}
+		}
+	}
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *SpotExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg SpotBookMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.updateLastPing()
+
+			if msg.Error != nil {
+				e.incrementErrorCount()
+				e.log().Error("Request error", "event", "request_failed", "code", msg.Error.Code, "error", msg.Error.Message)
+				continue
+			}
+
+			// The snapshot of recent trades sent on subscribing is skipped
+			if len(msg.Trades) > 0 {
+				e.incrementMessageCount()
+				if msg.Type == "incremental" {
+					e.handleTrades(msg.Symbol, msg.Trades)
+				}
+				continue
+			}
+
+			// Pong and subscription acks carry no book
+			if msg.Book == nil {
+				continue
+			}
+
+			e.incrementMessageCount()
+
+			bids := convertScaledLevels(msg.Book.Bids)
+			asks := convertScaledLevels(msg.Book.Asks)
+
+			if msg.Type == "snapshot" {
+				e.snapshotMu.Lock()
+				e.snapshot = &exchange.Snapshot{
+					Exchange:     e.GetName(),
+					Symbol:       msg.Symbol,
+					LastUpdateID: msg.Sequence,
+					Bids:         bids,
+					Asks:         asks,
+					Timestamp:    time.Unix(0, msg.Timestamp),
+				}
+				e.snapshotReceived = true
+				e.snapshotMu.Unlock()
+				e.lastSequence = msg.Sequence
+
+				e.log().Info("Received orderbook snapshot", "event", "snapshot", "bids", len(bids), "asks", len(asks))
+				continue
+			}
+
+			prev := e.lastSequence
+			e.lastSequence = msg.Sequence
+
+			canonicalUpdate := &exchange.DepthUpdate{
+				Exchange:      e.GetName(),
+				Symbol:        msg.Symbol,
+				EventTime:     time.Unix(0, msg.Timestamp),
+				FirstUpdateID: prev + 1,
+				FinalUpdateID: msg.Sequence,
+				PrevUpdateID:  prev,
+				Bids:          bids,
+				Asks:          asks,
+			}
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrades forwards trades, unscaling their prices and quantities
+func (e *SpotExchange) handleTrades(symbol string, trades []TradeRow) {
+	for _, trade := range trades {
+		var priceEp, qtyEv int64
+		if json.Unmarshal(trade.Price, &priceEp) != nil || json.Unmarshal(trade.Quantity, &qtyEv) != nil {
+			e.incrementErrorCount()
+			e.log().Error("Failed to parse trade price or quantity", "event", "parse_failed")
+			continue
+		}
+		exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+			Exchange:  e.GetName(),
+			Symbol:    symbol,
+			Price:     unscale(priceEp, spotPriceScale),
+			Quantity:  unscale(qtyEv, spotQtyScale),
+			Side:      exchange.TradeSide(strings.ToLower(trade.Side)),
+			Timestamp: time.Unix(0, trade.Timestamp),
+		})
+	}
+}
+
+// convertScaledLevels converts [priceEp, qtyEv] levels to decimal strings
+func convertScaledLevels(levels [][2]int64) []exchange.PriceLevel {
+	result := make([]exchange.PriceLevel, len(levels))
+	for i, level := range levels {
+		result[i] = exchange.PriceLevel{
+			Price:    unscale(level[0], spotPriceScale),
+			Quantity: unscale(level[1], spotQtyScale),
+		}
+	}
+	return result
+}
+
+// unscale converts a Phemex scaled integer into its decimal string
+// Example: unscale(6500012000000, 8) -> "65000.12"
+func unscale(value int64, scale int32) string {
+	return decimal.New(value, -scale).String()
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *SpotExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *SpotExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *SpotExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *SpotExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
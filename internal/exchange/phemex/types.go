@@ -0,0 +1,90 @@
+package phemex
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Config holds configuration for Phemex exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. sBTCUSDT on spot, BTCUSDT on futures), as resolved by symbols.Native
+}
+
+// Request represents a Phemex WebSocket JSON-RPC request
+type Request struct {
+	ID     int64         `json:"id"`
+	Method string        `json:"method"` // "orderbook.subscribe", "orderbook_p.subscribe", "server.ping"
+	Params []interface{} `json:"params"`
+}
+
+// SpotBookMessage represents a spot orderbook message
+// Spot prices and quantities are scaled integers (priceEp / baseQtyEv)
+type SpotBookMessage struct {
+	Book      *ScaledBook `json:"book"`
+	Trades    []TradeRow  `json:"trades"` // Set on trade messages instead of Book
+	Depth     int         `json:"depth"`
+	Sequence  int64       `json:"sequence"`
+	Symbol    string      `json:"symbol"`
+	Timestamp int64       `json:"timestamp"` // Nanoseconds
+	Type      string      `json:"type"`      // "snapshot" or "incremental"
+	Error     *Error      `json:"error"`
+}
+
+// ScaledBook holds [priceEp, qtyEv] levels
+type ScaledBook struct {
+	Asks [][2]int64 `json:"asks"`
+	Bids [][2]int64 `json:"bids"`
+}
+
+// FuturesBookMessage represents a USDT-margined perpetual orderbook message
+// Perpetual books on the orderbook_p channel carry real-valued strings
+type FuturesBookMessage struct {
+	Book      *StringBook `json:"orderbook_p"`
+	Trades    []TradeRow  `json:"trades_p"` // Set on trade messages instead of Book
+	Depth     int         `json:"depth"`
+	Sequence  int64       `json:"sequence"`
+	Symbol    string      `json:"symbol"`
+	Timestamp int64       `json:"timestamp"` // Nanoseconds
+	Type      string      `json:"type"`      // "snapshot" or "incremental"
+	Error     *Error      `json:"error"`
+}
+
+// StringBook holds [price, qty] levels
+type StringBook struct {
+	Asks [][]string `json:"asks"`
+	Bids [][]string `json:"bids"`
+}
+
+// TradeRow represents a [timestamp, side, price, qty] trade entry; price and qty are
+// scaled integers on spot and strings on perpetuals
+type TradeRow struct {
+	Timestamp int64  // Nanoseconds
+	Side      string // Taker side: "Buy" or "Sell"
+	Price     json.RawMessage
+	Quantity  json.RawMessage
+}
+
+// UnmarshalJSON decodes the heterogeneous array format used by Phemex
+func (t *TradeRow) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 4 {
+		return fmt.Errorf("unexpected trade entry length %d", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &t.Timestamp); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &t.Side); err != nil {
+		return err
+	}
+	t.Price, t.Quantity = raw[2], raw[3]
+	return nil
+}
+
+// Error represents a Phemex request error
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,46 @@
+package poloniex
+
+import "encoding/json"
+
+// Config holds configuration for Poloniex exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. BTC_USDT), as resolved by symbols.Native
+}
+
+// WSRequest represents a Poloniex WebSocket request
+type WSRequest struct {
+	Event   string   `json:"event"` // "subscribe" or "ping"
+	Channel []string `json:"channel,omitempty"`
+	Symbols []string `json:"symbols,omitempty"`
+}
+
+// WSMessage represents a WebSocket message from Poloniex; Data is decoded by channel
+type WSMessage struct {
+	Event   string          `json:"event"`   // "subscribe", "pong" or "error" for control messages
+	Channel string          `json:"channel"` // e.g., book_lv2, trades
+	Action  string          `json:"action"`  // "snapshot" or "update"
+	Data    json.RawMessage `json:"data"`
+	Message string          `json:"message"`
+}
+
+// BookEvent represents a book_lv2 payload
+// Each update carries the id of the previous event in lastId
+type BookEvent struct {
+	Symbol     string     `json:"symbol"`
+	CreateTime int64      `json:"createTime"` // Milliseconds
+	Asks       [][]string `json:"asks"`       // [price, quantity]
+	Bids       [][]string `json:"bids"`       // [price, quantity]
+	LastID     int64      `json:"lastId"`
+	ID         int64      `json:"id"`
+	Ts         int64      `json:"ts"`
+}
+
+// TradeEvent represents a trades payload
+type TradeEvent struct {
+	Symbol     string `json:"symbol"`
+	ID         string `json:"id"`
+	Price      string `json:"price"`
+	Quantity   string `json:"quantity"`
+	TakerSide  string `json:"takerSide"`  // "buy" or "sell"
+	CreateTime int64  `json:"createTime"` // Milliseconds
+}
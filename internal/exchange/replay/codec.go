@@ -0,0 +1,159 @@
+package replay
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// decoder reads records one at a time; json.Decoder and gob.Decoder both satisfy it
+type decoder interface {
+	Decode(v interface{}) error
+}
+
+// encoder writes records one at a time; json.Encoder and gob.Encoder both satisfy it
+type encoder interface {
+	Encode(v interface{}) error
+}
+
+// detectFormat picks the format from the file extension, ignoring a trailing .gz or .zst
+// Examples: book.ndjson -> ndjson, book.bin.gz -> binary, book.gob -> binary
+func detectFormat(path string) Format {
+	path = strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(path), ".gz"), ".zst")
+	ext := filepath.Ext(path)
+	if ext == ".bin" || ext == ".gob" {
+		return FormatBinary
+	}
+	return FormatNDJSON
+}
+
+// newDecoder creates a record decoder for the given format
+func newDecoder(r io.Reader, format Format) (decoder, error) {
+	switch format {
+	case FormatNDJSON:
+		return json.NewDecoder(r), nil
+	case FormatBinary:
+		return gob.NewDecoder(r), nil
+	default:
+		return nil, fmt.Errorf("unknown replay format: %s", format)
+	}
+}
+
+// openRecording opens a recording for reading, decompressing .gz and .zst files
+// The returned closer releases both the decompressor and the file
+func openRecording(path string, format Format) (decoder, io.Closer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var r io.Reader = file
+	var closer io.Closer = file
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		r = gz
+		closer = multiCloser{gz, file}
+	} else if strings.HasSuffix(strings.ToLower(path), ".zst") {
+		zr, err := zstd.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to open zstd stream: %w", err)
+		}
+		r = zr
+		closer = multiCloser{zr.IOReadCloser(), file}
+	}
+
+	dec, err := newDecoder(r, format)
+	if err != nil {
+		closer.Close()
+		return nil, nil, err
+	}
+
+	return dec, closer, nil
+}
+
+// multiCloser closes several closers in order, returning the first error
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var first error
+	for _, c := range m {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Writer encodes snapshots and depth updates into a recording that FileExchange can replay
+type Writer struct {
+	enc encoder
+}
+
+// NewWriter creates a Writer that encodes records to w in the given format
+func NewWriter(w io.Writer, format Format) (*Writer, error) {
+	switch format {
+	case FormatNDJSON:
+		return &Writer{enc: json.NewEncoder(w)}, nil
+	case FormatBinary:
+		return &Writer{enc: gob.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown replay format: %s", format)
+	}
+}
+
+// WriteSnapshot records a snapshot received at the given time
+func (w *Writer) WriteSnapshot(at time.Time, snapshot *exchange.Snapshot) error {
+	return w.enc.Encode(&Record{Type: RecordSnapshot, Time: at, Snapshot: snapshot})
+}
+
+// WriteUpdate records a depth update received at the given time
+func (w *Writer) WriteUpdate(at time.Time, update *exchange.DepthUpdate) error {
+	return w.enc.Encode(&Record{Type: RecordUpdate, Time: at, Update: update})
+}
+
+// Reader decodes the records of a recording in order, without the replay timing of FileExchange
+type Reader struct {
+	dec    decoder
+	closer io.Closer
+}
+
+// OpenReader opens a recording for reading; an empty format is detected from the extension
+func OpenReader(path string, format Format) (*Reader, error) {
+	if format == "" {
+		format = detectFormat(path)
+	}
+	dec, closer, err := openRecording(path, format)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{dec: dec, closer: closer}, nil
+}
+
+// Next returns the next record, or io.EOF after the last one
+func (r *Reader) Next() (*Record, error) {
+	var rec Record
+	if err := r.dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Close closes the recording
+func (r *Reader) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,327 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// FileExchange implements the Exchange interface by replaying a recording from disk
+// The first snapshot in the file seeds the book; the records after it are replayed
+// with their original spacing, scaled by the configured speed. Later snapshots
+// replace the one returned by GetSnapshot so reinitialization stays consistent
+type FileExchange struct {
+	symbol     string
+	path       string
+	format     Format
+	speed      float64
+	dec        decoder
+	closer     io.Closer
+	updateChan chan *exchange.DepthUpdate
+	done       chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	health     atomic.Value // stores exchange.HealthStatus
+	isRunning  bool
+	lastTime   time.Time
+	snapshot   *exchange.Snapshot
+	snapshotMu sync.Mutex
+}
+
+// NewFileExchange creates a new replay exchange instance
+func NewFileExchange(config Config) *FileExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	path := config.Path
+	if path == "" {
+		path = os.Getenv("REPLAY_FILE")
+	}
+
+	speed := config.Speed
+	if speed <= 0 {
+		if s, err := strconv.ParseFloat(os.Getenv("REPLAY_SPEED"), 64); err == nil && s > 0 {
+			speed = s
+		} else {
+			speed = 1
+		}
+	}
+
+	format := config.Format
+	if format == "" {
+		format = Format(os.Getenv("REPLAY_FORMAT"))
+	}
+	if format == "" {
+		format = detectFormat(path)
+	}
+
+	ex := &FileExchange{
+		symbol:     config.Symbol,
+		path:       path,
+		format:     format,
+		speed:      speed,
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FileExchange) GetName() exchange.ExchangeName {
+	return exchange.File
+}
+
+// GetSymbol returns the trading symbol
+func (e *FileExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FileExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect opens the recording, reads up to the first snapshot and starts the replay
+func (e *FileExchange) Connect(ctx context.Context) error {
+	if e.path == "" {
+		return fmt.Errorf("no recording configured (set REPLAY_FILE)")
+	}
+
+	dec, closer, err := openRecording(e.path, e.format)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	e.dec = dec
+	e.closer = closer
+
+	// Updates recorded before the first snapshot cannot be applied, so skip them
+	skipped := 0
+	for {
+		rec, err := e.next()
+		if err != nil {
+			closer.Close()
+			if err == io.EOF {
+				return fmt.Errorf("recording %s contains no snapshot", e.path)
+			}
+			e.incrementErrorCount()
+			return fmt.Errorf("failed to read recording: %w", err)
+		}
+		if rec.Type == RecordSnapshot && rec.Snapshot != nil {
+			e.storeSnapshot(rec.Snapshot)
+			e.lastTime = rec.Time
+			break
+		}
+		skipped++
+	}
+
+	e.updateConnectionStatus(true)
+	e.log().Info("Replaying recording", "event", "replay_started", "path", e.path, "format", e.format, "speed", e.speed, "skipped", skipped)
+
+	e.isRunning = true
+	go e.replay()
+
+	return nil
+}
+
+// Close stops the replay and closes the recording
+func (e *FileExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	select {
+	case <-e.done:
+	default:
+		close(e.done)
+	}
+
+	e.updateConnectionStatus(false)
+	e.log().Info("Replay stopped", "event", "replay_stopped")
+	return nil
+}
+
+// GetSnapshot returns the most recent snapshot replayed so far
+func (e *FileExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.snapshotMu.Lock()
+	defer e.snapshotMu.Unlock()
+
+	if e.snapshot == nil {
+		return nil, fmt.Errorf("replay not started")
+	}
+	return e.snapshot, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FileExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns nil; recordings hold depth updates only
+func (e *FileExchange) Trades() <-chan *exchange.Trade {
+	return nil
+}
+
+// IsConnected checks if the replay is active
+func (e *FileExchange) IsConnected() bool {
+	return e.isRunning
+}
+
+// Health returns connection health information
+func (e *FileExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the replayed recording feed
+func (e *FileExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		NativeSymbol: e.symbol,
+	}
+}
+
+// replay emits the remaining records with their recorded spacing until the file ends
+func (e *FileExchange) replay() {
+	defer close(e.updateChan)
+	defer e.updateConnectionStatus(false)
+	defer e.closer.Close()
+
+	for {
+		rec, err := e.next()
+		if err == io.EOF {
+			e.log().Info("Reached end of recording", "event", "replay_done")
+			return
+		}
+		if err != nil {
+			e.incrementErrorCount()
+			e.log().Error("Failed to read recording", "event", "read_failed", "error", err)
+			return
+		}
+
+		if !e.wait(rec.Time) {
+			return
+		}
+
+		e.incrementMessageCount()
+		e.updateLastPing()
+
+		switch {
+		case rec.Type == RecordSnapshot && rec.Snapshot != nil:
+			e.storeSnapshot(rec.Snapshot)
+		case rec.Type == RecordUpdate && rec.Update != nil:
+			// A replay must not drop updates, so block instead of skipping when the
+			// consumer falls behind
+			select {
+			case e.updateChan <- rec.Update:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			}
+		default:
+			e.log().Warn("Skipping unknown record type", "event", "record_skipped", "type", rec.Type)
+		}
+	}
+}
+
+// wait sleeps for the recorded gap since the previous record, scaled by the speed
+// It returns false if the replay was stopped while waiting
+func (e *FileExchange) wait(at time.Time) bool {
+	gap := at.Sub(e.lastTime)
+	e.lastTime = at
+
+	delay := time.Duration(float64(gap) / e.speed)
+	if delay <= 0 {
+		select {
+		case <-e.ctx.Done():
+			return false
+		case <-e.done:
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-e.ctx.Done():
+		return false
+	case <-e.done:
+		return false
+	}
+}
+
+// next decodes the next record from the recording
+func (e *FileExchange) next() (*Record, error) {
+	var rec Record
+	if err := e.dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// storeSnapshot replaces the snapshot returned by GetSnapshot
+func (e *FileExchange) storeSnapshot(snapshot *exchange.Snapshot) {
+	e.snapshotMu.Lock()
+	e.snapshot = snapshot
+	e.snapshotMu.Unlock()
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FileExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FileExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FileExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FileExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,185 @@
+package replay
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+func writeRecording(t *testing.T, path string, format Format) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create recording: %v", err)
+	}
+	defer file.Close()
+
+	var gz *gzip.Writer
+	var out io.Writer = file
+	if filepath.Ext(path) == ".gz" {
+		gz = gzip.NewWriter(file)
+		out = gz
+	}
+
+	w, err := NewWriter(out, format)
+	if err != nil {
+		t.Fatalf("NewWriter() error: %v", err)
+	}
+
+	start := time.Unix(1700000000, 0)
+	update := func(id int64) *exchange.DepthUpdate {
+		return &exchange.DepthUpdate{
+			Exchange:      exchange.Binance,
+			Symbol:        "BTCUSDT",
+			FirstUpdateID: id,
+			FinalUpdateID: id,
+			PrevUpdateID:  id - 1,
+			Bids:          []exchange.PriceLevel{{Price: "100", Quantity: "2"}},
+		}
+	}
+
+	steps := []error{
+		w.WriteUpdate(start, update(1)),
+		w.WriteSnapshot(start.Add(time.Millisecond), &exchange.Snapshot{
+			Exchange:     exchange.Binance,
+			Symbol:       "BTCUSDT",
+			LastUpdateID: 1,
+			Bids:         []exchange.PriceLevel{{Price: "100", Quantity: "1"}},
+			Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "1"}},
+		}),
+		w.WriteUpdate(start.Add(2*time.Millisecond), update(2)),
+		w.WriteUpdate(start.Add(3*time.Millisecond), update(3)),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatalf("Failed to close gzip stream: %v", err)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		format Format
+	}{
+		{name: "NDJSON", file: "book.ndjson", format: FormatNDJSON},
+		{name: "Binary", file: "book.bin", format: FormatBinary},
+		{name: "Gzipped NDJSON", file: "book.ndjson.gz", format: FormatNDJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeRecording(t, path, tt.format)
+
+			if got := detectFormat(path); got != tt.format {
+				t.Fatalf("detectFormat(%s) = %s, expected %s", tt.file, got, tt.format)
+			}
+
+			ex := NewFileExchange(Config{Symbol: "BTCUSDT", Path: path, Speed: MaxSpeed})
+			if err := ex.Connect(context.Background()); err != nil {
+				t.Fatalf("Connect() error: %v", err)
+			}
+			defer ex.Close()
+
+			snapshot, err := ex.GetSnapshot(context.Background())
+			if err != nil {
+				t.Fatalf("GetSnapshot() error: %v", err)
+			}
+			if snapshot.LastUpdateID != 1 || len(snapshot.Bids) != 1 || len(snapshot.Asks) != 1 {
+				t.Errorf("Unexpected snapshot: %+v", snapshot)
+			}
+
+			var ids []int64
+			for update := range ex.Updates() {
+				ids = append(ids, update.FinalUpdateID)
+			}
+
+			// The update recorded before the snapshot is skipped
+			if len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+				t.Errorf("Expected updates [2 3], got %v", ids)
+			}
+		})
+	}
+}
+
+func TestReplayTiming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.ndjson")
+	writeRecording(t, path, FormatNDJSON)
+
+	// Two 1ms gaps after the snapshot, replayed at 0.01x, take about 200ms
+	ex := NewFileExchange(Config{Symbol: "BTCUSDT", Path: path, Speed: 0.01})
+	if err := ex.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer ex.Close()
+
+	start := time.Now()
+	for range ex.Updates() {
+	}
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected replay to honour recorded timing, finished in %v", elapsed)
+	}
+}
+
+func TestReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.ndjson.gz")
+	writeRecording(t, path, FormatNDJSON)
+
+	r, err := OpenReader(path, "")
+	if err != nil {
+		t.Fatalf("OpenReader() error: %v", err)
+	}
+	defer r.Close()
+
+	var types []string
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error: %v", err)
+		}
+		types = append(types, rec.Type)
+	}
+
+	// Unlike FileExchange, the reader returns the update recorded before the snapshot
+	expected := []string{RecordUpdate, RecordSnapshot, RecordUpdate, RecordUpdate}
+	if len(types) != len(expected) {
+		t.Fatalf("Expected records %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Errorf("Expected records %v, got %v", expected, types)
+			break
+		}
+	}
+}
+
+func TestConnectWithoutSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.ndjson")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("Failed to write recording: %v", err)
+	}
+
+	ex := NewFileExchange(Config{Symbol: "BTCUSDT", Path: path})
+	if err := ex.Connect(context.Background()); err == nil {
+		t.Error("Expected error for recording without snapshot")
+	}
+}
@@ -0,0 +1,43 @@
+package replay
+
+import (
+	"math"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+// MaxSpeed replays records back to back without waiting between them
+var MaxSpeed = math.Inf(1)
+
+// Config holds configuration for a replay source
+// Empty fields fall back to REPLAY_FILE, REPLAY_SPEED and REPLAY_FORMAT
+type Config struct {
+	Symbol string
+	Path   string  // Recording to replay; a trailing .gz or .zst is decompressed
+	Speed  float64 // Playback rate relative to the recorded timing (2 = twice as fast)
+	Format Format  // Encoding of the recording; detected from the extension when empty
+}
+
+// Format identifies how records are encoded on disk
+type Format string
+
+const (
+	FormatNDJSON Format = "ndjson" // One JSON record per line
+	FormatBinary Format = "binary" // encoding/gob stream of records
+)
+
+// Record types
+const (
+	RecordSnapshot = "snapshot"
+	RecordUpdate   = "update"
+)
+
+// Record is a single entry of a recording
+// Time is when the record was received and drives the replay timing
+type Record struct {
+	Type     string                `json:"type"`
+	Time     time.Time             `json:"time"`
+	Snapshot *exchange.Snapshot    `json:"snapshot,omitempty"`
+	Update   *exchange.DepthUpdate `json:"update,omitempty"`
+}
@@ -0,0 +1,332 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+// Config holds configuration for a connection pool
+type Config struct {
+	Name             exchange.ExchangeName // Exchange of the pool's log records
+	Symbol           string                // Symbol of the pool's log records
+	URL              string                // WebSocket endpoint every connection dials
+	MaxTopicsPerConn int                   // Topics per connection; 0 puts every topic on one connection
+	PingInterval     time.Duration         // Interval for PingMessage; 0 disables pings
+	PingMessage      []byte                // Text frame sent every PingInterval
+
+	// OnConnect runs once a connection is dialed and before it subscribes (e.g., to log in)
+	// The read loop has not started yet, so it may read responses itself
+	OnConnect func(c *Conn) error
+
+	// Subscribe sends the subscription request for c.Topics
+	Subscribe func(c *Conn) error
+}
+
+// Handler processes one message read from a connection
+// Topics are pinned to a connection, so messages of one topic are handled in order
+type Handler func(c *Conn, message []byte)
+
+// Pool shards subscription topics across several WebSocket connections, for
+// venues that cap how many topics a single connection may carry
+type Pool struct {
+	config   Config
+	conns    []*Conn
+	done     chan struct{}
+	finished chan struct{}
+	closing  sync.Once
+	wg       sync.WaitGroup
+}
+
+// Conn is one WebSocket connection of a pool and the topics subscribed on it
+type Conn struct {
+	Index   int
+	Topics  []string
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	closed  chan struct{}
+	health  atomic.Value // stores exchange.HealthStatus
+}
+
+// NewPool creates a new connection pool
+func NewPool(config Config) *Pool {
+	return &Pool{
+		config:   config,
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+}
+
+// Connect opens one connection per shard of topics, subscribes each and starts
+// reading, passing every message to handle
+// If any connection fails, the ones already opened are closed again
+func (p *Pool) Connect(ctx context.Context, topics []string, handle Handler) error {
+	if len(topics) == 0 {
+		return fmt.Errorf("no topics to subscribe")
+	}
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	shards := partition(topics, p.config.MaxTopicsPerConn)
+	conns := make([]*Conn, 0, len(shards))
+
+	for i, shardTopics := range shards {
+		c, err := p.open(ctx, &dialer, i, shardTopics)
+		if err != nil {
+			for _, opened := range conns {
+				opened.ws.Close()
+			}
+			return err
+		}
+		conns = append(conns, c)
+	}
+
+	p.conns = conns
+	p.log().Info("Subscribed", "event", "subscribed", "topics", len(topics), "connections", len(conns))
+
+	for _, c := range conns {
+		p.wg.Add(1)
+		go p.readMessages(c, handle)
+		if p.config.PingInterval > 0 && len(p.config.PingMessage) > 0 {
+			go p.pingLoop(c)
+		}
+	}
+
+	go func() {
+		p.wg.Wait()
+		close(p.finished)
+	}()
+
+	return nil
+}
+
+// open dials one connection and subscribes it to its topics
+func (p *Pool) open(ctx context.Context, dialer *websocket.Dialer, index int, topics []string) (*Conn, error) {
+	ws, _, err := dialer.DialContext(ctx, p.config.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("websocket connection %d failed: %w", index, err)
+	}
+
+	c := &Conn{
+		Index:  index,
+		Topics: topics,
+		ws:     ws,
+		closed: make(chan struct{}),
+	}
+	c.health.Store(exchange.HealthStatus{Connected: true})
+
+	if p.config.OnConnect != nil {
+		if err := p.config.OnConnect(c); err != nil {
+			ws.Close()
+			return nil, err
+		}
+	}
+
+	if err := p.config.Subscribe(c); err != nil {
+		ws.Close()
+		return nil, fmt.Errorf("failed to subscribe on connection %d: %w", index, err)
+	}
+
+	return c, nil
+}
+
+// Close closes every connection gracefully
+func (p *Pool) Close() error {
+	var firstErr error
+
+	p.closing.Do(func() {
+		close(p.done)
+
+		for _, c := range p.conns {
+			err := c.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			if err != nil {
+				p.log().Warn("Failed to send close message", "event", "close_failed", "connection", c.Index, "error", err)
+			}
+		}
+
+		select {
+		case <-p.finished:
+		case <-time.After(time.Second):
+		}
+
+		for _, c := range p.conns {
+			c.setConnected(false)
+			if err := c.ws.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	})
+
+	return firstErr
+}
+
+// Done returns a channel that is closed once every connection has stopped reading
+func (p *Pool) Done() <-chan struct{} {
+	return p.finished
+}
+
+// log returns the logger of connection events
+func (p *Pool) log() *slog.Logger {
+	return logging.For(string(p.config.Name), p.config.Symbol)
+}
+
+// Len returns the number of connections the pool opened
+func (p *Pool) Len() int {
+	return len(p.conns)
+}
+
+// Conns returns the connections of the pool, e.g. to resubscribe each of them
+func (p *Pool) Conns() []*Conn {
+	return p.conns
+}
+
+// Connected reports whether any connection is still reading
+func (p *Pool) Connected() bool {
+	for _, c := range p.conns {
+		if c.Health().Connected {
+			return true
+		}
+	}
+	return false
+}
+
+// Health returns the health of every connection, in connection order
+func (p *Pool) Health() []exchange.HealthStatus {
+	statuses := make([]exchange.HealthStatus, len(p.conns))
+	for i, c := range p.conns {
+		statuses[i] = c.Health()
+	}
+	return statuses
+}
+
+// readMessages continuously reads messages from one connection
+func (p *Pool) readMessages(c *Conn, handle Handler) {
+	defer p.wg.Done()
+	defer close(c.closed)
+	defer c.setConnected(false)
+
+	for {
+		_, message, err := exchange.ReadMessage(c.ws, p.config.Name)
+		if err != nil {
+			select {
+			case <-p.done:
+			default:
+				c.incrementErrorCount()
+				p.log().Error("WebSocket read error", "event", "read_failed", "connection", c.Index, "topics", len(c.Topics), "error", err)
+			}
+			return
+		}
+
+		c.recordMessage()
+		handle(c, message)
+	}
+}
+
+// pingLoop sends the configured keepalive message on one connection
+func (p *Pool) pingLoop(c *Conn) {
+	ticker := time.NewTicker(p.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			if err := c.WriteMessage(websocket.TextMessage, p.config.PingMessage); err != nil {
+				p.log().Error("Failed to send ping", "event", "heartbeat_failed", "connection", c.Index, "error", err)
+			}
+		}
+	}
+}
+
+// partition splits topics into shards of at most max topics, keeping their order
+func partition(topics []string, max int) [][]string {
+	if max <= 0 || len(topics) <= max {
+		return [][]string{topics}
+	}
+
+	shards := make([][]string, 0, (len(topics)+max-1)/max)
+	for start := 0; start < len(topics); start += max {
+		end := start + max
+		if end > len(topics) {
+			end = len(topics)
+		}
+		shards = append(shards, topics[start:end])
+	}
+	return shards
+}
+
+// WriteJSON sends a JSON message on the connection
+func (c *Conn) WriteJSON(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteJSON(v)
+}
+
+// WriteMessage sends a raw message on the connection
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteMessage(messageType, data)
+}
+
+// ReadJSON reads one JSON message within timeout
+// Only safe from OnConnect, before the pool starts reading the connection
+func (c *Conn) ReadJSON(v interface{}, timeout time.Duration) error {
+	c.ws.SetReadDeadline(time.Now().Add(timeout))
+	defer c.ws.SetReadDeadline(time.Time{})
+	return c.ws.ReadJSON(v)
+}
+
+// Health returns connection health information
+func (c *Conn) Health() exchange.HealthStatus {
+	if status, ok := c.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// setConnected updates the connection status in health
+func (c *Conn) setConnected(connected bool) {
+	status := c.Health()
+	if status.Connected == connected {
+		return
+	}
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	c.health.Store(status)
+}
+
+// recordMessage increments the message count and updates the last ping time in health
+func (c *Conn) recordMessage() {
+	status := c.Health()
+	status.MessageCount++
+	status.LastPing = time.Now()
+	c.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (c *Conn) incrementErrorCount() {
+	status := c.Health()
+	status.ErrorCount++
+	c.health.Store(status)
+}
@@ -0,0 +1,176 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestPartition(t *testing.T) {
+	topics := []string{"a", "b", "c", "d", "e"}
+
+	cases := []struct {
+		max  int
+		want int
+	}{
+		{0, 1},
+		{5, 1},
+		{10, 1},
+		{2, 3},
+		{1, 5},
+	}
+
+	for _, tc := range cases {
+		shards := partition(topics, tc.max)
+		if len(shards) != tc.want {
+			t.Errorf("partition(max=%d) gave %d shards, want %d", tc.max, len(shards), tc.want)
+			continue
+		}
+
+		var joined []string
+		for _, shard := range shards {
+			if tc.max > 0 && len(shard) > tc.max {
+				t.Errorf("partition(max=%d) shard has %d topics", tc.max, len(shard))
+			}
+			joined = append(joined, shard...)
+		}
+		if strings.Join(joined, ",") != strings.Join(topics, ",") {
+			t.Errorf("partition(max=%d) reordered topics: %v", tc.max, joined)
+		}
+	}
+}
+
+// echoServer replies to every subscribe request with one message per topic
+func echoServer(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var topics []string
+			if err := conn.ReadJSON(&topics); err != nil {
+				return
+			}
+			for _, topic := range topics {
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(topic)); err != nil {
+					return
+				}
+			}
+		}
+	}))
+}
+
+func TestPoolShardsTopics(t *testing.T) {
+	server := echoServer(t)
+	defer server.Close()
+
+	pool := NewPool(Config{
+		Name:             "test",
+		URL:              "ws" + strings.TrimPrefix(server.URL, "http"),
+		MaxTopicsPerConn: 2,
+		Subscribe: func(c *Conn) error {
+			return c.WriteJSON(c.Topics)
+		},
+	})
+
+	var mu sync.Mutex
+	received := make(map[string]int)
+	all := make(chan struct{})
+
+	topics := []string{"a", "b", "c", "d", "e"}
+	err := pool.Connect(context.Background(), topics, func(c *Conn, message []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[string(message)] = c.Index
+		if len(received) == len(topics) {
+			close(all)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	select {
+	case <-all:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out, received %v", received)
+	}
+
+	if pool.Len() != 3 {
+		t.Errorf("pool opened %d connections, want 3", pool.Len())
+	}
+
+	mu.Lock()
+	for i, topic := range topics {
+		if received[topic] != i/2 {
+			t.Errorf("topic %s arrived on connection %d, want %d", topic, received[topic], i/2)
+		}
+	}
+	mu.Unlock()
+
+	for i, status := range pool.Health() {
+		if !status.Connected {
+			t.Errorf("connection %d not connected", i)
+		}
+		if status.MessageCount == 0 {
+			t.Errorf("connection %d counted no messages", i)
+		}
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+
+	select {
+	case <-pool.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool did not finish after Close")
+	}
+
+	if pool.Connected() {
+		t.Error("pool still connected after Close")
+	}
+}
+
+func TestPoolConnectFailureClosesOpened(t *testing.T) {
+	server := echoServer(t)
+	defer server.Close()
+
+	calls := 0
+	pool := NewPool(Config{
+		Name:             "test",
+		URL:              "ws" + strings.TrimPrefix(server.URL, "http"),
+		MaxTopicsPerConn: 1,
+		Subscribe: func(c *Conn) error {
+			calls++
+			if c.Index == 1 {
+				return errors.New("subscribe refused")
+			}
+			return c.WriteJSON(c.Topics)
+		},
+	})
+
+	err := pool.Connect(context.Background(), []string{"a", "b", "c"}, func(c *Conn, message []byte) {})
+	if err == nil {
+		t.Fatal("Connect succeeded despite a failed subscribe")
+	}
+	if calls != 2 {
+		t.Errorf("subscribe called %d times, want 2", calls)
+	}
+	if pool.Len() != 0 {
+		t.Errorf("pool kept %d connections after failure", pool.Len())
+	}
+}
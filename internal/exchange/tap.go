@@ -0,0 +1,56 @@
+package exchange
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/telemetry"
+)
+
+// MessageTap receives every raw message adapters read from their feeds, with the time it
+// was received; it is called on the adapter's read loop and must not block
+type MessageTap interface {
+	Tap(name ExchangeName, received time.Time, message []byte)
+}
+
+// MessageReader is the read side of a WebSocket connection
+type MessageReader interface {
+	ReadMessage() (messageType int, p []byte, err error)
+}
+
+// tap holds the installed MessageTap, nil when none is
+var tap atomic.Pointer[MessageTap]
+
+// SetMessageTap installs a tap for every adapter; nil removes it
+func SetMessageTap(t MessageTap) {
+	if t == nil {
+		tap.Store(nil)
+		return
+	}
+	tap.Store(&t)
+}
+
+// ReadMessage reads one message from conn, passing it to the installed tap
+func ReadMessage(conn MessageReader, name ExchangeName) (int, []byte, error) {
+	messageType, message, err := conn.ReadMessage()
+	if err == nil {
+		telemetry.Received(string(name), len(message))
+		if t := tap.Load(); t != nil {
+			(*t).Tap(name, time.Now(), message)
+		}
+	}
+	return messageType, message, err
+}
+
+// ReadJSON reads one message from conn like ReadMessage and decodes it into v
+func ReadJSON(conn MessageReader, name ExchangeName, v interface{}) error {
+	_, message, err := ReadMessage(conn, name)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = json.Unmarshal(message, v)
+	telemetry.Parsed(string(name), time.Since(start))
+	return err
+}
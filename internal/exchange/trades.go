@@ -0,0 +1,23 @@
+package exchange
+
+import (
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// SendTrade hands a trade to the trades channel of an adapter without blocking its read
+// loop; trades are dropped while the channel is full so they never hold up depth updates
+func SendTrade(name ExchangeName, trades chan<- *Trade, trade *Trade) {
+	select {
+	case trades <- trade:
+	default:
+		logging.For(string(name), trade.Symbol).Warn("Trade channel full, skipping trade", "event", "trade_dropped", "trade_id", trade.TradeID)
+	}
+}
+
+// TakerSide returns the taker side of a trade from the venue's buyer-is-maker flag
+func TakerSide(buyerIsMaker bool) TradeSide {
+	if buyerIsMaker {
+		return TakerSell
+	}
+	return TakerBuy
+}
@@ -11,6 +11,8 @@ type ExchangeName string
 const (
 	Binancef     ExchangeName = "binancef"
 	Binance      ExchangeName = "binance"
+	BinanceUS    ExchangeName = "binanceus"
+	BinanceCoinf ExchangeName = "binancecoinf"
 	Bybitf       ExchangeName = "bybitf"
 	Bybit        ExchangeName = "bybit"
 	Kraken       ExchangeName = "kraken"
@@ -20,6 +22,30 @@ const (
 	Asterdexf    ExchangeName = "asterdexf"
 	BingX        ExchangeName = "bingx"
 	BingXf       ExchangeName = "bingxf"
+	Deribitf     ExchangeName = "deribitf"
+	Gate         ExchangeName = "gate"
+	Gatef        ExchangeName = "gatef"
+	Dydxf        ExchangeName = "dydxf"
+	Bitget       ExchangeName = "bitget"
+	Bitgetf      ExchangeName = "bitgetf"
+	HTX          ExchangeName = "htx"
+	CryptoCom    ExchangeName = "cryptocom"
+	Bitstamp     ExchangeName = "bitstamp"
+	Gemini       ExchangeName = "gemini"
+	Phemex       ExchangeName = "phemex"
+	Phemexf      ExchangeName = "phemexf"
+	WooXf        ExchangeName = "wooxf"
+	Aevof        ExchangeName = "aevof"
+	Paradexf     ExchangeName = "paradexf"
+	Hyperliquid  ExchangeName = "hyperliquid"
+	CoinbaseIntx ExchangeName = "coinbaseintx"
+	Backpack     ExchangeName = "backpack"
+	UniswapV3    ExchangeName = "uniswapv3"
+	Lighterf     ExchangeName = "lighterf"
+	Poloniex     ExchangeName = "poloniex"
+	BitMart      ExchangeName = "bitmart"
+	File         ExchangeName = "file"       // Replays a recording from disk
+	Aggregated   ExchangeName = "aggregated" // Consolidated book across venues
 )
 
 // Exchange defines the interface that all exchange adapters must implement
@@ -42,11 +68,41 @@ type Exchange interface {
 	// Updates returns a channel that receives depth updates in canonical format
 	Updates() <-chan *DepthUpdate
 
+	// Trades returns a channel that receives public trades in canonical format
+	// It is nil when the venue has no trades feed, and closed with Updates otherwise
+	Trades() <-chan *Trade
+
 	// IsConnected returns connection status
 	IsConnected() bool
 
 	// Health returns connection health information
 	Health() HealthStatus
+
+	// Capabilities returns what the feed offers, so callers can skip unsupported features
+	Capabilities() Capabilities
+}
+
+// Resyncer is implemented by adapters whose snapshot arrives on the stream;
+// Resync resubscribes so the next GetSnapshot returns a fresh book
+type Resyncer interface {
+	Resync() error
+}
+
+// ConnectionReporter is implemented by adapters that shard subscriptions across
+// several connections; Health then summarizes them all
+type ConnectionReporter interface {
+	// ConnectionCount returns the number of open connections
+	ConnectionCount() int
+
+	// ConnectionHealth returns health information per connection
+	ConnectionHealth() []HealthStatus
+}
+
+// FundingSource is implemented by perpetual adapters that publish funding and open interest
+type FundingSource interface {
+	// Funding returns a channel that receives funding and open interest in canonical format
+	// It is nil when the market has no funding, and closed with Updates otherwise
+	Funding() <-chan *Funding
 }
 
 // Snapshot represents a canonical orderbook snapshot (normalized across exchanges)
@@ -56,6 +112,8 @@ type Snapshot struct {
 	LastUpdateID int64        // Last update ID from exchange
 	Bids         []PriceLevel // Bid levels [price, quantity]
 	Asks         []PriceLevel // Ask levels [price, quantity]
+	BidOrders    []Order      // Resting bid orders, for order-level (L3) feeds
+	AskOrders    []Order      // Resting ask orders, for order-level (L3) feeds
 	Timestamp    time.Time    // Snapshot timestamp
 }
 
@@ -69,6 +127,49 @@ type DepthUpdate struct {
 	PrevUpdateID  int64        // Previous update ID (for continuity checking)
 	Bids          []PriceLevel // Updated bid levels
 	Asks          []PriceLevel // Updated ask levels
+	Checksum      *uint32      // Venue checksum of the book after this update, if published
+	BidOrders     []Order      // Changed bid orders, for order-level (L3) feeds
+	AskOrders     []Order      // Changed ask orders, for order-level (L3) feeds
+}
+
+// TradeSide is the side of the order that took liquidity in a trade
+type TradeSide string
+
+const (
+	TakerBuy  TradeSide = "buy"  // A buy order lifted an ask
+	TakerSell TradeSide = "sell" // A sell order hit a bid
+)
+
+// Trade represents a canonical public trade (normalized across exchanges)
+type Trade struct {
+	Exchange  ExchangeName // Exchange name
+	Symbol    string       // Trading symbol
+	TradeID   string       // Venue trade ID, empty when the venue has none
+	Price     string       // Price as string to avoid precision loss
+	Quantity  string       // Base size, in the units of book quantities
+	Side      TradeSide    // Side of the taker
+	Timestamp time.Time    // Execution time reported by the venue
+}
+
+// Funding represents the canonical funding and open interest of a perpetual market
+// Adapters merge partial venue updates, so every value carries the latest of each field;
+// fields the venue does not publish are empty
+type Funding struct {
+	Exchange     ExchangeName // Exchange name
+	Symbol       string       // Trading symbol
+	Rate         string       // Funding rate of the current interval, as a fraction (e.g., 0.0001)
+	NextFunding  time.Time    // When the rate is next charged; zero if the venue does not say
+	MarkPrice    string       // Price positions are marked at
+	IndexPrice   string       // Spot index the mark price tracks
+	OpenInterest string       // Open contracts, in the units of book quantities
+	Timestamp    time.Time    // When the venue last updated any field
+}
+
+// Order represents a single resting order on an order-level (L3) feed
+type Order struct {
+	ID       string // Venue order ID
+	Price    string // Limit price
+	Quantity string // Remaining size; "0" removes the order
 }
 
 // PriceLevel represents a single price level [price, quantity]
@@ -84,4 +185,16 @@ type HealthStatus struct {
 	MessageCount  int64
 	ErrorCount    int64
 	ReconnectTime *time.Time
+	Disconnects   int64 // Times an established connection was lost or closed
+}
+
+// Capabilities describes the features of an exchange feed
+type Capabilities struct {
+	MaxDepth       int           // Levels per side the feed delivers; 0 for the full book
+	UpdateInterval time.Duration // Push or poll interval; 0 if changes stream as they happen
+	Checksum       bool          // Whether the feed publishes book checksums
+	Trades         bool          // Whether the venue has a public trades feed
+	Funding        bool          // Whether the venue publishes funding rates
+	L3             bool          // Whether updates carry individual orders rather than levels
+	NativeSymbol   string        // Symbol in the venue's own format (e.g., BTC-USDT-SWAP)
 }
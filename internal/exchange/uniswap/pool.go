@@ -0,0 +1,287 @@
+package uniswap
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+// tickBase is the price ratio between adjacent Uniswap V3 ticks
+const tickBase = 1.0001
+
+// ticksPerBatch bounds the size of a ticks() batch, since public RPC endpoints cap batch sizes
+const ticksPerBatch = 50
+
+// loadPoolInfo reads tick spacing and token details of the pool
+func loadPoolInfo(ctx context.Context, client *rpcClient, pool, base string) (*poolInfo, error) {
+	results, err := client.batchCall(ctx, []call{
+		{to: pool, data: selectorTickSpacing},
+		{to: pool, data: selectorToken0},
+		{to: pool, data: selectorToken1},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spacing, err := decodeInt(results[0], 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tickSpacing: %w", err)
+	}
+	token0, err := decodeAddress(results[1], 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token0: %w", err)
+	}
+	token1, err := decodeAddress(results[2], 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token1: %w", err)
+	}
+
+	results, err = client.batchCall(ctx, []call{
+		{to: token0, data: selectorDecimals},
+		{to: token1, data: selectorDecimals},
+		{to: token0, data: selectorSymbol},
+		{to: token1, data: selectorSymbol},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	decimals0, err := decodeUint(results[0], 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token0 decimals: %w", err)
+	}
+	decimals1, err := decodeUint(results[1], 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token1 decimals: %w", err)
+	}
+
+	info := &poolInfo{
+		tickSpacing: spacing.Int64(),
+		decimals0:   int(decimals0.Int64()),
+		decimals1:   int(decimals1.Int64()),
+		baseIsZero:  true,
+	}
+
+	// Wrapped tokens (WBTC, WETH) stand in for the base asset; token0 is assumed
+	// to be the base when symbols cannot be decoded
+	symbol1, err := decodeString(results[3])
+	if err == nil && matchesBase(symbol1, base) {
+		info.baseIsZero = false
+	}
+
+	return info, nil
+}
+
+// matchesBase reports whether a token symbol represents the base asset
+func matchesBase(tokenSymbol, base string) bool {
+	tokenSymbol = strings.ToUpper(tokenSymbol)
+	return tokenSymbol == base || tokenSymbol == "W"+base
+}
+
+// loadPoolState reads the pool price, active liquidity and liquidityNet of every
+// initialized tick within rangeTicks of the current tick
+func loadPoolState(ctx context.Context, client *rpcClient, pool string, info *poolInfo, rangeTicks int64) (*poolState, error) {
+	results, err := client.batchCall(ctx, []call{
+		{to: pool, data: selectorSlot0},
+		{to: pool, data: selectorLiquidity},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqrtPriceX96, err := decodeUint(results[0], 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slot0: %w", err)
+	}
+	tick, err := decodeInt(results[0], 1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slot0 tick: %w", err)
+	}
+	liquidity, err := decodeUint(results[1], 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid liquidity: %w", err)
+	}
+
+	state := &poolState{
+		sqrtPrice: x96ToFloat(sqrtPriceX96),
+		tick:      tick.Int64(),
+		liquidity: bigToFloat(liquidity),
+		netByTick: make(map[int64]float64),
+	}
+
+	spacing := info.tickSpacing
+	lowCompressed := floorDiv(state.tick-rangeTicks, spacing)
+	highCompressed := floorDiv(state.tick+rangeTicks, spacing)
+	lowWord := floorDiv(lowCompressed, 256)
+	highWord := floorDiv(highCompressed, 256)
+
+	bitmapCalls := make([]call, 0, highWord-lowWord+1)
+	for w := lowWord; w <= highWord; w++ {
+		bitmapCalls = append(bitmapCalls, call{to: pool, data: selectorTickBitmap + encodeInt(w)})
+	}
+
+	bitmaps, err := client.batchCall(ctx, bitmapCalls)
+	if err != nil {
+		return nil, err
+	}
+
+	initialized := make([]int64, 0)
+	for i, data := range bitmaps {
+		bitmap, err := decodeUint(data, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tickBitmap: %w", err)
+		}
+		wordPos := lowWord + int64(i)
+		for bit := 0; bit < 256; bit++ {
+			if bitmap.Bit(bit) == 0 {
+				continue
+			}
+			compressed := wordPos*256 + int64(bit)
+			if compressed >= lowCompressed && compressed <= highCompressed {
+				initialized = append(initialized, compressed*spacing)
+			}
+		}
+	}
+
+	for start := 0; start < len(initialized); start += ticksPerBatch {
+		end := start + ticksPerBatch
+		if end > len(initialized) {
+			end = len(initialized)
+		}
+
+		tickCalls := make([]call, 0, end-start)
+		for _, t := range initialized[start:end] {
+			tickCalls = append(tickCalls, call{to: pool, data: selectorTicks + encodeInt(t)})
+		}
+
+		tickResults, err := client.batchCall(ctx, tickCalls)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, data := range tickResults {
+			net, err := decodeInt(data, 1)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ticks result: %w", err)
+			}
+			state.netByTick[initialized[start+i]] = bigToFloat(net)
+		}
+	}
+
+	return state, nil
+}
+
+// buildLevels converts pool liquidity into price levels, one per tick-spacing segment
+// The quantity of a level is the base amount swapped while the price moves across
+// its segment; levels are priced at the segment boundary furthest from the pool price
+func buildLevels(info *poolInfo, state *poolState, rangeTicks int64) ([]exchange.PriceLevel, []exchange.PriceLevel) {
+	spacing := info.tickSpacing
+	scale0 := math.Pow10(info.decimals0)
+	scale1 := math.Pow10(info.decimals1)
+	compressed := floorDiv(state.tick, spacing)
+
+	var up, down []segment
+
+	// Walk up: liquidity is added when crossing an initialized tick left to right
+	liquidity := state.liquidity
+	sqrtCurrent := state.sqrtPrice
+	for t := (compressed + 1) * spacing; t <= state.tick+rangeTicks; t += spacing {
+		sqrtNext := sqrtAtTick(t)
+		up = append(up, segment{
+			tick:    t,
+			amount0: liquidity * (sqrtNext - sqrtCurrent) / (sqrtCurrent * sqrtNext) / scale0,
+			amount1: liquidity * (sqrtNext - sqrtCurrent) / scale1,
+		})
+		liquidity += state.netByTick[t]
+		sqrtCurrent = sqrtNext
+	}
+
+	// Walk down: liquidity is removed when crossing an initialized tick right to left
+	liquidity = state.liquidity
+	sqrtCurrent = state.sqrtPrice
+	for t := compressed * spacing; t >= state.tick-rangeTicks; t -= spacing {
+		sqrtLower := sqrtAtTick(t)
+		down = append(down, segment{
+			tick:    t,
+			amount0: liquidity * (sqrtCurrent - sqrtLower) / (sqrtCurrent * sqrtLower) / scale0,
+			amount1: liquidity * (sqrtCurrent - sqrtLower) / scale1,
+		})
+		liquidity -= state.netByTick[t]
+		sqrtCurrent = sqrtLower
+	}
+
+	decimalsShift := math.Pow10(info.decimals0 - info.decimals1)
+	price := func(t int64) float64 {
+		return math.Pow(tickBase, float64(t)) * decimalsShift
+	}
+
+	// With token0 as base, token1-per-token0 price rises with the tick: liquidity
+	// above the price sells base (asks) and below it buys base (bids). With token1
+	// as base the price is inverted and the sides swap
+	if info.baseIsZero {
+		return toLevels(down, func(s segment) (float64, float64) { return price(s.tick), s.amount0 }),
+			toLevels(up, func(s segment) (float64, float64) { return price(s.tick), s.amount0 })
+	}
+	return toLevels(up, func(s segment) (float64, float64) { return 1 / price(s.tick), s.amount1 }),
+		toLevels(down, func(s segment) (float64, float64) { return 1 / price(s.tick), s.amount1 })
+}
+
+// segment is the liquidity between two adjacent tick-spacing boundaries
+type segment struct {
+	tick    int64 // Boundary furthest from the pool price
+	amount0 float64
+	amount1 float64
+}
+
+// toLevels formats segments as price levels, skipping empty ones
+func toLevels(segments []segment, priceAndQty func(segment) (float64, float64)) []exchange.PriceLevel {
+	levels := make([]exchange.PriceLevel, 0, len(segments))
+	for _, s := range segments {
+		p, qty := priceAndQty(s)
+		if qty <= 0 || math.IsInf(p, 0) || math.IsNaN(p) {
+			continue
+		}
+		levels = append(levels, exchange.PriceLevel{
+			Price:    fmt.Sprintf("%.10f", p),
+			Quantity: fmt.Sprintf("%.10f", qty),
+		})
+	}
+	return levels
+}
+
+// rangeTicksFor returns the number of ticks covering pct price distance, rounded up
+func rangeTicksFor(pct float64) int64 {
+	return int64(math.Ceil(math.Log(1+pct) / math.Log(tickBase)))
+}
+
+// sqrtAtTick returns sqrt(1.0001^tick)
+func sqrtAtTick(tick int64) float64 {
+	return math.Pow(tickBase, float64(tick)/2)
+}
+
+// floorDiv divides rounding toward negative infinity, as Uniswap's tick compression does
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// x96ToFloat converts a Q64.96 fixed-point value to float64
+func x96ToFloat(value *big.Int) float64 {
+	f := new(big.Float).SetInt(value)
+	f.Quo(f, new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 96)))
+	result, _ := f.Float64()
+	return result
+}
+
+// bigToFloat converts a big integer to float64
+func bigToFloat(value *big.Int) float64 {
+	result, _ := new(big.Float).SetInt(value).Float64()
+	return result
+}
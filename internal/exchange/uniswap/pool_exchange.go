@@ -0,0 +1,391 @@
+package uniswap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+const (
+	defaultRPCURL          = "https://ethereum-rpc.publicnode.com"
+	defaultRefreshInterval = 12 * time.Second // One Ethereum block
+	defaultRangePct        = 0.10
+)
+
+// defaultPools maps base assets to deep Ethereum mainnet Uniswap V3 pools
+var defaultPools = map[string]string{
+	"BTC": "0x99ac8cA7087fA4A2A1FB6357269965A2014ABc35", // WBTC/USDC 0.3%
+	"ETH": "0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640", // USDC/WETH 0.05%
+}
+
+// PoolExchange implements the Exchange interface for a Uniswap V3 pool
+// There is no stream to subscribe to, so tick liquidity is re-read over RPC on an
+// interval, converted into a synthetic book and diffed against the previous read
+type PoolExchange struct {
+	symbol          string
+	base            string
+	pool            string
+	refreshInterval time.Duration
+	rangeTicks      int64
+	client          *rpcClient
+	info            *poolInfo
+	updateChan      chan *exchange.DepthUpdate
+	done            chan struct{}
+	ctx             context.Context
+	cancel          context.CancelFunc
+	health          atomic.Value // stores exchange.HealthStatus
+	isRunning       bool
+	bookMu          sync.Mutex
+	sequence        int64
+	prevBids        map[string]string
+	prevAsks        map[string]string
+}
+
+// NewPoolExchange creates a new Uniswap V3 pool exchange instance
+func NewPoolExchange(config Config) *PoolExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	base := baseAsset(config.Symbol)
+
+	rpcURL := firstNonEmpty(config.RPCURL, os.Getenv("UNISWAP_RPC_URL"), defaultRPCURL)
+	pool := firstNonEmpty(config.Pool, os.Getenv("UNISWAP_POOL"), defaultPools[base])
+
+	refreshInterval := config.RefreshInterval
+	if refreshInterval <= 0 {
+		if d, err := time.ParseDuration(os.Getenv("UNISWAP_REFRESH_INTERVAL")); err == nil && d > 0 {
+			refreshInterval = d
+		} else {
+			refreshInterval = defaultRefreshInterval
+		}
+	}
+
+	rangePct := config.RangePct
+	if rangePct <= 0 {
+		rangePct = defaultRangePct
+	}
+
+	ex := &PoolExchange{
+		symbol:          config.Symbol,
+		base:            base,
+		pool:            pool,
+		refreshInterval: refreshInterval,
+		rangeTicks:      rangeTicksFor(rangePct),
+		client: &rpcClient{
+			url:        rpcURL,
+			httpClient: &http.Client{Timeout: 10 * time.Second},
+		},
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+		prevBids:   make(map[string]string),
+		prevAsks:   make(map[string]string),
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *PoolExchange) GetName() exchange.ExchangeName {
+	return exchange.UniswapV3
+}
+
+// GetSymbol returns the trading symbol
+func (e *PoolExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *PoolExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect loads the pool properties and starts the refresh loop
+func (e *PoolExchange) Connect(ctx context.Context) error {
+	if e.pool == "" {
+		return fmt.Errorf("no Uniswap V3 pool configured for %s (set UNISWAP_POOL)", e.symbol)
+	}
+
+	info, err := loadPoolInfo(ctx, e.client, e.pool, e.base)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("failed to load pool %s: %w", e.pool, err)
+	}
+	e.info = info
+
+	e.updateConnectionStatus(true)
+	e.log().Info("Pool loaded", "event", "pool_loaded", "pool", e.pool, "tick_spacing", info.tickSpacing, "refresh_interval", e.refreshInterval)
+
+	e.isRunning = true
+	go e.refreshLoop()
+
+	return nil
+}
+
+// Close stops the refresh loop
+func (e *PoolExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	select {
+	case <-e.done:
+	default:
+		close(e.done)
+	}
+
+	e.updateConnectionStatus(false)
+	e.log().Info("Refresh stopped", "event", "refresh_stopped")
+	return nil
+}
+
+// GetSnapshot reads the pool and returns the synthetic book, resetting the diff baseline
+func (e *PoolExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	bids, asks, err := e.readBook(ctx)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to read pool: %w", err)
+	}
+
+	e.bookMu.Lock()
+	defer e.bookMu.Unlock()
+
+	e.sequence++
+	e.prevBids = levelMap(bids)
+	e.prevAsks = levelMap(asks)
+
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.symbol,
+		LastUpdateID: e.sequence,
+		Bids:         bids,
+		Asks:         asks,
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (e *PoolExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns nil; swaps are not reported as trades
+func (e *PoolExchange) Trades() <-chan *exchange.Trade {
+	return nil
+}
+
+// IsConnected checks if the refresh loop is active
+func (e *PoolExchange) IsConnected() bool {
+	return e.isRunning
+}
+
+// Health returns connection health information
+func (e *PoolExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the synthetic pool book
+// Levels only cover the configured range around the pool price
+func (e *PoolExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		UpdateInterval: e.refreshInterval,
+		NativeSymbol:   e.pool,
+	}
+}
+
+// refreshLoop re-reads the pool every refresh interval
+func (e *PoolExchange) refreshLoop() {
+	defer close(e.updateChan)
+	defer e.updateConnectionStatus(false)
+
+	ticker := time.NewTicker(e.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped refreshing", "event", "refresh_stopped")
+			return
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.refresh()
+		}
+	}
+}
+
+// refresh reads the pool and emits the levels that changed since the last read
+func (e *PoolExchange) refresh() {
+	ctx, cancel := context.WithTimeout(e.ctx, e.refreshInterval)
+	defer cancel()
+
+	bids, asks, err := e.readBook(ctx)
+	if err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to refresh", "event", "refresh_failed", "error", err)
+		return
+	}
+
+	e.incrementMessageCount()
+	e.updateLastPing()
+
+	e.bookMu.Lock()
+	defer e.bookMu.Unlock()
+
+	bidChanges := diffLevels(e.prevBids, bids)
+	askChanges := diffLevels(e.prevAsks, asks)
+	if len(bidChanges) == 0 && len(askChanges) == 0 {
+		return
+	}
+
+	e.sequence++
+	update := &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        e.symbol,
+		EventTime:     time.Now(),
+		FirstUpdateID: e.sequence,
+		FinalUpdateID: e.sequence,
+		PrevUpdateID:  e.sequence - 1,
+		Bids:          bidChanges,
+		Asks:          askChanges,
+	}
+
+	select {
+	case e.updateChan <- update:
+	case <-e.ctx.Done():
+	case <-e.done:
+	default:
+		e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", update.FinalUpdateID)
+	}
+}
+
+// readBook reads pool state and converts it into bid and ask levels
+func (e *PoolExchange) readBook(ctx context.Context) ([]exchange.PriceLevel, []exchange.PriceLevel, error) {
+	if e.info == nil {
+		return nil, nil, fmt.Errorf("pool not loaded")
+	}
+
+	state, err := loadPoolState(ctx, e.client, e.pool, e.info, e.rangeTicks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bids, asks := buildLevels(e.info, state, e.rangeTicks)
+	return bids, asks, nil
+}
+
+// diffLevels returns the levels that changed since prev, with removed levels at
+// zero quantity, and replaces prev with the current levels
+func diffLevels(prev map[string]string, current []exchange.PriceLevel) []exchange.PriceLevel {
+	changes := make([]exchange.PriceLevel, 0)
+	seen := make(map[string]struct{}, len(current))
+
+	for _, level := range current {
+		seen[level.Price] = struct{}{}
+		if prev[level.Price] != level.Quantity {
+			changes = append(changes, level)
+			prev[level.Price] = level.Quantity
+		}
+	}
+
+	for price := range prev {
+		if _, ok := seen[price]; !ok {
+			changes = append(changes, exchange.PriceLevel{Price: price, Quantity: "0"})
+			delete(prev, price)
+		}
+	}
+
+	return changes
+}
+
+// levelMap indexes levels by price
+func levelMap(levels []exchange.PriceLevel) map[string]string {
+	m := make(map[string]string, len(levels))
+	for _, level := range levels {
+		m[level.Price] = level.Quantity
+	}
+	return m
+}
+
+// baseAsset extracts the base asset of a symbol
+// Examples: BTCUSDT -> BTC, ETH-USDC -> ETH, WBTCUSDC -> BTC
+func baseAsset(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	symbol = strings.NewReplacer("-", "", "_", "", "/", "").Replace(symbol)
+
+	for _, quote := range []string{"USDT", "USDC", "USD", "DAI"} {
+		if strings.HasSuffix(symbol, quote) {
+			symbol = strings.TrimSuffix(symbol, quote)
+			break
+		}
+	}
+
+	if symbol == "WBTC" || symbol == "WETH" {
+		return strings.TrimPrefix(symbol, "W")
+	}
+	return symbol
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *PoolExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *PoolExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *PoolExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *PoolExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
@@ -0,0 +1,174 @@
+package uniswap
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// Function selectors of the Uniswap V3 pool and ERC-20 calls used
+const (
+	selectorSlot0       = "3850c7bd" // slot0()
+	selectorLiquidity   = "1a686502" // liquidity()
+	selectorTickSpacing = "d0c93a7c" // tickSpacing()
+	selectorToken0      = "0dfe1681" // token0()
+	selectorToken1      = "d21220a7" // token1()
+	selectorTickBitmap  = "5339c296" // tickBitmap(int16)
+	selectorTicks       = "f30dba93" // ticks(int24)
+	selectorDecimals    = "313ce567" // decimals()
+	selectorSymbol      = "95d89b41" // symbol()
+)
+
+// rpcClient performs batched eth_call requests against a JSON-RPC endpoint
+type rpcClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// call describes a single eth_call
+type call struct {
+	to   string
+	data string
+}
+
+// batchCall executes calls in one JSON-RPC batch and returns the raw results in order
+func (c *rpcClient) batchCall(ctx context.Context, calls []call) ([][]byte, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	requests := make([]rpcRequest, len(calls))
+	for i, cl := range calls {
+		requests[i] = rpcRequest{
+			JSONRPC: "2.0",
+			ID:      i,
+			Method:  "eth_call",
+			Params:  []interface{}{callArgs{To: cl.to, Data: "0x" + cl.data}, "latest"},
+		}
+	}
+
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rpc request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rpc request failed with status %d", resp.StatusCode)
+	}
+
+	var responses []rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		return nil, fmt.Errorf("failed to decode rpc response: %w", err)
+	}
+
+	results := make([][]byte, len(calls))
+	for _, r := range responses {
+		if r.ID < 0 || r.ID >= len(calls) {
+			continue
+		}
+		if r.Error != nil {
+			return nil, fmt.Errorf("eth_call %d failed: code=%d, msg=%s", r.ID, r.Error.Code, r.Error.Message)
+		}
+
+		var hexResult string
+		if err := json.Unmarshal(r.Result, &hexResult); err != nil {
+			return nil, fmt.Errorf("invalid eth_call result: %w", err)
+		}
+
+		data, err := hex.DecodeString(strings.TrimPrefix(hexResult, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid eth_call hex: %w", err)
+		}
+		results[r.ID] = data
+	}
+
+	for i, r := range results {
+		if r == nil {
+			return nil, fmt.Errorf("missing result for eth_call %d", i)
+		}
+	}
+
+	return results, nil
+}
+
+// encodeInt encodes a signed integer argument as a 32-byte two's complement word
+func encodeInt(value int64) string {
+	n := big.NewInt(value)
+	if value < 0 {
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return fmt.Sprintf("%064x", n)
+}
+
+// word returns the i-th 32-byte word of an ABI-encoded result
+func word(data []byte, i int) ([]byte, error) {
+	start := i * 32
+	if len(data) < start+32 {
+		return nil, fmt.Errorf("result too short: %d bytes, need word %d", len(data), i)
+	}
+	return data[start : start+32], nil
+}
+
+// decodeUint decodes the i-th word as an unsigned integer
+func decodeUint(data []byte, i int) (*big.Int, error) {
+	w, err := word(data, i)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(w), nil
+}
+
+// decodeInt decodes the i-th word as a sign-extended two's complement integer
+func decodeInt(data []byte, i int) (*big.Int, error) {
+	n, err := decodeUint(data, i)
+	if err != nil {
+		return nil, err
+	}
+	if n.Bit(255) == 1 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return n, nil
+}
+
+// decodeAddress decodes the i-th word as an address
+func decodeAddress(data []byte, i int) (string, error) {
+	w, err := word(data, i)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(w[12:]), nil
+}
+
+// decodeString decodes a dynamic ABI string result
+func decodeString(data []byte) (string, error) {
+	offset, err := decodeUint(data, 0)
+	if err != nil {
+		return "", err
+	}
+	start := int(offset.Int64())
+	if len(data) < start+32 {
+		return "", fmt.Errorf("string offset out of range")
+	}
+	length := int(new(big.Int).SetBytes(data[start : start+32]).Int64())
+	if len(data) < start+32+length {
+		return "", fmt.Errorf("string length out of range")
+	}
+	return string(data[start+32 : start+32+length]), nil
+}
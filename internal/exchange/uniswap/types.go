@@ -0,0 +1,60 @@
+package uniswap
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Config holds configuration for a Uniswap V3 pool
+// Empty fields fall back to UNISWAP_RPC_URL, UNISWAP_POOL and UNISWAP_REFRESH_INTERVAL,
+// then to built-in defaults
+type Config struct {
+	Symbol          string
+	RPCURL          string        // Ethereum JSON-RPC endpoint
+	Pool            string        // Pool contract address
+	RefreshInterval time.Duration // How often tick liquidity is re-read
+	RangePct        float64       // Distance from the pool price to build levels for (0.10 = 10%)
+}
+
+// rpcRequest represents a JSON-RPC request
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcResponse represents a JSON-RPC response
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// rpcError represents a JSON-RPC error
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// callArgs represents the eth_call transaction object
+type callArgs struct {
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+// poolInfo holds the static properties of a pool
+type poolInfo struct {
+	tickSpacing int64
+	decimals0   int
+	decimals1   int
+	baseIsZero  bool // Whether token0 is the base asset of the configured symbol
+}
+
+// poolState holds the pool price and the liquidity around it
+type poolState struct {
+	sqrtPrice float64           // Current sqrt(price) in raw token units
+	tick      int64             // Current tick
+	liquidity float64           // Active liquidity
+	netByTick map[int64]float64 // liquidityNet of initialized ticks in range
+}
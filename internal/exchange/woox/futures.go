@@ -0,0 +1,404 @@
+package woox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/gorilla/websocket"
+)
+
+// Public market data streams are keyed by application ID in the URL path; any
+// registered ID works for public topics, so the one from WOO X's docs is used
+const (
+	wsURL   = "wss://wss.woox.io/ws/stream/OqdphuyCtYWxwzhxyLLjOWNdFP7sQt8RPWzmb5xY"
+	restURL = "https://api.woox.io/v1/public/orderbook/%s?max_level=1000"
+)
+
+// FuturesExchange implements the Exchange interface for WOO X perpetuals
+// Updates are sequenced by millisecond timestamps: ts identifies an update and
+// prevTs the one before it, and the REST snapshot timestamp anchors the chain
+type FuturesExchange struct {
+	symbol     string
+	wooSymbol  string // WOO X format (e.g., PERP_BTC_USDT)
+	restURL    string
+	wsConn     *websocket.Conn
+	writeMu    sync.Mutex
+	updateChan chan *exchange.DepthUpdate
+	tradeChan  chan *exchange.Trade
+	done       chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	health     atomic.Value // stores exchange.HealthStatus
+}
+
+// NewFuturesExchange creates a new WOO X perpetual exchange instance
+func NewFuturesExchange(config Config) *FuturesExchange {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	wooSymbol := config.Symbol
+
+	ex := &FuturesExchange{
+		symbol:     config.Symbol,
+		wooSymbol:  wooSymbol,
+		restURL:    fmt.Sprintf(restURL, wooSymbol),
+		updateChan: make(chan *exchange.DepthUpdate, 1000),
+		tradeChan:  make(chan *exchange.Trade, 1000),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	ex.health.Store(exchange.HealthStatus{
+		Connected:    false,
+		LastPing:     time.Time{},
+		MessageCount: 0,
+		ErrorCount:   0,
+	})
+
+	return ex
+}
+
+// GetName returns the exchange name
+func (e *FuturesExchange) GetName() exchange.ExchangeName {
+	return exchange.WooXf
+}
+
+// GetSymbol returns the trading symbol
+func (e *FuturesExchange) GetSymbol() string {
+	return e.symbol
+}
+
+// log returns the logger of feed events, carrying the exchange and symbol
+func (e *FuturesExchange) log() *slog.Logger {
+	return logging.For(string(e.GetName()), e.GetSymbol())
+}
+
+// Connect establishes WebSocket connection to WOO X
+func (e *FuturesExchange) Connect(ctx context.Context) error {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		e.incrementErrorCount()
+		return fmt.Errorf("websocket connection failed: %w", err)
+	}
+
+	e.wsConn = conn
+	e.updateConnectionStatus(true)
+	e.log().Info("WebSocket connected", "event", "ws_connected")
+
+	topic := fmt.Sprintf("%s@orderbookupdate", e.wooSymbol)
+	if err := e.send(WSRequest{ID: "orderbook", Event: "subscribe", Topic: topic}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	tradeTopic := fmt.Sprintf("%s@trade", e.wooSymbol)
+	if err := e.send(WSRequest{ID: "trade", Event: "subscribe", Topic: tradeTopic}); err != nil {
+		e.incrementErrorCount()
+		conn.Close()
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	e.log().Info("Subscribed", "event", "subscribed", "channel", topic, "trade_channel", tradeTopic)
+
+	go e.readMessages()
+
+	return nil
+}
+
+// Close closes the WebSocket connection
+func (e *FuturesExchange) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	if e.wsConn != nil {
+		select {
+		case <-e.done:
+		default:
+			close(e.done)
+		}
+
+		e.writeMu.Lock()
+		err := e.wsConn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		e.writeMu.Unlock()
+		if err != nil {
+			e.log().Warn("Failed to send close message", "event", "close_failed", "error", err)
+		}
+
+		select {
+		case <-time.After(time.Second):
+		}
+
+		e.updateConnectionStatus(false)
+		return e.wsConn.Close()
+	}
+	return nil
+}
+
+// GetSnapshot fetches the initial orderbook snapshot via REST API
+func (e *FuturesExchange) GetSnapshot(ctx context.Context) (*exchange.Snapshot, error) {
+	e.log().Info("Fetching orderbook snapshot", "event", "snapshot_fetch")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.restURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var wooSnapshot SnapshotResponse
+	if err := json.NewDecoder(resp.Body).Decode(&wooSnapshot); err != nil {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	if !wooSnapshot.Success {
+		e.incrementErrorCount()
+		return nil, fmt.Errorf("snapshot request for %s was not successful", e.wooSymbol)
+	}
+
+	bids := make([]exchange.PriceLevel, len(wooSnapshot.Bids))
+	for i, bid := range wooSnapshot.Bids {
+		bids[i] = formatLevel(bid.Price, bid.Quantity)
+	}
+
+	asks := make([]exchange.PriceLevel, len(wooSnapshot.Asks))
+	for i, ask := range wooSnapshot.Asks {
+		asks[i] = formatLevel(ask.Price, ask.Quantity)
+	}
+
+	return &exchange.Snapshot{
+		Exchange:     e.GetName(),
+		Symbol:       e.wooSymbol,
+		LastUpdateID: wooSnapshot.Timestamp,
+		Bids:         bids,
+		Asks:         asks,
+		Timestamp:    time.UnixMilli(wooSnapshot.Timestamp),
+	}, nil
+}
+
+// Updates returns a channel that receives depth updates
+func (e *FuturesExchange) Updates() <-chan *exchange.DepthUpdate {
+	return e.updateChan
+}
+
+// Trades returns a channel that receives public trades
+func (e *FuturesExchange) Trades() <-chan *exchange.Trade {
+	return e.tradeChan
+}
+
+// IsConnected checks if the WebSocket connection is active
+func (e *FuturesExchange) IsConnected() bool {
+	return e.wsConn != nil
+}
+
+// Health returns connection health information
+func (e *FuturesExchange) Health() exchange.HealthStatus {
+	if status, ok := e.health.Load().(exchange.HealthStatus); ok {
+		return status
+	}
+	return exchange.HealthStatus{}
+}
+
+// Capabilities returns the features of the WOO X perpetual feed
+func (e *FuturesExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		MaxDepth:       1000,
+		UpdateInterval: 200 * time.Millisecond,
+		Trades:         true,
+		NativeSymbol:   e.wooSymbol,
+	}
+}
+
+// send writes a request to the WebSocket
+func (e *FuturesExchange) send(req WSRequest) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.wsConn.WriteJSON(req)
+}
+
+// readMessages continuously reads WebSocket messages
+func (e *FuturesExchange) readMessages() {
+	defer close(e.updateChan)
+	defer close(e.tradeChan)
+	defer e.updateConnectionStatus(false)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			e.log().Debug("Stopped reading messages", "event", "read_stopped")
+			return
+		case <-e.done:
+			return
+		default:
+			var msg WSMessage
+			if err := exchange.ReadJSON(e.wsConn, e.GetName(), &msg); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("WebSocket read error", "event", "read_failed", "error", err)
+				return
+			}
+
+			e.updateLastPing()
+
+			// WOO X closes connections that leave server pings unanswered
+			if msg.Event == "ping" {
+				if err := e.send(WSRequest{Event: "pong", Ts: time.Now().UnixMilli()}); err != nil {
+					e.log().Error("Failed to send pong", "event", "heartbeat_failed", "error", err)
+				}
+				continue
+			}
+
+			if msg.Event == "subscribe" {
+				if msg.Success != nil && !*msg.Success {
+					e.incrementErrorCount()
+					e.log().Error("Subscription failed", "event", "subscribe_failed", "error", msg.ErrorMsg)
+				}
+				continue
+			}
+
+			if len(msg.Data) == 0 {
+				continue
+			}
+
+			if strings.HasSuffix(msg.Topic, "@trade") {
+				e.incrementMessageCount()
+				e.handleTrade(msg.Ts, msg.Data)
+				continue
+			}
+
+			if !strings.HasSuffix(msg.Topic, "@orderbookupdate") {
+				continue
+			}
+
+			e.incrementMessageCount()
+
+			var update BookUpdate
+			if err := json.Unmarshal(msg.Data, &update); err != nil {
+				e.incrementErrorCount()
+				e.log().Error("Failed to parse update", "event", "parse_failed", "error", err)
+				continue
+			}
+
+			canonicalUpdate := e.convertDepthUpdate(msg.Ts, &update)
+
+			select {
+			case e.updateChan <- canonicalUpdate:
+			case <-e.ctx.Done():
+				return
+			case <-e.done:
+				return
+			default:
+				e.log().Warn("Update channel full, skipping update", "event", "update_dropped", "final_update_id", canonicalUpdate.FinalUpdateID)
+			}
+		}
+	}
+}
+
+// handleTrade forwards a trade topic message
+func (e *FuturesExchange) handleTrade(ts int64, raw json.RawMessage) {
+	var trade TradeData
+	if err := json.Unmarshal(raw, &trade); err != nil {
+		e.incrementErrorCount()
+		e.log().Error("Failed to parse trade", "event", "parse_failed", "error", err)
+		return
+	}
+
+	level := formatLevel(trade.Price, trade.Size)
+	exchange.SendTrade(e.GetName(), e.tradeChan, &exchange.Trade{
+		Exchange:  e.GetName(),
+		Symbol:    trade.Symbol,
+		Price:     level.Price,
+		Quantity:  level.Quantity,
+		Side:      exchange.TradeSide(strings.ToLower(trade.Side)),
+		Timestamp: time.UnixMilli(ts),
+	})
+}
+
+// convertDepthUpdate converts WOO X orderbook update to canonical format
+func (e *FuturesExchange) convertDepthUpdate(ts int64, update *BookUpdate) *exchange.DepthUpdate {
+	bids := make([]exchange.PriceLevel, len(update.Bids))
+	for i, bid := range update.Bids {
+		bids[i] = formatLevel(bid[0], bid[1])
+	}
+
+	asks := make([]exchange.PriceLevel, len(update.Asks))
+	for i, ask := range update.Asks {
+		asks[i] = formatLevel(ask[0], ask[1])
+	}
+
+	return &exchange.DepthUpdate{
+		Exchange:      e.GetName(),
+		Symbol:        update.Symbol,
+		EventTime:     time.UnixMilli(ts),
+		FirstUpdateID: update.PrevTs + 1,
+		FinalUpdateID: ts,
+		PrevUpdateID:  update.PrevTs,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+// formatLevel formats float price levels so REST and WebSocket keys match
+func formatLevel(price, quantity float64) exchange.PriceLevel {
+	return exchange.PriceLevel{
+		Price:    fmt.Sprintf("%.10f", price),
+		Quantity: fmt.Sprintf("%.10f", quantity),
+	}
+}
+
+// updateConnectionStatus updates the connection status in health
+func (e *FuturesExchange) updateConnectionStatus(connected bool) {
+	status := e.Health()
+	if status.Connected && !connected {
+		status.Disconnects++
+	}
+	status.Connected = connected
+	if !connected {
+		now := time.Now()
+		status.ReconnectTime = &now
+	}
+	e.health.Store(status)
+}
+
+// incrementMessageCount increments the message count in health
+func (e *FuturesExchange) incrementMessageCount() {
+	status := e.Health()
+	status.MessageCount++
+	e.health.Store(status)
+}
+
+// incrementErrorCount increments the error count in health
+func (e *FuturesExchange) incrementErrorCount() {
+	status := e.Health()
+	status.ErrorCount++
+	e.health.Store(status)
+}
+
+// updateLastPing updates the last ping time in health
+func (e *FuturesExchange) updateLastPing() {
+	status := e.Health()
+	status.LastPing = time.Now()
+	e.health.Store(status)
+}
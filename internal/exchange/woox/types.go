@@ -0,0 +1,58 @@
+package woox
+
+import "encoding/json"
+
+// Config holds configuration for WOO X exchange
+type Config struct {
+	Symbol string // Native symbol (e.g. PERP_BTC_USDT), as resolved by symbols.Native
+}
+
+// WSRequest represents a WOO X WebSocket request
+type WSRequest struct {
+	ID    string `json:"id,omitempty"`
+	Event string `json:"event"` // "subscribe" or "pong"
+	Topic string `json:"topic,omitempty"`
+	Ts    int64  `json:"ts,omitempty"`
+}
+
+// WSMessage represents a WebSocket message from WOO X; Data is decoded by topic
+type WSMessage struct {
+	ID       string          `json:"id"`
+	Event    string          `json:"event"` // "ping", "subscribe"
+	Success  *bool           `json:"success"`
+	ErrorMsg string          `json:"errorMsg"`
+	Topic    string          `json:"topic"` // e.g., PERP_BTC_USDT@orderbookupdate
+	Ts       int64           `json:"ts"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// BookUpdate represents an orderbookupdate payload
+// Each update links to the previous one through prevTs
+type BookUpdate struct {
+	Symbol string       `json:"symbol"`
+	PrevTs int64        `json:"prevTs"`
+	Bids   [][2]float64 `json:"bids"` // [price, quantity]
+	Asks   [][2]float64 `json:"asks"` // [price, quantity]
+}
+
+// TradeData represents a trade topic payload
+type TradeData struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+	Size   float64 `json:"size"`
+	Side   string  `json:"side"` // Taker side: "BUY" or "SELL"
+}
+
+// SnapshotResponse represents the REST orderbook response
+type SnapshotResponse struct {
+	Success   bool            `json:"success"`
+	Bids      []SnapshotLevel `json:"bids"`
+	Asks      []SnapshotLevel `json:"asks"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// SnapshotLevel represents a single REST orderbook level
+type SnapshotLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
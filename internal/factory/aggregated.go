@@ -0,0 +1,54 @@
+package factory
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+
+	"github.com/shopspring/decimal"
+)
+
+// newAggregated builds a consolidated book over the configured venues
+// Empty Venues and TickSize fall back to AGGREGATE_EXCHANGES (comma separated) and
+// AGGREGATE_TICK_SIZE, then to every monitored exchange at exact prices
+func newAggregated(config ExchangeConfig) (exchange.Exchange, error) {
+	names := config.Venues
+	if len(names) == 0 {
+		for _, name := range strings.Split(os.Getenv("AGGREGATE_EXCHANGES"), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, exchange.ExchangeName(name))
+			}
+		}
+	}
+	if len(names) == 0 {
+		names = ListMonitored()
+	}
+
+	tickSize := config.TickSize
+	if tickSize <= 0 {
+		if t, err := strconv.ParseFloat(os.Getenv("AGGREGATE_TICK_SIZE"), 64); err == nil && t > 0 {
+			tickSize = t
+		}
+	}
+
+	venues := make([]exchange.Exchange, 0, len(names))
+	for _, name := range names {
+		if name == exchange.Aggregated {
+			return nil, fmt.Errorf("aggregated exchange cannot include itself")
+		}
+
+		venue, err := NewExchange(ExchangeConfig{
+			Name:   name,
+			Symbol: config.Symbol,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create venue %s: %w", name, err)
+		}
+		venues = append(venues, venue)
+	}
+
+	return exchange.NewAggregate(config.Symbol, venues, decimal.NewFromFloat(tickSize)), nil
+}
@@ -0,0 +1,301 @@
+package factory
+
+import (
+	"fmt"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/aevo"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/asterdex"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/backpack"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/binance"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/bingx"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/bitget"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/bitmart"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/bitstamp"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/bybit"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/coinbase"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/coinbaseintx"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/cryptocom"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/deribit"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/dydx"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/gate"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/gemini"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/htx"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/hyperliquid"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/kraken"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/lighter"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/okx"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/paradex"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/phemex"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/poloniex"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/replay"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/uniswap"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/woox"
+	"github.com/imajinl/crypto-orderbook/internal/symbols"
+)
+
+// init registers the adapters that ship with this module
+// Optional adapters are available by name but are not monitored unless requested
+func init() {
+	Register(exchange.Binancef, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return binance.NewFuturesExchange(binance.Config{
+			Symbol:      config.Symbol,
+			Depth:       config.Depth,
+			UpdateSpeed: config.UpdateSpeed,
+		}), nil
+	})
+
+	Register(exchange.Binance, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return binance.NewSpotExchange(binance.Config{
+			Symbol:      config.Symbol,
+			Depth:       config.Depth,
+			UpdateSpeed: config.UpdateSpeed,
+		}), nil
+	})
+
+	Register(exchange.Bybitf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return bybit.NewFuturesExchange(bybit.Config{
+			Symbol: config.Symbol,
+			Depth:  config.Depth,
+		}), nil
+	})
+
+	Register(exchange.Bybit, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return bybit.NewSpotExchange(bybit.Config{
+			Symbol: config.Symbol,
+			Depth:  config.Depth,
+		}), nil
+	})
+
+	Register(exchange.Kraken, func(config ExchangeConfig) (exchange.Exchange, error) {
+		krakenConfig := kraken.Config{
+			Symbol:    config.Symbol,
+			APIKey:    config.APIKey,
+			APISecret: config.APISecret,
+		}
+		if config.L3 {
+			if config.APIKey == "" || config.APISecret == "" {
+				return nil, fmt.Errorf("kraken level3 feed needs KRAKEN_API_KEY and KRAKEN_API_SECRET")
+			}
+			return kraken.NewLevel3Exchange(krakenConfig), nil
+		}
+		return kraken.NewSpotExchange(krakenConfig), nil
+	})
+
+	Register(exchange.OKX, func(config ExchangeConfig) (exchange.Exchange, error) {
+		instType := config.InstrumentType
+		if instType == "" {
+			// Perpetual and dated inputs such as BTC-PERP resolve to derivative books
+			if inst, err := symbols.Parse(config.Symbol); err == nil {
+				switch inst.Type {
+				case symbols.Perpetual:
+					instType = okx.InstTypeSwap
+				case symbols.Future:
+					instType = okx.InstTypeFutures
+				}
+			}
+		}
+
+		// The REST poller cannot use credentials, so logged-in sessions go over WebSocket
+		if instType != "" || config.APIKey != "" {
+			return okx.NewBookExchange(okx.BookConfig{
+				Symbol:     config.Symbol,
+				InstType:   instType,
+				Contract:   config.Contract,
+				APIKey:     config.APIKey,
+				APISecret:  config.APISecret,
+				Passphrase: config.Passphrase,
+			}), nil
+		}
+		return okx.NewSpotExchange(okx.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Coinbase, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return coinbase.NewSpotExchange(coinbase.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Asterdexf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return asterdex.NewFuturesExchange(asterdex.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.BingX, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return bingx.NewSpotExchange(bingx.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Hyperliquidf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return hyperliquid.NewFuturesExchange(hyperliquid.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Deribitf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return deribit.NewFuturesExchange(deribit.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Bitget, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return bitget.NewSpotExchange(bitget.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Bitgetf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return bitget.NewFuturesExchange(bitget.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.CryptoCom, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return cryptocom.NewSpotExchange(cryptocom.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Bitstamp, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return bitstamp.NewSpotExchange(bitstamp.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Gemini, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return gemini.NewSpotExchange(gemini.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Phemexf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return phemex.NewFuturesExchange(phemex.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.WooXf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return woox.NewFuturesExchange(woox.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Aevof, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return aevo.NewFuturesExchange(aevo.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Paradexf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return paradex.NewFuturesExchange(paradex.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Hyperliquid, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return hyperliquid.NewSpotExchange(hyperliquid.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.CoinbaseIntx, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return coinbaseintx.NewFuturesExchange(coinbaseintx.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Backpack, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return backpack.NewSpotExchange(backpack.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.UniswapV3, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return uniswap.NewPoolExchange(uniswap.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	Register(exchange.Lighterf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return lighter.NewFuturesExchange(lighter.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.BinanceUS, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return binance.NewUSSpotExchange(binance.Config{
+			Symbol:      config.Symbol,
+			Depth:       config.Depth,
+			UpdateSpeed: config.UpdateSpeed,
+		}), nil
+	})
+
+	RegisterOptional(exchange.BinanceCoinf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return binance.NewCoinFuturesExchange(binance.Config{
+			Symbol:      config.Symbol,
+			Depth:       config.Depth,
+			UpdateSpeed: config.UpdateSpeed,
+		}), nil
+	})
+
+	RegisterOptional(exchange.BingXf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return bingx.NewFuturesExchange(bingx.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.Gate, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return gate.NewSpotExchange(gate.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.Gatef, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return gate.NewFuturesExchange(gate.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.Dydxf, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return dydx.NewFuturesExchange(dydx.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.HTX, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return htx.NewSpotExchange(htx.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.Phemex, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return phemex.NewSpotExchange(phemex.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.Poloniex, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return poloniex.NewSpotExchange(poloniex.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.BitMart, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return bitmart.NewSpotExchange(bitmart.Config{
+			Symbol: config.Symbol,
+		}), nil
+	})
+
+	RegisterOptional(exchange.File, func(config ExchangeConfig) (exchange.Exchange, error) {
+		return replay.NewFileExchange(replay.Config{
+			Symbol: config.Symbol,
+			Path:   config.Path,
+		}), nil
+	})
+
+	RegisterOptional(exchange.Aggregated, newAggregated)
+}
@@ -2,103 +2,162 @@ package factory
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
-	"orderbook/internal/exchange"
-	"orderbook/internal/exchange/asterdex"
-	"orderbook/internal/exchange/binance"
-	"orderbook/internal/exchange/bingx"
-	"orderbook/internal/exchange/bybit"
-	"orderbook/internal/exchange/coinbase"
-	"orderbook/internal/exchange/hyperliquid"
-	"orderbook/internal/exchange/kraken"
-	"orderbook/internal/exchange/okx"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/symbols"
 )
 
 // ExchangeConfig holds configuration for creating an exchange
 type ExchangeConfig struct {
-	Name   exchange.ExchangeName
-	Symbol string
+	Name           exchange.ExchangeName
+	Symbol         string
+	InstrumentType string                  // OKX only: SPOT, SWAP, FUTURES or OPTION
+	Contract       string                  // OKX only: expiry or expiry-strike-type suffix
+	Path           string                  // file only: recording to replay
+	Venues         []exchange.ExchangeName // aggregated only: venues to merge
+	TickSize       float64                 // aggregated only: bucket size, 0 merges exact prices
+	L3             bool                    // Prefer the order-level feed where the venue has one (currently Kraken)
+	Depth          int                     // Binance snapshot size or Bybit channel depth, 0 keeps the adapter default
+	UpdateSpeed    time.Duration           // Binance diff stream interval, 0 keeps the adapter default
+
+	// Optional API credentials for venues that serve richer market data to
+	// logged-in sessions (currently OKX tick-by-tick books and the Kraken level3
+	// feed); others ignore them
+	APIKey     string
+	APISecret  string
+	Passphrase string
+}
+
+// Constructor creates an exchange adapter from its configuration
+type Constructor func(config ExchangeConfig) (exchange.Exchange, error)
+
+// registration is a registered exchange constructor
+type registration struct {
+	name        exchange.ExchangeName
+	constructor Constructor
+	monitored   bool // Included in the default monitoring set
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[exchange.ExchangeName]*registration)
+	order      []exchange.ExchangeName // Registration order, used for display
+)
+
+// Register makes an exchange adapter available by name and includes it in the
+// default monitoring set. Downstream packages can call it from init to add
+// custom or private adapters without modifying this package.
+// It panics if the name is registered twice or the constructor is nil.
+func Register(name exchange.ExchangeName, constructor Constructor) {
+	register(name, constructor, true)
+}
+
+// RegisterOptional makes an exchange adapter available by name without adding it
+// to the default monitoring set
+func RegisterOptional(name exchange.ExchangeName, constructor Constructor) {
+	register(name, constructor, false)
+}
+
+func register(name exchange.ExchangeName, constructor Constructor, monitored bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if constructor == nil {
+		panic(fmt.Sprintf("factory: Register constructor is nil for %s", name))
+	}
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("factory: Register called twice for %s", name))
+	}
+
+	registry[name] = &registration{
+		name:        name,
+		constructor: constructor,
+		monitored:   monitored,
+	}
+	order = append(order, name)
+}
+
+// ListRegistered returns every registered exchange in registration order
+func ListRegistered() []exchange.ExchangeName {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]exchange.ExchangeName, len(order))
+	copy(names, order)
+	return names
+}
+
+// ListMonitored returns the registered exchanges in the default monitoring set, in registration order
+func ListMonitored() []exchange.ExchangeName {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]exchange.ExchangeName, 0, len(order))
+	for _, name := range order {
+		if registry[name].monitored {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ParseExchanges parses a comma-separated list of exchange names, e.g. binance,okx,kraken
+// Names are case-insensitive and listed once; an unknown name fails with the registered ones.
+// An empty list returns nil, leaving the choice to the caller
+func ParseExchanges(list string) ([]exchange.ExchangeName, error) {
+	var names []exchange.ExchangeName
+	for _, field := range strings.Split(list, ",") {
+		name := exchange.ExchangeName(strings.ToLower(strings.TrimSpace(field)))
+		if name == "" || slices.Contains(names, name) {
+			continue
+		}
+		if !ValidateExchangeName(string(name)) {
+			registered := ListRegistered()
+			known := make([]string, len(registered))
+			for i, r := range registered {
+				known[i] = string(r)
+			}
+			return nil, fmt.Errorf("unknown exchange %q, registered exchanges are: %s", name, strings.Join(known, ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
 }
 
 // NewExchange creates a new exchange instance based on the configuration
 func NewExchange(config ExchangeConfig) (exchange.Exchange, error) {
-	switch config.Name {
-	case exchange.Binancef:
-		return binance.NewFuturesExchange(binance.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.Binance:
-		return binance.NewSpotExchange(binance.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.Bybitf:
-		return bybit.NewFuturesExchange(bybit.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.Bybit:
-		return bybit.NewSpotExchange(bybit.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.Kraken:
-		return kraken.NewSpotExchange(kraken.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.OKX:
-		return okx.NewSpotExchange(okx.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.Coinbase:
-		return coinbase.NewSpotExchange(coinbase.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.Asterdexf:
-		return asterdex.NewFuturesExchange(asterdex.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.BingX:
-		return bingx.NewSpotExchange(bingx.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.BingXf:
-		return bingx.NewFuturesExchange(bingx.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	case exchange.Hyperliquidf:
-		return hyperliquid.NewFuturesExchange(hyperliquid.Config{
-			Symbol: config.Symbol,
-		}), nil
-
-	default:
+	registryMu.RLock()
+	reg, ok := registry[config.Name]
+	registryMu.RUnlock()
+
+	if !ok {
 		return nil, fmt.Errorf("unknown exchange: %s", config.Name)
 	}
+
+	config.Symbol = symbols.Native(config.Name, config.Symbol)
+
+	return reg.constructor(config)
 }
 
 // ValidateExchangeName checks if the exchange name is supported
 func ValidateExchangeName(name string) bool {
-	switch exchange.ExchangeName(name) {
-	case exchange.Binancef, exchange.Binance, exchange.Bybitf, exchange.Bybit, exchange.Kraken, exchange.Hyperliquidf, exchange.OKX, exchange.Coinbase, exchange.Asterdexf, exchange.BingX, exchange.BingXf:
-		return true
-	default:
-		return false
-	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	_, ok := registry[exchange.ExchangeName(name)]
+	return ok
 }
 
 // GetSupportedExchanges returns a list of all supported exchanges
 func GetSupportedExchanges() []exchange.ExchangeName {
-	return []exchange.ExchangeName{exchange.Binancef, exchange.Binance, exchange.Bybitf, exchange.Bybit, exchange.Kraken, exchange.Hyperliquidf, exchange.OKX, exchange.Coinbase, exchange.Asterdexf, exchange.BingX, exchange.BingXf}
+	return ListRegistered()
 }
 
 // GetImplementedExchanges returns a list of currently implemented exchanges
 func GetImplementedExchanges() []exchange.ExchangeName {
-	return []exchange.ExchangeName{exchange.Binancef, exchange.Binance, exchange.Bybitf, exchange.Bybit, exchange.Kraken, exchange.Hyperliquidf, exchange.OKX, exchange.Coinbase, exchange.Asterdexf, exchange.BingX, exchange.BingXf}
+	return ListRegistered()
 }
@@ -0,0 +1,93 @@
+package factory
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+func TestParseExchanges(t *testing.T) {
+	names, err := ParseExchanges(" Binance,okx,,kraken,okx ")
+	if err != nil {
+		t.Fatalf("ParseExchanges() error: %v", err)
+	}
+	want := []exchange.ExchangeName{exchange.Binance, exchange.OKX, exchange.Kraken}
+	if len(names) != len(want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, names)
+		}
+	}
+
+	if names, err := ParseExchanges(""); err != nil || names != nil {
+		t.Errorf("expected nothing for an empty list, got %v, %v", names, err)
+	}
+
+	_, err = ParseExchanges("binance,mtgox")
+	if err == nil {
+		t.Fatal("expected an error for an unknown exchange")
+	}
+	if !strings.Contains(err.Error(), `"mtgox"`) || !strings.Contains(err.Error(), "binancef, binance") {
+		t.Errorf("expected the error to list the registered exchanges, got %v", err)
+	}
+}
+
+func TestNewExchangeStreamOptions(t *testing.T) {
+	tests := []struct {
+		config    ExchangeConfig
+		wantDepth int
+		wantSpeed time.Duration
+	}{
+		{ExchangeConfig{Name: exchange.Binance}, 5000, time.Second},
+		{ExchangeConfig{Name: exchange.Binance, Depth: 20, UpdateSpeed: 100 * time.Millisecond}, 20, 100 * time.Millisecond},
+		{ExchangeConfig{Name: exchange.Binancef, Depth: 50, UpdateSpeed: 500 * time.Millisecond}, 50, 500 * time.Millisecond},
+		{ExchangeConfig{Name: exchange.Binancef, Depth: 5000, UpdateSpeed: time.Second}, 1000, 250 * time.Millisecond}, // Not offered on futures
+		{ExchangeConfig{Name: exchange.Bybit, Depth: 50}, 50, 20 * time.Millisecond},
+		{ExchangeConfig{Name: exchange.Bybitf, Depth: 500}, 500, 100 * time.Millisecond},
+		{ExchangeConfig{Name: exchange.Bybit, Depth: 500}, 1000, 200 * time.Millisecond}, // Linear only
+	}
+	for _, tt := range tests {
+		tt.config.Symbol = "BTCUSDT"
+		ex, err := NewExchange(tt.config)
+		if err != nil {
+			t.Fatalf("NewExchange(%+v) error: %v", tt.config, err)
+		}
+		caps := ex.Capabilities()
+		if caps.MaxDepth != tt.wantDepth || caps.UpdateInterval != tt.wantSpeed {
+			t.Errorf("%s with depth %d and speed %v: expected %d levels every %v, got %d every %v",
+				tt.config.Name, tt.config.Depth, tt.config.UpdateSpeed, tt.wantDepth, tt.wantSpeed, caps.MaxDepth, caps.UpdateInterval)
+		}
+	}
+}
+
+func TestNewExchangeNativeSymbol(t *testing.T) {
+	tests := []struct {
+		config ExchangeConfig
+		want   string
+	}{
+		{ExchangeConfig{Name: exchange.Kraken, Symbol: "BTCUSDT"}, "BTC/USD"},
+		{ExchangeConfig{Name: exchange.OKX, Symbol: "BTCUSDT"}, "BTC-USDT"},
+		{ExchangeConfig{Name: exchange.OKX, Symbol: "BTC-PERP"}, "BTC-USDT-SWAP"},
+		{ExchangeConfig{Name: exchange.OKX, Symbol: "ETHUSDT", InstrumentType: "OPTION", Contract: "250627-4000-C"}, "ETH-USD-250627-4000-C"},
+		{ExchangeConfig{Name: exchange.Coinbase, Symbol: "BTC-USDT"}, "BTC-USD"},
+		{ExchangeConfig{Name: exchange.Deribitf, Symbol: "BTCUSDC"}, "BTC_USDC-PERPETUAL"},
+		{ExchangeConfig{Name: exchange.Phemex, Symbol: "BTC/USDT"}, "sBTCUSDT"},
+		{ExchangeConfig{Name: exchange.Gatef, Symbol: "ETHUSDT"}, "ETH_USDT"},
+		{ExchangeConfig{Name: exchange.Lighterf, Symbol: "SOL-PERP"}, "SOL"},
+		{ExchangeConfig{Name: exchange.BinanceCoinf, Symbol: "BTCUSDT"}, "BTCUSD_PERP"},
+		{ExchangeConfig{Name: exchange.Bitstamp, Symbol: "XBT/USDC"}, "btcusd"},
+	}
+	for _, tt := range tests {
+		ex, err := NewExchange(tt.config)
+		if err != nil {
+			t.Fatalf("NewExchange(%+v) error: %v", tt.config, err)
+		}
+		if got := ex.Capabilities().NativeSymbol; got != tt.want {
+			t.Errorf("%s %s: expected the adapter to use %s, got %s", tt.config.Name, tt.config.Symbol, tt.want, got)
+		}
+	}
+}
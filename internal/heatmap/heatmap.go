@@ -0,0 +1,225 @@
+// Package heatmap keeps a rolling record of the depth of each book, bucketed by price, for
+// Bookmap-style liquidity heatmaps
+//
+// Every interval each registered book is aggregated into price buckets of a fixed width and
+// the buckets near mid are stored as a column; columns older than the window are dropped.
+// Matrix turns the columns of a book into a time×price matrix of resting size.
+package heatmap
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// Config configures a heatmap
+type Config struct {
+	Interval time.Duration   // Time between columns, default 1s
+	Window   time.Duration   // Time columns are kept, default 15m
+	Tick     decimal.Decimal // Bucket width in quote units; zero picks one per book from its first mid
+	Range    decimal.Decimal // Fraction of mid kept either side, default 0.01
+}
+
+// autoBuckets is the number of buckets per side Range is divided into when Tick is zero
+const autoBuckets = 50
+
+// Heatmap samples the depth of registered books
+// Books register and unregister like they do with a collector
+type Heatmap struct {
+	config Config
+	mu     sync.RWMutex
+	symbol string
+	books  map[string]*series
+}
+
+// series is the record of one book
+type series struct {
+	ob      *orderbook.OrderBook
+	tick    decimal.Decimal // Bucket width, fixed on the first sample so columns line up
+	columns []column        // Oldest first
+}
+
+// column is the depth of a book at one time
+type column struct {
+	time    time.Time
+	bestBid float64
+	bestAsk float64
+	cells   []cell // By ascending price
+}
+
+// cell is the size resting in the bucket at index times the tick
+type cell struct {
+	index int64
+	size  float64
+}
+
+// New creates a heatmap of the books of symbol; call Start to sample
+func New(config Config, symbol string) *Heatmap {
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.Window <= 0 {
+		config.Window = 15 * time.Minute
+	}
+	if !config.Range.IsPositive() {
+		config.Range = decimal.NewFromFloat(0.01)
+	}
+	return &Heatmap{config: config, symbol: symbol, books: make(map[string]*series)}
+}
+
+// RegisterOrderbook starts recording the book of an exchange
+func (h *Heatmap) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.books[name] = &series{ob: ob, tick: h.config.Tick}
+}
+
+// UnregisterOrderbook stops recording the book of an exchange and drops its columns
+func (h *Heatmap) UnregisterOrderbook(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.books, name)
+}
+
+// SetSymbol changes the symbol reported with matrices, for books registered after a symbol switch
+func (h *Heatmap) SetSymbol(symbol string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.symbol = symbol
+}
+
+// Start samples the books every interval until ctx is done
+func (h *Heatmap) Start(ctx context.Context) {
+	ticker := time.NewTicker(h.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.sample(now)
+		}
+	}
+}
+
+// sample adds a column to every initialized book with a two-sided market
+func (h *Heatmap) sample(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cutoff := now.Add(-h.config.Window)
+	for _, s := range h.books {
+		trim := 0
+		for trim < len(s.columns) && s.columns[trim].time.Before(cutoff) {
+			trim++
+		}
+		s.columns = s.columns[trim:]
+
+		snapshot := s.ob.Snapshot()
+		stats := snapshot.Stats
+		if !snapshot.Initialized || !stats.BestBid.IsPositive() || !stats.BestAsk.IsPositive() {
+			continue
+		}
+		mid := stats.BestBid.Add(stats.BestAsk).Div(decimal.NewFromInt(2))
+		if !s.tick.IsPositive() {
+			s.tick = collector.BucketStep(mid.Mul(h.config.Range).Div(decimal.NewFromInt(autoBuckets)))
+		}
+		low := mid.Mul(decimal.NewFromInt(1).Sub(h.config.Range))
+		high := mid.Mul(decimal.NewFromInt(1).Add(h.config.Range))
+
+		bids, asks := snapshot.Aggregate(s.tick)
+		col := column{time: now, bestBid: stats.BestBid.InexactFloat64(), bestAsk: stats.BestAsk.InexactFloat64()}
+		for i := len(bids) - 1; i >= 0; i-- {
+			if bids[i].Price.GreaterThanOrEqual(low) {
+				col.cells = append(col.cells, s.cell(bids[i].Price, bids[i].Quantity))
+			}
+		}
+		for _, level := range asks {
+			if level.Price.GreaterThan(high) {
+				break
+			}
+			col.cells = append(col.cells, s.cell(level.Price, level.Quantity))
+		}
+		s.columns = append(s.columns, col)
+	}
+}
+
+func (s *series) cell(price, quantity decimal.Decimal) cell {
+	return cell{index: price.Div(s.tick).Round(0).IntPart(), size: quantity.InexactFloat64()}
+}
+
+// Matrix is the depth of a book over time, bucketed by price
+type Matrix struct {
+	Exchange string      `json:"exchange"`
+	Symbol   string      `json:"symbol"`
+	Tick     float64     `json:"tick"`     // Bucket width
+	Prices   []float64   `json:"prices"`   // Bucket prices, ascending; bids are floored and asks ceiled to them
+	Times    []time.Time `json:"times"`    // Column times, ascending
+	BestBid  []float64   `json:"best_bid"` // Best bid of each column
+	BestAsk  []float64   `json:"best_ask"` // Best ask of each column
+	Depth    [][]float64 `json:"depth"`    // Depth[t][p] is the base size resting in bucket Prices[p] at Times[t]
+}
+
+// Matrix returns the columns of the last window of the book of an exchange, or all kept
+// columns if window is zero; false means the exchange is not registered
+func (h *Heatmap) Matrix(exchange string, window time.Duration) (*Matrix, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	s, ok := h.books[exchange]
+	if !ok {
+		return nil, false
+	}
+
+	columns := s.columns
+	if window > 0 && len(columns) > 0 {
+		cutoff := columns[len(columns)-1].time.Add(-window)
+		columns = columns[sort.Search(len(columns), func(i int) bool { return !columns[i].time.Before(cutoff) }):]
+	}
+	m := &Matrix{
+		Exchange: exchange,
+		Symbol:   h.symbol,
+		Tick:     s.tick.InexactFloat64(),
+		Prices:   []float64{},
+		Times:    make([]time.Time, len(columns)),
+		BestBid:  make([]float64, len(columns)),
+		BestAsk:  make([]float64, len(columns)),
+		Depth:    make([][]float64, len(columns)),
+	}
+
+	// Rows cover every bucket any column has, so mid drifting through the window widens them
+	var lowest, highest int64
+	first := true
+	for _, col := range columns {
+		if len(col.cells) == 0 {
+			continue
+		}
+		if low := col.cells[0].index; first || low < lowest {
+			lowest = low
+		}
+		if high := col.cells[len(col.cells)-1].index; first || high > highest {
+			highest = high
+		}
+		first = false
+	}
+	if !first {
+		for index := lowest; index <= highest; index++ {
+			m.Prices = append(m.Prices, s.tick.Mul(decimal.NewFromInt(index)).InexactFloat64())
+		}
+	}
+	for t, col := range columns {
+		m.Times[t] = col.time
+		m.BestBid[t] = col.bestBid
+		m.BestAsk[t] = col.bestAsk
+		m.Depth[t] = make([]float64, len(m.Prices))
+		for _, c := range col.cells {
+			m.Depth[t][c.index-lowest] = c.size
+		}
+	}
+	return m, true
+}
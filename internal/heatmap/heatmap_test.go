@@ -0,0 +1,80 @@
+package heatmap
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+func liveBook(t *testing.T) *orderbook.OrderBook {
+	t.Helper()
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		Bids: []exchange.PriceLevel{{Price: "99", Quantity: "1"}, {Price: "98.5", Quantity: "2"}, {Price: "90", Quantity: "5"}},
+		Asks: []exchange.PriceLevel{{Price: "101", Quantity: "3"}, {Price: "101.5", Quantity: "4"}, {Price: "110", Quantity: "6"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	return ob
+}
+
+func TestMatrix(t *testing.T) {
+	h := New(Config{Window: time.Minute, Tick: decimal.NewFromInt(1), Range: decimal.NewFromFloat(0.02)}, "BTCUSDT")
+	h.RegisterOrderbook("okx", liveBook(t), exchange.Capabilities{})
+	h.RegisterOrderbook("bybit", orderbook.New(), exchange.Capabilities{})
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := range 3 {
+		h.sample(start.Add(time.Duration(i) * 40 * time.Second))
+	}
+
+	// The first column has aged out of the window; levels beyond 2% of mid are left out
+	m, ok := h.Matrix("okx", 0)
+	if !ok {
+		t.Fatal("expected a matrix for okx")
+	}
+	if len(m.Times) != 2 || !m.Times[0].Equal(start.Add(40*time.Second)) {
+		t.Fatalf("unexpected times %v", m.Times)
+	}
+	if want := []float64{98, 99, 100, 101, 102}; !slices.Equal(m.Prices, want) {
+		t.Errorf("prices %v, want %v", m.Prices, want)
+	}
+	if want := []float64{2, 1, 0, 3, 4}; !slices.Equal(m.Depth[1], want) {
+		t.Errorf("depth %v, want %v", m.Depth[1], want)
+	}
+	if m.BestBid[0] != 99 || m.BestAsk[0] != 101 || m.Symbol != "BTCUSDT" || m.Tick != 1 {
+		t.Errorf("unexpected matrix %+v", m)
+	}
+
+	if m, _ := h.Matrix("okx", 10*time.Second); len(m.Times) != 1 {
+		t.Errorf("expected one column in the last 10s, got %d", len(m.Times))
+	}
+	if m, ok := h.Matrix("bybit", 0); !ok || len(m.Times) != 0 || len(m.Prices) != 0 {
+		t.Errorf("expected an empty matrix for the syncing book, got %+v", m)
+	}
+	if _, ok := h.Matrix("kraken", 0); ok {
+		t.Error("expected no matrix for an unregistered exchange")
+	}
+}
+
+func TestAutoTick(t *testing.T) {
+	h := New(Config{}, "BTCUSDT")
+	h.RegisterOrderbook("okx", liveBook(t), exchange.Capabilities{})
+	h.sample(time.Now())
+
+	// 1% of a mid of 100 in 50 buckets; the levels beyond 99 and 101 are out of range
+	m, _ := h.Matrix("okx", 0)
+	if m.Tick != 0.02 {
+		t.Errorf("tick %v, want 0.02", m.Tick)
+	}
+	if n := len(m.Prices); n != 101 || m.Prices[0] != 99 || m.Prices[n-1] != 101 || m.Depth[0][0] != 1 || m.Depth[0][n-1] != 3 {
+		t.Errorf("expected 0.02 buckets from 99 to 101, got %v", m.Prices)
+	}
+}
@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// consoleHandler writes records as "15:04:05.000 INFO  [exchange] message key=value", close
+// to the plain log lines the monitor printed before structured logging
+type consoleHandler struct {
+	w      io.Writer
+	opts   slog.HandlerOptions
+	attrs  []slog.Attr // Attributes added with WithAttrs, already qualified by their group
+	prefix string      // Group qualifying attributes added from now on, with a trailing dot
+}
+
+func newConsoleHandler(w io.Writer, opts *slog.HandlerOptions) *consoleHandler {
+	return &consoleHandler{w: w, opts: *opts}
+}
+
+// Enabled implements slog.Handler
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minimum := slog.LevelInfo
+	if h.opts.Level != nil {
+		minimum = h.opts.Level.Level()
+	}
+	return level >= minimum
+}
+
+// Handle implements slog.Handler
+func (h *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	attrs := append([]slog.Attr(nil), h.attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, qualify(h.prefix, attr)...)
+		return true
+	})
+
+	// The exchange, or else the component, leads the message like the old [name] prefix
+	name := ""
+	for _, key := range []string{KeyExchange, KeyComponent} {
+		for _, attr := range attrs {
+			if attr.Key == key && name == "" {
+				name = attr.Value.String()
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(record.Time.Format("15:04:05.000"))
+	buf.WriteByte(' ')
+	level := record.Level.String()
+	buf.WriteString(level)
+	buf.WriteString(strings.Repeat(" ", max(1, 6-len(level))))
+	if name != "" {
+		buf.WriteString("[" + name + "] ")
+	}
+	buf.WriteString(record.Message)
+	for _, attr := range attrs {
+		if attr.Key == KeyExchange || attr.Key == KeyComponent {
+			continue
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(attr.Key)
+		buf.WriteByte('=')
+		value := attr.Value.String()
+		if value == "" || strings.ContainsAny(value, " =\"") {
+			value = strconv.Quote(value)
+		}
+		buf.WriteString(value)
+	}
+	buf.WriteByte('\n')
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+// WithAttrs implements slog.Handler
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		next.attrs = append(next.attrs, qualify(h.prefix, attr)...)
+	}
+	return &next
+}
+
+// WithGroup implements slog.Handler
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.prefix = h.prefix + name + "."
+	return &next
+}
+
+// qualify flattens an attribute, prefixing its key with the open groups
+func qualify(prefix string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() != slog.KindGroup {
+		if attr.Key == "" {
+			return nil
+		}
+		attr.Key = prefix + attr.Key
+		return []slog.Attr{attr}
+	}
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+	var attrs []slog.Attr
+	for _, member := range attr.Value.Group() {
+		attrs = append(attrs, qualify(prefix, member)...)
+	}
+	return attrs
+}
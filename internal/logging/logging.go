@@ -0,0 +1,100 @@
+// Package logging sets up the structured logger shared by every component
+//
+// Records carry the exchange and symbol they concern and an event type, so logs can be
+// shipped to Loki or ELK and filtered per venue.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Standard attribute keys
+const (
+	KeyExchange  = "exchange"
+	KeySymbol    = "symbol"
+	KeyEvent     = "event"
+	KeyComponent = "component"
+)
+
+// Format selects how records are written
+type Format string
+
+const (
+	Console Format = "console" // Human-readable lines for a terminal
+	JSON    Format = "json"    // One JSON object per record, for log shippers
+)
+
+// ParseFormat parses a log format name; empty selects Console
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case "", Console:
+		return Console, nil
+	case JSON:
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unknown log format %q (want console or json)", value)
+}
+
+// ParseLevel parses debug, info, warn or error; empty selects info
+func ParseLevel(value string) (slog.Level, error) {
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", value)
+	}
+	return level, nil
+}
+
+// Config configures the logger
+type Config struct {
+	Level  slog.Level // Least severe level written
+	Format Format     // Console (default) or JSON
+}
+
+// output is the destination shared by every handler, swappable while logging
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.w.Write(p)
+}
+
+var out = &output{w: os.Stderr}
+
+// Setup makes a logger of config the slog default, which the standard log package writes
+// through too
+func Setup(config Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: config.Level}
+	var handler slog.Handler
+	if config.Format == JSON {
+		handler = slog.NewJSONHandler(out, opts)
+	} else {
+		handler = newConsoleHandler(out, opts)
+	}
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// SetOutput changes where records are written, e.g. to a terminal UI while it owns the screen
+func SetOutput(w io.Writer) {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	out.w = w
+}
+
+// For returns the default logger with the exchange and symbol of a book attached
+func For(exchange, symbol string) *slog.Logger {
+	return slog.Default().With(KeyExchange, exchange, KeySymbol, symbol)
+}
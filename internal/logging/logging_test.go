@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func capture(t *testing.T, config Config) *bytes.Buffer {
+	t.Helper()
+	previous := slog.Default()
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	Setup(config)
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		SetOutput(os.Stderr)
+	})
+	return &buf
+}
+
+func TestJSON(t *testing.T) {
+	buf := capture(t, Config{Level: slog.LevelInfo, Format: JSON})
+
+	For("binance", "BTCUSDT").Warn("Sequence gap", KeyEvent, "sequence_gap", "prev_update_id", 41)
+	For("okx", "BTC-USDT").Error("Failed to parse message", KeyEvent, "parse_failed", "error", "bad json")
+	slog.Default().With(KeyComponent, "collector").Warn("Slow insert")
+	log.Printf("[bybit] Subscribed to orderbook.1000.BTCUSDT")
+	slog.Debug("hidden below the level")
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want 4: %s", len(records), buf)
+	}
+
+	for i, want := range []map[string]any{
+		{"level": "WARN", "msg": "Sequence gap", "exchange": "binance", "symbol": "BTCUSDT", "event": "sequence_gap", "prev_update_id": float64(41)},
+		{"level": "ERROR", "msg": "Failed to parse message", "exchange": "okx", "symbol": "BTC-USDT", "event": "parse_failed", "error": "bad json"},
+		{"level": "WARN", "msg": "Slow insert", "component": "collector"},
+		// Lines of the standard log package are kept as they are, at info
+		{"level": "INFO", "msg": "[bybit] Subscribed to orderbook.1000.BTCUSDT", "exchange": nil},
+	} {
+		for key, value := range want {
+			if records[i][key] != value {
+				t.Errorf("record %d: %s = %v, want %v", i, key, records[i][key], value)
+			}
+		}
+	}
+}
+
+func TestConsole(t *testing.T) {
+	buf := capture(t, Config{Level: slog.LevelDebug})
+
+	For("kraken", "BTCUSDT").WithGroup("book").Debug("Resync", "reason", "checksum mismatch")
+	log.Printf("plain line")
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], ` DEBUG [kraken] Resync symbol=BTCUSDT book.reason="checksum mismatch"`+"\n") {
+		t.Errorf("unexpected console line %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], " INFO  plain line\n") {
+		t.Errorf("unexpected line of the log package %q", lines[1])
+	}
+}
+
+func TestParse(t *testing.T) {
+	if level, err := ParseLevel("warn"); err != nil || level != slog.LevelWarn {
+		t.Errorf("ParseLevel(warn) = %v, %v", level, err)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if format, err := ParseFormat("JSON"); err != nil || format != JSON {
+		t.Errorf("ParseFormat(JSON) = %v, %v", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,182 @@
+// Package metrics exposes the live books and the health of their feeds to Prometheus
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics reads every registered book when scraped, so values are as fresh as the scrape
+// and nothing is computed between scrapes
+// Books register and unregister like they do with a collector
+type Metrics struct {
+	symbol   string
+	mu       sync.RWMutex
+	feeds    map[string]feed
+	registry *prometheus.Registry
+}
+
+// feed is a registered book and the exchange feeding it
+type feed struct {
+	ob *orderbook.OrderBook
+	ex exchange.Exchange
+}
+
+// Labels of every per-book series
+var bookLabels = []string{"exchange", "symbol"}
+
+var (
+	bestBid       = prometheus.NewDesc("orderbook_best_bid", "Best bid price.", bookLabels, nil)
+	bestAsk       = prometheus.NewDesc("orderbook_best_ask", "Best ask price.", bookLabels, nil)
+	spread        = prometheus.NewDesc("orderbook_spread", "Best ask minus best bid.", bookLabels, nil)
+	midPrice      = prometheus.NewDesc("orderbook_mid_price", "Mid of the best bid and ask.", bookLabels, nil)
+	levels        = prometheus.NewDesc("orderbook_levels", "Price levels held per side.", append(bookLabels, "side"), nil)
+	bandLiquidity = prometheus.NewDesc("orderbook_band_liquidity", "Resting size within a band around mid, in base units; absent when the feed does not reach the band.", append(bookLabels, "band", "side"), nil)
+	bandNotional  = prometheus.NewDesc("orderbook_band_notional", "Resting notional within a band around mid, in quote units; absent when the feed does not reach the band.", append(bookLabels, "band", "side"), nil)
+	staleness     = prometheus.NewDesc("orderbook_staleness_seconds", "Seconds since the book last changed.", bookLabels, nil)
+	initialized   = prometheus.NewDesc("orderbook_initialized", "1 while the book is synced with its feed.", bookLabels, nil)
+	connected     = prometheus.NewDesc("orderbook_connected", "1 while the feed is connected.", bookLabels, nil)
+
+	updatesApplied   = prometheus.NewDesc("orderbook_updates_applied_total", "Depth updates applied to the book.", bookLabels, nil)
+	eventsDropped    = prometheus.NewDesc("orderbook_events_dropped_total", "Book events a slow subscriber had no room for.", bookLabels, nil)
+	resyncs          = prometheus.NewDesc("orderbook_resyncs_total", "Times the book was rebuilt from a fresh snapshot.", bookLabels, nil)
+	checksumFailures = prometheus.NewDesc("orderbook_checksum_failures_total", "Venue checksums that did not match the local book.", bookLabels, nil)
+	sequenceGaps     = prometheus.NewDesc("orderbook_sequence_gaps_total", "Updates that did not follow on from the previous update ID.", bookLabels, nil)
+	crossed          = prometheus.NewDesc("orderbook_crossed_total", "Updates that left the best bid at or above the best ask.", bookLabels, nil)
+	messages         = prometheus.NewDesc("orderbook_feed_messages_total", "Messages received from the feed.", bookLabels, nil)
+	feedErrors       = prometheus.NewDesc("orderbook_feed_errors_total", "Errors reading or parsing the feed.", bookLabels, nil)
+	disconnects      = prometheus.NewDesc("orderbook_feed_disconnects_total", "Times an established feed connection was lost or closed.", bookLabels, nil)
+)
+
+// New creates the metrics of the books of symbol, alongside Go runtime and process metrics
+func New(symbol string) *Metrics {
+	m := &Metrics{symbol: symbol, feeds: make(map[string]feed), registry: prometheus.NewRegistry()}
+	m.registry.MustRegister(m, collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return m
+}
+
+// RegisterExchange reports the book of an exchange and the health of its feed
+func (m *Metrics) RegisterExchange(name string, ob *orderbook.OrderBook, ex exchange.Exchange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.feeds[name] = feed{ob: ob, ex: ex}
+}
+
+// SetSymbol changes the symbol label, for books registered after a symbol switch
+func (m *Metrics) SetSymbol(symbol string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.symbol = symbol
+}
+
+// UnregisterExchange stops reporting an exchange; its series disappear from the next scrape
+func (m *Metrics) UnregisterExchange(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.feeds, name)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		bestBid, bestAsk, spread, midPrice, levels, bandLiquidity, bandNotional, staleness, initialized, connected,
+		updatesApplied, eventsDropped, resyncs, checksumFailures, sequenceGaps, crossed, messages, feedErrors, disconnects,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector, reading each book once
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.feeds))
+	for name := range m.feeds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	feeds := make([]feed, len(names))
+	for i, name := range names {
+		feeds[i] = m.feeds[name]
+	}
+	symbol := m.symbol
+	m.mu.RUnlock()
+
+	for i, name := range names {
+		collectBook(ch, name, symbol, feeds[i])
+	}
+}
+
+// collectBook emits the series of one book
+func collectBook(ch chan<- prometheus.Metric, name, symbol string, f feed) {
+	book := f.ob.Snapshot()
+	health := f.ex.Health()
+	stats := book.Stats
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, append([]string{name, symbol}, labels...)...)
+	}
+	counter := func(desc *prometheus.Desc, value int64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), name, symbol)
+	}
+
+	gauge(initialized, boolValue(book.Initialized))
+	gauge(connected, boolValue(health.Connected))
+	counter(updatesApplied, stats.EventsProcessed)
+	counter(eventsDropped, stats.EventsDropped)
+	counter(resyncs, stats.Resyncs)
+	counter(checksumFailures, stats.ChecksumFailures)
+	counter(sequenceGaps, stats.SequenceGaps)
+	counter(crossed, stats.CrossedCount)
+	counter(messages, health.MessageCount)
+	counter(feedErrors, health.ErrorCount)
+	counter(disconnects, health.Disconnects)
+	if !stats.LastUpdateTime.IsZero() {
+		gauge(staleness, stats.Staleness.Seconds())
+	}
+	if !book.Initialized {
+		return
+	}
+
+	gauge(levels, float64(len(book.Bids)), "bid")
+	gauge(levels, float64(len(book.Asks)), "ask")
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return
+	}
+	gauge(bestBid, stats.BestBid.InexactFloat64())
+	gauge(bestAsk, stats.BestAsk.InexactFloat64())
+	gauge(spread, stats.Spread.InexactFloat64())
+	gauge(midPrice, book.MidPrice().InexactFloat64())
+
+	// Bands a depth-limited feed does not reach would be understated, so they are left out
+	fullBook := f.ex.Capabilities().MaxDepth == 0
+	for _, band := range stats.Bands {
+		if !fullBook && !book.CoversDepth(band.Pct.InexactFloat64()) {
+			continue
+		}
+		label := types.BandLabel(band.Pct)
+		gauge(bandLiquidity, band.Bid.InexactFloat64(), label, "bid")
+		gauge(bandLiquidity, band.Ask.InexactFloat64(), label, "ask")
+		gauge(bandNotional, band.BidNotional.InexactFloat64(), label, "bid")
+		gauge(bandNotional, band.AskNotional.InexactFloat64(), label, "ask")
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+)
+
+// fakeFeed reports fixed health; the rest of the interface is never called
+type fakeFeed struct {
+	exchange.Exchange
+	health exchange.HealthStatus
+	caps   exchange.Capabilities
+}
+
+func (f fakeFeed) Health() exchange.HealthStatus       { return f.health }
+func (f fakeFeed) Capabilities() exchange.Capabilities { return f.caps }
+
+func TestMetrics(t *testing.T) {
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		Bids: []exchange.PriceLevel{{Price: "99", Quantity: "1"}, {Price: "90", Quantity: "2"}},
+		Asks: []exchange.PriceLevel{{Price: "101", Quantity: "3"}, {Price: "110", Quantity: "4"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	m := New("BTCUSDT")
+	m.RegisterExchange("bybit", ob, fakeFeed{health: exchange.HealthStatus{Connected: true, MessageCount: 12, Disconnects: 2}})
+	m.RegisterExchange("okx", orderbook.New(), fakeFeed{caps: exchange.Capabilities{MaxDepth: 50}})
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`orderbook_best_bid{exchange="bybit",symbol="BTCUSDT"} 99`,
+		`orderbook_spread{exchange="bybit",symbol="BTCUSDT"} 2`,
+		`orderbook_mid_price{exchange="bybit",symbol="BTCUSDT"} 100`,
+		`orderbook_levels{exchange="bybit",side="ask",symbol="BTCUSDT"} 2`,
+		`orderbook_band_liquidity{band="2%",exchange="bybit",side="bid",symbol="BTCUSDT"} 1`,
+		`orderbook_feed_messages_total{exchange="bybit",symbol="BTCUSDT"} 12`,
+		`orderbook_feed_disconnects_total{exchange="bybit",symbol="BTCUSDT"} 2`,
+		`orderbook_initialized{exchange="okx",symbol="BTCUSDT"} 0`,
+		"process_start_time_seconds",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	if strings.Contains(body, `orderbook_best_bid{exchange="okx"`) {
+		t.Error("expected no prices for an uninitialized book")
+	}
+
+	m.UnregisterExchange("bybit")
+	if body := scrape(t, m); strings.Contains(body, `exchange="bybit"`) {
+		t.Error("expected the unregistered exchange to disappear")
+	}
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Body)
+	if err != nil {
+		t.Fatalf("reading metrics: %v", err)
+	}
+	return string(body)
+}
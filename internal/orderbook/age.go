@@ -0,0 +1,96 @@
+package orderbook
+
+import (
+	"sort"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// LevelMetadata returns up to depth levels per side with their age and update count,
+// best price first; a depth of 0 returns every level
+func (ob *OrderBook) LevelMetadata(depth int) (bids, asks []types.LevelMeta) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	at := now()
+	return levelMeta(ob.bids, depth, at), levelMeta(ob.asks, depth, at)
+}
+
+// LiquidityAge buckets the size of the top depth levels per side by how long each
+// level has existed; buckets are upper age bounds and an empty list selects
+// types.DefaultAgeBuckets. A depth of 0 covers every level
+func (ob *OrderBook) LiquidityAge(depth int, buckets []time.Duration) types.AgeDistribution {
+	if len(buckets) == 0 {
+		buckets = types.DefaultAgeBuckets
+	}
+	buckets = append([]time.Duration(nil), buckets...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+	bids, asks := ob.LevelMetadata(depth)
+
+	dist := types.AgeDistribution{Buckets: make([]types.AgeBucket, len(buckets)+1)}
+	for i := range dist.Buckets {
+		dist.Buckets[i] = types.AgeBucket{BidQty: decimal.Zero, AskQty: decimal.Zero}
+		if i < len(buckets) {
+			dist.Buckets[i].MaxAge = buckets[i]
+		}
+	}
+
+	bucketFor := func(age time.Duration) *types.AgeBucket {
+		i := sort.Search(len(buckets), func(i int) bool { return age < buckets[i] })
+		return &dist.Buckets[i]
+	}
+
+	updates := 0
+	for _, level := range bids {
+		bucket := bucketFor(level.Age)
+		bucket.BidQty = bucket.BidQty.Add(level.Quantity)
+		bucket.BidLevels++
+		updates += level.Updates
+	}
+	for _, level := range asks {
+		bucket := bucketFor(level.Age)
+		bucket.AskQty = bucket.AskQty.Add(level.Quantity)
+		bucket.AskLevels++
+		updates += level.Updates
+	}
+
+	dist.BidMeanAge = meanAge(bids)
+	dist.AskMeanAge = meanAge(asks)
+	if levels := len(bids) + len(asks); levels > 0 {
+		dist.UpdatesPerLevel = float64(updates) / float64(levels)
+	}
+	return dist
+}
+
+// levelMeta copies the metadata of up to depth levels of a side (must be called with mutex locked)
+func levelMeta(side *bookSide, depth int, at time.Time) []types.LevelMeta {
+	var levels []types.LevelMeta
+	side.ascendNodes(func(node *levelNode) bool {
+		levels = append(levels, types.LevelMeta{
+			Price:    node.Price,
+			Quantity: node.Quantity,
+			Created:  node.created,
+			Age:      at.Sub(node.created),
+			Updates:  node.updates,
+		})
+		return depth <= 0 || len(levels) < depth
+	})
+	return levels
+}
+
+// meanAge returns the size-weighted mean age of levels
+func meanAge(levels []types.LevelMeta) time.Duration {
+	total, weighted := decimal.Zero, decimal.Zero
+	for _, level := range levels {
+		total = total.Add(level.Quantity)
+		weighted = weighted.Add(level.Quantity.Mul(decimal.NewFromInt(int64(level.Age))))
+	}
+	if total.IsZero() {
+		return 0
+	}
+	return time.Duration(weighted.Div(total).IntPart())
+}
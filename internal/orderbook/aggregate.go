@@ -0,0 +1,65 @@
+package orderbook
+
+import (
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// Aggregate returns both sides grouped into price buckets of width tick (e.g. 10 for $10 bins),
+// best bucket first. Bids are floored and asks ceiled to the bucket edge so the
+// aggregated spread never looks tighter than the real one; a tick <= 0 returns every level
+func (ob *OrderBook) Aggregate(tick decimal.Decimal) (bids, asks []types.PriceLevel) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	if !tick.IsPositive() {
+		return ob.bids.Top(0), ob.asks.Top(0)
+	}
+	return bucketSide(ob.bids, tick, true), bucketSide(ob.asks, tick, false)
+}
+
+// Aggregate groups the copied levels into price buckets like OrderBook.Aggregate
+func (s *BookSnapshot) Aggregate(tick decimal.Decimal) (bids, asks []types.PriceLevel) {
+	if !tick.IsPositive() {
+		return s.Bids, s.Asks
+	}
+	return bucketLevels(s.Bids, tick, true), bucketLevels(s.Asks, tick, false)
+}
+
+// bucketSide groups a side into buckets while walking it in price order (must be called with mutex locked)
+func bucketSide(side *bookSide, tick decimal.Decimal, floor bool) []types.PriceLevel {
+	var buckets []types.PriceLevel
+	side.Ascend(func(level types.PriceLevel) bool {
+		buckets = addToBucket(buckets, level, tick, floor)
+		return true
+	})
+	return buckets
+}
+
+// bucketLevels groups levels sorted from the best price outwards into buckets
+func bucketLevels(levels []types.PriceLevel, tick decimal.Decimal, floor bool) []types.PriceLevel {
+	var buckets []types.PriceLevel
+	for _, level := range levels {
+		buckets = addToBucket(buckets, level, tick, floor)
+	}
+	return buckets
+}
+
+// addToBucket adds a level to the last bucket when it rounds to the same edge, else opens a new one
+// Levels arrive in price order, so each bucket is only ever the last one
+func addToBucket(buckets []types.PriceLevel, level types.PriceLevel, tick decimal.Decimal, floor bool) []types.PriceLevel {
+	edge := level.Price.Div(tick)
+	if floor {
+		edge = edge.Floor()
+	} else {
+		edge = edge.Ceil()
+	}
+	edge = edge.Mul(tick)
+
+	if last := len(buckets) - 1; last >= 0 && buckets[last].Price.Equal(edge) {
+		buckets[last].Quantity = buckets[last].Quantity.Add(level.Quantity)
+		return buckets
+	}
+	return append(buckets, types.PriceLevel{Price: edge, Quantity: level.Quantity})
+}
@@ -0,0 +1,114 @@
+package orderbook
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// sideBands keeps the cumulative size and notional within each band around mid for
+// one side, adjusted as levels change rather than re-walked on every update
+// When mid moves only the levels between the old and new band limits are visited
+type sideBands struct {
+	pcts   []decimal.Decimal // Ascending band fractions the sums are kept for
+	mid    decimal.Decimal   // Mid the limits were computed at
+	limits []decimal.Decimal // Price limit of each band at the current mid, nil until first centred
+	sums   []bandSum         // Cumulative: sums[i] holds every level within pcts[i]
+}
+
+// BandSums returns the cumulative liquidity within each band around midPrice; bands must be
+// ascending. The first call, or a call with different bands, walks the side once
+func (s *bookSide) BandSums(midPrice decimal.Decimal, bands []decimal.Decimal) []bandSum {
+	if s.bands.limits == nil || !equalBands(s.bands.pcts, bands) {
+		s.bands.pcts = append(s.bands.pcts[:0], bands...)
+		s.bands.limits = make([]decimal.Decimal, len(bands))
+		for i, pct := range bands {
+			s.bands.limits[i] = s.bandLimit(midPrice, pct)
+		}
+		s.bands.sums = bandLiquidity(s, midPrice, bands, s.descending)
+		s.bands.mid = midPrice
+	} else if !midPrice.Equal(s.bands.mid) {
+		s.recenterBands(midPrice)
+		s.bands.mid = midPrice
+	}
+	return append([]bandSum(nil), s.bands.sums...)
+}
+
+// ResetBands drops the band sums, e.g. while the book is one-sided and has no mid
+func (s *bookSide) ResetBands() {
+	s.bands.limits = nil
+}
+
+// bandLimit returns the furthest price from midPrice still within pct on this side
+func (s *bookSide) bandLimit(midPrice, pct decimal.Decimal) decimal.Decimal {
+	if s.descending {
+		return midPrice.Sub(midPrice.Mul(pct))
+	}
+	return midPrice.Add(midPrice.Mul(pct))
+}
+
+// recenterBands moves each band limit to the new mid, adding the levels it now covers
+// and removing those it no longer does
+func (s *bookSide) recenterBands(midPrice decimal.Decimal) {
+	for i, pct := range s.bands.pcts {
+		limit := s.bandLimit(midPrice, pct)
+		previous := s.bands.limits[i]
+		if previous.Equal(limit) {
+			continue
+		}
+
+		// Levels past the nearer limit up to and including the further one change bands
+		near, far, widened := limit, previous, false
+		if s.before(previous, limit) {
+			near, far, widened = previous, limit, true
+		}
+
+		sum := &s.bands.sums[i]
+		s.ascendFrom(near, func(node *levelNode) bool {
+			if node.Price.Equal(near) {
+				return true
+			}
+			if s.before(far, node.Price) {
+				return false
+			}
+			notional := node.Price.Mul(node.Quantity)
+			if widened {
+				sum.size = sum.size.Add(node.Quantity)
+				sum.notional = sum.notional.Add(notional)
+			} else {
+				sum.size = sum.size.Sub(node.Quantity)
+				sum.notional = sum.notional.Sub(notional)
+			}
+			return true
+		})
+		s.bands.limits[i] = limit
+	}
+}
+
+// adjustBands applies a quantity change at price to every band covering it
+func (s *bookSide) adjustBands(price, delta decimal.Decimal) {
+	if s.bands.limits == nil || delta.IsZero() {
+		return
+	}
+
+	notional := price.Mul(delta)
+	for i := len(s.bands.limits) - 1; i >= 0; i-- {
+		// Bands are nested, so once one misses the price so do all narrower ones
+		if s.before(s.bands.limits[i], price) {
+			return
+		}
+		s.bands.sums[i].size = s.bands.sums[i].size.Add(delta)
+		s.bands.sums[i].notional = s.bands.sums[i].notional.Add(notional)
+	}
+}
+
+// equalBands reports whether two band lists hold the same fractions in the same order
+func equalBands(a, b []decimal.Decimal) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package orderbook
+
+import (
+	"hash/crc32"
+	"strings"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+// Checksum describes how a venue checksums the top of its book
+type Checksum struct {
+	Depth   int                                           // Levels per side covered by the checksum
+	Compute func(bids, asks []exchange.PriceLevel) uint32 // Levels are best first, as published
+}
+
+// checksums maps venues that publish book checksums to their algorithm
+// Bitget verifies its checksums inside the adapter, where it can resync without a snapshot
+var checksums = map[exchange.ExchangeName]*Checksum{
+	exchange.Kraken: {Depth: 10, Compute: krakenChecksum},
+	exchange.OKX:    {Depth: 25, Compute: okxChecksum},
+}
+
+// ChecksumFor returns the checksum algorithm of a venue, or nil if it publishes none
+func ChecksumFor(name exchange.ExchangeName) *Checksum {
+	return checksums[name]
+}
+
+// krakenChecksum computes the CRC32 of the top asks then the top bids, each level
+// written as price and quantity with the decimal point and leading zeros removed
+func krakenChecksum(bids, asks []exchange.PriceLevel) uint32 {
+	var b strings.Builder
+	for _, level := range asks {
+		b.WriteString(krakenDigits(level.Price))
+		b.WriteString(krakenDigits(level.Quantity))
+	}
+	for _, level := range bids {
+		b.WriteString(krakenDigits(level.Price))
+		b.WriteString(krakenDigits(level.Quantity))
+	}
+	return crc32.ChecksumIEEE([]byte(b.String()))
+}
+
+// krakenDigits strips the decimal point and leading zeros of a formatted value
+func krakenDigits(value string) string {
+	return strings.TrimLeft(strings.Replace(value, ".", "", 1), "0")
+}
+
+// okxChecksum computes the CRC32 of the top levels interleaved as
+// bid1Price:bid1Size:ask1Price:ask1Size:..., skipping a side once it runs out
+func okxChecksum(bids, asks []exchange.PriceLevel) uint32 {
+	depth := len(bids)
+	if len(asks) > depth {
+		depth = len(asks)
+	}
+
+	parts := make([]string, 0, 4*depth)
+	for i := 0; i < depth; i++ {
+		if i < len(bids) {
+			parts = append(parts, bids[i].Price, bids[i].Quantity)
+		}
+		if i < len(asks) {
+			parts = append(parts, asks[i].Price, asks[i].Quantity)
+		}
+	}
+	return crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))
+}
@@ -0,0 +1,122 @@
+package orderbook
+
+import (
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// DepthCurve returns the cumulative depth of each side, one point per level from the
+// best price outwards, up to band (0.02 = 2%) from mid; a band <= 0 covers every level
+func (ob *OrderBook) DepthCurve(band decimal.Decimal) (bids, asks []types.DepthPoint) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	if ob.bestBid.IsZero() || ob.bestAsk.IsZero() {
+		return nil, nil
+	}
+
+	midPrice := ob.bestBid.Add(ob.bestAsk).Div(decimal.NewFromInt(2))
+	return walkCurve(ob.bids.Ascend, midPrice, band, true), walkCurve(ob.asks.Ascend, midPrice, band, false)
+}
+
+// DepthAtOffsets samples the cumulative depth curve at fixed offsets from mid (0.001 = 10bp)
+func (ob *OrderBook) DepthAtOffsets(offsets []decimal.Decimal) []types.BandLiquidity {
+	if len(offsets) == 0 {
+		return nil
+	}
+	offsets = types.NormalizeBands(offsets)
+
+	bids, asks := ob.DepthCurve(offsets[len(offsets)-1])
+	return sampleCurve(bids, asks, offsets)
+}
+
+// DepthCurve returns the cumulative depth of the copied levels like OrderBook.DepthCurve
+func (s *BookSnapshot) DepthCurve(band decimal.Decimal) (bids, asks []types.DepthPoint) {
+	midPrice := s.MidPrice()
+	if midPrice.IsZero() {
+		return nil, nil
+	}
+	return walkCurve(ascendLevels(s.Bids), midPrice, band, true), walkCurve(ascendLevels(s.Asks), midPrice, band, false)
+}
+
+// DepthAtOffsets samples the copied levels like OrderBook.DepthAtOffsets
+func (s *BookSnapshot) DepthAtOffsets(offsets []decimal.Decimal) []types.BandLiquidity {
+	if len(offsets) == 0 {
+		return nil
+	}
+	offsets = types.NormalizeBands(offsets)
+
+	bids, asks := s.DepthCurve(offsets[len(offsets)-1])
+	return sampleCurve(bids, asks, offsets)
+}
+
+// ascendLevels adapts levels sorted from the best price outwards to the bookSide walk
+func ascendLevels(levels []types.PriceLevel) func(fn func(level types.PriceLevel) bool) {
+	return func(fn func(level types.PriceLevel) bool) {
+		for _, level := range levels {
+			if !fn(level) {
+				return
+			}
+		}
+	}
+}
+
+// walkCurve accumulates size and notional level by level until the band is passed
+func walkCurve(ascend func(fn func(level types.PriceLevel) bool), midPrice, band decimal.Decimal, bid bool) []types.DepthPoint {
+	var points []types.DepthPoint
+	quantity, notional := decimal.Zero, decimal.Zero
+
+	ascend(func(level types.PriceLevel) bool {
+		distance := level.Price.Sub(midPrice)
+		if bid {
+			distance = distance.Neg()
+		}
+		offset := distance.Div(midPrice)
+		if band.IsPositive() && offset.GreaterThan(band) {
+			return false
+		}
+
+		quantity = quantity.Add(level.Quantity)
+		notional = notional.Add(level.Price.Mul(level.Quantity))
+		points = append(points, types.DepthPoint{
+			Price:    level.Price,
+			Offset:   offset,
+			Quantity: quantity,
+			Notional: notional,
+		})
+		return true
+	})
+	return points
+}
+
+// sampleCurve reads both curves at ascending offsets, taking the last point within each offset
+func sampleCurve(bids, asks []types.DepthPoint, offsets []decimal.Decimal) []types.BandLiquidity {
+	result := make([]types.BandLiquidity, len(offsets))
+	bid, ask := -1, -1
+	for i, offset := range offsets {
+		for bid+1 < len(bids) && bids[bid+1].Offset.LessThanOrEqual(offset) {
+			bid++
+		}
+		for ask+1 < len(asks) && asks[ask+1].Offset.LessThanOrEqual(offset) {
+			ask++
+		}
+
+		point := types.BandLiquidity{
+			Pct:         offset,
+			Bid:         decimal.Zero,
+			Ask:         decimal.Zero,
+			BidNotional: decimal.Zero,
+			AskNotional: decimal.Zero,
+		}
+		if bid >= 0 {
+			point.Bid, point.BidNotional = bids[bid].Quantity, bids[bid].Notional
+		}
+		if ask >= 0 {
+			point.Ask, point.AskNotional = asks[ask].Quantity, asks[ask].Notional
+		}
+		point.Delta = point.Bid.Sub(point.Ask)
+		result[i] = point
+	}
+	return result
+}
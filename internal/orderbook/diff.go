@@ -0,0 +1,88 @@
+package orderbook
+
+import (
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// LevelChange is the change at one price between two snapshots
+type LevelChange struct {
+	Price  decimal.Decimal
+	Before decimal.Decimal // Zero when the level was added
+	After  decimal.Decimal // Zero when the level was removed
+	Delta  decimal.Decimal // After - Before
+}
+
+// SideDiff holds the level changes of one side, each list best price first
+type SideDiff struct {
+	Added   []LevelChange
+	Removed []LevelChange
+	Changed []LevelChange   // Levels in both snapshots whose size moved
+	Placed  decimal.Decimal // Size added across new and grown levels
+	Pulled  decimal.Decimal // Size taken away across removed and shrunk levels, as a positive amount
+	NetFlow decimal.Decimal // Placed - Pulled; positive when liquidity was added
+}
+
+// BookDiff describes how the book moved from one snapshot to a later one
+type BookDiff struct {
+	Bids     SideDiff
+	Asks     SideDiff
+	Interval time.Duration // Time between the two snapshots
+}
+
+// Diff compares two snapshots of the same book, from a to b
+// Size that traded away and size that was cancelled both count as pulled, since
+// level data alone cannot tell them apart
+func Diff(a, b *BookSnapshot) BookDiff {
+	return BookDiff{
+		Bids:     diffSide(a.Bids, b.Bids, true),
+		Asks:     diffSide(a.Asks, b.Asks, false),
+		Interval: b.TakenAt.Sub(a.TakenAt),
+	}
+}
+
+// diffSide merges two sides sorted from the best price outwards
+func diffSide(before, after []types.PriceLevel, bid bool) SideDiff {
+	diff := SideDiff{Placed: decimal.Zero, Pulled: decimal.Zero}
+
+	// ahead reports whether price x sorts before price y on this side
+	ahead := func(x, y decimal.Decimal) bool {
+		if bid {
+			return x.GreaterThan(y)
+		}
+		return x.LessThan(y)
+	}
+
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case j == len(after) || (i < len(before) && ahead(before[i].Price, after[j].Price)):
+			level := before[i]
+			diff.Removed = append(diff.Removed, LevelChange{Price: level.Price, Before: level.Quantity, After: decimal.Zero, Delta: level.Quantity.Neg()})
+			diff.Pulled = diff.Pulled.Add(level.Quantity)
+			i++
+		case i == len(before) || ahead(after[j].Price, before[i].Price):
+			level := after[j]
+			diff.Added = append(diff.Added, LevelChange{Price: level.Price, Before: decimal.Zero, After: level.Quantity, Delta: level.Quantity})
+			diff.Placed = diff.Placed.Add(level.Quantity)
+			j++
+		default:
+			if delta := after[j].Quantity.Sub(before[i].Quantity); !delta.IsZero() {
+				diff.Changed = append(diff.Changed, LevelChange{Price: after[j].Price, Before: before[i].Quantity, After: after[j].Quantity, Delta: delta})
+				if delta.IsPositive() {
+					diff.Placed = diff.Placed.Add(delta)
+				} else {
+					diff.Pulled = diff.Pulled.Sub(delta)
+				}
+			}
+			i++
+			j++
+		}
+	}
+
+	diff.NetFlow = diff.Placed.Sub(diff.Pulled)
+	return diff
+}
@@ -0,0 +1,132 @@
+package orderbook
+
+import (
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// subscriberBuffer is the capacity of each subscription channel; events that do not fit are dropped
+const subscriberBuffer = 256
+
+// BookEventType identifies what changed in the book
+type BookEventType string
+
+const (
+	EventBestBid      BookEventType = "best_bid"      // The best bid price changed
+	EventBestAsk      BookEventType = "best_ask"      // The best ask price changed
+	EventLevelAdded   BookEventType = "level_added"   // A price level appeared
+	EventLevelRemoved BookEventType = "level_removed" // A price level was emptied
+	EventLargeSize    BookEventType = "large_size"    // A level reached the large size threshold
+)
+
+// BookEvent is a normalized change to the book
+// Side is types.Buy for bids and types.Sell for asks; for best price events
+// Price and Quantity describe the new best level and are zero when the side emptied
+type BookEvent struct {
+	Type     BookEventType
+	Side     types.Side
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+	UpdateID int64
+	Time     time.Time
+}
+
+// Subscribe returns a channel of book events; a slow reader loses events rather
+// than stalling updates, counted in Stats.EventsDropped. Release it with Unsubscribe
+func (ob *OrderBook) Subscribe() <-chan BookEvent {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ch := make(chan BookEvent, subscriberBuffer)
+	ob.subscribers = append(ob.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops delivery to a channel returned by Subscribe and closes it
+func (ob *OrderBook) Unsubscribe(events <-chan BookEvent) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	for i, ch := range ob.subscribers {
+		if ch == events {
+			ob.subscribers = append(ob.subscribers[:i], ob.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// SetLargeSize sets the level size, in base units, at which EventLargeSize is emitted; zero disables it
+func (ob *OrderBook) SetLargeSize(size decimal.Decimal) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.largeSize = size
+}
+
+// setLevel stores a level on one side and emits the level events it causes (must be called with mutex locked)
+func (ob *OrderBook) setLevel(side *bookSide, raw exchange.PriceLevel, price, qty decimal.Decimal) {
+	if len(ob.subscribers) == 0 {
+		side.SetRaw(raw, price, qty)
+		return
+	}
+
+	previous, existed := side.Get(price)
+	side.SetRaw(raw, price, qty)
+
+	bookSide := types.Sell
+	if side.descending {
+		bookSide = types.Buy
+	}
+
+	switch {
+	case !existed && qty.IsPositive():
+		ob.emit(EventLevelAdded, bookSide, price, qty)
+	case existed && qty.IsZero():
+		ob.emit(EventLevelRemoved, bookSide, price, qty)
+	}
+
+	if ob.largeSize.IsPositive() && qty.GreaterThanOrEqual(ob.largeSize) &&
+		(!existed || previous.Quantity.LessThan(ob.largeSize)) {
+		ob.emit(EventLargeSize, bookSide, price, qty)
+	}
+}
+
+// emitBestChanges emits best price events against the best prices before a change (must be called with mutex locked)
+func (ob *OrderBook) emitBestChanges(previousBid, previousAsk decimal.Decimal) {
+	if len(ob.subscribers) == 0 {
+		return
+	}
+
+	if !ob.bestBid.Equal(previousBid) {
+		best, _ := ob.bids.Best()
+		ob.emit(EventBestBid, types.Buy, best.Price, best.Quantity)
+	}
+	if !ob.bestAsk.Equal(previousAsk) {
+		best, _ := ob.asks.Best()
+		ob.emit(EventBestAsk, types.Sell, best.Price, best.Quantity)
+	}
+}
+
+// emit delivers an event to every subscriber without blocking (must be called with mutex locked)
+func (ob *OrderBook) emit(eventType BookEventType, side types.Side, price, qty decimal.Decimal) {
+	event := BookEvent{
+		Type:     eventType,
+		Side:     side,
+		Price:    price,
+		Quantity: qty,
+		UpdateID: ob.lastUpdateID,
+		Time:     time.Now(),
+	}
+
+	for _, ch := range ob.subscribers {
+		select {
+		case ch <- event:
+		default:
+			ob.stats.EventsDropped++
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package orderbook
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// SetFixedPoint keys and sums levels as int64 scaled by scale instead of decimals, so
+// updates are parsed without decimals and side totals are integer sums. Each changed
+// level still builds its quantity and band delta as decimals, and stats are computed
+// as decimals, so BenchmarkHandleDepthUpdate drops only from about 550 to 350
+// allocations per update. Levels with more decimals than the scale are rejected in
+// snapshots and skipped in updates. The book must be resynced afterwards
+func (ob *OrderBook) SetFixedPoint(scale types.FixedScale) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.scale = &scale
+	ob.bids = ob.newSide(true)
+	ob.asks = ob.newSide(false)
+	ob.updateStats()
+}
+
+// FixedScale returns the scale of a fixed-point book
+func (ob *OrderBook) FixedScale() (types.FixedScale, bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+	if ob.scale == nil {
+		return types.FixedScale{}, false
+	}
+	return *ob.scale, true
+}
+
+// newSide creates an empty side in the representation selected for the book
+func (ob *OrderBook) newSide(descending bool) *bookSide {
+	if ob.scale == nil {
+		return newBookSide(descending)
+	}
+	return newFixedSide(descending, *ob.scale)
+}
+
+// loadFixedLevels fills a fixed-point side from snapshot levels (must be called with mutex locked)
+func (ob *OrderBook) loadFixedLevels(side *bookSide, name string, levels []exchange.PriceLevel) error {
+	for _, level := range levels {
+		ticks, err := ob.scale.ParsePrice(level.Price)
+		if err != nil {
+			return fmt.Errorf("invalid %s price %s: %w", name, level.Price, err)
+		}
+		lots, err := ob.scale.ParseQty(level.Quantity)
+		if err != nil {
+			return fmt.Errorf("invalid %s quantity %s: %w", name, level.Quantity, err)
+		}
+		side.SetFixed(level, ticks, lots)
+	}
+	return nil
+}
+
+// applyFixedLevels applies update levels to a fixed-point side, parsing them straight to
+// ticks and lots unless subscribers need the decimal level (must be called with mutex locked)
+func (ob *OrderBook) applyFixedLevels(side *bookSide, levels []exchange.PriceLevel) {
+	for _, level := range levels {
+		ticks, err := ob.scale.ParsePrice(level.Price)
+		if err != nil {
+			continue
+		}
+		lots, _ := ob.scale.ParseQty(level.Quantity)
+		if len(ob.subscribers) == 0 {
+			side.SetFixed(level, ticks, lots)
+			continue
+		}
+		ob.setLevel(side, level, ob.scale.Price(ticks), ob.scale.Qty(lots))
+	}
+}
+
+// newFixedSide creates an empty side storing levels as ticks and lots of scale
+func newFixedSide(descending bool, scale types.FixedScale) *bookSide {
+	side := newBookSide(descending)
+	side.scale = &scale
+	return side
+}
+
+// ticksOf converts a price to ticks, rounding prices finer than the scale
+func (s *bookSide) ticksOf(price decimal.Decimal) int64 {
+	if ticks, ok := s.scale.PriceTicks(price); ok {
+		return ticks
+	}
+	return price.Shift(s.scale.PriceDecimals).Round(0).IntPart()
+}
+
+// lotsOf converts a quantity to lots, rounding quantities finer than the scale
+func (s *bookSide) lotsOf(qty decimal.Decimal) int64 {
+	if lots, ok := s.scale.QtyLots(qty); ok {
+		return lots
+	}
+	return qty.Shift(s.scale.QtyDecimals).Round(0).IntPart()
+}
+
+// SetFixed is SetRaw for a fixed-point side, taking the level as ticks and lots
+func (s *bookSide) SetFixed(raw exchange.PriceLevel, ticks, lots int64) {
+	key := levelKey{ticks: ticks}
+	if lots == 0 {
+		s.remove(key)
+		return
+	}
+
+	if node, ok := s.index[key]; ok {
+		node.raw = raw
+		delta := lots - node.lots
+		if delta == 0 {
+			return
+		}
+		s.totalLots += delta
+		s.notionalTicks.AddProduct(ticks, delta)
+		node.lots = lots
+		node.Quantity = s.scale.Qty(lots)
+		node.updates++
+		s.adjustBands(node.Price, s.scale.Qty(delta))
+		return
+	}
+
+	node := &levelNode{
+		PriceLevel: types.PriceLevel{Price: s.scale.Price(ticks), Quantity: s.scale.Qty(lots)},
+		raw:        raw,
+		created:    now(),
+		ticks:      ticks,
+		lots:       lots,
+	}
+	s.insert(key, node)
+	s.totalLots += lots
+	s.notionalTicks.AddProduct(ticks, lots)
+	s.adjustBands(node.Price, node.Quantity)
+}
+
+// wideInt is a signed 128-bit integer, enough for sums of ticks * lots that overflow int64
+type wideInt struct {
+	hi int64
+	lo uint64
+}
+
+// AddProduct adds a * b
+func (w *wideInt) AddProduct(a, b int64) {
+	negative := (a < 0) != (b < 0)
+	hi, lo := bits.Mul64(absUint(a), absUint(b))
+	if negative {
+		// Two's complement of the 128-bit product
+		lo = ^lo + 1
+		hi = ^hi
+		if lo == 0 {
+			hi++
+		}
+	}
+
+	var carry uint64
+	w.lo, carry = bits.Add64(w.lo, lo, 0)
+	w.hi += int64(hi) + int64(carry)
+}
+
+// Decimal returns the value scaled down by decimals
+func (w wideInt) Decimal(decimals int32) decimal.Decimal {
+	value := new(big.Int).Lsh(big.NewInt(w.hi), 64)
+	value.Add(value, new(big.Int).SetUint64(w.lo))
+	return decimal.NewFromBigInt(value, -decimals)
+}
+
+// absUint returns the magnitude of v, correct for math.MinInt64
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
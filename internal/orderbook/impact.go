@@ -0,0 +1,103 @@
+package orderbook
+
+import (
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceForSize walks the book as a market order of size (base units) would and returns
+// its volume-weighted price and slippage versus mid; Complete is false when the book is too thin
+func (ob *OrderBook) PriceForSize(side types.Side, size decimal.Decimal) types.Execution {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	remaining := size
+	return ob.execute(side, func(level types.PriceLevel) (decimal.Decimal, bool) {
+		fill := decimal.Min(level.Quantity, remaining)
+		remaining = remaining.Sub(fill)
+		return fill, remaining.IsPositive()
+	}, func() bool { return !remaining.IsPositive() })
+}
+
+// PriceForNotional walks the book as a market order spending notional (quote currency) would
+// and returns its volume-weighted price and slippage versus mid; Complete is false when the book is too thin
+func (ob *OrderBook) PriceForNotional(side types.Side, notional decimal.Decimal) types.Execution {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	remaining := notional
+	return ob.execute(side, func(level types.PriceLevel) (decimal.Decimal, bool) {
+		if value := level.Quantity.Mul(level.Price); value.LessThan(remaining) {
+			remaining = remaining.Sub(value)
+			return level.Quantity, true
+		}
+		fill := remaining.Div(level.Price)
+		remaining = decimal.Zero
+		return fill, false
+	}, func() bool { return !remaining.IsPositive() })
+}
+
+// SizeForPrice returns the size available before the price moves past limitPrice,
+// with the volume-weighted price and slippage versus mid of taking all of it;
+// Complete is false when the book ran out before reaching limitPrice
+func (ob *OrderBook) SizeForPrice(side types.Side, limitPrice decimal.Decimal) types.Execution {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	reached := false
+	return ob.execute(side, func(level types.PriceLevel) (decimal.Decimal, bool) {
+		if (side == types.Buy && level.Price.GreaterThan(limitPrice)) ||
+			(side == types.Sell && level.Price.LessThan(limitPrice)) {
+			reached = true
+			return decimal.Zero, false
+		}
+		return level.Quantity, true
+	}, func() bool { return reached })
+}
+
+// execute walks the side a market order on side consumes; take returns the size filled at
+// a level and whether to continue, complete reports whether the request was satisfied
+// (must be called with mutex locked)
+func (ob *OrderBook) execute(side types.Side, take func(level types.PriceLevel) (decimal.Decimal, bool), complete func() bool) types.Execution {
+	result := types.Execution{
+		Side:       side,
+		Size:       decimal.Zero,
+		Notional:   decimal.Zero,
+		VWAP:       decimal.Zero,
+		WorstPrice: decimal.Zero,
+		Slippage:   decimal.Zero,
+	}
+
+	book := ob.asks
+	if side == types.Sell {
+		book = ob.bids
+	}
+
+	book.Ascend(func(level types.PriceLevel) bool {
+		fill, more := take(level)
+		if fill.IsPositive() {
+			result.Size = result.Size.Add(fill)
+			result.Notional = result.Notional.Add(fill.Mul(level.Price))
+			result.WorstPrice = level.Price
+			result.Levels++
+		}
+		return more
+	})
+	result.Complete = complete()
+
+	if result.Size.IsZero() {
+		return result
+	}
+	result.VWAP = result.Notional.Div(result.Size)
+
+	if !ob.bestBid.IsZero() && !ob.bestAsk.IsZero() {
+		midPrice := ob.bestBid.Add(ob.bestAsk).Div(decimal.NewFromInt(2))
+		cost := result.VWAP.Sub(midPrice)
+		if side == types.Sell {
+			cost = cost.Neg()
+		}
+		result.Slippage = cost.Div(midPrice)
+	}
+	return result
+}
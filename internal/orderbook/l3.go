@@ -0,0 +1,196 @@
+package orderbook
+
+import (
+	"fmt"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// l3Order is a resting order tracked by an order-level book
+type l3Order struct {
+	id    string
+	bid   bool
+	price decimal.Decimal
+	qty   decimal.Decimal
+}
+
+// SetL3 switches the book to order-level mode, where snapshots and updates carry
+// individual orders and price levels are their aggregate. Takes effect from the next snapshot
+func (ob *OrderBook) SetL3(enabled bool) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.l3 = enabled
+}
+
+// IsL3 reports whether the book tracks individual orders
+func (ob *OrderBook) IsL3() bool {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+	return ob.l3
+}
+
+// QueuePosition returns where an order sits in the queue at its price level;
+// ok is false when the book is not order-level or the order is unknown
+func (ob *OrderBook) QueuePosition(orderID string) (position types.QueuePosition, ok bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	order, ok := ob.orders[orderID]
+	if !ok {
+		return types.QueuePosition{}, false
+	}
+
+	queue := ob.queues[queueKey(order.bid, order.price)]
+	position = types.QueuePosition{
+		Price:         order.price,
+		Quantity:      order.qty,
+		SizeAhead:     decimal.Zero,
+		OrdersAtLevel: len(queue),
+	}
+	for _, ahead := range queue {
+		if ahead == order {
+			break
+		}
+		position.Position++
+		position.SizeAhead = position.SizeAhead.Add(ahead.qty)
+	}
+	return position, true
+}
+
+// LevelOrders returns the orders resting at a price in queue order, front first;
+// Buy selects resting bids and Sell resting asks
+func (ob *OrderBook) LevelOrders(side types.Side, price decimal.Decimal) []exchange.Order {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	queue := ob.queues[queueKey(side == types.Buy, price)]
+	orders := make([]exchange.Order, len(queue))
+	for i, order := range queue {
+		orders[i] = exchange.Order{ID: order.id, Price: order.price.String(), Quantity: order.qty.String()}
+	}
+	return orders
+}
+
+// loadOrderSnapshot rebuilds the book from the orders of a snapshot (must be called with mutex locked)
+func (ob *OrderBook) loadOrderSnapshot(snapshot *exchange.Snapshot) error {
+	ob.bids = ob.newSide(true)
+	ob.asks = ob.newSide(false)
+	ob.orders = make(map[string]*l3Order, len(snapshot.BidOrders)+len(snapshot.AskOrders))
+	ob.queues = make(map[string][]*l3Order)
+
+	for _, order := range snapshot.BidOrders {
+		if err := ob.setOrder(true, order); err != nil {
+			return err
+		}
+	}
+	for _, order := range snapshot.AskOrders {
+		if err := ob.setOrder(false, order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOrders applies the order changes of an update, skipping malformed ones (must be called with mutex locked)
+func (ob *OrderBook) applyOrders(update *exchange.DepthUpdate) {
+	for _, order := range update.BidOrders {
+		ob.setOrder(true, order)
+	}
+	for _, order := range update.AskOrders {
+		ob.setOrder(false, order)
+	}
+}
+
+// setOrder adds, resizes or removes an order and its share of the level (must be called with mutex locked)
+// A size change at the same price keeps the order's place in the queue
+func (ob *OrderBook) setOrder(bid bool, raw exchange.Order) error {
+	price, err := decimal.NewFromString(raw.Price)
+	if err != nil {
+		return fmt.Errorf("invalid order price %s: %w", raw.Price, err)
+	}
+	qty, err := decimal.NewFromString(raw.Quantity)
+	if err != nil {
+		return fmt.Errorf("invalid order quantity %s: %w", raw.Quantity, err)
+	}
+
+	if existing, ok := ob.orders[raw.ID]; ok {
+		if qty.IsPositive() && existing.bid == bid && existing.price.Equal(price) {
+			ob.adjustLevel(bid, price, qty.Sub(existing.qty))
+			existing.qty = qty
+			return nil
+		}
+		ob.removeOrder(existing)
+	}
+
+	if !qty.IsPositive() {
+		return nil
+	}
+
+	order := &l3Order{id: raw.ID, bid: bid, price: price, qty: qty}
+	key := queueKey(bid, price)
+	ob.orders[raw.ID] = order
+	ob.queues[key] = append(ob.queues[key], order)
+	ob.adjustLevel(bid, price, qty)
+	return nil
+}
+
+// removeOrder drops an order from its queue and level (must be called with mutex locked)
+func (ob *OrderBook) removeOrder(order *l3Order) {
+	key := queueKey(order.bid, order.price)
+	queue := ob.queues[key]
+	for i, queued := range queue {
+		if queued == order {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(ob.queues, key)
+	} else {
+		ob.queues[key] = queue
+	}
+
+	delete(ob.orders, order.id)
+	ob.adjustLevel(order.bid, order.price, order.qty.Neg())
+}
+
+// adjustLevel changes the aggregate size at a price by delta (must be called with mutex locked)
+func (ob *OrderBook) adjustLevel(bid bool, price, delta decimal.Decimal) {
+	side := ob.asks
+	if bid {
+		side = ob.bids
+	}
+
+	total := delta
+	if level, ok := side.Get(price); ok {
+		total = level.Quantity.Add(delta)
+	}
+	ob.setLevel(side, exchange.PriceLevel{Price: price.String(), Quantity: total.String()}, price, total)
+}
+
+// dropLevelOrders forgets every order resting at a price (must be called with mutex locked)
+func (ob *OrderBook) dropLevelOrders(bid bool, price decimal.Decimal) {
+	key := queueKey(bid, price)
+	for _, order := range ob.queues[key] {
+		delete(ob.orders, order.id)
+	}
+	delete(ob.queues, key)
+}
+
+// updateOrderStats refreshes the queue statistics of an order-level book (must be called with mutex locked)
+func (ob *OrderBook) updateOrderStats() {
+	ob.stats.TotalOrders = len(ob.orders)
+	ob.stats.BestBidOrders = len(ob.queues[queueKey(true, ob.bestBid)])
+	ob.stats.BestAskOrders = len(ob.queues[queueKey(false, ob.bestAsk)])
+}
+
+// queueKey identifies the order queue of one side at one price
+func queueKey(bid bool, price decimal.Decimal) string {
+	if bid {
+		return "b" + price.String()
+	}
+	return "a" + price.String()
+}
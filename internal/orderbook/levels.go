@@ -0,0 +1,353 @@
+package orderbook
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+const maxSkipLevel = 24 // Enough for millions of price levels per side
+
+// now stamps level metadata; replaced in tests
+var now = time.Now
+
+// bookSide holds one side of the book as a skiplist ordered from the best price
+// outwards, with an index by price for O(1) lookups of existing levels
+// Quantity and notional totals are kept up to date on every change so they never need a scan
+// A side created with a fixed scale orders, indexes and totals levels as int64 ticks and lots,
+// see newFixedSide
+type bookSide struct {
+	descending bool // Bids sort high to low, asks low to high
+	head       *levelNode
+	level      int
+	index      map[levelKey]*levelNode
+	total      decimal.Decimal
+	notional   decimal.Decimal // Sum of price * quantity
+	bands      sideBands       // Liquidity within each band around mid, see BandSums
+	rng        *rand.Rand
+
+	scale         *types.FixedScale // Non-nil for fixed-point sides
+	totalLots     int64
+	notionalTicks wideInt // Sum of ticks * lots, scaled by both decimals
+}
+
+// levelKey indexes a level by its normalized price string, or by ticks on fixed-point sides
+type levelKey struct {
+	price string
+	ticks int64
+}
+
+// levelNode is a skiplist node holding one price level
+type levelNode struct {
+	types.PriceLevel
+	raw     exchange.PriceLevel // Strings as published, needed for venue checksums
+	created time.Time           // When the level appeared in this book
+	updates int                 // Size changes since it appeared
+	ticks   int64               // Fixed-point sides only
+	lots    int64
+	next    []*levelNode
+}
+
+// newBookSide creates an empty side; descending is used for bids
+func newBookSide(descending bool) *bookSide {
+	return &bookSide{
+		descending: descending,
+		head:       &levelNode{next: make([]*levelNode, maxSkipLevel)},
+		level:      1,
+		index:      make(map[levelKey]*levelNode),
+		total:      decimal.Zero,
+		notional:   decimal.Zero,
+		rng:        rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// before reports whether price a sorts ahead of price b on this side
+func (s *bookSide) before(a, b decimal.Decimal) bool {
+	if s.descending {
+		return a.GreaterThan(b)
+	}
+	return a.LessThan(b)
+}
+
+// nodeBefore is before for two levels, comparing ticks on fixed-point sides
+func (s *bookSide) nodeBefore(a, b *levelNode) bool {
+	if s.scale == nil {
+		return s.before(a.Price, b.Price)
+	}
+	if s.descending {
+		return a.ticks > b.ticks
+	}
+	return a.ticks < b.ticks
+}
+
+// key returns the index key of a price
+func (s *bookSide) key(price decimal.Decimal) levelKey {
+	if s.scale == nil {
+		return levelKey{price: price.String()}
+	}
+	return levelKey{ticks: s.ticksOf(price)}
+}
+
+// Len returns the number of price levels
+func (s *bookSide) Len() int {
+	return len(s.index)
+}
+
+// Total returns the summed quantity of all levels
+func (s *bookSide) Total() decimal.Decimal {
+	if s.scale != nil {
+		return s.scale.Qty(s.totalLots)
+	}
+	return s.total
+}
+
+// Notional returns the summed price * quantity of all levels, in quote currency
+func (s *bookSide) Notional() decimal.Decimal {
+	if s.scale != nil {
+		return s.notionalTicks.Decimal(s.scale.PriceDecimals + s.scale.QtyDecimals)
+	}
+	return s.notional
+}
+
+// Best returns the best level, if any
+func (s *bookSide) Best() (types.PriceLevel, bool) {
+	first := s.head.next[0]
+	if first == nil {
+		return types.PriceLevel{}, false
+	}
+	return first.PriceLevel, true
+}
+
+// Worst returns the level furthest from the best price, if any
+func (s *bookSide) Worst() (types.PriceLevel, bool) {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil {
+			x = x.next[i]
+		}
+	}
+	if x == s.head {
+		return types.PriceLevel{}, false
+	}
+	return x.PriceLevel, true
+}
+
+// Get returns the level at price, if present
+func (s *bookSide) Get(price decimal.Decimal) (types.PriceLevel, bool) {
+	node, ok := s.index[s.key(price)]
+	if !ok {
+		return types.PriceLevel{}, false
+	}
+	return node.PriceLevel, true
+}
+
+// Set stores the quantity at a price, removing the level when quantity is zero
+func (s *bookSide) Set(price, quantity decimal.Decimal) {
+	s.SetRaw(exchange.PriceLevel{Price: price.String(), Quantity: quantity.String()}, price, quantity)
+}
+
+// SetRaw is Set for a level parsed from raw, keeping the published strings
+func (s *bookSide) SetRaw(raw exchange.PriceLevel, price, quantity decimal.Decimal) {
+	if s.scale != nil {
+		s.SetFixed(raw, s.ticksOf(price), s.lotsOf(quantity))
+		return
+	}
+	if quantity.IsZero() {
+		s.Delete(price)
+		return
+	}
+
+	key := levelKey{price: price.String()}
+	if node, ok := s.index[key]; ok {
+		s.total = s.total.Sub(node.Quantity).Add(quantity)
+		s.adjustBands(price, quantity.Sub(node.Quantity))
+		s.notional = s.notional.Add(price.Mul(quantity.Sub(node.Quantity)))
+		if !quantity.Equal(node.Quantity) {
+			node.updates++
+		}
+		node.Quantity = quantity
+		node.raw = raw
+		return
+	}
+
+	s.insert(key, &levelNode{
+		PriceLevel: types.PriceLevel{Price: price, Quantity: quantity},
+		raw:        raw,
+		created:    now(),
+	})
+	s.total = s.total.Add(quantity)
+	s.notional = s.notional.Add(price.Mul(quantity))
+	s.adjustBands(price, quantity)
+}
+
+// insert links a new node into the skiplist and the index
+func (s *bookSide) insert(key levelKey, node *levelNode) {
+	var update [maxSkipLevel]*levelNode
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && s.nodeBefore(x.next[i], node) {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+
+	lvl := s.randomLevel()
+	if lvl > s.level {
+		for i := s.level; i < lvl; i++ {
+			update[i] = s.head
+		}
+		s.level = lvl
+	}
+
+	node.next = make([]*levelNode, lvl)
+	for i := 0; i < lvl; i++ {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+	}
+	s.index[key] = node
+}
+
+// Delete removes the level at price, reporting whether it existed
+func (s *bookSide) Delete(price decimal.Decimal) bool {
+	return s.remove(s.key(price))
+}
+
+// remove unlinks the level at key, reporting whether it existed
+func (s *bookSide) remove(key levelKey) bool {
+	node, ok := s.index[key]
+	if !ok {
+		return false
+	}
+
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && x.next[i] != node && s.nodeBefore(x.next[i], node) {
+			x = x.next[i]
+		}
+		if x.next[i] == node {
+			x.next[i] = node.next[i]
+		}
+	}
+
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+
+	delete(s.index, key)
+	if s.scale != nil {
+		s.totalLots -= node.lots
+		s.notionalTicks.AddProduct(node.ticks, -node.lots)
+	} else {
+		s.total = s.total.Sub(node.Quantity)
+		s.notional = s.notional.Sub(node.Price.Mul(node.Quantity))
+	}
+	s.adjustBands(node.Price, node.Quantity.Neg())
+	return true
+}
+
+// Ascend calls fn for each level from the best price outwards until fn returns false
+func (s *bookSide) Ascend(fn func(level types.PriceLevel) bool) {
+	for x := s.head.next[0]; x != nil; x = x.next[0] {
+		if !fn(x.PriceLevel) {
+			return
+		}
+	}
+}
+
+// ascendNodes is Ascend with access to the level metadata
+func (s *bookSide) ascendNodes(fn func(node *levelNode) bool) {
+	for x := s.head.next[0]; x != nil; x = x.next[0] {
+		if !fn(x) {
+			return
+		}
+	}
+}
+
+// ascendFrom is ascendNodes starting at the first level at or beyond price
+func (s *bookSide) ascendFrom(price decimal.Decimal, fn func(node *levelNode) bool) {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && s.before(x.next[i].Price, price) {
+			x = x.next[i]
+		}
+	}
+	for x = x.next[0]; x != nil; x = x.next[0] {
+		if !fn(x) {
+			return
+		}
+	}
+}
+
+// Top returns up to n levels from the best price outwards; n <= 0 returns every level
+func (s *bookSide) Top(n int) []types.PriceLevel {
+	size := s.Len()
+	if n > 0 && n < size {
+		size = n
+	}
+
+	levels := make([]types.PriceLevel, 0, size)
+	s.Ascend(func(level types.PriceLevel) bool {
+		levels = append(levels, level)
+		return len(levels) < size
+	})
+	return levels
+}
+
+// TopSums returns the cumulative size and notional of the best levels at each depth,
+// the whole side for depths past the last level; depths must be ascending
+// Fixed-point sides sum ticks and lots, converting only the results
+func (s *bookSide) TopSums(depths []int) []bandSum {
+	sums := make([]bandSum, len(depths))
+	size, notional := decimal.Zero, decimal.Zero
+	var lots int64
+	var ticks wideInt
+
+	next := 0
+	record := func(count int) {
+		for ; next < len(depths) && depths[next] <= count; next++ {
+			if s.scale != nil {
+				sums[next] = bandSum{size: s.scale.Qty(lots), notional: ticks.Decimal(s.scale.PriceDecimals + s.scale.QtyDecimals)}
+			} else {
+				sums[next] = bandSum{size: size, notional: notional}
+			}
+		}
+	}
+
+	count := 0
+	for x := s.head.next[0]; x != nil && next < len(depths); x = x.next[0] {
+		if s.scale != nil {
+			lots += x.lots
+			ticks.AddProduct(x.ticks, x.lots)
+		} else {
+			size = size.Add(x.Quantity)
+			notional = notional.Add(x.Price.Mul(x.Quantity))
+		}
+		count++
+		record(count)
+	}
+	record(math.MaxInt)
+	return sums
+}
+
+// TopRaw returns up to n levels from the best price outwards as published by the venue
+func (s *bookSide) TopRaw(n int) []exchange.PriceLevel {
+	levels := make([]exchange.PriceLevel, 0, n)
+	for x := s.head.next[0]; x != nil && len(levels) < n; x = x.next[0] {
+		levels = append(levels, x.raw)
+	}
+	return levels
+}
+
+// randomLevel picks a node height with a 1/4 chance of growing each level
+func (s *bookSide) randomLevel() int {
+	lvl := 1
+	for lvl < maxSkipLevel && s.rng.Int63()&3 == 0 {
+		lvl++
+	}
+	return lvl
+}
@@ -0,0 +1,153 @@
+package orderbook
+
+import (
+	"sort"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// ofiBucketInterval is the resolution OFI contributions are summed at, so busy books keep
+// a bounded number of buckets regardless of their update rate
+const ofiBucketInterval = time.Second
+
+// ofiBucket sums the OFI contributions of the updates within one interval
+type ofiBucket struct {
+	at      time.Time // Start of the interval
+	ofi     float64
+	updates int
+}
+
+// ofiWindow keeps the running sums of one trailing window over the shared buckets
+type ofiWindow struct {
+	window  time.Duration
+	first   int // Index of the oldest bucket in the window
+	ofi     float64
+	updates int
+}
+
+// ofiTracker accumulates order flow imbalance from the changes at the best bid and ask
+// between updates, with running sums per window so the summary is O(windows) per update
+type ofiTracker struct {
+	windows []*ofiWindow // Shortest first
+	buckets []ofiBucket  // Oldest first, covering the longest window
+
+	hasTop           bool // The previous top of book is set
+	bidPrice, bidQty float64
+	askPrice, askQty float64
+}
+
+// EnableOFI keeps the order flow imbalance at the top of book over each trailing window,
+// reported in Stats.OFI; no windows turn it off, and windows are counted in whole seconds
+func (ob *OrderBook) EnableOFI(windows []time.Duration) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.ofi = nil
+	ob.stats.OFI = nil
+	if len(windows) > 0 {
+		sorted := append([]time.Duration(nil), windows...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		ob.ofi = &ofiTracker{}
+		for _, window := range sorted {
+			window = max(window.Truncate(ofiBucketInterval), ofiBucketInterval)
+			ob.ofi.windows = append(ob.ofi.windows, &ofiWindow{window: window})
+		}
+		ob.sampleOFI(time.Now()) // A live book's top is where the flow starts
+	}
+	ob.publish()
+}
+
+// restart forgets the previous top of book, so the jump to a fresh snapshot is not counted
+// as order flow
+func (t *ofiTracker) restart() {
+	if t != nil {
+		t.hasTop = false
+	}
+}
+
+// sampleOFI adds the contribution of the latest update to the windows (must be called with mutex locked)
+// Following Cont, Kukanov and Stoikov, a higher or unchanged bid adds its size, a lower or
+// unchanged bid takes away the previous size, and the ask side counts the other way round
+func (ob *OrderBook) sampleOFI(now time.Time) {
+	t := ob.ofi
+	if t == nil {
+		return
+	}
+	bid, okBid := ob.bids.Best()
+	ask, okAsk := ob.asks.Best()
+	if !okBid || !okAsk || !ask.Price.GreaterThan(bid.Price) {
+		t.hasTop = false // Empty or crossed, the next update starts over
+		return
+	}
+
+	bidPrice, bidQty := bid.Price.InexactFloat64(), bid.Quantity.InexactFloat64()
+	askPrice, askQty := ask.Price.InexactFloat64(), ask.Quantity.InexactFloat64()
+	if !t.hasTop {
+		t.hasTop = true
+		t.bidPrice, t.bidQty, t.askPrice, t.askQty = bidPrice, bidQty, askPrice, askQty
+		return
+	}
+
+	e := 0.0
+	if bidPrice >= t.bidPrice {
+		e += bidQty
+	}
+	if bidPrice <= t.bidPrice {
+		e -= t.bidQty
+	}
+	if askPrice <= t.askPrice {
+		e -= askQty
+	}
+	if askPrice >= t.askPrice {
+		e += t.askQty
+	}
+	t.bidPrice, t.bidQty, t.askPrice, t.askQty = bidPrice, bidQty, askPrice, askQty
+
+	t.add(now, e)
+	ob.stats.OFI = t.summary()
+}
+
+// add records one contribution and drops the buckets that fell out of each window
+func (t *ofiTracker) add(now time.Time, e float64) {
+	at := now.Truncate(ofiBucketInterval)
+	if n := len(t.buckets); n > 0 && t.buckets[n-1].at.Equal(at) {
+		t.buckets[n-1].ofi += e
+		t.buckets[n-1].updates++
+	} else {
+		t.buckets = append(t.buckets, ofiBucket{at: at, ofi: e, updates: 1})
+	}
+	for _, w := range t.windows {
+		w.ofi += e
+		w.updates++
+		cutoff := at.Add(-w.window)
+		for w.first < len(t.buckets) && !t.buckets[w.first].at.After(cutoff) {
+			w.ofi -= t.buckets[w.first].ofi
+			w.updates -= t.buckets[w.first].updates
+			w.first++
+		}
+	}
+
+	// The longest window holds the oldest bucket still needed
+	if drop := t.windows[len(t.windows)-1].first; drop > 0 {
+		t.buckets = append(t.buckets[:0], t.buckets[drop:]...)
+		for _, w := range t.windows {
+			w.first -= drop
+		}
+	}
+}
+
+// summary converts the running sums into the reported windows
+func (t *ofiTracker) summary() []types.OFIWindow {
+	windows := make([]types.OFIWindow, len(t.windows))
+	for i, w := range t.windows {
+		ofi := w.ofi
+		if w.updates == 0 {
+			ofi = 0 // Drop the rounding left in an emptied window
+		}
+		windows[i] = types.OFIWindow{Window: w.window, OFI: decimal.NewFromFloat(ofi), Updates: w.updates}
+	}
+	return windows
+}
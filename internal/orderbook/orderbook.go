@@ -2,13 +2,14 @@ package orderbook
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"orderbook/internal/exchange"
-	"orderbook/internal/types"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
 
 	"github.com/shopspring/decimal"
 )
@@ -16,13 +17,39 @@ import (
 // OrderBook manages the real-time order book state
 type OrderBook struct {
 	mu           sync.RWMutex
-	bids         map[string]types.PriceLevel
-	asks         map[string]types.PriceLevel
+	bids         *bookSide
+	asks         *bookSide
 	lastUpdateID int64
 	eventBuffer  []*exchange.DepthUpdate
 	initialized  bool
 	stats        types.Stats
 	currentTick  types.TickLevel
+	checksum     *Checksum
+	gapPolicy    types.GapPolicy
+	crossPolicy  types.CrossPolicy
+	crossed      bool                         // The book was crossed after the last update
+	bands        []decimal.Decimal            // Configured liquidity bands, ascending
+	bandWalk     []decimal.Decimal            // Configured and default bands, ascending, as computed per update
+	bandIndex    map[string]int               // Position of each band in bandWalk
+	depths       []int                        // Top-N level counts for imbalance, ascending
+	l3           bool                         // Levels are aggregated from individual orders
+	scale        *types.FixedScale            // Levels are stored as scaled int64, nil for decimals
+	orders       map[string]*l3Order          // Resting orders by ID, L3 only
+	queues       map[string][]*l3Order        // Orders per side and price in time priority, L3 only
+	resyncNeeded bool                         // Set when the local book is known to be wrong
+	resync       chan struct{}                // Signals that a resync was requested
+	subscribers  []chan BookEvent             // Receivers of book events
+	largeSize    decimal.Decimal              // Level size that triggers EventLargeSize, zero when off
+	maxDepth     int                          // Levels kept per side, 0 keeps all
+	maxBand      decimal.Decimal              // Distance from mid beyond which levels are dropped, zero keeps all
+	staleAfter   time.Duration                // Time without updates that triggers a resync, 0 disables it
+	rolling      *rollingWindow               // Trailing top-of-book samples, nil when disabled
+	ofi          *ofiTracker                  // Order flow imbalance at the top of book, nil when disabled
+	touch        []*TouchTracker              // Readers of the size at the best bid and ask
+	version      atomic.Uint64                // Bumped on every change, under the write lock
+	published    atomic.Pointer[BookSnapshot] // Latest snapshot handed to readers
+	view         atomic.Pointer[bookView]     // Latest stats and top of book for lock-free getters
+	logger       atomic.Pointer[slog.Logger]  // Receives book events, the slog default when unset
 	// Cached best bid/ask for performance
 	bestBid   decimal.Decimal
 	bestAsk   decimal.Decimal
@@ -32,17 +59,24 @@ type OrderBook struct {
 
 // New creates a new OrderBook instance
 func New() *OrderBook {
-	return &OrderBook{
-		bids:        make(map[string]types.PriceLevel),
-		asks:        make(map[string]types.PriceLevel),
+	ob := &OrderBook{
+		bids:        newBookSide(true),
+		asks:        newBookSide(false),
 		eventBuffer: make([]*exchange.DepthUpdate, 0),
+		resync:      make(chan struct{}, 1),
 		currentTick: types.Tick1, // Default to 1.0 tick size
+		gapPolicy:   types.GapBuffer,
+		crossPolicy: types.CrossLog,
 		bestBid:     decimal.Zero,
 		bestAsk:     decimal.Zero,
 		stats: types.Stats{
 			ConnectionTime: time.Now(),
 		},
 	}
+	ob.setBands(types.DefaultLiquidityBands)
+	ob.depths = types.DefaultImbalanceDepths
+	ob.publish()
+	return ob
 }
 
 // LoadSnapshot initializes the orderbook with a snapshot from the exchange
@@ -50,49 +84,58 @@ func (ob *OrderBook) LoadSnapshot(snapshot *exchange.Snapshot) error {
 	ob.mu.Lock()
 	defer ob.mu.Unlock()
 
+	if ob.l3 {
+		if err := ob.loadOrderSnapshot(snapshot); err != nil {
+			return err
+		}
+		ob.lastUpdateID = snapshot.LastUpdateID
+		ob.resyncNeeded = false
+		ob.stats.LastUpdateTime = time.Now()
+		ob.truncate()
+		ob.ofi.restart()
+		ob.updateStats()
+		return nil
+	}
+
+	bids := ob.newSide(true)
+	asks := ob.newSide(false)
+
+	if err := ob.loadLevels(bids, "bid", snapshot.Bids); err != nil {
+		return err
+	}
+	if err := ob.loadLevels(asks, "ask", snapshot.Asks); err != nil {
+		return err
+	}
+
 	ob.lastUpdateID = snapshot.LastUpdateID
-	ob.bids = make(map[string]types.PriceLevel)
-	ob.asks = make(map[string]types.PriceLevel)
-	ob.bestBid = decimal.Zero
-	ob.bestAsk = decimal.NewFromFloat(999999999)
+	ob.bids = bids
+	ob.asks = asks
+	ob.stats.LastUpdateTime = time.Now()
+	ob.truncate()
+	ob.resyncNeeded = false
+	ob.ofi.restart()
 
-	for _, bid := range snapshot.Bids {
-		price, err := decimal.NewFromString(bid.Price)
-		if err != nil {
-			return fmt.Errorf("invalid bid price %s: %w", bid.Price, err)
-		}
-		qty, err := decimal.NewFromString(bid.Quantity)
-		if err != nil {
-			return fmt.Errorf("invalid bid quantity %s: %w", bid.Quantity, err)
-		}
-		if !qty.IsZero() {
-			ob.bids[bid.Price] = types.PriceLevel{Price: price, Quantity: qty}
-			// Update best bid
-			if price.GreaterThan(ob.bestBid) {
-				ob.bestBid = price
-			}
-		}
+	ob.updateStats()
+	return nil
+}
+
+// loadLevels fills a fresh side from snapshot levels (must be called with mutex locked)
+func (ob *OrderBook) loadLevels(side *bookSide, name string, levels []exchange.PriceLevel) error {
+	if ob.scale != nil {
+		return ob.loadFixedLevels(side, name, levels)
 	}
 
-	for _, ask := range snapshot.Asks {
-		price, err := decimal.NewFromString(ask.Price)
+	for _, level := range levels {
+		price, err := decimal.NewFromString(level.Price)
 		if err != nil {
-			return fmt.Errorf("invalid ask price %s: %w", ask.Price, err)
+			return fmt.Errorf("invalid %s price %s: %w", name, level.Price, err)
 		}
-		qty, err := decimal.NewFromString(ask.Quantity)
+		qty, err := decimal.NewFromString(level.Quantity)
 		if err != nil {
-			return fmt.Errorf("invalid ask quantity %s: %w", ask.Quantity, err)
-		}
-		if !qty.IsZero() {
-			ob.asks[ask.Price] = types.PriceLevel{Price: price, Quantity: qty}
-			// Update best ask
-			if price.LessThan(ob.bestAsk) {
-				ob.bestAsk = price
-			}
+			return fmt.Errorf("invalid %s quantity %s: %w", name, level.Quantity, err)
 		}
+		side.SetRaw(level, price, qty)
 	}
-
-	ob.updateStats()
 	return nil
 }
 
@@ -103,25 +146,54 @@ func (ob *OrderBook) HandleDepthUpdate(update *exchange.DepthUpdate) {
 
 	if !ob.initialized {
 		ob.eventBuffer = append(ob.eventBuffer, update)
+		ob.publish()
 		return
 	}
 
 	expectedPrevID := ob.lastUpdateID
 	if update.PrevUpdateID != expectedPrevID {
 		if update.FirstUpdateID <= expectedPrevID+1 && update.FinalUpdateID > expectedPrevID {
-			//log.Printf("Accepting overlapping event: U=%d, u=%d, expected_pu=%d, got_pu=%d", update.FirstUpdateID, update.FinalUpdateID, expectedPrevID, update.PrevUpdateID)
+			ob.log().Debug("Accepting overlapping event", "event", "overlap",
+				"first_update_id", update.FirstUpdateID, "final_update_id", update.FinalUpdateID,
+				"expected_prev_update_id", expectedPrevID, "prev_update_id", update.PrevUpdateID)
 			ob.applyUpdate(update)
 			return
 		}
 
-		//log.Printf("Sequence gap: expected pu=%d, got pu=%d. Buffering event...", expectedPrevID, update.PrevUpdateID)
-		ob.eventBuffer = append(ob.eventBuffer, update)
+		ob.handleGap(update, expectedPrevID)
 		return
 	}
 
 	ob.applyUpdate(update)
 }
 
+// handleGap applies the gap policy to an update that does not follow on from the book (must be called with mutex locked)
+func (ob *OrderBook) handleGap(update *exchange.DepthUpdate, expectedPrevID int64) {
+	ob.stats.SequenceGaps++
+	defer ob.publish()
+
+	gap := func(action string) {
+		ob.log().Warn("Sequence gap", "event", "sequence_gap", "action", action,
+			"expected_prev_update_id", expectedPrevID, "prev_update_id", update.PrevUpdateID,
+			"first_update_id", update.FirstUpdateID, "final_update_id", update.FinalUpdateID)
+	}
+	switch ob.gapPolicy {
+	case types.GapLog:
+		gap("apply")
+		ob.applyUpdate(update)
+	case types.GapDrop:
+		gap("drop")
+	case types.GapResync:
+		ob.eventBuffer = append(ob.eventBuffer, update)
+		if !ob.resyncNeeded {
+			gap("resync")
+			ob.requestResync()
+		}
+	default:
+		ob.eventBuffer = append(ob.eventBuffer, update)
+	}
+}
+
 // ProcessBufferedEvents processes any buffered events after snapshot load
 func (ob *OrderBook) ProcessBufferedEvents() {
 	ob.mu.Lock()
@@ -131,22 +203,24 @@ func (ob *OrderBook) ProcessBufferedEvents() {
 
 	for _, event := range ob.eventBuffer {
 		if event.FinalUpdateID <= ob.lastUpdateID {
-			log.Printf("Discarding old buffered event: u=%d <= lastUpdateId=%d",
-				event.FinalUpdateID, ob.lastUpdateID)
+			ob.log().Debug("Discarding old buffered event", "event", "buffer_discard",
+				"final_update_id", event.FinalUpdateID, "last_update_id", ob.lastUpdateID)
 			continue
 		}
 
 		if event.FirstUpdateID <= ob.lastUpdateID+1 && event.FinalUpdateID > ob.lastUpdateID {
 			validEvents = append(validEvents, event)
-			log.Printf("Found valid buffered event: U=%d, u=%d, lastUpdateId=%d",
-				event.FirstUpdateID, event.FinalUpdateID, ob.lastUpdateID)
+			ob.log().Debug("Found valid buffered event", "event", "buffer_replay",
+				"first_update_id", event.FirstUpdateID, "final_update_id", event.FinalUpdateID, "last_update_id", ob.lastUpdateID)
 		}
 	}
 
 	if len(validEvents) == 0 {
-		log.Printf("No valid events found in buffer, dropping all and starting fresh")
+		ob.log().Info("No valid events found in buffer, dropping all and starting fresh", "event", "initialized",
+			"last_update_id", ob.lastUpdateID, "buffered", 0)
 		ob.eventBuffer = nil
 		ob.initialized = true
+		ob.publish()
 		return
 	}
 
@@ -163,38 +237,132 @@ func (ob *OrderBook) ProcessBufferedEvents() {
 	}
 
 	ob.initialized = true
-	log.Printf("Orderbook initialized with %d valid events", len(validEvents))
+	ob.publish()
+	ob.log().Info("Orderbook initialized from buffered events", "event", "initialized",
+		"last_update_id", ob.lastUpdateID, "buffered", len(validEvents))
+}
+
+// SetLogger sets the logger of book events, typically carrying the exchange and symbol of
+// the book; the slog default is used until then
+func (ob *OrderBook) SetLogger(logger *slog.Logger) {
+	ob.logger.Store(logger)
+}
+
+// log returns the logger of book events
+func (ob *OrderBook) log() *slog.Logger {
+	if logger := ob.logger.Load(); logger != nil {
+		return logger
+	}
+	return slog.Default()
 }
 
 // CheckAndReinitialize checks if the orderbook needs reinitialization
 func (ob *OrderBook) CheckAndReinitialize(getSnapshot func() (*exchange.Snapshot, error)) {
 	ob.mu.RLock()
-	shouldReinit := len(ob.eventBuffer) > 100
+	resyncNeeded := ob.resyncNeeded
 	bufferLen := len(ob.eventBuffer)
 	initialized := ob.initialized
+	staleness := time.Since(ob.stats.LastUpdateTime)
+	stale := ob.staleAfter > 0 && initialized && staleness > ob.staleAfter
+	shouldReinit := bufferLen > 100 || resyncNeeded || stale
 	ob.mu.RUnlock()
 
 	if shouldReinit {
-		log.Printf("Reinitializing due to buffer accumulation: %d events", bufferLen)
+		if resyncNeeded {
+			ob.log().Warn("Reinitializing due to resync request", "event", "resync", "reason", "requested")
+		} else if stale {
+			ob.log().Warn("Reinitializing due to staleness", "event", "resync", "reason", "stale", "staleness", staleness.Round(time.Second))
+		} else {
+			ob.log().Warn("Reinitializing due to buffer accumulation", "event", "resync", "reason", "buffer", "buffered", bufferLen)
+		}
 		ob.mu.Lock()
 		ob.initialized = false
+		ob.stats.Resyncs++
+		ob.publish()
 		ob.mu.Unlock()
 
 		snapshot, err := getSnapshot()
 		if err != nil {
-			log.Printf("Failed to reinitialize: %v", err)
+			ob.log().Error("Failed to reinitialize", "event", "resync_failed", "error", err)
 			return
 		}
 
 		if err := ob.LoadSnapshot(snapshot); err != nil {
-			log.Printf("Failed to load snapshot during reinitialize: %v", err)
+			ob.log().Error("Failed to load snapshot during reinitialize", "event", "resync_failed", "error", err)
 			return
 		}
 
 		ob.ProcessBufferedEvents()
 	} else if initialized && bufferLen > 0 && bufferLen%10 == 0 {
-		log.Printf("Buffer status: %d events pending", bufferLen)
+		ob.log().Info("Buffer status", "event", "buffer_status", "buffered", bufferLen)
+	}
+}
+
+// SetChecksum enables verification of venue checksums carried by depth updates
+func (ob *OrderBook) SetChecksum(checksum *Checksum) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.checksum = checksum
+}
+
+// SetLiquidityBands selects the bands reported in Stats.Bands as fractions of mid
+// (0.001 = 0.1%); an empty list restores types.DefaultLiquidityBands
+func (ob *OrderBook) SetLiquidityBands(bands []decimal.Decimal) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.setBands(bands)
+	ob.updateStats()
+}
+
+// setBands stores the configured bands and the combined list walked per update (must be called with mutex locked)
+func (ob *OrderBook) setBands(bands []decimal.Decimal) {
+	ob.bands = types.NormalizeBands(bands)
+	ob.bandWalk = types.NormalizeBands(append(append([]decimal.Decimal(nil), ob.bands...), types.DefaultLiquidityBands...))
+	ob.bandIndex = make(map[string]int, len(ob.bandWalk))
+	for i, pct := range ob.bandWalk {
+		ob.bandIndex[pct.String()] = i
+	}
+}
+
+// SetImbalanceDepths selects the top-N level counts reported in Stats.Imbalances;
+// an empty list restores types.DefaultImbalanceDepths
+func (ob *OrderBook) SetImbalanceDepths(depths []int) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if len(depths) == 0 {
+		depths = types.DefaultImbalanceDepths
+	}
+	ob.depths = append([]int(nil), depths...)
+	sort.Ints(ob.depths)
+	ob.updateStats()
+}
+
+// LiquidityWithin returns the bid and ask size resting within pct of mid (0.02 = 2%)
+func (ob *OrderBook) LiquidityWithin(pct decimal.Decimal) (bids, asks decimal.Decimal) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	if ob.bestBid.IsZero() || ob.bestAsk.IsZero() {
+		return decimal.Zero, decimal.Zero
 	}
+
+	midPrice := ob.bestBid.Add(ob.bestAsk).Div(decimal.NewFromInt(2))
+	band := []decimal.Decimal{pct}
+	return bandLiquidity(ob.bids, midPrice, band, true)[0].size, bandLiquidity(ob.asks, midPrice, band, false)[0].size
+}
+
+// SetGapPolicy selects how sequence gaps are handled; the default is types.GapBuffer
+func (ob *OrderBook) SetGapPolicy(policy types.GapPolicy) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.gapPolicy = policy
+}
+
+// ResyncRequests returns a channel signalled when the book needs a fresh snapshot,
+// so callers can run CheckAndReinitialize without waiting for their next check
+func (ob *OrderBook) ResyncRequests() <-chan struct{} {
+	return ob.resync
 }
 
 // SetTickLevel changes the current tick level for price aggregation
@@ -211,145 +379,278 @@ func (ob *OrderBook) GetTickLevel() types.TickLevel {
 	return ob.currentTick
 }
 
-// GetBids returns a copy of the current bid levels
+// GetBids returns a copy of the current bid levels keyed by price as published
+// Kept for callers that predate ordered access; prefer GetBidLevels
 func (ob *OrderBook) GetBids() map[string]types.PriceLevel {
 	ob.mu.RLock()
 	defer ob.mu.RUnlock()
-
-	bids := make(map[string]types.PriceLevel)
-	for k, v := range ob.bids {
-		bids[k] = v
-	}
-	return bids
+	return levelsByPrice(ob.bids)
 }
 
-// GetAsks returns a copy of the current ask levels
+// GetAsks returns a copy of the current ask levels keyed by price as published
+// Kept for callers that predate ordered access; prefer GetAskLevels
 func (ob *OrderBook) GetAsks() map[string]types.PriceLevel {
 	ob.mu.RLock()
 	defer ob.mu.RUnlock()
+	return levelsByPrice(ob.asks)
+}
 
-	asks := make(map[string]types.PriceLevel)
-	for k, v := range ob.asks {
-		asks[k] = v
-	}
-	return asks
+// GetBidLevels returns up to depth bid levels, best (highest) price first
+// A depth of 0 returns every level
+func (ob *OrderBook) GetBidLevels(depth int) []types.PriceLevel {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+	return ob.bids.Top(depth)
 }
 
-// GetStats returns a copy of the current statistics
-func (ob *OrderBook) GetStats() types.Stats {
+// GetAskLevels returns up to depth ask levels, best (lowest) price first
+// A depth of 0 returns every level
+func (ob *OrderBook) GetAskLevels(depth int) []types.PriceLevel {
 	ob.mu.RLock()
 	defer ob.mu.RUnlock()
-	return ob.stats
+	return ob.asks.Top(depth)
+}
+
+// BestBid returns the highest bid level, if the book has bids
+func (ob *OrderBook) BestBid() (types.PriceLevel, bool) {
+	view := ob.view.Load()
+	return view.bestBid, view.hasBid
+}
+
+// BestAsk returns the lowest ask level, if the book has asks
+func (ob *OrderBook) BestAsk() (types.PriceLevel, bool) {
+	view := ob.view.Load()
+	return view.bestAsk, view.hasAsk
+}
+
+// GetStats returns a copy of the current statistics without waiting for updates in progress
+func (ob *OrderBook) GetStats() types.Stats {
+	stats := ob.view.Load().stats
+	stats.Staleness = staleness(stats.LastUpdateTime)
+	return stats
+}
+
+// SetStaleAfter makes CheckAndReinitialize resync a book that has not changed for d; 0 disables it
+func (ob *OrderBook) SetStaleAfter(d time.Duration) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.staleAfter = d
+}
+
+// staleness returns the time since the book last changed, or zero before it ever has
+func staleness(lastUpdate time.Time) time.Duration {
+	if lastUpdate.IsZero() {
+		return 0
+	}
+	return time.Since(lastUpdate)
 }
 
 // IsInitialized returns whether the orderbook is initialized
 func (ob *OrderBook) IsInitialized() bool {
-	ob.mu.RLock()
-	defer ob.mu.RUnlock()
-	return ob.initialized
+	return ob.view.Load().initialized
 }
 
 // GetBufferLength returns the current buffer length
 func (ob *OrderBook) GetBufferLength() int {
+	return ob.view.Load().stats.BufferedEvents
+}
+
+// CoversDepth reports whether both sides of the book reach at least pct (0.02 = 2%)
+// away from the mid price. Depth-limited feeds only carry the top levels, so
+// liquidity bands beyond their deepest level would be understated
+func (ob *OrderBook) CoversDepth(pct float64) bool {
 	ob.mu.RLock()
 	defer ob.mu.RUnlock()
-	return len(ob.eventBuffer)
+
+	if ob.bestBid.IsZero() || ob.bestAsk.IsZero() {
+		return false
+	}
+
+	midPrice := ob.bestBid.Add(ob.bestAsk).Div(decimal.NewFromInt(2))
+	threshold := midPrice.Mul(decimal.NewFromFloat(pct))
+	minBid := midPrice.Sub(threshold)
+	maxAsk := midPrice.Add(threshold)
+
+	bidCovered := false
+	ob.bids.Ascend(func(level types.PriceLevel) bool {
+		bidCovered = level.Price.LessThanOrEqual(minBid)
+		return !bidCovered
+	})
+
+	askCovered := false
+	ob.asks.Ascend(func(level types.PriceLevel) bool {
+		askCovered = level.Price.GreaterThanOrEqual(maxAsk)
+		return !askCovered
+	})
+
+	return bidCovered && askCovered
 }
 
 // applyUpdate applies a depth update to the orderbook (must be called with mutex locked)
 func (ob *OrderBook) applyUpdate(update *exchange.DepthUpdate) {
-	bestBidChanged := false
-	bestAskChanged := false
+	ob.lastUpdateID = update.FinalUpdateID
+
+	if ob.l3 {
+		ob.applyOrders(update)
+	}
+
+	if ob.scale != nil {
+		ob.applyFixedLevels(ob.bids, update.Bids)
+		ob.applyFixedLevels(ob.asks, update.Asks)
+		ob.finishUpdate(update)
+		return
+	}
 
 	for _, bid := range update.Bids {
-		price := bid.Price
-		qty, _ := decimal.NewFromString(bid.Quantity)
-		priceDecimal, _ := decimal.NewFromString(price)
-
-		if qty.IsZero() {
-			// Remove bid level
-			if _, exists := ob.bids[price]; exists {
-				delete(ob.bids, price)
-				// Check if this was the best bid
-				if priceDecimal.Equal(ob.bestBid) {
-					bestBidChanged = true
-				}
-			}
-		} else {
-			// Add/update bid level
-			ob.bids[price] = types.PriceLevel{Price: priceDecimal, Quantity: qty}
-			// Check if this is a new best bid
-			if priceDecimal.GreaterThan(ob.bestBid) {
-				ob.bestBid = priceDecimal
-			}
+		price, err := decimal.NewFromString(bid.Price)
+		if err != nil {
+			continue
 		}
+		qty, _ := decimal.NewFromString(bid.Quantity)
+		ob.setLevel(ob.bids, bid, price, qty)
 	}
 
 	for _, ask := range update.Asks {
-		price := ask.Price
+		price, err := decimal.NewFromString(ask.Price)
+		if err != nil {
+			continue
+		}
 		qty, _ := decimal.NewFromString(ask.Quantity)
-		priceDecimal, _ := decimal.NewFromString(price)
-
-		if qty.IsZero() {
-			// Remove ask level
-			if _, exists := ob.asks[price]; exists {
-				delete(ob.asks, price)
-				// Check if this was the best ask
-				if priceDecimal.Equal(ob.bestAsk) {
-					bestAskChanged = true
-				}
+		ob.setLevel(ob.asks, ask, price, qty)
+	}
+
+	ob.finishUpdate(update)
+}
+
+// finishUpdate counts an applied update and runs the integrity checks (must be called with mutex locked)
+func (ob *OrderBook) finishUpdate(update *exchange.DepthUpdate) {
+	ob.stats.EventsProcessed++
+	ob.stats.LastEventTime = update.EventTime
+	ob.stats.LastUpdateTime = time.Now()
+	ob.verifyChecksum(update)
+	ob.checkCrossed(update)
+	ob.truncate()
+	ob.updateStats()
+}
+
+// verifyChecksum compares the venue checksum of an update with the local book and
+// requests a resync on mismatch (must be called with mutex locked)
+func (ob *OrderBook) verifyChecksum(update *exchange.DepthUpdate) {
+	if ob.checksum == nil || update.Checksum == nil || ob.resyncNeeded {
+		return
+	}
+
+	depth := ob.checksum.Depth
+	got := ob.checksum.Compute(ob.bids.TopRaw(depth), ob.asks.TopRaw(depth))
+	if got == *update.Checksum {
+		return
+	}
+
+	ob.stats.ChecksumFailures++
+	ob.log().Warn("Checksum mismatch, requesting resync", "event", "checksum_mismatch",
+		"final_update_id", update.FinalUpdateID, "expected", *update.Checksum, "computed", got)
+	ob.requestResync()
+}
+
+// SetCrossPolicy selects how crossed or locked books are handled; the default is types.CrossLog
+func (ob *OrderBook) SetCrossPolicy(policy types.CrossPolicy) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.crossPolicy = policy
+}
+
+// checkCrossed counts an update that leaves the best bid at or above the best ask and applies
+// the cross policy; a crossed book is only logged as it becomes crossed (must be called with mutex locked)
+func (ob *OrderBook) checkCrossed(update *exchange.DepthUpdate) {
+	bestBid, hasBid := ob.bids.Best()
+	bestAsk, hasAsk := ob.asks.Best()
+	if !hasBid || !hasAsk || bestBid.Price.LessThan(bestAsk.Price) {
+		ob.crossed = false
+		return
+	}
+
+	ob.stats.CrossedCount++
+	if !ob.crossed {
+		ob.log().Warn("Crossed book", "event", "crossed_book", "final_update_id", update.FinalUpdateID,
+			"best_bid", bestBid.Price, "best_ask", bestAsk.Price, "policy", ob.crossPolicy)
+	}
+	ob.crossed = true
+
+	switch ob.crossPolicy {
+	case types.CrossTrim:
+		ob.trimCrossed(update)
+		ob.crossed = false
+	case types.CrossResync:
+		if !ob.resyncNeeded {
+			ob.requestResync()
+		}
+	}
+}
+
+// trimCrossed drops levels until the book is uncrossed, keeping the side this update
+// just set: a fresh bid at the best ask means the ask was missed being deleted (must be called with mutex locked)
+func (ob *OrderBook) trimCrossed(update *exchange.DepthUpdate) {
+	for {
+		bestBid, hasBid := ob.bids.Best()
+		bestAsk, hasAsk := ob.asks.Best()
+		if !hasBid || !hasAsk || bestBid.Price.LessThan(bestAsk.Price) {
+			return
+		}
+
+		if touchesBid(update, bestBid.Price) {
+			ob.asks.Delete(bestAsk.Price)
+			if ob.l3 {
+				ob.dropLevelOrders(false, bestAsk.Price)
 			}
 		} else {
-			// Add/update ask level
-			ob.asks[price] = types.PriceLevel{Price: priceDecimal, Quantity: qty}
-			// Check if this is a new best ask
-			if priceDecimal.LessThan(ob.bestAsk) {
-				ob.bestAsk = priceDecimal
+			ob.bids.Delete(bestBid.Price)
+			if ob.l3 {
+				ob.dropLevelOrders(true, bestBid.Price)
 			}
 		}
 	}
+}
 
-	// Recalculate best prices only if needed
-	if bestBidChanged {
-		ob.recalculateBestBid()
+// touchesBid reports whether an update sets the bid level or a bid order at price
+func touchesBid(update *exchange.DepthUpdate, price decimal.Decimal) bool {
+	for _, level := range update.Bids {
+		if p, err := decimal.NewFromString(level.Price); err == nil && p.Equal(price) {
+			return true
+		}
 	}
-	if bestAskChanged {
-		ob.recalculateBestAsk()
+	for _, order := range update.BidOrders {
+		if p, err := decimal.NewFromString(order.Price); err == nil && p.Equal(price) {
+			return true
+		}
 	}
+	return false
+}
 
-	ob.lastUpdateID = update.FinalUpdateID
-	ob.stats.EventsProcessed++
-	ob.stats.LastEventTime = update.EventTime
-	ob.updateCachedStats()
+// requestResync marks the book for reinitialization and wakes any listener (must be called with mutex locked)
+func (ob *OrderBook) requestResync() {
+	ob.resyncNeeded = true
+	select {
+	case ob.resync <- struct{}{}:
+	default:
+	}
 }
 
-// updateStats recalculates orderbook statistics (must be called with mutex locked)
+// updateStats refreshes the cached best prices and level counts, then the stats (must be called with mutex locked)
 func (ob *OrderBook) updateStats() {
-	ob.bidLevels = len(ob.bids)
-	ob.askLevels = len(ob.asks)
+	ob.bidLevels = ob.bids.Len()
+	ob.askLevels = ob.asks.Len()
 
-	ob.bestBid = decimal.Zero
-	ob.bestAsk = decimal.NewFromFloat(999999999)
+	previousBid, previousAsk := ob.bestBid, ob.bestAsk
+	defer ob.emitBestChanges(previousBid, previousAsk)
 
-	if len(ob.bids) > 0 {
-		for _, level := range ob.bids {
-			if level.Price.GreaterThan(ob.bestBid) {
-				ob.bestBid = level.Price
-			}
-		}
+	ob.bestBid = decimal.Zero
+	if best, ok := ob.bids.Best(); ok {
+		ob.bestBid = best.Price
 	}
 
-	if len(ob.asks) > 0 {
-		for _, level := range ob.asks {
-			if level.Price.LessThan(ob.bestAsk) {
-				ob.bestAsk = level.Price
-			}
-		}
-		if ob.bestAsk.Equal(decimal.NewFromFloat(999999999)) {
-			ob.bestAsk = decimal.Zero
-		}
-	} else {
-		ob.bestAsk = decimal.Zero
+	ob.bestAsk = decimal.Zero
+	if best, ok := ob.asks.Best(); ok {
+		ob.bestAsk = best.Price
 	}
 
 	ob.updateCachedStats()
@@ -359,7 +660,6 @@ func (ob *OrderBook) updateStats() {
 func (ob *OrderBook) updateCachedStats() {
 	ob.stats.BidLevels = ob.bidLevels
 	ob.stats.AskLevels = ob.askLevels
-	ob.stats.BufferedEvents = len(ob.eventBuffer)
 	ob.stats.BestBid = ob.bestBid
 	ob.stats.BestAsk = ob.bestAsk
 
@@ -371,11 +671,88 @@ func (ob *OrderBook) updateCachedStats() {
 
 	// Calculate liquidity depth metrics
 	ob.calculateLiquidityDepth()
+	ob.calculateImbalance()
+
+	if ob.l3 {
+		ob.updateOrderStats()
+	}
+	at := now()
+	ob.sampleRolling(at)
+	ob.sampleOFI(at)
+	ob.sampleTouch(at)
+
+	ob.publish()
+}
+
+// bookView is an immutable copy of the stats and top of book, replaced on every change
+type bookView struct {
+	stats          types.Stats
+	initialized    bool
+	bestBid        types.PriceLevel
+	bestAsk        types.PriceLevel
+	hasBid, hasAsk bool
+}
+
+// publish bumps the book version and hands readers a fresh view, so GetStats and the
+// other light getters never wait on the write lock (must be called with mutex locked)
+// The stats slices are rebuilt on every change rather than reused, so sharing them is safe
+func (ob *OrderBook) publish() {
+	ob.version.Add(1)
+	ob.stats.BufferedEvents = len(ob.eventBuffer)
+
+	view := &bookView{stats: ob.stats, initialized: ob.initialized}
+	view.bestBid, view.hasBid = ob.bids.Best()
+	view.bestAsk, view.hasAsk = ob.asks.Best()
+	ob.view.Store(view)
+}
+
+// calculateImbalance calculates the microprice and the imbalance at each configured depth (must be called with mutex locked)
+func (ob *OrderBook) calculateImbalance() {
+	if ob.bestBid.IsZero() || ob.bestAsk.IsZero() || len(ob.depths) == 0 {
+		ob.stats.Microprice = decimal.Zero
+		ob.stats.Imbalances = nil
+		return
+	}
+
+	// Running size and notional per side, read off at each configured depth
+	bids := ob.bids.TopSums(ob.depths)
+	asks := ob.asks.TopSums(ob.depths)
+	imbalances := make([]types.DepthImbalance, len(ob.depths))
+	for i, depth := range ob.depths {
+		imbalances[i] = depthImbalance(depth, bids[i].size, asks[i].size, bids[i].notional, asks[i].notional)
+	}
+	ob.stats.Imbalances = imbalances
+
+	// The top levels alone give the microprice
+	bestBid, _ := ob.bids.Best()
+	bestAsk, _ := ob.asks.Best()
+	ob.stats.Microprice = depthImbalance(1, bestBid.Quantity, bestAsk.Quantity,
+		bestBid.Price.Mul(bestBid.Quantity), bestAsk.Price.Mul(bestAsk.Quantity)).WeightedMid
 }
 
-// calculateLiquidityDepth calculates liquidity at various depth percentages (must be called with mutex locked)
+// depthImbalance derives the imbalance and weighted mid from the size and notional of each side
+func depthImbalance(levels int, bidSize, askSize, bidNotional, askNotional decimal.Decimal) types.DepthImbalance {
+	total := bidSize.Add(askSize)
+	if total.IsZero() || bidSize.IsZero() || askSize.IsZero() {
+		return types.DepthImbalance{Levels: levels, Imbalance: decimal.Zero, WeightedMid: decimal.Zero}
+	}
+
+	// Each side's VWAP is weighted by the opposite size, pulling the mid towards the thinner side
+	bidVWAP := bidNotional.Div(bidSize)
+	askVWAP := askNotional.Div(askSize)
+	return types.DepthImbalance{
+		Levels:      levels,
+		Imbalance:   bidSize.Div(total),
+		WeightedMid: bidVWAP.Mul(askSize).Add(askVWAP.Mul(bidSize)).Div(total),
+	}
+}
+
+// calculateLiquidityDepth calculates liquidity within the configured bands and the default ones (must be called with mutex locked)
 func (ob *OrderBook) calculateLiquidityDepth() {
 	if ob.bestBid.IsZero() || ob.bestAsk.IsZero() {
+		ob.bids.ResetBands()
+		ob.asks.ResetBands()
+		ob.stats.Bands = nil
 		ob.stats.BidLiquidity05Pct = decimal.Zero
 		ob.stats.AskLiquidity05Pct = decimal.Zero
 		ob.stats.BidLiquidity2Pct = decimal.Zero
@@ -387,97 +764,132 @@ func (ob *OrderBook) calculateLiquidityDepth() {
 		ob.stats.DeltaLiquidity10Pct = decimal.Zero
 		ob.stats.TotalBidsQty = decimal.Zero
 		ob.stats.TotalAsksQty = decimal.Zero
+		ob.stats.BidNotional05Pct = decimal.Zero
+		ob.stats.AskNotional05Pct = decimal.Zero
+		ob.stats.BidNotional2Pct = decimal.Zero
+		ob.stats.AskNotional2Pct = decimal.Zero
+		ob.stats.BidNotional10Pct = decimal.Zero
+		ob.stats.AskNotional10Pct = decimal.Zero
+		ob.stats.TotalBidsNotional = decimal.Zero
+		ob.stats.TotalAsksNotional = decimal.Zero
 		return
 	}
 
 	// Calculate mid price
 	midPrice := ob.bestBid.Add(ob.bestAsk).Div(decimal.NewFromInt(2))
 
-	// Calculate price thresholds
-	threshold05Pct := midPrice.Mul(decimal.NewFromFloat(0.005))
-	threshold2Pct := midPrice.Mul(decimal.NewFromFloat(0.02))
-	threshold10Pct := midPrice.Mul(decimal.NewFromFloat(0.10))
-
-	// Calculate bid side liquidity
-	bidLiq05 := decimal.Zero
-	bidLiq2 := decimal.Zero
-	bidLiq10 := decimal.Zero
-	totalBidsQty := decimal.Zero
-	minBid05Pct := midPrice.Sub(threshold05Pct)
-	minBid2Pct := midPrice.Sub(threshold2Pct)
-	minBid10Pct := midPrice.Sub(threshold10Pct)
-
-	for _, level := range ob.bids {
-		totalBidsQty = totalBidsQty.Add(level.Quantity)
-		if level.Price.GreaterThanOrEqual(minBid05Pct) {
-			bidLiq05 = bidLiq05.Add(level.Quantity)
-		}
-		if level.Price.GreaterThanOrEqual(minBid2Pct) {
-			bidLiq2 = bidLiq2.Add(level.Quantity)
-		}
-		if level.Price.GreaterThanOrEqual(minBid10Pct) {
-			bidLiq10 = bidLiq10.Add(level.Quantity)
+	// Each side keeps its band sums as levels change, only visiting levels that cross a band limit as mid moves
+	bidLiq := ob.bids.BandSums(midPrice, ob.bandWalk)
+	askLiq := ob.asks.BandSums(midPrice, ob.bandWalk)
+
+	// Stats are copied out by GetStats, so the band slice is rebuilt rather than reused
+	bands := make([]types.BandLiquidity, len(ob.bands))
+	for i, pct := range ob.bands {
+		j := ob.bandIndex[pct.String()]
+		bands[i] = types.BandLiquidity{
+			Pct:         pct,
+			Bid:         bidLiq[j].size,
+			Ask:         askLiq[j].size,
+			Delta:       bidLiq[j].size.Sub(askLiq[j].size),
+			BidNotional: bidLiq[j].notional,
+			AskNotional: askLiq[j].notional,
 		}
 	}
+	ob.stats.Bands = bands
 
-	// Calculate ask side liquidity
-	askLiq05 := decimal.Zero
-	askLiq2 := decimal.Zero
-	askLiq10 := decimal.Zero
-	totalAsksQty := decimal.Zero
-	maxAsk05Pct := midPrice.Add(threshold05Pct)
-	maxAsk2Pct := midPrice.Add(threshold2Pct)
-	maxAsk10Pct := midPrice.Add(threshold10Pct)
+	i05 := ob.bandIndex[types.DefaultLiquidityBands[0].String()]
+	i2 := ob.bandIndex[types.DefaultLiquidityBands[1].String()]
+	i10 := ob.bandIndex[types.DefaultLiquidityBands[2].String()]
 
-	for _, level := range ob.asks {
-		totalAsksQty = totalAsksQty.Add(level.Quantity)
-		if level.Price.LessThanOrEqual(maxAsk05Pct) {
-			askLiq05 = askLiq05.Add(level.Quantity)
-		}
-		if level.Price.LessThanOrEqual(maxAsk2Pct) {
-			askLiq2 = askLiq2.Add(level.Quantity)
-		}
-		if level.Price.LessThanOrEqual(maxAsk10Pct) {
-			askLiq10 = askLiq10.Add(level.Quantity)
-		}
-	}
+	// Totals are maintained by each side as levels change
+	totalBidsQty := ob.bids.Total()
+	totalAsksQty := ob.asks.Total()
 
 	// Update stats
-	ob.stats.BidLiquidity05Pct = bidLiq05
-	ob.stats.AskLiquidity05Pct = askLiq05
-	ob.stats.BidLiquidity2Pct = bidLiq2
-	ob.stats.AskLiquidity2Pct = askLiq2
-	ob.stats.BidLiquidity10Pct = bidLiq10
-	ob.stats.AskLiquidity10Pct = askLiq10
+	ob.stats.BidLiquidity05Pct = bidLiq[i05].size
+	ob.stats.AskLiquidity05Pct = askLiq[i05].size
+	ob.stats.BidLiquidity2Pct = bidLiq[i2].size
+	ob.stats.AskLiquidity2Pct = askLiq[i2].size
+	ob.stats.BidLiquidity10Pct = bidLiq[i10].size
+	ob.stats.AskLiquidity10Pct = askLiq[i10].size
 	ob.stats.TotalBidsQty = totalBidsQty
 	ob.stats.TotalAsksQty = totalAsksQty
 
 	// Calculate deltas (positive = more bid liquidity = bullish pressure)
-	ob.stats.DeltaLiquidity05Pct = bidLiq05.Sub(askLiq05)
-	ob.stats.DeltaLiquidity2Pct = bidLiq2.Sub(askLiq2)
-	ob.stats.DeltaLiquidity10Pct = bidLiq10.Sub(askLiq10)
+	ob.stats.DeltaLiquidity05Pct = bidLiq[i05].size.Sub(askLiq[i05].size)
+	ob.stats.DeltaLiquidity2Pct = bidLiq[i2].size.Sub(askLiq[i2].size)
+	ob.stats.DeltaLiquidity10Pct = bidLiq[i10].size.Sub(askLiq[i10].size)
 	ob.stats.TotalDelta = totalBidsQty.Sub(totalAsksQty)
+
+	// Notional equivalents in quote currency
+	ob.stats.BidNotional05Pct = bidLiq[i05].notional
+	ob.stats.AskNotional05Pct = askLiq[i05].notional
+	ob.stats.BidNotional2Pct = bidLiq[i2].notional
+	ob.stats.AskNotional2Pct = askLiq[i2].notional
+	ob.stats.BidNotional10Pct = bidLiq[i10].notional
+	ob.stats.AskNotional10Pct = askLiq[i10].notional
+	ob.stats.TotalBidsNotional = ob.bids.Notional()
+	ob.stats.TotalAsksNotional = ob.asks.Notional()
 }
 
-// recalculateBestBid recalculates the best bid when the current best is removed
-func (ob *OrderBook) recalculateBestBid() {
-	ob.bestBid = decimal.Zero
-	for _, level := range ob.bids {
-		if level.Price.GreaterThan(ob.bestBid) {
-			ob.bestBid = level.Price
+// bandSum is the size and notional resting within one band
+type bandSum struct {
+	size     decimal.Decimal
+	notional decimal.Decimal // Sum of price * size, in quote currency
+}
+
+// bandLiquidity sums the size and notional within each band around mid, walking out
+// from the best price until the widest band; bands must be ascending
+func bandLiquidity(side *bookSide, midPrice decimal.Decimal, bands []decimal.Decimal, bid bool) []bandSum {
+	limits := make([]decimal.Decimal, len(bands))
+	for i, pct := range bands {
+		if bid {
+			limits[i] = midPrice.Sub(midPrice.Mul(pct))
+		} else {
+			limits[i] = midPrice.Add(midPrice.Mul(pct))
 		}
 	}
-}
 
-// recalculateBestAsk recalculates the best ask when the current best is removed
-func (ob *OrderBook) recalculateBestAsk() {
-	ob.bestAsk = decimal.NewFromFloat(999999999)
-	for _, level := range ob.asks {
-		if level.Price.LessThan(ob.bestAsk) {
-			ob.bestAsk = level.Price
+	inside := func(price, limit decimal.Decimal) bool {
+		if bid {
+			return price.GreaterThanOrEqual(limit)
 		}
+		return price.LessThanOrEqual(limit)
 	}
-	if ob.bestAsk.Equal(decimal.NewFromFloat(999999999)) {
-		ob.bestAsk = decimal.Zero
+
+	// Size is first added to the narrowest band holding the level, then accumulated outwards
+	liquidity := make([]bandSum, len(bands))
+	for i := range liquidity {
+		liquidity[i] = bandSum{size: decimal.Zero, notional: decimal.Zero}
 	}
+
+	band := 0
+	side.Ascend(func(level types.PriceLevel) bool {
+		for band < len(limits) && !inside(level.Price, limits[band]) {
+			band++
+		}
+		if band == len(limits) {
+			return false
+		}
+		liquidity[band].size = liquidity[band].size.Add(level.Quantity)
+		liquidity[band].notional = liquidity[band].notional.Add(level.Price.Mul(level.Quantity))
+		return true
+	})
+
+	for i := 1; i < len(liquidity); i++ {
+		liquidity[i].size = liquidity[i].size.Add(liquidity[i-1].size)
+		liquidity[i].notional = liquidity[i].notional.Add(liquidity[i-1].notional)
+	}
+	return liquidity
+}
+
+// levelsByPrice copies a side into a map keyed by price as published by the venue, the
+// strings TopRaw returns
+func levelsByPrice(side *bookSide) map[string]types.PriceLevel {
+	levels := make(map[string]types.PriceLevel, side.Len())
+	side.ascendNodes(func(node *levelNode) bool {
+		levels[node.raw.Price] = node.PriceLevel
+		return true
+	})
+	return levels
 }
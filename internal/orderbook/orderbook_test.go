@@ -0,0 +1,1409 @@
+package orderbook
+
+import (
+	"hash/crc32"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+func TestBookSideOrdering(t *testing.T) {
+	bids := newBookSide(true)
+	asks := newBookSide(false)
+
+	prices := []string{"100.5", "99", "101", "100", "98.25"}
+	for _, p := range prices {
+		price := decimal.RequireFromString(p)
+		bids.Set(price, decimal.NewFromInt(1))
+		asks.Set(price, decimal.NewFromInt(1))
+	}
+
+	expectedBids := []string{"101", "100.5", "100", "99", "98.25"}
+	for i, level := range bids.Top(0) {
+		if level.Price.String() != expectedBids[i] {
+			t.Errorf("bid %d: expected %s, got %s", i, expectedBids[i], level.Price)
+		}
+	}
+
+	expectedAsks := []string{"98.25", "99", "100", "100.5", "101"}
+	for i, level := range asks.Top(0) {
+		if level.Price.String() != expectedAsks[i] {
+			t.Errorf("ask %d: expected %s, got %s", i, expectedAsks[i], level.Price)
+		}
+	}
+
+	if top := bids.Top(2); len(top) != 2 {
+		t.Errorf("expected 2 levels from Top(2), got %d", len(top))
+	}
+}
+
+func TestBookSideUpdateAndDelete(t *testing.T) {
+	side := newBookSide(false)
+
+	side.Set(decimal.RequireFromString("10"), decimal.RequireFromString("1.5"))
+	side.Set(decimal.RequireFromString("11"), decimal.RequireFromString("2"))
+
+	// Same price in a different string form updates the existing level
+	side.Set(decimal.RequireFromString("10.00"), decimal.RequireFromString("3"))
+
+	if side.Len() != 2 {
+		t.Fatalf("expected 2 levels, got %d", side.Len())
+	}
+	if !side.Total().Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected total 5, got %s", side.Total())
+	}
+
+	// Zero quantity removes the level
+	side.Set(decimal.RequireFromString("10"), decimal.Zero)
+
+	best, ok := side.Best()
+	if !ok || best.Price.String() != "11" {
+		t.Errorf("expected best 11 after delete, got %v (ok=%t)", best.Price, ok)
+	}
+	if !side.Total().Equal(decimal.NewFromInt(2)) {
+		t.Errorf("expected total 2, got %s", side.Total())
+	}
+
+	if side.Delete(decimal.RequireFromString("42")) {
+		t.Error("Delete reported removing a missing level")
+	}
+}
+
+func TestBookSideRandomized(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	side := newBookSide(true)
+	reference := make(map[int64]int64)
+
+	for i := 0; i < 5000; i++ {
+		price := rng.Int63n(500)
+		qty := rng.Int63n(4) // 0 removes the level
+		side.Set(decimal.NewFromInt(price), decimal.NewFromInt(qty))
+		if qty == 0 {
+			delete(reference, price)
+		} else {
+			reference[price] = qty
+		}
+	}
+
+	expected := make([]int64, 0, len(reference))
+	total := int64(0)
+	for price, qty := range reference {
+		expected = append(expected, price)
+		total += qty
+	}
+	sort.Slice(expected, func(i, j int) bool { return expected[i] > expected[j] })
+
+	levels := side.Top(0)
+	if len(levels) != len(expected) {
+		t.Fatalf("expected %d levels, got %d", len(expected), len(levels))
+	}
+	for i, level := range levels {
+		if level.Price.IntPart() != expected[i] || level.Quantity.IntPart() != reference[expected[i]] {
+			t.Fatalf("level %d: expected %d@%d, got %s@%s",
+				i, reference[expected[i]], expected[i], level.Quantity, level.Price)
+		}
+	}
+	if side.Total().IntPart() != total {
+		t.Errorf("expected total %d, got %s", total, side.Total())
+	}
+}
+
+func TestOrderBookBestAndStats(t *testing.T) {
+	ob := New()
+
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{
+			{Price: "99", Quantity: "1"},
+			{Price: "100", Quantity: "2"},
+			{Price: "80", Quantity: "5"},
+		},
+		Asks: []exchange.PriceLevel{
+			{Price: "102", Quantity: "1"},
+			{Price: "101", Quantity: "3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		EventTime:     time.Now(),
+		FirstUpdateID: 2,
+		FinalUpdateID: 2,
+		PrevUpdateID:  1,
+		Bids:          []exchange.PriceLevel{{Price: "100", Quantity: "0"}},
+		Asks:          []exchange.PriceLevel{{Price: "100.5", Quantity: "4"}},
+	})
+
+	bestBid, _ := ob.BestBid()
+	bestAsk, _ := ob.BestAsk()
+	if bestBid.Price.String() != "99" || bestAsk.Price.String() != "100.5" {
+		t.Errorf("expected best 99/100.5, got %s/%s", bestBid.Price, bestAsk.Price)
+	}
+
+	stats := ob.GetStats()
+	if stats.BidLevels != 2 || stats.AskLevels != 3 {
+		t.Errorf("expected 2 bid and 3 ask levels, got %d and %d", stats.BidLevels, stats.AskLevels)
+	}
+	if !stats.TotalBidsQty.Equal(decimal.NewFromInt(6)) || !stats.TotalAsksQty.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("expected totals 6/8, got %s/%s", stats.TotalBidsQty, stats.TotalAsksQty)
+	}
+
+	// Mid is 99.75, so the 10% band reaches 89.775 and excludes the bid at 80
+	if !stats.BidLiquidity10Pct.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected 10%% bid liquidity 1, got %s", stats.BidLiquidity10Pct)
+	}
+	// The 2% band reaches 101.745, covering the asks at 100.5 and 101
+	if !stats.AskLiquidity2Pct.Equal(decimal.NewFromInt(7)) {
+		t.Errorf("expected 2%% ask liquidity 7, got %s", stats.AskLiquidity2Pct)
+	}
+
+	levels := ob.GetBids()
+	if _, ok := levels["99"]; !ok || len(levels) != 2 {
+		t.Errorf("GetBids compatibility map has unexpected contents: %v", levels)
+	}
+}
+
+func TestGetLevelsKeyedByPublishedPrice(t *testing.T) {
+	ob := New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "100.50", Quantity: "1"}},
+		Asks:         []exchange.PriceLevel{{Price: "101.00", Quantity: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	if _, ok := ob.GetBids()["100.50"]; !ok {
+		t.Errorf("expected the bid keyed 100.50, got %v", ob.GetBids())
+	}
+	if _, ok := ob.GetAsks()["101.00"]; !ok {
+		t.Errorf("expected the ask keyed 101.00, got %v", ob.GetAsks())
+	}
+}
+
+func TestChecksumFormats(t *testing.T) {
+	bids := []exchange.PriceLevel{{Price: "0.05000", Quantity: "1.50000000"}}
+	asks := []exchange.PriceLevel{
+		{Price: "0.05005", Quantity: "0.00000500"},
+		{Price: "0.05010", Quantity: "2.00000000"},
+	}
+
+	kraken := crc32.ChecksumIEEE([]byte("5005" + "500" + "5010" + "200000000" + "5000" + "150000000"))
+	if got := krakenChecksum(bids, asks); got != kraken {
+		t.Errorf("kraken: expected %d, got %d", kraken, got)
+	}
+
+	okx := crc32.ChecksumIEEE([]byte("0.05000:1.50000000:0.05005:0.00000500:0.05010:2.00000000"))
+	if got := okxChecksum(bids, asks); got != okx {
+		t.Errorf("okx: expected %d, got %d", okx, got)
+	}
+}
+
+func TestOrderBookChecksumMismatch(t *testing.T) {
+	ob := New()
+	ob.SetChecksum(ChecksumFor(exchange.OKX))
+
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "100.0", Quantity: "2.0"}},
+		Asks:         []exchange.PriceLevel{{Price: "101.0", Quantity: "1.0"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	// Raw strings are kept, so the checksum covers the venue's own formatting
+	good := crc32.ChecksumIEEE([]byte("100.0:3.0:101.0:1.0"))
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		FirstUpdateID: 2,
+		FinalUpdateID: 2,
+		PrevUpdateID:  1,
+		Bids:          []exchange.PriceLevel{{Price: "100.0", Quantity: "3.0"}},
+		Checksum:      &good,
+	})
+	if failures := ob.GetStats().ChecksumFailures; failures != 0 {
+		t.Fatalf("expected no checksum failures, got %d", failures)
+	}
+
+	bad := good + 1
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		FirstUpdateID: 3,
+		FinalUpdateID: 3,
+		PrevUpdateID:  2,
+		Asks:          []exchange.PriceLevel{{Price: "101.0", Quantity: "4.0"}},
+		Checksum:      &bad,
+	})
+	if failures := ob.GetStats().ChecksumFailures; failures != 1 {
+		t.Fatalf("expected 1 checksum failure, got %d", failures)
+	}
+
+	select {
+	case <-ob.ResyncRequests():
+	default:
+		t.Fatal("expected a resync request after the mismatch")
+	}
+
+	reloaded := false
+	ob.CheckAndReinitialize(func() (*exchange.Snapshot, error) {
+		reloaded = true
+		return &exchange.Snapshot{
+			LastUpdateID: 10,
+			Bids:         []exchange.PriceLevel{{Price: "100.0", Quantity: "3.0"}},
+			Asks:         []exchange.PriceLevel{{Price: "101.0", Quantity: "4.0"}},
+		}, nil
+	})
+	if !reloaded || !ob.IsInitialized() {
+		t.Errorf("expected the book to be reinitialized (reloaded=%t)", reloaded)
+	}
+}
+
+func TestOrderBookGapPolicies(t *testing.T) {
+	gapped := &exchange.DepthUpdate{
+		FirstUpdateID: 5,
+		FinalUpdateID: 5,
+		PrevUpdateID:  4,
+		Bids:          []exchange.PriceLevel{{Price: "99", Quantity: "7"}},
+	}
+
+	tests := []struct {
+		policy   types.GapPolicy
+		applied  bool
+		buffered int
+		resync   bool
+	}{
+		{types.GapBuffer, false, 1, false},
+		{types.GapLog, true, 0, false},
+		{types.GapDrop, false, 0, false},
+		{types.GapResync, false, 1, true},
+	}
+
+	for _, tt := range tests {
+		ob := New()
+		ob.SetGapPolicy(tt.policy)
+		err := ob.LoadSnapshot(&exchange.Snapshot{
+			LastUpdateID: 1,
+			Bids:         []exchange.PriceLevel{{Price: "100", Quantity: "1"}},
+			Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "1"}},
+		})
+		if err != nil {
+			t.Fatalf("%s: LoadSnapshot failed: %v", tt.policy, err)
+		}
+		ob.ProcessBufferedEvents()
+
+		ob.HandleDepthUpdate(gapped)
+
+		if gaps := ob.GetStats().SequenceGaps; gaps != 1 {
+			t.Errorf("%s: expected 1 gap, got %d", tt.policy, gaps)
+		}
+		if _, ok := ob.GetBids()["99"]; ok != tt.applied {
+			t.Errorf("%s: expected applied=%t", tt.policy, tt.applied)
+		}
+		if buffered := ob.GetBufferLength(); buffered != tt.buffered {
+			t.Errorf("%s: expected %d buffered events, got %d", tt.policy, tt.buffered, buffered)
+		}
+
+		resync := false
+		select {
+		case <-ob.ResyncRequests():
+			resync = true
+		default:
+		}
+		if resync != tt.resync {
+			t.Errorf("%s: expected resync=%t", tt.policy, tt.resync)
+		}
+	}
+}
+
+func TestParseGapPolicy(t *testing.T) {
+	if policy, err := types.ParseGapPolicy(""); err != nil || policy != types.GapBuffer {
+		t.Errorf("expected empty name to select buffer, got %q (%v)", policy, err)
+	}
+	if _, err := types.ParseGapPolicy("skip"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
+func TestBookSideIncrementalBands(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	bands := []decimal.Decimal{
+		decimal.RequireFromString("0.005"),
+		decimal.RequireFromString("0.02"),
+		decimal.RequireFromString("0.1"),
+	}
+
+	for _, descending := range []bool{true, false} {
+		side := newBookSide(descending)
+		for i := 0; i < 5000; i++ {
+			side.Set(decimal.NewFromInt(900+rng.Int63n(200)), decimal.NewFromInt(rng.Int63n(4)))
+
+			// Mid wanders by whole and half ticks so limits land on and between levels
+			mid := decimal.NewFromInt(950 + rng.Int63n(100)).Add(decimal.NewFromFloat(0.5).Mul(decimal.NewFromInt(rng.Int63n(2))))
+			if i%7 != 0 {
+				continue
+			}
+
+			got := side.BandSums(mid, bands)
+			want := bandLiquidity(side, mid, bands, descending)
+			for j := range bands {
+				if !got[j].size.Equal(want[j].size) || !got[j].notional.Equal(want[j].notional) {
+					t.Fatalf("descending=%v step %d band %s: incremental %s/%s, walked %s/%s", descending, i, bands[j],
+						got[j].size, got[j].notional, want[j].size, want[j].notional)
+				}
+			}
+		}
+	}
+}
+
+func TestOrderBookLiquidityBands(t *testing.T) {
+	ob := New()
+	bands, err := types.ParseLiquidityBands("1, 10bp,0.5%,1")
+	if err != nil {
+		t.Fatalf("ParseLiquidityBands failed: %v", err)
+	}
+	ob.SetLiquidityBands(bands)
+
+	err = ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{
+			{Price: "99.95", Quantity: "1"},
+			{Price: "99.6", Quantity: "2"},
+			{Price: "99", Quantity: "4"},
+		},
+		Asks: []exchange.PriceLevel{
+			{Price: "100.05", Quantity: "1"},
+			{Price: "100.2", Quantity: "3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	// Mid is 100, so the bands reach 0.1, 0.5 and 1 away from it
+	expected := []struct {
+		label    string
+		bid, ask int64
+	}{
+		{"0.1%", 1, 1},
+		{"0.5%", 3, 4},
+		{"1%", 7, 4},
+	}
+
+	stats := ob.GetStats()
+	if len(stats.Bands) != len(expected) {
+		t.Fatalf("expected %d bands, got %d", len(expected), len(stats.Bands))
+	}
+	for i, want := range expected {
+		band := stats.Bands[i]
+		if types.BandLabel(band.Pct) != want.label {
+			t.Errorf("band %d: expected label %s, got %s", i, want.label, types.BandLabel(band.Pct))
+		}
+		if band.Bid.IntPart() != want.bid || band.Ask.IntPart() != want.ask {
+			t.Errorf("band %s: expected %d/%d, got %s/%s", want.label, want.bid, want.ask, band.Bid, band.Ask)
+		}
+	}
+
+	// Default bands are still filled for the fixed fields
+	if !stats.BidLiquidity2Pct.Equal(decimal.NewFromInt(7)) {
+		t.Errorf("expected 2%% bid liquidity 7, got %s", stats.BidLiquidity2Pct)
+	}
+
+	bids, asks := ob.LiquidityWithin(decimal.RequireFromString("0.0045"))
+	if bids.IntPart() != 3 || asks.IntPart() != 4 {
+		t.Errorf("expected 3/4 within 0.45%%, got %s/%s", bids, asks)
+	}
+
+	if _, err := types.ParseLiquidityBands("1,abc"); err == nil {
+		t.Error("expected an error for an invalid band")
+	}
+}
+
+func TestOrderBookNotional(t *testing.T) {
+	ob := New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{
+			{Price: "100", Quantity: "2"},
+			{Price: "90", Quantity: "1"},
+		},
+		Asks: []exchange.PriceLevel{
+			{Price: "102", Quantity: "1.5"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	// Resizing and removing levels keeps the running notional in step
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		FirstUpdateID: 2,
+		FinalUpdateID: 2,
+		PrevUpdateID:  1,
+		Bids: []exchange.PriceLevel{
+			{Price: "100", Quantity: "3"},
+			{Price: "90", Quantity: "0"},
+		},
+	})
+
+	stats := ob.GetStats()
+	if !stats.TotalBidsNotional.Equal(decimal.NewFromInt(300)) || !stats.TotalAsksNotional.Equal(decimal.NewFromInt(153)) {
+		t.Errorf("expected total notional 300/153, got %s/%s", stats.TotalBidsNotional, stats.TotalAsksNotional)
+	}
+	if !stats.BidNotional2Pct.Equal(decimal.NewFromInt(300)) || !stats.AskNotional2Pct.Equal(decimal.NewFromInt(153)) {
+		t.Errorf("expected 2%% notional 300/153, got %s/%s", stats.BidNotional2Pct, stats.AskNotional2Pct)
+	}
+	if len(stats.Bands) != 3 || !stats.Bands[1].BidNotional.Equal(stats.BidNotional2Pct) {
+		t.Errorf("expected band notional to match the 2%% field, got %+v", stats.Bands)
+	}
+}
+
+func TestOrderBookImpact(t *testing.T) {
+	ob := New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{
+			{Price: "99", Quantity: "1"},
+			{Price: "98", Quantity: "2"},
+		},
+		Asks: []exchange.PriceLevel{
+			{Price: "101", Quantity: "1"},
+			{Price: "102", Quantity: "1"},
+			{Price: "104", Quantity: "2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	// Buying 2 takes 101 and 102: VWAP 101.5, 1.5% above the mid of 100
+	buy := ob.PriceForSize(types.Buy, decimal.NewFromInt(2))
+	if !buy.Complete || !buy.VWAP.Equal(decimal.RequireFromString("101.5")) || buy.Levels != 2 {
+		t.Errorf("unexpected buy execution: %+v", buy)
+	}
+	if !buy.Slippage.Equal(decimal.RequireFromString("0.015")) {
+		t.Errorf("expected buy slippage 0.015, got %s", buy.Slippage)
+	}
+
+	// Selling 2 takes 99 and half of 98: VWAP 98.5, 1.5% below mid
+	sell := ob.PriceForSize(types.Sell, decimal.NewFromInt(2))
+	if !sell.Complete || !sell.VWAP.Equal(decimal.RequireFromString("98.5")) || !sell.WorstPrice.Equal(decimal.NewFromInt(98)) {
+		t.Errorf("unexpected sell execution: %+v", sell)
+	}
+	if !sell.Slippage.Equal(decimal.RequireFromString("0.015")) {
+		t.Errorf("expected sell slippage 0.015, got %s", sell.Slippage)
+	}
+
+	if thin := ob.PriceForSize(types.Sell, decimal.NewFromInt(10)); thin.Complete || !thin.Size.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("expected a partial fill of 3, got %+v", thin)
+	}
+
+	// Spending 203 buys 101 and 102, the same as buying a size of 2
+	spend := ob.PriceForNotional(types.Buy, decimal.NewFromInt(203))
+	if !spend.Complete || !spend.Size.Equal(decimal.NewFromInt(2)) || !spend.Slippage.Equal(decimal.RequireFromString("0.015")) {
+		t.Errorf("unexpected buy of 203 notional: %+v", spend)
+	}
+	if half := ob.PriceForNotional(types.Sell, decimal.NewFromInt(148)); !half.Complete || !half.Size.Equal(decimal.RequireFromString("1.5")) {
+		t.Errorf("expected to sell 1.5 for 148 notional, got %+v", half)
+	}
+	if thin := ob.PriceForNotional(types.Sell, decimal.NewFromInt(1000)); thin.Complete || !thin.Notional.Equal(decimal.NewFromInt(295)) {
+		t.Errorf("expected a partial fill of 295 notional, got %+v", thin)
+	}
+
+	upTo := ob.SizeForPrice(types.Buy, decimal.NewFromInt(103))
+	if !upTo.Complete || !upTo.Size.Equal(decimal.NewFromInt(2)) || !upTo.WorstPrice.Equal(decimal.NewFromInt(102)) {
+		t.Errorf("unexpected size up to 103: %+v", upTo)
+	}
+	if all := ob.SizeForPrice(types.Buy, decimal.NewFromInt(200)); all.Complete || !all.Size.Equal(decimal.NewFromInt(4)) {
+		t.Errorf("expected the whole ask side without reaching the limit, got %+v", all)
+	}
+}
+
+func TestOrderBookImbalance(t *testing.T) {
+	ob := New()
+	ob.SetImbalanceDepths([]int{2, 1})
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{
+			{Price: "99", Quantity: "3"},
+			{Price: "98", Quantity: "1"},
+		},
+		Asks: []exchange.PriceLevel{
+			{Price: "101", Quantity: "1"},
+			{Price: "102", Quantity: "3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	stats := ob.GetStats()
+
+	// Three times the size on the bid pulls the microprice towards the ask: (99*1 + 101*3) / 4
+	if !stats.Microprice.Equal(decimal.RequireFromString("100.5")) {
+		t.Errorf("expected microprice 100.5, got %s", stats.Microprice)
+	}
+
+	if len(stats.Imbalances) != 2 || stats.Imbalances[0].Levels != 1 || stats.Imbalances[1].Levels != 2 {
+		t.Fatalf("expected imbalances at 1 and 2 levels, got %+v", stats.Imbalances)
+	}
+	if !stats.Imbalances[0].Imbalance.Equal(decimal.RequireFromString("0.75")) {
+		t.Errorf("expected top-level imbalance 0.75, got %s", stats.Imbalances[0].Imbalance)
+	}
+
+	// Both sides hold 4 over two levels, so the weighted mid is the mean of the VWAPs 98.75 and 101.75
+	deep := stats.Imbalances[1]
+	if !deep.Imbalance.Equal(decimal.RequireFromString("0.5")) || !deep.WeightedMid.Equal(decimal.RequireFromString("100.25")) {
+		t.Errorf("expected 0.5 imbalance and weighted mid 100.25 at 2 levels, got %s and %s", deep.Imbalance, deep.WeightedMid)
+	}
+}
+
+func TestOrderBookL3(t *testing.T) {
+	ob := New()
+	ob.SetL3(true)
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		BidOrders: []exchange.Order{
+			{ID: "b1", Price: "99", Quantity: "1"},
+			{ID: "b2", Price: "99", Quantity: "2"},
+			{ID: "b3", Price: "99", Quantity: "0.5"},
+			{ID: "b4", Price: "98", Quantity: "4"},
+		},
+		AskOrders: []exchange.Order{
+			{ID: "a1", Price: "101", Quantity: "3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	bids := ob.GetBidLevels(0)
+	if len(bids) != 2 || !bids[0].Quantity.Equal(decimal.RequireFromString("3.5")) {
+		t.Fatalf("expected 3.5 aggregated at 99, got %+v", bids)
+	}
+
+	pos, ok := ob.QueuePosition("b3")
+	if !ok || pos.Position != 2 || !pos.SizeAhead.Equal(decimal.NewFromInt(3)) || pos.OrdersAtLevel != 3 {
+		t.Fatalf("expected b3 third behind 3, got %+v (found %t)", pos, ok)
+	}
+
+	// Resizing keeps priority, a new order joins the back, a delete moves everyone up
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		BidOrders: []exchange.Order{
+			{ID: "b2", Price: "99", Quantity: "1.5"},
+			{ID: "b5", Price: "99", Quantity: "1"},
+			{ID: "b1", Price: "99", Quantity: "0"},
+		},
+	})
+
+	pos, _ = ob.QueuePosition("b3")
+	if pos.Position != 1 || !pos.SizeAhead.Equal(decimal.RequireFromString("1.5")) || pos.OrdersAtLevel != 3 {
+		t.Errorf("expected b3 second behind 1.5, got %+v", pos)
+	}
+	if _, ok := ob.QueuePosition("b1"); ok {
+		t.Error("expected deleted order to be gone")
+	}
+
+	// Moving an order to another price sends it to the back of that queue
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		BidOrders: []exchange.Order{{ID: "b2", Price: "98", Quantity: "1.5"}},
+	})
+
+	stats := ob.GetStats()
+	if !stats.BestBid.Equal(decimal.NewFromInt(99)) || stats.TotalOrders != 5 || stats.BestBidOrders != 2 || stats.BestAskOrders != 1 {
+		t.Errorf("unexpected L3 stats: best bid %s, %d orders, queues %d/%d",
+			stats.BestBid, stats.TotalOrders, stats.BestBidOrders, stats.BestAskOrders)
+	}
+	if level := ob.GetBidLevels(1)[0]; !level.Quantity.Equal(decimal.RequireFromString("1.5")) {
+		t.Errorf("expected 1.5 left at 99, got %s", level.Quantity)
+	}
+
+	orders := ob.LevelOrders(types.Buy, decimal.NewFromInt(98))
+	if len(orders) != 2 || orders[0].ID != "b4" || orders[1].ID != "b2" {
+		t.Errorf("expected b4 then b2 at 98, got %+v", orders)
+	}
+}
+
+func TestOrderBookSnapshot(t *testing.T) {
+	ob := New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "1"}, {Price: "98", Quantity: "2"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	first := ob.Snapshot()
+	if !first.Initialized || len(first.Bids) != 2 || !first.Bids[0].Price.Equal(decimal.NewFromInt(99)) {
+		t.Fatalf("unexpected snapshot: %+v", first)
+	}
+	if !first.MidPrice().Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected mid 100, got %s", first.MidPrice())
+	}
+	if again := ob.Snapshot(); again != first {
+		t.Error("expected an unchanged book to share its snapshot")
+	}
+
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		FirstUpdateID: 2,
+		FinalUpdateID: 2,
+		PrevUpdateID:  1,
+		Bids:          []exchange.PriceLevel{{Price: "99", Quantity: "0"}},
+	})
+
+	// The earlier copy is untouched by the update
+	if len(first.Bids) != 2 || first.LastUpdateID != 1 {
+		t.Errorf("expected the old snapshot to keep 2 bids at u=1, got %d at u=%d", len(first.Bids), first.LastUpdateID)
+	}
+
+	second := ob.Snapshot()
+	if second == first || second.Version <= first.Version {
+		t.Fatalf("expected a newer snapshot after an update")
+	}
+	if len(second.Bids) != 1 || !second.Stats.BestBid.Equal(decimal.NewFromInt(98)) || second.LastUpdateID != 2 {
+		t.Errorf("expected best bid 98 at u=2, got %s at u=%d", second.Stats.BestBid, second.LastUpdateID)
+	}
+}
+
+func TestOrderBookEvents(t *testing.T) {
+	ob := New()
+	ob.SetLargeSize(decimal.NewFromInt(10))
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "1"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	events := ob.Subscribe()
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		FirstUpdateID: 2,
+		FinalUpdateID: 2,
+		PrevUpdateID:  1,
+		Bids:          []exchange.PriceLevel{{Price: "100", Quantity: "12"}},
+		Asks:          []exchange.PriceLevel{{Price: "101", Quantity: "0"}, {Price: "102", Quantity: "1"}},
+	})
+	ob.Unsubscribe(events)
+
+	var got []BookEventType
+	for event := range events {
+		if event.UpdateID != 2 {
+			t.Errorf("expected events stamped u=2, got %d", event.UpdateID)
+		}
+		got = append(got, event.Type)
+	}
+
+	expected := []BookEventType{
+		EventLevelAdded, EventLargeSize, // Bid at 100
+		EventLevelRemoved, EventLevelAdded, // Ask 101 gone, 102 added
+		EventBestBid, EventBestAsk,
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("event %d: expected %s, got %s", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestOrderBookMaxDepth(t *testing.T) {
+	ob := New()
+	ob.SetMaxDepth(2)
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{
+			{Price: "99", Quantity: "1"},
+			{Price: "98", Quantity: "2"},
+			{Price: "97", Quantity: "3"},
+		},
+		Asks: []exchange.PriceLevel{
+			{Price: "101", Quantity: "1"},
+			{Price: "150", Quantity: "5"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	stats := ob.GetStats()
+	if stats.BidLevels != 2 || stats.TruncatedLevels != 1 || !stats.TruncatedVolume.Equal(decimal.NewFromInt(3)) {
+		t.Fatalf("expected the 97 bid truncated, got %d bid levels, %d truncated (%s)",
+			stats.BidLevels, stats.TruncatedLevels, stats.TruncatedVolume)
+	}
+
+	// A better bid pushes the worst kept level out
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{
+		FirstUpdateID: 2,
+		FinalUpdateID: 2,
+		PrevUpdateID:  1,
+		Bids:          []exchange.PriceLevel{{Price: "99.5", Quantity: "1"}},
+	})
+	if bids := ob.GetBidLevels(0); len(bids) != 2 || !bids[1].Price.Equal(decimal.NewFromInt(99)) {
+		t.Errorf("expected bids 99.5 and 99, got %+v", bids)
+	}
+
+	// The band drops the far ask regardless of the level cap
+	ob.SetMaxBand(decimal.RequireFromString("0.1"))
+	if asks := ob.GetAskLevels(0); len(asks) != 1 || !asks[0].Price.Equal(decimal.NewFromInt(101)) {
+		t.Errorf("expected only the 101 ask within 10%%, got %+v", asks)
+	}
+	if stats := ob.GetStats(); stats.TruncatedLevels != 3 || !stats.TruncatedVolume.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected 3 truncated levels totalling 10, got %d (%s)", stats.TruncatedLevels, stats.TruncatedVolume)
+	}
+}
+
+func TestOrderBookAggregate(t *testing.T) {
+	ob := New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{
+			{Price: "99.5", Quantity: "1"},
+			{Price: "91", Quantity: "2"},
+			{Price: "89.9", Quantity: "4"},
+		},
+		Asks: []exchange.PriceLevel{
+			{Price: "100.5", Quantity: "1"},
+			{Price: "109", Quantity: "2"},
+			{Price: "110.1", Quantity: "3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	bids, asks := ob.Aggregate(decimal.NewFromInt(10))
+
+	expectedBids := []string{"90:3", "80:4"}
+	expectedAsks := []string{"110:3", "120:3"}
+	check := func(name string, levels []types.PriceLevel, expected []string) {
+		if len(levels) != len(expected) {
+			t.Fatalf("%s: expected %d buckets, got %+v", name, len(expected), levels)
+		}
+		for i, level := range levels {
+			if got := level.Price.String() + ":" + level.Quantity.String(); got != expected[i] {
+				t.Errorf("%s bucket %d: expected %s, got %s", name, i, expected[i], got)
+			}
+		}
+	}
+	check("bids", bids, expectedBids)
+	check("asks", asks, expectedAsks)
+
+	snapBids, snapAsks := ob.Snapshot().Aggregate(decimal.NewFromInt(10))
+	check("snapshot bids", snapBids, expectedBids)
+	check("snapshot asks", snapAsks, expectedAsks)
+}
+
+func TestOrderBookDepthCurve(t *testing.T) {
+	ob := New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{
+			{Price: "99", Quantity: "1"},
+			{Price: "98", Quantity: "2"},
+			{Price: "90", Quantity: "5"},
+		},
+		Asks: []exchange.PriceLevel{
+			{Price: "101", Quantity: "1"},
+			{Price: "103", Quantity: "1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	// The 90 bid is 10% from mid and outside a 5% curve
+	bids, asks := ob.DepthCurve(decimal.RequireFromString("0.05"))
+	if len(bids) != 2 || len(asks) != 2 {
+		t.Fatalf("expected 2 points per side within 5%%, got %d and %d", len(bids), len(asks))
+	}
+	if last := bids[1]; !last.Quantity.Equal(decimal.NewFromInt(3)) || !last.Notional.Equal(decimal.NewFromInt(295)) ||
+		!last.Offset.Equal(decimal.RequireFromString("0.02")) {
+		t.Errorf("expected 3 (295 notional) at 2%% on the bid curve, got %+v", last)
+	}
+
+	points := ob.DepthAtOffsets([]decimal.Decimal{decimal.RequireFromString("0.02"), decimal.RequireFromString("0.001")})
+	if len(points) != 2 || !points[0].Pct.Equal(decimal.RequireFromString("0.001")) {
+		t.Fatalf("expected 2 points, narrowest first, got %+v", points)
+	}
+	if !points[0].Bid.IsZero() || !points[0].Ask.IsZero() {
+		t.Errorf("expected nothing within 10bp, got %s/%s", points[0].Bid, points[0].Ask)
+	}
+	if !points[1].Bid.Equal(decimal.NewFromInt(3)) || !points[1].Ask.Equal(decimal.NewFromInt(1)) || !points[1].Delta.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("expected 3 bid and 1 ask within 2%%, got %+v", points[1])
+	}
+
+	if snap := ob.Snapshot().DepthAtOffsets([]decimal.Decimal{decimal.RequireFromString("0.03")}); !snap[0].Ask.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("expected the snapshot curve to hold 2 asks within 3%%, got %s", snap[0].Ask)
+	}
+}
+
+func TestOrderBookStaleness(t *testing.T) {
+	ob := New()
+	snapshot := &exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "1"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "1"}},
+	}
+	if err := ob.LoadSnapshot(snapshot); err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	resyncs := 0
+	getSnapshot := func() (*exchange.Snapshot, error) {
+		resyncs++
+		return snapshot, nil
+	}
+
+	// Staleness alone never resyncs until a threshold is set
+	time.Sleep(5 * time.Millisecond)
+	ob.CheckAndReinitialize(getSnapshot)
+	if resyncs != 0 {
+		t.Fatalf("expected no resync without a threshold")
+	}
+	if stats := ob.GetStats(); stats.LastUpdateTime.IsZero() || stats.Staleness < 5*time.Millisecond {
+		t.Errorf("expected staleness of at least 5ms, got %s", stats.Staleness)
+	}
+
+	ob.SetStaleAfter(time.Millisecond)
+	ob.CheckAndReinitialize(getSnapshot)
+	if resyncs != 1 {
+		t.Fatalf("expected a stale book to resync once, got %d", resyncs)
+	}
+	if stats := ob.GetStats(); stats.Staleness >= 5*time.Millisecond {
+		t.Errorf("expected the fresh snapshot to reset staleness, got %s", stats.Staleness)
+	}
+}
+
+func TestOrderBookCrossPolicies(t *testing.T) {
+	load := func(policy types.CrossPolicy) *OrderBook {
+		ob := New()
+		ob.SetCrossPolicy(policy)
+		err := ob.LoadSnapshot(&exchange.Snapshot{
+			LastUpdateID: 1,
+			Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "1"}},
+			Asks:         []exchange.PriceLevel{{Price: "100", Quantity: "1"}, {Price: "101", Quantity: "1"}},
+		})
+		if err != nil {
+			t.Fatalf("LoadSnapshot failed: %v", err)
+		}
+		ob.ProcessBufferedEvents()
+
+		// A new bid at 100 means the 100 ask should have been deleted
+		ob.HandleDepthUpdate(&exchange.DepthUpdate{
+			FirstUpdateID: 2,
+			FinalUpdateID: 2,
+			PrevUpdateID:  1,
+			Bids:          []exchange.PriceLevel{{Price: "100", Quantity: "2"}},
+		})
+		return ob
+	}
+
+	logged := load(types.CrossLog)
+	if stats := logged.GetStats(); stats.CrossedCount != 1 || !stats.BestAsk.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("log: expected 1 crossing with the book left locked, got %d at ask %s", stats.CrossedCount, stats.BestAsk)
+	}
+
+	trimmed := load(types.CrossTrim)
+	if stats := trimmed.GetStats(); stats.CrossedCount != 1 || !stats.BestBid.Equal(decimal.NewFromInt(100)) || !stats.BestAsk.Equal(decimal.NewFromInt(101)) {
+		t.Errorf("trim: expected the stale ask dropped (100/101), got %s/%s", stats.BestBid, stats.BestAsk)
+	}
+
+	resynced := load(types.CrossResync)
+	select {
+	case <-resynced.ResyncRequests():
+	default:
+		t.Error("resync: expected a resync request")
+	}
+}
+
+func TestParseCrossPolicy(t *testing.T) {
+	if policy, err := types.ParseCrossPolicy(""); err != nil || policy != types.CrossLog {
+		t.Errorf("expected empty policy to default to log, got %q (%v)", policy, err)
+	}
+	if _, err := types.ParseCrossPolicy("ignore"); err == nil {
+		t.Error("expected an unknown policy to be rejected")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	level := func(price, qty string) types.PriceLevel {
+		return types.PriceLevel{Price: decimal.RequireFromString(price), Quantity: decimal.RequireFromString(qty)}
+	}
+	before := &BookSnapshot{
+		Bids: []types.PriceLevel{level("100", "1"), level("99", "2"), level("98", "3")},
+		Asks: []types.PriceLevel{level("101", "1")},
+	}
+	after := &BookSnapshot{
+		Bids: []types.PriceLevel{level("100.5", "4"), level("100", "1"), level("99", "1.5")},
+		Asks: []types.PriceLevel{level("101", "3")},
+	}
+
+	diff := Diff(before, after)
+
+	bids := diff.Bids
+	if len(bids.Added) != 1 || !bids.Added[0].Price.Equal(decimal.RequireFromString("100.5")) {
+		t.Errorf("expected 100.5 added, got %+v", bids.Added)
+	}
+	if len(bids.Removed) != 1 || !bids.Removed[0].Price.Equal(decimal.NewFromInt(98)) {
+		t.Errorf("expected 98 removed, got %+v", bids.Removed)
+	}
+	if len(bids.Changed) != 1 || !bids.Changed[0].Delta.Equal(decimal.RequireFromString("-0.5")) {
+		t.Errorf("expected 99 down 0.5 as the only change, got %+v", bids.Changed)
+	}
+	if !bids.Placed.Equal(decimal.NewFromInt(4)) || !bids.Pulled.Equal(decimal.RequireFromString("3.5")) || !bids.NetFlow.Equal(decimal.RequireFromString("0.5")) {
+		t.Errorf("expected bids placed 4, pulled 3.5, net 0.5, got %s, %s, %s", bids.Placed, bids.Pulled, bids.NetFlow)
+	}
+
+	if !diff.Asks.NetFlow.Equal(decimal.NewFromInt(2)) || len(diff.Asks.Changed) != 1 {
+		t.Errorf("expected asks net flow 2 from one change, got %s (%+v)", diff.Asks.NetFlow, diff.Asks.Changed)
+	}
+}
+
+func TestOrderBookRolling(t *testing.T) {
+	ob := New()
+	ob.EnableRolling(3 * time.Second)
+
+	start := time.Now()
+	sample := func(offset time.Duration, bid, ask string) {
+		ob.mu.Lock()
+		defer ob.mu.Unlock()
+		ob.bestBid = decimal.RequireFromString(bid)
+		ob.bestAsk = decimal.RequireFromString(ask)
+		ob.sampleRolling(start.Add(offset))
+		ob.publish()
+	}
+
+	sample(0, "99", "101")
+	sample(500*time.Millisecond, "10", "20") // Within the sample interval, ignored
+	sample(time.Second, "99", "103")
+	sample(2*time.Second, "99", "101")
+
+	rolling := ob.GetStats().Rolling
+	if rolling.Samples != 3 || !rolling.AvgSpread.Round(6).Equal(decimal.RequireFromString("2.666667")) {
+		t.Fatalf("expected 3 samples averaging 2.666667, got %d averaging %s", rolling.Samples, rolling.AvgSpread)
+	}
+	if !rolling.SpreadVolatility.IsPositive() || !rolling.MidReturnVolatility.IsPositive() {
+		t.Errorf("expected positive volatilities, got %s and %s", rolling.SpreadVolatility, rolling.MidReturnVolatility)
+	}
+
+	// Samples older than the window fall out, leaving two identical spreads
+	sample(4*time.Second+500*time.Millisecond, "99", "101")
+	rolling = ob.GetStats().Rolling
+	if rolling.Samples != 2 || !rolling.AvgSpread.Equal(decimal.NewFromInt(2)) || !rolling.SpreadVolatility.IsZero() {
+		t.Errorf("expected 2 samples of spread 2, got %d averaging %s (σ %s)", rolling.Samples, rolling.AvgSpread, rolling.SpreadVolatility)
+	}
+}
+
+func TestOrderBookOFI(t *testing.T) {
+	ob := New()
+	ob.EnableOFI([]time.Duration{time.Hour, 2 * time.Second})
+	snapshot := &exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "100", Quantity: "2"}, {Price: "99", Quantity: "1"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "3"}, {Price: "102", Quantity: "1"}},
+	}
+	if err := ob.LoadSnapshot(snapshot); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	if ofi := ob.GetStats().OFI; len(ofi) != 0 {
+		t.Fatalf("expected no OFI from the snapshot alone, got %+v", ofi)
+	}
+
+	for i, update := range []exchange.DepthUpdate{
+		{Bids: []exchange.PriceLevel{{Price: "100", Quantity: "5"}}}, // Bid size up 3: +3
+		{Asks: []exchange.PriceLevel{{Price: "101", Quantity: "0"}}}, // Best ask pulled: +3
+		{Bids: []exchange.PriceLevel{{Price: "100", Quantity: "0"}}}, // Best bid of 5 gone: -5
+	} {
+		update.FirstUpdateID, update.FinalUpdateID, update.PrevUpdateID = int64(i+2), int64(i+2), int64(i+1)
+		ob.HandleDepthUpdate(&update)
+	}
+
+	ofi := ob.GetStats().OFI
+	if len(ofi) != 2 || ofi[0].Window != 2*time.Second || ofi[1].Window != time.Hour {
+		t.Fatalf("expected both windows, shortest first, got %+v", ofi)
+	}
+	if !ofi[1].OFI.Equal(decimal.NewFromInt(1)) || ofi[1].Updates != 3 {
+		t.Errorf("expected OFI 1 from 3 updates over the hour, got %s from %d", ofi[1].OFI, ofi[1].Updates)
+	}
+
+	// An unchanged top adds nothing, and the short window drops the earlier updates
+	ob.mu.Lock()
+	ob.sampleOFI(time.Now().Add(5 * time.Second))
+	ob.publish()
+	ob.mu.Unlock()
+	ofi = ob.GetStats().OFI
+	if !ofi[0].OFI.IsZero() || ofi[0].Updates != 1 {
+		t.Errorf("expected only the unchanged top in the short window, got %s from %d", ofi[0].OFI, ofi[0].Updates)
+	}
+	if !ofi[1].OFI.Equal(decimal.NewFromInt(1)) || ofi[1].Updates != 4 {
+		t.Errorf("expected OFI 1 from 4 updates over the hour, got %s from %d", ofi[1].OFI, ofi[1].Updates)
+	}
+
+	// A fresh snapshot is not order flow
+	snapshot.LastUpdateID = 10
+	snapshot.Bids = []exchange.PriceLevel{{Price: "90", Quantity: "50"}}
+	if err := ob.LoadSnapshot(snapshot); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	if ofi = ob.GetStats().OFI; ofi[1].Updates != 4 {
+		t.Errorf("expected the snapshot to add no update, got %d", ofi[1].Updates)
+	}
+
+	ob.EnableOFI(nil)
+	if ofi = ob.GetStats().OFI; len(ofi) != 0 {
+		t.Errorf("expected no OFI once disabled, got %+v", ofi)
+	}
+}
+
+func TestOrderBookTouch(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	ob := New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "100", Quantity: "2"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "4"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	touch := ob.TrackTouch()
+
+	// 2 rests at the bid for a second, then 1 for three
+	clock = clock.Add(time.Second)
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{FirstUpdateID: 2, FinalUpdateID: 2, PrevUpdateID: 1, Bids: []exchange.PriceLevel{{Price: "100", Quantity: "1"}}})
+	clock = clock.Add(3 * time.Second)
+	stats, ok := touch.Take()
+	if !ok || stats.Updates != 1 || stats.Interval != 4*time.Second {
+		t.Fatalf("expected one update over 4s, got %+v", stats)
+	}
+	if !stats.Bid.Mean.Equal(decimal.NewFromFloat(1.5)) || !stats.Bid.Min.Equal(decimal.NewFromInt(1)) || !stats.Bid.TimeWeighted.Equal(decimal.NewFromFloat(1.25)) {
+		t.Errorf("unexpected bid touch %+v", stats.Bid)
+	}
+	if !stats.Ask.Mean.Equal(decimal.NewFromInt(4)) || !stats.Ask.TimeWeighted.Equal(decimal.NewFromInt(4)) {
+		t.Errorf("unexpected ask touch %+v", stats.Ask)
+	}
+
+	// A quiet interval still sees the resting sizes
+	clock = clock.Add(2 * time.Second)
+	if stats, ok = touch.Take(); !ok || stats.Updates != 0 || !stats.Bid.Min.Equal(decimal.NewFromInt(1)) || !stats.Bid.TimeWeighted.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the resting bid of 1 over a quiet interval, got %+v", stats)
+	}
+
+	ob.UntrackTouch(touch)
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{FirstUpdateID: 3, FinalUpdateID: 3, PrevUpdateID: 2, Bids: []exchange.PriceLevel{{Price: "100", Quantity: "9"}}})
+	if stats, _ = touch.Take(); stats.Updates != 0 || !stats.Bid.Mean.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected no updates once untracked, got %+v", stats)
+	}
+}
+
+func TestOrderBookSamplesOnBookClock(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	ob := New()
+	ob.EnableRolling(time.Minute)
+	ob.EnableOFI([]time.Duration{time.Minute})
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "100", Quantity: "2"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "4"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	// Seconds apart on the book clock, though not on the wall clock, so each update is sampled
+	for i := int64(2); i <= 4; i++ {
+		clock = clock.Add(time.Second)
+		ob.HandleDepthUpdate(&exchange.DepthUpdate{FirstUpdateID: i, FinalUpdateID: i, PrevUpdateID: i - 1, Bids: []exchange.PriceLevel{{Price: "100", Quantity: decimal.NewFromInt(i).String()}}})
+	}
+
+	stats := ob.GetStats()
+	if stats.Rolling.Samples != 4 {
+		t.Errorf("expected rolling samples of the snapshot and 3 updates, got %d", stats.Rolling.Samples)
+	}
+
+	// Two minutes later only the newest update is still within the OFI window
+	clock = clock.Add(2 * time.Minute)
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{FirstUpdateID: 5, FinalUpdateID: 5, PrevUpdateID: 4, Bids: []exchange.PriceLevel{{Price: "100", Quantity: "6"}}})
+	if ofi := ob.GetStats().OFI; len(ofi) != 1 || ofi[0].Updates != 1 {
+		t.Errorf("expected 1 update in the OFI window, got %+v", ofi)
+	}
+}
+
+func TestOrderBookLiquidityAge(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	ob := New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "1"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	// A minute later a new bid appears and the old one changes size twice
+	clock = clock.Add(time.Minute)
+	for i, qty := range []string{"2", "3"} {
+		ob.HandleDepthUpdate(&exchange.DepthUpdate{
+			FirstUpdateID: int64(i + 2),
+			FinalUpdateID: int64(i + 2),
+			PrevUpdateID:  int64(i + 1),
+			Bids:          []exchange.PriceLevel{{Price: "99", Quantity: qty}, {Price: "98", Quantity: "1"}},
+		})
+	}
+	clock = clock.Add(5 * time.Second)
+
+	bids, _ := ob.LevelMetadata(0)
+	if len(bids) != 2 || bids[0].Updates != 2 || bids[0].Age != 65*time.Second || bids[1].Age != 5*time.Second {
+		t.Fatalf("unexpected bid metadata: %+v", bids)
+	}
+
+	dist := ob.LiquidityAge(0, []time.Duration{time.Minute, 10 * time.Second})
+	if len(dist.Buckets) != 3 || dist.Buckets[0].MaxAge != 10*time.Second {
+		t.Fatalf("expected buckets <10s, <1m and older, got %+v", dist.Buckets)
+	}
+	if dist.Buckets[0].BidLevels != 1 || dist.Buckets[2].BidLevels != 1 || !dist.Buckets[2].BidQty.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("expected the new bid under 10s and 3 resting for over a minute, got %+v", dist.Buckets)
+	}
+	if !dist.Buckets[2].AskQty.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the untouched ask in the oldest bucket, got %+v", dist.Buckets[2])
+	}
+
+	// (3 * 65s + 1 * 5s) / 4
+	if dist.BidMeanAge != 50*time.Second {
+		t.Errorf("expected size-weighted bid age 50s, got %s", dist.BidMeanAge)
+	}
+}
+
+func TestOrderBookConcurrentReaders(t *testing.T) {
+	ob := New()
+	if ob.IsInitialized() || ob.GetBufferLength() != 0 {
+		t.Fatal("expected a fresh book to be uninitialized with an empty buffer")
+	}
+
+	ob.HandleDepthUpdate(&exchange.DepthUpdate{FirstUpdateID: 2, FinalUpdateID: 2, PrevUpdateID: 1})
+	if ob.GetBufferLength() != 1 || ob.GetStats().BufferedEvents != 1 {
+		t.Fatalf("expected the buffered update to be visible to readers, got %d", ob.GetBufferLength())
+	}
+
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "1"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				stats := ob.GetStats()
+				bid, okBid := ob.BestBid()
+				ask, okAsk := ob.BestAsk()
+				if okBid && okAsk && !bid.Price.LessThan(ask.Price) {
+					t.Errorf("reader saw a crossed top of book: %s / %s", bid.Price, ask.Price)
+					return
+				}
+				if stats.BestBid.IsPositive() && stats.BestAsk.IsPositive() && !stats.BestBid.LessThan(stats.BestAsk) {
+					t.Errorf("reader saw crossed stats: %s / %s", stats.BestBid, stats.BestAsk)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := int64(3); i < 500; i++ {
+		ob.HandleDepthUpdate(&exchange.DepthUpdate{
+			FirstUpdateID: i,
+			FinalUpdateID: i,
+			PrevUpdateID:  i - 1,
+			Bids:          []exchange.PriceLevel{{Price: decimal.NewFromInt(90 + i%9).String(), Quantity: "1"}},
+			Asks:          []exchange.PriceLevel{{Price: decimal.NewFromInt(101 + i%9).String(), Quantity: "1"}},
+		})
+	}
+	close(stop)
+	wg.Wait()
+
+	stats := ob.GetStats()
+	bid, _ := ob.BestBid()
+	if ob.Snapshot().LastUpdateID != 499 || !bid.Price.Equal(stats.BestBid) {
+		t.Errorf("expected readers to see the last update, got best bid %s vs %s", bid.Price, stats.BestBid)
+	}
+}
+
+func TestOrderBookFixedPoint(t *testing.T) {
+	scale := types.FixedScale{PriceDecimals: 2, QtyDecimals: 4}
+	fixed := New()
+	fixed.SetFixedPoint(scale)
+	reference := New()
+
+	snapshot := &exchange.Snapshot{
+		LastUpdateID: 1,
+		Bids:         []exchange.PriceLevel{{Price: "99.50", Quantity: "1.5"}, {Price: "99", Quantity: "2"}},
+		Asks:         []exchange.PriceLevel{{Price: "100.5", Quantity: "0.25"}, {Price: "101.25", Quantity: "3"}},
+	}
+	for _, ob := range []*OrderBook{fixed, reference} {
+		if err := ob.LoadSnapshot(snapshot); err != nil {
+			t.Fatalf("LoadSnapshot failed: %v", err)
+		}
+		ob.ProcessBufferedEvents()
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for i := int64(2); i < 2000; i++ {
+		update := &exchange.DepthUpdate{FirstUpdateID: i, FinalUpdateID: i, PrevUpdateID: i - 1}
+		for j := 0; j < 3; j++ {
+			qty := decimal.New(rng.Int63n(40000), -4).String() // Zero removes the level
+			update.Bids = append(update.Bids, exchange.PriceLevel{Price: decimal.New(9000+rng.Int63n(1000), -2).String(), Quantity: qty})
+			update.Asks = append(update.Asks, exchange.PriceLevel{Price: decimal.New(10050+rng.Int63n(1000), -2).String(), Quantity: qty})
+		}
+		fixed.HandleDepthUpdate(update)
+		reference.HandleDepthUpdate(update)
+	}
+
+	got, want := fixed.GetStats(), reference.GetStats()
+	if got.BidLevels != want.BidLevels || got.AskLevels != want.AskLevels ||
+		!got.BestBid.Equal(want.BestBid) || !got.BestAsk.Equal(want.BestAsk) {
+		t.Fatalf("fixed book diverged: %d/%d levels at %s/%s, expected %d/%d at %s/%s",
+			got.BidLevels, got.AskLevels, got.BestBid, got.BestAsk, want.BidLevels, want.AskLevels, want.BestBid, want.BestAsk)
+	}
+	if !got.TotalBidsQty.Equal(want.TotalBidsQty) || !got.TotalAsksNotional.Equal(want.TotalAsksNotional) ||
+		!got.BidLiquidity2Pct.Equal(want.BidLiquidity2Pct) || !got.AskNotional10Pct.Equal(want.AskNotional10Pct) {
+		t.Errorf("fixed totals diverged: %s/%s/%s/%s, expected %s/%s/%s/%s",
+			got.TotalBidsQty, got.TotalAsksNotional, got.BidLiquidity2Pct, got.AskNotional10Pct,
+			want.TotalBidsQty, want.TotalAsksNotional, want.BidLiquidity2Pct, want.AskNotional10Pct)
+	}
+
+	gotBids, wantBids := fixed.GetBidLevels(0), reference.GetBidLevels(0)
+	for i := range wantBids {
+		if !gotBids[i].Price.Equal(wantBids[i].Price) || !gotBids[i].Quantity.Equal(wantBids[i].Quantity) {
+			t.Fatalf("bid %d: got %s@%s, expected %s@%s", i, gotBids[i].Quantity, gotBids[i].Price, wantBids[i].Quantity, wantBids[i].Price)
+		}
+	}
+
+	// Prices finer than the scale are rejected rather than rounded into another level
+	err := fixed.LoadSnapshot(&exchange.Snapshot{LastUpdateID: 1, Bids: []exchange.PriceLevel{{Price: "99.505", Quantity: "1"}}})
+	if err == nil {
+		t.Error("expected a snapshot price with 3 decimals to be rejected at 2")
+	}
+}
+
+func TestFixedScaleParse(t *testing.T) {
+	scale := types.FixedScale{PriceDecimals: 2, QtyDecimals: 8}
+	tests := []struct {
+		input    string
+		expected int64
+		ok       bool
+	}{
+		{"123.45", 12345, true},
+		{"123", 12300, true},
+		{"0.5", 50, true},
+		{".5", 50, true},
+		{"-1.25", -125, true},
+		{"1.2500000", 125, true}, // Trailing zeros past the scale are fine
+		{"1.234", 0, false},
+		{"1e2", 10000, true},
+		{"", 0, false},
+		{"abc", 0, false},
+		{"99999999999999999999", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := scale.ParsePrice(tt.input)
+		if (err == nil) != tt.ok || got != tt.expected {
+			t.Errorf("ParsePrice(%q) = %d, %v; expected %d, ok %t", tt.input, got, err, tt.expected, tt.ok)
+		}
+	}
+
+	// Sums of ticks * lots overflow int64 but not the wide accumulator
+	var sum wideInt
+	sum.AddProduct(1<<40, 1<<40)
+	sum.AddProduct(1<<40, -(1 << 39))
+	if want := decimal.NewFromInt(1 << 40).Mul(decimal.NewFromInt(1 << 39)); !sum.Decimal(0).Equal(want) {
+		t.Errorf("expected %s, got %s", want, sum.Decimal(0))
+	}
+}
+
+func BenchmarkHandleDepthUpdate(b *testing.B) {
+	run := func(b *testing.B, ob *OrderBook) {
+		snapshot := &exchange.Snapshot{LastUpdateID: 1}
+		for i := 0; i < 500; i++ {
+			snapshot.Bids = append(snapshot.Bids, exchange.PriceLevel{Price: decimal.New(50000_00-int64(i), -2).String(), Quantity: "1.5"})
+			snapshot.Asks = append(snapshot.Asks, exchange.PriceLevel{Price: decimal.New(50001_00+int64(i), -2).String(), Quantity: "1.5"})
+		}
+		if err := ob.LoadSnapshot(snapshot); err != nil {
+			b.Fatalf("LoadSnapshot failed: %v", err)
+		}
+		ob.ProcessBufferedEvents()
+
+		rng := rand.New(rand.NewSource(1))
+		updates := make([]*exchange.DepthUpdate, 1024)
+		for i := range updates {
+			updates[i] = &exchange.DepthUpdate{
+				Bids: []exchange.PriceLevel{{Price: decimal.New(50000_00-rng.Int63n(500), -2).String(), Quantity: decimal.New(rng.Int63n(30000), -4).String()}},
+				Asks: []exchange.PriceLevel{{Price: decimal.New(50001_00+rng.Int63n(500), -2).String(), Quantity: decimal.New(rng.Int63n(30000), -4).String()}},
+			}
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			update := updates[i%len(updates)]
+			id := int64(i) + 2
+			update.FirstUpdateID, update.FinalUpdateID, update.PrevUpdateID = id, id, id-1
+			ob.HandleDepthUpdate(update)
+		}
+	}
+
+	b.Run("decimal", func(b *testing.B) {
+		run(b, New())
+	})
+	b.Run("fixed", func(b *testing.B) {
+		ob := New()
+		ob.SetFixedPoint(types.DefaultFixedScale)
+		run(b, ob)
+	})
+}
@@ -0,0 +1,145 @@
+package orderbook
+
+import (
+	"math"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// rollingSampleInterval is the minimum spacing of rolling window samples, so busy books
+// keep a bounded ring regardless of their update rate
+const rollingSampleInterval = time.Second
+
+// rollingSample is the top of book at one point in the window
+type rollingSample struct {
+	at     time.Time
+	spread float64
+	ret    float64 // Log return of mid since the previous sample, 0 for the first
+}
+
+// rollingWindow keeps top-of-book samples for a trailing window in a ring buffer,
+// with running sums so the summary is O(1) per update
+type rollingWindow struct {
+	window  time.Duration
+	samples []rollingSample
+	head    int // Index of the oldest sample
+	size    int
+	lastMid float64
+
+	spreadSum, spreadSq float64
+	retSum, retSq       float64
+	returns             int // Samples contributing a return
+}
+
+// newRollingWindow sizes the ring for one sample per interval across the window
+func newRollingWindow(window time.Duration) *rollingWindow {
+	capacity := int(window/rollingSampleInterval) + 1
+	return &rollingWindow{window: window, samples: make([]rollingSample, capacity)}
+}
+
+// EnableRolling keeps rolling spread and mid statistics over the trailing window,
+// reported in Stats.Rolling; a window <= 0 turns them off
+func (ob *OrderBook) EnableRolling(window time.Duration) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.rolling = nil
+	ob.stats.Rolling = types.RollingStats{}
+	if window > 0 {
+		ob.rolling = newRollingWindow(window)
+	}
+	ob.publish()
+}
+
+// sampleRolling records the top of book at most once per sample interval (must be called with mutex locked)
+func (ob *OrderBook) sampleRolling(now time.Time) {
+	w := ob.rolling
+	if w == nil || ob.bestBid.IsZero() || ob.bestAsk.IsZero() || !ob.bestAsk.GreaterThan(ob.bestBid) {
+		return
+	}
+	if w.size > 0 && now.Sub(w.newest().at) < rollingSampleInterval {
+		return
+	}
+
+	for w.size > 0 && (w.size == len(w.samples) || now.Sub(w.oldest().at) > w.window) {
+		w.evict()
+	}
+
+	mid := ob.bestBid.Add(ob.bestAsk).Div(decimal.NewFromInt(2)).InexactFloat64()
+	sample := rollingSample{at: now, spread: ob.bestAsk.Sub(ob.bestBid).InexactFloat64()}
+	if w.lastMid > 0 && w.size > 0 {
+		sample.ret = math.Log(mid / w.lastMid)
+		w.retSum += sample.ret
+		w.retSq += sample.ret * sample.ret
+		w.returns++
+	}
+	w.lastMid = mid
+
+	w.samples[(w.head+w.size)%len(w.samples)] = sample
+	w.size++
+	w.spreadSum += sample.spread
+	w.spreadSq += sample.spread * sample.spread
+
+	ob.stats.Rolling = w.summary()
+}
+
+// oldest returns the first sample in the window
+func (w *rollingWindow) oldest() rollingSample {
+	return w.samples[w.head]
+}
+
+// newest returns the last sample in the window
+func (w *rollingWindow) newest() rollingSample {
+	return w.samples[(w.head+w.size-1)%len(w.samples)]
+}
+
+// evict drops the oldest sample and its share of the running sums
+func (w *rollingWindow) evict() {
+	sample := w.oldest()
+	w.spreadSum -= sample.spread
+	w.spreadSq -= sample.spread * sample.spread
+
+	// The next sample's return was measured against this one and now opens the window
+	w.head = (w.head + 1) % len(w.samples)
+	w.size--
+	if w.size > 0 && w.returns > 0 {
+		next := &w.samples[w.head]
+		w.retSum -= next.ret
+		w.retSq -= next.ret * next.ret
+		w.returns--
+		next.ret = 0
+	}
+}
+
+// summary converts the running sums into the reported statistics
+func (w *rollingWindow) summary() types.RollingStats {
+	stats := types.RollingStats{
+		Window:  w.window,
+		Samples: w.size,
+	}
+	if w.size == 0 {
+		return stats
+	}
+
+	meanSpread := w.spreadSum / float64(w.size)
+	stats.AvgSpread = decimal.NewFromFloat(meanSpread)
+	stats.SpreadVolatility = decimal.NewFromFloat(stdDev(w.spreadSum, w.spreadSq, w.size))
+	stats.MidReturnVolatility = decimal.NewFromFloat(stdDev(w.retSum, w.retSq, w.returns))
+	return stats
+}
+
+// stdDev returns the population standard deviation from a running sum and sum of squares
+func stdDev(sum, sumSq float64, n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	mean := sum / float64(n)
+	variance := sumSq/float64(n) - mean*mean
+	if variance <= 0 {
+		return 0 // Rounding in the running sums can dip just below zero
+	}
+	return math.Sqrt(variance)
+}
@@ -0,0 +1,77 @@
+package orderbook
+
+import (
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// BookSnapshot is an immutable point-in-time copy of an order book
+// Snapshots are shared between readers, so their slices must not be modified
+type BookSnapshot struct {
+	Bids         []types.PriceLevel // Best (highest) price first
+	Asks         []types.PriceLevel // Best (lowest) price first
+	Stats        types.Stats        // Staleness is as of TakenAt
+	LastUpdateID int64
+	Initialized  bool
+	Version      uint64    // Increases with every change to the book
+	TakenAt      time.Time // When the copy was made
+}
+
+// Snapshot returns a consistent copy of the book and its stats
+// The copy is built on the first read after a change and then shared, so readers
+// that poll faster than the book updates neither copy again nor take the lock
+func (ob *OrderBook) Snapshot() *BookSnapshot {
+	if snap := ob.published.Load(); snap != nil && snap.Version == ob.version.Load() {
+		return snap
+	}
+
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	// Another reader may have published this version while we waited for the lock
+	version := ob.version.Load()
+	if snap := ob.published.Load(); snap != nil && snap.Version == version {
+		return snap
+	}
+
+	snap := &BookSnapshot{
+		Bids:         ob.bids.Top(0),
+		Asks:         ob.asks.Top(0),
+		Stats:        ob.stats,
+		LastUpdateID: ob.lastUpdateID,
+		Initialized:  ob.initialized,
+		Version:      version,
+		TakenAt:      time.Now(),
+	}
+	snap.Stats.Staleness = staleness(ob.stats.LastUpdateTime)
+	snap.Stats.Bands = append([]types.BandLiquidity(nil), ob.stats.Bands...)
+	snap.Stats.Imbalances = append([]types.DepthImbalance(nil), ob.stats.Imbalances...)
+
+	ob.published.Store(snap)
+	return snap
+}
+
+// MidPrice returns the mid of the best bid and ask, or zero when either side is empty
+func (s *BookSnapshot) MidPrice() decimal.Decimal {
+	if len(s.Bids) == 0 || len(s.Asks) == 0 {
+		return decimal.Zero
+	}
+	return s.Bids[0].Price.Add(s.Asks[0].Price).Div(decimal.NewFromInt(2))
+}
+
+// CoversDepth reports whether both sides reach at least pct (0.02 = 2%) away from mid,
+// like OrderBook.CoversDepth but for the copied levels
+func (s *BookSnapshot) CoversDepth(pct float64) bool {
+	midPrice := s.MidPrice()
+	if midPrice.IsZero() {
+		return false
+	}
+
+	threshold := midPrice.Mul(decimal.NewFromFloat(pct))
+	deepestBid := s.Bids[len(s.Bids)-1].Price
+	deepestAsk := s.Asks[len(s.Asks)-1].Price
+	return deepestBid.LessThanOrEqual(midPrice.Sub(threshold)) && deepestAsk.GreaterThanOrEqual(midPrice.Add(threshold))
+}
@@ -0,0 +1,155 @@
+package orderbook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// touchSide accumulates the size at the best price of one side over an interval
+type touchSide struct {
+	sum      float64 // Of the sizes seen
+	min      float64
+	weighted float64 // Size times the seconds it rested
+}
+
+// add records a size seen at the touch
+func (s *touchSide) add(qty float64, first bool) {
+	s.sum += qty
+	if first || qty < s.min {
+		s.min = qty
+	}
+}
+
+// summary converts the sums of an interval into the reported size
+func (s *touchSide) summary(samples int, elapsed time.Duration) types.TouchSize {
+	mean := s.sum / float64(samples)
+	weighted := mean
+	if elapsed > 0 {
+		weighted = s.weighted / elapsed.Seconds()
+	}
+	return types.TouchSize{Mean: decimal.NewFromFloat(mean), Min: decimal.NewFromFloat(s.min), TimeWeighted: decimal.NewFromFloat(weighted)}
+}
+
+// TouchTracker accumulates the size at the best bid and ask of a book between reads, so every
+// reader summarizes the touch over its own interval. Get one from TrackTouch
+type TouchTracker struct {
+	mu             sync.Mutex
+	since          time.Time // Start of the interval
+	at             time.Time // When the resting sizes took over
+	live           bool      // The book has an uncrossed top
+	bidQty, askQty float64   // Resting at the touch
+	bid, ask       touchSide
+	samples        int           // Sizes seen within the interval
+	updates        int           // Updates with an uncrossed top within the interval
+	elapsed        time.Duration // Time with an uncrossed top within the interval
+}
+
+// TrackTouch starts accumulating the size at the best bid and ask on every update; read it with
+// TouchTracker.Take and release it with UntrackTouch
+func (ob *OrderBook) TrackTouch() *TouchTracker {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	at := now()
+	t := &TouchTracker{since: at, at: at}
+	if bidQty, askQty, live := ob.touchSizes(); live {
+		t.rest(bidQty, askQty)
+	}
+	ob.touch = append(ob.touch, t)
+	return t
+}
+
+// UntrackTouch stops feeding a tracker returned by TrackTouch
+func (ob *OrderBook) UntrackTouch(t *TouchTracker) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	for i, tracked := range ob.touch {
+		if tracked == t {
+			ob.touch = append(ob.touch[:i], ob.touch[i+1:]...)
+			return
+		}
+	}
+}
+
+// touchSizes returns the sizes at the best bid and ask; live is false when a side is empty or
+// the book is crossed (must be called with mutex locked)
+func (ob *OrderBook) touchSizes() (bidQty, askQty float64, live bool) {
+	bid, okBid := ob.bids.Best()
+	ask, okAsk := ob.asks.Best()
+	if !okBid || !okAsk || !ask.Price.GreaterThan(bid.Price) {
+		return 0, 0, false
+	}
+	return bid.Quantity.InexactFloat64(), ask.Quantity.InexactFloat64(), true
+}
+
+// sampleTouch hands the sizes at the touch after an update to every tracker (must be called with mutex locked)
+func (ob *OrderBook) sampleTouch(at time.Time) {
+	if len(ob.touch) == 0 {
+		return
+	}
+	bidQty, askQty, live := ob.touchSizes()
+	for _, t := range ob.touch {
+		t.observe(at, bidQty, askQty, live)
+	}
+}
+
+// observe weights the sizes that rested until now, then records the new ones
+func (t *TouchTracker) observe(at time.Time, bidQty, askQty float64, live bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.accrue(at)
+	t.live = false
+	if live {
+		t.rest(bidQty, askQty)
+		t.updates++
+	}
+}
+
+// rest records the sizes now resting at the touch
+func (t *TouchTracker) rest(bidQty, askQty float64) {
+	t.live = true
+	t.bidQty, t.askQty = bidQty, askQty
+	t.bid.add(bidQty, t.samples == 0)
+	t.ask.add(askQty, t.samples == 0)
+	t.samples++
+}
+
+// accrue weights the resting sizes by the time since they took over
+func (t *TouchTracker) accrue(at time.Time) {
+	if rested := at.Sub(t.at); t.live && rested > 0 {
+		t.bid.weighted += t.bidQty * rested.Seconds()
+		t.ask.weighted += t.askQty * rested.Seconds()
+		t.elapsed += rested
+	}
+	t.at = at
+}
+
+// Take returns the touch since the previous Take, or since TrackTouch, and starts a new interval
+// with the sizes still resting; ok is false when the book had no uncrossed top in the interval
+func (t *TouchTracker) Take() (types.TouchStats, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	at := now()
+	t.accrue(at)
+	stats := types.TouchStats{Updates: t.updates, Interval: at.Sub(t.since)}
+	ok := t.samples > 0
+	if ok {
+		stats.Bid = t.bid.summary(t.samples, t.elapsed)
+		stats.Ask = t.ask.summary(t.samples, t.elapsed)
+	}
+
+	t.since = at
+	t.bid, t.ask = touchSide{}, touchSide{}
+	t.samples, t.updates, t.elapsed = 0, 0, 0
+	if t.live {
+		t.rest(t.bidQty, t.askQty)
+	}
+	return stats, ok
+}
@@ -0,0 +1,75 @@
+package orderbook
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// SetMaxDepth caps the levels kept per side; levels past the cap are dropped
+// after every snapshot and update and counted in Stats.TruncatedLevels.
+// A dropped level is only restored if the venue sends it again, so the tail of
+// a capped book can be thinner than the venue's. Zero keeps every level
+func (ob *OrderBook) SetMaxDepth(levels int) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.maxDepth = levels
+	ob.truncate()
+	ob.updateStats()
+}
+
+// SetMaxBand drops levels further than pct (0.05 = 5%) from mid, like SetMaxDepth
+// but bounded by price; zero keeps every level
+func (ob *OrderBook) SetMaxBand(pct decimal.Decimal) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	ob.maxBand = pct
+	ob.truncate()
+	ob.updateStats()
+}
+
+// truncate drops the levels past the depth cap and price band from both sides (must be called with mutex locked)
+// The cap never cuts into the levels covered by the venue checksum
+func (ob *OrderBook) truncate() {
+	if ob.maxDepth <= 0 && !ob.maxBand.IsPositive() {
+		return
+	}
+
+	depth := ob.maxDepth
+	if depth > 0 && ob.checksum != nil && depth < ob.checksum.Depth {
+		depth = ob.checksum.Depth
+	}
+
+	var minBid, maxAsk decimal.Decimal
+	bestBid, hasBid := ob.bids.Best()
+	bestAsk, hasAsk := ob.asks.Best()
+	if ob.maxBand.IsPositive() && hasBid && hasAsk {
+		midPrice := bestBid.Price.Add(bestAsk.Price).Div(decimal.NewFromInt(2))
+		threshold := midPrice.Mul(ob.maxBand)
+		minBid = midPrice.Sub(threshold)
+		maxAsk = midPrice.Add(threshold)
+	}
+
+	ob.truncateSide(ob.bids, depth, func(price decimal.Decimal) bool {
+		return !minBid.IsZero() && price.LessThan(minBid)
+	})
+	ob.truncateSide(ob.asks, depth, func(price decimal.Decimal) bool {
+		return !maxAsk.IsZero() && price.GreaterThan(maxAsk)
+	})
+}
+
+// truncateSide drops the worst levels of a side while it is deeper than depth or
+// its worst level is outside the band (must be called with mutex locked)
+func (ob *OrderBook) truncateSide(side *bookSide, depth int, outside func(price decimal.Decimal) bool) {
+	for {
+		worst, ok := side.Worst()
+		if !ok || ((depth <= 0 || side.Len() <= depth) && !outside(worst.Price)) {
+			return
+		}
+
+		side.Delete(worst.Price)
+		if ob.l3 {
+			ob.dropLevelOrders(side.descending, worst.Price)
+		}
+		ob.stats.TruncatedLevels++
+		ob.stats.TruncatedVolume = ob.stats.TruncatedVolume.Add(worst.Quantity)
+	}
+}
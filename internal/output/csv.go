@@ -0,0 +1,94 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/types"
+)
+
+// csvFormatter writes a header on the first round, then a row per exchange and round
+// Band columns follow the bands of the first book; uncovered bands are left empty
+type csvFormatter struct {
+	w          io.Writer
+	staleAfter time.Duration
+	csv        *csv.Writer
+	bands      []string // Band labels with a column, nil until the header is written
+}
+
+func (f *csvFormatter) Format(books []Book) error {
+	books, snapshots := live(books)
+	if len(books) == 0 {
+		return nil
+	}
+	if f.csv == nil {
+		f.csv = csv.NewWriter(f.w)
+		header := []string{"timestamp", "exchange", "symbol", "best_bid", "best_ask", "mid_price", "spread",
+			"total_bids_qty", "total_asks_qty", "total_bids_notional", "total_asks_notional", "microprice"}
+		f.bands = []string{}
+		for _, band := range snapshots[0].Stats.Bands {
+			label := types.BandLabel(band.Pct)
+			f.bands = append(f.bands, label)
+			header = append(header, "bid_"+label, "ask_"+label)
+		}
+		header = append(header, "cvd", "taker_buy_volume", "taker_sell_volume", "funding_rate", "open_interest", "basis_bps", "resiliency_score", "staleness_seconds", "stale")
+		if err := f.csv.Write(header); err != nil {
+			return err
+		}
+	}
+
+	for i, b := range books {
+		book := snapshots[i]
+		stats := book.Stats
+		row := []string{
+			book.TakenAt.UTC().Format(time.RFC3339Nano),
+			b.Exchange,
+			b.Symbol,
+			stats.BestBid.String(),
+			stats.BestAsk.String(),
+			book.MidPrice().String(),
+			stats.Spread.String(),
+			stats.TotalBidsQty.String(),
+			stats.TotalAsksQty.String(),
+			stats.TotalBidsNotional.StringFixed(2),
+			stats.TotalAsksNotional.StringFixed(2),
+			stats.Microprice.String(),
+		}
+		for _, label := range f.bands {
+			bid, ask := "", ""
+			for _, band := range stats.Bands {
+				if types.BandLabel(band.Pct) == label && bandCovered(book, b.Caps, band.Pct.InexactFloat64()) {
+					bid, ask = band.Bid.String(), band.Ask.String()
+				}
+			}
+			row = append(row, bid, ask)
+		}
+		cvd, buys, sells := "", "", ""
+		if b.Flow != nil {
+			cvd, buys, sells = b.Flow.CVD.String(), b.Flow.BuyVolume.String(), b.Flow.SellVolume.String()
+		}
+		row = append(row, cvd, buys, sells)
+		rate, openInterest := "", ""
+		if b.Funding != nil {
+			rate, openInterest = b.Funding.Rate.String(), b.Funding.OpenInterest.String()
+		}
+		basis := ""
+		if b.Basis != nil {
+			basis = b.Basis.BasisBps().String()
+		}
+		row = append(row, rate, openInterest, basis)
+		resiliency := ""
+		if b.Resiliency != nil {
+			resiliency = b.Resiliency.Score.String()
+		}
+		row = append(row, resiliency)
+		row = append(row, strconv.FormatFloat(stats.Staleness.Seconds(), 'f', 3, 64), strconv.FormatBool(stale(book, f.staleAfter)))
+		if err := f.csv.Write(row); err != nil {
+			return err
+		}
+	}
+	f.csv.Flush()
+	return f.csv.Error()
+}
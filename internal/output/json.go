@@ -0,0 +1,71 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+)
+
+// jsonFormatter writes a JSON object per exchange and round, shaped like the stored snapshots
+type jsonFormatter struct {
+	w          io.Writer
+	staleAfter time.Duration
+}
+
+// jsonBook is a stored snapshot with the state of the feed behind it
+type jsonBook struct {
+	*database.OrderbookSnapshotAPI
+	StalenessSeconds float64 `json:"staleness_seconds"`
+	Stale            bool    `json:"stale"`
+	SequenceGaps     int64   `json:"sequence_gaps"`
+	ChecksumFailures int64   `json:"checksum_failures"`
+	CrossedCount     int64   `json:"crossed_count"`
+}
+
+func (f *jsonFormatter) Format(books []Book) error {
+	books, snapshots := live(books)
+	encoder := json.NewEncoder(f.w)
+	for i, b := range books {
+		book := snapshots[i]
+		snapshot := collector.NewSnapshot(b.Exchange, b.Symbol, book, b.Caps)
+		if b.Flow != nil {
+			snapshot.Flow = collector.NewFlow(*b.Flow)
+		}
+		if b.Funding != nil {
+			snapshot.Funding = collector.NewFunding(*b.Funding, book.TakenAt)
+		}
+		if b.Basis != nil {
+			snapshot.Basis = collector.NewBasis(*b.Basis)
+		}
+		if b.Resiliency != nil {
+			snapshot.Resiliency = collector.NewResiliency(*b.Resiliency)
+		}
+		if b.Share != nil {
+			snapshot.MarketShare = collector.NewMarketShare(*b.Share)
+		}
+		if b.Volatility != nil {
+			snapshot.Volatility = collector.NewVolatility(*b.Volatility)
+		}
+		if b.Fees != nil {
+			if spread, ok := analytics.ComputeEffectiveSpread(book, *b.Fees); ok {
+				snapshot.EffectiveSpread = collector.NewEffectiveSpread(spread)
+			}
+		}
+		err := encoder.Encode(jsonBook{
+			OrderbookSnapshotAPI: snapshot,
+			StalenessSeconds:     book.Stats.Staleness.Seconds(),
+			Stale:                stale(book, f.staleAfter),
+			SequenceGaps:         book.Stats.SequenceGaps,
+			ChecksumFailures:     book.Stats.ChecksumFailures,
+			CrossedCount:         book.Stats.CrossedCount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package output renders the periodic stats of every book to the console
+//
+// The colored text is for people watching a terminal; the table is a compact version of
+// it, and JSON lines and CSV are for piping the stats into other programs.
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+)
+
+// Format selects how stats are rendered
+type Format string
+
+const (
+	Text  Format = "text"  // Colored blocks per exchange
+	Table Format = "table" // One aligned row per exchange
+	JSON  Format = "json"  // One JSON object per exchange and round
+	CSV   Format = "csv"   // A header, then one row per exchange and round
+)
+
+// ParseFormat parses an output format name; empty selects Text
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return Text, nil
+	case Text, Table, JSON, CSV:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown output format %q (want text, table, json or csv)", value)
+}
+
+// Book is a book whose stats are rendered
+type Book struct {
+	Exchange   string
+	Symbol     string
+	Book       *orderbook.OrderBook
+	Caps       exchange.Capabilities
+	Flow       *analytics.FlowStats        // Taker flow of the exchange, nil when not tracked or before its first trade
+	Funding    *analytics.FundingStats     // Funding and open interest of a perpetual, nil for other markets
+	Basis      *analytics.BasisStats       // Basis of a perpetual over the spot book of its venue, nil unless both are live
+	Resiliency *analytics.ResiliencyStats  // Replenishment after large depletions, nil when not tracked or before the first sample
+	Spoofing   *analytics.SpoofingStats    // Spoofing and layering counters, nil unless the book is watched
+	Share      *analytics.MarketShareStats // Share of the consolidated liquidity and best quotes, nil when not tracked
+	Volatility *analytics.VolatilityStats  // Realized volatility of mid per window, nil when not tracked or before the first sample
+	Fees       *analytics.FeeSchedule      // Fees of the venue the fee-adjusted spread is computed with, nil when not shown
+}
+
+// Formatter renders one round of stats; books that are not initialized are left out
+type Formatter interface {
+	Format(books []Book) error
+}
+
+// New creates a formatter writing to w; books without updates for staleAfter are flagged,
+// 0 never flags them
+func New(format Format, w io.Writer, staleAfter time.Duration) Formatter {
+	switch format {
+	case Table:
+		return &tableFormatter{w: w, staleAfter: staleAfter}
+	case JSON:
+		return &jsonFormatter{w: w, staleAfter: staleAfter}
+	case CSV:
+		return &csvFormatter{w: w, staleAfter: staleAfter}
+	}
+	return &textFormatter{w: w, staleAfter: staleAfter}
+}
+
+// live copies the initialized books
+func live(books []Book) ([]Book, []*orderbook.BookSnapshot) {
+	var kept []Book
+	var snapshots []*orderbook.BookSnapshot
+	for _, b := range books {
+		if snapshot := b.Book.Snapshot(); snapshot.Initialized {
+			kept = append(kept, b)
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return kept, snapshots
+}
+
+// flows returns the taker flow of the books that have it
+func flows(books []Book) []analytics.FlowStats {
+	var all []analytics.FlowStats
+	for _, b := range books {
+		if b.Flow != nil {
+			all = append(all, *b.Flow)
+		}
+	}
+	return all
+}
+
+// stale reports whether a book has gone without updates for longer than staleAfter
+func stale(snapshot *orderbook.BookSnapshot, staleAfter time.Duration) bool {
+	return staleAfter > 0 && snapshot.Stats.Staleness > staleAfter
+}
+
+// bandCovered reports whether the stats of a band can be trusted: the feed delivers the whole
+// book or the levels reach the band
+func bandCovered(snapshot *orderbook.BookSnapshot, caps exchange.Capabilities, pct float64) bool {
+	return caps.MaxDepth == 0 || snapshot.CoversDepth(pct)
+}
@@ -0,0 +1,311 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+func testBooks(t *testing.T) []Book {
+	t.Helper()
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		Bids: []exchange.PriceLevel{{Price: "99", Quantity: "1"}, {Price: "98", Quantity: "2"}},
+		Asks: []exchange.PriceLevel{{Price: "101", Quantity: "3"}, {Price: "102", Quantity: "4"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	return []Book{
+		{Exchange: "okx", Symbol: "BTCUSDT", Book: ob},
+		{Exchange: "bybit", Symbol: "BTCUSDT", Book: orderbook.New()},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for value, want := range map[string]Format{"": Text, "text": Text, "TABLE": Table, " json ": JSON, "csv": CSV} {
+		if got, err := ParseFormat(value); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(Text, &buf, time.Minute).Format(testBooks(t)); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "okx") || !strings.Contains(out, "100.00") || strings.Contains(out, "bybit") {
+		t.Errorf("unexpected text output:\n%s", out)
+	}
+}
+
+func TestTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(Table, &buf, time.Minute).Format(testBooks(t)); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and a row, got:\n%s", buf.String())
+	}
+	if fields := strings.Fields(lines[0]); fields[0] != "EXCHANGE" {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); fields[0] != "okx" || fields[1] != "100.00" || fields[len(fields)-1] != "ok" {
+		t.Errorf("unexpected row %q", lines[1])
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(JSON, &buf, time.Minute).Format(testBooks(t)); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one line for the initialized book, got:\n%s", buf.String())
+	}
+	var book map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &book); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[0], err)
+	}
+	if book["exchange"] != "okx" || book["symbol"] != "BTCUSDT" || book["mid_price"] != float64(100) || book["stale"] != false {
+		t.Errorf("unexpected object %v", book)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	f := New(CSV, &buf, time.Minute)
+	books := testBooks(t)
+	for range 2 {
+		if err := f.Format(books); err != nil {
+			t.Fatalf("Format() error: %v", err)
+		}
+	}
+
+	// The header is written once, followed by a row per round
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want a header and 2 rows", len(records))
+	}
+	header, row := records[0], records[1]
+	if header[0] != "timestamp" || header[len(header)-1] != "stale" {
+		t.Errorf("unexpected header %v", header)
+	}
+	columns := make(map[string]string, len(header))
+	for i, name := range header {
+		columns[name] = row[i]
+	}
+	if columns["exchange"] != "okx" || columns["mid_price"] != "100" || columns["best_ask"] != "101" || columns["stale"] != "false" {
+		t.Errorf("unexpected row %v", columns)
+	}
+}
+
+func TestFlowOutput(t *testing.T) {
+	books := testBooks(t)
+	books[0].Flow = &analytics.FlowStats{Window: time.Minute, CVD: decimal.NewFromInt(-3), BuyVolume: decimal.NewFromInt(1), SellVolume: decimal.NewFromInt(3)}
+
+	var text bytes.Buffer
+	if err := New(Text, &text, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(text.String(), "FLOW 1m0s") || !strings.Contains(text.String(), "(25% buy)") {
+		t.Errorf("expected the flow of okx in:\n%s", text.String())
+	}
+
+	var rows bytes.Buffer
+	if err := New(CSV, &rows, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	records, err := csv.NewReader(&rows).ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("expected a header and a row, got %v, %v", records, err)
+	}
+	for i, name := range records[0] {
+		if name == "cvd" && records[1][i] != "-3" {
+			t.Errorf("cvd %q, want -3", records[1][i])
+		}
+	}
+}
+
+func TestFundingOutput(t *testing.T) {
+	books := testBooks(t)
+	books[0].Funding = &analytics.FundingStats{
+		Rate:         decimal.RequireFromString("0.0001"),
+		NextFunding:  time.Now().Add(time.Hour),
+		MarkPrice:    decimal.NewFromInt(100),
+		OpenInterest: decimal.NewFromInt(2500),
+		Window:       time.Hour,
+	}
+	books[0].Basis = &analytics.BasisStats{BasisPair: analytics.BasisPair{Spot: "okxspot", Perp: "okx"}, Basis: decimal.RequireFromString("0.00042")}
+
+	var text bytes.Buffer
+	if err := New(Text, &text, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(text.String(), "0.0100%") || !strings.Contains(text.String(), "OI notional: 250.00K") || !strings.Contains(text.String(), "BASIS vs okxspot") {
+		t.Errorf("expected the funding of okx in:\n%s", text.String())
+	}
+
+	var rows bytes.Buffer
+	if err := New(CSV, &rows, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	records, err := csv.NewReader(&rows).ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("expected a header and a row, got %v, %v", records, err)
+	}
+	for i, name := range records[0] {
+		if name == "open_interest" && records[1][i] != "2500" {
+			t.Errorf("open_interest %q, want 2500", records[1][i])
+		}
+	}
+}
+
+func TestResiliencyOutput(t *testing.T) {
+	books := testBooks(t)
+	books[0].Resiliency = &analytics.ResiliencyStats{
+		Score:         decimal.RequireFromString("0.375"),
+		Depletions:    2,
+		Recovered:     1,
+		MeanRecovery:  15 * time.Second,
+		Replenishment: decimal.RequireFromString("0.43"),
+		BidDepth:      decimal.NewFromInt(10),
+		AskDepth:      decimal.NewFromInt(8),
+		Window:        15 * time.Minute,
+	}
+
+	var text bytes.Buffer
+	if err := New(Text, &text, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(text.String(), "RESILIENCY 15m0s") || !strings.Contains(text.String(), "1 recovered, mean 15s") {
+		t.Errorf("expected the resiliency of okx in:\n%s", text.String())
+	}
+
+	var rows bytes.Buffer
+	if err := New(CSV, &rows, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	records, err := csv.NewReader(&rows).ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("expected a header and a row, got %v, %v", records, err)
+	}
+	for i, name := range records[0] {
+		if name == "resiliency_score" && records[1][i] != "0.375" {
+			t.Errorf("resiliency_score %q, want 0.375", records[1][i])
+		}
+	}
+}
+
+func TestMarketShareOutput(t *testing.T) {
+	books := testBooks(t)
+	books[0].Share = &analytics.MarketShareStats{
+		Bands:   []analytics.BandShare{{Pct: decimal.RequireFromString("0.005"), Total: decimal.RequireFromString("0.31")}},
+		BestBid: decimal.RequireFromString("0.45"),
+		BestAsk: decimal.RequireFromString("0.2"),
+		Sampled: 10 * time.Minute,
+	}
+
+	var text bytes.Buffer
+	if err := New(Text, &text, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(text.String(), "SHARE: 0.5%: 31%") || !strings.Contains(text.String(), "of 10m0s") {
+		t.Errorf("expected the market share of okx in:\n%s", text.String())
+	}
+
+	var lines bytes.Buffer
+	if err := New(JSON, &lines, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(lines.String(), `"market_share":{"bands":{"0.5%":{"bid":0,"ask":0,"total":0.31}},"best_bid":0.45`) {
+		t.Errorf("expected the market share of okx in:\n%s", lines.String())
+	}
+}
+
+func TestVolatilityOutput(t *testing.T) {
+	books := testBooks(t)
+	books[0].Volatility = &analytics.VolatilityStats{Windows: []analytics.WindowVolatility{
+		{Window: time.Minute, Returns: 59, Volatility: decimal.RequireFromString("0.0005"), Annualized: decimal.RequireFromString("0.362")},
+		{Window: time.Hour, Returns: 3599, Volatility: decimal.RequireFromString("0.004"), Annualized: decimal.RequireFromString("0.375")},
+	}}
+
+	var text bytes.Buffer
+	if err := New(Text, &text, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(text.String(), "VOLATILITY: 1m: 0.05% (36.2% ann.) │ 1h: 0.40% (37.5% ann.)") {
+		t.Errorf("expected the volatility of okx in:\n%s", text.String())
+	}
+
+	var lines bytes.Buffer
+	if err := New(JSON, &lines, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(lines.String(), `"volatility":{"1h":{"volatility":0.004,"annualized":0.375,"returns":3599}`) {
+		t.Errorf("expected the volatility of okx in:\n%s", lines.String())
+	}
+}
+
+func TestOFIOutput(t *testing.T) {
+	books := testBooks(t)
+	books[0].Book.EnableOFI([]time.Duration{time.Minute})
+	books[0].Book.HandleDepthUpdate(&exchange.DepthUpdate{FirstUpdateID: 1, FinalUpdateID: 1, Bids: []exchange.PriceLevel{{Price: "99", Quantity: "4"}}})
+
+	var text bytes.Buffer
+	if err := New(Text, &text, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(text.String(), "OFI: 1m: "+colorGreen+"+3.00"+colorReset+" over 1 updates") {
+		t.Errorf("expected the OFI of okx in:\n%s", text.String())
+	}
+
+	var lines bytes.Buffer
+	if err := New(JSON, &lines, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(lines.String(), `"ofi":{"1m":{"ofi":3,"updates":1}}`) {
+		t.Errorf("expected the OFI of okx in:\n%s", lines.String())
+	}
+}
+
+func TestFeesOutput(t *testing.T) {
+	books := testBooks(t)
+	books[0].Fees = &analytics.FeeSchedule{Maker: decimal.RequireFromString("0.0002"), Taker: decimal.RequireFromString("0.0005")}
+
+	var text bytes.Buffer
+	if err := New(Text, &text, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(text.String(), "FEES 2.00bp/5.00bp: Raw: 200.00bp │ Taker: "+colorMagenta+"210.00bp") {
+		t.Errorf("expected the fee-adjusted spread of okx in:\n%s", text.String())
+	}
+
+	var lines bytes.Buffer
+	if err := New(JSON, &lines, time.Minute).Format(books); err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	if !strings.Contains(lines.String(), `"effective_spread":{"raw_bps":200,"taker_bps":210,"maker_bps":196,"maker_fee_bps":2,"taker_fee_bps":5}`) {
+		t.Errorf("expected the fee-adjusted spread of okx in:\n%s", lines.String())
+	}
+}
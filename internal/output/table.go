@@ -0,0 +1,106 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// tableFormatter prints an aligned row per exchange with the top of book, totals and the
+// widest configured band
+type tableFormatter struct {
+	w          io.Writer
+	staleAfter time.Duration
+}
+
+func (f *tableFormatter) Format(books []Book) error {
+	books, snapshots := live(books)
+	if len(books) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(f.w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	band := widestBand(snapshots[0])
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "EXCHANGE\tMID\tSPREAD\tBID\tASK\tBID QTY\tASK QTY\tBID %[1]s\tASK %[1]s\tBID NOTIONAL\tASK NOTIONAL\tIMB\tCVD\tBUY\tFUNDING\tOI\tBASIS\tRESIL\tSTATUS\t\n", band)
+	for i, b := range books {
+		book := snapshots[i]
+		stats := book.Stats
+		bandBid, bandAsk := "", ""
+		for _, liquidity := range stats.Bands {
+			if types.BandLabel(liquidity.Pct) == band {
+				covered := bandCovered(book, b.Caps, liquidity.Pct.InexactFloat64())
+				bandBid, bandAsk = bandText(liquidity.Bid, covered), bandText(liquidity.Ask, covered)
+			}
+		}
+		imbalance := ""
+		if len(stats.Imbalances) > 0 {
+			imbalance = stats.Imbalances[0].Imbalance.StringFixed(2)
+		}
+		cvd, buyRatio := "", ""
+		if b.Flow != nil {
+			cvd, buyRatio = b.Flow.CVD.StringFixed(2), b.Flow.BuyRatio().Mul(decimal.NewFromInt(100)).StringFixed(0)+"%"
+		}
+		rate, openInterest := "", ""
+		if b.Funding != nil {
+			rate, openInterest = b.Funding.Rate.Mul(decimal.NewFromInt(100)).StringFixed(4)+"%", b.Funding.OpenInterest.StringFixed(2)
+		}
+		basis := ""
+		if b.Basis != nil {
+			basis = b.Basis.BasisBps().StringFixed(2) + "bp"
+		}
+		resiliency := ""
+		if b.Resiliency != nil {
+			resiliency = b.Resiliency.Score.StringFixed(2)
+		}
+		status := "ok"
+		if stale(book, f.staleAfter) {
+			status = "stale " + stats.Staleness.Round(time.Second).String()
+		} else if stats.SequenceGaps > 0 || stats.ChecksumFailures > 0 || stats.CrossedCount > 0 {
+			status = fmt.Sprintf("gaps %d, checksum %d, crossed %d", stats.SequenceGaps, stats.ChecksumFailures, stats.CrossedCount)
+		}
+		fmt.Fprintln(tw, strings.Join([]string{
+			b.Exchange,
+			book.MidPrice().StringFixed(2),
+			stats.Spread.StringFixed(4),
+			stats.BestBid.StringFixed(2),
+			stats.BestAsk.StringFixed(2),
+			stats.TotalBidsQty.StringFixed(2),
+			stats.TotalAsksQty.StringFixed(2),
+			bandBid,
+			bandAsk,
+			notionalText(stats.TotalBidsNotional),
+			notionalText(stats.TotalAsksNotional),
+			imbalance,
+			cvd,
+			buyRatio,
+			rate,
+			openInterest,
+			basis,
+			resiliency,
+			status,
+		}, "\t")+"\t")
+	}
+	return tw.Flush()
+}
+
+// widestBand returns the label of the widest band of a book, e.g. "2%"
+func widestBand(book *orderbook.BookSnapshot) string {
+	if len(book.Stats.Bands) == 0 {
+		return "-"
+	}
+	widest := book.Stats.Bands[0].Pct
+	for _, band := range book.Stats.Bands {
+		if band.Pct.GreaterThan(widest) {
+			widest = band.Pct
+		}
+	}
+	return types.BandLabel(widest)
+}
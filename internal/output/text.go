@@ -0,0 +1,342 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	colorReset   = "\033[0m"
+	colorYellow  = "\033[33m"
+	colorGreen   = "\033[32m"
+	colorRed     = "\033[31m"
+	colorMagenta = "\033[35m"
+	colorBold    = "\033[1m"
+)
+
+// textFormatter prints a colored block per exchange
+type textFormatter struct {
+	w          io.Writer
+	staleAfter time.Duration
+}
+
+func (f *textFormatter) Format(books []Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	w := f.w
+	fmt.Fprintln(w)
+	f.consolidated(books)
+	f.consolidatedFlow(books)
+
+	for i, b := range books {
+		book := b.Book.Snapshot()
+		if !book.Initialized {
+			continue
+		}
+
+		stats := book.Stats
+		midPrice := stats.BestBid.Add(stats.BestAsk).Div(decimal.NewFromInt(2))
+
+		// print exchange name
+		fmt.Fprintf(w, "%s%s%s", colorBold, b.Exchange, colorReset)
+		// Print exchange header
+		fmt.Fprintf(w, "  Mid: %s%10s%s │ Spread: %s%8s%s | BB: %s%10s%s │ BA: %s%10s%s\n",
+			colorYellow, midPrice.StringFixed(2), colorReset,
+			colorMagenta, stats.Spread.StringFixed(4), colorReset,
+			colorGreen, stats.BestBid.StringFixed(2), colorReset,
+			colorRed, stats.BestAsk.StringFixed(2), colorReset)
+
+		if stale(book, f.staleAfter) {
+			fmt.Fprintf(w, "  %sSTALE: no updates for %s%s\n", colorRed, stats.Staleness.Round(time.Second), colorReset)
+		}
+
+		// Print depth metrics; bands a depth-limited feed does not reach show n/a
+		for _, band := range stats.Bands {
+			covered := bandCovered(book, b.Caps, band.Pct.InexactFloat64())
+			fmt.Fprintf(w, "  DEPTH %-5s Bids: %s%9s%s │ Asks: %s%9s%s │ Δ: %s%10s%s\n",
+				types.BandLabel(band.Pct),
+				colorGreen, bandText(band.Bid, covered), colorReset,
+				colorRed, bandText(band.Ask, covered), colorReset,
+				deltaColor(band.Delta), bandText(band.Delta, covered), colorReset)
+		}
+
+		fmt.Fprintf(w, "  TOTAL QTY: Bids: %s%9s%s │ Asks: %s%9s%s\n",
+			colorGreen, stats.TotalBidsQty.StringFixed(2), colorReset,
+			colorRed, stats.TotalAsksQty.StringFixed(2), colorReset)
+
+		if len(stats.Imbalances) > 0 {
+			fmt.Fprintf(w, "  MICRO: %s%10s%s │ IMB %s\n",
+				colorYellow, stats.Microprice.StringFixed(2), colorReset, imbalanceText(stats.Imbalances))
+		}
+
+		if rolling := stats.Rolling; rolling.Samples > 0 {
+			fmt.Fprintf(w, "  ROLLING %s: Avg spread: %s%s%s │ Spread σ: %s │ Mid σ: %sbp\n",
+				rolling.Window, colorMagenta, rolling.AvgSpread.StringFixed(4), colorReset,
+				rolling.SpreadVolatility.StringFixed(4), rolling.MidReturnVolatility.Mul(decimal.NewFromInt(10000)).StringFixed(2))
+		}
+
+		if b.Fees != nil {
+			if spread, ok := analytics.ComputeEffectiveSpread(book, *b.Fees); ok {
+				fmt.Fprintf(w, "  FEES %s/%s: %s\n", rateBps(spread.Fees.Maker), rateBps(spread.Fees.Taker), effectiveSpreadText(spread))
+			}
+		}
+
+		if len(stats.OFI) > 0 {
+			fmt.Fprintf(w, "  OFI: %s\n", ofiText(stats.OFI))
+		}
+
+		if b.Flow != nil {
+			fmt.Fprintf(w, "  FLOW %s: %s\n", b.Flow.Window, flowText(*b.Flow))
+		}
+
+		if b.Funding != nil {
+			fmt.Fprintf(w, "  FUNDING: %s\n", fundingText(*b.Funding, book.TakenAt))
+		}
+
+		if b.Basis != nil {
+			fmt.Fprintf(w, "  BASIS vs %s: %s\n", b.Basis.Spot, basisText(*b.Basis))
+		}
+
+		if b.Resiliency != nil {
+			fmt.Fprintf(w, "  RESILIENCY %s: %s\n", b.Resiliency.Window, resiliencyText(*b.Resiliency))
+		}
+
+		if b.Share != nil {
+			fmt.Fprintf(w, "  SHARE: %s\n", shareText(*b.Share))
+		}
+
+		if b.Volatility != nil {
+			fmt.Fprintf(w, "  VOLATILITY: %s\n", volatilityText(*b.Volatility))
+		}
+
+		if s := b.Spoofing; s != nil && s.Pulls > 0 {
+			fmt.Fprintf(w, "  SPOOFING: Pulls: %d │ Spoofing: %s%d%s │ Layering: %s%d%s\n",
+				s.Pulls, colorRed, s.Spoofing, colorReset, colorRed, s.Layering, colorReset)
+		}
+
+		fmt.Fprintf(w, "  NOTIONAL:  Bids: %s%9s%s │ Asks: %s%9s%s\n",
+			colorGreen, notionalText(stats.TotalBidsNotional), colorReset,
+			colorRed, notionalText(stats.TotalAsksNotional), colorReset)
+
+		if stats.TotalOrders > 0 {
+			fmt.Fprintf(w, "  ORDERS: %d resting │ Best bid queue: %s%d%s │ Best ask queue: %s%d%s\n",
+				stats.TotalOrders,
+				colorGreen, stats.BestBidOrders, colorReset,
+				colorRed, stats.BestAskOrders, colorReset)
+		}
+
+		if stats.SequenceGaps > 0 || stats.ChecksumFailures > 0 || stats.CrossedCount > 0 {
+			fmt.Fprintf(w, "  INTEGRITY: Gaps: %s%d%s │ Checksum failures: %s%d%s │ Crossed: %s%d%s\n",
+				colorRed, stats.SequenceGaps, colorReset,
+				colorRed, stats.ChecksumFailures, colorReset,
+				colorRed, stats.CrossedCount, colorReset)
+		}
+
+		// Print separator between exchanges (but not after the last one)
+		if i < len(books)-1 {
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
+
+// consolidated prints the best bid and offer across the books that are live and not stale,
+// when there are at least two
+func (f *textFormatter) consolidated(books []Book) {
+	snapshots := make(map[string]*orderbook.BookSnapshot, len(books))
+	for _, b := range books {
+		if book := b.Book.Snapshot(); !stale(book, f.staleAfter) {
+			snapshots[b.Exchange] = book
+		}
+	}
+	cbbo, ok := analytics.ComputeCBBO(snapshots)
+	if !ok || cbbo.Venues < 2 {
+		return
+	}
+
+	spreadColor := colorMagenta
+	if cbbo.Crossed() {
+		spreadColor = colorRed
+	}
+	fmt.Fprintf(f.w, "%sCBBO%s  Bid: %s%10s%s (%s) │ Ask: %s%10s%s (%s) │ Spread: %s%s (%sbp)%s\n\n",
+		colorBold, colorReset,
+		colorGreen, cbbo.BestBid.StringFixed(2), colorReset, strings.Join(cbbo.BidVenues, ", "),
+		colorRed, cbbo.BestAsk.StringFixed(2), colorReset, strings.Join(cbbo.AskVenues, ", "),
+		spreadColor, cbbo.Spread.StringFixed(4), cbbo.SpreadBps().StringFixed(2), colorReset)
+}
+
+// consolidatedFlow prints the taker flow summed over the books, when at least two have trades
+func (f *textFormatter) consolidatedFlow(books []Book) {
+	all := flows(books)
+	if len(all) < 2 {
+		return
+	}
+	fmt.Fprintf(f.w, "%sFLOW%s  %s\n\n", colorBold, colorReset, flowText(analytics.MergeFlow(all)))
+}
+
+// flowText formats taker flow, e.g. "CVD: 12.50 │ Δ: 3.10 │ Buy: 8.20 / Sell: 5.10 (62% buy) │ Large: 1"
+func flowText(flow analytics.FlowStats) string {
+	return fmt.Sprintf("CVD: %s%s%s │ Δ: %s%s%s │ Buy: %s%s%s / Sell: %s%s%s (%s%% buy) │ Large: %d",
+		deltaColor(flow.CVD), flow.CVD.StringFixed(2), colorReset,
+		deltaColor(flow.Delta()), flow.Delta().StringFixed(2), colorReset,
+		colorGreen, flow.BuyVolume.StringFixed(2), colorReset,
+		colorRed, flow.SellVolume.StringFixed(2), colorReset,
+		flow.BuyRatio().Mul(decimal.NewFromInt(100)).StringFixed(0), flow.LargeTrades)
+}
+
+// fundingText formats funding and open interest, e.g.
+// "Rate: 0.0100% │ Next: 42m0s │ Mark: 100.10 │ Index: 100.00 │ OI: 1234.50 (Δ1h0m0s: 12.30) │ OI notional: 123.57K"
+func fundingText(funding analytics.FundingStats, now time.Time) string {
+	parts := []string{fmt.Sprintf("Rate: %s%s%%%s", deltaColor(funding.Rate), funding.Rate.Mul(decimal.NewFromInt(100)).StringFixed(4), colorReset)}
+	if funding.NextFunding.After(now) {
+		parts = append(parts, "Next: "+funding.NextFunding.Sub(now).Round(time.Minute).String())
+	}
+	if funding.MarkPrice.IsPositive() {
+		parts = append(parts, fmt.Sprintf("Mark: %s%s%s", colorYellow, funding.MarkPrice.StringFixed(2), colorReset))
+	}
+	if funding.IndexPrice.IsPositive() {
+		parts = append(parts, "Index: "+funding.IndexPrice.StringFixed(2))
+	}
+	if funding.OpenInterest.IsPositive() {
+		parts = append(parts, fmt.Sprintf("OI: %s (Δ%s: %s%s%s)", funding.OpenInterest.StringFixed(2), funding.Window,
+			deltaColor(funding.OpenInterestChange), funding.OpenInterestChange.StringFixed(2), colorReset))
+		if funding.MarkPrice.IsPositive() {
+			parts = append(parts, "OI notional: "+notionalText(funding.OpenInterestNotional()))
+		}
+	}
+	return strings.Join(parts, " │ ")
+}
+
+// basisText formats a spot-perp basis, e.g. "Basis: 4.20bp │ Annualized: 4.60% │ Funding annualized: 10.95%"
+func basisText(basis analytics.BasisStats) string {
+	text := fmt.Sprintf("Basis: %s%sbp%s", deltaColor(basis.Basis), basis.BasisBps().StringFixed(2), colorReset)
+	if basis.Funded {
+		hundred := decimal.NewFromInt(100)
+		text += fmt.Sprintf(" │ Annualized: %s%% │ Funding annualized: %s%%",
+			basis.AnnualizedBasis.Mul(hundred).StringFixed(2), basis.AnnualizedFunding.Mul(hundred).StringFixed(2))
+	}
+	return text
+}
+
+// resiliencyText formats the resiliency of a book, e.g.
+// "Score: 0.75 │ Depletions: 2 (1 recovered, mean 15s) │ Replenished: 43% │ Near mid: Bids 10.00 / Asks 8.00"
+func resiliencyText(resiliency analytics.ResiliencyStats) string {
+	scoreColor := colorGreen
+	if resiliency.Score.LessThan(decimal.NewFromFloat(0.5)) {
+		scoreColor = colorRed
+	}
+	text := fmt.Sprintf("Score: %s%s%s │ Depletions: %d", scoreColor, resiliency.Score.StringFixed(2), colorReset, resiliency.Depletions)
+	if resiliency.Depletions > 0 {
+		text += fmt.Sprintf(" (%d recovered", resiliency.Recovered)
+		if resiliency.Recovered > 0 {
+			text += ", mean " + resiliency.MeanRecovery.Round(time.Second).String()
+		}
+		text += fmt.Sprintf(") │ Replenished: %s%%", resiliency.Replenishment.Mul(decimal.NewFromInt(100)).StringFixed(0))
+	}
+	return text + fmt.Sprintf(" │ Near mid: Bids %s%s%s / Asks %s%s%s",
+		colorGreen, resiliency.BidDepth.StringFixed(2), colorReset, colorRed, resiliency.AskDepth.StringFixed(2), colorReset)
+}
+
+// shareText formats the market share of a book, e.g.
+// "0.5%: 31% │ 2%: 28% │ Best bid: 45% │ Best ask: 20% of 10m0s"
+func shareText(share analytics.MarketShareStats) string {
+	hundred := decimal.NewFromInt(100)
+	var parts []string
+	for _, band := range share.Bands {
+		parts = append(parts, fmt.Sprintf("%s: %s%%", types.BandLabel(band.Pct), band.Total.Mul(hundred).StringFixed(0)))
+	}
+	parts = append(parts, fmt.Sprintf("Best bid: %s%s%%%s", colorGreen, share.BestBid.Mul(hundred).StringFixed(0), colorReset))
+	parts = append(parts, fmt.Sprintf("Best ask: %s%s%%%s of %s", colorRed, share.BestAsk.Mul(hundred).StringFixed(0), colorReset, share.Sampled.Round(time.Second)))
+	return strings.Join(parts, " │ ")
+}
+
+// volatilityText formats the realized volatility of a book, e.g.
+// "1m: 0.05% (36.2% ann.) │ 5m: 0.11% (35.7% ann.)"
+func volatilityText(volatility analytics.VolatilityStats) string {
+	hundred := decimal.NewFromInt(100)
+	parts := make([]string, 0, len(volatility.Windows))
+	for _, window := range volatility.Windows {
+		parts = append(parts, fmt.Sprintf("%s: %s%% (%s%% ann.)", collector.WindowLabel(window.Window),
+			window.Volatility.Mul(hundred).StringFixed(2), window.Annualized.Mul(hundred).StringFixed(1)))
+	}
+	return strings.Join(parts, " │ ")
+}
+
+// effectiveSpreadText formats the fee-adjusted spread of a book, e.g.
+// Raw: 2.00bp │ Taker: 10.00bp │ Maker: 4.00bp
+func effectiveSpreadText(spread analytics.EffectiveSpread) string {
+	return fmt.Sprintf("Raw: %s │ Taker: %s%s%s │ Maker: %s%s%s",
+		rateBps(spread.Raw), colorMagenta, rateBps(spread.Taker), colorReset,
+		deltaColor(spread.Maker), rateBps(spread.Maker), colorReset)
+}
+
+// rateBps formats a fraction in basis points, e.g. 0.0004 as 4.00bp
+func rateBps(rate decimal.Decimal) string {
+	return rate.Mul(decimal.NewFromInt(10000)).StringFixed(2) + "bp"
+}
+
+// ofiText formats the order flow imbalance of a book per window, e.g.
+// 1m: +3.20 over 118 updates │ 5m: -1.05 over 602 updates
+func ofiText(windows []types.OFIWindow) string {
+	parts := make([]string, 0, len(windows))
+	for _, window := range windows {
+		sign := ""
+		if window.OFI.IsPositive() {
+			sign = "+"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s%s%s%s over %d updates", collector.WindowLabel(window.Window),
+			deltaColor(window.OFI), sign, window.OFI.StringFixed(2), colorReset, window.Updates))
+	}
+	return strings.Join(parts, " │ ")
+}
+
+func deltaColor(delta decimal.Decimal) string {
+	if delta.GreaterThan(decimal.Zero) {
+		return colorGreen
+	} else if delta.LessThan(decimal.Zero) {
+		return colorRed
+	}
+	return colorYellow
+}
+
+// bandText formats a band liquidity value, or n/a when the band is not covered
+func bandText(value decimal.Decimal, covered bool) string {
+	if !covered {
+		return "n/a"
+	}
+	return value.StringFixed(2)
+}
+
+// notionalText formats a quote-currency amount compactly (e.g. 12.5M)
+func notionalText(value decimal.Decimal) string {
+	v := value.InexactFloat64()
+	switch {
+	case v >= 1e9:
+		return fmt.Sprintf("%.2fB", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("%.2fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("%.2fK", v/1e3)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// imbalanceText formats the imbalance per depth, e.g. "L1 0.62 │ L5 0.48"
+func imbalanceText(imbalances []types.DepthImbalance) string {
+	parts := make([]string, len(imbalances))
+	for i, depth := range imbalances {
+		parts[i] = fmt.Sprintf("L%d %s", depth.Levels, depth.Imbalance.StringFixed(2))
+	}
+	return strings.Join(parts, " │ ")
+}
@@ -0,0 +1,104 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaConfig configures the Kafka sink
+type KafkaConfig struct {
+	Brokers      []string      // Bootstrap brokers, e.g. localhost:9092
+	BatchTimeout time.Duration // Longest a message waits for its batch, default 50ms
+}
+
+// KafkaSink produces messages asynchronously, hashing keys to partitions so every
+// message of one book lands on the same partition in order
+type KafkaSink struct {
+	brokers []string
+	writer  *kafka.Writer
+	mu      sync.RWMutex // Guards closed against concurrent publishes
+	closed  bool
+	failed  atomic.Int64 // Messages the brokers did not accept
+}
+
+// NewKafkaSink creates a producer for the brokers; topics are created on first use where the cluster allows it
+func NewKafkaSink(config KafkaConfig) (*KafkaSink, error) {
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("no Kafka brokers configured")
+	}
+	if config.BatchTimeout <= 0 {
+		config.BatchTimeout = 50 * time.Millisecond
+	}
+
+	s := &KafkaSink{brokers: config.Brokers}
+	s.writer = &kafka.Writer{
+		Addr:                   kafka.TCP(config.Brokers...),
+		Balancer:               &kafka.Hash{},
+		BatchTimeout:           config.BatchTimeout,
+		RequiredAcks:           kafka.RequireOne,
+		Compression:            kafka.Lz4,
+		Async:                  true,
+		AllowAutoTopicCreation: true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				s.failed.Add(int64(len(messages)))
+				logger("kafka").Error("Failed to deliver messages", "event", "publish_failed", "messages", len(messages), "error", err)
+			}
+		},
+	}
+	return s, nil
+}
+
+// Publish queues a message on the producer
+func (s *KafkaSink) Publish(topic, key string, value []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("kafka sink is closed")
+	}
+	// Async writers return at once; delivery errors arrive in Completion
+	return s.writer.WriteMessages(context.Background(), kafka.Message{Topic: topic, Key: []byte(key), Value: value})
+}
+
+// TestConnection dials the first reachable broker and reads the cluster metadata
+func (s *KafkaSink) TestConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var lastErr error
+	for _, broker := range s.brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		_, err = conn.Brokers()
+		conn.Close()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("no Kafka broker reachable: %w", lastErr)
+}
+
+// Close flushes pending messages and closes the producer
+func (s *KafkaSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.writer.Close()
+}
+
+// Failed returns the messages the brokers did not accept
+func (s *KafkaSink) Failed() int64 {
+	return s.failed.Load()
+}
@@ -0,0 +1,137 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSConfig configures the NATS sink
+type NATSConfig struct {
+	URL string // Server URL, default nats://127.0.0.1:4222
+
+	// Stream, when set, persists messages in a JetStream stream of this name, created or
+	// updated on connect to capture Subjects; without it messages use core NATS and are
+	// only seen by subscribers connected at the time
+	Stream   string
+	Subjects []string      // Subjects the stream captures, e.g. orderbook.depth.>
+	MaxAge   time.Duration // Retention of stream messages, default 24h
+}
+
+// NATSSink publishes each message on the subject <topic>.<exchange>.<symbol>, so
+// subscribers can filter with wildcards such as orderbook.depth.*.BTCUSDT
+type NATSSink struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream // nil for core NATS
+	mu     sync.RWMutex        // Guards closed against concurrent publishes
+	closed bool
+	failed atomic.Int64 // Messages the server did not accept
+}
+
+// NewNATSSink connects to the server and, when a stream is configured, sets it up
+func NewNATSSink(config NATSConfig) (*NATSSink, error) {
+	if config.URL == "" {
+		config.URL = nats.DefaultURL
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 24 * time.Hour
+	}
+
+	s := &NATSSink{}
+	conn, err := nats.Connect(config.URL,
+		nats.Name("orderbook"),
+		nats.MaxReconnects(-1),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			logger("nats").Error("Connection error", "event", "connection_error", "error", err)
+		}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	s.conn = conn
+
+	if config.Stream == "" {
+		return s, nil
+	}
+
+	s.js, err = jetstream.New(conn,
+		jetstream.WithPublishAsyncMaxPending(4096),
+		jetstream.WithPublishAsyncErrHandler(func(_ jetstream.JetStream, msg *nats.Msg, err error) {
+			if s.failed.Add(1) == 1 {
+				logger("nats").Error("Failed to persist message", "event", "publish_failed", "subject", msg.Subject, "error", err)
+			}
+		}))
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open JetStream: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream := jetstream.StreamConfig{Name: config.Stream, Subjects: config.Subjects, MaxAge: config.MaxAge}
+	if _, err := s.js.CreateOrUpdateStream(ctx, stream); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set up stream %s: %w", config.Stream, err)
+	}
+	return s, nil
+}
+
+// Publish sends a message without waiting for the server; JetStream acknowledgements
+// are collected in the background
+func (s *NATSSink) Publish(topic, key string, value []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("nats sink is closed")
+	}
+
+	subject := natsSubject(topic, key)
+	if s.js == nil {
+		return s.conn.Publish(subject, value)
+	}
+	_, err := s.js.PublishAsync(subject, value)
+	return err
+}
+
+// TestConnection round-trips to the server
+func (s *NATSSink) TestConnection() error {
+	return s.conn.FlushTimeout(10 * time.Second)
+}
+
+// Close waits briefly for outstanding acknowledgements, flushes and disconnects
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.js != nil {
+		select {
+		case <-s.js.PublishAsyncComplete():
+		case <-time.After(5 * time.Second):
+			logger("nats").Warn("Closing with unacknowledged messages", "event", "close_pending", "messages", s.js.PublishAsyncPending())
+		}
+	}
+	err := s.conn.FlushTimeout(5 * time.Second)
+	s.conn.Close()
+	return err
+}
+
+// Failed returns the messages JetStream did not persist
+func (s *NATSSink) Failed() int64 {
+	return s.failed.Load()
+}
+
+// natsSubject turns an exchange:symbol key into subject tokens after the topic, replacing
+// characters NATS reserves within a token
+func natsSubject(topic, key string) string {
+	token := strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_").Replace(key)
+	return topic + "." + strings.ReplaceAll(token, ":", ".")
+}
@@ -0,0 +1,139 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// Sink delivers encoded messages to a message broker
+// Publish must not block on the network; delivery failures are the sink's to count and report
+type Sink interface {
+	Publish(topic, key string, value []byte) error
+	TestConnection() error
+	Close() error
+}
+
+// logger returns the logger of a sink
+func logger(sink string) *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, sink)
+}
+
+// Topics names the destinations of each kind of message
+type Topics struct {
+	Depth string // Normalized depth updates, default orderbook.depth
+	Stats string // Periodic stats snapshots, default orderbook.stats
+}
+
+// Publisher encodes depth updates and stats snapshots as JSON and hands them to a sink,
+// keyed by exchange and symbol so each book stays ordered within one partition
+// It satisfies collector.DatabaseClient, so a Collector can drive the stats snapshots
+type Publisher struct {
+	sink   Sink
+	topics Topics
+}
+
+// WithDefaults fills in the default name of each unset topic
+func (t Topics) WithDefaults() Topics {
+	if t.Depth == "" {
+		t.Depth = "orderbook.depth"
+	}
+	if t.Stats == "" {
+		t.Stats = "orderbook.stats"
+	}
+	return t
+}
+
+// New creates a publisher writing to sink
+func New(sink Sink, topics Topics) *Publisher {
+	return &Publisher{sink: sink, topics: topics.WithDefaults()}
+}
+
+// DepthMessage is a normalized depth update as published; levels are [price, quantity]
+// strings exactly as the venue sent them, and a zero quantity removes the level
+type DepthMessage struct {
+	Exchange      string      `json:"exchange"`
+	Symbol        string      `json:"symbol"`
+	EventTime     time.Time   `json:"event_time"`
+	FirstUpdateID int64       `json:"first_update_id"`
+	FinalUpdateID int64       `json:"final_update_id"`
+	PrevUpdateID  int64       `json:"prev_update_id,omitempty"`
+	Bids          [][2]string `json:"bids"`
+	Asks          [][2]string `json:"asks"`
+	Checksum      *uint32     `json:"checksum,omitempty"`
+}
+
+// Key returns the message key of a book
+func Key(exchange, symbol string) string {
+	return exchange + ":" + symbol
+}
+
+// PublishDepth publishes an update of the book of symbol on its exchange; symbol is the
+// monitored symbol rather than the venue's native one so consumers can join venues
+func (p *Publisher) PublishDepth(symbol string, update *exchange.DepthUpdate) error {
+	message := NewDepthMessage(symbol, update)
+	return p.publish(p.topics.Depth, Key(message.Exchange, symbol), message)
+}
+
+// NewDepthMessage converts an update of the book of symbol into its published form
+func NewDepthMessage(symbol string, update *exchange.DepthUpdate) DepthMessage {
+	return DepthMessage{
+		Exchange:      string(update.Exchange),
+		Symbol:        symbol,
+		EventTime:     update.EventTime,
+		FirstUpdateID: update.FirstUpdateID,
+		FinalUpdateID: update.FinalUpdateID,
+		PrevUpdateID:  update.PrevUpdateID,
+		Bids:          levelPairs(update.Bids),
+		Asks:          levelPairs(update.Asks),
+		Checksum:      update.Checksum,
+	}
+}
+
+// InsertOrderbookSnapshot publishes a stats snapshot
+func (p *Publisher) InsertOrderbookSnapshot(snapshot *database.OrderbookSnapshotAPI) error {
+	return p.publish(p.topics.Stats, Key(snapshot.Exchange, snapshot.Symbol), snapshot)
+}
+
+// InsertOrderbookSnapshotsBatch publishes each snapshot as its own message
+func (p *Publisher) InsertOrderbookSnapshotsBatch(snapshots []*database.OrderbookSnapshotAPI) error {
+	for _, snapshot := range snapshots {
+		if err := p.InsertOrderbookSnapshot(snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestConnection checks that the broker is reachable
+func (p *Publisher) TestConnection() error {
+	return p.sink.TestConnection()
+}
+
+// Close flushes pending messages and closes the sink
+func (p *Publisher) Close() error {
+	return p.sink.Close()
+}
+
+// publish encodes a message and hands it to the sink
+func (p *Publisher) publish(topic, key string, message any) error {
+	value, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return p.sink.Publish(topic, key, value)
+}
+
+// levelPairs converts levels to [price, quantity] pairs
+func levelPairs(levels []exchange.PriceLevel) [][2]string {
+	pairs := make([][2]string, len(levels))
+	for i, level := range levels {
+		pairs[i] = [2]string{level.Price, level.Quantity}
+	}
+	return pairs
+}
@@ -0,0 +1,100 @@
+package publisher
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+// recordingSink keeps published messages in memory
+type recordingSink struct {
+	messages []recordedMessage
+}
+
+type recordedMessage struct {
+	topic, key string
+	value      []byte
+}
+
+func (s *recordingSink) Publish(topic, key string, value []byte) error {
+	s.messages = append(s.messages, recordedMessage{topic, key, value})
+	return nil
+}
+
+func (s *recordingSink) TestConnection() error { return nil }
+func (s *recordingSink) Close() error          { return nil }
+
+func TestPublisherDepth(t *testing.T) {
+	sink := &recordingSink{}
+	p := New(sink, Topics{Depth: "depth"})
+
+	checksum := uint32(42)
+	update := &exchange.DepthUpdate{
+		Exchange:      "binancef",
+		Symbol:        "btcusdt",
+		EventTime:     time.Unix(1700000000, 0).UTC(),
+		FirstUpdateID: 10,
+		FinalUpdateID: 12,
+		Bids:          []exchange.PriceLevel{{Price: "100.5", Quantity: "0"}},
+		Checksum:      &checksum,
+	}
+	if err := p.PublishDepth("BTCUSDT", update); err != nil {
+		t.Fatalf("PublishDepth() error: %v", err)
+	}
+
+	if len(sink.messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(sink.messages))
+	}
+	message := sink.messages[0]
+	if message.topic != "depth" || message.key != "binancef:BTCUSDT" {
+		t.Errorf("unexpected topic %q and key %q", message.topic, message.key)
+	}
+	want := `{"exchange":"binancef","symbol":"BTCUSDT","event_time":"2023-11-14T22:13:20Z","first_update_id":10,` +
+		`"final_update_id":12,"bids":[["100.5","0"]],"asks":[],"checksum":42}`
+	if string(message.value) != want {
+		t.Errorf("unexpected message\n got %s\nwant %s", message.value, want)
+	}
+}
+
+func TestPublisherStats(t *testing.T) {
+	sink := &recordingSink{}
+	p := New(sink, Topics{})
+
+	bid := 99.5
+	snapshots := []*database.OrderbookSnapshotAPI{
+		{Exchange: "bybit", Symbol: "BTCUSDT", BestBid: &bid},
+		{Exchange: "okx", Symbol: "BTCUSDT"},
+	}
+	if err := p.InsertOrderbookSnapshotsBatch(snapshots); err != nil {
+		t.Fatalf("InsertOrderbookSnapshotsBatch() error: %v", err)
+	}
+
+	if len(sink.messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(sink.messages))
+	}
+	if sink.messages[0].topic != "orderbook.stats" || sink.messages[1].key != "okx:BTCUSDT" {
+		t.Errorf("unexpected messages %+v", sink.messages)
+	}
+	var decoded database.OrderbookSnapshotAPI
+	if err := json.Unmarshal(sink.messages[0].value, &decoded); err != nil || decoded.BestBid == nil || *decoded.BestBid != bid {
+		t.Errorf("unexpected snapshot %s (%v)", sink.messages[0].value, err)
+	}
+}
+
+func TestNATSSubject(t *testing.T) {
+	tests := []struct {
+		key, want string
+	}{
+		{Key("binancef", "BTCUSDT"), "orderbook.depth.binancef.BTCUSDT"},
+		{Key("kraken", "BTC/USD"), "orderbook.depth.kraken.BTC/USD"},
+		{Key("hyperliquid", "k.PEPE"), "orderbook.depth.hyperliquid.k_PEPE"},
+	}
+	for _, tt := range tests {
+		if got := natsSubject("orderbook.depth", tt.key); got != tt.want {
+			t.Errorf("natsSubject(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
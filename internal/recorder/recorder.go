@@ -0,0 +1,310 @@
+package recorder
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/replay"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects how archive files are compressed
+type Compression string
+
+const (
+	CompressionGzip Compression = "gzip" // .gz, readable by every tool
+	CompressionZstd Compression = "zstd" // .zst, smaller and faster
+)
+
+// ParseCompression interprets a compression name; empty selects gzip
+func ParseCompression(name string) (Compression, error) {
+	switch Compression(name) {
+	case "", CompressionGzip:
+		return CompressionGzip, nil
+	case CompressionZstd:
+		return CompressionZstd, nil
+	default:
+		return "", fmt.Errorf("unknown compression %q: expected gzip or zstd", name)
+	}
+}
+
+// Config configures a recorder
+type Config struct {
+	Dir            string        // Root of the archive, default recordings
+	Compression    Compression   // Default gzip
+	RotateSize     int64         // Uncompressed bytes written to a file before it is rotated, default 256MB
+	RotateInterval time.Duration // Longest a file stays open, default 1h
+	QueueSize      int           // Pending records before new ones are dropped, default 65536
+}
+
+// Recorder archives what each exchange sends, in two kinds of rotating files per exchange:
+//
+//	<dir>/<exchange>/raw-<start>.ndjson.gz   every raw WebSocket message with its receive time
+//	<dir>/<exchange>/book-<start>.ndjson.gz  normalized snapshots and updates in the replay format
+//
+// Book files can be replayed with the file exchange (REPLAY_FILE); each starts with a snapshot,
+// so they only rotate when a snapshot arrives after the size or interval is reached.
+// Records are written by a background goroutine; a full queue drops them rather than
+// stalling the feeds, counted in Dropped
+type Recorder struct {
+	config  Config
+	mu      sync.RWMutex // Guards closed against concurrent enqueues
+	closed  bool
+	queue   chan record
+	stopped chan struct{}
+	dropped atomic.Int64
+	raw     map[exchange.ExchangeName]*archive
+	books   map[exchange.ExchangeName]*bookArchive
+}
+
+// RawRecord is one line of a raw archive; Message holds JSON messages as they were
+// received and Binary holds any other payload (e.g. compressed frames)
+type RawRecord struct {
+	Received time.Time       `json:"received"`
+	Message  json.RawMessage `json:"message,omitempty"`
+	Binary   []byte          `json:"binary,omitempty"`
+}
+
+// record is one queued entry for the writer
+type record struct {
+	name     exchange.ExchangeName
+	received time.Time
+	raw      []byte
+	snapshot *exchange.Snapshot
+	update   *exchange.DepthUpdate
+}
+
+// archive is an open compressed file
+type archive struct {
+	file    *os.File
+	stream  io.WriteCloser
+	written int64
+	opened  time.Time
+}
+
+// bookArchive is an open book file and its replay encoder
+type bookArchive struct {
+	*archive
+	writer *replay.Writer
+}
+
+// New creates the archive directory and starts the writer
+func New(config Config) (*Recorder, error) {
+	if config.Dir == "" {
+		config.Dir = "recordings"
+	}
+	if config.Compression == "" {
+		config.Compression = CompressionGzip
+	}
+	if config.RotateSize <= 0 {
+		config.RotateSize = 256 << 20
+	}
+	if config.RotateInterval <= 0 {
+		config.RotateInterval = time.Hour
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 65536
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	r := &Recorder{
+		config:  config,
+		queue:   make(chan record, config.QueueSize),
+		stopped: make(chan struct{}),
+		raw:     make(map[exchange.ExchangeName]*archive),
+		books:   make(map[exchange.ExchangeName]*bookArchive),
+	}
+	go r.writeLoop()
+	return r, nil
+}
+
+// Tap records a raw message; it makes Recorder an exchange.MessageTap
+func (r *Recorder) Tap(name exchange.ExchangeName, received time.Time, message []byte) {
+	r.enqueue(record{name: name, received: received, raw: message})
+}
+
+// RecordSnapshot records a normalized snapshot of an exchange's book
+func (r *Recorder) RecordSnapshot(name exchange.ExchangeName, snapshot *exchange.Snapshot) {
+	r.enqueue(record{name: name, received: time.Now(), snapshot: snapshot})
+}
+
+// RecordUpdate records a normalized depth update of an exchange's book
+func (r *Recorder) RecordUpdate(name exchange.ExchangeName, update *exchange.DepthUpdate) {
+	r.enqueue(record{name: name, received: time.Now(), update: update})
+}
+
+// enqueue hands a record to the writer without blocking
+func (r *Recorder) enqueue(rec record) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- rec:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// writeLoop writes queued records until the queue is closed, then closes every file
+func (r *Recorder) writeLoop() {
+	defer close(r.stopped)
+
+	for rec := range r.queue {
+		if err := r.write(rec); err != nil {
+			logger().Error("Failed to write record", "event", "write_failed", logging.KeyExchange, rec.name, "error", err)
+		}
+	}
+
+	for name, a := range r.raw {
+		if err := a.Close(); err != nil {
+			logger().Error("Failed to close raw archive", "event", "close_failed", logging.KeyExchange, name, "error", err)
+		}
+	}
+	for name, b := range r.books {
+		if err := b.Close(); err != nil {
+			logger().Error("Failed to close book archive", "event", "close_failed", logging.KeyExchange, name, "error", err)
+		}
+	}
+}
+
+// logger returns the logger of recorders
+func logger() *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, "recorder")
+}
+
+// write appends one record to the file of its kind, rotating it first when due
+func (r *Recorder) write(rec record) error {
+	if rec.raw != nil {
+		a := r.raw[rec.name]
+		if a != nil && r.due(a) {
+			a.Close()
+			a = nil
+		}
+		if a == nil {
+			var err error
+			if a, err = r.open(rec.name, "raw", rec.received); err != nil {
+				return err
+			}
+			r.raw[rec.name] = a
+		}
+
+		line := RawRecord{Received: rec.received}
+		if json.Valid(rec.raw) {
+			line.Message = rec.raw
+		} else {
+			line.Binary = rec.raw
+		}
+		data, err := json.Marshal(line)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		_, err = a.Write(append(data, '\n'))
+		return err
+	}
+
+	b := r.books[rec.name]
+	if rec.snapshot != nil && b != nil && r.due(b.archive) {
+		b.Close()
+		b = nil
+	}
+	if b == nil {
+		if rec.snapshot == nil {
+			return nil // A replay file must start with a snapshot
+		}
+		a, err := r.open(rec.name, "book", rec.received)
+		if err != nil {
+			return err
+		}
+		writer, _ := replay.NewWriter(a, replay.FormatNDJSON)
+		b = &bookArchive{archive: a, writer: writer}
+		r.books[rec.name] = b
+	}
+
+	if rec.snapshot != nil {
+		return b.writer.WriteSnapshot(rec.received, rec.snapshot)
+	}
+	return b.writer.WriteUpdate(rec.received, rec.update)
+}
+
+// due reports whether a file has reached its rotation size or interval
+func (r *Recorder) due(a *archive) bool {
+	return a.written >= r.config.RotateSize || time.Since(a.opened) >= r.config.RotateInterval
+}
+
+// open creates a new compressed file of a kind for an exchange
+func (r *Recorder) open(name exchange.ExchangeName, kind string, start time.Time) (*archive, error) {
+	dir := filepath.Join(r.config.Dir, string(name))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	ext := ".ndjson.gz"
+	if r.config.Compression == CompressionZstd {
+		ext = ".ndjson.zst"
+	}
+	path := filepath.Join(dir, kind+"-"+start.UTC().Format("20060102T150405.000000000Z")+ext)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	var stream io.WriteCloser
+	if r.config.Compression == CompressionZstd {
+		if stream, err = zstd.NewWriter(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to start zstd stream: %w", err)
+		}
+	} else {
+		stream = gzip.NewWriter(file)
+	}
+	return &archive{file: file, stream: stream, opened: time.Now()}, nil
+}
+
+// Write compresses p into the file
+func (a *archive) Write(p []byte) (int, error) {
+	n, err := a.stream.Write(p)
+	a.written += int64(n)
+	return n, err
+}
+
+// Close finishes the compressed stream and closes the file
+func (a *archive) Close() error {
+	err := a.stream.Close()
+	if closeErr := a.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Dropped returns the records lost to a full queue
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close writes the queued records and closes every file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	<-r.stopped
+	return nil
+}
@@ -0,0 +1,131 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/exchange/replay"
+)
+
+func TestRecorderRaw(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	received := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r.Tap(exchange.Binancef, received, []byte(`{"e":"depthUpdate","u":1}`))
+	r.Tap(exchange.Binancef, received.Add(time.Millisecond), []byte{0x1f, 0x8b, 0x00})
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	r.Tap(exchange.Binancef, received, []byte(`{}`)) // Ignored once closed
+
+	files, _ := filepath.Glob(filepath.Join(dir, "binancef", "raw-*.ndjson.gz"))
+	if len(files) != 1 {
+		t.Fatalf("expected one raw archive, got %v", files)
+	}
+	file, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error: %v", err)
+	}
+
+	var lines []RawRecord
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var line RawRecord
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid line %s: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if string(lines[0].Message) != `{"e":"depthUpdate","u":1}` || !lines[0].Received.Equal(received) {
+		t.Errorf("unexpected JSON message %+v", lines[0])
+	}
+	if len(lines[1].Binary) != 3 || lines[1].Message != nil {
+		t.Errorf("expected the binary frame base64 encoded, got %+v", lines[1])
+	}
+}
+
+func TestRecorderBookReplays(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(Config{Dir: dir, Compression: CompressionZstd})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// Updates before the first snapshot cannot be replayed and are left out
+	r.RecordUpdate(exchange.Bybit, &exchange.DepthUpdate{FinalUpdateID: 1})
+	r.RecordSnapshot(exchange.Bybit, &exchange.Snapshot{LastUpdateID: 1,
+		Bids: []exchange.PriceLevel{{Price: "99", Quantity: "1"}}, Asks: []exchange.PriceLevel{{Price: "101", Quantity: "1"}}})
+	r.RecordUpdate(exchange.Bybit, &exchange.DepthUpdate{FirstUpdateID: 2, FinalUpdateID: 2,
+		Bids: []exchange.PriceLevel{{Price: "100", Quantity: "2"}}})
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "bybit", "book-*.ndjson.zst"))
+	if len(files) != 1 {
+		t.Fatalf("expected one book archive, got %v", files)
+	}
+
+	ex := replay.NewFileExchange(replay.Config{Symbol: "BTCUSDT", Path: files[0], Speed: replay.MaxSpeed})
+	if err := ex.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer ex.Close()
+	snapshot, err := ex.GetSnapshot(context.Background())
+	if err != nil || snapshot.LastUpdateID != 1 {
+		t.Fatalf("unexpected snapshot %+v (%v)", snapshot, err)
+	}
+	var ids []int64
+	for update := range ex.Updates() {
+		ids = append(ids, update.FinalUpdateID)
+	}
+	if len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("expected update [2], got %v", ids)
+	}
+}
+
+func TestReadMessageTap(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	exchange.SetMessageTap(r)
+	defer exchange.SetMessageTap(nil)
+
+	var msg struct{ U int64 }
+	if err := exchange.ReadJSON(fakeConn(`{"U":7}`), exchange.OKX, &msg); err != nil || msg.U != 7 {
+		t.Fatalf("ReadJSON() = %+v, %v", msg, err)
+	}
+	r.Close()
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "okx", "raw-*")); len(files) != 1 {
+		t.Errorf("expected the tapped message to be archived, got %v", files)
+	}
+}
+
+// fakeConn returns one text message
+type fakeConn string
+
+func (c fakeConn) ReadMessage() (int, []byte, error) {
+	return 1, []byte(c), nil
+}
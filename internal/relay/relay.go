@@ -0,0 +1,314 @@
+package relay
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/relay/relaypb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Config configures the gRPC relay client
+type Config struct {
+	// Target is the endpoint implementing OrderbookRelay (proto/orderbook/v1/relay.proto):
+	// host:port or grpc://host:port in plaintext, grpcs://host:port over TLS
+	Target    string
+	QueueSize int // Messages held while the endpoint is slow or down before new ones are dropped, default 10000
+}
+
+// Stats counts the messages handed to the relay
+type Stats struct {
+	Sent    int64
+	Dropped int64 // Dropped because the queue was full
+	Failed  int64 // Lost while the endpoint was unreachable or the stream broke
+}
+
+// Client streams stats snapshots, stored levels and depth updates to a gRPC endpoint over one
+// long-lived client stream, reopened after failures. Messages are queued so the collector and
+// feeds never wait on the network; while the stream is down they are counted as failed
+// It satisfies collector.DatabaseClient and collector.DepthWriter
+type Client struct {
+	conn    *grpc.ClientConn
+	client  relaypb.OrderbookRelayClient
+	mu      sync.RWMutex // Guards closed against concurrent enqueues
+	closed  bool
+	queue   chan *relaypb.RelayMessage
+	stopped chan struct{} // Closed once the send loop has flushed the queue
+	sent    atomic.Int64
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+// NewClient creates a client for the endpoint; the connection is made in the background
+func NewClient(config Config) (*Client, error) {
+	target, creds, err := parseTarget(config.Target)
+	if err != nil {
+		return nil, err
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 10000
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client for %s: %w", target, err)
+	}
+	c := &Client{
+		conn:    conn,
+		client:  relaypb.NewOrderbookRelayClient(conn),
+		queue:   make(chan *relaypb.RelayMessage, config.QueueSize),
+		stopped: make(chan struct{}),
+	}
+	go c.sendLoop()
+	return c, nil
+}
+
+// parseTarget splits the scheme selecting transport security off a target
+func parseTarget(target string) (string, credentials.TransportCredentials, error) {
+	switch {
+	case target == "":
+		return "", nil, fmt.Errorf("a gRPC target is required, e.g. grpc://localhost:50051")
+	case strings.HasPrefix(target, "grpcs://"):
+		return strings.TrimPrefix(target, "grpcs://"), credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12}), nil
+	default:
+		return strings.TrimPrefix(target, "grpc://"), insecure.NewCredentials(), nil
+	}
+}
+
+// InsertOrderbookSnapshot queues a single snapshot
+func (c *Client) InsertOrderbookSnapshot(snapshot *database.OrderbookSnapshotAPI) error {
+	return c.enqueue(&relaypb.RelayMessage{Payload: &relaypb.RelayMessage_Snapshot{Snapshot: SnapshotMessage(snapshot)}})
+}
+
+// InsertOrderbookSnapshotsBatch queues each snapshot as its own message
+func (c *Client) InsertOrderbookSnapshotsBatch(snapshots []*database.OrderbookSnapshotAPI) error {
+	for _, snapshot := range snapshots {
+		if err := c.InsertOrderbookSnapshot(snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertDepthSnapshots queues the levels of each book
+func (c *Client) InsertDepthSnapshots(depth []*database.DepthSnapshotAPI) error {
+	for _, book := range depth {
+		message := &relaypb.Depth{
+			Exchange:  book.Exchange,
+			Symbol:    book.Symbol,
+			Timestamp: timestamppb.New(book.Timestamp),
+			Bids:      levelMessages(book.Bids),
+			Asks:      levelMessages(book.Asks),
+		}
+		if err := c.enqueue(&relaypb.RelayMessage{Payload: &relaypb.RelayMessage_Depth{Depth: message}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishDepth queues an update of the book of symbol on its exchange; symbol is the
+// monitored symbol rather than the venue's native one so receivers can join venues
+func (c *Client) PublishDepth(symbol string, update *exchange.DepthUpdate) error {
+	return c.enqueue(&relaypb.RelayMessage{Payload: &relaypb.RelayMessage_Update{Update: DepthUpdateMessage(symbol, update)}})
+}
+
+// DepthUpdateMessage converts an update of the book of symbol, keeping the venue's level strings
+func DepthUpdateMessage(symbol string, update *exchange.DepthUpdate) *relaypb.DepthUpdate {
+	return &relaypb.DepthUpdate{
+		Exchange:      string(update.Exchange),
+		Symbol:        symbol,
+		EventTime:     timestamppb.New(update.EventTime),
+		FirstUpdateId: update.FirstUpdateID,
+		FinalUpdateId: update.FinalUpdateID,
+		PrevUpdateId:  update.PrevUpdateID,
+		Bids:          deltaMessages(update.Bids),
+		Asks:          deltaMessages(update.Asks),
+		Checksum:      update.Checksum,
+	}
+}
+
+// enqueue hands a message to the send loop without blocking
+func (c *Client) enqueue(message *relaypb.RelayMessage) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return fmt.Errorf("gRPC relay is closed")
+	}
+
+	select {
+	case c.queue <- message:
+		return nil
+	default:
+		return fmt.Errorf("gRPC relay queue full, message dropped (%d total)", c.dropped.Add(1))
+	}
+}
+
+// sendLoop writes queued messages to the stream, opening a new one whenever the last broke
+func (c *Client) sendLoop() {
+	defer close(c.stopped)
+
+	var stream grpc.ClientStreamingClient[relaypb.RelayMessage, relaypb.StreamSummary]
+	healthy := true
+	fail := func(err error) {
+		c.failed.Add(1)
+		if healthy {
+			logger().Error("Stream failed, retrying with each message", "event", "stream_failed", "target", c.conn.Target(), "error", err)
+			healthy = false
+		}
+	}
+
+	for message := range c.queue {
+		if stream == nil {
+			s, err := c.client.Stream(context.Background())
+			if err != nil {
+				fail(err)
+				continue
+			}
+			stream = s
+		}
+		if err := stream.Send(message); err != nil {
+			// Send only reports that the stream ended; the status comes from closing it
+			if _, closeErr := stream.CloseAndRecv(); closeErr != nil {
+				err = closeErr
+			}
+			stream = nil
+			fail(err)
+			continue
+		}
+		c.sent.Add(1)
+		if !healthy {
+			logger().Info("Stream restored", "event", "stream_restored", "target", c.conn.Target())
+			healthy = true
+		}
+	}
+
+	if stream != nil {
+		if summary, err := stream.CloseAndRecv(); err != nil {
+			logger().Error("Failed to close stream", "event", "close_failed", "error", err)
+		} else {
+			logger().Info("Stream closed", "event", "stream_closed", "received", summary.GetReceived())
+		}
+	}
+}
+
+// logger returns the logger of the relay client
+func logger() *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, "relay")
+}
+
+// Stats returns the message counters
+func (c *Client) Stats() Stats {
+	return Stats{Sent: c.sent.Load(), Dropped: c.dropped.Load(), Failed: c.failed.Load()}
+}
+
+// TestConnection waits up to 10 seconds for the endpoint to accept a connection
+func (c *Client) TestConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c.conn.Connect()
+	for {
+		state := c.conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("gRPC endpoint %s not reachable: %s", c.conn.Target(), state)
+		}
+	}
+}
+
+// Close sends the queued messages, ends the stream and closes the connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	<-c.stopped
+	return c.conn.Close()
+}
+
+// SnapshotMessage converts a snapshot; unset metrics stay unset
+func SnapshotMessage(s *database.OrderbookSnapshotAPI) *relaypb.Snapshot {
+	message := &relaypb.Snapshot{
+		Exchange: s.Exchange, Symbol: s.Symbol, Timestamp: timestamppb.New(s.Timestamp),
+		BestBid: s.BestBid, BestAsk: s.BestAsk, MidPrice: s.MidPrice, Spread: s.Spread,
+		BidLiquidity_05Pct: s.BidLiquidity05Pct, AskLiquidity_05Pct: s.AskLiquidity05Pct,
+		BidLiquidity_2Pct: s.BidLiquidity2Pct, AskLiquidity_2Pct: s.AskLiquidity2Pct,
+		BidLiquidity_10Pct: s.BidLiquidity10Pct, AskLiquidity_10Pct: s.AskLiquidity10Pct,
+		TotalBidsQty: s.TotalBidsQty, TotalAsksQty: s.TotalAsksQty,
+		BidNotional_05Pct: s.BidNotional05Pct, AskNotional_05Pct: s.AskNotional05Pct,
+		BidNotional_2Pct: s.BidNotional2Pct, AskNotional_2Pct: s.AskNotional2Pct,
+		BidNotional_10Pct: s.BidNotional10Pct, AskNotional_10Pct: s.AskNotional10Pct,
+		TotalBidsNotional: s.TotalBidsNotional, TotalAsksNotional: s.TotalAsksNotional,
+		Microprice:     s.Microprice,
+		LiquidityBands: bandMessages(s.LiquidityBands),
+		DepthCurve:     bandMessages(s.DepthCurve),
+	}
+	if len(s.Imbalance) > 0 {
+		message.Imbalance = make(map[string]*relaypb.Imbalance, len(s.Imbalance))
+		for levels, value := range s.Imbalance {
+			message.Imbalance[levels] = &relaypb.Imbalance{Imbalance: value.Imbalance, WeightedMid: value.WeightedMid}
+		}
+	}
+	if len(s.Impact) > 0 {
+		message.Impact = make(map[string]*relaypb.Impact, len(s.Impact))
+		for size, value := range s.Impact {
+			message.Impact[size] = &relaypb.Impact{BuyVwap: value.BuyVWAP, BuySlippage: value.BuySlippage,
+				SellVwap: value.SellVWAP, SellSlippage: value.SellSlippage}
+		}
+	}
+	if r := s.Rolling; r != nil {
+		message.Rolling = &relaypb.Rolling{WindowSeconds: r.WindowSeconds, Samples: int32(r.Samples),
+			AvgSpread: r.AvgSpread, SpreadVolatility: r.SpreadVolatility, MidReturnVolatility: r.MidReturnVolatility}
+	}
+	return message
+}
+
+// bandMessages converts band or curve liquidity keyed by label
+func bandMessages(bands map[string]database.BandLiquidityAPI) map[string]*relaypb.BandLiquidity {
+	if len(bands) == 0 {
+		return nil
+	}
+	messages := make(map[string]*relaypb.BandLiquidity, len(bands))
+	for label, band := range bands {
+		messages[label] = &relaypb.BandLiquidity{Bid: band.Bid, Ask: band.Ask, BidNotional: band.BidNotional, AskNotional: band.AskNotional}
+	}
+	return messages
+}
+
+// levelMessages converts stored levels
+func levelMessages(levels []database.LevelAPI) []*relaypb.Level {
+	messages := make([]*relaypb.Level, len(levels))
+	for i, level := range levels {
+		messages[i] = &relaypb.Level{Price: level.Price, Quantity: level.Quantity}
+	}
+	return messages
+}
+
+// deltaMessages converts the levels of an update, keeping the venue's strings
+func deltaMessages(levels []exchange.PriceLevel) []*relaypb.LevelDelta {
+	messages := make([]*relaypb.LevelDelta, len(levels))
+	for i, level := range levels {
+		messages[i] = &relaypb.LevelDelta{Price: level.Price, Quantity: level.Quantity}
+	}
+	return messages
+}
@@ -0,0 +1,123 @@
+package relay
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/relay/relaypb"
+
+	"google.golang.org/grpc"
+)
+
+// relayServer keeps every message it receives
+type relayServer struct {
+	relaypb.UnimplementedOrderbookRelayServer
+	mu       sync.Mutex
+	messages []*relaypb.RelayMessage
+}
+
+func (s *relayServer) Stream(stream grpc.ClientStreamingServer[relaypb.RelayMessage, relaypb.StreamSummary]) error {
+	var received int64
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&relaypb.StreamSummary{Received: received})
+		}
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.messages = append(s.messages, message)
+		s.mu.Unlock()
+		received++
+	}
+}
+
+func TestClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	receiver := &relayServer{}
+	relaypb.RegisterOrderbookRelayServer(server, receiver)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	client, err := NewClient(Config{Target: "grpc://" + listener.Addr().String()})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+	if err := client.TestConnection(); err != nil {
+		t.Fatalf("TestConnection() error: %v", err)
+	}
+
+	now := time.Now()
+	bid, band := 99.5, 3.0
+	snapshot := &database.OrderbookSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: now, BestBid: &bid,
+		Imbalance:      map[string]database.ImbalanceAPI{"5": {Imbalance: 0.6, WeightedMid: 100.1}},
+		LiquidityBands: map[string]database.BandLiquidityAPI{"25bp": {Bid: &band}}}
+	if err := client.InsertOrderbookSnapshotsBatch([]*database.OrderbookSnapshotAPI{snapshot}); err != nil {
+		t.Fatal(err)
+	}
+	depth := &database.DepthSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: now, Bids: []database.LevelAPI{{Price: 99.5, Quantity: 1}}}
+	if err := client.InsertDepthSnapshots([]*database.DepthSnapshotAPI{depth}); err != nil {
+		t.Fatal(err)
+	}
+	update := &exchange.DepthUpdate{Exchange: "bybit", Symbol: "BTCUSDT", EventTime: now, FinalUpdateID: 42,
+		Asks: []exchange.PriceLevel{{Price: "100.5", Quantity: "0"}}}
+	if err := client.PublishDepth("BTCUSDT", update); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if len(receiver.messages) != 3 || client.Stats().Sent != 3 {
+		t.Fatalf("expected 3 messages, got %d (%+v)", len(receiver.messages), client.Stats())
+	}
+	got := receiver.messages[0].GetSnapshot()
+	if got.GetBestBid() != 99.5 || got.BestAsk != nil || got.GetImbalance()["5"].GetWeightedMid() != 100.1 ||
+		got.GetLiquidityBands()["25bp"].GetBid() != 3 || !got.GetTimestamp().AsTime().Equal(now) {
+		t.Errorf("unexpected snapshot %v", got)
+	}
+	if levels := receiver.messages[1].GetDepth().GetBids(); len(levels) != 1 || levels[0].GetQuantity() != 1 {
+		t.Errorf("unexpected levels %v", levels)
+	}
+	if delta := receiver.messages[2].GetUpdate(); delta.GetFinalUpdateId() != 42 || delta.GetAsks()[0].GetQuantity() != "0" {
+		t.Errorf("unexpected update %v", delta)
+	}
+}
+
+func TestClientUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := listener.Addr().String()
+	listener.Close()
+
+	client, err := NewClient(Config{Target: target, QueueSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		client.InsertOrderbookSnapshot(&database.OrderbookSnapshotAPI{Exchange: "okx", Symbol: "BTCUSDT", Timestamp: time.Now()})
+	}
+	client.Close()
+
+	stats := client.Stats()
+	if stats.Sent != 0 || stats.Dropped+stats.Failed != 20 {
+		t.Errorf("expected every message dropped or failed, got %+v", stats)
+	}
+	if _, err := NewClient(Config{}); err == nil {
+		t.Error("expected an error without a target")
+	}
+}
@@ -0,0 +1,1509 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: orderbook/v1/relay.proto
+
+// Messages streamed by the grpc storage driver. Implement OrderbookRelay to receive the
+// stats snapshots, stored levels and raw depth updates of every monitored book.
+// Regenerate the Go code with:
+//   protoc -I proto --go_out=. --go_opt=module=github.com/imajinl/crypto-orderbook --go-grpc_out=. --go-grpc_opt=module=github.com/imajinl/crypto-orderbook orderbook/v1/relay.proto
+
+package relaypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RelayMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Payload:
+	//	*RelayMessage_Snapshot
+	//	*RelayMessage_Depth
+	//	*RelayMessage_Update
+	Payload isRelayMessage_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *RelayMessage) Reset() {
+	*x = RelayMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RelayMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelayMessage) ProtoMessage() {}
+
+func (x *RelayMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelayMessage.ProtoReflect.Descriptor instead.
+func (*RelayMessage) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *RelayMessage) GetPayload() isRelayMessage_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *RelayMessage) GetSnapshot() *Snapshot {
+	if x, ok := x.GetPayload().(*RelayMessage_Snapshot); ok {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (x *RelayMessage) GetDepth() *Depth {
+	if x, ok := x.GetPayload().(*RelayMessage_Depth); ok {
+		return x.Depth
+	}
+	return nil
+}
+
+func (x *RelayMessage) GetUpdate() *DepthUpdate {
+	if x, ok := x.GetPayload().(*RelayMessage_Update); ok {
+		return x.Update
+	}
+	return nil
+}
+
+type isRelayMessage_Payload interface {
+	isRelayMessage_Payload()
+}
+
+type RelayMessage_Snapshot struct {
+	Snapshot *Snapshot `protobuf:"bytes,1,opt,name=snapshot,proto3,oneof"`
+}
+
+type RelayMessage_Depth struct {
+	Depth *Depth `protobuf:"bytes,2,opt,name=depth,proto3,oneof"`
+}
+
+type RelayMessage_Update struct {
+	Update *DepthUpdate `protobuf:"bytes,3,opt,name=update,proto3,oneof"`
+}
+
+func (*RelayMessage_Snapshot) isRelayMessage_Payload() {}
+
+func (*RelayMessage_Depth) isRelayMessage_Payload() {}
+
+func (*RelayMessage_Update) isRelayMessage_Payload() {}
+
+type StreamSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Received int64 `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"` // Messages the server accepted
+}
+
+func (x *StreamSummary) Reset() {
+	*x = StreamSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamSummary) ProtoMessage() {}
+
+func (x *StreamSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamSummary.ProtoReflect.Descriptor instead.
+func (*StreamSummary) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StreamSummary) GetReceived() int64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+// Snapshot mirrors a row of orderbook_snapshots; metrics the book could not provide are unset
+type Snapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Exchange           string                    `protobuf:"bytes,1,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Symbol             string                    `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Timestamp          *timestamppb.Timestamp    `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	BestBid            *float64                  `protobuf:"fixed64,4,opt,name=best_bid,json=bestBid,proto3,oneof" json:"best_bid,omitempty"`
+	BestAsk            *float64                  `protobuf:"fixed64,5,opt,name=best_ask,json=bestAsk,proto3,oneof" json:"best_ask,omitempty"`
+	MidPrice           *float64                  `protobuf:"fixed64,6,opt,name=mid_price,json=midPrice,proto3,oneof" json:"mid_price,omitempty"`
+	Spread             *float64                  `protobuf:"fixed64,7,opt,name=spread,proto3,oneof" json:"spread,omitempty"`
+	BidLiquidity_05Pct *float64                  `protobuf:"fixed64,8,opt,name=bid_liquidity_05_pct,json=bidLiquidity05Pct,proto3,oneof" json:"bid_liquidity_05_pct,omitempty"`
+	AskLiquidity_05Pct *float64                  `protobuf:"fixed64,9,opt,name=ask_liquidity_05_pct,json=askLiquidity05Pct,proto3,oneof" json:"ask_liquidity_05_pct,omitempty"`
+	BidLiquidity_2Pct  *float64                  `protobuf:"fixed64,10,opt,name=bid_liquidity_2_pct,json=bidLiquidity2Pct,proto3,oneof" json:"bid_liquidity_2_pct,omitempty"`
+	AskLiquidity_2Pct  *float64                  `protobuf:"fixed64,11,opt,name=ask_liquidity_2_pct,json=askLiquidity2Pct,proto3,oneof" json:"ask_liquidity_2_pct,omitempty"`
+	BidLiquidity_10Pct *float64                  `protobuf:"fixed64,12,opt,name=bid_liquidity_10_pct,json=bidLiquidity10Pct,proto3,oneof" json:"bid_liquidity_10_pct,omitempty"`
+	AskLiquidity_10Pct *float64                  `protobuf:"fixed64,13,opt,name=ask_liquidity_10_pct,json=askLiquidity10Pct,proto3,oneof" json:"ask_liquidity_10_pct,omitempty"`
+	TotalBidsQty       *float64                  `protobuf:"fixed64,14,opt,name=total_bids_qty,json=totalBidsQty,proto3,oneof" json:"total_bids_qty,omitempty"`
+	TotalAsksQty       *float64                  `protobuf:"fixed64,15,opt,name=total_asks_qty,json=totalAsksQty,proto3,oneof" json:"total_asks_qty,omitempty"`
+	BidNotional_05Pct  *float64                  `protobuf:"fixed64,16,opt,name=bid_notional_05_pct,json=bidNotional05Pct,proto3,oneof" json:"bid_notional_05_pct,omitempty"`
+	AskNotional_05Pct  *float64                  `protobuf:"fixed64,17,opt,name=ask_notional_05_pct,json=askNotional05Pct,proto3,oneof" json:"ask_notional_05_pct,omitempty"`
+	BidNotional_2Pct   *float64                  `protobuf:"fixed64,18,opt,name=bid_notional_2_pct,json=bidNotional2Pct,proto3,oneof" json:"bid_notional_2_pct,omitempty"`
+	AskNotional_2Pct   *float64                  `protobuf:"fixed64,19,opt,name=ask_notional_2_pct,json=askNotional2Pct,proto3,oneof" json:"ask_notional_2_pct,omitempty"`
+	BidNotional_10Pct  *float64                  `protobuf:"fixed64,20,opt,name=bid_notional_10_pct,json=bidNotional10Pct,proto3,oneof" json:"bid_notional_10_pct,omitempty"`
+	AskNotional_10Pct  *float64                  `protobuf:"fixed64,21,opt,name=ask_notional_10_pct,json=askNotional10Pct,proto3,oneof" json:"ask_notional_10_pct,omitempty"`
+	TotalBidsNotional  *float64                  `protobuf:"fixed64,22,opt,name=total_bids_notional,json=totalBidsNotional,proto3,oneof" json:"total_bids_notional,omitempty"`
+	TotalAsksNotional  *float64                  `protobuf:"fixed64,23,opt,name=total_asks_notional,json=totalAsksNotional,proto3,oneof" json:"total_asks_notional,omitempty"`
+	Microprice         *float64                  `protobuf:"fixed64,24,opt,name=microprice,proto3,oneof" json:"microprice,omitempty"`
+	Imbalance          map[string]*Imbalance     `protobuf:"bytes,25,rep,name=imbalance,proto3" json:"imbalance,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`                                 // Keyed by level count, e.g. "5"
+	LiquidityBands     map[string]*BandLiquidity `protobuf:"bytes,26,rep,name=liquidity_bands,json=liquidityBands,proto3" json:"liquidity_bands,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"` // Keyed by band label, e.g. "25bp"
+	DepthCurve         map[string]*BandLiquidity `protobuf:"bytes,27,rep,name=depth_curve,json=depthCurve,proto3" json:"depth_curve,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`             // Keyed by offset label
+	Impact             map[string]*Impact        `protobuf:"bytes,28,rep,name=impact,proto3" json:"impact,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`                                       // Keyed by order size in base units
+	Rolling            *Rolling                  `protobuf:"bytes,29,opt,name=rolling,proto3" json:"rolling,omitempty"`
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Snapshot) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *Snapshot) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Snapshot) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Snapshot) GetBestBid() float64 {
+	if x != nil && x.BestBid != nil {
+		return *x.BestBid
+	}
+	return 0
+}
+
+func (x *Snapshot) GetBestAsk() float64 {
+	if x != nil && x.BestAsk != nil {
+		return *x.BestAsk
+	}
+	return 0
+}
+
+func (x *Snapshot) GetMidPrice() float64 {
+	if x != nil && x.MidPrice != nil {
+		return *x.MidPrice
+	}
+	return 0
+}
+
+func (x *Snapshot) GetSpread() float64 {
+	if x != nil && x.Spread != nil {
+		return *x.Spread
+	}
+	return 0
+}
+
+func (x *Snapshot) GetBidLiquidity_05Pct() float64 {
+	if x != nil && x.BidLiquidity_05Pct != nil {
+		return *x.BidLiquidity_05Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetAskLiquidity_05Pct() float64 {
+	if x != nil && x.AskLiquidity_05Pct != nil {
+		return *x.AskLiquidity_05Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetBidLiquidity_2Pct() float64 {
+	if x != nil && x.BidLiquidity_2Pct != nil {
+		return *x.BidLiquidity_2Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetAskLiquidity_2Pct() float64 {
+	if x != nil && x.AskLiquidity_2Pct != nil {
+		return *x.AskLiquidity_2Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetBidLiquidity_10Pct() float64 {
+	if x != nil && x.BidLiquidity_10Pct != nil {
+		return *x.BidLiquidity_10Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetAskLiquidity_10Pct() float64 {
+	if x != nil && x.AskLiquidity_10Pct != nil {
+		return *x.AskLiquidity_10Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetTotalBidsQty() float64 {
+	if x != nil && x.TotalBidsQty != nil {
+		return *x.TotalBidsQty
+	}
+	return 0
+}
+
+func (x *Snapshot) GetTotalAsksQty() float64 {
+	if x != nil && x.TotalAsksQty != nil {
+		return *x.TotalAsksQty
+	}
+	return 0
+}
+
+func (x *Snapshot) GetBidNotional_05Pct() float64 {
+	if x != nil && x.BidNotional_05Pct != nil {
+		return *x.BidNotional_05Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetAskNotional_05Pct() float64 {
+	if x != nil && x.AskNotional_05Pct != nil {
+		return *x.AskNotional_05Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetBidNotional_2Pct() float64 {
+	if x != nil && x.BidNotional_2Pct != nil {
+		return *x.BidNotional_2Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetAskNotional_2Pct() float64 {
+	if x != nil && x.AskNotional_2Pct != nil {
+		return *x.AskNotional_2Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetBidNotional_10Pct() float64 {
+	if x != nil && x.BidNotional_10Pct != nil {
+		return *x.BidNotional_10Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetAskNotional_10Pct() float64 {
+	if x != nil && x.AskNotional_10Pct != nil {
+		return *x.AskNotional_10Pct
+	}
+	return 0
+}
+
+func (x *Snapshot) GetTotalBidsNotional() float64 {
+	if x != nil && x.TotalBidsNotional != nil {
+		return *x.TotalBidsNotional
+	}
+	return 0
+}
+
+func (x *Snapshot) GetTotalAsksNotional() float64 {
+	if x != nil && x.TotalAsksNotional != nil {
+		return *x.TotalAsksNotional
+	}
+	return 0
+}
+
+func (x *Snapshot) GetMicroprice() float64 {
+	if x != nil && x.Microprice != nil {
+		return *x.Microprice
+	}
+	return 0
+}
+
+func (x *Snapshot) GetImbalance() map[string]*Imbalance {
+	if x != nil {
+		return x.Imbalance
+	}
+	return nil
+}
+
+func (x *Snapshot) GetLiquidityBands() map[string]*BandLiquidity {
+	if x != nil {
+		return x.LiquidityBands
+	}
+	return nil
+}
+
+func (x *Snapshot) GetDepthCurve() map[string]*BandLiquidity {
+	if x != nil {
+		return x.DepthCurve
+	}
+	return nil
+}
+
+func (x *Snapshot) GetImpact() map[string]*Impact {
+	if x != nil {
+		return x.Impact
+	}
+	return nil
+}
+
+func (x *Snapshot) GetRolling() *Rolling {
+	if x != nil {
+		return x.Rolling
+	}
+	return nil
+}
+
+type Imbalance struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Imbalance   float64 `protobuf:"fixed64,1,opt,name=imbalance,proto3" json:"imbalance,omitempty"`
+	WeightedMid float64 `protobuf:"fixed64,2,opt,name=weighted_mid,json=weightedMid,proto3" json:"weighted_mid,omitempty"`
+}
+
+func (x *Imbalance) Reset() {
+	*x = Imbalance{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Imbalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Imbalance) ProtoMessage() {}
+
+func (x *Imbalance) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Imbalance.ProtoReflect.Descriptor instead.
+func (*Imbalance) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Imbalance) GetImbalance() float64 {
+	if x != nil {
+		return x.Imbalance
+	}
+	return 0
+}
+
+func (x *Imbalance) GetWeightedMid() float64 {
+	if x != nil {
+		return x.WeightedMid
+	}
+	return 0
+}
+
+type BandLiquidity struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bid         *float64 `protobuf:"fixed64,1,opt,name=bid,proto3,oneof" json:"bid,omitempty"`
+	Ask         *float64 `protobuf:"fixed64,2,opt,name=ask,proto3,oneof" json:"ask,omitempty"`
+	BidNotional *float64 `protobuf:"fixed64,3,opt,name=bid_notional,json=bidNotional,proto3,oneof" json:"bid_notional,omitempty"`
+	AskNotional *float64 `protobuf:"fixed64,4,opt,name=ask_notional,json=askNotional,proto3,oneof" json:"ask_notional,omitempty"`
+}
+
+func (x *BandLiquidity) Reset() {
+	*x = BandLiquidity{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BandLiquidity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BandLiquidity) ProtoMessage() {}
+
+func (x *BandLiquidity) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BandLiquidity.ProtoReflect.Descriptor instead.
+func (*BandLiquidity) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BandLiquidity) GetBid() float64 {
+	if x != nil && x.Bid != nil {
+		return *x.Bid
+	}
+	return 0
+}
+
+func (x *BandLiquidity) GetAsk() float64 {
+	if x != nil && x.Ask != nil {
+		return *x.Ask
+	}
+	return 0
+}
+
+func (x *BandLiquidity) GetBidNotional() float64 {
+	if x != nil && x.BidNotional != nil {
+		return *x.BidNotional
+	}
+	return 0
+}
+
+func (x *BandLiquidity) GetAskNotional() float64 {
+	if x != nil && x.AskNotional != nil {
+		return *x.AskNotional
+	}
+	return 0
+}
+
+type Impact struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BuyVwap      *float64 `protobuf:"fixed64,1,opt,name=buy_vwap,json=buyVwap,proto3,oneof" json:"buy_vwap,omitempty"`
+	BuySlippage  *float64 `protobuf:"fixed64,2,opt,name=buy_slippage,json=buySlippage,proto3,oneof" json:"buy_slippage,omitempty"`
+	SellVwap     *float64 `protobuf:"fixed64,3,opt,name=sell_vwap,json=sellVwap,proto3,oneof" json:"sell_vwap,omitempty"`
+	SellSlippage *float64 `protobuf:"fixed64,4,opt,name=sell_slippage,json=sellSlippage,proto3,oneof" json:"sell_slippage,omitempty"`
+}
+
+func (x *Impact) Reset() {
+	*x = Impact{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Impact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Impact) ProtoMessage() {}
+
+func (x *Impact) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Impact.ProtoReflect.Descriptor instead.
+func (*Impact) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Impact) GetBuyVwap() float64 {
+	if x != nil && x.BuyVwap != nil {
+		return *x.BuyVwap
+	}
+	return 0
+}
+
+func (x *Impact) GetBuySlippage() float64 {
+	if x != nil && x.BuySlippage != nil {
+		return *x.BuySlippage
+	}
+	return 0
+}
+
+func (x *Impact) GetSellVwap() float64 {
+	if x != nil && x.SellVwap != nil {
+		return *x.SellVwap
+	}
+	return 0
+}
+
+func (x *Impact) GetSellSlippage() float64 {
+	if x != nil && x.SellSlippage != nil {
+		return *x.SellSlippage
+	}
+	return 0
+}
+
+type Rolling struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	WindowSeconds       float64 `protobuf:"fixed64,1,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	Samples             int32   `protobuf:"varint,2,opt,name=samples,proto3" json:"samples,omitempty"`
+	AvgSpread           float64 `protobuf:"fixed64,3,opt,name=avg_spread,json=avgSpread,proto3" json:"avg_spread,omitempty"`
+	SpreadVolatility    float64 `protobuf:"fixed64,4,opt,name=spread_volatility,json=spreadVolatility,proto3" json:"spread_volatility,omitempty"`
+	MidReturnVolatility float64 `protobuf:"fixed64,5,opt,name=mid_return_volatility,json=midReturnVolatility,proto3" json:"mid_return_volatility,omitempty"`
+}
+
+func (x *Rolling) Reset() {
+	*x = Rolling{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rolling) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rolling) ProtoMessage() {}
+
+func (x *Rolling) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rolling.ProtoReflect.Descriptor instead.
+func (*Rolling) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Rolling) GetWindowSeconds() float64 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+func (x *Rolling) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *Rolling) GetAvgSpread() float64 {
+	if x != nil {
+		return x.AvgSpread
+	}
+	return 0
+}
+
+func (x *Rolling) GetSpreadVolatility() float64 {
+	if x != nil {
+		return x.SpreadVolatility
+	}
+	return 0
+}
+
+func (x *Rolling) GetMidReturnVolatility() float64 {
+	if x != nil {
+		return x.MidReturnVolatility
+	}
+	return 0
+}
+
+// Depth holds the top levels per side of a book, best first, stamped like its snapshot
+type Depth struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Exchange  string                 `protobuf:"bytes,1,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Symbol    string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Bids      []*Level               `protobuf:"bytes,4,rep,name=bids,proto3" json:"bids,omitempty"`
+	Asks      []*Level               `protobuf:"bytes,5,rep,name=asks,proto3" json:"asks,omitempty"`
+}
+
+func (x *Depth) Reset() {
+	*x = Depth{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Depth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Depth) ProtoMessage() {}
+
+func (x *Depth) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Depth.ProtoReflect.Descriptor instead.
+func (*Depth) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Depth) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *Depth) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Depth) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Depth) GetBids() []*Level {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *Depth) GetAsks() []*Level {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+type Level struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Price    float64 `protobuf:"fixed64,1,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity float64 `protobuf:"fixed64,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+}
+
+func (x *Level) Reset() {
+	*x = Level{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Level) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Level) ProtoMessage() {}
+
+func (x *Level) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Level.ProtoReflect.Descriptor instead.
+func (*Level) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Level) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Level) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+// DepthUpdate is a normalized depth update; levels are exactly as the venue sent them and a
+// zero quantity removes the level
+type DepthUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Exchange      string                 `protobuf:"bytes,1,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"` // The monitored symbol rather than the venue's native one
+	EventTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=event_time,json=eventTime,proto3" json:"event_time,omitempty"`
+	FirstUpdateId int64                  `protobuf:"varint,4,opt,name=first_update_id,json=firstUpdateId,proto3" json:"first_update_id,omitempty"`
+	FinalUpdateId int64                  `protobuf:"varint,5,opt,name=final_update_id,json=finalUpdateId,proto3" json:"final_update_id,omitempty"`
+	PrevUpdateId  int64                  `protobuf:"varint,6,opt,name=prev_update_id,json=prevUpdateId,proto3" json:"prev_update_id,omitempty"`
+	Bids          []*LevelDelta          `protobuf:"bytes,7,rep,name=bids,proto3" json:"bids,omitempty"`
+	Asks          []*LevelDelta          `protobuf:"bytes,8,rep,name=asks,proto3" json:"asks,omitempty"`
+	Checksum      *uint32                `protobuf:"varint,9,opt,name=checksum,proto3,oneof" json:"checksum,omitempty"`
+}
+
+func (x *DepthUpdate) Reset() {
+	*x = DepthUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DepthUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepthUpdate) ProtoMessage() {}
+
+func (x *DepthUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepthUpdate.ProtoReflect.Descriptor instead.
+func (*DepthUpdate) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DepthUpdate) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *DepthUpdate) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *DepthUpdate) GetEventTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EventTime
+	}
+	return nil
+}
+
+func (x *DepthUpdate) GetFirstUpdateId() int64 {
+	if x != nil {
+		return x.FirstUpdateId
+	}
+	return 0
+}
+
+func (x *DepthUpdate) GetFinalUpdateId() int64 {
+	if x != nil {
+		return x.FinalUpdateId
+	}
+	return 0
+}
+
+func (x *DepthUpdate) GetPrevUpdateId() int64 {
+	if x != nil {
+		return x.PrevUpdateId
+	}
+	return 0
+}
+
+func (x *DepthUpdate) GetBids() []*LevelDelta {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *DepthUpdate) GetAsks() []*LevelDelta {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+func (x *DepthUpdate) GetChecksum() uint32 {
+	if x != nil && x.Checksum != nil {
+		return *x.Checksum
+	}
+	return 0
+}
+
+type LevelDelta struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Price    string `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity string `protobuf:"bytes,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+}
+
+func (x *LevelDelta) Reset() {
+	*x = LevelDelta{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_orderbook_v1_relay_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LevelDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LevelDelta) ProtoMessage() {}
+
+func (x *LevelDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_orderbook_v1_relay_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LevelDelta.ProtoReflect.Descriptor instead.
+func (*LevelDelta) Descriptor() ([]byte, []int) {
+	return file_orderbook_v1_relay_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LevelDelta) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *LevelDelta) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+var File_orderbook_v1_relay_proto protoreflect.FileDescriptor
+
+var file_orderbook_v1_relay_proto_rawDesc = []byte{
+	0x0a, 0x18, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2f, 0x76, 0x31, 0x2f, 0x72,
+	0x65, 0x6c, 0x61, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x6f, 0x72, 0x64, 0x65,
+	0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb1, 0x01, 0x0a, 0x0c, 0x52, 0x65,
+	0x6c, 0x61, 0x79, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x34, 0x0a, 0x08, 0x73, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6f,
+	0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x48, 0x00, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x12, 0x2b, 0x0a, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x13, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x65, 0x70, 0x74, 0x68, 0x48, 0x00, 0x52, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x12, 0x33, 0x0a,
+	0x06, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x70,
+	0x74, 0x68, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x06, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x2b, 0x0a,
+	0x0d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1a,
+	0x0a, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x22, 0xbe, 0x11, 0x0a, 0x08, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x63, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x78, 0x63, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x38, 0x0a, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1e, 0x0a, 0x08, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x69,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x07, 0x62, 0x65, 0x73, 0x74, 0x42,
+	0x69, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x61, 0x73,
+	0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x07, 0x62, 0x65, 0x73, 0x74, 0x41,
+	0x73, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09, 0x6d, 0x69, 0x64, 0x5f, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x08, 0x6d, 0x69, 0x64, 0x50,
+	0x72, 0x69, 0x63, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61,
+	0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61,
+	0x64, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a, 0x14, 0x62, 0x69, 0x64, 0x5f, 0x6c, 0x69, 0x71, 0x75,
+	0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x30, 0x35, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x01, 0x48, 0x04, 0x52, 0x11, 0x62, 0x69, 0x64, 0x4c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69,
+	0x74, 0x79, 0x30, 0x35, 0x50, 0x63, 0x74, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a, 0x14, 0x61, 0x73,
+	0x6b, 0x5f, 0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x30, 0x35, 0x5f, 0x70,
+	0x63, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x48, 0x05, 0x52, 0x11, 0x61, 0x73, 0x6b, 0x4c,
+	0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x30, 0x35, 0x50, 0x63, 0x74, 0x88, 0x01, 0x01,
+	0x12, 0x32, 0x0a, 0x13, 0x62, 0x69, 0x64, 0x5f, 0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x5f, 0x32, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x48, 0x06, 0x52,
+	0x10, 0x62, 0x69, 0x64, 0x4c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x32, 0x50, 0x63,
+	0x74, 0x88, 0x01, 0x01, 0x12, 0x32, 0x0a, 0x13, 0x61, 0x73, 0x6b, 0x5f, 0x6c, 0x69, 0x71, 0x75,
+	0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x32, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x07, 0x52, 0x10, 0x61, 0x73, 0x6b, 0x4c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x32, 0x50, 0x63, 0x74, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a, 0x14, 0x62, 0x69, 0x64, 0x5f,
+	0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x31, 0x30, 0x5f, 0x70, 0x63, 0x74,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x01, 0x48, 0x08, 0x52, 0x11, 0x62, 0x69, 0x64, 0x4c, 0x69, 0x71,
+	0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x31, 0x30, 0x50, 0x63, 0x74, 0x88, 0x01, 0x01, 0x12, 0x34,
+	0x0a, 0x14, 0x61, 0x73, 0x6b, 0x5f, 0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f,
+	0x31, 0x30, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x01, 0x48, 0x09, 0x52, 0x11,
+	0x61, 0x73, 0x6b, 0x4c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x31, 0x30, 0x50, 0x63,
+	0x74, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x69,
+	0x64, 0x73, 0x5f, 0x71, 0x74, 0x79, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x01, 0x48, 0x0a, 0x52, 0x0c,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x69, 0x64, 0x73, 0x51, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12,
+	0x29, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x61, 0x73, 0x6b, 0x73, 0x5f, 0x71, 0x74,
+	0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x01, 0x48, 0x0b, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x41, 0x73, 0x6b, 0x73, 0x51, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x32, 0x0a, 0x13, 0x62, 0x69,
+	0x64, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x30, 0x35, 0x5f, 0x70, 0x63,
+	0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x01, 0x48, 0x0c, 0x52, 0x10, 0x62, 0x69, 0x64, 0x4e, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x30, 0x35, 0x50, 0x63, 0x74, 0x88, 0x01, 0x01, 0x12, 0x32,
+	0x0a, 0x13, 0x61, 0x73, 0x6b, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x30,
+	0x35, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28, 0x01, 0x48, 0x0d, 0x52, 0x10, 0x61,
+	0x73, 0x6b, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x30, 0x35, 0x50, 0x63, 0x74, 0x88,
+	0x01, 0x01, 0x12, 0x30, 0x0a, 0x12, 0x62, 0x69, 0x64, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e,
+	0x61, 0x6c, 0x5f, 0x32, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x12, 0x20, 0x01, 0x28, 0x01, 0x48, 0x0e,
+	0x52, 0x0f, 0x62, 0x69, 0x64, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x32, 0x50, 0x63,
+	0x74, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a, 0x12, 0x61, 0x73, 0x6b, 0x5f, 0x6e, 0x6f, 0x74, 0x69,
+	0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x32, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x0f, 0x52, 0x0f, 0x61, 0x73, 0x6b, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x32,
+	0x50, 0x63, 0x74, 0x88, 0x01, 0x01, 0x12, 0x32, 0x0a, 0x13, 0x62, 0x69, 0x64, 0x5f, 0x6e, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x31, 0x30, 0x5f, 0x70, 0x63, 0x74, 0x18, 0x14, 0x20,
+	0x01, 0x28, 0x01, 0x48, 0x10, 0x52, 0x10, 0x62, 0x69, 0x64, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e,
+	0x61, 0x6c, 0x31, 0x30, 0x50, 0x63, 0x74, 0x88, 0x01, 0x01, 0x12, 0x32, 0x0a, 0x13, 0x61, 0x73,
+	0x6b, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x31, 0x30, 0x5f, 0x70, 0x63,
+	0x74, 0x18, 0x15, 0x20, 0x01, 0x28, 0x01, 0x48, 0x11, 0x52, 0x10, 0x61, 0x73, 0x6b, 0x4e, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x31, 0x30, 0x50, 0x63, 0x74, 0x88, 0x01, 0x01, 0x12, 0x33,
+	0x0a, 0x13, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x69, 0x64, 0x73, 0x5f, 0x6e, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x18, 0x16, 0x20, 0x01, 0x28, 0x01, 0x48, 0x12, 0x52, 0x11, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x42, 0x69, 0x64, 0x73, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c,
+	0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x13, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x61, 0x73, 0x6b,
+	0x73, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x18, 0x17, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x13, 0x52, 0x11, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x41, 0x73, 0x6b, 0x73, 0x4e, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x6d, 0x69, 0x63, 0x72,
+	0x6f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x18, 0x20, 0x01, 0x28, 0x01, 0x48, 0x14, 0x52, 0x0a,
+	0x6d, 0x69, 0x63, 0x72, 0x6f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x88, 0x01, 0x01, 0x12, 0x43, 0x0a,
+	0x09, 0x69, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x19, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x25, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x49, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x69, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x53, 0x0a, 0x0f, 0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f,
+	0x62, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x1a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x6f, 0x72,
+	0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x2e, 0x4c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x42, 0x61, 0x6e,
+	0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0e, 0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69,
+	0x74, 0x79, 0x42, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x47, 0x0a, 0x0b, 0x64, 0x65, 0x70, 0x74, 0x68,
+	0x5f, 0x63, 0x75, 0x72, 0x76, 0x65, 0x18, 0x1b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x6f,
+	0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x2e, 0x44, 0x65, 0x70, 0x74, 0x68, 0x43, 0x75, 0x72, 0x76, 0x65, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x64, 0x65, 0x70, 0x74, 0x68, 0x43, 0x75, 0x72, 0x76, 0x65,
+	0x12, 0x3a, 0x0a, 0x06, 0x69, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x18, 0x1c, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x22, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x49, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x69, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x12, 0x2f, 0x0a, 0x07,
+	0x72, 0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x6c,
+	0x6c, 0x69, 0x6e, 0x67, 0x52, 0x07, 0x72, 0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x1a, 0x55, 0x0a,
+	0x0e, 0x49, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x2d, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e,
+	0x49, 0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x1a, 0x5e, 0x0a, 0x13, 0x4c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x42, 0x61, 0x6e, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6f,
+	0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x64,
+	0x4c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x1a, 0x5a, 0x0a, 0x0f, 0x44, 0x65, 0x70, 0x74, 0x68, 0x43, 0x75, 0x72,
+	0x76, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72,
+	0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x64, 0x4c, 0x69, 0x71, 0x75,
+	0x69, 0x64, 0x69, 0x74, 0x79, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x1a, 0x4f, 0x0a, 0x0b, 0x49, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e,
+	0x49, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x69, 0x64, 0x42, 0x0b,
+	0x0a, 0x09, 0x5f, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x61, 0x73, 0x6b, 0x42, 0x0c, 0x0a, 0x0a, 0x5f,
+	0x6d, 0x69, 0x64, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x73, 0x70,
+	0x72, 0x65, 0x61, 0x64, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x62, 0x69, 0x64, 0x5f, 0x6c, 0x69, 0x71,
+	0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x30, 0x35, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x17, 0x0a,
+	0x15, 0x5f, 0x61, 0x73, 0x6b, 0x5f, 0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f,
+	0x30, 0x35, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x62, 0x69, 0x64, 0x5f, 0x6c,
+	0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x32, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x16,
+	0x0a, 0x14, 0x5f, 0x61, 0x73, 0x6b, 0x5f, 0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79,
+	0x5f, 0x32, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x62, 0x69, 0x64, 0x5f, 0x6c,
+	0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x31, 0x30, 0x5f, 0x70, 0x63, 0x74, 0x42,
+	0x17, 0x0a, 0x15, 0x5f, 0x61, 0x73, 0x6b, 0x5f, 0x6c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x5f, 0x31, 0x30, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x5f, 0x62, 0x69, 0x64, 0x73, 0x5f, 0x71, 0x74, 0x79, 0x42, 0x11, 0x0a, 0x0f, 0x5f,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x61, 0x73, 0x6b, 0x73, 0x5f, 0x71, 0x74, 0x79, 0x42, 0x16,
+	0x0a, 0x14, 0x5f, 0x62, 0x69, 0x64, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f,
+	0x30, 0x35, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x61, 0x73, 0x6b, 0x5f, 0x6e,
+	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x30, 0x35, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x15,
+	0x0a, 0x13, 0x5f, 0x62, 0x69, 0x64, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f,
+	0x32, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x15, 0x0a, 0x13, 0x5f, 0x61, 0x73, 0x6b, 0x5f, 0x6e, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x32, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x16, 0x0a, 0x14,
+	0x5f, 0x62, 0x69, 0x64, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x31, 0x30,
+	0x5f, 0x70, 0x63, 0x74, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x61, 0x73, 0x6b, 0x5f, 0x6e, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x31, 0x30, 0x5f, 0x70, 0x63, 0x74, 0x42, 0x16, 0x0a, 0x14,
+	0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x69, 0x64, 0x73, 0x5f, 0x6e, 0x6f, 0x74, 0x69,
+	0x6f, 0x6e, 0x61, 0x6c, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x61,
+	0x73, 0x6b, 0x73, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x42, 0x0d, 0x0a, 0x0b,
+	0x5f, 0x6d, 0x69, 0x63, 0x72, 0x6f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x22, 0x4c, 0x0a, 0x09, 0x49,
+	0x6d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6d, 0x62, 0x61,
+	0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x69, 0x6d, 0x62,
+	0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x65, 0x64, 0x5f, 0x6d, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x77, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x65, 0x64, 0x4d, 0x69, 0x64, 0x22, 0xbf, 0x01, 0x0a, 0x0d, 0x42, 0x61,
+	0x6e, 0x64, 0x4c, 0x69, 0x71, 0x75, 0x69, 0x64, 0x69, 0x74, 0x79, 0x12, 0x15, 0x0a, 0x03, 0x62,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x03, 0x62, 0x69, 0x64, 0x88,
+	0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x61, 0x73, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x01, 0x52, 0x03, 0x61, 0x73, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x62, 0x69, 0x64,
+	0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x02, 0x52, 0x0b, 0x62, 0x69, 0x64, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x88, 0x01,
+	0x01, 0x12, 0x26, 0x0a, 0x0c, 0x61, 0x73, 0x6b, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61,
+	0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x0b, 0x61, 0x73, 0x6b, 0x4e, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x88, 0x01, 0x01, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x62, 0x69,
+	0x64, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x61, 0x73, 0x6b, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x62, 0x69,
+	0x64, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x61,
+	0x73, 0x6b, 0x5f, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x22, 0xda, 0x01, 0x0a, 0x06,
+	0x49, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x12, 0x1e, 0x0a, 0x08, 0x62, 0x75, 0x79, 0x5f, 0x76, 0x77,
+	0x61, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x07, 0x62, 0x75, 0x79, 0x56,
+	0x77, 0x61, 0x70, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x62, 0x75, 0x79, 0x5f, 0x73, 0x6c,
+	0x69, 0x70, 0x70, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x0b,
+	0x62, 0x75, 0x79, 0x53, 0x6c, 0x69, 0x70, 0x70, 0x61, 0x67, 0x65, 0x88, 0x01, 0x01, 0x12, 0x20,
+	0x0a, 0x09, 0x73, 0x65, 0x6c, 0x6c, 0x5f, 0x76, 0x77, 0x61, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x02, 0x52, 0x08, 0x73, 0x65, 0x6c, 0x6c, 0x56, 0x77, 0x61, 0x70, 0x88, 0x01, 0x01,
+	0x12, 0x28, 0x0a, 0x0d, 0x73, 0x65, 0x6c, 0x6c, 0x5f, 0x73, 0x6c, 0x69, 0x70, 0x70, 0x61, 0x67,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x0c, 0x73, 0x65, 0x6c, 0x6c, 0x53,
+	0x6c, 0x69, 0x70, 0x70, 0x61, 0x67, 0x65, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x62,
+	0x75, 0x79, 0x5f, 0x76, 0x77, 0x61, 0x70, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x62, 0x75, 0x79, 0x5f,
+	0x73, 0x6c, 0x69, 0x70, 0x70, 0x61, 0x67, 0x65, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x73, 0x65, 0x6c,
+	0x6c, 0x5f, 0x76, 0x77, 0x61, 0x70, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x73, 0x65, 0x6c, 0x6c, 0x5f,
+	0x73, 0x6c, 0x69, 0x70, 0x70, 0x61, 0x67, 0x65, 0x22, 0xca, 0x01, 0x0a, 0x07, 0x52, 0x6f, 0x6c,
+	0x6c, 0x69, 0x6e, 0x67, 0x12, 0x25, 0x0a, 0x0e, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x5f, 0x73,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x77, 0x69,
+	0x6e, 0x64, 0x6f, 0x77, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73, 0x61,
+	0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x76, 0x67, 0x5f, 0x73, 0x70, 0x72,
+	0x65, 0x61, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x61, 0x76, 0x67, 0x53, 0x70,
+	0x72, 0x65, 0x61, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x76,
+	0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x10, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x56, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x12, 0x32, 0x0a, 0x15, 0x6d, 0x69, 0x64, 0x5f, 0x72, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x5f,
+	0x76, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x13, 0x6d, 0x69, 0x64, 0x52, 0x65, 0x74, 0x75, 0x72, 0x6e, 0x56, 0x6f, 0x6c, 0x61, 0x74,
+	0x69, 0x6c, 0x69, 0x74, 0x79, 0x22, 0xc7, 0x01, 0x0a, 0x05, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12,
+	0x1a, 0x0a, 0x08, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d,
+	0x62, 0x6f, 0x6c, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x27, 0x0a,
+	0x04, 0x62, 0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6f, 0x72,
+	0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x52, 0x04, 0x62, 0x69, 0x64, 0x73, 0x12, 0x27, 0x0a, 0x04, 0x61, 0x73, 0x6b, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x04, 0x61, 0x73, 0x6b, 0x73, 0x22,
+	0x39, 0x0a, 0x05, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x22, 0xfc, 0x02, 0x0a, 0x0b, 0x44,
+	0x65, 0x70, 0x74, 0x68, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x78,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x39,
+	0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x66, 0x69, 0x72,
+	0x73, 0x74, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0d, 0x66, 0x69, 0x72, 0x73, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49,
+	0x64, 0x12, 0x26, 0x0a, 0x0f, 0x66, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x66, 0x69, 0x6e, 0x61,
+	0x6c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x70, 0x72, 0x65,
+	0x76, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x64, 0x12,
+	0x2c, 0x0a, 0x04, 0x62, 0x69, 0x64, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e,
+	0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x52, 0x04, 0x62, 0x69, 0x64, 0x73, 0x12, 0x2c, 0x0a,
+	0x04, 0x61, 0x73, 0x6b, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6f, 0x72,
+	0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x44, 0x65, 0x6c, 0x74, 0x61, 0x52, 0x04, 0x61, 0x73, 0x6b, 0x73, 0x12, 0x1f, 0x0a, 0x08, 0x63,
+	0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52,
+	0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09,
+	0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x22, 0x3e, 0x0a, 0x0a, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x32, 0x55, 0x0a, 0x0e, 0x4f, 0x72, 0x64,
+	0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x52, 0x65, 0x6c, 0x61, 0x79, 0x12, 0x43, 0x0a, 0x06, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x1a, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f,
+	0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6c, 0x61, 0x79, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x1a, 0x1b, 0x2e, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x28, 0x01,
+	0x42, 0x3c, 0x5a, 0x3a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x69,
+	0x6d, 0x61, 0x6a, 0x69, 0x6e, 0x6c, 0x2f, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x2d, 0x6f, 0x72,
+	0x64, 0x65, 0x72, 0x62, 0x6f, 0x6f, 0x6b, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c,
+	0x2f, 0x72, 0x65, 0x6c, 0x61, 0x79, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x79, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_orderbook_v1_relay_proto_rawDescOnce sync.Once
+	file_orderbook_v1_relay_proto_rawDescData = file_orderbook_v1_relay_proto_rawDesc
+)
+
+func file_orderbook_v1_relay_proto_rawDescGZIP() []byte {
+	file_orderbook_v1_relay_proto_rawDescOnce.Do(func() {
+		file_orderbook_v1_relay_proto_rawDescData = protoimpl.X.CompressGZIP(file_orderbook_v1_relay_proto_rawDescData)
+	})
+	return file_orderbook_v1_relay_proto_rawDescData
+}
+
+var file_orderbook_v1_relay_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_orderbook_v1_relay_proto_goTypes = []any{
+	(*RelayMessage)(nil),          // 0: orderbook.v1.RelayMessage
+	(*StreamSummary)(nil),         // 1: orderbook.v1.StreamSummary
+	(*Snapshot)(nil),              // 2: orderbook.v1.Snapshot
+	(*Imbalance)(nil),             // 3: orderbook.v1.Imbalance
+	(*BandLiquidity)(nil),         // 4: orderbook.v1.BandLiquidity
+	(*Impact)(nil),                // 5: orderbook.v1.Impact
+	(*Rolling)(nil),               // 6: orderbook.v1.Rolling
+	(*Depth)(nil),                 // 7: orderbook.v1.Depth
+	(*Level)(nil),                 // 8: orderbook.v1.Level
+	(*DepthUpdate)(nil),           // 9: orderbook.v1.DepthUpdate
+	(*LevelDelta)(nil),            // 10: orderbook.v1.LevelDelta
+	nil,                           // 11: orderbook.v1.Snapshot.ImbalanceEntry
+	nil,                           // 12: orderbook.v1.Snapshot.LiquidityBandsEntry
+	nil,                           // 13: orderbook.v1.Snapshot.DepthCurveEntry
+	nil,                           // 14: orderbook.v1.Snapshot.ImpactEntry
+	(*timestamppb.Timestamp)(nil), // 15: google.protobuf.Timestamp
+}
+var file_orderbook_v1_relay_proto_depIdxs = []int32{
+	2,  // 0: orderbook.v1.RelayMessage.snapshot:type_name -> orderbook.v1.Snapshot
+	7,  // 1: orderbook.v1.RelayMessage.depth:type_name -> orderbook.v1.Depth
+	9,  // 2: orderbook.v1.RelayMessage.update:type_name -> orderbook.v1.DepthUpdate
+	15, // 3: orderbook.v1.Snapshot.timestamp:type_name -> google.protobuf.Timestamp
+	11, // 4: orderbook.v1.Snapshot.imbalance:type_name -> orderbook.v1.Snapshot.ImbalanceEntry
+	12, // 5: orderbook.v1.Snapshot.liquidity_bands:type_name -> orderbook.v1.Snapshot.LiquidityBandsEntry
+	13, // 6: orderbook.v1.Snapshot.depth_curve:type_name -> orderbook.v1.Snapshot.DepthCurveEntry
+	14, // 7: orderbook.v1.Snapshot.impact:type_name -> orderbook.v1.Snapshot.ImpactEntry
+	6,  // 8: orderbook.v1.Snapshot.rolling:type_name -> orderbook.v1.Rolling
+	15, // 9: orderbook.v1.Depth.timestamp:type_name -> google.protobuf.Timestamp
+	8,  // 10: orderbook.v1.Depth.bids:type_name -> orderbook.v1.Level
+	8,  // 11: orderbook.v1.Depth.asks:type_name -> orderbook.v1.Level
+	15, // 12: orderbook.v1.DepthUpdate.event_time:type_name -> google.protobuf.Timestamp
+	10, // 13: orderbook.v1.DepthUpdate.bids:type_name -> orderbook.v1.LevelDelta
+	10, // 14: orderbook.v1.DepthUpdate.asks:type_name -> orderbook.v1.LevelDelta
+	3,  // 15: orderbook.v1.Snapshot.ImbalanceEntry.value:type_name -> orderbook.v1.Imbalance
+	4,  // 16: orderbook.v1.Snapshot.LiquidityBandsEntry.value:type_name -> orderbook.v1.BandLiquidity
+	4,  // 17: orderbook.v1.Snapshot.DepthCurveEntry.value:type_name -> orderbook.v1.BandLiquidity
+	5,  // 18: orderbook.v1.Snapshot.ImpactEntry.value:type_name -> orderbook.v1.Impact
+	0,  // 19: orderbook.v1.OrderbookRelay.Stream:input_type -> orderbook.v1.RelayMessage
+	1,  // 20: orderbook.v1.OrderbookRelay.Stream:output_type -> orderbook.v1.StreamSummary
+	20, // [20:21] is the sub-list for method output_type
+	19, // [19:20] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_orderbook_v1_relay_proto_init() }
+func file_orderbook_v1_relay_proto_init() {
+	if File_orderbook_v1_relay_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_orderbook_v1_relay_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*RelayMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*StreamSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Snapshot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*Imbalance); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*BandLiquidity); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Impact); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*Rolling); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*Depth); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*Level); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*DepthUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_orderbook_v1_relay_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*LevelDelta); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_orderbook_v1_relay_proto_msgTypes[0].OneofWrappers = []any{
+		(*RelayMessage_Snapshot)(nil),
+		(*RelayMessage_Depth)(nil),
+		(*RelayMessage_Update)(nil),
+	}
+	file_orderbook_v1_relay_proto_msgTypes[2].OneofWrappers = []any{}
+	file_orderbook_v1_relay_proto_msgTypes[4].OneofWrappers = []any{}
+	file_orderbook_v1_relay_proto_msgTypes[5].OneofWrappers = []any{}
+	file_orderbook_v1_relay_proto_msgTypes[9].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_orderbook_v1_relay_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_orderbook_v1_relay_proto_goTypes,
+		DependencyIndexes: file_orderbook_v1_relay_proto_depIdxs,
+		MessageInfos:      file_orderbook_v1_relay_proto_msgTypes,
+	}.Build()
+	File_orderbook_v1_relay_proto = out.File
+	file_orderbook_v1_relay_proto_rawDesc = nil
+	file_orderbook_v1_relay_proto_goTypes = nil
+	file_orderbook_v1_relay_proto_depIdxs = nil
+}
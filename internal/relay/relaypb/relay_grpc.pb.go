@@ -0,0 +1,123 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: orderbook/v1/relay.proto
+
+// Messages streamed by the grpc storage driver. Implement OrderbookRelay to receive the
+// stats snapshots, stored levels and raw depth updates of every monitored book.
+// Regenerate the Go code with:
+//   protoc -I proto --go_out=. --go_opt=module=github.com/imajinl/crypto-orderbook --go-grpc_out=. --go-grpc_opt=module=github.com/imajinl/crypto-orderbook orderbook/v1/relay.proto
+
+package relaypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	OrderbookRelay_Stream_FullMethodName = "/orderbook.v1.OrderbookRelay/Stream"
+)
+
+// OrderbookRelayClient is the client API for OrderbookRelay service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OrderbookRelayClient interface {
+	// Stream carries the messages of one connection in the order they were produced; the
+	// server replies once the client closes the stream
+	Stream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RelayMessage, StreamSummary], error)
+}
+
+type orderbookRelayClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrderbookRelayClient(cc grpc.ClientConnInterface) OrderbookRelayClient {
+	return &orderbookRelayClient{cc}
+}
+
+func (c *orderbookRelayClient) Stream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RelayMessage, StreamSummary], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderbookRelay_ServiceDesc.Streams[0], OrderbookRelay_Stream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RelayMessage, StreamSummary]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderbookRelay_StreamClient = grpc.ClientStreamingClient[RelayMessage, StreamSummary]
+
+// OrderbookRelayServer is the server API for OrderbookRelay service.
+// All implementations must embed UnimplementedOrderbookRelayServer
+// for forward compatibility.
+type OrderbookRelayServer interface {
+	// Stream carries the messages of one connection in the order they were produced; the
+	// server replies once the client closes the stream
+	Stream(grpc.ClientStreamingServer[RelayMessage, StreamSummary]) error
+	mustEmbedUnimplementedOrderbookRelayServer()
+}
+
+// UnimplementedOrderbookRelayServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOrderbookRelayServer struct{}
+
+func (UnimplementedOrderbookRelayServer) Stream(grpc.ClientStreamingServer[RelayMessage, StreamSummary]) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedOrderbookRelayServer) mustEmbedUnimplementedOrderbookRelayServer() {}
+func (UnimplementedOrderbookRelayServer) testEmbeddedByValue()                        {}
+
+// UnsafeOrderbookRelayServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrderbookRelayServer will
+// result in compilation errors.
+type UnsafeOrderbookRelayServer interface {
+	mustEmbedUnimplementedOrderbookRelayServer()
+}
+
+func RegisterOrderbookRelayServer(s grpc.ServiceRegistrar, srv OrderbookRelayServer) {
+	// If the following call pancis, it indicates UnimplementedOrderbookRelayServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&OrderbookRelay_ServiceDesc, srv)
+}
+
+func _OrderbookRelay_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(OrderbookRelayServer).Stream(&grpc.GenericServerStream[RelayMessage, StreamSummary]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderbookRelay_StreamServer = grpc.ClientStreamingServer[RelayMessage, StreamSummary]
+
+// OrderbookRelay_ServiceDesc is the grpc.ServiceDesc for OrderbookRelay service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OrderbookRelay_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "orderbook.v1.OrderbookRelay",
+	HandlerType: (*OrderbookRelayServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _OrderbookRelay_Stream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "orderbook/v1/relay.proto",
+}
@@ -0,0 +1,259 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: orderbook/v1/service.proto
+
+// Queries and streams served by the monitor with -grpc-addr, reusing the messages of the
+// relay so a receiver of either decodes the same types.
+// Regenerate the Go code with:
+//   protoc -I proto --go_out=. --go_opt=module=github.com/imajinl/crypto-orderbook --go-grpc_out=. --go-grpc_opt=module=github.com/imajinl/crypto-orderbook orderbook/v1/service.proto
+
+package relaypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	OrderbookService_GetBook_FullMethodName       = "/orderbook.v1.OrderbookService/GetBook"
+	OrderbookService_GetStats_FullMethodName      = "/orderbook.v1.OrderbookService/GetStats"
+	OrderbookService_StreamUpdates_FullMethodName = "/orderbook.v1.OrderbookService/StreamUpdates"
+	OrderbookService_StreamStats_FullMethodName   = "/orderbook.v1.OrderbookService/StreamStats"
+)
+
+// OrderbookServiceClient is the client API for OrderbookService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OrderbookServiceClient interface {
+	// GetBook returns the top levels of one book; NOT_FOUND for an exchange that is not
+	// connected or a symbol that is not monitored, UNAVAILABLE while the book syncs
+	GetBook(ctx context.Context, in *GetBookRequest, opts ...grpc.CallOption) (*Depth, error)
+	// GetStats returns the stats snapshot of every initialized book, or of the exchanges asked for
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// StreamUpdates sends every depth update of the selected books as it is applied. A client
+	// that falls too far behind is ended with RESOURCE_EXHAUSTED and should fetch the book again
+	StreamUpdates(ctx context.Context, in *StreamUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DepthUpdate], error)
+	// StreamStats sends the stats snapshot of each selected book once per interval
+	StreamStats(ctx context.Context, in *StreamStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Snapshot], error)
+}
+
+type orderbookServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrderbookServiceClient(cc grpc.ClientConnInterface) OrderbookServiceClient {
+	return &orderbookServiceClient{cc}
+}
+
+func (c *orderbookServiceClient) GetBook(ctx context.Context, in *GetBookRequest, opts ...grpc.CallOption) (*Depth, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Depth)
+	err := c.cc.Invoke(ctx, OrderbookService_GetBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderbookServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, OrderbookService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderbookServiceClient) StreamUpdates(ctx context.Context, in *StreamUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DepthUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderbookService_ServiceDesc.Streams[0], OrderbookService_StreamUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamUpdatesRequest, DepthUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderbookService_StreamUpdatesClient = grpc.ServerStreamingClient[DepthUpdate]
+
+func (c *orderbookServiceClient) StreamStats(ctx context.Context, in *StreamStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Snapshot], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderbookService_ServiceDesc.Streams[1], OrderbookService_StreamStats_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamStatsRequest, Snapshot]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderbookService_StreamStatsClient = grpc.ServerStreamingClient[Snapshot]
+
+// OrderbookServiceServer is the server API for OrderbookService service.
+// All implementations must embed UnimplementedOrderbookServiceServer
+// for forward compatibility.
+type OrderbookServiceServer interface {
+	// GetBook returns the top levels of one book; NOT_FOUND for an exchange that is not
+	// connected or a symbol that is not monitored, UNAVAILABLE while the book syncs
+	GetBook(context.Context, *GetBookRequest) (*Depth, error)
+	// GetStats returns the stats snapshot of every initialized book, or of the exchanges asked for
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// StreamUpdates sends every depth update of the selected books as it is applied. A client
+	// that falls too far behind is ended with RESOURCE_EXHAUSTED and should fetch the book again
+	StreamUpdates(*StreamUpdatesRequest, grpc.ServerStreamingServer[DepthUpdate]) error
+	// StreamStats sends the stats snapshot of each selected book once per interval
+	StreamStats(*StreamStatsRequest, grpc.ServerStreamingServer[Snapshot]) error
+	mustEmbedUnimplementedOrderbookServiceServer()
+}
+
+// UnimplementedOrderbookServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOrderbookServiceServer struct{}
+
+func (UnimplementedOrderbookServiceServer) GetBook(context.Context, *GetBookRequest) (*Depth, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBook not implemented")
+}
+func (UnimplementedOrderbookServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedOrderbookServiceServer) StreamUpdates(*StreamUpdatesRequest, grpc.ServerStreamingServer[DepthUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUpdates not implemented")
+}
+func (UnimplementedOrderbookServiceServer) StreamStats(*StreamStatsRequest, grpc.ServerStreamingServer[Snapshot]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamStats not implemented")
+}
+func (UnimplementedOrderbookServiceServer) mustEmbedUnimplementedOrderbookServiceServer() {}
+func (UnimplementedOrderbookServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeOrderbookServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrderbookServiceServer will
+// result in compilation errors.
+type UnsafeOrderbookServiceServer interface {
+	mustEmbedUnimplementedOrderbookServiceServer()
+}
+
+func RegisterOrderbookServiceServer(s grpc.ServiceRegistrar, srv OrderbookServiceServer) {
+	// If the following call pancis, it indicates UnimplementedOrderbookServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&OrderbookService_ServiceDesc, srv)
+}
+
+func _OrderbookService_GetBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderbookServiceServer).GetBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderbookService_GetBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderbookServiceServer).GetBook(ctx, req.(*GetBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderbookService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderbookServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderbookService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderbookServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderbookService_StreamUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamUpdatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderbookServiceServer).StreamUpdates(m, &grpc.GenericServerStream[StreamUpdatesRequest, DepthUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderbookService_StreamUpdatesServer = grpc.ServerStreamingServer[DepthUpdate]
+
+func _OrderbookService_StreamStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderbookServiceServer).StreamStats(m, &grpc.GenericServerStream[StreamStatsRequest, Snapshot]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderbookService_StreamStatsServer = grpc.ServerStreamingServer[Snapshot]
+
+// OrderbookService_ServiceDesc is the grpc.ServiceDesc for OrderbookService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OrderbookService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "orderbook.v1.OrderbookService",
+	HandlerType: (*OrderbookServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBook",
+			Handler:    _OrderbookService_GetBook_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _OrderbookService_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamUpdates",
+			Handler:       _OrderbookService_StreamUpdates_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamStats",
+			Handler:       _OrderbookService_StreamStats_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "orderbook/v1/service.proto",
+}
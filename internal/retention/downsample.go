@@ -0,0 +1,452 @@
+package retention
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+)
+
+// mean accumulates the average of the values seen
+type mean struct {
+	sum   float64
+	count int
+}
+
+func (m *mean) add(value *float64) {
+	if value != nil {
+		m.sum += *value
+		m.count++
+	}
+}
+
+// value returns the average, nil when no value was seen
+func (m *mean) value() *float64 {
+	if m.count == 0 {
+		return nil
+	}
+	v := m.sum / float64(m.count)
+	return &v
+}
+
+// scalars returns the addresses of the pointer fields of a snapshot, in a fixed order
+func scalars(s *database.OrderbookSnapshotAPI) []**float64 {
+	return []**float64{
+		&s.BestBid, &s.BestAsk, &s.MidPrice, &s.Spread,
+		&s.BidLiquidity05Pct, &s.AskLiquidity05Pct, &s.BidLiquidity2Pct, &s.AskLiquidity2Pct,
+		&s.BidLiquidity10Pct, &s.AskLiquidity10Pct, &s.TotalBidsQty, &s.TotalAsksQty,
+		&s.BidNotional05Pct, &s.AskNotional05Pct, &s.BidNotional2Pct, &s.AskNotional2Pct,
+		&s.BidNotional10Pct, &s.AskNotional10Pct, &s.TotalBidsNotional, &s.TotalAsksNotional,
+		&s.Microprice,
+	}
+}
+
+// bucket accumulates the snapshots of one book falling into one interval
+type bucket struct {
+	exchange, symbol string
+	start            time.Time
+	scalars          []mean
+	imbalance        map[string]*[2]mean
+	bands            map[string]*[4]mean
+	curve            map[string]*[4]mean
+	impact           map[string]*[4]mean
+	slippage         map[string]*[4]mean
+	rolling          *[5]mean
+	flow             *[7]mean
+	funding          *[8]mean
+	basis            *[4]mean
+	basisSpot        string
+	resiliency       *[8]mean
+	shareBands       map[string]*[3]mean
+	share            *[3]mean
+	volatility       map[string]*[3]mean
+	ofi              map[string]*[2]mean
+	effective        *[5]mean
+	index            *[2]mean
+	indexWeighting   string
+	indexWeights     map[string]*mean
+	touch            *[5]mean
+	touchMin         [2]float64 // Smallest bid and ask touch of the bucket, set with touch
+}
+
+// bucketKey identifies a bucket
+type bucketKey struct {
+	exchange, symbol string
+	start            time.Time
+}
+
+// Downsample averages snapshots into one snapshot per book and interval of resolution,
+// timestamped at the start of the interval and sorted by time. Each metric is the mean of
+// the snapshots that had it, so a band missing from some snapshots averages the others
+func Downsample(snapshots []*database.OrderbookSnapshotAPI, resolution time.Duration) []*database.OrderbookSnapshotAPI {
+	buckets := make(map[bucketKey]*bucket)
+	for _, s := range snapshots {
+		key := bucketKey{s.Exchange, s.Symbol, s.Timestamp.UTC().Truncate(resolution)}
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{exchange: s.Exchange, symbol: s.Symbol, start: key.start, scalars: make([]mean, len(scalars(s)))}
+			buckets[key] = b
+		}
+		b.add(s)
+	}
+
+	result := make([]*database.OrderbookSnapshotAPI, 0, len(buckets))
+	for _, b := range buckets {
+		result = append(result, b.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].Timestamp.Before(result[j].Timestamp)
+		}
+		if result[i].Exchange != result[j].Exchange {
+			return result[i].Exchange < result[j].Exchange
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
+
+// add accumulates one snapshot
+func (b *bucket) add(s *database.OrderbookSnapshotAPI) {
+	for i, field := range scalars(s) {
+		b.scalars[i].add(*field)
+	}
+
+	for levels, value := range s.Imbalance {
+		m := entry(&b.imbalance, levels)
+		m[0].add(&value.Imbalance)
+		m[1].add(&value.WeightedMid)
+	}
+	addBands(&b.bands, s.LiquidityBands)
+	addBands(&b.curve, s.DepthCurve)
+	addImpact(&b.impact, s.Impact)
+	addImpact(&b.slippage, s.Slippage)
+
+	if r := s.Rolling; r != nil {
+		if b.rolling == nil {
+			b.rolling = &[5]mean{}
+		}
+		samples := float64(r.Samples)
+		b.rolling[0].add(&r.WindowSeconds)
+		b.rolling[1].add(&samples)
+		b.rolling[2].add(&r.AvgSpread)
+		b.rolling[3].add(&r.SpreadVolatility)
+		b.rolling[4].add(&r.MidReturnVolatility)
+	}
+
+	if f := s.Flow; f != nil {
+		if b.flow == nil {
+			b.flow = &[7]mean{}
+		}
+		trades, large := float64(f.Trades), float64(f.LargeTrades)
+		b.flow[0].add(&f.WindowSeconds)
+		b.flow[1].add(&f.CVD)
+		b.flow[2].add(&f.BuyVolume)
+		b.flow[3].add(&f.SellVolume)
+		b.flow[4].add(&f.BuyRatio)
+		b.flow[5].add(&trades)
+		b.flow[6].add(&large)
+	}
+
+	if f := s.Funding; f != nil {
+		if b.funding == nil {
+			b.funding = &[8]mean{}
+		}
+		for i, value := range []*float64{
+			&f.Rate, &f.SecondsToFunding, &f.MarkPrice, &f.IndexPrice,
+			&f.OpenInterest, &f.OpenInterestNotional, &f.OpenInterestChange, &f.WindowSeconds,
+		} {
+			b.funding[i].add(value)
+		}
+	}
+
+	if basis := s.Basis; basis != nil {
+		if b.basis == nil {
+			b.basis = &[4]mean{}
+		}
+		b.basisSpot = basis.SpotExchange
+		b.basis[0].add(&basis.SpotMid)
+		b.basis[1].add(&basis.BasisBps)
+		b.basis[2].add(&basis.AnnualizedBasis)
+		b.basis[3].add(&basis.AnnualizedFunding)
+	}
+
+	if r := s.Resiliency; r != nil {
+		if b.resiliency == nil {
+			b.resiliency = &[8]mean{}
+		}
+		depletions, recovered := float64(r.Depletions), float64(r.Recovered)
+		for i, value := range []*float64{
+			&r.Score, &depletions, &recovered, &r.MeanRecoverySeconds,
+			&r.Replenishment, &r.BidDepth, &r.AskDepth, &r.WindowSeconds,
+		} {
+			b.resiliency[i].add(value)
+		}
+	}
+
+	if m := s.MarketShare; m != nil {
+		if b.share == nil {
+			b.share = &[3]mean{}
+		}
+		b.share[0].add(&m.BestBid)
+		b.share[1].add(&m.BestAsk)
+		b.share[2].add(&m.SampledSeconds)
+		for label, value := range m.Bands {
+			band := entry(&b.shareBands, label)
+			band[0].add(&value.Bid)
+			band[1].add(&value.Ask)
+			band[2].add(&value.Total)
+		}
+	}
+
+	for window, value := range s.Volatility {
+		m := entry(&b.volatility, window)
+		returns := float64(value.Returns)
+		m[0].add(&value.Volatility)
+		m[1].add(&value.Annualized)
+		m[2].add(&returns)
+	}
+
+	for window, value := range s.OFI {
+		m := entry(&b.ofi, window)
+		updates := float64(value.Updates)
+		m[0].add(&value.OFI)
+		m[1].add(&updates)
+	}
+
+	if e := s.EffectiveSpread; e != nil {
+		if b.effective == nil {
+			b.effective = &[5]mean{}
+		}
+		for i, value := range []*float64{&e.RawBps, &e.TakerBps, &e.MakerBps, &e.MakerFeeBps, &e.TakerFeeBps} {
+			b.effective[i].add(value)
+		}
+	}
+
+	if x := s.IndexPrice; x != nil {
+		if b.index == nil {
+			b.index = &[2]mean{}
+		}
+		venues := float64(x.Venues)
+		b.indexWeighting = x.Weighting
+		b.index[0].add(&x.Price)
+		b.index[1].add(&venues)
+		for name, weight := range x.Weights {
+			entry(&b.indexWeights, name).add(&weight)
+		}
+	}
+
+	if t := s.Touch; t != nil {
+		if b.touch == nil {
+			b.touch = &[5]mean{}
+			b.touchMin = [2]float64{t.BidMin, t.AskMin}
+		}
+		updates := float64(t.Updates)
+		for i, value := range []*float64{&t.BidMean, &t.BidTimeWeighted, &t.AskMean, &t.AskTimeWeighted, &updates} {
+			b.touch[i].add(value)
+		}
+		b.touchMin = [2]float64{min(b.touchMin[0], t.BidMin), min(b.touchMin[1], t.AskMin)}
+	}
+}
+
+// snapshot returns the averaged snapshot of the bucket
+func (b *bucket) snapshot() *database.OrderbookSnapshotAPI {
+	s := &database.OrderbookSnapshotAPI{Exchange: b.exchange, Symbol: b.symbol, Timestamp: b.start}
+	for i, field := range scalars(s) {
+		*field = b.scalars[i].value()
+	}
+
+	if len(b.imbalance) > 0 {
+		s.Imbalance = make(map[string]database.ImbalanceAPI, len(b.imbalance))
+		for levels, m := range b.imbalance {
+			s.Imbalance[levels] = database.ImbalanceAPI{Imbalance: zero(m[0].value()), WeightedMid: zero(m[1].value())}
+		}
+	}
+	s.LiquidityBands = bandValues(b.bands)
+	s.DepthCurve = bandValues(b.curve)
+	s.Impact = impactValues(b.impact)
+	s.Slippage = impactValues(b.slippage)
+
+	if r := b.rolling; r != nil {
+		s.Rolling = &database.RollingAPI{
+			WindowSeconds:       zero(r[0].value()),
+			Samples:             int(math.Round(zero(r[1].value()))),
+			AvgSpread:           zero(r[2].value()),
+			SpreadVolatility:    zero(r[3].value()),
+			MidReturnVolatility: zero(r[4].value()),
+		}
+	}
+	if f := b.flow; f != nil {
+		s.Flow = &database.FlowAPI{
+			WindowSeconds: zero(f[0].value()),
+			CVD:           zero(f[1].value()),
+			BuyVolume:     zero(f[2].value()),
+			SellVolume:    zero(f[3].value()),
+			BuyRatio:      zero(f[4].value()),
+			Trades:        int(math.Round(zero(f[5].value()))),
+			LargeTrades:   int(math.Round(zero(f[6].value()))),
+		}
+	}
+	if f := b.funding; f != nil {
+		s.Funding = &database.FundingAPI{
+			Rate:                 zero(f[0].value()),
+			SecondsToFunding:     zero(f[1].value()),
+			MarkPrice:            zero(f[2].value()),
+			IndexPrice:           zero(f[3].value()),
+			OpenInterest:         zero(f[4].value()),
+			OpenInterestNotional: zero(f[5].value()),
+			OpenInterestChange:   zero(f[6].value()),
+			WindowSeconds:        zero(f[7].value()),
+		}
+	}
+	if basis := b.basis; basis != nil {
+		s.Basis = &database.BasisAPI{
+			SpotExchange:      b.basisSpot,
+			SpotMid:           zero(basis[0].value()),
+			BasisBps:          zero(basis[1].value()),
+			AnnualizedBasis:   zero(basis[2].value()),
+			AnnualizedFunding: zero(basis[3].value()),
+		}
+	}
+	if r := b.resiliency; r != nil {
+		s.Resiliency = &database.ResiliencyAPI{
+			Score:               zero(r[0].value()),
+			Depletions:          int(math.Round(zero(r[1].value()))),
+			Recovered:           int(math.Round(zero(r[2].value()))),
+			MeanRecoverySeconds: zero(r[3].value()),
+			Replenishment:       zero(r[4].value()),
+			BidDepth:            zero(r[5].value()),
+			AskDepth:            zero(r[6].value()),
+			WindowSeconds:       zero(r[7].value()),
+		}
+	}
+	if m := b.share; m != nil {
+		s.MarketShare = &database.MarketShareAPI{
+			BestBid:        zero(m[0].value()),
+			BestAsk:        zero(m[1].value()),
+			SampledSeconds: zero(m[2].value()),
+		}
+		if len(b.shareBands) > 0 {
+			s.MarketShare.Bands = make(map[string]database.BandShareAPI, len(b.shareBands))
+			for label, band := range b.shareBands {
+				s.MarketShare.Bands[label] = database.BandShareAPI{Bid: zero(band[0].value()), Ask: zero(band[1].value()), Total: zero(band[2].value())}
+			}
+		}
+	}
+	if len(b.volatility) > 0 {
+		s.Volatility = make(map[string]database.VolatilityAPI, len(b.volatility))
+		for window, m := range b.volatility {
+			s.Volatility[window] = database.VolatilityAPI{
+				Volatility: zero(m[0].value()),
+				Annualized: zero(m[1].value()),
+				Returns:    int(math.Round(zero(m[2].value()))),
+			}
+		}
+	}
+	if len(b.ofi) > 0 {
+		s.OFI = make(map[string]database.OFIAPI, len(b.ofi))
+		for window, m := range b.ofi {
+			s.OFI[window] = database.OFIAPI{OFI: zero(m[0].value()), Updates: int(math.Round(zero(m[1].value())))}
+		}
+	}
+	if e := b.effective; e != nil {
+		s.EffectiveSpread = &database.EffectiveSpreadAPI{
+			RawBps:      zero(e[0].value()),
+			TakerBps:    zero(e[1].value()),
+			MakerBps:    zero(e[2].value()),
+			MakerFeeBps: zero(e[3].value()),
+			TakerFeeBps: zero(e[4].value()),
+		}
+	}
+	if x := b.index; x != nil {
+		s.IndexPrice = &database.IndexAPI{
+			Price:     zero(x[0].value()),
+			Weighting: b.indexWeighting,
+			Venues:    int(math.Round(zero(x[1].value()))),
+			Weights:   make(map[string]float64, len(b.indexWeights)),
+		}
+		for name, weight := range b.indexWeights {
+			s.IndexPrice.Weights[name] = zero(weight.value())
+		}
+	}
+	if t := b.touch; t != nil {
+		s.Touch = &database.TouchAPI{
+			BidMean:         zero(t[0].value()),
+			BidMin:          b.touchMin[0],
+			BidTimeWeighted: zero(t[1].value()),
+			AskMean:         zero(t[2].value()),
+			AskMin:          b.touchMin[1],
+			AskTimeWeighted: zero(t[3].value()),
+			Updates:         int(math.Round(zero(t[4].value()))),
+		}
+	}
+	return s
+}
+
+// entry returns the accumulators of a map key, creating the map and entry as needed
+func entry[T any](m *map[string]*T, key string) *T {
+	if *m == nil {
+		*m = make(map[string]*T)
+	}
+	value, ok := (*m)[key]
+	if !ok {
+		value = new(T)
+		(*m)[key] = value
+	}
+	return value
+}
+
+// addBands accumulates band or curve liquidity keyed by label
+func addBands(m *map[string]*[4]mean, bands map[string]database.BandLiquidityAPI) {
+	for label, value := range bands {
+		band := entry(m, label)
+		band[0].add(value.Bid)
+		band[1].add(value.Ask)
+		band[2].add(value.BidNotional)
+		band[3].add(value.AskNotional)
+	}
+}
+
+// bandValues returns the averaged bands, nil when there are none
+func bandValues(m map[string]*[4]mean) map[string]database.BandLiquidityAPI {
+	if len(m) == 0 {
+		return nil
+	}
+	bands := make(map[string]database.BandLiquidityAPI, len(m))
+	for label, band := range m {
+		bands[label] = database.BandLiquidityAPI{Bid: band[0].value(), Ask: band[1].value(), BidNotional: band[2].value(), AskNotional: band[3].value()}
+	}
+	return bands
+}
+
+// addImpact accumulates market impact keyed by size or notional
+func addImpact(m *map[string]*[4]mean, impact map[string]database.ImpactAPI) {
+	for key, value := range impact {
+		cost := entry(m, key)
+		cost[0].add(value.BuyVWAP)
+		cost[1].add(value.BuySlippage)
+		cost[2].add(value.SellVWAP)
+		cost[3].add(value.SellSlippage)
+	}
+}
+
+// impactValues returns the averaged market impact, nil when there is none
+func impactValues(m map[string]*[4]mean) map[string]database.ImpactAPI {
+	if len(m) == 0 {
+		return nil
+	}
+	impact := make(map[string]database.ImpactAPI, len(m))
+	for key, cost := range m {
+		impact[key] = database.ImpactAPI{BuyVWAP: cost[0].value(), BuySlippage: cost[1].value(), SellVWAP: cost[2].value(), SellSlippage: cost[3].value()}
+	}
+	return impact
+}
+
+// zero dereferences an average, 0 when there was none
+func zero(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
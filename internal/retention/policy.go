@@ -0,0 +1,117 @@
+package retention
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tier is one downsampled copy of the snapshots, averaged into buckets of Resolution
+type Tier struct {
+	Resolution time.Duration
+	Keep       time.Duration // How long rows are kept, 0 forever
+}
+
+// Policy describes how long snapshots are kept at each resolution. Raw snapshots older
+// than Raw are rolled into the first tier and deleted, rows of each tier older than its
+// Keep are rolled into the next one, and those of the last tier are deleted
+type Policy struct {
+	Raw   time.Duration // How long raw snapshots are kept, 0 forever
+	Tiers []Tier        // Increasing resolutions
+}
+
+// Table returns the rollup table of a tier, e.g. orderbook_snapshots_1m
+func (t Tier) Table() string {
+	return "orderbook_snapshots_" + durationLabel(t.Resolution)
+}
+
+// ParsePolicy interprets a comma-separated policy such as raw=7d,1m=365d,1h=0, where each
+// entry after raw is a resolution and how long it is kept (0 forever); durations accept
+// Go syntax plus a d suffix for days
+func ParsePolicy(spec string) (Policy, error) {
+	var policy Policy
+	seenRaw := false
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Policy{}, fmt.Errorf("invalid retention entry %q: expected raw=<keep> or <resolution>=<keep>", entry)
+		}
+		keep, err := parseDuration(value)
+		if err != nil || keep < 0 {
+			return Policy{}, fmt.Errorf("invalid retention of %q: %q", name, value)
+		}
+
+		if name == "raw" {
+			if seenRaw || len(policy.Tiers) > 0 {
+				return Policy{}, fmt.Errorf("raw retention must come first, once")
+			}
+			policy.Raw, seenRaw = keep, true
+			continue
+		}
+
+		resolution, err := parseDuration(name)
+		if err != nil || resolution < time.Second || resolution%time.Second != 0 {
+			return Policy{}, fmt.Errorf("invalid resolution %q: expected whole seconds, e.g. 1m", name)
+		}
+		if n := len(policy.Tiers); n > 0 {
+			previous := policy.Tiers[n-1]
+			if resolution <= previous.Resolution || resolution%previous.Resolution != 0 {
+				return Policy{}, fmt.Errorf("resolution %s must be a multiple of %s", name, durationLabel(previous.Resolution))
+			}
+			if previous.Keep == 0 {
+				return Policy{}, fmt.Errorf("tier %s keeps rows forever, so %s would never receive any", durationLabel(previous.Resolution), name)
+			}
+		}
+		policy.Tiers = append(policy.Tiers, Tier{Resolution: resolution, Keep: keep})
+	}
+
+	if !seenRaw {
+		return Policy{}, fmt.Errorf("retention needs a raw=<keep> entry")
+	}
+	if policy.Raw == 0 && len(policy.Tiers) > 0 {
+		return Policy{}, fmt.Errorf("raw snapshots kept forever are never downsampled")
+	}
+	return policy, nil
+}
+
+// String formats a policy in the syntax of ParsePolicy
+func (p Policy) String() string {
+	entries := []string{"raw=" + durationLabel(p.Raw)}
+	for _, tier := range p.Tiers {
+		entries = append(entries, durationLabel(tier.Resolution)+"="+durationLabel(tier.Keep))
+	}
+	return strings.Join(entries, ",")
+}
+
+// parseDuration accepts time.ParseDuration syntax and whole days such as 7d
+func parseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// durationLabel formats a duration in its largest whole unit, e.g. 1m, 4h or 7d
+func durationLabel(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "0"
+	case d%(24*time.Hour) == 0:
+		return strconv.FormatInt(int64(d/(24*time.Hour)), 10) + "d"
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	default:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+}
@@ -0,0 +1,185 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+)
+
+// Store is a backend whose snapshots can be read back, so old rows are downsampled by this
+// process into rollup tables with the orderbook_snapshots columns (see Tier.Table)
+type Store interface {
+	CreateSnapshotTable(table string) error
+	OldestSnapshot(table string) (time.Time, bool, error)
+	SnapshotsBetween(table string, from, to time.Time) ([]*database.OrderbookSnapshotAPI, error)
+	InsertSnapshotsInto(table string, snapshots []*database.OrderbookSnapshotAPI) error
+	DeleteSnapshotsBefore(table string, cutoff time.Time) (int64, error)
+	DeleteDepthBefore(cutoff time.Time) (int64, error)
+}
+
+// Pruner is a backend that can only delete old rows; downsampling there is left to its
+// native rollups (e.g. ClickHouse materialized views)
+type Pruner interface {
+	PruneBefore(cutoff time.Time) (int64, error)
+}
+
+// rawTable holds the snapshots as collected
+const rawTable = "orderbook_snapshots"
+
+// Result counts the rows one run changed
+type Result struct {
+	Downsampled int64 // Rows written to rollup tables
+	Deleted     int64 // Rows deleted, including the levels of raw snapshots
+}
+
+// Job applies a policy to a backend on an interval
+type Job struct {
+	store    Store
+	pruner   Pruner
+	policy   Policy
+	interval time.Duration
+}
+
+// NewJob checks that the client supports retention and creates the rollup tables of the
+// policy. Clients that can only prune keep raw snapshots for Policy.Raw and ignore the tiers
+func NewJob(client any, policy Policy, interval time.Duration) (*Job, error) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	job := &Job{policy: policy, interval: interval}
+
+	switch c := client.(type) {
+	case Store:
+		job.store = c
+		for _, tier := range policy.Tiers {
+			if err := c.CreateSnapshotTable(tier.Table()); err != nil {
+				return nil, err
+			}
+		}
+	case Pruner:
+		job.pruner = c
+		if len(policy.Tiers) > 0 {
+			logger().Warn("Storage cannot downsample, so old snapshots are deleted without rollups", "event", "rollups_disabled", "client", fmt.Sprintf("%T", client), "raw", durationLabel(policy.Raw))
+		}
+	default:
+		return nil, fmt.Errorf("%T does not support retention", client)
+	}
+	return job, nil
+}
+
+// Start runs the job immediately and then on every interval until the context is cancelled
+func (j *Job) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if result, err := j.Run(time.Now()); err != nil {
+			logger().Error("Run failed", "event", "run_failed", "error", err)
+		} else if result.Downsampled > 0 || result.Deleted > 0 {
+			logger().Info("Downsampled and deleted snapshots", "event", "run", "downsampled", result.Downsampled, "deleted", result.Deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// logger returns the logger of retention jobs
+func logger() *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, "retention")
+}
+
+// Run applies the policy once as of now
+func (j *Job) Run(now time.Time) (Result, error) {
+	var result Result
+	if j.pruner != nil {
+		if j.policy.Raw == 0 {
+			return result, nil
+		}
+		deleted, err := j.pruner.PruneBefore(now.Add(-j.policy.Raw).Truncate(time.Second))
+		result.Deleted = deleted
+		return result, err
+	}
+
+	source, keep := rawTable, j.policy.Raw
+	for i := 0; i <= len(j.policy.Tiers); i++ {
+		var next *Tier
+		if i < len(j.policy.Tiers) {
+			next = &j.policy.Tiers[i]
+		}
+		if keep > 0 {
+			if err := j.expire(source, now.Add(-keep), next, &result); err != nil {
+				return result, fmt.Errorf("%s: %w", source, err)
+			}
+			if source == rawTable {
+				deleted, err := j.store.DeleteDepthBefore(now.Add(-keep).Truncate(time.Second))
+				result.Deleted += deleted
+				if err != nil {
+					return result, fmt.Errorf("levels: %w", err)
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		source, keep = next.Table(), next.Keep
+	}
+	return result, nil
+}
+
+// expire rolls the rows of source older than cutoff into the next tier, one window at a
+// time starting from the oldest row, deleting each window once its rollups are written.
+// Without a next tier the rows are deleted. The cutoff is aligned to the next resolution
+// so only complete buckets are rolled up
+func (j *Job) expire(source string, cutoff time.Time, next *Tier, result *Result) error {
+	if next == nil {
+		deleted, err := j.store.DeleteSnapshotsBefore(source, cutoff.Truncate(time.Second))
+		result.Deleted += deleted
+		return err
+	}
+
+	cutoff = cutoff.Truncate(next.Resolution)
+	window := next.Resolution * max(1, (time.Hour+next.Resolution-1)/next.Resolution)
+	done := time.Time{}
+	for {
+		oldest, ok, err := j.store.OldestSnapshot(source)
+		if err != nil || !ok {
+			return err
+		}
+		if oldest.Before(done) {
+			return fmt.Errorf("rows before %s remain after they were deleted", done.Format(time.RFC3339))
+		}
+		from := oldest.Truncate(next.Resolution)
+		if !from.Before(cutoff) {
+			return nil
+		}
+		to := from.Add(window)
+		if to.After(cutoff) {
+			to = cutoff
+		}
+
+		snapshots, err := j.store.SnapshotsBetween(source, from, to)
+		if err != nil {
+			return err
+		}
+		rollups := Downsample(snapshots, next.Resolution)
+		if err := j.store.InsertSnapshotsInto(next.Table(), rollups); err != nil {
+			return err
+		}
+		result.Downsampled += int64(len(rollups))
+
+		deleted, err := j.store.DeleteSnapshotsBefore(source, to)
+		result.Deleted += deleted
+		if err != nil {
+			return err
+		}
+		done = to
+	}
+}
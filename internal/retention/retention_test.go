@@ -0,0 +1,127 @@
+package retention
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("raw=7d, 1m=365d, 1h=0")
+	if err != nil {
+		t.Fatalf("ParsePolicy() error: %v", err)
+	}
+	want := Policy{Raw: 7 * 24 * time.Hour, Tiers: []Tier{{time.Minute, 365 * 24 * time.Hour}, {time.Hour, 0}}}
+	if policy.Raw != want.Raw || len(policy.Tiers) != 2 || policy.Tiers[0] != want.Tiers[0] || policy.Tiers[1] != want.Tiers[1] {
+		t.Errorf("ParsePolicy() = %+v, want %+v", policy, want)
+	}
+	if got := policy.String(); got != "raw=7d,1m=365d,1h=0" {
+		t.Errorf("String() = %q", got)
+	}
+	if table := policy.Tiers[0].Table(); table != "orderbook_snapshots_1m" {
+		t.Errorf("Table() = %q", table)
+	}
+
+	for _, spec := range []string{"", "1m=1d", "raw=1d,1m=1d,90s=1d", "raw=1d,1m=0,1h=1d", "raw=0,1m=1d", "raw=1d,500ms=1d", "raw=x"} {
+		if _, err := ParsePolicy(spec); err == nil {
+			t.Errorf("ParsePolicy(%q) expected an error", spec)
+		}
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	one, three := 1.0, 3.0
+	snapshots := []*database.OrderbookSnapshotAPI{
+		{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: start.Add(10 * time.Second), BestBid: &one,
+			Imbalance:      map[string]database.ImbalanceAPI{"5": {Imbalance: 0.2, WeightedMid: 100}},
+			LiquidityBands: map[string]database.BandLiquidityAPI{"25bp": {Bid: &one}}},
+		{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: start.Add(50 * time.Second), BestBid: &three,
+			Imbalance: map[string]database.ImbalanceAPI{"5": {Imbalance: 0.4, WeightedMid: 102}},
+			Rolling:   &database.RollingAPI{Samples: 7}},
+		{Exchange: "bybit", Symbol: "BTCUSDT", Timestamp: start.Add(70 * time.Second), BestBid: &three},
+	}
+
+	rollups := Downsample(snapshots, time.Minute)
+	if len(rollups) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(rollups))
+	}
+	first := rollups[0]
+	if !first.Timestamp.Equal(start) || *first.BestBid != 2 || first.BestAsk != nil {
+		t.Errorf("unexpected first bucket %+v", first)
+	}
+	if imbalance := first.Imbalance["5"]; imbalance.Imbalance < 0.3-1e-9 || imbalance.Imbalance > 0.3+1e-9 || imbalance.WeightedMid != 101 {
+		t.Errorf("unexpected imbalance %+v", imbalance)
+	}
+	if band := first.LiquidityBands["25bp"]; *band.Bid != 1 || band.Ask != nil {
+		t.Errorf("expected the band averaged over the snapshots that had it, got %+v", band)
+	}
+	if first.Rolling == nil || first.Rolling.Samples != 7 {
+		t.Errorf("unexpected rolling %+v", first.Rolling)
+	}
+	if second := rollups[1]; !second.Timestamp.Equal(start.Add(time.Minute)) || *second.BestBid != 3 || second.Imbalance != nil {
+		t.Errorf("unexpected second bucket %+v", second)
+	}
+}
+
+func TestJobSQLite(t *testing.T) {
+	client, err := database.NewSQLiteClient(filepath.Join(t.TempDir(), "book.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() error: %v", err)
+	}
+	defer client.Close()
+
+	now := time.Date(2024, 5, 10, 0, 0, 30, 0, time.UTC)
+	bid := 100.0
+	var snapshots []*database.OrderbookSnapshotAPI
+	// One snapshot every 20s over the last three hours
+	for at := now.Add(-3 * time.Hour); at.Before(now); at = at.Add(20*time.Second + 500*time.Millisecond) {
+		snapshots = append(snapshots, &database.OrderbookSnapshotAPI{Exchange: "okx", Symbol: "BTCUSDT", Timestamp: at, BestBid: &bid})
+	}
+	if err := client.InsertOrderbookSnapshotsBatch(snapshots); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, _ := ParsePolicy("raw=1h,1m=0")
+	job, err := NewJob(client, policy, time.Hour)
+	if err != nil {
+		t.Fatalf("NewJob() error: %v", err)
+	}
+	result, err := job.Run(now)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	cutoff := now.Add(-time.Hour).Truncate(time.Minute)
+	raw, _ := client.SnapshotsBetween("orderbook_snapshots", time.Time{}, now.Add(time.Second))
+	for _, s := range raw {
+		if s.Timestamp.Before(cutoff) {
+			t.Fatalf("raw snapshot at %s survived the %s cutoff", s.Timestamp, cutoff)
+		}
+	}
+	rollups, _ := client.SnapshotsBetween("orderbook_snapshots_1m", time.Time{}, now)
+	if len(rollups) != 120 || int64(len(rollups)) != result.Downsampled {
+		t.Errorf("expected 120 one-minute rollups, got %d (result %+v)", len(rollups), result)
+	}
+	if int(result.Deleted)+len(raw) != len(snapshots) {
+		t.Errorf("deleted %d and kept %d of %d snapshots", result.Deleted, len(raw), len(snapshots))
+	}
+	for _, r := range rollups {
+		if r.Timestamp.Second() != 0 || *r.BestBid != bid {
+			t.Fatalf("unexpected rollup %+v", r)
+		}
+	}
+
+	// A second run has nothing left to do
+	if result, err := job.Run(now); err != nil || result.Downsampled != 0 {
+		t.Errorf("second Run() = %+v, %v", result, err)
+	}
+}
+
+func TestNewJobUnsupported(t *testing.T) {
+	if _, err := NewJob(struct{}{}, Policy{Raw: time.Hour}, 0); err == nil {
+		t.Error("expected an error for a client without retention support")
+	}
+}
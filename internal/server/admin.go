@@ -0,0 +1,158 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Controller applies the runtime changes requested through the admin API
+// Changes take effect without a restart; methods return an error wrapping ErrNotFound or
+// ErrUnavailable to choose the status of a failed request, any other error is a bad request
+type Controller interface {
+	AdminState() AdminState
+	SetCollectorEnabled(enabled bool) error
+	AddExchange(name string) error
+	RemoveExchange(name string) error
+	SwitchSymbol(symbol string) error
+	SetIntervals(intervals map[string]time.Duration) error
+}
+
+// Errors a Controller wraps
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrUnavailable = errors.New("not available")
+)
+
+// AdminState is the runtime configuration reported by the admin API
+type AdminState struct {
+	Symbol           string            `json:"symbol"`
+	Exchanges        []string          `json:"exchanges"`           // Exchanges selected for monitoring
+	Running          []string          `json:"running"`             // Selected exchanges with a connection in progress or open
+	Available        []string          `json:"available"`           // Every exchange that can be added
+	CollectorEnabled *bool             `json:"collector_enabled"`   // Whether snapshots are stored, nil without a database
+	Intervals        map[string]string `json:"intervals,omitempty"` // Adjustable intervals by name, e.g. "db": "10s"
+}
+
+// adminRoutes registers the admin API on mux:
+//
+//	GET    /api/v1/admin                    runtime configuration
+//	PUT    /api/v1/admin/collector          {"enabled": bool} enables or disables storing snapshots
+//	PUT    /api/v1/admin/exchanges/{name}   starts monitoring an exchange, reconnecting it if it failed
+//	DELETE /api/v1/admin/exchanges/{name}   stops monitoring an exchange
+//	PUT    /api/v1/admin/symbol             {"symbol": "ETHUSDT"} switches every exchange to a symbol
+//	PUT    /api/v1/admin/intervals          {"db": "30s", "log": "1s"} changes intervals by name
+//
+// Every request needs the header "Authorization: Bearer <token>"; successful changes answer
+// with the new configuration
+func (s *Server) adminRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin", s.authorized(s.handleAdminState))
+	mux.HandleFunc("PUT /api/v1/admin/collector", s.authorized(s.handleAdminCollector))
+	mux.HandleFunc("PUT /api/v1/admin/exchanges/{name}", s.authorized(s.handleAdminAddExchange))
+	mux.HandleFunc("DELETE /api/v1/admin/exchanges/{name}", s.authorized(s.handleAdminRemoveExchange))
+	mux.HandleFunc("PUT /api/v1/admin/symbol", s.authorized(s.handleAdminSymbol))
+	mux.HandleFunc("PUT /api/v1/admin/intervals", s.authorized(s.handleAdminIntervals))
+}
+
+// authorized rejects requests without the admin token
+func (s *Server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	want := []byte("Bearer " + s.config.AdminToken)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid admin token")
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *Server) handleAdminState(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.config.Admin.AdminState())
+}
+
+func (s *Server) handleAdminCollector(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if !readBody(w, r, &body) {
+		return
+	}
+	if body.Enabled == nil {
+		writeError(w, http.StatusBadRequest, "enabled is required")
+		return
+	}
+	s.applyAdmin(w, s.config.Admin.SetCollectorEnabled(*body.Enabled))
+}
+
+func (s *Server) handleAdminAddExchange(w http.ResponseWriter, r *http.Request) {
+	s.applyAdmin(w, s.config.Admin.AddExchange(strings.ToLower(r.PathValue("name"))))
+}
+
+func (s *Server) handleAdminRemoveExchange(w http.ResponseWriter, r *http.Request) {
+	s.applyAdmin(w, s.config.Admin.RemoveExchange(strings.ToLower(r.PathValue("name"))))
+}
+
+func (s *Server) handleAdminSymbol(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Symbol string `json:"symbol"`
+	}
+	if !readBody(w, r, &body) {
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(body.Symbol))
+	if symbol == "" {
+		writeError(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	s.applyAdmin(w, s.config.Admin.SwitchSymbol(symbol))
+}
+
+func (s *Server) handleAdminIntervals(w http.ResponseWriter, r *http.Request) {
+	var body map[string]string
+	if !readBody(w, r, &body) {
+		return
+	}
+	if len(body) == 0 {
+		writeError(w, http.StatusBadRequest, "no intervals given")
+		return
+	}
+	intervals := make(map[string]time.Duration, len(body))
+	for name, value := range body {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid %s interval %q", name, value)
+			return
+		}
+		intervals[name] = interval
+	}
+	s.applyAdmin(w, s.config.Admin.SetIntervals(intervals))
+}
+
+// applyAdmin answers a change with the new configuration, or with the error that prevented it
+func (s *Server) applyAdmin(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, s.config.Admin.AdminState())
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, "%v", err)
+	case errors.Is(err, ErrUnavailable):
+		writeError(w, http.StatusConflict, "%v", err)
+	default:
+		writeError(w, http.StatusBadRequest, "%v", err)
+	}
+}
+
+// readBody decodes a JSON request body, answering bad requests itself
+func readBody(w http.ResponseWriter, r *http.Request, into any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(into); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: %v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeController records the changes asked of it
+type fakeController struct {
+	state     AdminState
+	intervals map[string]time.Duration
+}
+
+func (c *fakeController) AdminState() AdminState { return c.state }
+
+func (c *fakeController) SetCollectorEnabled(enabled bool) error {
+	if c.state.CollectorEnabled == nil {
+		return fmt.Errorf("no database configured: %w", ErrUnavailable)
+	}
+	*c.state.CollectorEnabled = enabled
+	return nil
+}
+
+func (c *fakeController) AddExchange(name string) error {
+	if !slices.Contains(c.state.Available, name) {
+		return fmt.Errorf("unknown exchange %s: %w", name, ErrNotFound)
+	}
+	c.state.Exchanges = append(c.state.Exchanges, name)
+	return nil
+}
+
+func (c *fakeController) RemoveExchange(name string) error {
+	i := slices.Index(c.state.Exchanges, name)
+	if i < 0 {
+		return fmt.Errorf("%s is not monitored: %w", name, ErrNotFound)
+	}
+	c.state.Exchanges = slices.Delete(c.state.Exchanges, i, i+1)
+	return nil
+}
+
+func (c *fakeController) SwitchSymbol(symbol string) error {
+	c.state.Symbol = symbol
+	return nil
+}
+
+func (c *fakeController) SetIntervals(intervals map[string]time.Duration) error {
+	for name := range intervals {
+		if name != "db" {
+			return fmt.Errorf("unknown interval %s", name)
+		}
+	}
+	c.intervals = intervals
+	return nil
+}
+
+func TestAdmin(t *testing.T) {
+	enabled := true
+	controller := &fakeController{state: AdminState{
+		Symbol:           "BTCUSDT",
+		Exchanges:        []string{"binance"},
+		Available:        []string{"binance", "okx"},
+		CollectorEnabled: &enabled,
+	}}
+	s := New(Config{Admin: controller, AdminToken: "secret"}, "BTCUSDT")
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	do := func(method, path, token, body string, want int) AdminState {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest() error: %v", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s error: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("%s %s status %d, want %d", method, path, resp.StatusCode, want)
+		}
+		var state AdminState
+		if want == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+				t.Fatalf("%s %s decode error: %v", method, path, err)
+			}
+		}
+		return state
+	}
+
+	do("GET", "/api/v1/admin", "", "", http.StatusUnauthorized)
+	do("GET", "/api/v1/admin", "wrong", "", http.StatusUnauthorized)
+	if state := do("GET", "/api/v1/admin", "secret", "", http.StatusOK); state.Symbol != "BTCUSDT" {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	if state := do("PUT", "/api/v1/admin/collector", "secret", `{"enabled": false}`, http.StatusOK); *state.CollectorEnabled {
+		t.Error("expected the collector disabled")
+	}
+	do("PUT", "/api/v1/admin/collector", "secret", `{}`, http.StatusBadRequest)
+	do("PUT", "/api/v1/admin/collector", "secret", `{"enabled": true, "extra": 1}`, http.StatusBadRequest)
+
+	if state := do("PUT", "/api/v1/admin/exchanges/OKX", "secret", "", http.StatusOK); !slices.Equal(state.Exchanges, []string{"binance", "okx"}) {
+		t.Errorf("exchanges after adding okx %v", state.Exchanges)
+	}
+	do("PUT", "/api/v1/admin/exchanges/mtgox", "secret", "", http.StatusNotFound)
+	if state := do("DELETE", "/api/v1/admin/exchanges/binance", "secret", "", http.StatusOK); !slices.Equal(state.Exchanges, []string{"okx"}) {
+		t.Errorf("exchanges after removing binance %v", state.Exchanges)
+	}
+
+	if state := do("PUT", "/api/v1/admin/symbol", "secret", `{"symbol": " ethusdt "}`, http.StatusOK); state.Symbol != "ETHUSDT" {
+		t.Errorf("symbol %s, want ETHUSDT", state.Symbol)
+	}
+	do("PUT", "/api/v1/admin/symbol", "secret", `{"symbol": ""}`, http.StatusBadRequest)
+
+	do("PUT", "/api/v1/admin/intervals", "secret", `{"db": "30s"}`, http.StatusOK)
+	if controller.intervals["db"] != 30*time.Second {
+		t.Errorf("intervals %v", controller.intervals)
+	}
+	do("PUT", "/api/v1/admin/intervals", "secret", `{"db": "-1s"}`, http.StatusBadRequest)
+	do("PUT", "/api/v1/admin/intervals", "secret", `{"log": "1s"}`, http.StatusBadRequest)
+
+	controller.state.CollectorEnabled = nil
+	do("PUT", "/api/v1/admin/collector", "secret", `{"enabled": true}`, http.StatusConflict)
+
+	// Without a token the admin API is not served at all
+	open := httptest.NewServer(New(Config{Admin: controller}, "BTCUSDT").Handler())
+	defer open.Close()
+	resp, err := http.Get(open.URL + "/api/v1/admin")
+	if err != nil {
+		t.Fatalf("GET error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("admin API without a token status %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/relay"
+	"github.com/imajinl/crypto-orderbook/internal/relay/relaypb"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Stats stream intervals
+const (
+	defaultStatsInterval = 5 * time.Second
+	minStatsInterval     = 100 * time.Millisecond
+)
+
+// updateBuffer is the number of depth updates held per StreamUpdates call before the
+// client is considered too slow
+const updateBuffer = 4096
+
+// grpcService implements OrderbookService (proto/orderbook/v1/service.proto) from the books
+// registered with the server
+type grpcService struct {
+	relaypb.UnimplementedOrderbookServiceServer
+	s *Server
+
+	mu          sync.RWMutex
+	subscribers map[*updateStream]struct{}
+}
+
+// updateStream is one StreamUpdates call waiting for depth updates
+type updateStream struct {
+	exchanges []string
+	updates   chan *relaypb.DepthUpdate
+	overflow  chan struct{} // Closed once an update found the buffer full
+	once      sync.Once
+}
+
+func newGRPCService(s *Server) *grpcService {
+	return &grpcService{s: s, subscribers: make(map[*updateStream]struct{})}
+}
+
+// publish hands an update to every stream selecting its exchange
+func (g *grpcService) publish(symbol string, update *exchange.DepthUpdate) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if len(g.subscribers) == 0 {
+		return
+	}
+	message := relay.DepthUpdateMessage(symbol, update)
+	for sub := range g.subscribers {
+		if !selected(sub.exchanges, message.Exchange) {
+			continue
+		}
+		select {
+		case sub.updates <- message:
+		default:
+			sub.once.Do(func() { close(sub.overflow) })
+		}
+	}
+}
+
+// GetBook returns the top levels of one book
+func (g *grpcService) GetBook(ctx context.Context, req *relaypb.GetBookRequest) (*relaypb.Depth, error) {
+	depth := int(req.GetDepth())
+	if depth == 0 {
+		depth = defaultDepth
+	}
+	if depth < 0 || depth > g.s.config.MaxDepth {
+		return nil, status.Errorf(codes.InvalidArgument, "depth must be between 1 and %d", g.s.config.MaxDepth)
+	}
+
+	snapshot, err := g.s.liveBook(req.GetExchange(), req.GetSymbol())
+	if err != nil {
+		code := codes.NotFound
+		if err.status == http.StatusServiceUnavailable {
+			code = codes.Unavailable
+		}
+		return nil, status.Error(code, err.message)
+	}
+	return &relaypb.Depth{
+		Exchange:  req.GetExchange(),
+		Symbol:    g.s.monitored(),
+		Timestamp: timestamppb.New(snapshot.TakenAt),
+		Bids:      levelMessages(snapshot.Bids[:min(depth, len(snapshot.Bids))]),
+		Asks:      levelMessages(snapshot.Asks[:min(depth, len(snapshot.Asks))]),
+	}, nil
+}
+
+// GetStats returns the stats snapshot of every selected, initialized book
+func (g *grpcService) GetStats(ctx context.Context, req *relaypb.GetStatsRequest) (*relaypb.GetStatsResponse, error) {
+	return &relaypb.GetStatsResponse{Snapshots: g.snapshots(req.GetExchanges())}, nil
+}
+
+// StreamUpdates sends depth updates of the selected books until the client goes away or
+// falls behind
+func (g *grpcService) StreamUpdates(req *relaypb.StreamUpdatesRequest, stream relaypb.OrderbookService_StreamUpdatesServer) error {
+	sub := &updateStream{
+		exchanges: req.GetExchanges(),
+		updates:   make(chan *relaypb.DepthUpdate, updateBuffer),
+		overflow:  make(chan struct{}),
+	}
+	g.mu.Lock()
+	g.subscribers[sub] = struct{}{}
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		delete(g.subscribers, sub)
+		g.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-sub.overflow:
+			return status.Errorf(codes.ResourceExhausted, "client fell more than %d updates behind; fetch the book again", updateBuffer)
+		case update := <-sub.updates:
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamStats sends the snapshots of the selected books now and then once per interval
+func (g *grpcService) StreamStats(req *relaypb.StreamStatsRequest, stream relaypb.OrderbookService_StreamStatsServer) error {
+	interval := defaultStatsInterval
+	if req.GetInterval() != nil {
+		interval = req.GetInterval().AsDuration()
+	}
+	if interval < minStatsInterval {
+		return status.Errorf(codes.InvalidArgument, "interval must be at least %v", minStatsInterval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, snapshot := range g.snapshots(req.GetExchanges()) {
+			if err := stream.Send(snapshot); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshots converts the stats of every selected, initialized book
+func (g *grpcService) snapshots(exchanges []string) []*relaypb.Snapshot {
+	symbol := g.s.monitored()
+	names, books := g.s.registered()
+	var snapshots []*relaypb.Snapshot
+	for i, name := range names {
+		if !selected(exchanges, name) {
+			continue
+		}
+		if book := books[i].ob.Snapshot(); book.Initialized {
+			snapshots = append(snapshots, relay.SnapshotMessage(collector.NewSnapshot(name, symbol, book, books[i].caps)))
+		}
+	}
+	return snapshots
+}
+
+// selected reports whether an exchange passes a filter; an empty filter selects all
+func selected(exchanges []string, name string) bool {
+	return len(exchanges) == 0 || slices.Contains(exchanges, name)
+}
+
+// levelMessages converts book levels
+func levelMessages(levels []types.PriceLevel) []*relaypb.Level {
+	messages := make([]*relaypb.Level, len(levels))
+	for i, level := range levels {
+		messages[i] = &relaypb.Level{Price: level.Price.InexactFloat64(), Quantity: level.Quantity.InexactFloat64()}
+	}
+	return messages
+}
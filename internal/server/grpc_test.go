@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/relay/relaypb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestGRPCService(t *testing.T) {
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		Bids: []exchange.PriceLevel{{Price: "99", Quantity: "1"}, {Price: "98", Quantity: "2"}},
+		Asks: []exchange.PriceLevel{{Price: "101", Quantity: "3"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	s := New(Config{GRPCAddr: "127.0.0.1:0"}, "BTCUSDT")
+	s.RegisterOrderbook("bybit", ob, exchange.Capabilities{})
+	s.RegisterOrderbook("pending", orderbook.New(), exchange.Capabilities{})
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer s.Close()
+	if s.Addr() != nil {
+		t.Error("expected no HTTP listener without an HTTP address")
+	}
+
+	conn, err := grpc.NewClient(s.GRPCAddr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+	defer conn.Close()
+	client := relaypb.NewOrderbookServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	book, err := client.GetBook(ctx, &relaypb.GetBookRequest{Exchange: "bybit", Symbol: "BTC-USDT", Depth: 1})
+	if err != nil {
+		t.Fatalf("GetBook() error: %v", err)
+	}
+	if len(book.Bids) != 1 || book.Bids[0].Price != 99 || book.Asks[0].Quantity != 3 {
+		t.Errorf("unexpected book %v", book)
+	}
+	for _, tc := range []struct {
+		req  *relaypb.GetBookRequest
+		want codes.Code
+	}{
+		{&relaypb.GetBookRequest{Exchange: "okx", Symbol: "BTCUSDT"}, codes.NotFound},
+		{&relaypb.GetBookRequest{Exchange: "bybit", Symbol: "ETHUSDT"}, codes.NotFound},
+		{&relaypb.GetBookRequest{Exchange: "pending", Symbol: "BTCUSDT"}, codes.Unavailable},
+		{&relaypb.GetBookRequest{Exchange: "bybit", Symbol: "BTCUSDT", Depth: -1}, codes.InvalidArgument},
+	} {
+		if _, err := client.GetBook(ctx, tc.req); status.Code(err) != tc.want {
+			t.Errorf("GetBook(%v) code %v, want %v", tc.req, status.Code(err), tc.want)
+		}
+	}
+
+	stats, err := client.GetStats(ctx, &relaypb.GetStatsRequest{})
+	if err != nil {
+		t.Fatalf("GetStats() error: %v", err)
+	}
+	if len(stats.Snapshots) != 1 || stats.Snapshots[0].Exchange != "bybit" || stats.Snapshots[0].GetBestBid() != 99 {
+		t.Errorf("unexpected stats %v", stats.Snapshots)
+	}
+
+	statsStream, err := client.StreamStats(ctx, &relaypb.StreamStatsRequest{Interval: durationpb.New(100 * time.Millisecond)})
+	if err != nil {
+		t.Fatalf("StreamStats() error: %v", err)
+	}
+	for range 2 {
+		snapshot, err := statsStream.Recv()
+		if err != nil {
+			t.Fatalf("StreamStats Recv() error: %v", err)
+		}
+		if snapshot.Exchange != "bybit" {
+			t.Errorf("unexpected snapshot %v", snapshot)
+		}
+	}
+	tooFast, err := client.StreamStats(ctx, &relaypb.StreamStatsRequest{Interval: durationpb.New(time.Millisecond)})
+	if err != nil {
+		t.Fatalf("StreamStats() error: %v", err)
+	}
+	if _, err := tooFast.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("StreamStats with 1ms interval code %v, want InvalidArgument", status.Code(err))
+	}
+
+	updates, err := client.StreamUpdates(ctx, &relaypb.StreamUpdatesRequest{Exchanges: []string{"bybit"}})
+	if err != nil {
+		t.Fatalf("StreamUpdates() error: %v", err)
+	}
+	// The stream registers once the server handles the call, which may be after it returns
+	for deadline := time.Now().Add(2 * time.Second); !streaming(s) && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.PublishDepth("BTCUSDT", &exchange.DepthUpdate{Exchange: "okx", Bids: []exchange.PriceLevel{{Price: "1", Quantity: "1"}}})
+	s.PublishDepth("BTCUSDT", &exchange.DepthUpdate{Exchange: "bybit", FinalUpdateID: 8, Bids: []exchange.PriceLevel{{Price: "99", Quantity: "0"}}})
+	update, err := updates.Recv()
+	if err != nil {
+		t.Fatalf("StreamUpdates Recv() error: %v", err)
+	}
+	if update.Exchange != "bybit" || update.FinalUpdateId != 8 || len(update.Bids) != 1 {
+		t.Errorf("unexpected update %v", update)
+	}
+}
+
+func streaming(s *Server) bool {
+	s.rpc.mu.RLock()
+	defer s.rpc.mu.RUnlock()
+	return len(s.rpc.subscribers) > 0
+}
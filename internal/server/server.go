@@ -0,0 +1,642 @@
+// Package server serves the live books of every exchange over HTTP as JSON, so other
+// programs can read levels and stats without a database or broker in between
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/heatmap"
+	"github.com/imajinl/crypto-orderbook/internal/logging"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/relay/relaypb"
+	"github.com/imajinl/crypto-orderbook/internal/symbols"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	"google.golang.org/grpc"
+)
+
+// Config configures the HTTP API and gRPC servers
+type Config struct {
+	Addr          string                 // HTTP listen address, e.g. :8080 or 127.0.0.1:8080; empty serves no HTTP
+	GRPCAddr      string                 // gRPC listen address for OrderbookService; empty serves no gRPC
+	MaxDepth      int                    // Most levels per side a book request may ask for, default 1000
+	ClientQueue   int                    // Messages held per WebSocket client before backpressure applies, default 1000
+	Backpressure  Backpressure           // Default policy for WebSocket clients that fall behind, default drop-oldest
+	StatsInterval time.Duration          // Interval between stats snapshots sent to WebSocket clients, applied by the caller's collector
+	Metrics       http.Handler           // Served at /metrics when set
+	Heatmap       *heatmap.Heatmap       // Served at /api/v1/heatmap/{exchange} when set
+	Flow          *analytics.Flow        // Served at /api/v1/flow when set
+	Spoofing      *analytics.Spoofing    // Served at /api/v1/spoofing when set
+	MarketShare   *analytics.MarketShare // Served at /api/v1/market-share when set
+	Volatility    *analytics.Volatility  // Realized volatility added to the book stats when set
+	Fees          *analytics.Fees        // Fee schedules of the fee-adjusted spread added to the book stats when set
+	Index         *analytics.Index       // Served at /api/v1/index and added to the stats when set
+	Admin         Controller             // Serves the admin API under /api/v1/admin when set with AdminToken
+	AdminToken    string                 // Bearer token every admin request must present
+}
+
+// defaultDepth is the number of levels per side returned when a request does not say
+const defaultDepth = 50
+
+// Server answers requests from the books registered with it
+// Books register and unregister like they do with a collector, so the server always
+// reflects the exchanges currently connected
+type Server struct {
+	config     Config
+	symbol     string
+	mu         sync.RWMutex
+	books      map[string]book
+	feeds      map[string]*feed
+	connects   map[string]int64                // Times each exchange registered a feed, kept across disconnects
+	collectors map[string]*collector.Collector // Reported at /api/v1/collector, by name
+	hub        *Hub
+	http       *http.Server
+	addr       net.Addr
+	closing    chan struct{} // Closed by Close to end event streams
+	closed     sync.Once
+	rpc        *grpcService
+	grpc       *grpc.Server
+	grpcAddr   net.Addr
+}
+
+// book is a registered orderbook and the capabilities of its feed
+type book struct {
+	ob   *orderbook.OrderBook
+	caps exchange.Capabilities
+}
+
+// New creates a server for the books of symbol; call Start to listen
+func New(config Config, symbol string) *Server {
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = 1000
+	}
+	if config.ClientQueue <= 0 {
+		config.ClientQueue = 1000
+	}
+	if config.Backpressure == "" {
+		config.Backpressure = DropOldest
+	}
+	s := &Server{config: config, symbol: symbol, books: make(map[string]book), feeds: make(map[string]*feed), connects: make(map[string]int64), collectors: make(map[string]*collector.Collector), closing: make(chan struct{}), hub: newHub(config.ClientQueue, config.Backpressure)}
+	s.http = &http.Server{Addr: config.Addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	s.rpc = newGRPCService(s)
+	s.grpc = grpc.NewServer()
+	relaypb.RegisterOrderbookServiceServer(s.grpc, s.rpc)
+	return s
+}
+
+// RegisterOrderbook serves the book of an exchange
+func (s *Server) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.books[name] = book{ob: ob, caps: caps}
+}
+
+// UnregisterOrderbook stops serving the book of an exchange
+func (s *Server) UnregisterOrderbook(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.books, name)
+	delete(s.feeds, name)
+}
+
+// SetSymbol changes the monitored symbol, for books registered after a symbol switch
+func (s *Server) SetSymbol(symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.symbol = symbol
+}
+
+// monitored returns the monitored symbol
+func (s *Server) monitored() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.symbol
+}
+
+// Handler returns the API routes:
+//
+//	GET /api/v1/exchanges                         connected exchanges and the state of their books
+//	GET /api/v1/stats[?exchange=name]             stats of every initialized book, or one; with the index price when configured
+//	GET /api/v1/stats/stream[?exchanges=&interval=] Server-Sent Events of the same stats, default every 5s
+//	GET /api/v1/book/{exchange}/{symbol}[?depth=] top levels per side, default 50
+//	GET /api/v1/collector                         stats of the storage, publishing and streaming collectors
+//	GET /api/v1/connections                       health, reconnects and message rates of the feeds
+//	GET /api/v1/heatmap/{exchange}[?minutes=]    depth by price bucket and time, when configured
+//	GET /api/v1/flow                              taker flow of every exchange, when configured
+//	GET /api/v1/spoofing                          spoofing and layering counters and recent events, when configured
+//	GET /api/v1/market-share                      venues ranked by their share of liquidity and best quotes, when configured
+//	GET /api/v1/index                             index price across the venues and the weight of each, when configured
+//	GET /ws[?exchanges=&symbols=&channels=&backpressure=] WebSocket stream of depth updates and stats
+//	GET /metrics                                  Prometheus metrics, when configured
+//	/api/v1/admin/...                             runtime reconfiguration, when configured; see adminRoutes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/exchanges", s.handleExchanges)
+	mux.HandleFunc("GET /api/v1/stats", s.handleStats)
+	mux.HandleFunc("GET /api/v1/stats/stream", s.handleStatsStream)
+	mux.HandleFunc("GET /api/v1/book/{exchange}/{symbol}", s.handleBook)
+	mux.HandleFunc("GET /api/v1/collector", s.handleCollectors)
+	mux.HandleFunc("GET /api/v1/connections", s.handleConnections)
+	if s.config.Heatmap != nil {
+		mux.HandleFunc("GET /api/v1/heatmap/{exchange}", s.handleHeatmap)
+	}
+	if s.config.Flow != nil {
+		mux.HandleFunc("GET /api/v1/flow", s.handleFlow)
+	}
+	if s.config.Spoofing != nil {
+		mux.HandleFunc("GET /api/v1/spoofing", s.handleSpoofing)
+	}
+	if s.config.MarketShare != nil {
+		mux.HandleFunc("GET /api/v1/market-share", s.handleMarketShare)
+	}
+	if s.config.Index != nil {
+		mux.HandleFunc("GET /api/v1/index", s.handleIndex)
+	}
+	mux.HandleFunc("GET /ws", s.hub.serveWS)
+	if s.config.Metrics != nil {
+		mux.Handle("GET /metrics", s.config.Metrics)
+	}
+	if s.config.Admin != nil && s.config.AdminToken != "" {
+		s.adminRoutes(mux)
+	}
+	return mux
+}
+
+// PublishDepth hands an update of the book of symbol to the WebSocket clients and gRPC
+// streams subscribed to it; symbol is the monitored symbol rather than the venue's native one
+func (s *Server) PublishDepth(symbol string, update *exchange.DepthUpdate) error {
+	s.rpc.publish(symbol, update)
+	return s.hub.PublishDepth(symbol, update)
+}
+
+// Start listens on the configured addresses and serves in the background
+func (s *Server) Start() error {
+	if s.config.Addr != "" {
+		listener, err := net.Listen("tcp", s.config.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", s.config.Addr, err)
+		}
+		s.addr = listener.Addr()
+		go func() {
+			if err := s.http.Serve(listener); err != nil && err != http.ErrServerClosed {
+				logger().Error("Stopped serving HTTP", "event", "serve_failed", "error", err)
+			}
+		}()
+	}
+	if s.config.GRPCAddr != "" {
+		listener, err := net.Listen("tcp", s.config.GRPCAddr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("failed to listen on %s: %w", s.config.GRPCAddr, err)
+		}
+		s.grpcAddr = listener.Addr()
+		go func() {
+			if err := s.grpc.Serve(listener); err != nil {
+				logger().Error("Stopped serving gRPC", "event", "serve_failed", "error", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// Addr returns the address HTTP is served on once started, nil without HTTP
+func (s *Server) Addr() net.Addr {
+	return s.addr
+}
+
+// GRPCAddr returns the address gRPC is served on once started, nil without gRPC
+func (s *Server) GRPCAddr() net.Addr {
+	return s.grpcAddr
+}
+
+// Hub returns the WebSocket fan-out; feed it depth updates with PublishDepth and stats
+// snapshots by driving a Collector with it
+func (s *Server) Hub() *Hub {
+	return s.hub
+}
+
+// Close disconnects WebSocket clients, event streams and gRPC streams, waits up to 5 seconds for HTTP
+// requests in flight, then stops the server
+func (s *Server) Close() error {
+	s.hub.Close()
+	s.closed.Do(func() { close(s.closing) })
+	s.grpc.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.http.Shutdown(ctx)
+}
+
+// ExchangeInfo describes a connected exchange and its book
+type ExchangeInfo struct {
+	Exchange         string     `json:"exchange"`
+	Symbol           string     `json:"symbol"`
+	NativeSymbol     string     `json:"native_symbol,omitempty"`
+	Initialized      bool       `json:"initialized"`
+	BidLevels        int        `json:"bid_levels"`
+	AskLevels        int        `json:"ask_levels"`
+	LastUpdate       *time.Time `json:"last_update,omitempty"`
+	StalenessSeconds float64    `json:"staleness_seconds"`
+	MaxDepth         int        `json:"max_depth"` // Levels per side the feed delivers, 0 for the full book
+	Checksum         bool       `json:"checksum"`
+	L3               bool       `json:"l3"`
+}
+
+// BookStats is the snapshot the collectors store for a book, plus the health of its feed
+type BookStats struct {
+	*database.OrderbookSnapshotAPI
+	EventsProcessed  int64   `json:"events_processed"`
+	EventsDropped    int64   `json:"events_dropped"`
+	BufferedEvents   int     `json:"buffered_events"`
+	SequenceGaps     int64   `json:"sequence_gaps"`
+	ChecksumFailures int64   `json:"checksum_failures"`
+	CrossedCount     int64   `json:"crossed_count"`
+	StalenessSeconds float64 `json:"staleness_seconds"`
+}
+
+// Book is the top of a book; levels are [price, quantity] strings, best first
+type Book struct {
+	Exchange     string      `json:"exchange"`
+	Symbol       string      `json:"symbol"`
+	Timestamp    time.Time   `json:"timestamp"`
+	LastUpdateID int64       `json:"last_update_id"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+func (s *Server) handleExchanges(w http.ResponseWriter, r *http.Request) {
+	symbol := s.monitored()
+	names, books := s.registered()
+	infos := make([]ExchangeInfo, 0, len(names))
+	for i, name := range names {
+		snapshot := books[i].ob.Snapshot()
+		info := ExchangeInfo{
+			Exchange:     name,
+			Symbol:       symbol,
+			NativeSymbol: books[i].caps.NativeSymbol,
+			Initialized:  snapshot.Initialized,
+			BidLevels:    len(snapshot.Bids),
+			AskLevels:    len(snapshot.Asks),
+			MaxDepth:     books[i].caps.MaxDepth,
+			Checksum:     books[i].caps.Checksum,
+			L3:           books[i].caps.L3,
+		}
+		if last := snapshot.Stats.LastUpdateTime; !last.IsZero() {
+			info.LastUpdate = &last
+			info.StalenessSeconds = snapshot.Stats.Staleness.Seconds()
+		}
+		infos = append(infos, info)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"symbol": symbol, "exchanges": infos})
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	symbol := s.monitored()
+	filter := r.URL.Query().Get("exchange")
+	var exchanges []string
+	if filter != "" {
+		exchanges = []string{filter}
+	}
+
+	stats := s.bookStats(symbol, exchanges)
+	if filter != "" && len(stats) == 0 {
+		writeError(w, http.StatusNotFound, "no initialized book for exchange %s", filter)
+		return
+	}
+	response := map[string]any{"symbol": symbol, "books": stats}
+	if s.config.Index != nil && filter == "" {
+		index, ok := s.config.Index.Compute()
+		response["index"] = newIndexResponse(symbol, index, ok)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// bookStats returns the stats of every initialized book of the selected exchanges; an empty
+// selection selects all
+func (s *Server) bookStats(symbol string, exchanges []string) []BookStats {
+	names, books := s.registered()
+	stats := make([]BookStats, 0, len(names))
+	for i, name := range names {
+		if !selected(exchanges, name) {
+			continue
+		}
+		snapshot := books[i].ob.Snapshot()
+		if !snapshot.Initialized {
+			continue
+		}
+		health := snapshot.Stats
+		book := collector.NewSnapshot(name, symbol, snapshot, books[i].caps)
+		if s.config.Volatility != nil {
+			if volatility, ok := s.config.Volatility.Stats(name); ok {
+				book.Volatility = collector.NewVolatility(volatility)
+			}
+		}
+		if s.config.Fees != nil {
+			if spread, ok := analytics.ComputeEffectiveSpread(snapshot, s.config.Fees.For(name)); ok {
+				book.EffectiveSpread = collector.NewEffectiveSpread(spread)
+			}
+		}
+		stats = append(stats, BookStats{
+			OrderbookSnapshotAPI: book,
+			EventsProcessed:      health.EventsProcessed,
+			EventsDropped:        health.EventsDropped,
+			BufferedEvents:       health.BufferedEvents,
+			SequenceGaps:         health.SequenceGaps,
+			ChecksumFailures:     health.ChecksumFailures,
+			CrossedCount:         health.CrossedCount,
+			StalenessSeconds:     health.Staleness.Seconds(),
+		})
+	}
+	return stats
+}
+
+func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("exchange")
+	depth := defaultDepth
+	if value := r.URL.Query().Get("depth"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > s.config.MaxDepth {
+			writeError(w, http.StatusBadRequest, "depth must be between 1 and %d", s.config.MaxDepth)
+			return
+		}
+		depth = n
+	}
+
+	snapshot, err := s.liveBook(name, r.PathValue("symbol"))
+	if err != nil {
+		writeError(w, err.status, "%s", err.message)
+		return
+	}
+	writeJSON(w, http.StatusOK, Book{
+		Exchange:     name,
+		Symbol:       s.monitored(),
+		Timestamp:    snapshot.TakenAt,
+		LastUpdateID: snapshot.LastUpdateID,
+		Bids:         levelPairs(snapshot.Bids[:min(depth, len(snapshot.Bids))]),
+		Asks:         levelPairs(snapshot.Asks[:min(depth, len(snapshot.Asks))]),
+	})
+}
+
+// handleHeatmap returns the heatmap matrix of a book for the last minutes asked for, or for
+// the whole window kept
+func (s *Server) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("exchange")
+	var window time.Duration
+	if value := r.URL.Query().Get("minutes"); value != "" {
+		minutes, err := strconv.ParseFloat(value, 64)
+		if err != nil || minutes <= 0 {
+			writeError(w, http.StatusBadRequest, "minutes must be a positive number")
+			return
+		}
+		window = time.Duration(minutes * float64(time.Minute))
+	}
+	matrix, ok := s.config.Heatmap.Matrix(name, window)
+	if !ok {
+		writeError(w, http.StatusNotFound, "exchange %s is not connected", name)
+		return
+	}
+	writeJSON(w, http.StatusOK, matrix)
+}
+
+// FlowResponse is the taker flow of every exchange with trades and of all of them together
+type FlowResponse struct {
+	Symbol       string                      `json:"symbol"`
+	Exchanges    map[string]database.FlowAPI `json:"exchanges"`
+	Consolidated *database.FlowAPI           `json:"consolidated"` // Nil before the first trade
+}
+
+// handleFlow returns the CVD and taker volumes of every exchange
+func (s *Server) handleFlow(w http.ResponseWriter, r *http.Request) {
+	response := FlowResponse{Symbol: s.monitored(), Exchanges: make(map[string]database.FlowAPI)}
+	for name, stats := range s.config.Flow.All() {
+		response.Exchanges[name] = *collector.NewFlow(stats)
+	}
+	if stats, ok := s.config.Flow.Consolidated(); ok {
+		response.Consolidated = collector.NewFlow(stats)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// SpoofingResponse is the spoofing and layering detected on every watched book
+type SpoofingResponse struct {
+	Symbol    string                    `json:"symbol"`
+	Exchanges map[string]SpoofingCounts `json:"exchanges"`
+	Events    []SpoofingEventResponse   `json:"events"` // Oldest first
+}
+
+// SpoofingCounts are the pulls and patterns counted on one book
+type SpoofingCounts struct {
+	Pulls     int        `json:"pulls"` // Large, short-lived and untraded pulls
+	Spoofing  int        `json:"spoofing"`
+	Layering  int        `json:"layering"`
+	LastEvent *time.Time `json:"last_event,omitempty"`
+}
+
+// SpoofingEventResponse is one detected pattern
+type SpoofingEventResponse struct {
+	Exchange        string    `json:"exchange"`
+	Kind            string    `json:"kind"` // spoofing or layering
+	Side            string    `json:"side"` // buy for bids, sell for asks
+	Price           string    `json:"price"`
+	Size            string    `json:"size"`
+	Pulls           int       `json:"pulls"`
+	LifetimeSeconds float64   `json:"lifetime_seconds"`
+	Time            time.Time `json:"time"`
+}
+
+// handleSpoofing returns the spoofing and layering counters and the latest events
+func (s *Server) handleSpoofing(w http.ResponseWriter, r *http.Request) {
+	response := SpoofingResponse{Symbol: s.monitored(), Exchanges: make(map[string]SpoofingCounts), Events: []SpoofingEventResponse{}}
+	for name, stats := range s.config.Spoofing.All() {
+		counts := SpoofingCounts{Pulls: stats.Pulls, Spoofing: stats.Spoofing, Layering: stats.Layering}
+		if !stats.LastEvent.IsZero() {
+			counts.LastEvent = &stats.LastEvent
+		}
+		response.Exchanges[name] = counts
+	}
+	for _, e := range s.config.Spoofing.Events() {
+		response.Events = append(response.Events, SpoofingEventResponse{
+			Exchange:        e.Exchange,
+			Kind:            string(e.Kind),
+			Side:            string(e.Side),
+			Price:           e.Price.String(),
+			Size:            e.Size.String(),
+			Pulls:           e.Pulls,
+			LifetimeSeconds: e.Lifetime.Seconds(),
+			Time:            e.Time,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// MarketShareResponse is the market share of every initialized book, the largest first
+type MarketShareResponse struct {
+	Symbol string               `json:"symbol"`
+	Venues []VenueShareResponse `json:"venues"`
+}
+
+// VenueShareResponse is the market share of one venue
+type VenueShareResponse struct {
+	Exchange string `json:"exchange"`
+	*database.MarketShareAPI
+}
+
+// handleMarketShare returns the venues ranked by their share of the consolidated liquidity and best quotes
+func (s *Server) handleMarketShare(w http.ResponseWriter, r *http.Request) {
+	response := MarketShareResponse{Symbol: s.monitored(), Venues: []VenueShareResponse{}}
+	for _, venue := range s.config.MarketShare.Ranked() {
+		response.Venues = append(response.Venues, VenueShareResponse{
+			Exchange:       venue.Exchange,
+			MarketShareAPI: collector.NewMarketShare(venue.MarketShareStats),
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// IndexResponse is the index price across the venues and the contribution of each
+type IndexResponse struct {
+	Symbol     string                   `json:"symbol"`
+	Price      *float64                 `json:"price"` // Null with too few venues left after trimming
+	Median     *float64                 `json:"median"`
+	Weighting  string                   `json:"weighting"`
+	Venues     int                      `json:"venues"`
+	Components []IndexComponentResponse `json:"components"`
+	Time       time.Time                `json:"time"`
+}
+
+// IndexComponentResponse is the contribution of one venue to the index price
+type IndexComponentResponse struct {
+	Exchange     string  `json:"exchange"`
+	Mid          float64 `json:"mid"`
+	DeviationBps float64 `json:"deviation_bps"` // From the median mid
+	Weight       float64 `json:"weight"`
+	Excluded     string  `json:"excluded,omitempty"` // stale, outlier or no weight
+}
+
+// newIndexResponse converts an index price into its response
+func newIndexResponse(symbol string, index analytics.IndexPrice, ok bool) IndexResponse {
+	response := IndexResponse{Symbol: symbol, Weighting: string(index.Weighting), Venues: index.Venues, Components: []IndexComponentResponse{}, Time: index.Time}
+	if ok {
+		price := index.Price.InexactFloat64()
+		response.Price = &price
+	}
+	if index.Median.IsPositive() {
+		median := index.Median.InexactFloat64()
+		response.Median = &median
+	}
+	for _, component := range index.Components {
+		response.Components = append(response.Components, IndexComponentResponse{
+			Exchange:     component.Exchange,
+			Mid:          component.Mid.InexactFloat64(),
+			DeviationBps: component.Deviation.InexactFloat64() * 10000,
+			Weight:       component.Weight.InexactFloat64(),
+			Excluded:     component.Excluded,
+		})
+	}
+	return response
+}
+
+// handleIndex returns the index price across the venues
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	index, ok := s.config.Index.Compute()
+	writeJSON(w, http.StatusOK, newIndexResponse(s.monitored(), index, ok))
+}
+
+// lookupError is a failed book lookup and the HTTP status it maps to
+type lookupError struct {
+	status  int
+	message string
+}
+
+// liveBook returns the current copy of a connected, initialized book of the monitored symbol
+func (s *Server) liveBook(name, symbol string) (*orderbook.BookSnapshot, *lookupError) {
+	s.mu.RLock()
+	entry, ok := s.books[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, &lookupError{http.StatusNotFound, fmt.Sprintf("exchange %s is not connected", name)}
+	}
+	if !s.matches(symbol, entry.caps) {
+		return nil, &lookupError{http.StatusNotFound, fmt.Sprintf("%s is not monitored, the book is %s", symbol, s.monitored())}
+	}
+
+	snapshot := entry.ob.Snapshot()
+	if !snapshot.Initialized {
+		return nil, &lookupError{http.StatusServiceUnavailable, fmt.Sprintf("the %s book is not initialized yet", name)}
+	}
+	return snapshot, nil
+}
+
+// registered returns the registered books sorted by exchange name
+func (s *Server) registered() ([]string, []book) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.books))
+	for name := range s.books {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	books := make([]book, len(names))
+	for i, name := range names {
+		books[i] = s.books[name]
+	}
+	return names, books
+}
+
+// matches reports whether a requested symbol names the monitored instrument, in any
+// notation symbols.Parse reads or as the venue's native symbol
+func (s *Server) matches(symbol string, caps exchange.Capabilities) bool {
+	return sameSymbol(symbol, s.monitored()) || strings.EqualFold(symbol, caps.NativeSymbol)
+}
+
+// sameSymbol reports whether two symbols name the same instrument, e.g. BTC-USDT and BTCUSDT
+func sameSymbol(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	first, err := symbols.Parse(a)
+	if err != nil {
+		return false
+	}
+	second, err := symbols.Parse(b)
+	return err == nil && first == second
+}
+
+// levelPairs converts levels to [price, quantity] strings
+func levelPairs(levels []types.PriceLevel) [][2]string {
+	pairs := make([][2]string, len(levels))
+	for i, level := range levels {
+		pairs[i] = [2]string{level.Price.String(), level.Quantity.String()}
+	}
+	return pairs
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger().Warn("Failed to write response", "event", "write_failed", "error", err)
+	}
+}
+
+// logger returns the logger of the HTTP and gRPC server
+func logger() *slog.Logger {
+	return slog.Default().With(logging.KeyComponent, "server")
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/imajinl/crypto-orderbook/internal/analytics"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/heatmap"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+)
+
+func TestServer(t *testing.T) {
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 7,
+		Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "1"}, {Price: "98", Quantity: "2"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "3"}, {Price: "102", Quantity: "4"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	s := New(Config{}, "BTCUSDT")
+	s.RegisterOrderbook("bybit", ob, exchange.Capabilities{NativeSymbol: "BTCUSDT"})
+	s.RegisterOrderbook("pending", orderbook.New(), exchange.Capabilities{})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	get := func(path string, want int, into any) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("GET %s status %d, want %d", path, resp.StatusCode, want)
+		}
+		if into != nil {
+			if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+				t.Fatalf("GET %s decode error: %v", path, err)
+			}
+		}
+	}
+
+	var book Book
+	get("/api/v1/book/bybit/BTC-USDT?depth=1", http.StatusOK, &book)
+	if book.LastUpdateID != 7 || len(book.Bids) != 1 || book.Bids[0] != [2]string{"99", "1"} || book.Asks[0] != [2]string{"101", "3"} {
+		t.Errorf("unexpected book %+v", book)
+	}
+	get("/api/v1/book/bybit/ETHUSDT", http.StatusNotFound, nil)
+	get("/api/v1/book/okx/BTCUSDT", http.StatusNotFound, nil)
+	get("/api/v1/book/pending/BTCUSDT", http.StatusServiceUnavailable, nil)
+	get("/api/v1/book/bybit/BTCUSDT?depth=0", http.StatusBadRequest, nil)
+
+	var exchanges struct {
+		Exchanges []ExchangeInfo `json:"exchanges"`
+	}
+	get("/api/v1/exchanges", http.StatusOK, &exchanges)
+	if len(exchanges.Exchanges) != 2 || exchanges.Exchanges[0].Exchange != "bybit" || exchanges.Exchanges[0].BidLevels != 2 || exchanges.Exchanges[1].Initialized {
+		t.Errorf("unexpected exchanges %+v", exchanges.Exchanges)
+	}
+
+	var stats struct {
+		Books []struct {
+			Exchange string  `json:"exchange"`
+			BestBid  float64 `json:"best_bid"`
+		} `json:"books"`
+	}
+	get("/api/v1/stats", http.StatusOK, &stats)
+	if len(stats.Books) != 1 || stats.Books[0].Exchange != "bybit" || stats.Books[0].BestBid != 99 {
+		t.Errorf("unexpected stats %+v", stats.Books)
+	}
+	get("/api/v1/stats?exchange=pending", http.StatusNotFound, nil)
+
+	s.UnregisterOrderbook("bybit")
+	get("/api/v1/book/bybit/BTCUSDT", http.StatusNotFound, nil)
+}
+
+func TestHeatmap(t *testing.T) {
+	depth := heatmap.New(heatmap.Config{}, "BTCUSDT")
+	depth.RegisterOrderbook("okx", orderbook.New(), exchange.Capabilities{})
+	server := httptest.NewServer(New(Config{Heatmap: depth}, "BTCUSDT").Handler())
+	defer server.Close()
+
+	for path, want := range map[string]int{
+		"/api/v1/heatmap/okx?minutes=5":  http.StatusOK,
+		"/api/v1/heatmap/okx?minutes=-1": http.StatusBadRequest,
+		"/api/v1/heatmap/kraken":         http.StatusNotFound,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error: %v", path, err)
+		}
+		var matrix heatmap.Matrix
+		if want == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&matrix); err != nil || matrix.Exchange != "okx" {
+				t.Errorf("GET %s decoded %+v, %v", path, matrix, err)
+			}
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s status %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestFlow(t *testing.T) {
+	flow := analytics.NewFlow(analytics.FlowConfig{}, "BTCUSDT")
+	flow.Record("okx", &exchange.Trade{Price: "100", Quantity: "2", Side: exchange.TakerBuy})
+	flow.Record("bybit", &exchange.Trade{Price: "100", Quantity: "0.5", Side: exchange.TakerSell})
+	server := httptest.NewServer(New(Config{Flow: flow}, "BTCUSDT").Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/flow")
+	if err != nil {
+		t.Fatalf("GET /api/v1/flow error: %v", err)
+	}
+	defer resp.Body.Close()
+	var body FlowResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if body.Symbol != "BTCUSDT" || len(body.Exchanges) != 2 || body.Exchanges["okx"].CVD != 2 {
+		t.Errorf("unexpected flow %+v", body)
+	}
+	if c := body.Consolidated; c == nil || c.CVD != 1.5 || c.BuyRatio != 0.8 || c.Trades != 2 {
+		t.Errorf("unexpected consolidated flow %+v", body.Consolidated)
+	}
+}
+
+func TestSpoofing(t *testing.T) {
+	spoofing := analytics.NewSpoofing(analytics.SpoofingConfig{}, "BTCUSDT")
+	spoofing.RegisterOrderbook("okx", orderbook.New(), exchange.Capabilities{Trades: true})
+	server := httptest.NewServer(New(Config{Spoofing: spoofing}, "BTCUSDT").Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/spoofing")
+	if err != nil {
+		t.Fatalf("GET /api/v1/spoofing error: %v", err)
+	}
+	defer resp.Body.Close()
+	var body SpoofingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if body.Symbol != "BTCUSDT" || len(body.Exchanges) != 1 || body.Exchanges["okx"].LastEvent != nil || body.Events == nil {
+		t.Errorf("unexpected spoofing %+v", body)
+	}
+}
+
+func TestMarketShare(t *testing.T) {
+	share := analytics.NewMarketShare(analytics.MarketShareConfig{})
+	ob := orderbook.New()
+	if err := ob.LoadSnapshot(&exchange.Snapshot{Bids: []exchange.PriceLevel{{Price: "99.9", Quantity: "1"}}, Asks: []exchange.PriceLevel{{Price: "100.1", Quantity: "1"}}}); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	share.RegisterOrderbook("okx", ob, exchange.Capabilities{})
+	share.RegisterOrderbook("bybit", orderbook.New(), exchange.Capabilities{})
+	server := httptest.NewServer(New(Config{MarketShare: share}, "BTCUSDT").Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/market-share")
+	if err != nil {
+		t.Fatalf("GET /api/v1/market-share error: %v", err)
+	}
+	defer resp.Body.Close()
+	var body MarketShareResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if body.Symbol != "BTCUSDT" || len(body.Venues) != 1 || body.Venues[0].Exchange != "okx" || body.Venues[0].Bands["0.5%"].Total != 1 {
+		t.Errorf("unexpected market share %+v", body)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	index := analytics.NewIndex(analytics.IndexConfig{})
+	ob := orderbook.New()
+	if err := ob.LoadSnapshot(&exchange.Snapshot{Bids: []exchange.PriceLevel{{Price: "99.9", Quantity: "1"}}, Asks: []exchange.PriceLevel{{Price: "100.1", Quantity: "1"}}}); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	index.RegisterOrderbook("okx", ob, exchange.Capabilities{})
+	server := httptest.NewServer(New(Config{Index: index}, "BTCUSDT").Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/index")
+	if err != nil {
+		t.Fatalf("GET /api/v1/index error: %v", err)
+	}
+	defer resp.Body.Close()
+	var body IndexResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if body.Symbol != "BTCUSDT" || body.Price == nil || *body.Price != 100 || body.Weighting != "equal" || len(body.Components) != 1 || body.Components[0].Weight != 1 {
+		t.Errorf("unexpected index %+v", body)
+	}
+}
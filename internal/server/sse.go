@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// handleStatsStream streams the stats of the selected books as Server-Sent Events, one
+// "stats" event per book now and then once per interval, for consumers without a WebSocket
+// client such as curl or a browser EventSource
+func (s *Server) handleStatsStream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	interval := defaultStatsInterval
+	if value := query.Get("interval"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid interval %q", value)
+			return
+		}
+		interval = parsed
+	}
+	if interval < minStatsInterval {
+		writeError(w, http.StatusBadRequest, "interval must be at least %v", minStatsInterval)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	exchanges := splitList(query.Get("exchanges"))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.writeStatsEvents(w, exchanges); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeStatsEvents writes an event per selected book, or a comment keeping the connection
+// alive through proxies when no book is initialized yet
+func (s *Server) writeStatsEvents(w http.ResponseWriter, exchanges []string) error {
+	stats := s.bookStats(s.monitored(), exchanges)
+	if len(stats) == 0 {
+		_, err := fmt.Fprint(w, ": waiting for books\n\n")
+		return err
+	}
+	for _, book := range stats {
+		data, err := json.Marshal(book)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: stats\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
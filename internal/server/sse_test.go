@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+)
+
+func TestStatsStream(t *testing.T) {
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		LastUpdateID: 3,
+		Bids:         []exchange.PriceLevel{{Price: "99", Quantity: "1"}},
+		Asks:         []exchange.PriceLevel{{Price: "101", Quantity: "2"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+
+	s := New(Config{}, "BTCUSDT")
+	s.RegisterOrderbook("okx", ob, exchange.Capabilities{})
+	s.RegisterOrderbook("bybit", orderbook.New(), exchange.Capabilities{})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	for _, path := range []string{"/api/v1/stats/stream?interval=1ms", "/api/v1/stats/stream?interval=soon"} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("GET %s status %d, want %d", path, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/api/v1/stats/stream?interval=100ms")
+	if err != nil {
+		t.Fatalf("GET stream error: %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type %q", got)
+	}
+
+	// The syncing book is skipped; two ticks give two events for okx
+	reader := bufio.NewReader(resp.Body)
+	for i := range 2 {
+		var event, data string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read error: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				break
+			}
+			if value, ok := strings.CutPrefix(line, "event: "); ok {
+				event = value
+			}
+			if value, ok := strings.CutPrefix(line, "data: "); ok {
+				data = value
+			}
+		}
+		var stats BookStats
+		if err := json.Unmarshal([]byte(data), &stats); err != nil {
+			t.Fatalf("event %d data %q: %v", i, data, err)
+		}
+		if event != "stats" || stats.Exchange != "okx" || stats.Symbol != "BTCUSDT" || stats.BestBid == nil || *stats.BestBid != 99 {
+			t.Errorf("event %d: %s %+v", i, event, stats.OrderbookSnapshotAPI)
+		}
+	}
+
+	// Closing the server ends the stream
+	s.Close()
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Errorf("stream ended with %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+// HealthReporter reports the health of the connection feeding a book; every exchange.Exchange is one
+type HealthReporter interface {
+	Health() exchange.HealthStatus
+}
+
+// minRateWindow is the shortest time a message rate is measured over
+const minRateWindow = time.Second
+
+// feed is the connection behind a registered book
+type feed struct {
+	reporter    HealthReporter
+	connectedAt time.Time
+	sampledAt   time.Time // When rate was last measured
+	sampled     int64     // Messages received by sampledAt
+	rate        float64   // Messages per second between the two latest samples
+}
+
+// ConnectionInfo describes the connection feeding a book
+type ConnectionInfo struct {
+	Exchange          string     `json:"exchange"`
+	Connected         bool       `json:"connected"`
+	ConnectedAt       time.Time  `json:"connected_at"`
+	LastPing          *time.Time `json:"last_ping,omitempty"`
+	Messages          int64      `json:"messages"`
+	Errors            int64      `json:"errors"`
+	Disconnects       int64      `json:"disconnects"`
+	Reconnects        int64      `json:"reconnects"`          // Times the exchange connected again since its first connection
+	MessagesPerSecond float64    `json:"messages_per_second"` // Over the time since the previous measurement, at least a second
+}
+
+// RegisterFeed reports the connection feeding the book of an exchange, until the book unregisters
+func (s *Server) RegisterFeed(name string, reporter HealthReporter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.feeds[name] = &feed{reporter: reporter, connectedAt: now, sampledAt: now}
+	s.connects[name]++
+}
+
+// AddCollector reports the stats of a collector under name
+func (s *Server) AddCollector(name string, c *collector.Collector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collectors[name] = c
+}
+
+func (s *Server) handleCollectors(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	stats := make(map[string]map[string]any, len(s.collectors))
+	for name, c := range s.collectors {
+		stats[name] = c.GetStats()
+	}
+	s.mu.RUnlock()
+	writeJSON(w, http.StatusOK, map[string]any{"collectors": stats})
+}
+
+func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	now := time.Now()
+	infos := make([]ConnectionInfo, 0, len(s.feeds))
+	for name, f := range s.feeds {
+		health := f.reporter.Health()
+		if elapsed := now.Sub(f.sampledAt); elapsed >= minRateWindow {
+			f.rate = float64(health.MessageCount-f.sampled) / elapsed.Seconds()
+			f.sampledAt, f.sampled = now, health.MessageCount
+		}
+		info := ConnectionInfo{
+			Exchange:          name,
+			Connected:         health.Connected,
+			ConnectedAt:       f.connectedAt,
+			Messages:          health.MessageCount,
+			Errors:            health.ErrorCount,
+			Disconnects:       health.Disconnects,
+			Reconnects:        s.connects[name] - 1,
+			MessagesPerSecond: f.rate,
+		}
+		if !health.LastPing.IsZero() {
+			info.LastPing = &health.LastPing
+		}
+		infos = append(infos, info)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Exchange < infos[j].Exchange })
+	writeJSON(w, http.StatusOK, map[string]any{"connections": infos})
+}
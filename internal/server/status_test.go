@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+)
+
+// fakeFeed reports a fixed health
+type fakeFeed struct {
+	health exchange.HealthStatus
+}
+
+func (f *fakeFeed) Health() exchange.HealthStatus { return f.health }
+
+func TestStatus(t *testing.T) {
+	s := New(Config{}, "BTCUSDT")
+	feed := &fakeFeed{health: exchange.HealthStatus{Connected: true, MessageCount: 40, ErrorCount: 1}}
+	s.RegisterOrderbook("okx", orderbook.New(), exchange.Capabilities{})
+	s.RegisterFeed("okx", feed)
+
+	// A second connection counts as a reconnect; the first measurement covers the time since it
+	s.UnregisterOrderbook("okx")
+	s.RegisterOrderbook("okx", orderbook.New(), exchange.Capabilities{})
+	s.RegisterFeed("okx", feed)
+	s.feeds["okx"].sampledAt = time.Now().Add(-2 * time.Second)
+
+	c := collector.NewCollector(nil, "BTCUSDT", time.Minute)
+	c.RegisterOrderbook("okx", orderbook.New(), exchange.Capabilities{})
+	s.AddCollector("db", c)
+
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+	get := func(path string, into any) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s status %d", path, resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+			t.Fatalf("GET %s decode error: %v", path, err)
+		}
+	}
+
+	var connections struct {
+		Connections []ConnectionInfo `json:"connections"`
+	}
+	get("/api/v1/connections", &connections)
+	if len(connections.Connections) != 1 {
+		t.Fatalf("got %d connections, want 1", len(connections.Connections))
+	}
+	info := connections.Connections[0]
+	if info.Exchange != "okx" || !info.Connected || info.Messages != 40 || info.Errors != 1 || info.Reconnects != 1 {
+		t.Errorf("unexpected connection %+v", info)
+	}
+	if info.MessagesPerSecond < 15 || info.MessagesPerSecond > 20 {
+		t.Errorf("rate %v, want about 20 messages over 2s", info.MessagesPerSecond)
+	}
+
+	var collectors struct {
+		Collectors map[string]map[string]any `json:"collectors"`
+	}
+	get("/api/v1/collector", &collectors)
+	db := collectors.Collectors["db"]
+	if db == nil || db["registered_count"] != float64(1) || db["insert_errors"] != float64(0) || db["last_store"] != nil || db["interval"] != "1m0s" {
+		t.Errorf("unexpected collector stats %v", db)
+	}
+
+	s.UnregisterOrderbook("okx")
+	get("/api/v1/connections", &connections)
+	if len(connections.Connections) != 0 {
+		t.Errorf("expected no connections after unregistering, got %+v", connections.Connections)
+	}
+}
@@ -0,0 +1,450 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/publisher"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// Backpressure decides what a WebSocket client that cannot keep up loses
+type Backpressure string
+
+const (
+	DropOldest Backpressure = "drop-oldest" // Discard the oldest queued message; clients see a dropped notice and should resync
+	Conflate   Backpressure = "conflate"    // Merge queued updates of a book into one and keep only its newest stats
+)
+
+// ParseBackpressure reads a backpressure policy; empty selects drop-oldest
+func ParseBackpressure(s string) (Backpressure, error) {
+	switch policy := Backpressure(strings.ToLower(strings.TrimSpace(s))); policy {
+	case "":
+		return DropOldest, nil
+	case DropOldest, Conflate:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown backpressure policy %q (want drop-oldest or conflate)", s)
+	}
+}
+
+// Channels a client can subscribe to
+const (
+	ChannelDepth = "depth"
+	ChannelStats = "stats"
+)
+
+// Message is one frame sent to WebSocket clients
+type Message struct {
+	Type    string                         `json:"type"` // depth, stats, dropped or error
+	Depth   *publisher.DepthMessage        `json:"depth,omitempty"`
+	Stats   *database.OrderbookSnapshotAPI `json:"stats,omitempty"`
+	Dropped int64                          `json:"dropped,omitempty"` // Messages lost to backpressure since the last notice
+	Error   string                         `json:"error,omitempty"`
+}
+
+// Subscription selects the messages a client receives; empty lists match everything
+// Clients set it with the exchanges, symbols and channels query parameters (comma
+// separated) and can replace it at any time by sending it as JSON
+type Subscription struct {
+	Exchanges []string `json:"exchanges"`
+	Symbols   []string `json:"symbols"`
+	Channels  []string `json:"channels"`
+}
+
+// validate rejects unknown channels
+func (s Subscription) validate() error {
+	for _, channel := range s.Channels {
+		if channel != ChannelDepth && channel != ChannelStats {
+			return fmt.Errorf("unknown channel %q (want depth or stats)", channel)
+		}
+	}
+	return nil
+}
+
+// matches reports whether a message of channel about a book passes the filter
+func (s Subscription) matches(channel, exchange, symbol string) bool {
+	if len(s.Channels) > 0 && !slices.Contains(s.Channels, channel) {
+		return false
+	}
+	if len(s.Exchanges) > 0 && !slices.Contains(s.Exchanges, exchange) {
+		return false
+	}
+	return len(s.Symbols) == 0 || slices.ContainsFunc(s.Symbols, func(filter string) bool { return sameSymbol(filter, symbol) })
+}
+
+// Hub fans normalized depth updates and stats snapshots out to the clients of /ws, each
+// with its own queue so a slow client only loses its own messages
+// It satisfies collector.DatabaseClient, so a Collector can drive the stats snapshots
+type Hub struct {
+	queueSize int
+	policy    Backpressure
+	upgrader  websocket.Upgrader
+	mu        sync.RWMutex
+	clients   map[*client]struct{}
+	closed    bool
+	dropped   atomic.Int64
+}
+
+// newHub creates a hub holding queueSize messages per client
+func newHub(queueSize int, policy Backpressure) *Hub {
+	return &Hub{
+		queueSize: queueSize,
+		policy:    policy,
+		// The API is meant for local tools, so pages served from any origin may connect
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		clients:  make(map[*client]struct{}),
+	}
+}
+
+// PublishDepth queues an update of the book of symbol for every client subscribed to it;
+// symbol is the monitored symbol rather than the venue's native one
+func (h *Hub) PublishDepth(symbol string, update *exchange.DepthUpdate) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.clients) == 0 {
+		return nil
+	}
+	message := publisher.NewDepthMessage(symbol, update)
+	for c := range h.clients {
+		if c.subscription().matches(ChannelDepth, message.Exchange, symbol) {
+			c.enqueue(Message{Type: ChannelDepth, Depth: &message})
+		}
+	}
+	return nil
+}
+
+// InsertOrderbookSnapshot queues a stats snapshot for every client subscribed to its book
+func (h *Hub) InsertOrderbookSnapshot(snapshot *database.OrderbookSnapshotAPI) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		if c.subscription().matches(ChannelStats, snapshot.Exchange, snapshot.Symbol) {
+			c.enqueue(Message{Type: ChannelStats, Stats: snapshot})
+		}
+	}
+	return nil
+}
+
+// InsertOrderbookSnapshotsBatch queues each snapshot as its own message
+func (h *Hub) InsertOrderbookSnapshotsBatch(snapshots []*database.OrderbookSnapshotAPI) error {
+	for _, snapshot := range snapshots {
+		if err := h.InsertOrderbookSnapshot(snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestConnection always succeeds; clients connect on their own
+func (h *Hub) TestConnection() error {
+	return nil
+}
+
+// Close disconnects every client and refuses new ones
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// Clients returns the number of connected clients
+func (h *Hub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// Dropped returns the messages lost to backpressure across all clients
+func (h *Hub) Dropped() int64 {
+	return h.dropped.Load()
+}
+
+// serveWS upgrades a request to a WebSocket and streams to it until either side closes
+func (h *Hub) serveWS(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	policy := h.policy
+	if value := query.Get("backpressure"); value != "" {
+		parsed, err := ParseBackpressure(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		policy = parsed
+	}
+	sub := Subscription{
+		Exchanges: splitList(query.Get("exchanges")),
+		Symbols:   splitList(query.Get("symbols")),
+		Channels:  splitList(query.Get("channels")),
+	}
+	if err := sub.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already replied
+		return
+	}
+	c := newClient(conn, h.queueSize, policy, &h.dropped)
+	c.sub = sub
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.Close()
+		return
+	}
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	go c.writeLoop()
+	c.readLoop()
+
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+	close(c.done)
+	conn.Close()
+}
+
+// Client timing; pings keep idle connections alive through proxies
+const (
+	writeTimeout = 10 * time.Second
+	pongTimeout  = 60 * time.Second
+	pingInterval = 30 * time.Second
+)
+
+// client is one WebSocket connection and the messages queued for it
+type client struct {
+	conn       *websocket.Conn
+	limit      int
+	policy     Backpressure
+	hubDropped *atomic.Int64
+	mu         sync.Mutex
+	sub        Subscription
+	queue      []*queued
+	books      map[string]*queued // Queued message of each book and channel, for conflation
+	dropped    int64              // Dropped since the last notice
+	wake       chan struct{}
+	done       chan struct{}
+}
+
+// queued is a message waiting to be written
+type queued struct {
+	key     string // Channel and book, empty for messages never conflated
+	message Message
+}
+
+func newClient(conn *websocket.Conn, limit int, policy Backpressure, hubDropped *atomic.Int64) *client {
+	return &client{
+		conn:       conn,
+		limit:      limit,
+		policy:     policy,
+		hubDropped: hubDropped,
+		books:      make(map[string]*queued),
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+}
+
+func (c *client) subscription() Subscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sub
+}
+
+// enqueue queues a message without blocking, applying the client's backpressure policy
+// once the queue is full
+func (c *client) enqueue(message Message) {
+	key := messageKey(message)
+
+	c.mu.Lock()
+	if c.policy == Conflate && key != "" {
+		if pending, ok := c.books[key]; ok {
+			pending.message = conflate(pending.message, message)
+			c.mu.Unlock()
+			return
+		}
+	}
+	if len(c.queue) >= c.limit {
+		oldest := c.queue[0]
+		c.queue = c.queue[1:]
+		if c.books[oldest.key] == oldest {
+			delete(c.books, oldest.key)
+		}
+		c.dropped++
+		c.hubDropped.Add(1)
+	}
+	entry := &queued{key: key, message: message}
+	c.queue = append(c.queue, entry)
+	if key != "" {
+		c.books[key] = entry
+	}
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take removes and returns the queued messages, preceded by a notice of any drops
+func (c *client) take() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	messages := make([]Message, 0, len(c.queue)+1)
+	if c.dropped > 0 {
+		messages = append(messages, Message{Type: "dropped", Dropped: c.dropped})
+		c.dropped = 0
+	}
+	for _, entry := range c.queue {
+		messages = append(messages, entry.message)
+	}
+	c.queue = nil
+	clear(c.books)
+	return messages
+}
+
+// writeLoop writes queued messages and pings until the connection fails or closes
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.wake:
+			for _, message := range c.take() {
+				c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+				if err := c.conn.WriteJSON(message); err != nil {
+					c.conn.Close()
+					return
+				}
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// readLoop applies subscriptions sent by the client until the connection closes
+func (c *client) readLoop() {
+	c.conn.SetReadLimit(64 << 10)
+	c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) && !errors.Is(err, net.ErrClosed) {
+				logger().Info("WebSocket client disconnected", "event", "client_disconnected", "remote", c.conn.RemoteAddr().String(), "error", err)
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+
+		var sub Subscription
+		if err := json.Unmarshal(data, &sub); err != nil {
+			c.enqueue(Message{Type: "error", Error: fmt.Sprintf("invalid subscription: %v", err)})
+			continue
+		}
+		if err := sub.validate(); err != nil {
+			c.enqueue(Message{Type: "error", Error: err.Error()})
+			continue
+		}
+		c.mu.Lock()
+		c.sub = sub
+		c.mu.Unlock()
+	}
+}
+
+// messageKey identifies the book and channel of a message for conflation
+func messageKey(message Message) string {
+	switch {
+	case message.Depth != nil:
+		return ChannelDepth + "|" + message.Depth.Exchange + "|" + message.Depth.Symbol
+	case message.Stats != nil:
+		return ChannelStats + "|" + message.Stats.Exchange + "|" + message.Stats.Symbol
+	default:
+		return ""
+	}
+}
+
+// conflate merges a newer message of a book into the one already queued: stats are
+// replaced, depth updates combine into one spanning both, the newer quantity of each
+// level winning, so applying it leaves the book as applying both would
+func conflate(older, newer Message) Message {
+	if older.Depth == nil || newer.Depth == nil {
+		return newer
+	}
+	merged := *newer.Depth
+	merged.FirstUpdateID = older.Depth.FirstUpdateID
+	merged.PrevUpdateID = older.Depth.PrevUpdateID
+	merged.Bids = mergeLevels(older.Depth.Bids, newer.Depth.Bids)
+	merged.Asks = mergeLevels(older.Depth.Asks, newer.Depth.Asks)
+	return Message{Type: ChannelDepth, Depth: &merged}
+}
+
+// mergeLevels overlays newer [price, quantity] levels on older ones, matching prices by
+// value so 100 and 100.0 are the same level
+func mergeLevels(older, newer [][2]string) [][2]string {
+	merged := append([][2]string(nil), older...)
+	index := make(map[string]int, len(merged))
+	for i, level := range merged {
+		index[priceKey(level[0])] = i
+	}
+	for _, level := range newer {
+		key := priceKey(level[0])
+		if i, ok := index[key]; ok {
+			merged[i] = level
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, level)
+	}
+	return merged
+}
+
+// priceKey normalizes a price string, leaving unparsable ones as they are
+func priceKey(price string) string {
+	if value, err := decimal.NewFromString(price); err == nil {
+		return value.String()
+	}
+	return price
+}
+
+// splitList splits a comma-separated query parameter, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/database"
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/publisher"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestHubStream(t *testing.T) {
+	s := New(Config{}, "BTCUSDT")
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+	defer s.Hub().Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?exchanges=bybit&channels=depth"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	defer conn.Close()
+	for deadline := time.Now().Add(time.Second); s.Hub().Clients() == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("client never registered")
+		}
+	}
+
+	hub := s.Hub()
+	hub.PublishDepth("BTCUSDT", &exchange.DepthUpdate{Exchange: "okx", FinalUpdateID: 1})
+	hub.InsertOrderbookSnapshot(&database.OrderbookSnapshotAPI{Exchange: "bybit", Symbol: "BTCUSDT"})
+	hub.PublishDepth("BTCUSDT", &exchange.DepthUpdate{Exchange: "bybit", FinalUpdateID: 2, Bids: []exchange.PriceLevel{{Price: "100", Quantity: "1"}}})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var message Message
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("ReadJSON() error: %v", err)
+	}
+	if message.Type != ChannelDepth || message.Depth.Exchange != "bybit" || message.Depth.FinalUpdateID != 2 {
+		t.Fatalf("unexpected message %+v", message)
+	}
+
+	// A new subscription replaces the filter
+	if err := conn.WriteJSON(Subscription{Channels: []string{"stats"}}); err != nil {
+		t.Fatalf("WriteJSON() error: %v", err)
+	}
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		if subscribed(hub, ChannelStats) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("subscription never applied")
+		}
+	}
+	hub.InsertOrderbookSnapshot(&database.OrderbookSnapshotAPI{Exchange: "okx", Symbol: "BTCUSDT"})
+	if err := conn.ReadJSON(&message); err != nil || message.Type != ChannelStats || message.Stats.Exchange != "okx" {
+		t.Fatalf("unexpected message %+v (%v)", message, err)
+	}
+
+	if err := conn.WriteJSON(Subscription{Channels: []string{"trades"}}); err != nil {
+		t.Fatalf("WriteJSON() error: %v", err)
+	}
+	if err := conn.ReadJSON(&message); err != nil || message.Type != "error" {
+		t.Fatalf("expected an error for an unknown channel, got %+v (%v)", message, err)
+	}
+}
+
+// subscribed reports whether every client of the hub has subscribed to exactly channel
+func subscribed(hub *Hub, channel string) bool {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	for c := range hub.clients {
+		if sub := c.subscription(); len(sub.Channels) != 1 || sub.Channels[0] != channel {
+			return false
+		}
+	}
+	return true
+}
+
+func TestClientBackpressure(t *testing.T) {
+	update := func(name string, first, final int64, bids ...exchange.PriceLevel) Message {
+		depth := publisher.NewDepthMessage("BTCUSDT", &exchange.DepthUpdate{Exchange: exchange.ExchangeName(name), FirstUpdateID: first, FinalUpdateID: final, Bids: bids})
+		return Message{Type: ChannelDepth, Depth: &depth}
+	}
+
+	var dropped atomic.Int64
+	c := newClient(nil, 2, DropOldest, &dropped)
+	c.enqueue(update("bybit", 1, 1))
+	c.enqueue(update("bybit", 2, 2))
+	c.enqueue(update("bybit", 3, 3))
+	messages := c.take()
+	if len(messages) != 3 || messages[0].Type != "dropped" || messages[0].Dropped != 1 || messages[1].Depth.FinalUpdateID != 2 {
+		t.Fatalf("unexpected drop-oldest queue %+v", messages)
+	}
+
+	c = newClient(nil, 2, Conflate, &dropped)
+	c.enqueue(update("bybit", 1, 4, exchange.PriceLevel{Price: "100", Quantity: "1"}, exchange.PriceLevel{Price: "99", Quantity: "2"}))
+	c.enqueue(update("okx", 1, 1))
+	c.enqueue(update("bybit", 5, 6, exchange.PriceLevel{Price: "100.0", Quantity: "0"}, exchange.PriceLevel{Price: "98", Quantity: "3"}))
+	messages = c.take()
+	if len(messages) != 2 {
+		t.Fatalf("expected the bybit updates to conflate, got %+v", messages)
+	}
+	merged := messages[0].Depth
+	want := [][2]string{{"100.0", "0"}, {"99", "2"}, {"98", "3"}}
+	if merged.FirstUpdateID != 1 || merged.FinalUpdateID != 6 || len(merged.Bids) != 3 {
+		t.Fatalf("unexpected conflated update %+v", merged)
+	}
+	for i := range want {
+		if merged.Bids[i] != want[i] {
+			t.Errorf("bid %d = %v, want %v", i, merged.Bids[i], want[i])
+		}
+	}
+	if dropped.Load() != 1 {
+		t.Errorf("hub counted %d drops, want 1", dropped.Load())
+	}
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/config"
+)
+
+// None is the driver that disables storage
+const None = "none"
+
+// Constructor creates a storage client from its configuration; a nil client disables storage
+type Constructor func(config config.StorageConfig) (collector.DatabaseClient, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Constructor)
+)
+
+// Register makes a storage driver available by name. Downstream packages can call it
+// from init to add private backends without modifying this package.
+// It panics if the name is registered twice or the constructor is nil.
+func Register(name string, constructor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if constructor == nil {
+		panic(fmt.Sprintf("storage: Register constructor is nil for %s", name))
+	}
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("storage: Register called twice for %s", name))
+	}
+	registry[name] = constructor
+}
+
+// Drivers returns the registered driver names, sorted
+func Drivers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the client of the configured driver (supabase when empty) and tests its
+// connection. It returns a nil client for the none driver
+func New(config config.StorageConfig) (collector.DatabaseClient, error) {
+	if config.Driver == "" {
+		config.Driver = "supabase"
+	}
+
+	registryMu.RLock()
+	constructor, ok := registry[config.Driver]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown storage driver %q: expected one of %v", config.Driver, Drivers())
+	}
+
+	client, err := constructor(config)
+	if err != nil || client == nil {
+		return nil, err
+	}
+	if err := client.TestConnection(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%s connection test failed: %w", config.Driver, err)
+	}
+	return client, nil
+}
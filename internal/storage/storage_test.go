@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/imajinl/crypto-orderbook/internal/collector"
+	"github.com/imajinl/crypto-orderbook/internal/config"
+	"github.com/imajinl/crypto-orderbook/internal/database"
+)
+
+func TestNew(t *testing.T) {
+	client, err := New(config.StorageConfig{Driver: None})
+	if err != nil || client != nil {
+		t.Fatalf("New(none) = %v, %v; want no client", client, err)
+	}
+
+	if _, err := New(config.StorageConfig{Driver: "mongo"}); err == nil {
+		t.Error("expected an error for an unknown driver")
+	}
+	if _, err := New(config.StorageConfig{Driver: "postgres"}); err == nil {
+		t.Error("expected an error without a DSN")
+	}
+	if _, err := New(config.StorageConfig{Driver: "csv", Path: t.TempDir(), CSV: database.CSVConfig{Columns: []string{"nope"}}}); err == nil {
+		t.Error("expected an error for an unknown CSV column")
+	}
+
+	client, err = New(config.StorageConfig{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "book.db")})
+	if err != nil {
+		t.Fatalf("New(sqlite) error: %v", err)
+	}
+	defer client.Close()
+	if _, ok := client.(collector.DepthWriter); !ok {
+		t.Error("expected the sqlite client to store levels")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-memory", func(config config.StorageConfig) (collector.DatabaseClient, error) {
+		return database.NewSQLiteClient(filepath.Join(t.TempDir(), "memory.db"))
+	})
+
+	found := false
+	for _, name := range Drivers() {
+		found = found || name == "test-memory"
+	}
+	if !found {
+		t.Errorf("registered driver missing from %v", Drivers())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a name twice to panic")
+		}
+	}()
+	Register("supabase", func(config config.StorageConfig) (collector.DatabaseClient, error) { return nil, nil })
+}
@@ -0,0 +1,160 @@
+// Package symbols resolves one canonical instrument input into the symbol each
+// venue uses, replacing per-adapter string munging for the common cases
+package symbols
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type identifies the kind of instrument
+type Type string
+
+const (
+	Spot      Type = "spot"
+	Perpetual Type = "perpetual"
+	Future    Type = "future"
+)
+
+// Instrument is a canonical instrument definition
+// An empty Quote means "the venue's default quote" (e.g., BTC-PERP)
+type Instrument struct {
+	Base         string
+	Quote        string
+	Type         Type
+	Expiry       string  // Futures only, in the venue-neutral form given by the user (e.g., 250627)
+	ContractSize float64 // Base units per contract; 1 for spot and linear perpetuals
+}
+
+// knownQuotes are tried longest first when splitting a compact symbol like BTCUSDT
+var knownQuotes = []string{"FDUSD", "USDT", "USDC", "BUSD", "USD", "EUR", "GBP", "JPY", "TRY", "SGD", "DAI", "BTC", "ETH"}
+
+// aliases maps venue-specific asset codes to their canonical names
+var aliases = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+}
+
+// perpSuffixes mark perpetual contracts across venues
+var perpSuffixes = []string{"PERPETUAL", "PERP", "SWAP"}
+
+// Parse reads an instrument from any of the common notations
+// Examples: BTCUSDT, btc_usdt, BTC-USD, XBT/USD -> spot; BTC-PERP, BTC-USDT-SWAP,
+// BTC-PERPETUAL, PERP_BTC_USDT -> perpetual; BTC-USD-250627 -> future
+func Parse(input string) (Instrument, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(input))
+	if symbol == "" {
+		return Instrument{}, fmt.Errorf("empty symbol")
+	}
+
+	inst := Instrument{Type: Spot, ContractSize: 1}
+
+	// WOO X style prefix
+	if strings.HasPrefix(symbol, "PERP_") {
+		symbol = strings.TrimPrefix(symbol, "PERP_")
+		inst.Type = Perpetual
+	}
+
+	parts := strings.FieldsFunc(symbol, func(r rune) bool {
+		return r == '-' || r == '_' || r == '/' || r == ':'
+	})
+
+	if len(parts) > 1 && isPerpSuffix(parts[len(parts)-1]) {
+		inst.Type = Perpetual
+		parts = parts[:len(parts)-1]
+	}
+
+	if len(parts) > 2 || (len(parts) == 2 && isExpiry(parts[1])) {
+		if !isExpiry(parts[len(parts)-1]) {
+			return Instrument{}, fmt.Errorf("unrecognized symbol %s", input)
+		}
+		inst.Type = Future
+		inst.Expiry = parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+
+	switch len(parts) {
+	case 1:
+		base, quote := splitCompact(parts[0])
+		if quote == "" && inst.Type == Spot {
+			return Instrument{}, fmt.Errorf("cannot find quote asset in %s", input)
+		}
+		inst.Base, inst.Quote = base, quote
+	case 2:
+		inst.Base, inst.Quote = parts[0], parts[1]
+	default:
+		return Instrument{}, fmt.Errorf("unrecognized symbol %s", input)
+	}
+
+	inst.Base = canonicalAsset(inst.Base)
+	inst.Quote = canonicalAsset(inst.Quote)
+	return inst, nil
+}
+
+// String returns the canonical notation: BTC-USDT, BTC-USDT-PERP, BTC-PERP or BTC-USD-250627
+func (i Instrument) String() string {
+	parts := []string{i.Base}
+	if i.Quote != "" {
+		parts = append(parts, i.Quote)
+	}
+
+	switch i.Type {
+	case Perpetual:
+		parts = append(parts, "PERP")
+	case Future:
+		parts = append(parts, i.Expiry)
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// QuoteOr returns the quote asset, or def when the input left it to the venue
+func (i Instrument) QuoteOr(def string) string {
+	if i.Quote == "" {
+		return def
+	}
+	return i.Quote
+}
+
+// splitCompact splits a compact symbol such as BTCUSDT into base and quote
+// A symbol without a known quote (e.g., BTC in BTC-PERP) is returned as the base
+func splitCompact(symbol string) (string, string) {
+	for _, quote := range knownQuotes {
+		if len(symbol) > len(quote) && strings.HasSuffix(symbol, quote) {
+			return strings.TrimSuffix(symbol, quote), quote
+		}
+	}
+	return symbol, ""
+}
+
+// canonicalAsset maps asset aliases to their canonical code
+func canonicalAsset(asset string) string {
+	if canonical, ok := aliases[asset]; ok {
+		return canonical
+	}
+	return asset
+}
+
+// isPerpSuffix reports whether a symbol part marks a perpetual
+func isPerpSuffix(part string) bool {
+	for _, suffix := range perpSuffixes {
+		if part == suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// isExpiry reports whether a symbol part looks like an expiry (250627 or 27JUN25)
+func isExpiry(part string) bool {
+	if len(part) < 6 || len(part) > 7 {
+		return false
+	}
+	digits := 0
+	for _, r := range part {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits == len(part) || (digits >= 3 && part[0] >= '0' && part[0] <= '9')
+}
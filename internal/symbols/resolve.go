@@ -0,0 +1,155 @@
+package symbols
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+)
+
+// Listing is an instrument as listed on one venue
+type Listing struct {
+	Instrument
+	Exchange exchange.ExchangeName
+	Native   string           // Symbol the venue uses for the instrument
+	Scale    types.FixedScale // Decimals for fixed-point books; zero fields use types.DefaultFixedScale
+}
+
+// FixedScale returns the price and quantity decimals of the listing for fixed-point books
+func (l Listing) FixedScale() types.FixedScale {
+	scale := l.Scale
+	if scale.PriceDecimals == 0 {
+		scale.PriceDecimals = types.DefaultFixedScale.PriceDecimals
+	}
+	if scale.QtyDecimals == 0 {
+		scale.QtyDecimals = types.DefaultFixedScale.QtyDecimals
+	}
+	return scale
+}
+
+// Override pins the native symbol (and optionally the contract size and the
+// fixed-point decimals) of one canonical instrument on one venue
+type Override struct {
+	Symbol        string  `json:"symbol"`
+	ContractSize  float64 `json:"contract_size,omitempty"`
+	PriceDecimals int32   `json:"price_decimals,omitempty"`
+	QtyDecimals   int32   `json:"qty_decimals,omitempty"`
+}
+
+var (
+	overridesMu sync.RWMutex
+	overrides   = make(map[exchange.ExchangeName]map[string]Override)
+)
+
+// LoadOverrides reads an override file and merges it into the translation table
+// The file maps exchange names to canonical instruments, e.g.
+//
+//	{"kraken": {"BTC-USD": {"symbol": "XBT/USD"}},
+//	 "okx": {"BTC-USDT-PERP": {"symbol": "BTC-USDT-SWAP", "contract_size": 0.01}},
+//	 "binancef": {"1000PEPE-USDT-PERP": {"symbol": "1000PEPEUSDT", "price_decimals": 10}}}
+func LoadOverrides(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read symbol overrides: %w", err)
+	}
+
+	var file map[exchange.ExchangeName]map[string]Override
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse symbol overrides: %w", err)
+	}
+
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+
+	for name, entries := range file {
+		if overrides[name] == nil {
+			overrides[name] = make(map[string]Override)
+		}
+		for key, override := range entries {
+			inst, err := Parse(key)
+			if err != nil {
+				return fmt.Errorf("invalid instrument %q for %s: %w", key, name, err)
+			}
+			overrides[name][inst.String()] = override
+		}
+	}
+
+	return nil
+}
+
+// SetOverride pins the native symbol of an instrument on a venue
+func SetOverride(name exchange.ExchangeName, canonical string, override Override) error {
+	inst, err := Parse(canonical)
+	if err != nil {
+		return err
+	}
+
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+
+	if overrides[name] == nil {
+		overrides[name] = make(map[string]Override)
+	}
+	overrides[name][inst.String()] = override
+	return nil
+}
+
+// Resolve translates a canonical input into the listing on a venue
+// Overrides take precedence over the built-in table
+func Resolve(name exchange.ExchangeName, input string) (Listing, error) {
+	inst, err := Parse(input)
+	if err != nil {
+		return Listing{}, err
+	}
+
+	listing := Listing{Instrument: inst, Exchange: name}
+
+	overridesMu.RLock()
+	override, ok := overrides[name][inst.String()]
+	overridesMu.RUnlock()
+
+	if ok {
+		listing.Native = override.Symbol
+		if override.ContractSize > 0 {
+			listing.ContractSize = override.ContractSize
+		}
+		listing.Scale = types.FixedScale{PriceDecimals: override.PriceDecimals, QtyDecimals: override.QtyDecimals}
+		return listing, nil
+	}
+
+	rule, ok := rules[name]
+	if !ok {
+		rule = Compact
+	}
+
+	native, err := rule(inst)
+	if err != nil {
+		return Listing{}, fmt.Errorf("%s: %w", name, err)
+	}
+	listing.Native = native
+	return listing, nil
+}
+
+// passthrough lists pseudo-exchanges whose symbol is not a venue symbol
+var passthrough = map[exchange.ExchangeName]bool{
+	exchange.File:       true,
+	exchange.Aggregated: true, // Each underlying venue resolves the input itself
+}
+
+// Native returns the venue symbol for an input, or the input unchanged when it
+// cannot be parsed (e.g., option names), leaving the adapter to interpret it
+func Native(name exchange.ExchangeName, input string) string {
+	if passthrough[name] {
+		return input
+	}
+
+	listing, err := Resolve(name, input)
+	if err != nil {
+		return strings.TrimSpace(input)
+	}
+	return listing.Native
+}
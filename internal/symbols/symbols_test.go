@@ -0,0 +1,137 @@
+package symbols
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input     string
+		canonical string
+		typ       Type
+	}{
+		{input: "BTCUSDT", canonical: "BTC-USDT", typ: Spot},
+		{input: "btc_usdt", canonical: "BTC-USDT", typ: Spot},
+		{input: "BTC-USD", canonical: "BTC-USD", typ: Spot},
+		{input: "XBT/USD", canonical: "BTC-USD", typ: Spot},
+		{input: "ETHBTC", canonical: "ETH-BTC", typ: Spot},
+		{input: "BTC-PERP", canonical: "BTC-PERP", typ: Perpetual},
+		{input: "BTCUSDT-PERP", canonical: "BTC-USDT-PERP", typ: Perpetual},
+		{input: "BTC-USDT-SWAP", canonical: "BTC-USDT-PERP", typ: Perpetual},
+		{input: "BTC-PERPETUAL", canonical: "BTC-PERP", typ: Perpetual},
+		{input: "PERP_ETH_USDT", canonical: "ETH-USDT-PERP", typ: Perpetual},
+		{input: "BTC-USD-250627", canonical: "BTC-USD-250627", typ: Future},
+		{input: "BTC-27JUN25", canonical: "BTC-27JUN25", typ: Future},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			inst, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%s) error: %v", tt.input, err)
+			}
+			if inst.String() != tt.canonical {
+				t.Errorf("Parse(%s) = %s, expected %s", tt.input, inst.String(), tt.canonical)
+			}
+			if inst.Type != tt.typ {
+				t.Errorf("Parse(%s) type = %s, expected %s", tt.input, inst.Type, tt.typ)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{"", "BTC", "BTC-USD-250627-100000-C"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) expected error", input)
+		}
+	}
+}
+
+func TestNative(t *testing.T) {
+	tests := []struct {
+		name     exchange.ExchangeName
+		input    string
+		expected string
+	}{
+		{name: exchange.Binance, input: "BTC-USDT", expected: "BTCUSDT"},
+		{name: exchange.Binancef, input: "BTC-PERP", expected: "BTCUSDT"},
+		{name: exchange.Kraken, input: "XBT/USD", expected: "BTC/USD"},
+		{name: exchange.Kraken, input: "BTCUSDT", expected: "BTC/USD"},
+		{name: exchange.OKX, input: "BTCUSDT", expected: "BTC-USDT"},
+		{name: exchange.OKX, input: "BTC-PERP", expected: "BTC-USDT-SWAP"},
+		{name: exchange.Coinbase, input: "BTCUSDT", expected: "BTC-USD"},
+		{name: exchange.CoinbaseIntx, input: "BTCUSDT", expected: "BTC-PERP"},
+		{name: exchange.Deribitf, input: "BTCUSDC", expected: "BTC_USDC-PERPETUAL"},
+		{name: exchange.Deribitf, input: "BTC-27JUN25", expected: "BTC-27JUN25"},
+		{name: exchange.HTX, input: "BTC/USDT", expected: "btcusdt"},
+		{name: exchange.Bitstamp, input: "BTCUSDC", expected: "btcusd"},
+		{name: exchange.Phemex, input: "BTC-USDT", expected: "sBTCUSDT"},
+		{name: exchange.WooXf, input: "BTC-PERP", expected: "PERP_BTC_USDT"},
+		{name: exchange.Paradexf, input: "XBTUSD", expected: "BTC-USD-PERP"},
+		{name: exchange.Hyperliquidf, input: "BTCUSDT", expected: "BTC"},
+		{name: exchange.Lighterf, input: "ETH-PERP", expected: "ETH"},
+		{name: exchange.BinanceCoinf, input: "BTCUSDT", expected: "BTCUSD_PERP"},
+		{name: exchange.BinanceCoinf, input: "BTCUSD_PERP", expected: "BTCUSD_PERP"},
+		{name: exchange.BinanceCoinf, input: "BTC-USD-250627", expected: "BTCUSD_250627"},
+		{name: exchange.BinanceUS, input: "BTCUSD", expected: "BTCUSD"},
+		{name: exchange.Poloniex, input: "BTCUSDT", expected: "BTC_USDT"},
+		{name: exchange.File, input: "anything", expected: "anything"},
+		{name: exchange.OKX, input: "BTC-USD-250627-100000-C", expected: "BTC-USD-250627-100000-C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.name)+"/"+tt.input, func(t *testing.T) {
+			if got := Native(tt.name, tt.input); got != tt.expected {
+				t.Errorf("Native(%s, %s) = %s, expected %s", tt.name, tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "symbols.json")
+	content := `{"kraken": {"BTC-USD": {"symbol": "XBT/USD", "price_decimals": 1}}, "okx": {"BTC-USDT-PERP": {"symbol": "BTC-USDT-SWAP", "contract_size": 0.01}}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write overrides: %v", err)
+	}
+
+	if err := LoadOverrides(path); err != nil {
+		t.Fatalf("LoadOverrides() error: %v", err)
+	}
+	defer func() {
+		overridesMu.Lock()
+		overrides = make(map[exchange.ExchangeName]map[string]Override)
+		overridesMu.Unlock()
+	}()
+
+	// Any notation of the same instrument hits the override
+	if got := Native(exchange.Kraken, "BTCUSD"); got != "XBT/USD" {
+		t.Errorf("Expected override XBT/USD, got %s", got)
+	}
+
+	listing, err := Resolve(exchange.OKX, "BTCUSDT-PERP")
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+	if listing.Native != "BTC-USDT-SWAP" || listing.ContractSize != 0.01 {
+		t.Errorf("Unexpected listing: %+v", listing)
+	}
+	if listing.FixedScale() != types.DefaultFixedScale {
+		t.Errorf("Expected the default fixed scale without an override, got %+v", listing.FixedScale())
+	}
+
+	// Unset decimals fall back to the default
+	listing, err = Resolve(exchange.Kraken, "BTC-USD")
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+	if scale := listing.FixedScale(); scale.PriceDecimals != 1 || scale.QtyDecimals != types.DefaultFixedScale.QtyDecimals {
+		t.Errorf("Unexpected fixed scale: %+v", scale)
+	}
+}
@@ -0,0 +1,158 @@
+package symbols
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+)
+
+// Rule translates a canonical instrument into a venue's native symbol
+type Rule func(inst Instrument) (string, error)
+
+// rules holds the translation table for venues whose symbols differ from the
+// compact BASEQUOTE form. Venues without a rule use Compact
+var rules = map[exchange.ExchangeName]Rule{
+	exchange.Kraken:       withSeparator("/", usdQuote),
+	exchange.OKX:          okxRule,
+	exchange.Coinbase:     withSeparator("-", usdQuote),
+	exchange.CoinbaseIntx: perpSuffix("-PERP"),
+	exchange.BingX:        withSeparator("-", nil),
+	exchange.BingXf:       withSeparator("-", nil),
+	exchange.Deribitf:     deribitRule,
+	exchange.Gate:         withSeparator("_", nil),
+	exchange.Gatef:        withSeparator("_", nil),
+	exchange.Dydxf:        fixedQuote("-", "USD"),
+	exchange.HTX:          lowerCompact,
+	exchange.CryptoCom:    withSeparator("_", nil),
+	exchange.Bitstamp:     bitstampRule,
+	exchange.Gemini:       geminiRule,
+	exchange.Phemex:       phemexSpotRule,
+	exchange.WooXf:        wooRule,
+	exchange.Aevof:        perpSuffix("-PERP"),
+	exchange.Paradexf:     perpSuffix("-USD-PERP"),
+	exchange.Hyperliquidf: baseOnly,
+	exchange.Backpack:     fixedQuote("_", "USDC"),
+	exchange.Lighterf:     baseOnly,
+	exchange.BinanceCoinf: binanceCoinRule,
+	exchange.Poloniex:     withSeparator("_", nil),
+	exchange.BitMart:      withSeparator("_", nil),
+}
+
+// defaultQuote is used when the input leaves the quote to the venue (e.g., BTC-PERP)
+const defaultQuote = "USDT"
+
+// Compact formats an instrument as BASEQUOTE, the form most venues use
+func Compact(inst Instrument) (string, error) {
+	if inst.Type == Future {
+		return "", fmt.Errorf("no compact form for dated future %s", inst)
+	}
+	return inst.Base + inst.QuoteOr(defaultQuote), nil
+}
+
+// withSeparator joins base and quote with sep, optionally mapping the quote first
+func withSeparator(sep string, mapQuote func(string) string) Rule {
+	return func(inst Instrument) (string, error) {
+		quote := inst.QuoteOr(defaultQuote)
+		if mapQuote != nil {
+			quote = mapQuote(quote)
+		}
+		return inst.Base + sep + quote, nil
+	}
+}
+
+// fixedQuote joins the base with a venue's single quote asset
+func fixedQuote(sep, quote string) Rule {
+	return func(inst Instrument) (string, error) {
+		return inst.Base + sep + quote, nil
+	}
+}
+
+// perpSuffix names a perpetual by its base and a venue suffix
+func perpSuffix(suffix string) Rule {
+	return func(inst Instrument) (string, error) {
+		return inst.Base + suffix, nil
+	}
+}
+
+// usdQuote maps stablecoin quotes to USD for fiat-denominated venues
+func usdQuote(quote string) string {
+	if quote == "USDT" {
+		return "USD"
+	}
+	return quote
+}
+
+// lowerCompact formats an instrument as lowercase basequote
+func lowerCompact(inst Instrument) (string, error) {
+	symbol, err := Compact(inst)
+	return strings.ToLower(symbol), err
+}
+
+// baseOnly names an instrument by its base asset
+func baseOnly(inst Instrument) (string, error) {
+	return inst.Base, nil
+}
+
+// okxRule formats OKX instrument IDs: BTC-USDT, BTC-USDT-SWAP, BTC-USD-250627
+func okxRule(inst Instrument) (string, error) {
+	id := inst.Base + "-" + inst.QuoteOr(defaultQuote)
+	switch inst.Type {
+	case Perpetual:
+		return id + "-SWAP", nil
+	case Future:
+		return id + "-" + inst.Expiry, nil
+	default:
+		return id, nil
+	}
+}
+
+// binanceCoinRule formats Binance COIN-margined contracts, which are all USD-settled
+// Examples: BTCUSD_PERP, BTCUSD_250627
+func binanceCoinRule(inst Instrument) (string, error) {
+	if inst.Type == Future {
+		return inst.Base + "USD_" + inst.Expiry, nil
+	}
+	return inst.Base + "USD_PERP", nil
+}
+
+// deribitRule formats Deribit instruments; USD and USDT inputs map to the inverse perpetual
+// Examples: BTC-PERPETUAL, BTC_USDC-PERPETUAL, BTC-27JUN25
+func deribitRule(inst Instrument) (string, error) {
+	if inst.Type == Future {
+		return inst.Base + "-" + inst.Expiry, nil
+	}
+	if inst.Quote == "USDC" {
+		return inst.Base + "_USDC-PERPETUAL", nil
+	}
+	return inst.Base + "-PERPETUAL", nil
+}
+
+// bitstampRule formats Bitstamp pairs, mapping stablecoin quotes to USD
+func bitstampRule(inst Instrument) (string, error) {
+	return strings.ToLower(inst.Base + fiatQuote(inst.QuoteOr("USD"))), nil
+}
+
+// geminiRule formats Gemini pairs, mapping stablecoin quotes to USD
+func geminiRule(inst Instrument) (string, error) {
+	return inst.Base + fiatQuote(inst.QuoteOr("USD")), nil
+}
+
+// fiatQuote maps stablecoin quotes to USD
+func fiatQuote(quote string) string {
+	if quote == "USDT" || quote == "USDC" {
+		return "USD"
+	}
+	return quote
+}
+
+// phemexSpotRule formats Phemex spot symbols with their "s" prefix
+func phemexSpotRule(inst Instrument) (string, error) {
+	symbol, err := Compact(inst)
+	return "s" + symbol, err
+}
+
+// wooRule formats WOO X perpetuals: PERP_BTC_USDT
+func wooRule(inst Instrument) (string, error) {
+	return "PERP_" + inst.Base + "_" + inst.QuoteOr(defaultQuote), nil
+}
@@ -0,0 +1,217 @@
+// Package telemetry instruments the message path with OpenTelemetry
+//
+// Each depth update passes through stages: receive (read from the feed), parse (decode into
+// the canonical update), apply (to the book) and publish (to streaming outputs); each
+// collector tick reads stats from every book and stores them. Every stage records its
+// duration in the orderbook.pipeline.duration histogram, and a sample of updates and ticks
+// is traced with a span per stage. Until Setup installs exporters nothing is recorded, so
+// instrumented code costs next to nothing.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Pipeline stages
+const (
+	StageReceive = "receive"
+	StageParse   = "parse"
+	StageApply   = "apply"
+	StagePublish = "publish"
+	StageStats   = "stats"
+	StageStore   = "store"
+)
+
+// instrumentation is the name instruments and spans are reported under
+const instrumentation = "orderbook"
+
+// Config configures the exporters
+type Config struct {
+	Endpoint       string        // OTLP/gRPC collector URL, e.g. http://localhost:4317; empty disables
+	SampleRatio    float64       // Fraction of updates and collector ticks traced, default 0.01
+	ExportInterval time.Duration // Interval between metric exports, default 15s
+	ServiceName    string        // Reported service.name, default orderbook
+}
+
+// Setup exports spans and metrics over OTLP/gRPC until the returned shutdown is called,
+// which flushes what is pending
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	if config.SampleRatio <= 0 {
+		config.SampleRatio = 0.01
+	}
+	if config.ExportInterval <= 0 {
+		config.ExportInterval = 15 * time.Second
+	}
+	if config.ServiceName == "" {
+		config.ServiceName = instrumentation
+	}
+	if !strings.Contains(config.Endpoint, "://") {
+		return nil, fmt.Errorf("endpoint %q must be a URL such as http://localhost:4317", config.Endpoint)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(config.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resource: %w", err)
+	}
+
+	spans, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(config.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span exporter: %w", err)
+	}
+	traces := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(spans),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+
+	metrics, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithEndpointURL(config.Endpoint))
+	if err != nil {
+		traces.Shutdown(ctx)
+		return nil, fmt.Errorf("failed to create metric exporter: %w", err)
+	}
+	meters := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metrics, sdkmetric.WithInterval(config.ExportInterval))),
+	)
+
+	return install(traces, meters), nil
+}
+
+// install makes the providers global and turns recording on
+func install(traces *sdktrace.TracerProvider, meters *sdkmetric.MeterProvider) func(context.Context) error {
+	otel.SetTracerProvider(traces)
+	otel.SetMeterProvider(meters)
+	enabled.Store(true)
+	return func(ctx context.Context) error {
+		enabled.Store(false)
+		return errors.Join(traces.Shutdown(ctx), meters.Shutdown(ctx))
+	}
+}
+
+// enabled is set while exporters are installed
+var enabled atomic.Bool
+
+// Instruments come from the global providers, which hand them to the exporting providers
+// once Setup installs them
+var (
+	tracer = otel.Tracer(instrumentation)
+	meter  = otel.Meter(instrumentation)
+
+	stageDuration = must(meter.Float64Histogram("orderbook.pipeline.duration",
+		metric.WithDescription("Time spent in one stage of the message path."), metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(1e-6, 5e-6, 1e-5, 5e-5, 1e-4, 5e-4, 1e-3, 5e-3, 1e-2, 5e-2, 0.1, 0.5, 1, 5)))
+	updateLatency = must(meter.Float64Histogram("orderbook.update.latency",
+		metric.WithDescription("Time from the venue's event time to the update being applied to the book."), metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(1e-3, 5e-3, 1e-2, 2.5e-2, 5e-2, 0.1, 0.25, 0.5, 1, 2.5, 5, 10)))
+	received = must(meter.Int64Counter("orderbook.feed.messages",
+		metric.WithDescription("Messages read from the feeds."), metric.WithUnit("{message}")))
+	receivedBytes = must(meter.Int64Counter("orderbook.feed.bytes",
+		metric.WithDescription("Bytes read from the feeds."), metric.WithUnit("By")))
+)
+
+func must[T any](instrument T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return instrument
+}
+
+// Received counts a message read from the feed of exchange
+func Received(exchange string, size int) {
+	if !enabled.Load() {
+		return
+	}
+	attrs := metric.WithAttributes(attribute.String("exchange", exchange))
+	received.Add(context.Background(), 1, attrs)
+	receivedBytes.Add(context.Background(), int64(size), attrs)
+}
+
+// Parsed records the time spent decoding a message from the feed of exchange
+func Parsed(exchange string, took time.Duration) {
+	if !enabled.Load() {
+		return
+	}
+	stageDuration.Record(context.Background(), took.Seconds(),
+		metric.WithAttributes(attribute.String("stage", StageParse), attribute.String("exchange", exchange)))
+}
+
+// Trace follows one depth update or collector tick through its stages
+// Traces are nil while telemetry is off; every method is then a no-op
+type Trace struct {
+	ctx   context.Context
+	span  trace.Span
+	attrs []attribute.KeyValue
+}
+
+// StartUpdate starts tracing a depth update of the book of exchange
+func StartUpdate(exchange, symbol string, finalUpdateID int64) *Trace {
+	if !enabled.Load() {
+		return nil
+	}
+	attrs := []attribute.KeyValue{attribute.String("exchange", exchange), attribute.String("symbol", symbol)}
+	ctx, span := tracer.Start(context.Background(), "orderbook.update",
+		trace.WithAttributes(append(attrs, attribute.Int64("final_update_id", finalUpdateID))...))
+	return &Trace{ctx: ctx, span: span, attrs: attrs}
+}
+
+// StartCollect starts tracing a collector tick storing the books of symbol
+func StartCollect(symbol string) *Trace {
+	if !enabled.Load() {
+		return nil
+	}
+	attrs := []attribute.KeyValue{attribute.String("symbol", symbol)}
+	ctx, span := tracer.Start(context.Background(), "orderbook.collect", trace.WithAttributes(attrs...))
+	return &Trace{ctx: ctx, span: span, attrs: attrs}
+}
+
+// Stage starts a stage, adding to the attributes of the trace; the returned function ends it
+// and records its duration, marking the span failed if err is not nil
+func (t *Trace) Stage(stage string, attrs ...attribute.KeyValue) func(err error) {
+	if t == nil {
+		return func(error) {}
+	}
+	attrs = append(append([]attribute.KeyValue{attribute.String("stage", stage)}, t.attrs...), attrs...)
+	_, span := tracer.Start(t.ctx, "orderbook."+stage, trace.WithAttributes(attrs...))
+	start := time.Now()
+	return func(err error) {
+		stageDuration.Record(t.ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// Applied records how long after the venue's event time an update reached the book
+func (t *Trace) Applied(eventTime time.Time) {
+	if t == nil || eventTime.IsZero() {
+		return
+	}
+	updateLatency.Record(t.ctx, time.Since(eventTime).Seconds(), metric.WithAttributes(t.attrs...))
+}
+
+// End ends the trace
+func (t *Trace) End() {
+	if t == nil {
+		return
+	}
+	t.span.End()
+}
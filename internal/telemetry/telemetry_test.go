@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPipeline(t *testing.T) {
+	// Nothing is recorded before telemetry is installed
+	if tr := StartUpdate("binance", "BTCUSDT", 1); tr != nil {
+		t.Fatal("expected no trace while telemetry is off")
+	}
+	var off *Trace
+	off.Stage(StageApply)(nil)
+	off.End()
+
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	shutdown := install(
+		sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans), sdktrace.WithSampler(sdktrace.AlwaysSample())),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	)
+	defer shutdown(context.Background())
+
+	Received("binance", 120)
+	Parsed("binance", time.Millisecond)
+	tr := StartUpdate("binance", "BTCUSDT", 42)
+	tr.Stage(StageApply)(nil)
+	tr.Applied(time.Now().Add(-50 * time.Millisecond))
+	tr.Stage(StagePublish)(errors.New("broker down"))
+	tr.End()
+
+	ended := spans.GetSpans()
+	if len(ended) != 3 {
+		t.Fatalf("got %d spans, want 3", len(ended))
+	}
+	root := ended[2]
+	if root.Name != "orderbook.update" || ended[0].Name != "orderbook.apply" || ended[1].Name != "orderbook.publish" {
+		t.Errorf("unexpected spans %s, %s, %s", ended[0].Name, ended[1].Name, root.Name)
+	}
+	if ended[0].Parent.SpanID() != root.SpanContext.SpanID() {
+		t.Error("expected stages to be children of the update span")
+	}
+	if ended[1].Status.Code != codes.Error {
+		t.Error("expected the failed stage to be marked as an error")
+	}
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	found := map[string]bool{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = true
+			if hist, ok := m.Data.(metricdata.Histogram[float64]); ok && m.Name == "orderbook.pipeline.duration" {
+				// Parse, apply and publish
+				if len(hist.DataPoints) != 3 {
+					t.Errorf("got %d stage series, want 3", len(hist.DataPoints))
+				}
+			}
+		}
+	}
+	for _, name := range []string{"orderbook.pipeline.duration", "orderbook.update.latency", "orderbook.feed.messages", "orderbook.feed.bytes"} {
+		if !found[name] {
+			t.Errorf("metric %s not recorded", name)
+		}
+	}
+}
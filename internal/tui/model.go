@@ -0,0 +1,444 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+	"github.com/imajinl/crypto-orderbook/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shopspring/decimal"
+)
+
+// Lines of log output shown below the table
+const footerLines = 5
+
+var (
+	titleStyle  = lipgloss.NewStyle().Bold(true)
+	headerStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+	cursorStyle = lipgloss.NewStyle().Reverse(true)
+	bidStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	askStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	warnStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	faintStyle  = lipgloss.NewStyle().Faint(true)
+)
+
+// row is one exchange in the table, read once per refresh
+type row struct {
+	name      string
+	book      *orderbook.BookSnapshot
+	caps      exchange.Capabilities
+	mid       decimal.Decimal
+	spreadBps decimal.Decimal
+	status    string
+}
+
+// column is a sortable column of the table
+type column struct {
+	title string
+	width int
+	text  func(r row) string
+	less  func(a, b row) bool
+}
+
+var columns = []column{
+	{"Exchange", 14, func(r row) string { return r.name }, func(a, b row) bool { return a.name < b.name }},
+	{"Mid", 14, func(r row) string { return priceText(r.mid) }, byDecimal(func(r row) decimal.Decimal { return r.mid })},
+	{"Spread", 10, func(r row) string { return priceText(r.book.Stats.Spread) }, byDecimal(func(r row) decimal.Decimal { return r.book.Stats.Spread })},
+	{"Bps", 7, func(r row) string { return r.spreadBps.StringFixed(2) }, byDecimal(func(r row) decimal.Decimal { return r.spreadBps })},
+	{"Bid", 14, func(r row) string { return priceText(r.book.Stats.BestBid) }, byDecimal(func(r row) decimal.Decimal { return r.book.Stats.BestBid })},
+	{"Ask", 14, func(r row) string { return priceText(r.book.Stats.BestAsk) }, byDecimal(func(r row) decimal.Decimal { return r.book.Stats.BestAsk })},
+	{"Bid depth", 11, func(r row) string { return bandText(r, true) }, byDecimal(func(r row) decimal.Decimal { return firstBand(r).Bid })},
+	{"Ask depth", 11, func(r row) string { return bandText(r, false) }, byDecimal(func(r row) decimal.Decimal { return firstBand(r).Ask })},
+	{"Imb", 6, imbalanceText, byDecimal(func(r row) decimal.Decimal { return firstImbalance(r) })},
+	{"Updates", 10, func(r row) string { return fmt.Sprint(r.book.Stats.EventsProcessed) }, func(a, b row) bool { return a.book.Stats.EventsProcessed < b.book.Stats.EventsProcessed }},
+	{"Age", 7, ageText, func(a, b row) bool { return a.book.Stats.Staleness < b.book.Stats.Staleness }},
+	{"Status", 6, func(r row) string { return r.status }, func(a, b row) bool { return a.status < b.status }},
+}
+
+// model is the bubbletea model of the UI
+type model struct {
+	ui         *UI
+	symbol     string
+	rows       []row
+	sortBy     int
+	descending bool
+	cursor     int
+	current    string // Exchange under the cursor, kept across refreshes and re-sorts
+	ladder     bool   // Showing the ladder of the current exchange rather than the table
+	prompt     bool   // Reading a symbol to switch to
+	input      []rune
+	width      int
+	height     int
+}
+
+// tickMsg triggers a refresh
+type tickMsg time.Time
+
+func newModel(u *UI) *model {
+	m := &model{ui: u}
+	m.refresh()
+	return m
+}
+
+func (m *model) tick() tea.Cmd {
+	return tea.Tick(m.ui.config.Refresh, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// Init implements tea.Model
+func (m *model) Init() tea.Cmd {
+	return m.tick()
+}
+
+// Update implements tea.Model
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		m.refresh()
+		return m, m.tick()
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if m.prompt {
+			m.promptKey(msg)
+			return m, nil
+		}
+		return m, m.key(msg)
+	}
+	return m, nil
+}
+
+// key handles a key outside the symbol prompt
+func (m *model) key(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "enter":
+		m.ladder = m.current != ""
+	case "esc", "backspace":
+		m.ladder = false
+	case "left", "h":
+		if !m.ladder {
+			m.sortBy = (m.sortBy + len(columns) - 1) % len(columns)
+			m.sort()
+		}
+	case "right", "l":
+		if !m.ladder {
+			m.sortBy = (m.sortBy + 1) % len(columns)
+			m.sort()
+		}
+	case "r":
+		m.descending = !m.descending
+		m.sort()
+	case "/", "s":
+		m.prompt = true
+		m.input = nil
+	}
+	return nil
+}
+
+// promptKey edits the symbol prompt; enter hands the symbol over, esc cancels
+func (m *model) promptKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		if symbol := strings.ToUpper(strings.TrimSpace(string(m.input))); symbol != "" && symbol != m.symbol {
+			m.ui.requestSymbol(symbol)
+		}
+		m.prompt = false
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.prompt = false
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeyRunes:
+		m.input = append(m.input, msg.Runes...)
+	}
+}
+
+// move moves the cursor; in the ladder view it switches to the neighbouring exchange
+func (m *model) move(delta int) {
+	if len(m.rows) == 0 {
+		return
+	}
+	m.cursor = max(0, min(len(m.rows)-1, m.cursor+delta))
+	m.current = m.rows[m.cursor].name
+}
+
+// refresh reads every registered book
+func (m *model) refresh() {
+	symbol, names, books := m.ui.registered()
+	m.symbol = symbol
+	m.rows = m.rows[:0]
+	for i, name := range names {
+		snapshot := books[i].ob.Snapshot()
+		r := row{name: name, book: snapshot, caps: books[i].caps, mid: snapshot.MidPrice(), status: "ok"}
+		if !r.mid.IsZero() {
+			r.spreadBps = snapshot.Stats.Spread.Div(r.mid).Mul(decimal.NewFromInt(10000))
+		}
+		switch {
+		case !snapshot.Initialized:
+			r.status = "sync"
+		case m.ui.config.StaleAfter > 0 && snapshot.Stats.Staleness > m.ui.config.StaleAfter:
+			r.status = "STALE"
+		}
+		m.rows = append(m.rows, r)
+	}
+	m.sort()
+}
+
+// sort orders the rows by the sort column, by name within ties, and keeps the cursor on
+// the current exchange
+func (m *model) sort() {
+	less := columns[m.sortBy].less
+	sort.SliceStable(m.rows, func(i, j int) bool {
+		a, b := m.rows[i], m.rows[j]
+		if m.descending {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return m.rows[i].name < m.rows[j].name
+	})
+
+	m.cursor = min(m.cursor, max(len(m.rows)-1, 0))
+	for i, r := range m.rows {
+		if r.name == m.current {
+			m.cursor = i
+		}
+	}
+	if len(m.rows) == 0 {
+		m.current, m.ladder = "", false
+		return
+	}
+	m.current = m.rows[m.cursor].name
+}
+
+// View implements tea.Model
+func (m *model) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("%s · %d exchanges", m.symbol, len(m.rows))))
+	b.WriteString("\n\n")
+
+	body := m.table()
+	help := "↑/↓ select · enter ladder · ←/→ sort · r reverse · / symbol · q quit"
+	if m.ladder {
+		body = m.ladderView()
+		help = "↑/↓ exchange · esc table · / symbol · q quit"
+	}
+	b.WriteString(body)
+	b.WriteString("\n")
+
+	if m.prompt {
+		b.WriteString(fmt.Sprintf("Switch to symbol: %s█\n", string(m.input)))
+	} else {
+		b.WriteString(faintStyle.Render(help) + "\n")
+	}
+	for _, line := range m.ui.logs.last(footerLines) {
+		b.WriteString(faintStyle.Render(m.clip(line)) + "\n")
+	}
+	return b.String()
+}
+
+// table renders the exchanges, scrolled to keep the cursor visible
+func (m *model) table() string {
+	var b strings.Builder
+	var header []string
+	for i, c := range columns {
+		title := c.title
+		if i == m.sortBy {
+			title += map[bool]string{false: "▲", true: "▼"}[m.descending]
+		}
+		header = append(header, pad(title, c.width, i == 0))
+	}
+	b.WriteString(headerStyle.Render(m.clip(strings.Join(header, " "))) + "\n")
+
+	if len(m.rows) == 0 {
+		b.WriteString(faintStyle.Render("Waiting for exchanges to connect...") + "\n")
+		return b.String()
+	}
+
+	// Title, blank line, header, help and the log footer take the rest of the screen
+	visible := len(m.rows)
+	if m.height > 0 {
+		visible = max(1, m.height-5-footerLines)
+	}
+	start := max(0, min(m.cursor-visible+1, len(m.rows)-visible))
+	for i := start; i < min(start+visible, len(m.rows)); i++ {
+		r := m.rows[i]
+		cells := make([]string, len(columns))
+		for j, c := range columns {
+			text := "-"
+			if r.book.Initialized || j == 0 || j == len(columns)-1 {
+				text = c.text(r)
+			}
+			cells[j] = pad(text, c.width, j == 0)
+		}
+		line := m.clip(strings.Join(cells, " "))
+		switch {
+		case i == m.cursor:
+			line = cursorStyle.Render(line)
+		case r.status != "ok":
+			line = warnStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// ladderView renders the levels of the current exchange, asks above bids, with their
+// cumulative size from the touch
+func (m *model) ladderView() string {
+	var r row
+	for _, candidate := range m.rows {
+		if candidate.name == m.current {
+			r = candidate
+		}
+	}
+	if r.book == nil {
+		return "Exchange disconnected\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(r.name))
+	if r.caps.NativeSymbol != "" {
+		b.WriteString(" " + r.caps.NativeSymbol)
+	}
+	b.WriteString("\n")
+	if !r.book.Initialized {
+		b.WriteString(warnStyle.Render("Book is syncing with its feed") + "\n")
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("Mid %s · spread %s (%s bps) · %d bids, %d asks · age %s\n\n",
+		priceText(r.mid), priceText(r.book.Stats.Spread), r.spreadBps.StringFixed(2),
+		len(r.book.Bids), len(r.book.Asks), ageText(r)))
+	b.WriteString(headerStyle.Render(fmt.Sprintf("%-16s %16s %16s", "Price", "Size", "Total")) + "\n")
+
+	levels := m.ui.config.Levels
+	asks := r.book.Asks[:min(levels, len(r.book.Asks))]
+	totals := cumulative(asks)
+	for i := len(asks) - 1; i >= 0; i-- {
+		b.WriteString(askStyle.Render(fmt.Sprintf("%-16s %16s %16s", asks[i].Price, asks[i].Quantity, totals[i])) + "\n")
+	}
+	b.WriteString(faintStyle.Render(fmt.Sprintf("%-16s", priceText(r.mid))) + "\n")
+	bids := r.book.Bids[:min(levels, len(r.book.Bids))]
+	totals = cumulative(bids)
+	for i, level := range bids {
+		b.WriteString(bidStyle.Render(fmt.Sprintf("%-16s %16s %16s", level.Price, level.Quantity, totals[i])) + "\n")
+	}
+
+	// Bands a depth-limited feed does not reach would be understated, so they show n/a
+	if len(r.book.Stats.Bands) > 0 {
+		b.WriteString("\n")
+	}
+	for _, band := range r.book.Stats.Bands {
+		covered := r.caps.MaxDepth == 0 || r.book.CoversDepth(band.Pct.InexactFloat64())
+		bid, ask := "n/a", "n/a"
+		if covered {
+			bid, ask = band.Bid.StringFixed(2), band.Ask.StringFixed(2)
+		}
+		b.WriteString(fmt.Sprintf("Depth %-6s bids %s · asks %s\n", types.BandLabel(band.Pct), bidStyle.Render(bid), askStyle.Render(ask)))
+	}
+	return b.String()
+}
+
+// clip cuts a line to the terminal width
+func (m *model) clip(line string) string {
+	if m.width <= 0 {
+		return line
+	}
+	runes := []rune(line)
+	if len(runes) <= m.width {
+		return line
+	}
+	return string(runes[:m.width])
+}
+
+// pad aligns text in a column, names to the left and numbers to the right
+func pad(text string, width int, left bool) string {
+	if left {
+		return fmt.Sprintf("%-*s", width, text)
+	}
+	return fmt.Sprintf("%*s", width, text)
+}
+
+// cumulative returns the running size of levels from the touch
+func cumulative(levels []types.PriceLevel) []string {
+	totals := make([]string, len(levels))
+	total := decimal.Zero
+	for i, level := range levels {
+		total = total.Add(level.Quantity)
+		totals[i] = total.String()
+	}
+	return totals
+}
+
+func byDecimal(value func(r row) decimal.Decimal) func(a, b row) bool {
+	return func(a, b row) bool { return value(a).LessThan(value(b)) }
+}
+
+// firstBand returns the narrowest liquidity band, which the table shows
+func firstBand(r row) types.BandLiquidity {
+	if len(r.book.Stats.Bands) == 0 {
+		return types.BandLiquidity{}
+	}
+	return r.book.Stats.Bands[0]
+}
+
+// bandText formats the bid or ask size of the narrowest band, n/a when the feed does not
+// reach it
+func bandText(r row, bid bool) string {
+	band := firstBand(r)
+	if band.Pct.IsZero() {
+		return "-"
+	}
+	if r.caps.MaxDepth != 0 && !r.book.CoversDepth(band.Pct.InexactFloat64()) {
+		return "n/a"
+	}
+	if bid {
+		return band.Bid.StringFixed(2)
+	}
+	return band.Ask.StringFixed(2)
+}
+
+func firstImbalance(r row) decimal.Decimal {
+	if len(r.book.Stats.Imbalances) == 0 {
+		return decimal.Zero
+	}
+	return r.book.Stats.Imbalances[0].Imbalance
+}
+
+func imbalanceText(r row) string {
+	if len(r.book.Stats.Imbalances) == 0 {
+		return "-"
+	}
+	return firstImbalance(r).StringFixed(2)
+}
+
+func ageText(r row) string {
+	if r.book.Stats.LastUpdateTime.IsZero() {
+		return "-"
+	}
+	return r.book.Stats.Staleness.Round(100 * time.Millisecond).String()
+}
+
+// priceText formats a price with the precision it carries
+func priceText(value decimal.Decimal) string {
+	if value.IsZero() {
+		return "-"
+	}
+	return value.String()
+}
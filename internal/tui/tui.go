@@ -0,0 +1,183 @@
+// Package tui shows the live books in a full-screen terminal UI, as an alternative to the
+// stats printed to the log every -log-interval
+package tui
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Config configures the terminal UI
+type Config struct {
+	Refresh    time.Duration // Interval between redraws, default 500ms
+	Levels     int           // Levels per side in the ladder view, default 15
+	StaleAfter time.Duration // Books unchanged for longer are flagged stale; 0 disables
+}
+
+// logLines is the number of log lines kept for the footer
+const logLines = 200
+
+// UI draws the books registered with it
+// Books register and unregister like they do with a collector, so the table always lists
+// the exchanges currently connected
+type UI struct {
+	config  Config
+	mu      sync.RWMutex
+	symbol  string
+	books   map[string]book
+	logs    *logBuffer
+	symbols chan string
+	program *tea.Program
+	done    chan struct{}
+}
+
+// book is a registered orderbook and the capabilities of its feed
+type book struct {
+	ob   *orderbook.OrderBook
+	caps exchange.Capabilities
+}
+
+// New creates the UI for the books of symbol; call Run to take over the terminal
+func New(config Config, symbol string) *UI {
+	if config.Refresh <= 0 {
+		config.Refresh = 500 * time.Millisecond
+	}
+	if config.Levels <= 0 {
+		config.Levels = 15
+	}
+	u := &UI{
+		config:  config,
+		symbol:  symbol,
+		books:   make(map[string]book),
+		logs:    &logBuffer{},
+		symbols: make(chan string, 1),
+		done:    make(chan struct{}),
+	}
+	u.program = tea.NewProgram(newModel(u), tea.WithAltScreen())
+	return u
+}
+
+// RegisterOrderbook adds the book of an exchange to the table
+func (u *UI) RegisterOrderbook(name string, ob *orderbook.OrderBook, caps exchange.Capabilities) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.books[name] = book{ob: ob, caps: caps}
+}
+
+// UnregisterOrderbook removes the book of an exchange from the table
+func (u *UI) UnregisterOrderbook(name string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.books, name)
+}
+
+// SetSymbol changes the symbol shown in the title, for books registered after a symbol switch
+func (u *UI) SetSymbol(symbol string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.symbol = symbol
+}
+
+// Symbols delivers the symbols the user asks to switch to; switching is up to the receiver
+func (u *UI) Symbols() <-chan string {
+	return u.symbols
+}
+
+// Done is closed once the user quits or the UI stops
+func (u *UI) Done() <-chan struct{} {
+	return u.done
+}
+
+// LogWriter returns a writer whose latest lines are shown below the table; point the log at
+// it while the UI runs so log output does not tear the screen
+func (u *UI) LogWriter() io.Writer {
+	return u.logs
+}
+
+// Run takes over the terminal until the user quits or Close is called
+func (u *UI) Run() error {
+	defer close(u.done)
+	_, err := u.program.Run()
+	return err
+}
+
+// Close stops the UI and gives the terminal back
+func (u *UI) Close() {
+	u.program.Quit()
+	<-u.done
+}
+
+// requestSymbol hands a symbol to the receiver of Symbols, replacing one not yet taken
+func (u *UI) requestSymbol(symbol string) {
+	for {
+		select {
+		case u.symbols <- symbol:
+			return
+		default:
+		}
+		select {
+		case <-u.symbols:
+		default:
+		}
+	}
+}
+
+// registered returns the monitored symbol and the registered books sorted by exchange name
+func (u *UI) registered() (string, []string, []book) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	names := make([]string, 0, len(u.books))
+	for name := range u.books {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	books := make([]book, len(names))
+	for i, name := range names {
+		books[i] = u.books[name]
+	}
+	return u.symbol, names, books
+}
+
+// logBuffer keeps the latest complete lines written to it
+type logBuffer struct {
+	mu      sync.Mutex
+	partial []byte
+	lines   []string
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.partial = append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		b.lines = append(b.lines, strings.TrimRight(string(b.partial[:i]), "\r"))
+		b.partial = b.partial[i+1:]
+	}
+	if len(b.lines) > logLines {
+		b.lines = append([]string(nil), b.lines[len(b.lines)-logLines:]...)
+	}
+	return len(p), nil
+}
+
+// last returns up to n of the latest lines, oldest first
+func (b *logBuffer) last(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n > len(b.lines) {
+		n = len(b.lines)
+	}
+	return append([]string(nil), b.lines[len(b.lines)-n:]...)
+}
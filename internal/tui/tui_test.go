@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/imajinl/crypto-orderbook/internal/exchange"
+	"github.com/imajinl/crypto-orderbook/internal/orderbook"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func liveBook(t *testing.T, bid, ask string) *orderbook.OrderBook {
+	t.Helper()
+	ob := orderbook.New()
+	err := ob.LoadSnapshot(&exchange.Snapshot{
+		Bids: []exchange.PriceLevel{{Price: bid, Quantity: "1"}, {Price: "90", Quantity: "2"}},
+		Asks: []exchange.PriceLevel{{Price: ask, Quantity: "3"}, {Price: "110", Quantity: "4"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	ob.ProcessBufferedEvents()
+	return ob
+}
+
+func press(m *model, keys ...string) {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "right":
+			msg = tea.KeyMsg{Type: tea.KeyRight}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		m.Update(msg)
+	}
+}
+
+// order returns the exchanges in the order the table lists them
+func order(m *model) string {
+	names := make([]string, len(m.rows))
+	for i, r := range m.rows {
+		names[i] = r.name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestTable(t *testing.T) {
+	u := New(Config{}, "BTCUSDT")
+	u.RegisterOrderbook("okx", liveBook(t, "99", "101"), exchange.Capabilities{})
+	u.RegisterOrderbook("bybit", liveBook(t, "98", "104"), exchange.Capabilities{})
+	u.RegisterOrderbook("kraken", orderbook.New(), exchange.Capabilities{})
+	m := newModel(u)
+
+	if got := order(m); got != "bybit,kraken,okx" {
+		t.Errorf("initial order %s, want by name", got)
+	}
+	view := m.View()
+	for _, want := range []string{"BTCUSDT · 3 exchanges", "Exchange▲", "sync", "101"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("table missing %q:\n%s", want, view)
+		}
+	}
+
+	// Sorting by mid leaves the syncing book, whose mid is zero, first
+	press(m, "right")
+	if got := order(m); got != "kraken,okx,bybit" {
+		t.Errorf("order by mid %s", got)
+	}
+	press(m, "r")
+	if got := order(m); got != "bybit,okx,kraken" {
+		t.Errorf("order by mid, descending %s", got)
+	}
+
+	press(m, "down", "enter")
+	if !m.ladder || m.current != "okx" {
+		t.Fatalf("expected the okx ladder, got ladder %t for %s", m.ladder, m.current)
+	}
+	ladder := m.View()
+	for _, want := range []string{"okx", "Price", "110", "Depth"} {
+		if !strings.Contains(ladder, want) {
+			t.Errorf("ladder missing %q:\n%s", want, ladder)
+		}
+	}
+	if strings.Index(ladder, "110") > strings.Index(ladder, "90") {
+		t.Error("expected asks above bids")
+	}
+	press(m, "esc")
+	if m.ladder {
+		t.Error("expected esc to return to the table")
+	}
+
+	u.UnregisterOrderbook("okx")
+	m.refresh()
+	if len(m.rows) != 2 || m.current == "okx" {
+		t.Errorf("expected okx gone, rows %s, current %s", order(m), m.current)
+	}
+}
+
+func TestSymbolPrompt(t *testing.T) {
+	u := New(Config{}, "BTCUSDT")
+	m := newModel(u)
+
+	press(m, "/", "e", "t", "h", "usdt", "enter")
+	select {
+	case symbol := <-u.Symbols():
+		if symbol != "ETHUSDT" {
+			t.Errorf("requested %s, want ETHUSDT", symbol)
+		}
+	default:
+		t.Fatal("expected a symbol request")
+	}
+
+	// q is typed into the prompt rather than quitting, and esc drops the input
+	press(m, "/", "q", "esc")
+	if m.prompt {
+		t.Error("expected esc to close the prompt")
+	}
+	select {
+	case symbol := <-u.Symbols():
+		t.Errorf("unexpected request for %s", symbol)
+	default:
+	}
+}
+
+func TestLogBuffer(t *testing.T) {
+	var b logBuffer
+	for i := range logLines + 10 {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	b.Write([]byte("partial"))
+	lines := b.last(2)
+	if len(lines) != 2 || lines[1] != fmt.Sprintf("line %d", logLines+9) {
+		t.Errorf("unexpected last lines %q", lines)
+	}
+	if all := b.last(1000); len(all) != logLines {
+		t.Errorf("kept %d lines, want %d", len(all), logLines)
+	}
+}
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// FixedScale is the number of decimals kept for prices and quantities when a book
+// stores them as scaled int64 (price 123.45 at 2 decimals is 12345 ticks)
+type FixedScale struct {
+	PriceDecimals int32
+	QtyDecimals   int32
+}
+
+// DefaultFixedScale covers the tick and lot sizes of nearly every listed instrument
+// while keeping prices up to 9e10 within int64
+var DefaultFixedScale = FixedScale{PriceDecimals: 8, QtyDecimals: 8}
+
+// ParsePrice converts a published price into ticks
+func (s FixedScale) ParsePrice(value string) (int64, error) {
+	return parseScaled(value, s.PriceDecimals)
+}
+
+// ParseQty converts a published quantity into lots
+func (s FixedScale) ParseQty(value string) (int64, error) {
+	return parseScaled(value, s.QtyDecimals)
+}
+
+// Price converts ticks back into a decimal price
+func (s FixedScale) Price(ticks int64) decimal.Decimal {
+	return decimal.New(ticks, -s.PriceDecimals)
+}
+
+// Qty converts lots back into a decimal quantity
+func (s FixedScale) Qty(lots int64) decimal.Decimal {
+	return decimal.New(lots, -s.QtyDecimals)
+}
+
+// PriceTicks converts a decimal price into ticks, reporting false when it has more
+// decimals than the scale or does not fit
+func (s FixedScale) PriceTicks(price decimal.Decimal) (int64, bool) {
+	return toScaled(price, s.PriceDecimals)
+}
+
+// QtyLots converts a decimal quantity into lots like PriceTicks
+func (s FixedScale) QtyLots(qty decimal.Decimal) (int64, bool) {
+	return toScaled(qty, s.QtyDecimals)
+}
+
+// parseScaled reads a plain decimal string straight into a scaled integer without
+// allocating, falling back to decimal parsing for exponent notation
+func parseScaled(value string, decimals int32) (int64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("empty number")
+	}
+
+	i, negative := 0, false
+	if value[0] == '-' || value[0] == '+' {
+		negative = value[0] == '-'
+		i++
+	}
+
+	var n int64
+	fraction := int32(-1) // Digits seen after the point, -1 before it
+	digits := false
+	for ; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+			if fraction >= 0 {
+				if fraction == decimals {
+					if c != '0' {
+						return 0, fmt.Errorf("%s has more than %d decimals", value, decimals)
+					}
+					continue
+				}
+				fraction++
+			}
+			if n > (math.MaxInt64-9)/10 {
+				return 0, fmt.Errorf("%s overflows at %d decimals", value, decimals)
+			}
+			n = n*10 + int64(c-'0')
+		case c == '.' && fraction < 0:
+			fraction = 0
+		default:
+			d, err := decimal.NewFromString(value)
+			if err != nil {
+				return 0, err
+			}
+			scaled, ok := toScaled(d, decimals)
+			if !ok {
+				return 0, fmt.Errorf("%s does not fit %d decimals", value, decimals)
+			}
+			return scaled, nil
+		}
+	}
+	if !digits {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+
+	if fraction < 0 {
+		fraction = 0
+	}
+	for ; fraction < decimals; fraction++ {
+		if n > math.MaxInt64/10 {
+			return 0, fmt.Errorf("%s overflows at %d decimals", value, decimals)
+		}
+		n *= 10
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+// toScaled converts a decimal into a scaled integer if it is exact at the scale and fits
+func toScaled(d decimal.Decimal, decimals int32) (int64, bool) {
+	shifted := d.Shift(decimals)
+	if !shifted.IsInteger() {
+		return 0, false
+	}
+	big := shifted.BigInt()
+	if !big.IsInt64() {
+		return 0, false
+	}
+	return big.Int64(), true
+}
@@ -1,6 +1,9 @@
 package types
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -26,25 +29,289 @@ var AvailableTickLevels = []TickLevel{
 	Tick100,
 }
 
+// GapPolicy selects how the order book reacts to a sequence gap in depth updates
+type GapPolicy string
+
+const (
+	GapBuffer GapPolicy = "buffer" // Buffer the update and wait for the periodic reinitialization check
+	GapLog    GapPolicy = "log"    // Log the gap and apply the update anyway
+	GapDrop   GapPolicy = "drop"   // Log the gap and discard the update
+	GapResync GapPolicy = "resync" // Buffer the update and reinitialize from a fresh snapshot immediately
+)
+
+// ParseGapPolicy validates a gap policy name; an empty name selects GapBuffer
+func ParseGapPolicy(name string) (GapPolicy, error) {
+	switch policy := GapPolicy(name); policy {
+	case "":
+		return GapBuffer, nil
+	case GapBuffer, GapLog, GapDrop, GapResync:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown gap policy %q (want buffer, log, drop or resync)", name)
+	}
+}
+
+// CrossPolicy selects how the order book reacts when the best bid reaches the best ask
+type CrossPolicy string
+
+const (
+	CrossLog    CrossPolicy = "log"    // Log and count the crossed book, leaving it as is
+	CrossTrim   CrossPolicy = "trim"   // Drop the stale levels on the side the update did not touch
+	CrossResync CrossPolicy = "resync" // Reinitialize from a fresh snapshot
+)
+
+// ParseCrossPolicy validates a cross policy name; an empty name selects CrossLog
+func ParseCrossPolicy(name string) (CrossPolicy, error) {
+	switch policy := CrossPolicy(name); policy {
+	case "":
+		return CrossLog, nil
+	case CrossLog, CrossTrim, CrossResync:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown cross policy %q (want log, trim or resync)", name)
+	}
+}
+
+// DefaultLiquidityBands are the band half-widths reported when none are configured
+var DefaultLiquidityBands = []decimal.Decimal{
+	decimal.RequireFromString("0.005"),
+	decimal.RequireFromString("0.02"),
+	decimal.RequireFromString("0.10"),
+}
+
+// ParseLiquidityBands parses a comma-separated band list such as "0.1,1,5" (percent)
+// or "10bp,25bp"; bands are returned as fractions of mid, ascending and deduplicated.
+// An empty list selects DefaultLiquidityBands
+func ParseLiquidityBands(list string) ([]decimal.Decimal, error) {
+	var bands []decimal.Decimal
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		band, err := ParseBand(item)
+		if err != nil {
+			return nil, err
+		}
+		bands = append(bands, band)
+	}
+	return NormalizeBands(bands), nil
+}
+
+// ParseBand parses a single band in percent ("0.5", "0.5%") or basis points ("50bp")
+// and returns it as a fraction of mid
+func ParseBand(item string) (decimal.Decimal, error) {
+	item = strings.TrimSpace(strings.ToLower(item))
+
+	scale := decimal.NewFromInt(100)
+	if strings.HasSuffix(item, "bp") {
+		item = strings.TrimSuffix(item, "bp")
+		scale = decimal.NewFromInt(10000)
+	}
+	item = strings.TrimSuffix(item, "%")
+
+	value, err := decimal.NewFromString(item)
+	if err != nil || !value.IsPositive() {
+		return decimal.Zero, fmt.Errorf("invalid band %q", item)
+	}
+	return value.Div(scale), nil
+}
+
+// NormalizeBands sorts bands ascending and drops duplicates; an empty list selects DefaultLiquidityBands
+func NormalizeBands(bands []decimal.Decimal) []decimal.Decimal {
+	if len(bands) == 0 {
+		return DefaultLiquidityBands
+	}
+
+	sorted := append([]decimal.Decimal(nil), bands...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+
+	result := sorted[:1]
+	for _, band := range sorted[1:] {
+		if !band.Equal(result[len(result)-1]) {
+			result = append(result, band)
+		}
+	}
+	return result
+}
+
+// BandLabel formats a band as a percentage, or in basis points below 0.1%
+func BandLabel(pct decimal.Decimal) string {
+	if pct.LessThan(decimal.RequireFromString("0.001")) {
+		return pct.Mul(decimal.NewFromInt(10000)).String() + "bp"
+	}
+	return pct.Mul(decimal.NewFromInt(100)).String() + "%"
+}
+
+// DefaultImbalanceDepths are the top-N level counts reported when none are configured
+var DefaultImbalanceDepths = []int{1, 5, 10}
+
+// DepthImbalance holds short-horizon signals over the top levels of each side
+type DepthImbalance struct {
+	Levels      int             // Levels per side included
+	Imbalance   decimal.Decimal // Bid size / (bid size + ask size); above 0.5 means more bids
+	WeightedMid decimal.Decimal // Side VWAPs weighted by the opposite side's size
+}
+
+// BandLiquidity holds the resting size within one band around mid
+type BandLiquidity struct {
+	Pct   decimal.Decimal // Band half-width as a fraction of mid (0.02 = 2%)
+	Bid   decimal.Decimal // Total bid size within the band
+	Ask   decimal.Decimal // Total ask size within the band
+	Delta decimal.Decimal // Bid - Ask
+
+	BidNotional decimal.Decimal // Bid price * size within the band, in quote currency
+	AskNotional decimal.Decimal // Ask price * size within the band, in quote currency
+}
+
+// DepthPoint is one level of a cumulative depth curve
+type DepthPoint struct {
+	Price    decimal.Decimal
+	Offset   decimal.Decimal // Distance from mid as a fraction of mid, growing away from the spread
+	Quantity decimal.Decimal // Size from the best price up to and including this level
+	Notional decimal.Decimal // Price * size over the same levels, in quote currency
+}
+
+// Side is the direction of a simulated execution
+type Side string
+
+const (
+	Buy  Side = "buy"  // Lifts asks from the lowest price up
+	Sell Side = "sell" // Hits bids from the highest price down
+)
+
+// Execution is the result of walking the book for a simulated market order
+type Execution struct {
+	Side       Side
+	Size       decimal.Decimal // Base size filled
+	Notional   decimal.Decimal // Quote currency spent or received
+	VWAP       decimal.Decimal // Volume-weighted average fill price; zero when nothing fills
+	WorstPrice decimal.Decimal // Price of the last level touched
+	Slippage   decimal.Decimal // Cost of VWAP versus mid as a fraction of mid (0.001 = 10bp), positive when worse
+	Levels     int             // Price levels consumed
+	Complete   bool            // Whether the book held enough to fill the request
+}
+
+// QueuePosition describes where an order rests in its price level's queue
+type QueuePosition struct {
+	Price         decimal.Decimal
+	Quantity      decimal.Decimal // Remaining size of the order
+	Position      int             // Orders ahead of it; 0 is the front of the queue
+	SizeAhead     decimal.Decimal // Size that fills before it
+	OrdersAtLevel int
+}
+
+// DefaultAgeBuckets are the upper age bounds used when none are given; older levels fall in a final open bucket
+var DefaultAgeBuckets = []time.Duration{time.Second, 10 * time.Second, time.Minute, 10 * time.Minute}
+
+// LevelMeta is a price level with its history in the book
+type LevelMeta struct {
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+	Created  time.Time     // When the level appeared; levels from a snapshot date from the snapshot
+	Age      time.Duration // Time since Created
+	Updates  int           // Size changes since it appeared
+}
+
+// AgeBucket holds the resting size whose level is younger than MaxAge and at least the previous bucket's bound
+type AgeBucket struct {
+	MaxAge    time.Duration // Zero for the final bucket of older levels
+	BidQty    decimal.Decimal
+	AskQty    decimal.Decimal
+	BidLevels int
+	AskLevels int
+}
+
+// AgeDistribution describes how long the liquidity near the top of the book has been resting
+// Young, often-updated size points to flickering liquidity, old size to stable resting orders
+type AgeDistribution struct {
+	Buckets         []AgeBucket
+	BidMeanAge      time.Duration // Size-weighted mean age of the bid levels
+	AskMeanAge      time.Duration // Size-weighted mean age of the ask levels
+	UpdatesPerLevel float64       // Mean size changes per level across both sides
+}
+
 // PriceLevel represents a single price level in the order book
 type PriceLevel struct {
 	Price    decimal.Decimal
 	Quantity decimal.Decimal
 }
 
+// RollingStats summarizes the top of book over a trailing window
+type RollingStats struct {
+	Window              time.Duration
+	Samples             int             // Top-of-book samples in the window, at most one per second
+	AvgSpread           decimal.Decimal // Mean spread across the samples
+	SpreadVolatility    decimal.Decimal // Standard deviation of the spread
+	MidReturnVolatility decimal.Decimal // Standard deviation of log returns of mid between samples
+}
+
+// OFIWindow is the order flow imbalance at the top of book over one trailing window
+// Positive values mean buying pressure: bids joined or stepped up, asks pulled or lifted
+type OFIWindow struct {
+	Window  time.Duration
+	OFI     decimal.Decimal // Sum of the signed size changes at the best bid and ask, in base units
+	Updates int             // Book updates contributing to the sum
+}
+
+// TouchSize is the size resting at the best price of one side over an interval, in base units
+type TouchSize struct {
+	Mean         decimal.Decimal // Of the size at the start of the interval and after every update
+	Min          decimal.Decimal
+	TimeWeighted decimal.Decimal // Each size weighted by how long it rested at the touch
+}
+
+// TouchStats summarizes the size at the best bid and ask between two reads of a touch tracker
+// Touch size decides how much a small order fills at the quoted price
+type TouchStats struct {
+	Bid      TouchSize
+	Ask      TouchSize
+	Updates  int           // Book updates within the interval
+	Interval time.Duration // Since the previous read
+}
+
 // Stats holds statistical information about the order book
 type Stats struct {
 	EventsProcessed int64
-	LastEventTime   time.Time
+	LastEventTime   time.Time     // Venue time of the last applied update
+	LastUpdateTime  time.Time     // Local time the book last changed, from a snapshot or an update
+	Staleness       time.Duration // Time since LastUpdateTime when the stats were read
 	ConnectionTime  time.Time
 	BufferedEvents  int
+	EventsDropped   int64 // Book events a slow subscriber had no room for
 	BidLevels       int
 	AskLevels       int
 	BestBid         decimal.Decimal
 	BestAsk         decimal.Decimal
 	Spread          decimal.Decimal
 
-	// Liquidity depth metrics (in base asset units)
+	// Order-level (L3) books only
+	TotalOrders   int // Resting orders across both sides
+	BestBidOrders int // Queue length at the best bid
+	BestAskOrders int // Queue length at the best ask
+
+	// Levels dropped by the depth cap or price band, and their summed size
+	TruncatedLevels int64
+	TruncatedVolume decimal.Decimal
+
+	// Book integrity
+	ChecksumFailures int64 // Venue checksums that did not match the local book
+	SequenceGaps     int64 // Updates that did not follow on from the previous update ID
+	CrossedCount     int64 // Updates that left the best bid at or above the best ask
+	Resyncs          int64 // Times the book was rebuilt from a fresh snapshot
+
+	// Liquidity within each configured band, narrowest first
+	Bands []BandLiquidity
+
+	// Trailing window metrics, zero unless enabled
+	Rolling RollingStats
+
+	// Short-horizon signals
+	Microprice decimal.Decimal  // Top-of-book mid weighted by the opposite side's size
+	Imbalances []DepthImbalance // One per configured depth, shallowest first
+	OFI        []OFIWindow      // One per configured window, shortest first; empty unless enabled
+
+	// Liquidity depth metrics (in base asset units), always kept for the default bands
 	BidLiquidity05Pct decimal.Decimal // Total bid size within 0.5% of mid
 	AskLiquidity05Pct decimal.Decimal // Total ask size within 0.5% of mid
 	BidLiquidity2Pct  decimal.Decimal // Total bid size within 2% of mid
@@ -61,6 +328,16 @@ type Stats struct {
 	TotalBidsQty decimal.Decimal // Sum of all bid quantities
 	TotalAsksQty decimal.Decimal // Sum of all ask quantities
 	TotalDelta   decimal.Decimal // TotalBidsQty - TotalAsksQty (positive = more bids)
+
+	// Notional liquidity (price * size in quote currency), comparable across symbols
+	BidNotional05Pct  decimal.Decimal // Bid notional within 0.5% of mid
+	AskNotional05Pct  decimal.Decimal // Ask notional within 0.5% of mid
+	BidNotional2Pct   decimal.Decimal // Bid notional within 2% of mid
+	AskNotional2Pct   decimal.Decimal // Ask notional within 2% of mid
+	BidNotional10Pct  decimal.Decimal // Bid notional within 10% of mid
+	AskNotional10Pct  decimal.Decimal // Ask notional within 10% of mid
+	TotalBidsNotional decimal.Decimal // Notional of all bid levels
+	TotalAsksNotional decimal.Decimal // Notional of all ask levels
 }
 
 // GetNextTickLevel returns the next tick level in the sequence
@@ -0,0 +1,11 @@
+-- Notional liquidity in quote currency (price * size), sent with every snapshot
+-- PostgREST rejects inserts naming unknown columns, so apply this before deploying
+ALTER TABLE orderbook_snapshots
+	ADD COLUMN IF NOT EXISTS bid_notional_05_pct DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS ask_notional_05_pct DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS bid_notional_2_pct DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS ask_notional_2_pct DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS bid_notional_10_pct DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS ask_notional_10_pct DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS total_bids_notional DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS total_asks_notional DOUBLE PRECISION;
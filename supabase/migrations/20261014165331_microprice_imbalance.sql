@@ -0,0 +1,5 @@
+-- Microprice and the imbalance and weighted mid per top-N depth, sent with every snapshot
+-- PostgREST rejects inserts naming unknown columns, so apply this before deploying
+ALTER TABLE orderbook_snapshots
+	ADD COLUMN IF NOT EXISTS microprice DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS imbalance JSONB;